@@ -2,7 +2,12 @@ package auth
 
 import (
 	"encoding/json"
+	"log"
+	"net"
 	"net/http"
+	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"asana-youtrack-sync/utils"
@@ -11,16 +16,43 @@ import (
 )
 
 type Handler struct {
-	service *Service
+	service        *Service
+	trustedProxies []*net.IPNet // proxies whose X-Forwarded-For is believed
 }
 
-// NewHandler creates a new authentication handler
+// NewHandler creates a new authentication handler. TRUSTED_PROXIES lists the
+// IPs or CIDRs of reverse proxies, comma-separated; X-Forwarded-For is
+// ignored unless the request comes through one of them.
 func NewHandler(service *Service) *Handler {
 	return &Handler{
-		service: service,
+		service:        service,
+		trustedProxies: parseTrustedProxies(os.Getenv("TRUSTED_PROXIES")),
 	}
 }
 
+func parseTrustedProxies(value string) []*net.IPNet {
+	var proxies []*net.IPNet
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			if ip := net.ParseIP(entry); ip != nil && ip.To4() != nil {
+				entry += "/32"
+			} else {
+				entry += "/128"
+			}
+		}
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			proxies = append(proxies, network)
+		} else {
+			log.Printf("AUTH: Ignoring invalid trusted proxy %q\n", entry)
+		}
+	}
+	return proxies
+}
+
 // RegisterRoutes registers authentication routes
 func (h *Handler) RegisterRoutes(router *mux.Router) {
 	auth := router.PathPrefix("/api/auth").Subrouter()
@@ -31,16 +63,22 @@ func (h *Handler) RegisterRoutes(router *mux.Router) {
 	// Register routes with OPTIONS support
 	auth.HandleFunc("/register", h.Register).Methods("POST", "OPTIONS")
 	auth.HandleFunc("/login", h.Login).Methods("POST", "OPTIONS")
+	// Refresh is authenticated by the refresh token itself, so the access token may already be expired
+	auth.HandleFunc("/refresh", h.RefreshToken).Methods("POST", "OPTIONS")
 
 	// Protected routes - create a subrouter with middleware
 	protected := auth.PathPrefix("").Subrouter()
 	protected.Use(h.service.Middleware)
 
-	protected.HandleFunc("/refresh", h.RefreshToken).Methods("POST", "OPTIONS")
 	protected.HandleFunc("/me", h.GetProfile).Methods("GET", "OPTIONS")
 	protected.HandleFunc("/change-password", h.ChangePassword).Methods("POST", "OPTIONS")
 	protected.HandleFunc("/logout", h.Logout).Methods("POST", "OPTIONS")
-	
+
+	// Session management
+	protected.HandleFunc("/sessions", h.ListSessions).Methods("GET", "OPTIONS")
+	protected.HandleFunc("/sessions/revoke-others", h.RevokeOtherSessions).Methods("POST", "OPTIONS")
+	protected.HandleFunc("/sessions/{id}", h.RevokeSession).Methods("DELETE", "OPTIONS")
+
 	// Account deletion endpoints
 	protected.HandleFunc("/account/summary", h.GetAccountDataSummary).Methods("GET", "OPTIONS")
 	protected.HandleFunc("/account/delete", h.DeleteAccount).Methods("POST", "OPTIONS")
@@ -113,7 +151,7 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	response, err := h.service.Login(req)
+	response, err := h.service.Login(req, r.UserAgent(), h.clientIP(r))
 	if err != nil {
 		switch err {
 		case ErrInvalidCredentials:
@@ -127,7 +165,7 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 	utils.SendSuccess(w, response, "Login successful")
 }
 
-// RefreshToken handles token refresh
+// RefreshToken exchanges a refresh token for a new access/refresh token pair
 func (h *Handler) RefreshToken(w http.ResponseWriter, r *http.Request) {
 	// Handle preflight OPTIONS request
 	if r.Method == "OPTIONS" {
@@ -135,15 +173,25 @@ func (h *Handler) RefreshToken(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, ok := GetUserFromContext(r)
-	if !ok {
-		utils.SendUnauthorized(w, "Authentication required")
+	var req RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.SendBadRequest(w, "Invalid request body")
 		return
 	}
 
-	response, err := h.service.RefreshToken(user.UserID)
+	if req.RefreshToken == "" {
+		utils.SendBadRequest(w, "Refresh token is required")
+		return
+	}
+
+	response, err := h.service.RefreshToken(req.RefreshToken)
 	if err != nil {
-		utils.SendInternalError(w, "Internal server error")
+		switch err {
+		case ErrInvalidRefresh, ErrUserNotFound:
+			utils.SendUnauthorized(w, "Invalid or expired refresh token")
+		default:
+			utils.SendInternalError(w, "Internal server error")
+		}
 		return
 	}
 
@@ -203,7 +251,7 @@ func (h *Handler) ChangePassword(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := h.service.ChangePassword(user.UserID, req)
+	err := h.service.ChangePassword(user.UserID, user.SessionID, req)
 	if err != nil {
 		switch err {
 		case ErrInvalidCredentials:
@@ -216,10 +264,10 @@ func (h *Handler) ChangePassword(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	utils.SendSuccess(w, nil, "Password changed successfully")
+	utils.SendSuccess(w, nil, "Password changed successfully. Other sessions have been signed out.")
 }
 
-// Logout handles user logout (client-side token invalidation)
+// Logout revokes the current session
 func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
 	// Handle preflight OPTIONS request
 	if r.Method == "OPTIONS" {
@@ -227,11 +275,104 @@ func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Since we're using stateless JWT tokens, logout is handled client-side
-	// by removing the token from storage. We just return a success response.
+	user, ok := GetUserFromContext(r)
+	if !ok {
+		utils.SendUnauthorized(w, "Authentication required")
+		return
+	}
+
+	if err := h.service.Logout(user.UserID, user.SessionID); err != nil {
+		utils.SendNotFound(w, "Session not found")
+		return
+	}
+
 	utils.SendSuccess(w, nil, "Logged out successfully")
 }
 
+// ListSessions returns the current user's active sessions
+func (h *Handler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	// Handle preflight OPTIONS request
+	if r.Method == "OPTIONS" {
+		h.handleOptions(w, r)
+		return
+	}
+
+	user, ok := GetUserFromContext(r)
+	if !ok {
+		utils.SendUnauthorized(w, "Authentication required")
+		return
+	}
+
+	sessions, err := h.service.ListSessions(user.UserID, user.SessionID)
+	if err != nil {
+		utils.SendInternalError(w, "Failed to list sessions")
+		return
+	}
+
+	utils.SendSuccess(w, sessions, "Sessions retrieved successfully")
+}
+
+// RevokeSession revokes one of the current user's sessions
+func (h *Handler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	// Handle preflight OPTIONS request
+	if r.Method == "OPTIONS" {
+		h.handleOptions(w, r)
+		return
+	}
+
+	user, ok := GetUserFromContext(r)
+	if !ok {
+		utils.SendUnauthorized(w, "Authentication required")
+		return
+	}
+
+	sessionID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utils.SendBadRequest(w, "Invalid session ID")
+		return
+	}
+
+	if err := h.service.RevokeSession(user.UserID, sessionID); err != nil {
+		utils.SendNotFound(w, "Session not found")
+		return
+	}
+
+	utils.LogInfo("SESSION_REVOKED", map[string]interface{}{
+		"user_id":    user.UserID,
+		"session_id": sessionID,
+	})
+
+	utils.SendSuccess(w, map[string]interface{}{
+		"session_id": sessionID,
+		"current":    sessionID == user.SessionID,
+	}, "Session revoked successfully")
+}
+
+// RevokeOtherSessions signs out every session except the current one
+func (h *Handler) RevokeOtherSessions(w http.ResponseWriter, r *http.Request) {
+	// Handle preflight OPTIONS request
+	if r.Method == "OPTIONS" {
+		h.handleOptions(w, r)
+		return
+	}
+
+	user, ok := GetUserFromContext(r)
+	if !ok {
+		utils.SendUnauthorized(w, "Authentication required")
+		return
+	}
+
+	revoked, err := h.service.RevokeOtherSessions(user.UserID, user.SessionID)
+	if err != nil {
+		utils.SendInternalError(w, "Failed to revoke sessions")
+		return
+	}
+
+	utils.SendSuccess(w, map[string]interface{}{
+		"revoked": revoked,
+	}, "Other sessions revoked successfully")
+}
+
 // GetAccountDataSummary returns a summary of user data before deletion
 func (h *Handler) GetAccountDataSummary(w http.ResponseWriter, r *http.Request) {
 	// Handle preflight OPTIONS request
@@ -361,6 +502,46 @@ func (h *Handler) DeleteAccount(w http.ResponseWriter, r *http.Request) {
 	utils.SendSuccess(w, response, "Your account and all associated data have been permanently deleted")
 }
 
+// clientIP returns the caller's address. X-Forwarded-For is only believed
+// when the request comes through a trusted proxy.
+func (h *Handler) clientIP(r *http.Request) string {
+	remote := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		remote = host
+	}
+	if !h.trustedProxy(remote) {
+		return remote
+	}
+
+	// Walk back from the nearest hop, skipping our own proxies; anything
+	// further left was supplied by the client
+	hops := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if hop == "" {
+			continue
+		}
+		if !h.trustedProxy(hop) {
+			return hop
+		}
+		remote = hop
+	}
+	return remote
+}
+
+func (h *Handler) trustedProxy(addr string) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	for _, network := range h.trustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
 // Health check endpoint
 func (h *Handler) Health(w http.ResponseWriter, r *http.Request) {
 	utils.SendSuccess(w, map[string]string{
@@ -4,6 +4,9 @@ import (
     "context"
     "net/http"
     "strings"
+
+    "asana-youtrack-sync/database"
+    "asana-youtrack-sync/utils"
 )
 
 type contextKey string
@@ -62,6 +65,52 @@ func (s *Service) OptionalMiddleware(next http.Handler) http.Handler {
     })
 }
 
+// Roles accepted by RequireRole, from least to most privileged
+const (
+    RoleViewer   = database.RoleViewer
+    RoleOperator = database.RoleOperator
+    RoleAdmin    = database.RoleAdmin
+)
+
+// RequireRole wraps a route handler so it only runs for users holding at least
+// the given role. It must run behind Middleware. Denials are written to the
+// audit log.
+func (s *Service) RequireRole(role string, next http.HandlerFunc) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        if r.Method == "OPTIONS" {
+            next(w, r)
+            return
+        }
+
+        claims, ok := GetUserFromContext(r)
+        if !ok {
+            http.Error(w, "Authentication required", http.StatusUnauthorized)
+            return
+        }
+
+        userRole := s.db.GetUserRole(claims.UserID)
+        if !database.RoleAtLeast(userRole, role) {
+            utils.LogWarn("permission_denied", map[string]interface{}{
+                "user_id":       claims.UserID,
+                "method":        r.Method,
+                "path":          r.URL.Path,
+                "role":          userRole,
+                "required_role": role,
+            })
+            if err := s.db.LogPermissionDenied(claims.UserID, claims.Email, r.Method, r.URL.Path, userRole, role); err != nil {
+                utils.LogError("permission_denied_audit", map[string]interface{}{
+                    "user_id": claims.UserID,
+                    "error":   err.Error(),
+                })
+            }
+            http.Error(w, "Insufficient permissions: "+role+" role required", http.StatusForbidden)
+            return
+        }
+
+        next(w, r)
+    }
+}
+
 // GetUserFromContext extracts user claims from request context
 func GetUserFromContext(r *http.Request) (*Claims, bool) {
     user, ok := r.Context().Value(UserContextKey).(*Claims)
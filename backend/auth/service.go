@@ -2,8 +2,12 @@ package auth
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"log"
 	"time"
 
 	"asana-youtrack-sync/database"
@@ -16,6 +20,13 @@ var (
 	ErrUserExists         = errors.New("user already exists")
 	ErrUserNotFound       = errors.New("user not found")
 	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrInvalidRefresh     = errors.New("invalid or expired refresh token")
+	ErrSessionNotFound    = errors.New("session not found")
+)
+
+const (
+	accessTokenTTL  = 15 * time.Minute    // Short-lived; renewed through /api/auth/refresh
+	refreshTokenTTL = 30 * 24 * time.Hour // Sliding window, extended on every rotation
 )
 
 // Service handles authentication operations
@@ -55,8 +66,9 @@ func (s *Service) Register(req RegisterRequest) (*UserInfo, error) {
 	}, nil
 }
 
-// Login authenticates a user and returns a JWT token
-func (s *Service) Login(req LoginRequest) (*LoginResponse, error) {
+// Login authenticates a user and starts a new session, returning an access
+// token and a refresh token
+func (s *Service) Login(req LoginRequest, userAgent, ipAddress string) (*LoginResponse, error) {
 	user, err := s.db.GetUserByUsername(req.Username)
 	if err != nil {
 		return nil, ErrInvalidCredentials
@@ -66,7 +78,18 @@ func (s *Service) Login(req LoginRequest) (*LoginResponse, error) {
 		return nil, ErrInvalidCredentials
 	}
 
-	token, expiresAt, err := s.generateToken(user)
+	refreshToken, err := generateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	refreshExpiresAt := time.Now().Add(refreshTokenTTL)
+
+	session, err := s.db.CreateUserSession(user.ID, hashRefreshToken(refreshToken), userAgent, ipAddress, refreshExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	token, expiresAt, err := s.generateToken(user, session.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -78,28 +101,101 @@ func (s *Service) Login(req LoginRequest) (*LoginResponse, error) {
 			Username: user.Username,
 			Email:    user.Email,
 		},
-		ExpiresAt: expiresAt,
+		ExpiresAt:        expiresAt,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: refreshExpiresAt,
 	}, nil
 }
 
-// RefreshToken generates a new JWT token for an existing user
-func (s *Service) RefreshToken(userID int) (*TokenResponse, error) {
-	user, err := s.db.GetUserByID(userID)
+// RefreshToken exchanges a refresh token for a new access token and rotates
+// the refresh token. Presenting an already-rotated token is treated as theft
+// and revokes the whole session.
+func (s *Service) RefreshToken(refreshToken string) (*TokenResponse, error) {
+	tokenHash := hashRefreshToken(refreshToken)
+
+	session, err := s.db.GetUserSessionByTokenHash(tokenHash)
+	if err != nil || !session.IsActive() {
+		return nil, ErrInvalidRefresh
+	}
+
+	if session.RefreshTokenHash != tokenHash {
+		log.Printf("AUTH: Refresh token reuse detected for session %d (user %d), revoking\n", session.ID, session.UserID)
+		s.db.RevokeUserSession(session.UserID, session.ID)
+		return nil, ErrInvalidRefresh
+	}
+
+	user, err := s.db.GetUserByID(session.UserID)
 	if err != nil {
 		return nil, ErrUserNotFound
 	}
 
-	token, expiresAt, err := s.generateToken(user)
+	newRefreshToken, err := generateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	refreshExpiresAt := time.Now().Add(refreshTokenTTL)
+
+	if err := s.db.RotateUserSessionToken(session.ID, tokenHash, hashRefreshToken(newRefreshToken), refreshExpiresAt); err != nil {
+		// Lost a race with a concurrent refresh of the same token
+		return nil, ErrInvalidRefresh
+	}
+
+	token, expiresAt, err := s.generateToken(user, session.ID)
 	if err != nil {
 		return nil, err
 	}
 
 	return &TokenResponse{
-		Token:     token,
-		ExpiresAt: expiresAt,
+		Token:            token,
+		ExpiresAt:        expiresAt,
+		RefreshToken:     newRefreshToken,
+		RefreshExpiresAt: refreshExpiresAt,
 	}, nil
 }
 
+// Logout revokes the session the access token belongs to
+func (s *Service) Logout(userID, sessionID int) error {
+	if err := s.db.RevokeUserSession(userID, sessionID); err != nil {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+// ListSessions returns the user's active sessions, flagging the current one
+func (s *Service) ListSessions(userID, currentSessionID int) ([]SessionInfo, error) {
+	sessions, err := s.db.GetActiveUserSessions(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]SessionInfo, 0, len(sessions))
+	for _, session := range sessions {
+		infos = append(infos, SessionInfo{
+			ID:         session.ID,
+			UserAgent:  session.UserAgent,
+			IPAddress:  session.IPAddress,
+			CreatedAt:  session.CreatedAt,
+			LastUsedAt: session.LastUsedAt,
+			ExpiresAt:  session.ExpiresAt,
+			Current:    session.ID == currentSessionID,
+		})
+	}
+	return infos, nil
+}
+
+// RevokeSession revokes one of the user's sessions
+func (s *Service) RevokeSession(userID, sessionID int) error {
+	if err := s.db.RevokeUserSession(userID, sessionID); err != nil {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+// RevokeOtherSessions revokes every session of the user except the current one
+func (s *Service) RevokeOtherSessions(userID, currentSessionID int) (int64, error) {
+	return s.db.RevokeAllUserSessions(userID, currentSessionID)
+}
+
 // GetUser retrieves user information by ID
 func (s *Service) GetUser(userID int) (*UserInfo, error) {
 	user, err := s.db.GetUserByID(userID)
@@ -114,8 +210,8 @@ func (s *Service) GetUser(userID int) (*UserInfo, error) {
 	}, nil
 }
 
-// ChangePassword changes a user's password
-func (s *Service) ChangePassword(userID int, req ChangePasswordRequest) error {
+// ChangePassword changes a user's password and revokes all other sessions
+func (s *Service) ChangePassword(userID, currentSessionID int, req ChangePasswordRequest) error {
 	user, err := s.db.GetUserByID(userID)
 	if err != nil {
 		return ErrUserNotFound
@@ -129,6 +225,10 @@ func (s *Service) ChangePassword(userID int, req ChangePasswordRequest) error {
 	if err = s.db.UpdateUserPassword(userID, newPasswordHash); err != nil {
 		return err
 	}
+
+	if _, err := s.db.RevokeAllUserSessions(userID, currentSessionID); err != nil {
+		log.Printf("AUTH: Warning: failed to revoke sessions after password change for user %d: %v\n", userID, err)
+	}
 	return nil
 }
 
@@ -143,6 +243,11 @@ func (s *Service) DeleteUserAccount(userID int, password string) error {
 		return ErrInvalidCredentials
 	}
 
+	// Sessions cascade with the user row; revoke first so in-flight tokens stop working immediately
+	if _, err := s.db.RevokeAllUserSessions(userID, 0); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
 	return s.db.DeleteUser(userID)
 }
 
@@ -197,20 +302,27 @@ func (s *Service) ValidateToken(tokenString string) (*Claims, error) {
 		return nil, err
 	}
 
-	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
-		return claims, nil
+	claims, ok := token.Claims.(*Claims)
+	if !ok || !token.Valid {
+		return nil, errors.New("invalid token")
 	}
-	return nil, errors.New("invalid token")
+
+	// Access tokens are bound to a server-side session so logout and revocation take effect immediately
+	if claims.SessionID == 0 || !s.db.IsUserSessionActive(claims.SessionID) {
+		return nil, errors.New("session revoked or expired")
+	}
+	return claims, nil
 }
 
-// generateToken creates a new JWT token for a user
-func (s *Service) generateToken(user *database.User) (string, time.Time, error) {
-	expiresAt := time.Now().Add(24 * time.Hour) // Token valid for 24 hours
+// generateToken creates a short-lived JWT access token bound to a session
+func (s *Service) generateToken(user *database.User, sessionID int) (string, time.Time, error) {
+	expiresAt := time.Now().Add(accessTokenTTL)
 
 	claims := &Claims{
-		UserID:   user.ID,
-		Username: user.Username,
-		Email:    user.Email,
+		UserID:    user.ID,
+		Username:  user.Username,
+		Email:     user.Email,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expiresAt),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -227,6 +339,21 @@ func (s *Service) generateToken(user *database.User) (string, time.Time, error)
 	return tokenString, expiresAt, nil
 }
 
+// generateRefreshToken creates an opaque random refresh token
+func generateRefreshToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashRefreshToken returns the form stored server-side; raw refresh tokens are never persisted
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
 // hashPassword hashes a password using Argon2
 func (s *Service) hashPassword(password string) string {
 	salt := make([]byte, 16)
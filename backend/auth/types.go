@@ -20,9 +20,11 @@ type RegisterRequest struct {
 
 // LoginResponse represents a login response
 type LoginResponse struct {
-    Token     string    `json:"token"`
-    User      UserInfo  `json:"user"`
-    ExpiresAt time.Time `json:"expires_at"`
+    Token            string    `json:"token"`
+    User             UserInfo  `json:"user"`
+    ExpiresAt        time.Time `json:"expires_at"`
+    RefreshToken     string    `json:"refresh_token"`
+    RefreshExpiresAt time.Time `json:"refresh_expires_at"`
 }
 
 // UserInfo represents public user information
@@ -37,13 +39,32 @@ type Claims struct {
     UserID   int    `json:"user_id"`
     Username string `json:"username"`
     Email    string `json:"email"`
+    SessionID int   `json:"sid"`
     jwt.RegisteredClaims
 }
 
 // TokenResponse represents a token refresh response
 type TokenResponse struct {
-    Token     string    `json:"token"`
-    ExpiresAt time.Time `json:"expires_at"`
+    Token            string    `json:"token"`
+    ExpiresAt        time.Time `json:"expires_at"`
+    RefreshToken     string    `json:"refresh_token"`
+    RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+}
+
+// RefreshRequest represents a token refresh request
+type RefreshRequest struct {
+    RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// SessionInfo represents an active login session
+type SessionInfo struct {
+    ID         int       `json:"id"`
+    UserAgent  string    `json:"user_agent"`
+    IPAddress  string    `json:"ip_address"`
+    CreatedAt  time.Time `json:"created_at"`
+    LastUsedAt time.Time `json:"last_used_at"`
+    ExpiresAt  time.Time `json:"expires_at"`
+    Current    bool      `json:"current"`
 }
 
 // ChangePasswordRequest represents a password change request
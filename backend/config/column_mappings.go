@@ -0,0 +1,205 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"asana-youtrack-sync/database"
+)
+
+// ErrInvalidColumnMapping is returned for column mappings that don't fit the
+// live Asana sections and YouTrack states
+var ErrInvalidColumnMapping = errors.New("invalid column mapping")
+
+// prepareColumnMappings checks the requested YouTrack-to-Asana mappings
+// against the live sections and states when they changed, and pins the
+// section GIDs and state IDs of both directions. The lookups use the
+// requested credentials and projects, so they see what the settings will be.
+func prepareColumnMappings(req *UpdateSettingsRequest, current *database.UserSettings) error {
+	mappings := &req.ColumnMappings
+	if len(mappings.AsanaToYouTrack) == 0 && len(mappings.YouTrackToAsana) == 0 {
+		return nil
+	}
+	clearColumnMappingIDs(mappings,
+		req.AsanaProjectID != current.AsanaProjectID,
+		req.YouTrackProjectID != current.YouTrackProjectID)
+
+	live := loadLiveColumns(&UserSettings{
+		AsanaPAT:          req.AsanaPAT,
+		AsanaProjectID:    req.AsanaProjectID,
+		YouTrackBaseURL:   req.YouTrackBaseURL,
+		YouTrackToken:     req.YouTrackToken,
+		YouTrackProjectID: req.YouTrackProjectID,
+		YouTrackBoardID:   req.YouTrackBoardID,
+	})
+	if err := live.validate(mappings.YouTrackToAsana, current.ColumnMappings.YouTrackToAsana); err != nil {
+		return err
+	}
+	live.pin(mappings)
+	return nil
+}
+
+// prepareSyncPairColumnMappings does the same for a pair saved through the
+// sync pair API, with the user's credentials and the pair's projects.
+// previous is the pair being replaced, or nil for a new one.
+func (s *Service) prepareSyncPairColumnMappings(userID int, pair, previous *database.SyncPair) error {
+	mappings := &pair.ColumnMappings
+	if len(mappings.AsanaToYouTrack) == 0 && len(mappings.YouTrackToAsana) == 0 {
+		return nil
+	}
+	if previous == nil {
+		previous = &database.SyncPair{}
+	}
+	clearColumnMappingIDs(mappings,
+		pair.AsanaProjectID != previous.AsanaProjectID,
+		pair.YouTrackProjectID != previous.YouTrackProjectID)
+
+	settings, err := s.GetSettings(userID)
+	if err != nil {
+		return err
+	}
+	live := loadLiveColumns(&UserSettings{
+		AsanaPAT:          settings.AsanaPAT,
+		AsanaProjectID:    pair.AsanaProjectID,
+		YouTrackBaseURL:   settings.YouTrackBaseURL,
+		YouTrackToken:     settings.YouTrackToken,
+		YouTrackProjectID: pair.YouTrackProjectID,
+		YouTrackBoardID:   pair.YouTrackBoardID,
+	})
+	if err := live.validate(mappings.YouTrackToAsana, previous.ColumnMappings.YouTrackToAsana); err != nil {
+		return err
+	}
+	live.pin(mappings)
+	return nil
+}
+
+// clearColumnMappingIDs drops pinned IDs of a project that changed; they mean
+// nothing in the new one
+func clearColumnMappingIDs(mappings *database.ColumnMappings, asanaProjectChanged, youtrackProjectChanged bool) {
+	for _, list := range [][]database.ColumnMapping{mappings.AsanaToYouTrack, mappings.YouTrackToAsana} {
+		for i := range list {
+			if asanaProjectChanged {
+				list[i].AsanaSectionGID = ""
+			}
+			if youtrackProjectChanged {
+				list[i].YouTrackStateID = ""
+			}
+		}
+	}
+}
+
+// liveColumns holds the Asana sections and YouTrack states mappings are
+// checked and pinned against, or why they couldn't be loaded
+type liveColumns struct {
+	sections    []database.AsanaSection
+	states      []database.YouTrackState
+	sectionsErr error
+	statesErr   error
+}
+
+func loadLiveColumns(target *UserSettings) *liveColumns {
+	live := &liveColumns{}
+	live.sections, live.sectionsErr = fetchAsanaSections(target)
+	live.states, live.statesErr = fetchYouTrackStates(target)
+	return live
+}
+
+// validate checks YouTrack-to-Asana mappings that differ from the saved ones
+func (l *liveColumns) validate(mappings, saved []database.ColumnMapping) error {
+	if sameColumnMappingList(mappings, saved) {
+		return nil
+	}
+	if l.sectionsErr != nil {
+		return fmt.Errorf("%w: could not load Asana sections to check it: %v", ErrInvalidColumnMapping, l.sectionsErr)
+	}
+	if l.statesErr != nil {
+		return fmt.Errorf("%w: could not load YouTrack states to check it: %v", ErrInvalidColumnMapping, l.statesErr)
+	}
+	return validateReverseMappings(mappings, l.sections, l.states)
+}
+
+// pin records the section GIDs and state IDs of both directions
+func (l *liveColumns) pin(mappings *database.ColumnMappings) {
+	if l.sectionsErr != nil {
+		fmt.Printf("SETTINGS: Could not pin Asana section GIDs: %v\n", l.sectionsErr)
+	}
+	if l.statesErr != nil {
+		fmt.Printf("SETTINGS: Could not pin YouTrack state IDs: %v\n", l.statesErr)
+	}
+	pinColumnMappings(mappings.AsanaToYouTrack, l.sections, l.states)
+	pinColumnMappings(mappings.YouTrackToAsana, l.sections, l.states)
+}
+
+// validateReverseMappings checks that every YouTrack-to-Asana mapping names a
+// live state, or the AnyYouTrackState default, and a live section, and that
+// no state is mapped twice. Several states may share a section.
+func validateReverseMappings(mappings []database.ColumnMapping, sections []database.AsanaSection, states []database.YouTrackState) error {
+	seen := make(map[string]bool)
+	for _, m := range mappings {
+		if strings.TrimSpace(m.AsanaColumn) == "" {
+			return fmt.Errorf("%w: YouTrack state %q has no Asana section", ErrInvalidColumnMapping, m.YouTrackStatus)
+		}
+		if findSection(sections, m) == nil {
+			return fmt.Errorf("%w: Asana section %q not found in the project", ErrInvalidColumnMapping, m.AsanaColumn)
+		}
+
+		state := strings.ToLower(strings.TrimSpace(m.YouTrackStatus))
+		switch {
+		case state == "":
+			return fmt.Errorf("%w: mapping to Asana section %q has no YouTrack state", ErrInvalidColumnMapping, m.AsanaColumn)
+		case state == database.AnyYouTrackState:
+		case findState(states, m) == nil:
+			return fmt.Errorf("%w: YouTrack state %q not found in the project", ErrInvalidColumnMapping, m.YouTrackStatus)
+		}
+		if seen[state] {
+			if state == database.AnyYouTrackState {
+				return fmt.Errorf("%w: only one default section can be set", ErrInvalidColumnMapping)
+			}
+			return fmt.Errorf("%w: YouTrack state %q is mapped more than once", ErrInvalidColumnMapping, m.YouTrackStatus)
+		}
+		seen[state] = true
+	}
+	return nil
+}
+
+// pinColumnMappings records the Asana section GID and YouTrack state ID of
+// each mapping, looked up by name. Mappings whose names aren't found keep the
+// IDs they had, so they survive their section or state being renamed.
+func pinColumnMappings(mappings []database.ColumnMapping, sections []database.AsanaSection, states []database.YouTrackState) {
+	for i := range mappings {
+		m := &mappings[i]
+		for _, section := range sections {
+			if strings.EqualFold(strings.TrimSpace(section.Name), strings.TrimSpace(m.AsanaColumn)) {
+				m.AsanaSectionGID = section.GID
+				break
+			}
+		}
+		for _, state := range states {
+			if state.ID != "" && strings.EqualFold(strings.TrimSpace(state.Name), strings.TrimSpace(m.YouTrackStatus)) {
+				m.YouTrackStateID = state.ID
+				break
+			}
+		}
+	}
+}
+
+func findSection(sections []database.AsanaSection, m database.ColumnMapping) *database.AsanaSection {
+	for i, section := range sections {
+		if strings.EqualFold(strings.TrimSpace(section.Name), strings.TrimSpace(m.AsanaColumn)) ||
+			(m.AsanaSectionGID != "" && section.GID == m.AsanaSectionGID) {
+			return &sections[i]
+		}
+	}
+	return nil
+}
+
+func findState(states []database.YouTrackState, m database.ColumnMapping) *database.YouTrackState {
+	for i, state := range states {
+		if strings.EqualFold(strings.TrimSpace(state.Name), strings.TrimSpace(m.YouTrackStatus)) ||
+			(m.YouTrackStateID != "" && state.ID == m.YouTrackStateID) {
+			return &states[i]
+		}
+	}
+	return nil
+}
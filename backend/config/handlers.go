@@ -2,7 +2,10 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 
 	"asana-youtrack-sync/auth"
 	"asana-youtrack-sync/utils"
@@ -40,6 +43,13 @@ func (h *Handler) RegisterRoutes(router *mux.Router, authService *auth.Service)
 	settings.HandleFunc("/columns/youtrack", h.GetYouTrackStates).Methods("GET", "OPTIONS")
 	settings.HandleFunc("/youtrack/boards", h.GetYouTrackBoards).Methods("GET", "OPTIONS")
 	settings.HandleFunc("/test-connections", h.TestConnections).Methods("POST", "OPTIONS")
+	settings.HandleFunc("/validate", h.ValidateMappings).Methods("GET", "OPTIONS")
+	settings.HandleFunc("/sync-pairs", h.ListSyncPairs).Methods("GET", "OPTIONS")
+	settings.HandleFunc("/sync-pairs", h.CreateSyncPair).Methods("POST")
+	settings.HandleFunc("/sync-pairs/{id}", h.GetSyncPair).Methods("GET", "OPTIONS")
+	settings.HandleFunc("/sync-pairs/{id}", h.UpdateSyncPair).Methods("PUT")
+	settings.HandleFunc("/sync-pairs/{id}", h.DeleteSyncPair).Methods("DELETE")
+	settings.HandleFunc("/sync-pairs/{id}/default", h.SetDefaultSyncPair).Methods("POST", "OPTIONS")
 }
 
 // Handle OPTIONS requests for all settings endpoints
@@ -51,7 +61,19 @@ func (h *Handler) handleOptions(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
-// GetSettings retrieves user settings
+// logPermissionDenied records a rejected admin-only request in the audit log
+func (h *Handler) logPermissionDenied(user *auth.Claims, r *http.Request) {
+	role := h.service.db.GetUserRole(user.UserID)
+	if err := h.service.db.LogPermissionDenied(user.UserID, user.Email, r.Method, r.URL.Path, role, auth.RoleAdmin); err != nil {
+		utils.LogError("permission_denied_audit", map[string]interface{}{
+			"user_id": user.UserID,
+			"error":   err.Error(),
+		})
+	}
+}
+
+// GetSettings retrieves user settings. The optional pair_id query parameter
+// selects the sync pair whose board configuration is returned.
 func (h *Handler) GetSettings(w http.ResponseWriter, r *http.Request) {
 	// Handle preflight OPTIONS request
 	if r.Method == "OPTIONS" {
@@ -65,16 +87,27 @@ func (h *Handler) GetSettings(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	settings, err := h.service.GetSettings(user.UserID)
+	pairID, err := PairIDFromRequest(r)
 	if err != nil {
+		utils.SendBadRequest(w, err.Error())
+		return
+	}
+
+	settings, err := h.service.ForPair(pairID).GetSettings(user.UserID)
+	if err != nil {
+		if pairID != 0 {
+			utils.SendNotFound(w, ErrSyncPairNotFound.Error())
+			return
+		}
 		utils.SendInternalError(w, "Failed to get settings")
 		return
 	}
 
-	utils.SendSuccess(w, settings, "Settings retrieved successfully")
+	utils.SendSuccess(w, settings.Masked(), "Settings retrieved successfully")
 }
 
-// UpdateSettings updates user settings
+// UpdateSettings updates user settings. The board configuration is written to
+// the sync pair named by the optional pair_id query parameter.
 func (h *Handler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
 	// Handle preflight OPTIONS request
 	if r.Method == "OPTIONS" {
@@ -94,29 +127,43 @@ func (h *Handler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Basic validation
-	if req.AsanaPAT != "" && len(req.AsanaPAT) < 10 {
+	// Basic validation (masked values mean "keep the stored credential")
+	if req.AsanaPAT != "" && !IsMaskedSecret(req.AsanaPAT) && len(req.AsanaPAT) < 10 {
 		utils.SendBadRequest(w, "Invalid Asana PAT")
 		return
 	}
 
-	if req.YouTrackBaseURL != "" && req.YouTrackToken == "" {
-		utils.SendBadRequest(w, "YouTrack token is required when URL is provided")
+	if req.YouTrackToken != "" && req.YouTrackBaseURL == "" {
+		utils.SendBadRequest(w, "YouTrack URL is required when token is provided")
 		return
 	}
 
-	if req.YouTrackToken != "" && req.YouTrackBaseURL == "" {
-		utils.SendBadRequest(w, "YouTrack URL is required when token is provided")
+	pairID, err := PairIDFromRequest(r)
+	if err != nil {
+		utils.SendBadRequest(w, err.Error())
 		return
 	}
 
-	settings, err := h.service.UpdateSettings(user.UserID, req)
+	settings, err := h.service.ForPair(pairID).UpdateSettings(user.UserID, req)
 	if err != nil {
+		if err == ErrYouTrackTokenRequired || errors.Is(err, ErrInvalidColumnMapping) {
+			utils.SendBadRequest(w, err.Error())
+			return
+		}
+		if err == ErrOrganizationAdminRequired {
+			h.logPermissionDenied(user, r)
+			utils.SendForbidden(w, err.Error())
+			return
+		}
+		if pairID != 0 {
+			utils.SendNotFound(w, ErrSyncPairNotFound.Error())
+			return
+		}
 		utils.SendInternalError(w, "Failed to update settings")
 		return
 	}
 
-	utils.SendSuccess(w, settings, "Settings updated successfully")
+	utils.SendSuccess(w, settings.Masked(), "Settings updated successfully")
 }
 
 // GetAsanaProjects retrieves available Asana projects
@@ -297,3 +344,225 @@ func (h *Handler) GetYouTrackBoards(w http.ResponseWriter, r *http.Request) {
 	utils.SendSuccess(w, boards, "YouTrack boards retrieved successfully")
 }
 
+// ValidateMappings checks the column, status, priority and tag mappings of
+// the sync pair named by the optional pair_id query parameter against the
+// live Asana and YouTrack projects
+func (h *Handler) ValidateMappings(w http.ResponseWriter, r *http.Request) {
+	// Handle preflight OPTIONS request
+	if r.Method == "OPTIONS" {
+		h.handleOptions(w, r)
+		return
+	}
+
+	user, ok := auth.GetUserFromContext(r)
+	if !ok {
+		utils.SendUnauthorized(w, "Authentication required")
+		return
+	}
+
+	pairID, err := h.service.RequestPairID(r, user.UserID)
+	if err == ErrSyncPairNotFound {
+		utils.SendNotFound(w, err.Error())
+		return
+	}
+	if err != nil {
+		utils.SendBadRequest(w, err.Error())
+		return
+	}
+
+	report, err := h.service.ForPair(pairID).ValidateMappings(user.UserID)
+	if err != nil {
+		utils.SendInternalError(w, "Failed to validate mappings: "+err.Error())
+		return
+	}
+
+	message := "All mappings are valid"
+	if !report.Valid {
+		message = fmt.Sprintf("Found %d mapping errors and %d warnings", report.Errors, report.Warnings)
+	} else if report.Warnings > 0 {
+		message = fmt.Sprintf("Mappings are valid with %d warnings", report.Warnings)
+	}
+	utils.SendSuccess(w, report, message)
+}
+
+// sendSyncPairError maps sync pair errors to HTTP responses
+func (h *Handler) sendSyncPairError(w http.ResponseWriter, r *http.Request, user *auth.Claims, err error, fallback string) {
+	switch err {
+	case ErrSyncPairNotFound:
+		utils.SendNotFound(w, err.Error())
+	case ErrOrganizationAdminRequired:
+		h.logPermissionDenied(user, r)
+		utils.SendForbidden(w, err.Error())
+	case ErrSyncPairNameRequired, ErrDefaultSyncPair, ErrInvalidMatchingThreshold, ErrInvalidOrphanPolicy, ErrInvalidTimeTracking:
+		utils.SendBadRequest(w, err.Error())
+	default:
+		if errors.Is(err, ErrInvalidColumnMapping) {
+			utils.SendBadRequest(w, err.Error())
+			return
+		}
+		utils.SendInternalError(w, fallback)
+	}
+}
+
+// ListSyncPairs handles GET /api/settings/sync-pairs
+func (h *Handler) ListSyncPairs(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "OPTIONS" {
+		h.handleOptions(w, r)
+		return
+	}
+
+	user, ok := auth.GetUserFromContext(r)
+	if !ok {
+		utils.SendUnauthorized(w, "Authentication required")
+		return
+	}
+
+	pairs, err := h.service.GetSyncPairs(user.UserID)
+	if err != nil {
+		utils.SendInternalError(w, "Failed to get sync pairs")
+		return
+	}
+
+	utils.SendSuccess(w, map[string]interface{}{
+		"pairs": pairs,
+		"count": len(pairs),
+	}, "Sync pairs retrieved successfully")
+}
+
+// CreateSyncPair handles POST /api/settings/sync-pairs
+func (h *Handler) CreateSyncPair(w http.ResponseWriter, r *http.Request) {
+	user, ok := auth.GetUserFromContext(r)
+	if !ok {
+		utils.SendUnauthorized(w, "Authentication required")
+		return
+	}
+
+	var req SyncPairRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.SendBadRequest(w, "Invalid request body")
+		return
+	}
+
+	pair, err := h.service.CreateSyncPair(user.UserID, req)
+	if err != nil {
+		h.sendSyncPairError(w, r, user, err, "Failed to create sync pair")
+		return
+	}
+
+	utils.LogInfo("sync_pair_created", map[string]interface{}{
+		"user_id": user.UserID,
+		"pair_id": pair.ID,
+	})
+	utils.SendCreated(w, pair, "Sync pair created successfully")
+}
+
+// GetSyncPair handles GET /api/settings/sync-pairs/{id}
+func (h *Handler) GetSyncPair(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "OPTIONS" {
+		h.handleOptions(w, r)
+		return
+	}
+
+	user, ok := auth.GetUserFromContext(r)
+	if !ok {
+		utils.SendUnauthorized(w, "Authentication required")
+		return
+	}
+
+	pairID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utils.SendBadRequest(w, "Invalid sync pair ID")
+		return
+	}
+
+	pair, err := h.service.GetSyncPair(user.UserID, pairID)
+	if err != nil {
+		h.sendSyncPairError(w, r, user, err, "Failed to get sync pair")
+		return
+	}
+
+	utils.SendSuccess(w, pair, "Sync pair retrieved successfully")
+}
+
+// UpdateSyncPair handles PUT /api/settings/sync-pairs/{id}
+func (h *Handler) UpdateSyncPair(w http.ResponseWriter, r *http.Request) {
+	user, ok := auth.GetUserFromContext(r)
+	if !ok {
+		utils.SendUnauthorized(w, "Authentication required")
+		return
+	}
+
+	pairID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utils.SendBadRequest(w, "Invalid sync pair ID")
+		return
+	}
+
+	var req SyncPairRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.SendBadRequest(w, "Invalid request body")
+		return
+	}
+
+	pair, err := h.service.UpdateSyncPair(user.UserID, pairID, req)
+	if err != nil {
+		h.sendSyncPairError(w, r, user, err, "Failed to update sync pair")
+		return
+	}
+
+	utils.SendSuccess(w, pair, "Sync pair updated successfully")
+}
+
+// DeleteSyncPair handles DELETE /api/settings/sync-pairs/{id}
+func (h *Handler) DeleteSyncPair(w http.ResponseWriter, r *http.Request) {
+	user, ok := auth.GetUserFromContext(r)
+	if !ok {
+		utils.SendUnauthorized(w, "Authentication required")
+		return
+	}
+
+	pairID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utils.SendBadRequest(w, "Invalid sync pair ID")
+		return
+	}
+
+	if err := h.service.DeleteSyncPair(user.UserID, pairID); err != nil {
+		h.sendSyncPairError(w, r, user, err, "Failed to delete sync pair")
+		return
+	}
+
+	utils.LogInfo("sync_pair_deleted", map[string]interface{}{
+		"user_id": user.UserID,
+		"pair_id": pairID,
+	})
+	utils.SendSuccess(w, nil, "Sync pair deleted successfully")
+}
+
+// SetDefaultSyncPair handles POST /api/settings/sync-pairs/{id}/default
+func (h *Handler) SetDefaultSyncPair(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "OPTIONS" {
+		h.handleOptions(w, r)
+		return
+	}
+
+	user, ok := auth.GetUserFromContext(r)
+	if !ok {
+		utils.SendUnauthorized(w, "Authentication required")
+		return
+	}
+
+	pairID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utils.SendBadRequest(w, "Invalid sync pair ID")
+		return
+	}
+
+	pair, err := h.service.SetDefaultSyncPair(user.UserID, pairID)
+	if err != nil {
+		h.sendSyncPairError(w, r, user, err, "Failed to set default sync pair")
+		return
+	}
+
+	utils.SendSuccess(w, pair, "Default sync pair updated successfully")
+}
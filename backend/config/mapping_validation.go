@@ -0,0 +1,746 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"asana-youtrack-sync/database"
+)
+
+// Kinds of mapping a validation issue can be about
+const (
+	MappingKindBoard         = "board"
+	MappingKindColumn        = "column"
+	MappingKindReverseColumn = "reverse_column"
+	MappingKindStatus        = "status"
+	MappingKindPriority      = "priority"
+	MappingKindTag           = "tag"
+	MappingKindResolvedState = "resolved_state"
+	MappingKindTimeField     = "time_field"
+	MappingKindSprint        = "sprint"
+)
+
+// Severities of a validation issue. Errors make a sync fail or do the wrong
+// thing; warnings still work but are likely not what was meant.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// priorityFieldKey names the Asana priority field in PriorityMapping; the
+// other entries map Asana priority options to YouTrack priorities
+const priorityFieldKey = "asana_field"
+
+// MappingIssue is one problem found with a mapping
+type MappingIssue struct {
+	Kind       string `json:"kind"`
+	Severity   string `json:"severity"`
+	Key        string `json:"key,omitempty"`   // Asana side of the mapping
+	Value      string `json:"value,omitempty"` // YouTrack side of the mapping
+	Problem    string `json:"problem"`
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+// MappingValidationReport is the result of checking a sync pair's mappings
+// against the live Asana project and YouTrack project
+type MappingValidationReport struct {
+	SyncPairID int            `json:"sync_pair_id"`
+	Valid      bool           `json:"valid"` // no errors; warnings allowed
+	Errors     int            `json:"errors"`
+	Warnings   int            `json:"warnings"`
+	Checked    map[string]int `json:"checked"` // mappings checked, by kind
+	Issues     []MappingIssue `json:"issues"`
+	// Live data that couldn't be loaded, by source. Mappings depending on
+	// it are not checked.
+	Unavailable map[string]string `json:"unavailable,omitempty"`
+	CheckedAt   time.Time         `json:"checked_at"`
+}
+
+// liveBoards is what the mappings are checked against. A nil list means the
+// source couldn't be loaded.
+type liveBoards struct {
+	sections       []database.AsanaSection
+	asanaTags      []string
+	asanaFields    map[string][]string // custom field name → enum option names
+	asanaTypes     map[string]string   // custom field name → field type
+	states         []database.YouTrackState
+	priorities     []string
+	subsystems     []string
+	boardName      string
+	boardProjects  []string
+	boardSprints   []string // names of the board's unarchived sprints
+	boardAvailable bool
+}
+
+// ValidateMappings checks every column, status, priority, tag and sprint
+// mapping, resolved state and number field of the user's sync pair against
+// the live boards and suggests fixes
+func (s *Service) ValidateMappings(userID int) (*MappingValidationReport, error) {
+	settings, err := s.GetSettings(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &MappingValidationReport{
+		SyncPairID:  settings.SyncPairID,
+		Checked:     make(map[string]int),
+		Issues:      []MappingIssue{},
+		Unavailable: make(map[string]string),
+		CheckedAt:   time.Now(),
+	}
+	live := loadLiveBoards(settings, report)
+
+	report.checkBoard(settings, live)
+	report.checkColumnMappings(settings.ColumnMappings, live)
+	report.checkStatusMappings(settings.CustomFieldMappings.StatusMapping, live)
+	report.checkPriorityMappings(settings.CustomFieldMappings.PriorityMapping, live)
+	report.checkTagMappings(settings.CustomFieldMappings.TagMapping, live)
+	report.checkResolvedStates(settings.CompletionConfig, live)
+	report.checkTimeFields(settings.TimeTracking, live)
+	report.checkSprintMappings(settings, live)
+
+	for _, issue := range report.Issues {
+		if issue.Severity == SeverityError {
+			report.Errors++
+		} else {
+			report.Warnings++
+		}
+	}
+	report.Valid = report.Errors == 0
+	return report, nil
+}
+
+func loadLiveBoards(settings *UserSettings, report *MappingValidationReport) *liveBoards {
+	live := &liveBoards{}
+	unavailable := func(source string, err error) {
+		report.Unavailable[source] = err.Error()
+	}
+
+	var err error
+	if live.sections, err = fetchAsanaSections(settings); err != nil {
+		unavailable("asana_sections", err)
+	}
+	if live.asanaTags, live.asanaFields, live.asanaTypes, err = fetchAsanaTagsAndFields(settings); err != nil {
+		unavailable("asana_tags", err)
+	}
+	if live.states, err = fetchYouTrackStates(settings); err != nil {
+		unavailable("youtrack_states", err)
+	}
+	fieldValues, err := fetchYouTrackFieldValues(settings)
+	if err != nil {
+		unavailable("youtrack_fields", err)
+	} else {
+		live.priorities = fieldValues["Priority"]
+		live.subsystems = fieldValues["Subsystem"]
+		if live.priorities == nil {
+			live.priorities = []string{}
+		}
+		if live.subsystems == nil {
+			live.subsystems = []string{}
+		}
+	}
+	if settings.YouTrackBoardID != "" {
+		if live.boardName, live.boardProjects, live.boardSprints, err = fetchYouTrackBoard(settings); err != nil {
+			unavailable("youtrack_board", err)
+		} else {
+			live.boardAvailable = true
+		}
+	}
+	return live
+}
+
+func (r *MappingValidationReport) add(issue MappingIssue) {
+	r.Issues = append(r.Issues, issue)
+}
+
+// ─── Checks ──────────────────────────────────────────────────────────────────
+
+func (r *MappingValidationReport) checkBoard(settings *UserSettings, live *liveBoards) {
+	if settings.YouTrackBoardID == "" {
+		return
+	}
+	r.Checked[MappingKindBoard]++
+	if _, failed := r.Unavailable["youtrack_board"]; failed {
+		r.add(MappingIssue{
+			Kind:       MappingKindBoard,
+			Severity:   SeverityError,
+			Value:      settings.YouTrackBoardID,
+			Problem:    "YouTrack board could not be loaded",
+			Suggestion: "Pick the board again in the settings; it may have been deleted or the token may lack access",
+		})
+		return
+	}
+	if !live.boardAvailable {
+		return
+	}
+	for _, project := range live.boardProjects {
+		if strings.EqualFold(project, settings.YouTrackProjectID) {
+			return
+		}
+	}
+	r.add(MappingIssue{
+		Kind:       MappingKindBoard,
+		Severity:   SeverityError,
+		Key:        live.boardName,
+		Value:      settings.YouTrackBoardID,
+		Problem:    fmt.Sprintf("YouTrack board %q doesn't include project %s", live.boardName, settings.YouTrackProjectID),
+		Suggestion: "Add the project to the board in YouTrack, or pick a board of this project",
+	})
+}
+
+func (r *MappingValidationReport) checkColumnMappings(mappings database.ColumnMappings, live *liveBoards) {
+	for _, m := range mappings.AsanaToYouTrack {
+		r.Checked[MappingKindColumn]++
+		r.checkSection(MappingKindColumn, m, live)
+		if m.YouTrackStatus == "" {
+			if !m.DisplayOnly && !m.Findings {
+				r.add(MappingIssue{
+					Kind:       MappingKindColumn,
+					Severity:   SeverityError,
+					Key:        m.AsanaColumn,
+					Problem:    "Syncable column has no YouTrack state",
+					Suggestion: "Pick a YouTrack state, or mark the column display-only",
+				})
+			}
+			continue
+		}
+		r.checkState(MappingKindColumn, m, live)
+	}
+
+	seen := make(map[string]bool)
+	for _, m := range mappings.YouTrackToAsana {
+		r.Checked[MappingKindReverseColumn]++
+		r.checkSection(MappingKindReverseColumn, m, live)
+
+		state := strings.ToLower(strings.TrimSpace(m.YouTrackStatus))
+		if state != database.AnyYouTrackState {
+			r.checkState(MappingKindReverseColumn, m, live)
+		}
+		if seen[state] {
+			r.add(MappingIssue{
+				Kind:       MappingKindReverseColumn,
+				Severity:   SeverityError,
+				Key:        m.AsanaColumn,
+				Value:      m.YouTrackStatus,
+				Problem:    "YouTrack state is mapped more than once; only the first mapping is used",
+				Suggestion: "Remove the duplicate mapping",
+			})
+		}
+		seen[state] = true
+	}
+}
+
+// checkSection checks the Asana side of a column mapping. A pinned GID whose
+// section now has another name still works, but is worth knowing about.
+func (r *MappingValidationReport) checkSection(kind string, m database.ColumnMapping, live *liveBoards) {
+	if live.sections == nil {
+		return
+	}
+	names := make([]string, 0, len(live.sections))
+	for _, section := range live.sections {
+		names = append(names, section.Name)
+		if m.AsanaSectionGID != "" && section.GID == m.AsanaSectionGID &&
+			!strings.EqualFold(strings.TrimSpace(section.Name), strings.TrimSpace(m.AsanaColumn)) {
+			r.add(MappingIssue{
+				Kind:       kind,
+				Severity:   SeverityWarning,
+				Key:        m.AsanaColumn,
+				Value:      m.YouTrackStatus,
+				Problem:    fmt.Sprintf("Asana section was renamed to %q", section.Name),
+				Suggestion: fmt.Sprintf("Save the mapping as %q", section.Name),
+			})
+			return
+		}
+	}
+	if findSection(live.sections, m) != nil {
+		return
+	}
+	r.add(missingIssue(kind, m.AsanaColumn, m.YouTrackStatus, "Asana section", m.AsanaColumn, names))
+}
+
+// checkState checks the YouTrack side of a column mapping
+func (r *MappingValidationReport) checkState(kind string, m database.ColumnMapping, live *liveBoards) {
+	if live.states == nil {
+		return
+	}
+	names := make([]string, 0, len(live.states))
+	for _, state := range live.states {
+		names = append(names, state.Name)
+		if m.YouTrackStateID != "" && state.ID == m.YouTrackStateID &&
+			!strings.EqualFold(strings.TrimSpace(state.Name), strings.TrimSpace(m.YouTrackStatus)) {
+			r.add(MappingIssue{
+				Kind:       kind,
+				Severity:   SeverityWarning,
+				Key:        m.AsanaColumn,
+				Value:      m.YouTrackStatus,
+				Problem:    fmt.Sprintf("YouTrack state was renamed to %q", state.Name),
+				Suggestion: fmt.Sprintf("Save the mapping as %q", state.Name),
+			})
+			return
+		}
+	}
+	if findState(live.states, m) != nil {
+		return
+	}
+	r.add(missingIssue(kind, m.AsanaColumn, m.YouTrackStatus, "YouTrack state", m.YouTrackStatus, names))
+}
+
+func (r *MappingValidationReport) checkStatusMappings(mapping map[string]string, live *liveBoards) {
+	for _, section := range sortedMappingKeys(mapping) {
+		state := mapping[section]
+		r.Checked[MappingKindStatus]++
+		if live.sections != nil {
+			r.checkName(MappingKindStatus, section, state, "Asana section", section, sectionNames(live.sections))
+		}
+		if live.states != nil {
+			r.checkName(MappingKindStatus, section, state, "YouTrack state", state, stateNames(live.states))
+		}
+	}
+}
+
+func (r *MappingValidationReport) checkResolvedStates(cc database.CompletionConfig, live *liveBoards) {
+	for _, state := range cc.ResolvedStates {
+		r.Checked[MappingKindResolvedState]++
+		if live.states != nil {
+			r.checkName(MappingKindResolvedState, "", state, "YouTrack state", state, stateNames(live.states))
+		}
+	}
+}
+
+// checkTimeFields checks that the fields time is mirrored to are number fields
+func (r *MappingValidationReport) checkTimeFields(tt database.TimeTrackingConfig, live *liveBoards) {
+	for _, field := range []string{tt.EstimateField, tt.SpentField} {
+		if field != "" {
+			r.checkNumberField(MappingKindTimeField, field, live)
+		}
+	}
+}
+
+// checkSprintMappings checks the Asana side of each sprint mapping, the sprints
+// on the board and the story points field
+func (r *MappingValidationReport) checkSprintMappings(settings *UserSettings, live *liveBoards) {
+	sc := settings.SprintConfig
+	if sc.StoryPointsField != "" {
+		r.checkNumberField(MappingKindSprint, sc.StoryPointsField, live)
+	}
+	if len(sc.Sprints) == 0 {
+		return
+	}
+	if settings.YouTrackBoardID == "" {
+		r.Checked[MappingKindSprint]++
+		r.add(MappingIssue{
+			Kind:       MappingKindSprint,
+			Severity:   SeverityError,
+			Problem:    "Sprints are mapped but no YouTrack board is selected",
+			Suggestion: "Pick the board the sprints belong to",
+		})
+		return
+	}
+
+	var options []string
+	if sc.AsanaField != "" && live.asanaFields != nil {
+		var ok bool
+		if options, ok = lookupFold(live.asanaFields, sc.AsanaField); !ok {
+			fieldNames := make([]string, 0, len(live.asanaFields))
+			for name := range live.asanaFields {
+				fieldNames = append(fieldNames, name)
+			}
+			sort.Strings(fieldNames)
+			r.Checked[MappingKindSprint]++
+			r.add(missingIssue(MappingKindSprint, sc.AsanaField, "", "Asana custom field", sc.AsanaField, fieldNames))
+		}
+	}
+
+	for _, key := range sortedMappingKeys(sc.Sprints) {
+		sprint := sc.Sprints[key]
+		r.Checked[MappingKindSprint]++
+		switch {
+		case sc.AsanaField == "" && live.sections != nil:
+			r.checkName(MappingKindSprint, key, sprint, "Asana section", key, sectionNames(live.sections))
+		case len(options) > 0:
+			// Only enum fields have options to check; text fields take any value
+			r.checkName(MappingKindSprint, key, sprint, "Asana sprint option", key, options)
+		}
+		if sprint != database.SprintCurrent && live.boardAvailable {
+			r.checkName(MappingKindSprint, key, sprint, "YouTrack sprint", sprint, live.boardSprints)
+		}
+	}
+}
+
+// checkNumberField checks that an Asana custom field exists and holds numbers
+func (r *MappingValidationReport) checkNumberField(kind, field string, live *liveBoards) {
+	r.Checked[kind]++
+	if live.asanaTypes == nil {
+		return
+	}
+	fieldNames := make([]string, 0, len(live.asanaTypes))
+	for name := range live.asanaTypes {
+		fieldNames = append(fieldNames, name)
+	}
+	sort.Strings(fieldNames)
+	var fieldType string
+	for name, t := range live.asanaTypes {
+		if strings.EqualFold(strings.TrimSpace(name), field) {
+			fieldType = t
+		}
+	}
+	switch {
+	case fieldType == "":
+		r.add(missingIssue(kind, field, "", "Asana custom field", field, fieldNames))
+	case fieldType != "number":
+		r.add(MappingIssue{
+			Kind:       kind,
+			Severity:   SeverityError,
+			Key:        field,
+			Problem:    fmt.Sprintf("Asana custom field %q is a %s field", field, fieldType),
+			Suggestion: "Use a number field",
+		})
+	default:
+		r.checkName(kind, field, "", "Asana custom field", field, fieldNames)
+	}
+}
+
+func (r *MappingValidationReport) checkPriorityMappings(mapping map[string]string, live *liveBoards) {
+	if len(mapping) == 0 {
+		return
+	}
+
+	var options []string
+	if live.asanaFields != nil {
+		field := mapping[priorityFieldKey]
+		if field == "" {
+			field = "Priority"
+		}
+		var ok bool
+		if options, ok = lookupFold(live.asanaFields, field); !ok {
+			fieldNames := make([]string, 0, len(live.asanaFields))
+			for name := range live.asanaFields {
+				fieldNames = append(fieldNames, name)
+			}
+			r.Checked[MappingKindPriority]++
+			r.add(missingIssue(MappingKindPriority, priorityFieldKey, field, "Asana custom field", field, fieldNames))
+		}
+	}
+
+	for _, asanaPriority := range sortedMappingKeys(mapping) {
+		if asanaPriority == priorityFieldKey {
+			continue
+		}
+		ytPriority := mapping[asanaPriority]
+		r.Checked[MappingKindPriority]++
+		if options != nil {
+			r.checkName(MappingKindPriority, asanaPriority, ytPriority, "Asana priority option", asanaPriority, options)
+		}
+		if live.priorities != nil {
+			r.checkName(MappingKindPriority, asanaPriority, ytPriority, "YouTrack priority", ytPriority, live.priorities)
+		}
+	}
+}
+
+func (r *MappingValidationReport) checkTagMappings(mapping map[string]string, live *liveBoards) {
+	for _, tag := range sortedMappingKeys(mapping) {
+		subsystem := mapping[tag]
+		r.Checked[MappingKindTag]++
+		if live.asanaTags != nil {
+			r.checkName(MappingKindTag, tag, subsystem, "Asana tag", tag, live.asanaTags)
+		}
+		if live.subsystems != nil {
+			r.checkName(MappingKindTag, tag, subsystem, "YouTrack subsystem", subsystem, live.subsystems)
+		}
+	}
+}
+
+// checkName reports a name that isn't among the live names, or only matches
+// one of them in another case
+func (r *MappingValidationReport) checkName(kind, key, value, what, name string, live []string) {
+	for _, candidate := range live {
+		if candidate == name {
+			return
+		}
+	}
+	for _, candidate := range live {
+		if strings.EqualFold(strings.TrimSpace(candidate), strings.TrimSpace(name)) {
+			r.add(MappingIssue{
+				Kind:       kind,
+				Severity:   SeverityWarning,
+				Key:        key,
+				Value:      value,
+				Problem:    fmt.Sprintf("%s %q only matches %q in another case or spacing", what, name, candidate),
+				Suggestion: fmt.Sprintf("Use %q", candidate),
+			})
+			return
+		}
+	}
+	r.add(missingIssue(kind, key, value, what, name, live))
+}
+
+func missingIssue(kind, key, value, what, name string, live []string) MappingIssue {
+	issue := MappingIssue{
+		Kind:     kind,
+		Severity: SeverityError,
+		Key:      key,
+		Value:    value,
+		Problem:  fmt.Sprintf("%s %q not found", what, name),
+	}
+	switch closest := closestName(name, live); {
+	case closest != "":
+		issue.Suggestion = fmt.Sprintf("Did you mean %q?", closest)
+	case len(live) == 0:
+		issue.Suggestion = fmt.Sprintf("The project has no %s values; remove the mapping", what)
+	case len(live) > 10:
+		issue.Suggestion = fmt.Sprintf("Use one of: %s, … (%d in total)", strings.Join(live[:10], ", "), len(live))
+	default:
+		issue.Suggestion = fmt.Sprintf("Use one of: %s", strings.Join(live, ", "))
+	}
+	return issue
+}
+
+// closestName returns the live name nearest to name, if it is near enough to
+// be a likely typo
+func closestName(name string, live []string) string {
+	target := strings.ToLower(strings.TrimSpace(name))
+	best, bestDistance := "", -1
+	for _, candidate := range live {
+		distance := editDistance(target, strings.ToLower(strings.TrimSpace(candidate)))
+		if bestDistance < 0 || distance < bestDistance {
+			best, bestDistance = candidate, distance
+		}
+	}
+	// Allow about one typo per four characters
+	if best == "" || bestDistance > len([]rune(target))/4+1 {
+		return ""
+	}
+	return best
+}
+
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+func sortedMappingKeys(mapping map[string]string) []string {
+	keys := make([]string, 0, len(mapping))
+	for key := range mapping {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func lookupFold(m map[string][]string, name string) ([]string, bool) {
+	for key, values := range m {
+		if strings.EqualFold(strings.TrimSpace(key), strings.TrimSpace(name)) {
+			return values, true
+		}
+	}
+	return nil, false
+}
+
+func sectionNames(sections []database.AsanaSection) []string {
+	names := make([]string, 0, len(sections))
+	for _, section := range sections {
+		names = append(names, section.Name)
+	}
+	return names
+}
+
+func stateNames(states []database.YouTrackState) []string {
+	names := make([]string, 0, len(states))
+	for _, state := range states {
+		names = append(names, state.Name)
+	}
+	return names
+}
+
+// ─── Live data ───────────────────────────────────────────────────────────────
+
+// getJSON fetches url with a bearer token and decodes the JSON response
+func getJSON(url, token string, out interface{}) error {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("request creation error: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/json")
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("API request error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("API error: %d - %s", resp.StatusCode, string(body))
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("JSON unmarshal error: %w", err)
+	}
+	return nil
+}
+
+// fetchAsanaTagsAndFields loads the tags of the project's workspace and the
+// enum options and types of the project's custom fields
+func fetchAsanaTagsAndFields(settings *UserSettings) ([]string, map[string][]string, map[string]string, error) {
+	if settings.AsanaPAT == "" || settings.AsanaProjectID == "" {
+		return nil, nil, nil, fmt.Errorf("asana credentials not configured")
+	}
+
+	var project struct {
+		Data struct {
+			Workspace struct {
+				GID string `json:"gid"`
+			} `json:"workspace"`
+			CustomFieldSettings []struct {
+				CustomField struct {
+					Name        string `json:"name"`
+					Type        string `json:"resource_subtype"`
+					EnumOptions []struct {
+						Name string `json:"name"`
+					} `json:"enum_options"`
+				} `json:"custom_field"`
+			} `json:"custom_field_settings"`
+		} `json:"data"`
+	}
+	projectURL := fmt.Sprintf("https://app.asana.com/api/1.0/projects/%s?opt_fields=workspace.gid,custom_field_settings.custom_field.name,custom_field_settings.custom_field.resource_subtype,custom_field_settings.custom_field.enum_options.name",
+		settings.AsanaProjectID)
+	if err := getJSON(projectURL, settings.AsanaPAT, &project); err != nil {
+		return nil, nil, nil, err
+	}
+
+	fields := make(map[string][]string)
+	types := make(map[string]string)
+	for _, setting := range project.Data.CustomFieldSettings {
+		types[setting.CustomField.Name] = setting.CustomField.Type
+		options := []string{}
+		for _, option := range setting.CustomField.EnumOptions {
+			options = append(options, option.Name)
+		}
+		fields[setting.CustomField.Name] = options
+	}
+
+	tags := []string{}
+	offset := ""
+	for {
+		tagsURL := fmt.Sprintf("https://app.asana.com/api/1.0/workspaces/%s/tags?limit=100&opt_fields=name", project.Data.Workspace.GID)
+		if offset != "" {
+			tagsURL += "&offset=" + url.QueryEscape(offset)
+		}
+		var page struct {
+			Data []struct {
+				Name string `json:"name"`
+			} `json:"data"`
+			NextPage *struct {
+				Offset string `json:"offset"`
+			} `json:"next_page"`
+		}
+		if err := getJSON(tagsURL, settings.AsanaPAT, &page); err != nil {
+			return nil, nil, nil, err
+		}
+		for _, tag := range page.Data {
+			tags = append(tags, tag.Name)
+		}
+		if page.NextPage == nil || page.NextPage.Offset == "" {
+			break
+		}
+		offset = page.NextPage.Offset
+	}
+	return tags, fields, types, nil
+}
+
+// fetchYouTrackFieldValues loads the values of the project's enum-like
+// custom fields, by field name
+func fetchYouTrackFieldValues(settings *UserSettings) (map[string][]string, error) {
+	if settings.YouTrackBaseURL == "" || settings.YouTrackToken == "" || settings.YouTrackProjectID == "" {
+		return nil, fmt.Errorf("youtrack credentials not configured")
+	}
+
+	var customFields []struct {
+		Field struct {
+			Name string `json:"name"`
+		} `json:"field"`
+		Bundle *struct {
+			Values []struct {
+				Name string `json:"name"`
+			} `json:"values"`
+		} `json:"bundle"`
+	}
+	fieldsURL := fmt.Sprintf("%s/api/admin/projects/%s/customFields?fields=field(name),bundle(values(name))",
+		settings.YouTrackBaseURL, settings.YouTrackProjectID)
+	if err := getJSON(fieldsURL, settings.YouTrackToken, &customFields); err != nil {
+		return nil, err
+	}
+
+	values := make(map[string][]string)
+	for _, field := range customFields {
+		if field.Bundle == nil {
+			continue
+		}
+		names := []string{}
+		for _, value := range field.Bundle.Values {
+			names = append(names, value.Name)
+		}
+		values[field.Field.Name] = names
+	}
+	return values, nil
+}
+
+// fetchYouTrackBoard loads the name of the configured board, the IDs and
+// short names of its projects and the names of its unarchived sprints
+func fetchYouTrackBoard(settings *UserSettings) (string, []string, []string, error) {
+	if settings.YouTrackBaseURL == "" || settings.YouTrackToken == "" {
+		return "", nil, nil, fmt.Errorf("youtrack credentials not configured")
+	}
+
+	var board struct {
+		Name     string `json:"name"`
+		Projects []struct {
+			ID        string `json:"id"`
+			ShortName string `json:"shortName"`
+		} `json:"projects"`
+		Sprints []struct {
+			Name     string `json:"name"`
+			Archived bool   `json:"archived"`
+		} `json:"sprints"`
+	}
+	boardURL := fmt.Sprintf("%s/api/agiles/%s?fields=name,projects(id,shortName),sprints(name,archived)",
+		settings.YouTrackBaseURL, settings.YouTrackBoardID)
+	if err := getJSON(boardURL, settings.YouTrackToken, &board); err != nil {
+		return "", nil, nil, err
+	}
+
+	projects := []string{}
+	for _, project := range board.Projects {
+		projects = append(projects, project.ID, project.ShortName)
+	}
+	sprints := []string{}
+	for _, sprint := range board.Sprints {
+		if !sprint.Archived {
+			sprints = append(sprints, sprint.Name)
+		}
+	}
+	return board.Name, projects, sprints, nil
+}
@@ -2,9 +2,11 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"asana-youtrack-sync/database"
@@ -23,8 +25,17 @@ type UserSettings struct {
 	SyncBoardMembership bool                       `json:"sync_board_membership"`
 	CustomFieldMappings CustomFieldMappings        `json:"custom_field_mappings"`
 	ColumnMappings      database.ColumnMappings    `json:"column_mappings"`
+	MatchingConfig      database.MatchingConfig    `json:"matching_config"`
+	OrphanPolicy        database.OrphanPolicy      `json:"orphan_policy"`
+	CompletionConfig    database.CompletionConfig  `json:"completion_config"`
+	TimeTracking        database.TimeTrackingConfig `json:"time_tracking"`
+	SprintConfig        database.SprintConfig      `json:"sprint_config"`
 	CreatedAt           time.Time                  `json:"created_at"`
 	UpdatedAt           time.Time                  `json:"updated_at"`
+	SyncPairID          int                        `json:"sync_pair_id"`
+	SyncPairName        string                     `json:"sync_pair_name"`
+	OrganizationID      *int                       `json:"organization_id,omitempty"`
+	OrganizationRole    string                     `json:"organization_role,omitempty"`
 }
 
 // CustomFieldMappings represents custom field mapping configuration
@@ -46,6 +57,9 @@ type UpdateSettingsRequest struct {
 	SyncBoardMembership bool                     `json:"sync_board_membership"`
 	CustomFieldMappings CustomFieldMappings      `json:"custom_field_mappings"`
 	ColumnMappings      database.ColumnMappings  `json:"column_mappings"`
+	// Credentials are write-only, so removing one takes an explicit flag
+	ClearAsanaPAT       bool                     `json:"clear_asana_pat"`
+	ClearYouTrackToken  bool                     `json:"clear_youtrack_token"`
 }
 
 // Project represents project information for dropdowns
@@ -69,9 +83,60 @@ type YouTrackProject struct {
 	RingId    string `json:"ringId"`
 }
 
-// Service handles settings management
+// secretMask prefixes masked credentials in API responses. Clients echo the
+// masked value back on update to mean "leave unchanged".
+const secretMask = "••••••••"
+
+// ErrYouTrackTokenRequired is returned when a YouTrack URL is saved without any token
+var ErrYouTrackTokenRequired = errors.New("YouTrack token is required when URL is provided")
+
+// ErrOrganizationAdminRequired is returned when a non-admin member changes the
+// shared board configuration or the column mappings
+var ErrOrganizationAdminRequired = errors.New("only organization admins can change the shared board configuration or column mappings")
+
+// MaskSecret hides all but the last four characters of a credential
+func MaskSecret(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	if len(secret) <= 8 {
+		return secretMask
+	}
+	return secretMask + secret[len(secret)-4:]
+}
+
+// IsMaskedSecret reports whether a value is a masked credential returned by the API
+func IsMaskedSecret(value string) bool {
+	return strings.HasPrefix(value, secretMask)
+}
+
+// MaskedSettings is the client-facing view of UserSettings: credentials are
+// write-only and only reported as masked values plus whether they are set.
+type MaskedSettings struct {
+	UserSettings
+	AsanaPATSet      bool `json:"asana_pat_set"`
+	YouTrackTokenSet bool `json:"youtrack_token_set"`
+}
+
+// Masked returns a copy of the settings that is safe to send to clients
+func (us *UserSettings) Masked() *MaskedSettings {
+	masked := &MaskedSettings{
+		UserSettings:     *us,
+		AsanaPATSet:      us.AsanaPAT != "",
+		YouTrackTokenSet: us.YouTrackToken != "",
+	}
+	masked.AsanaPAT = MaskSecret(us.AsanaPAT)
+	masked.YouTrackToken = MaskSecret(us.YouTrackToken)
+	return masked
+}
+
+// Service handles settings management. A service bound to a sync pair (see
+// ForPair) returns that pair's board configuration; the zero pair ID means
+// the user's default pair.
 type Service struct {
-	db *database.DB
+	db          *database.DB
+	pairID      int
+	pairDeleted func(pairID int)
 }
 
 // NewService creates a new settings service
@@ -79,9 +144,26 @@ func NewService(db *database.DB) *Service {
 	return &Service{db: db}
 }
 
+// ForPair returns a settings service bound to the given sync pair
+func (s *Service) ForPair(pairID int) *Service {
+	return &Service{db: s.db, pairID: pairID, pairDeleted: s.pairDeleted}
+}
+
+// OnSyncPairDeleted registers a callback run after a sync pair is deleted, so
+// packages holding per-pair state can drop it. It must be called before the
+// service handles requests.
+func (s *Service) OnSyncPairDeleted(fn func(pairID int)) {
+	s.pairDeleted = fn
+}
+
+// PairID returns the sync pair the service is bound to (0 for the default pair)
+func (s *Service) PairID() int {
+	return s.pairID
+}
+
 // GetSettings retrieves user settings
 func (s *Service) GetSettings(userID int) (*UserSettings, error) {
-	settings, err := s.db.GetUserSettings(userID)
+	settings, err := s.db.GetUserSettingsForPair(userID, s.pairID)
 	if err != nil {
 		return nil, fmt.Errorf("database error: %w", err)
 	}
@@ -102,9 +184,18 @@ func (s *Service) GetSettings(userID int) (*UserSettings, error) {
 			StatusMapping:   settings.CustomFieldMappings.StatusMapping,
 			CustomFields:    settings.CustomFieldMappings.CustomFields,
 		},
-		ColumnMappings: settings.ColumnMappings,
-		CreatedAt:      settings.CreatedAt,
-		UpdatedAt:      settings.UpdatedAt,
+		ColumnMappings:   settings.ColumnMappings,
+		MatchingConfig:   settings.MatchingConfig,
+		OrphanPolicy:     settings.OrphanPolicy,
+		CompletionConfig: settings.CompletionConfig,
+		TimeTracking:     settings.TimeTracking,
+		SprintConfig:     settings.SprintConfig,
+		CreatedAt:        settings.CreatedAt,
+		UpdatedAt:        settings.UpdatedAt,
+		SyncPairID:       settings.SyncPairID,
+		SyncPairName:     settings.SyncPairName,
+		OrganizationID:   settings.OrganizationID,
+		OrganizationRole: settings.OrganizationRole,
 	}, nil
 }
 
@@ -124,8 +215,47 @@ func (s *Service) UpdateSettings(userID int, req UpdateSettingsRequest) (*UserSe
 		req.CustomFieldMappings.CustomFields = make(map[string]string)
 	}
 
+	current, err := s.db.GetUserSettingsForPair(userID, s.pairID)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	// Credentials are write-only: an empty or masked value keeps the stored
+	// one unless the request asks to clear it
+	if req.ClearAsanaPAT {
+		req.AsanaPAT = ""
+	} else if req.AsanaPAT == "" || IsMaskedSecret(req.AsanaPAT) {
+		req.AsanaPAT = current.AsanaPAT
+	}
+	if req.ClearYouTrackToken {
+		req.YouTrackToken = ""
+	} else if req.YouTrackToken == "" || IsMaskedSecret(req.YouTrackToken) {
+		req.YouTrackToken = current.YouTrackToken
+	}
+
+	// Within an organization the board configuration and column mappings are
+	// shared and admin-managed
+	if current.OrganizationID != nil && current.OrganizationRole != database.RoleAdmin {
+		if req.AsanaProjectID != current.AsanaProjectID ||
+			req.YouTrackProjectID != current.YouTrackProjectID ||
+			req.YouTrackBoardID != current.YouTrackBoardID ||
+			req.SyncBoardMembership != current.SyncBoardMembership ||
+			!sameColumnMappings(req.ColumnMappings, current.ColumnMappings) {
+			return nil, ErrOrganizationAdminRequired
+		}
+	}
+
+	if req.YouTrackBaseURL != "" && req.YouTrackToken == "" {
+		return nil, ErrYouTrackTokenRequired
+	}
+
+	if err := prepareColumnMappings(&req, current); err != nil {
+		return nil, err
+	}
+
 	updatedSettings, err := s.db.UpdateUserSettings(
 		userID,
+		s.pairID,
 		req.AsanaPAT,
 		req.YouTrackBaseURL,
 		req.YouTrackToken,
@@ -161,12 +291,39 @@ func (s *Service) UpdateSettings(userID int, req UpdateSettingsRequest) (*UserSe
 			StatusMapping:   updatedSettings.CustomFieldMappings.StatusMapping,
 			CustomFields:    updatedSettings.CustomFieldMappings.CustomFields,
 		},
-		ColumnMappings: updatedSettings.ColumnMappings,
-		CreatedAt:      updatedSettings.CreatedAt,
-		UpdatedAt:      updatedSettings.UpdatedAt,
+		ColumnMappings:   updatedSettings.ColumnMappings,
+		MatchingConfig:   updatedSettings.MatchingConfig,
+		OrphanPolicy:     updatedSettings.OrphanPolicy,
+		CompletionConfig: updatedSettings.CompletionConfig,
+		TimeTracking:     updatedSettings.TimeTracking,
+		SprintConfig:     updatedSettings.SprintConfig,
+		CreatedAt:        updatedSettings.CreatedAt,
+		UpdatedAt:        updatedSettings.UpdatedAt,
+		SyncPairID:       updatedSettings.SyncPairID,
+		SyncPairName:     updatedSettings.SyncPairName,
+		OrganizationID:   updatedSettings.OrganizationID,
+		OrganizationRole: updatedSettings.OrganizationRole,
 	}, nil
 }
 
+// sameColumnMappings compares column mappings, treating nil and empty lists alike
+func sameColumnMappings(a, b database.ColumnMappings) bool {
+	return sameColumnMappingList(a.AsanaToYouTrack, b.AsanaToYouTrack) &&
+		sameColumnMappingList(a.YouTrackToAsana, b.YouTrackToAsana)
+}
+
+func sameColumnMappingList(a, b []database.ColumnMapping) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
 // GetAsanaProjects fetches Asana projects using user's PAT
 func (s *Service) GetAsanaProjects(userID int) ([]Project, error) {
 	settings, err := s.GetSettings(userID)
@@ -333,7 +490,10 @@ func (s *Service) GetAsanaSections(userID int) ([]database.AsanaSection, error)
 	if err != nil {
 		return nil, err
 	}
+	return fetchAsanaSections(settings)
+}
 
+func fetchAsanaSections(settings *UserSettings) ([]database.AsanaSection, error) {
 	if settings.AsanaPAT == "" || settings.AsanaProjectID == "" {
 		return nil, fmt.Errorf("asana credentials not configured")
 	}
@@ -377,12 +537,15 @@ func (s *Service) GetYouTrackStates(userID int) ([]database.YouTrackState, error
 	if err != nil {
 		return nil, err
 	}
+	return fetchYouTrackStates(settings)
+}
 
+func fetchYouTrackStates(settings *UserSettings) ([]database.YouTrackState, error) {
 	if settings.YouTrackBaseURL == "" || settings.YouTrackToken == "" || settings.YouTrackProjectID == "" {
 		return nil, fmt.Errorf("youtrack credentials not configured")
 	}
 
-	url := fmt.Sprintf("%s/api/admin/projects/%s/customFields?fields=field(name,fieldType(id)),bundle(values(name))",
+	url := fmt.Sprintf("%s/api/admin/projects/%s/customFields?fields=field(name,fieldType(id)),bundle(values(id,name,isResolved))",
 		settings.YouTrackBaseURL, settings.YouTrackProjectID)
 
 	req, err := http.NewRequest("GET", url, nil)
@@ -421,8 +584,12 @@ func (s *Service) GetYouTrackStates(userID int) ([]database.YouTrackState, error
 						for _, val := range values {
 							if valMap, ok := val.(map[string]interface{}); ok {
 								if stateName, ok := valMap["name"].(string); ok {
+									stateID, _ := valMap["id"].(string)
+									isResolved, _ := valMap["isResolved"].(bool)
 									states = append(states, database.YouTrackState{
-										Name: stateName,
+										ID:         stateID,
+										Name:       stateName,
+										IsResolved: isResolved,
 									})
 								}
 							}
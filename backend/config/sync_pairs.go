@@ -0,0 +1,319 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"asana-youtrack-sync/database"
+)
+
+var (
+	ErrSyncPairNotFound         = errors.New("sync pair not found")
+	ErrSyncPairNameRequired     = errors.New("sync pair name is required")
+	ErrDefaultSyncPair          = errors.New("the default sync pair cannot be deleted; make another pair the default first")
+	ErrInvalidSyncPairID        = errors.New("pair_id must be a positive integer")
+	ErrInvalidMatchingThreshold = errors.New("matching thresholds must be between 0 and 1")
+	ErrInvalidOrphanPolicy      = errors.New("orphan policy actions must be close, tag, relink or recreate, for reasons deleted, removed or archived, with a grace period of 0 hours or more")
+	ErrInvalidTimeTracking      = errors.New("time tracking unit must be hours or minutes, and estimate_on_create needs an estimate field")
+)
+
+// SyncPairRequest creates or updates a sync pair
+type SyncPairRequest struct {
+	Name                string                      `json:"name"`
+	AsanaProjectID      string                      `json:"asana_project_id"`
+	YouTrackProjectID   string                      `json:"youtrack_project_id"`
+	YouTrackBoardID     string                      `json:"youtrack_board_id"`
+	SyncBoardMembership bool                        `json:"sync_board_membership"`
+	CustomFieldMappings CustomFieldMappings         `json:"custom_field_mappings"`
+	ColumnMappings      database.ColumnMappings     `json:"column_mappings"`
+	MatchingConfig      database.MatchingConfig     `json:"matching_config"`
+	OrphanPolicy        database.OrphanPolicy       `json:"orphan_policy"`
+	CompletionConfig    database.CompletionConfig   `json:"completion_config"`
+	TimeTracking        database.TimeTrackingConfig `json:"time_tracking"`
+	SprintConfig        database.SprintConfig       `json:"sprint_config"`
+}
+
+// PairIDFromRequest reads the optional pair_id query parameter. A missing
+// parameter selects the default pair (0).
+func PairIDFromRequest(r *http.Request) (int, error) {
+	value := r.URL.Query().Get("pair_id")
+	if value == "" {
+		return 0, nil
+	}
+	pairID, err := strconv.Atoi(value)
+	if err != nil || pairID <= 0 {
+		return 0, ErrInvalidSyncPairID
+	}
+	return pairID, nil
+}
+
+// RequestPairID reads the pair_id query parameter and checks that the pair
+// belongs to the user's scope. It returns 0 when the request names no pair.
+func (s *Service) RequestPairID(r *http.Request, userID int) (int, error) {
+	pairID, err := PairIDFromRequest(r)
+	if err != nil || pairID == 0 {
+		return pairID, err
+	}
+	if _, err := s.GetSyncPair(userID, pairID); err != nil {
+		return 0, err
+	}
+	return pairID, nil
+}
+
+// GetSyncPairs lists the sync pairs in the user's scope, default pair first
+func (s *Service) GetSyncPairs(userID int) ([]*database.SyncPair, error) {
+	pairs, err := s.db.GetSyncPairs(userID)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return pairs, nil
+}
+
+// GetSyncPair returns one of the user's sync pairs (0 for the default pair)
+func (s *Service) GetSyncPair(userID, pairID int) (*database.SyncPair, error) {
+	pair, err := s.db.GetSyncPair(userID, pairID)
+	if err != nil {
+		return nil, ErrSyncPairNotFound
+	}
+	return pair, nil
+}
+
+// CreateSyncPair adds a sync pair to the user's scope
+func (s *Service) CreateSyncPair(userID int, req SyncPairRequest) (*database.SyncPair, error) {
+	if err := s.requirePairAdmin(userID); err != nil {
+		return nil, err
+	}
+	pair, err := syncPairFromRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.prepareSyncPairColumnMappings(userID, pair, nil); err != nil {
+		return nil, err
+	}
+	return s.db.CreateSyncPair(userID, pair)
+}
+
+// UpdateSyncPair replaces a sync pair's name, board configuration, matching
+// settings and orphan policy
+func (s *Service) UpdateSyncPair(userID, pairID int, req SyncPairRequest) (*database.SyncPair, error) {
+	if err := s.requirePairAdmin(userID); err != nil {
+		return nil, err
+	}
+	existing, err := s.GetSyncPair(userID, pairID)
+	if err != nil {
+		return nil, err
+	}
+	pair, err := syncPairFromRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	pair.ID = existing.ID
+	if err := s.prepareSyncPairColumnMappings(userID, pair, existing); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.db.UpdateSyncPair(userID, pair)
+	if errors.Is(err, database.ErrSyncPairNotFound) {
+		return nil, ErrSyncPairNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return updated, nil
+}
+
+// DeleteSyncPair removes a sync pair together with its ignore lists
+func (s *Service) DeleteSyncPair(userID, pairID int) error {
+	if err := s.requirePairAdmin(userID); err != nil {
+		return err
+	}
+	pair, err := s.GetSyncPair(userID, pairID)
+	if err != nil {
+		return err
+	}
+	if pair.IsDefault {
+		return ErrDefaultSyncPair
+	}
+	if err := s.db.DeleteSyncPair(userID, pair.ID); err != nil {
+		return err
+	}
+	if s.pairDeleted != nil {
+		s.pairDeleted(pair.ID)
+	}
+	return nil
+}
+
+// SetDefaultSyncPair makes a pair the one used when requests name no pair
+func (s *Service) SetDefaultSyncPair(userID, pairID int) (*database.SyncPair, error) {
+	if err := s.requirePairAdmin(userID); err != nil {
+		return nil, err
+	}
+	if _, err := s.GetSyncPair(userID, pairID); err != nil {
+		return nil, err
+	}
+	if err := s.db.SetDefaultSyncPair(userID, pairID); err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return s.GetSyncPair(userID, pairID)
+}
+
+// requirePairAdmin rejects non-admin organization members; pairs are part of
+// the shared board configuration
+func (s *Service) requirePairAdmin(userID int) error {
+	if s.db.GetUserRole(userID) != database.RoleAdmin {
+		return ErrOrganizationAdminRequired
+	}
+	return nil
+}
+
+func syncPairFromRequest(req SyncPairRequest) (*database.SyncPair, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, ErrSyncPairNameRequired
+	}
+
+	mappings := database.CustomFieldMappings{
+		TagMapping:      req.CustomFieldMappings.TagMapping,
+		PriorityMapping: req.CustomFieldMappings.PriorityMapping,
+		StatusMapping:   req.CustomFieldMappings.StatusMapping,
+		CustomFields:    req.CustomFieldMappings.CustomFields,
+	}
+	if mappings.TagMapping == nil {
+		mappings.TagMapping = make(map[string]string)
+	}
+	if mappings.PriorityMapping == nil {
+		mappings.PriorityMapping = make(map[string]string)
+	}
+	if mappings.StatusMapping == nil {
+		mappings.StatusMapping = make(map[string]string)
+	}
+	if mappings.CustomFields == nil {
+		mappings.CustomFields = make(map[string]string)
+	}
+
+	if err := validateMatchingConfig(req.MatchingConfig); err != nil {
+		return nil, err
+	}
+	orphanPolicy := req.OrphanPolicy
+	orphanPolicy.CloseState = strings.TrimSpace(orphanPolicy.CloseState)
+	orphanPolicy.Tag = strings.TrimSpace(orphanPolicy.Tag)
+	if err := validateOrphanPolicy(orphanPolicy); err != nil {
+		return nil, err
+	}
+	timeTracking, err := timeTrackingConfig(req.TimeTracking)
+	if err != nil {
+		return nil, err
+	}
+
+	columnMappings := req.ColumnMappings
+	if columnMappings.AsanaToYouTrack == nil {
+		columnMappings.AsanaToYouTrack = []database.ColumnMapping{}
+	}
+	if columnMappings.YouTrackToAsana == nil {
+		columnMappings.YouTrackToAsana = []database.ColumnMapping{}
+	}
+
+	return &database.SyncPair{
+		Name:                name,
+		AsanaProjectID:      strings.TrimSpace(req.AsanaProjectID),
+		YouTrackProjectID:   strings.TrimSpace(req.YouTrackProjectID),
+		YouTrackBoardID:     strings.TrimSpace(req.YouTrackBoardID),
+		SyncBoardMembership: req.SyncBoardMembership,
+		CustomFieldMappings: mappings,
+		ColumnMappings:      columnMappings,
+		MatchingConfig:      req.MatchingConfig,
+		OrphanPolicy:        orphanPolicy,
+		CompletionConfig:    completionConfig(req.CompletionConfig),
+		TimeTracking:        timeTracking,
+		SprintConfig:        sprintConfig(req.SprintConfig),
+	}, nil
+}
+
+// validateMatchingConfig checks that every threshold is a confidence between
+// 0 and 1; 0 selects the default
+func validateMatchingConfig(mc database.MatchingConfig) error {
+	thresholds := []float64{mc.Mapping.Threshold, mc.AsanaID.Threshold, mc.Title.Threshold,
+		mc.Similarity.Threshold, mc.Description.Threshold, mc.SuggestionThreshold}
+	for _, threshold := range thresholds {
+		if threshold < 0 || threshold > 1 {
+			return ErrInvalidMatchingThreshold
+		}
+	}
+	return nil
+}
+
+// validateOrphanPolicy checks the policy's actions and grace period
+func validateOrphanPolicy(policy database.OrphanPolicy) error {
+	if !validOrphanAction(policy.Action) || policy.GraceHours < 0 {
+		return ErrInvalidOrphanPolicy
+	}
+	for reason, action := range policy.ReasonActions {
+		switch reason {
+		case database.OrphanReasonDeleted, database.OrphanReasonRemoved, database.OrphanReasonArchived:
+		default:
+			return ErrInvalidOrphanPolicy
+		}
+		if !validOrphanAction(action) {
+			return ErrInvalidOrphanPolicy
+		}
+	}
+	return nil
+}
+
+func validOrphanAction(action string) bool {
+	switch action {
+	case database.OrphanActionNone, database.OrphanActionClose, database.OrphanActionTag,
+		database.OrphanActionRelink, database.OrphanActionRecreate:
+		return true
+	}
+	return false
+}
+
+// completionConfig trims the resolved states and drops blank and repeated ones
+func completionConfig(cc database.CompletionConfig) database.CompletionConfig {
+	states := []string{}
+	for _, state := range cc.ResolvedStates {
+		state = strings.TrimSpace(state)
+		if state == "" || (database.CompletionConfig{ResolvedStates: states}).IsResolved(state) {
+			continue
+		}
+		states = append(states, state)
+	}
+	return database.CompletionConfig{ResolvedStates: states}
+}
+
+// timeTrackingConfig trims the field names and checks the unit
+func timeTrackingConfig(tt database.TimeTrackingConfig) (database.TimeTrackingConfig, error) {
+	tt.EstimateField = strings.TrimSpace(tt.EstimateField)
+	tt.SpentField = strings.TrimSpace(tt.SpentField)
+	tt.Unit = strings.ToLower(strings.TrimSpace(tt.Unit))
+	switch tt.Unit {
+	case "", database.TimeUnitHours, database.TimeUnitMinutes:
+	default:
+		return tt, ErrInvalidTimeTracking
+	}
+	if tt.EstimateOnCreate && tt.EstimateField == "" {
+		return tt, ErrInvalidTimeTracking
+	}
+	return tt, nil
+}
+
+// sprintConfig trims the field names and drops sprint mappings with a blank
+// side
+func sprintConfig(sc database.SprintConfig) database.SprintConfig {
+	sprints := make(map[string]string)
+	for key, sprint := range sc.Sprints {
+		key, sprint = strings.TrimSpace(key), strings.TrimSpace(sprint)
+		if key != "" && sprint != "" {
+			sprints[key] = sprint
+		}
+	}
+	return database.SprintConfig{
+		AsanaField:       strings.TrimSpace(sc.AsanaField),
+		Sprints:          sprints,
+		StoryPointsField: strings.TrimSpace(sc.StoryPointsField),
+	}
+}
@@ -0,0 +1,128 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ─── Analysis Run Operations ─────────────────────────────────────────────────
+//
+// Analysis runs are scoped like ignores (see scopeClause) and belong to a sync
+// pair. Runs older than analysisRunRetention are pruned when a new one is
+// saved.
+
+const analysisRunRetention = 90 * 24 * time.Hour
+
+const analysisRunColumns = `id, user_id, sync_pair_id, selected_columns, counts, started_at, duration_ms, created_at`
+
+func scanAnalysisRun(row interface{ Scan(...interface{}) error }, withTickets bool) (*AnalysisRun, error) {
+	r := &AnalysisRun{}
+	var countsJSON, ticketsJSON []byte
+	dest := []interface{}{&r.ID, &r.UserID, &r.SyncPairID, &r.SelectedColumns, &countsJSON, &r.StartedAt, &r.DurationMs, &r.CreatedAt}
+	if withTickets {
+		dest = append(dest, &ticketsJSON)
+	}
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	json.Unmarshal(countsJSON, &r.Counts)
+	if withTickets {
+		json.Unmarshal(ticketsJSON, &r.TicketIDs)
+	}
+	return r, nil
+}
+
+// SaveAnalysisRun records a finished analysis and prunes the pair's runs that
+// are past retention
+func (db *DB) SaveAnalysisRun(userID, syncPairID int, run *AnalysisRun) error {
+	ctx := context.Background()
+	orgID := db.organizationIDFor(userID)
+
+	countsJSON, err := json.Marshal(run.Counts)
+	if err != nil {
+		return err
+	}
+	ticketsJSON, err := json.Marshal(run.TicketIDs)
+	if err != nil {
+		return err
+	}
+
+	err = db.pool.QueryRow(ctx,
+		`INSERT INTO analysis_runs (user_id, organization_id, sync_pair_id, selected_columns, counts, ticket_ids,
+		                            started_at, duration_ms, created_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())
+		 RETURNING id, created_at`,
+		userID, orgID, syncPairID, run.SelectedColumns, countsJSON, ticketsJSON, run.StartedAt, run.DurationMs,
+	).Scan(&run.ID, &run.CreatedAt)
+	if err != nil {
+		return err
+	}
+	run.UserID = userID
+	run.SyncPairID = syncPairID
+
+	_, err = db.pool.Exec(ctx,
+		`DELETE FROM analysis_runs WHERE `+scopeClause+` AND sync_pair_id=$3 AND created_at < $4`,
+		userID, orgID, syncPairID, time.Now().Add(-analysisRunRetention),
+	)
+	return err
+}
+
+// GetAnalysisRuns lists a pair's runs since the given time, newest first,
+// without their ticket IDs
+func (db *DB) GetAnalysisRuns(userID, syncPairID int, since time.Time, limit int) ([]*AnalysisRun, error) {
+	ctx := context.Background()
+	rows, err := db.pool.Query(ctx,
+		`SELECT `+analysisRunColumns+` FROM analysis_runs
+		 WHERE `+scopeClause+` AND sync_pair_id=$3 AND created_at >= $4
+		 ORDER BY created_at DESC, id DESC LIMIT $5`,
+		userID, db.organizationIDFor(userID), syncPairID, since, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []*AnalysisRun
+	for rows.Next() {
+		r, err := scanAnalysisRun(rows, false)
+		if err != nil {
+			continue
+		}
+		runs = append(runs, r)
+	}
+	return runs, nil
+}
+
+// GetAnalysisRun loads one run with its ticket IDs. A runID of 0 loads the
+// pair's latest run.
+func (db *DB) GetAnalysisRun(userID, syncPairID, runID int) (*AnalysisRun, error) {
+	ctx := context.Background()
+	r, err := scanAnalysisRun(db.pool.QueryRow(ctx,
+		`SELECT `+analysisRunColumns+`, ticket_ids FROM analysis_runs
+		 WHERE `+scopeClause+` AND sync_pair_id=$3 AND ($4=0 OR id=$4)
+		 ORDER BY created_at DESC, id DESC LIMIT 1`,
+		userID, db.organizationIDFor(userID), syncPairID, runID,
+	), true)
+	if err != nil {
+		return nil, fmt.Errorf("analysis run not found")
+	}
+	return r, nil
+}
+
+// GetPreviousAnalysisRun loads the run before the given one that analyzed the
+// same columns, so the two are comparable
+func (db *DB) GetPreviousAnalysisRun(userID, syncPairID int, run *AnalysisRun) (*AnalysisRun, error) {
+	ctx := context.Background()
+	r, err := scanAnalysisRun(db.pool.QueryRow(ctx,
+		`SELECT `+analysisRunColumns+`, ticket_ids FROM analysis_runs
+		 WHERE `+scopeClause+` AND sync_pair_id=$3 AND selected_columns=$4 AND id < $5
+		 ORDER BY id DESC LIMIT 1`,
+		userID, db.organizationIDFor(userID), syncPairID, run.SelectedColumns, run.ID,
+	), true)
+	if err != nil {
+		return nil, fmt.Errorf("no earlier analysis run of the same columns")
+	}
+	return r, nil
+}
@@ -16,8 +16,9 @@ import (
 // All methods keep the same signatures as the old JSON-file implementation
 // so the rest of the codebase requires no changes.
 type DB struct {
-	pool  *pgxpool.Pool
-	mutex sync.RWMutex // kept for any in-memory helpers
+	pool        *pgxpool.Pool
+	mutex       sync.RWMutex // kept for any in-memory helpers
+	memberships map[int]cachedMembership
 }
 
 var database *DB
@@ -51,6 +52,15 @@ func InitDB(dbPath string) (*DB, error) {
 		return nil, fmt.Errorf("failed to connect to Neon database: %w", err)
 	}
 
+	keyring, err = loadCredentialKeyring()
+	if err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("invalid credential encryption key: %w", err)
+	}
+	if keyring == nil {
+		log.Println("WARNING: CREDENTIALS_ENCRYPTION_KEY not set — Asana/YouTrack tokens are stored unencrypted")
+	}
+
 	database = &DB{pool: pool}
 
 	if err := database.runMigrations(ctx); err != nil {
@@ -58,6 +68,11 @@ func InitDB(dbPath string) (*DB, error) {
 		return nil, fmt.Errorf("failed to run schema migrations: %w", err)
 	}
 
+	// Encrypt legacy plaintext credentials and rewrap any sealed with a rotated-out key
+	if _, err := database.ReencryptCredentials(); err != nil {
+		log.Printf("WARNING: credential re-encryption failed: %v\n", err)
+	}
+
 	log.Println("PostgreSQL/Neon database initialized successfully")
 	return database, nil
 }
@@ -73,6 +88,11 @@ func (db *DB) Close() error {
 	return nil
 }
 
+// PoolStats returns a snapshot of the connection pool's statistics
+func (db *DB) PoolStats() *pgxpool.Stat {
+	return db.pool.Stat()
+}
+
 // runMigrations creates all tables if they don't exist.
 func (db *DB) runMigrations(ctx context.Context) error {
 	schema := `
@@ -102,6 +122,43 @@ CREATE TABLE IF NOT EXISTS user_settings (
 
 ALTER TABLE user_settings ADD COLUMN IF NOT EXISTS sync_board_membership BOOLEAN NOT NULL DEFAULT false;
 
+CREATE TABLE IF NOT EXISTS organizations (
+    id                    SERIAL PRIMARY KEY,
+    name                  TEXT NOT NULL,
+    created_by            INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at            TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at            TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS organization_members (
+    id              SERIAL PRIMARY KEY,
+    organization_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    user_id         INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE UNIQUE,
+    role            TEXT NOT NULL DEFAULT 'operator',
+    joined_at       TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_organization_members_org_id ON organization_members(organization_id);
+
+-- Roles are viewer/operator/admin; "member" predates the viewer and operator split
+ALTER TABLE organization_members ALTER COLUMN role SET DEFAULT 'operator';
+UPDATE organization_members SET role='operator' WHERE role='member';
+
+CREATE TABLE IF NOT EXISTS user_sessions (
+    id                  SERIAL PRIMARY KEY,
+    user_id             INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    refresh_token_hash  TEXT NOT NULL UNIQUE,
+    previous_token_hash TEXT NOT NULL DEFAULT '',
+    user_agent          TEXT NOT NULL DEFAULT '',
+    ip_address          TEXT NOT NULL DEFAULT '',
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_used_at        TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at          TIMESTAMPTZ NOT NULL,
+    revoked_at          TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_sessions_user_id ON user_sessions(user_id);
+
 CREATE TABLE IF NOT EXISTS sync_operations (
     id               SERIAL PRIMARY KEY,
     user_id          INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
@@ -119,8 +176,7 @@ CREATE TABLE IF NOT EXISTS ignored_tickets (
     asana_project_id TEXT NOT NULL,
     ticket_id        TEXT NOT NULL,
     ignore_type      TEXT NOT NULL,
-    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
-    UNIQUE(user_id, asana_project_id, ticket_id)
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW()
 );
 
 CREATE TABLE IF NOT EXISTS ticket_mappings (
@@ -131,8 +187,7 @@ CREATE TABLE IF NOT EXISTS ticket_mappings (
     youtrack_project_id  TEXT NOT NULL,
     youtrack_issue_id    TEXT NOT NULL,
     created_at           TIMESTAMPTZ NOT NULL DEFAULT NOW(),
-    updated_at           TIMESTAMPTZ NOT NULL DEFAULT NOW(),
-    UNIQUE(user_id, asana_task_id)
+    updated_at           TIMESTAMPTZ NOT NULL DEFAULT NOW()
 );
 
 CREATE TABLE IF NOT EXISTS rollback_snapshots (
@@ -144,6 +199,19 @@ CREATE TABLE IF NOT EXISTS rollback_snapshots (
     expires_at    TIMESTAMPTZ NOT NULL
 );
 
+ALTER TABLE rollback_snapshots ADD COLUMN IF NOT EXISTS pinned BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE rollback_snapshots ADD COLUMN IF NOT EXISTS compacted BOOLEAN NOT NULL DEFAULT false;
+
+CREATE TABLE IF NOT EXISTS snapshot_retention_policies (
+    user_id             INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    max_age_hours       INTEGER NOT NULL DEFAULT 24,
+    max_count           INTEGER NOT NULL DEFAULT 15,
+    max_storage_bytes   BIGINT NOT NULL DEFAULT 0,
+    compact_after_hours INTEGER NOT NULL DEFAULT 6,
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at          TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
 CREATE TABLE IF NOT EXISTS audit_logs (
     id            SERIAL PRIMARY KEY,
     operation_id  INTEGER NOT NULL REFERENCES sync_operations(id) ON DELETE CASCADE,
@@ -163,8 +231,7 @@ CREATE TABLE IF NOT EXISTS reverse_ignored_tickets (
     youtrack_project_id  TEXT NOT NULL,
     ticket_id            TEXT NOT NULL,
     ignore_type          TEXT NOT NULL,
-    created_at           TIMESTAMPTZ NOT NULL DEFAULT NOW(),
-    UNIQUE(user_id, youtrack_project_id, ticket_id)
+    created_at           TIMESTAMPTZ NOT NULL DEFAULT NOW()
 );
 
 CREATE TABLE IF NOT EXISTS reverse_auto_create_settings (
@@ -177,6 +244,254 @@ CREATE TABLE IF NOT EXISTS reverse_auto_create_settings (
     created_at         TIMESTAMPTZ NOT NULL DEFAULT NOW(),
     updated_at         TIMESTAMPTZ NOT NULL DEFAULT NOW()
 );
+
+-- Ignores and ticket mappings are shared within an organization. Personal rows
+-- (organization_id NULL) stay unique per user, shared rows per organization.
+ALTER TABLE ignored_tickets ADD COLUMN IF NOT EXISTS organization_id INTEGER REFERENCES organizations(id) ON DELETE CASCADE;
+ALTER TABLE ticket_mappings ADD COLUMN IF NOT EXISTS organization_id INTEGER REFERENCES organizations(id) ON DELETE CASCADE;
+ALTER TABLE reverse_ignored_tickets ADD COLUMN IF NOT EXISTS organization_id INTEGER REFERENCES organizations(id) ON DELETE CASCADE;
+
+DO $$
+DECLARE c RECORD;
+BEGIN
+    FOR c IN
+        SELECT conrelid::regclass AS tbl, conname FROM pg_constraint
+        WHERE contype = 'u'
+          AND conrelid IN ('ignored_tickets'::regclass, 'ticket_mappings'::regclass, 'reverse_ignored_tickets'::regclass)
+    LOOP
+        EXECUTE format('ALTER TABLE %s DROP CONSTRAINT %I', c.tbl, c.conname);
+    END LOOP;
+END $$;
+
+CREATE UNIQUE INDEX IF NOT EXISTS ux_ticket_mappings_personal ON ticket_mappings(user_id, asana_task_id) WHERE organization_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS ux_ticket_mappings_org ON ticket_mappings(organization_id, asana_task_id) WHERE organization_id IS NOT NULL;
+
+-- Sync pairs are named Asana project <-> YouTrack project connections, each
+-- with its own board configuration and ignore lists. Every scope (user or
+-- organization) has one default pair, seeded from the single board
+-- configuration that predates pairs.
+CREATE TABLE IF NOT EXISTS sync_pairs (
+    id                    SERIAL PRIMARY KEY,
+    user_id               INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    organization_id       INTEGER REFERENCES organizations(id) ON DELETE CASCADE,
+    name                  TEXT NOT NULL,
+    asana_project_id      TEXT NOT NULL DEFAULT '',
+    youtrack_project_id   TEXT NOT NULL DEFAULT '',
+    youtrack_board_id     TEXT NOT NULL DEFAULT '',
+    sync_board_membership BOOLEAN NOT NULL DEFAULT false,
+    custom_field_mappings JSONB NOT NULL DEFAULT '{}',
+    column_mappings       JSONB NOT NULL DEFAULT '{}',
+    is_default            BOOLEAN NOT NULL DEFAULT false,
+    created_at            TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at            TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_sync_pairs_user_id ON sync_pairs(user_id);
+CREATE INDEX IF NOT EXISTS idx_sync_pairs_organization_id ON sync_pairs(organization_id);
+CREATE UNIQUE INDEX IF NOT EXISTS ux_sync_pairs_default_personal ON sync_pairs(user_id) WHERE is_default AND organization_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS ux_sync_pairs_default_org ON sync_pairs(organization_id) WHERE is_default AND organization_id IS NOT NULL;
+
+INSERT INTO sync_pairs (user_id, name, asana_project_id, youtrack_project_id, youtrack_board_id,
+                        sync_board_membership, custom_field_mappings, column_mappings, is_default)
+SELECT s.user_id, 'Default', s.asana_project_id, s.youtrack_project_id, s.youtrack_board_id,
+       s.sync_board_membership, s.custom_field_mappings, s.column_mappings, true
+FROM user_settings s
+WHERE NOT EXISTS (SELECT 1 FROM sync_pairs p WHERE p.user_id = s.user_id);
+
+-- Organizations used to carry the shared board configuration themselves
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM information_schema.columns
+               WHERE table_name = 'organizations' AND column_name = 'asana_project_id') THEN
+        INSERT INTO sync_pairs (user_id, organization_id, name, asana_project_id, youtrack_project_id, youtrack_board_id,
+                                sync_board_membership, custom_field_mappings, column_mappings, is_default)
+        SELECT owner.user_id, o.id, 'Default', o.asana_project_id, o.youtrack_project_id, o.youtrack_board_id,
+               o.sync_board_membership, o.custom_field_mappings, o.column_mappings, true
+        FROM organizations o
+        CROSS JOIN LATERAL (
+            SELECT COALESCE(o.created_by, (SELECT m.user_id FROM organization_members m
+                                           WHERE m.organization_id = o.id ORDER BY m.joined_at LIMIT 1)) AS user_id
+        ) owner
+        WHERE owner.user_id IS NOT NULL
+          AND NOT EXISTS (SELECT 1 FROM sync_pairs p WHERE p.organization_id = o.id);
+
+        ALTER TABLE organizations
+            DROP COLUMN asana_project_id,
+            DROP COLUMN youtrack_project_id,
+            DROP COLUMN youtrack_board_id,
+            DROP COLUMN sync_board_membership,
+            DROP COLUMN custom_field_mappings,
+            DROP COLUMN column_mappings;
+    END IF;
+END $$;
+
+-- Ignore lists belong to a sync pair. Existing ignores move to the default
+-- pair of their scope when it still points at the same project.
+ALTER TABLE ignored_tickets ADD COLUMN IF NOT EXISTS sync_pair_id INTEGER REFERENCES sync_pairs(id) ON DELETE CASCADE;
+ALTER TABLE reverse_ignored_tickets ADD COLUMN IF NOT EXISTS sync_pair_id INTEGER REFERENCES sync_pairs(id) ON DELETE CASCADE;
+
+UPDATE ignored_tickets i SET sync_pair_id = p.id
+FROM sync_pairs p
+WHERE i.sync_pair_id IS NULL AND p.is_default AND p.asana_project_id = i.asana_project_id
+  AND ((i.organization_id IS NULL AND p.organization_id IS NULL AND p.user_id = i.user_id)
+       OR p.organization_id = i.organization_id);
+UPDATE reverse_ignored_tickets i SET sync_pair_id = p.id
+FROM sync_pairs p
+WHERE i.sync_pair_id IS NULL AND p.is_default AND p.youtrack_project_id = i.youtrack_project_id
+  AND ((i.organization_id IS NULL AND p.organization_id IS NULL AND p.user_id = i.user_id)
+       OR p.organization_id = i.organization_id);
+
+DROP INDEX IF EXISTS ux_ignored_tickets_personal;
+DROP INDEX IF EXISTS ux_ignored_tickets_org;
+DROP INDEX IF EXISTS ux_reverse_ignored_tickets_personal;
+DROP INDEX IF EXISTS ux_reverse_ignored_tickets_org;
+
+CREATE UNIQUE INDEX IF NOT EXISTS ux_ignored_tickets_pair_personal ON ignored_tickets(user_id, sync_pair_id, ticket_id) WHERE organization_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS ux_ignored_tickets_pair_org ON ignored_tickets(organization_id, sync_pair_id, ticket_id) WHERE organization_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS ux_reverse_ignored_tickets_pair_personal ON reverse_ignored_tickets(user_id, sync_pair_id, ticket_id) WHERE organization_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS ux_reverse_ignored_tickets_pair_org ON reverse_ignored_tickets(organization_id, sync_pair_id, ticket_id) WHERE organization_id IS NOT NULL;
+
+-- Per-pair tuning of the ticket matching strategies used by analysis
+ALTER TABLE sync_pairs ADD COLUMN IF NOT EXISTS matching_config JSONB NOT NULL DEFAULT '{}';
+
+-- Candidate pairings analysis was unsure about, waiting for review. Rejected
+-- suggestions are kept as negative matches so the pair isn't offered again.
+CREATE TABLE IF NOT EXISTS match_suggestions (
+    id                SERIAL PRIMARY KEY,
+    user_id           INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    organization_id   INTEGER REFERENCES organizations(id) ON DELETE CASCADE,
+    sync_pair_id      INTEGER NOT NULL REFERENCES sync_pairs(id) ON DELETE CASCADE,
+    asana_task_id     TEXT NOT NULL,
+    asana_task_name   TEXT NOT NULL DEFAULT '',
+    youtrack_issue_id TEXT NOT NULL,
+    youtrack_summary  TEXT NOT NULL DEFAULT '',
+    match_method      TEXT NOT NULL,
+    score             DOUBLE PRECISION NOT NULL,
+    status            TEXT NOT NULL DEFAULT 'pending',
+    reviewed_by       INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_at       TIMESTAMPTZ,
+    created_at        TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at        TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_match_suggestions_pair_status ON match_suggestions(sync_pair_id, status);
+CREATE UNIQUE INDEX IF NOT EXISTS ux_match_suggestions_personal ON match_suggestions(user_id, sync_pair_id, asana_task_id, youtrack_issue_id) WHERE organization_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS ux_match_suggestions_org ON match_suggestions(organization_id, sync_pair_id, asana_task_id, youtrack_issue_id) WHERE organization_id IS NOT NULL;
+
+-- Compact summary of every analysis run: bucket counts and the ticket IDs in
+-- each bucket, kept for drift history and run-to-run diffs
+CREATE TABLE IF NOT EXISTS analysis_runs (
+    id               SERIAL PRIMARY KEY,
+    user_id          INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    organization_id  INTEGER REFERENCES organizations(id) ON DELETE CASCADE,
+    sync_pair_id     INTEGER NOT NULL REFERENCES sync_pairs(id) ON DELETE CASCADE,
+    selected_columns TEXT NOT NULL DEFAULT '',
+    counts           JSONB NOT NULL DEFAULT '{}',
+    ticket_ids       JSONB NOT NULL DEFAULT '{}',
+    started_at       TIMESTAMPTZ NOT NULL,
+    duration_ms      INTEGER NOT NULL DEFAULT 0,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_analysis_runs_pair_created ON analysis_runs(sync_pair_id, created_at DESC);
+
+-- Per-pair policy for YouTrack issues whose Asana task vanished
+ALTER TABLE sync_pairs ADD COLUMN IF NOT EXISTS orphan_policy JSONB NOT NULL DEFAULT '{}';
+
+-- YouTrack issues whose Asana task vanished, from when analysis first saw
+-- them until they are linked to a task again. The action taken on each, by
+-- the pair's orphan policy or by hand, is kept with it.
+CREATE TABLE IF NOT EXISTS orphaned_issues (
+    id                SERIAL PRIMARY KEY,
+    user_id           INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    organization_id   INTEGER REFERENCES organizations(id) ON DELETE CASCADE,
+    sync_pair_id      INTEGER NOT NULL REFERENCES sync_pairs(id) ON DELETE CASCADE,
+    youtrack_issue_id TEXT NOT NULL,
+    youtrack_summary  TEXT NOT NULL DEFAULT '',
+    asana_task_id     TEXT NOT NULL DEFAULT '',
+    first_seen_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_seen_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    action            TEXT NOT NULL DEFAULT '',
+    action_result     TEXT NOT NULL DEFAULT '',
+    action_error      TEXT NOT NULL DEFAULT '',
+    acted_at          TIMESTAMPTZ,
+    resolved_at       TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_orphaned_issues_pair_open ON orphaned_issues(sync_pair_id) WHERE resolved_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS ux_orphaned_issues_personal ON orphaned_issues(user_id, sync_pair_id, youtrack_issue_id) WHERE organization_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS ux_orphaned_issues_org ON orphaned_issues(organization_id, sync_pair_id, youtrack_issue_id) WHERE organization_id IS NOT NULL;
+
+-- Per-pair mapping of Asana completion to YouTrack resolved states
+ALTER TABLE sync_pairs ADD COLUMN IF NOT EXISTS completion_config JSONB NOT NULL DEFAULT '{}';
+
+-- Completion both sides of a mapping last agreed on, so sync can tell which
+-- side completed or reopened the ticket
+ALTER TABLE ticket_mappings ADD COLUMN IF NOT EXISTS completed BOOLEAN;
+
+-- Why an orphaned issue's Asana task vanished: deleted, removed or archived
+ALTER TABLE orphaned_issues ADD COLUMN IF NOT EXISTS reason TEXT NOT NULL DEFAULT '';
+
+-- Per-pair mirroring of YouTrack estimation and spent time to Asana fields
+ALTER TABLE sync_pairs ADD COLUMN IF NOT EXISTS time_tracking JSONB NOT NULL DEFAULT '{}';
+
+-- Per-pair mapping of Asana fields or sections to YouTrack sprints, and of
+-- Asana story points to YouTrack
+ALTER TABLE sync_pairs ADD COLUMN IF NOT EXISTS sprint_config JSONB NOT NULL DEFAULT '{}';
+
+-- Explicit links between Asana users and YouTrack users, shared by all sync
+-- pairs of a scope. Assignees, followers and watchers are translated through
+-- them instead of matching names.
+CREATE TABLE IF NOT EXISTS user_identities (
+    id               SERIAL PRIMARY KEY,
+    user_id          INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    organization_id  INTEGER REFERENCES organizations(id) ON DELETE CASCADE,
+    asana_user_gid   TEXT NOT NULL,
+    asana_name       TEXT NOT NULL DEFAULT '',
+    youtrack_user_id TEXT NOT NULL,
+    youtrack_login   TEXT NOT NULL DEFAULT '',
+    youtrack_name    TEXT NOT NULL DEFAULT '',
+    email            TEXT NOT NULL DEFAULT '',
+    source           TEXT NOT NULL DEFAULT 'manual',
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS ux_user_identities_asana_personal ON user_identities(user_id, asana_user_gid) WHERE organization_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS ux_user_identities_asana_org ON user_identities(organization_id, asana_user_gid) WHERE organization_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS ux_user_identities_youtrack_personal ON user_identities(user_id, youtrack_user_id) WHERE organization_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS ux_user_identities_youtrack_org ON user_identities(organization_id, youtrack_user_id) WHERE organization_id IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS directory_users (
+    id              SERIAL PRIMARY KEY,
+    user_id         INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    organization_id INTEGER REFERENCES organizations(id) ON DELETE CASCADE,
+    platform        TEXT NOT NULL,
+    external_id     TEXT NOT NULL,
+    name            TEXT NOT NULL DEFAULT '',
+    email           TEXT NOT NULL DEFAULT '',
+    login           TEXT NOT NULL DEFAULT '',
+    refreshed_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS ux_directory_users_personal ON directory_users(user_id, platform, external_id) WHERE organization_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS ux_directory_users_org ON directory_users(organization_id, platform, external_id) WHERE organization_id IS NOT NULL;
+
+-- Organization-scoped rows cascade with their organization; DeleteOrganization
+-- hands them back to their members first. The columns added along with
+-- organizations used to be set to NULL and get their foreign keys replaced.
+DO $$
+DECLARE
+    t TEXT;
+BEGIN
+    FOREACH t IN ARRAY ARRAY['ignored_tickets', 'ticket_mappings', 'reverse_ignored_tickets'] LOOP
+        IF EXISTS (SELECT 1 FROM pg_constraint WHERE conname = t || '_organization_id_fkey' AND confdeltype = 'n') THEN
+            EXECUTE format('ALTER TABLE %I DROP CONSTRAINT %I', t, t || '_organization_id_fkey');
+            EXECUTE format('ALTER TABLE %I ADD CONSTRAINT %I FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE',
+                           t, t || '_organization_id_fkey');
+        END IF;
+    END LOOP;
+END $$;
 `
 	_, err := db.pool.Exec(ctx, schema)
 	return err
@@ -315,7 +630,15 @@ func (db *DB) GetUserDataSummary(userID int) (map[string]int, error) {
 
 // ─── Settings Operations ──────────────────────────────────────────────────────
 
+// GetUserSettings returns the user's settings with the board configuration of
+// their default sync pair
 func (db *DB) GetUserSettings(userID int) (*UserSettings, error) {
+	return db.GetUserSettingsForPair(userID, 0)
+}
+
+// GetUserSettingsForPair returns the user's settings with the board
+// configuration of the given sync pair (0 for the default pair)
+func (db *DB) GetUserSettingsForPair(userID, pairID int) (*UserSettings, error) {
 	ctx := context.Background()
 	s := &UserSettings{}
 	var cfmJSON, cmJSON []byte
@@ -331,19 +654,36 @@ func (db *DB) GetUserSettings(userID int) (*UserSettings, error) {
 	if err != nil {
 		return nil, fmt.Errorf("settings not found")
 	}
+	if err := s.decryptCredentials(); err != nil {
+		return nil, err
+	}
 	json.Unmarshal(cfmJSON, &s.CustomFieldMappings)
 	json.Unmarshal(cmJSON, &s.ColumnMappings)
+	if err := db.applySyncPair(s, pairID); err != nil && pairID != 0 {
+		return nil, err
+	}
 	return s, nil
 }
 
-func (db *DB) UpdateUserSettings(userID int, asanaPAT, youtrackBaseURL, youtrackToken, asanaProjectID, youtrackProjectID, youtrackBoardID string, syncBoardMembership bool, mappings CustomFieldMappings, columnMappings ColumnMappings) (*UserSettings, error) {
+// UpdateUserSettings stores the user's credentials and writes the board
+// configuration to the given sync pair (0 for the default pair)
+func (db *DB) UpdateUserSettings(userID, pairID int, asanaPAT, youtrackBaseURL, youtrackToken, asanaProjectID, youtrackProjectID, youtrackBoardID string, syncBoardMembership bool, mappings CustomFieldMappings, columnMappings ColumnMappings) (*UserSettings, error) {
 	ctx := context.Background()
 	cfmJSON, _ := json.Marshal(mappings)
 	cmJSON, _ := json.Marshal(columnMappings)
 
+	encryptedPAT, err := encryptCredential(asanaPAT)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt Asana PAT: %w", err)
+	}
+	encryptedToken, err := encryptCredential(youtrackToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt YouTrack token: %w", err)
+	}
+
 	s := &UserSettings{}
 	var cfmOut, cmOut []byte
-	err := db.pool.QueryRow(ctx,
+	err = db.pool.QueryRow(ctx,
 		`UPDATE user_settings
 		 SET asana_pat=$1, youtrack_base_url=$2, youtrack_token=$3,
 		     asana_project_id=$4, youtrack_project_id=$5, youtrack_board_id=$6,
@@ -352,7 +692,7 @@ func (db *DB) UpdateUserSettings(userID int, asanaPAT, youtrackBaseURL, youtrack
 		 RETURNING id, user_id, asana_pat, youtrack_base_url, youtrack_token,
 		           asana_project_id, youtrack_project_id, youtrack_board_id,
 		           sync_board_membership, custom_field_mappings, column_mappings, created_at, updated_at`,
-		asanaPAT, youtrackBaseURL, youtrackToken,
+		encryptedPAT, youtrackBaseURL, encryptedToken,
 		asanaProjectID, youtrackProjectID, youtrackBoardID,
 		syncBoardMembership, cfmJSON, cmJSON, userID,
 	).Scan(&s.ID, &s.UserID, &s.AsanaPAT, &s.YouTrackBaseURL, &s.YouTrackToken,
@@ -361,8 +701,23 @@ func (db *DB) UpdateUserSettings(userID int, asanaPAT, youtrackBaseURL, youtrack
 	if err != nil {
 		return nil, fmt.Errorf("settings not found")
 	}
+	if err := s.decryptCredentials(); err != nil {
+		return nil, err
+	}
 	json.Unmarshal(cfmOut, &s.CustomFieldMappings)
 	json.Unmarshal(cmOut, &s.ColumnMappings)
+
+	// Organization admins edit the shared pairs; for other members the shared
+	// configuration overrides what they sent
+	if db.GetUserRole(userID) == RoleAdmin {
+		if err := db.updateSyncPairBoardConfig(userID, pairID, asanaProjectID, youtrackProjectID,
+			youtrackBoardID, syncBoardMembership, cfmJSON, cmJSON); err != nil {
+			return nil, fmt.Errorf("failed to update sync pair: %w", err)
+		}
+	}
+	if err := db.applySyncPair(s, pairID); err != nil && pairID != 0 {
+		return nil, err
+	}
 	return s, nil
 }
 
@@ -421,8 +776,8 @@ func (db *DB) GetUserOperations(userID int, limit int) ([]*SyncOperation, error)
 	ctx := context.Background()
 	rows, err := db.pool.Query(ctx,
 		`SELECT id, user_id, operation_type, operation_data, status, error_message, created_at, completed_at
-		 FROM sync_operations WHERE user_id=$1 ORDER BY created_at DESC LIMIT $2`,
-		userID, limit,
+		 FROM sync_operations WHERE user_id=$1 AND operation_type<>$3 ORDER BY created_at DESC LIMIT $2`,
+		userID, limit, permissionDeniedOperation,
 	)
 	if err != nil {
 		return nil, err
@@ -443,17 +798,28 @@ func (db *DB) GetUserOperations(userID int, limit int) ([]*SyncOperation, error)
 }
 
 // ─── Ignored Ticket Operations ────────────────────────────────────────────────
+//
+// Ignores and ticket mappings are shared by an organization's members. Every
+// query filters with scopeClause: $1 is the acting user, $2 their organization
+// (NULL for personal use). Ignore lists additionally belong to one sync pair.
 
-func (db *DB) AddIgnoredTicket(userID int, asanaProjectID, ticketID, ignoreType string) (*IgnoredTicket, error) {
+const scopeClause = `CASE WHEN $2::int IS NULL THEN organization_id IS NULL AND user_id=$1 ELSE organization_id=$2 END`
+
+func (db *DB) AddIgnoredTicket(userID, syncPairID int, asanaProjectID, ticketID, ignoreType string) (*IgnoredTicket, error) {
 	ctx := context.Background()
+	orgID := db.organizationIDFor(userID)
+	conflict := `(user_id, sync_pair_id, ticket_id) WHERE organization_id IS NULL`
+	if orgID != nil {
+		conflict = `(organization_id, sync_pair_id, ticket_id) WHERE organization_id IS NOT NULL`
+	}
 	t := &IgnoredTicket{}
 	err := db.pool.QueryRow(ctx,
-		`INSERT INTO ignored_tickets (user_id, asana_project_id, ticket_id, ignore_type, created_at)
-		 VALUES ($1, $2, $3, $4, NOW())
-		 ON CONFLICT (user_id, asana_project_id, ticket_id) DO UPDATE
+		`INSERT INTO ignored_tickets (user_id, organization_id, sync_pair_id, asana_project_id, ticket_id, ignore_type, created_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, NOW())
+		 ON CONFLICT `+conflict+` DO UPDATE
 		   SET ignore_type=EXCLUDED.ignore_type, created_at=NOW()
 		 RETURNING id, user_id, asana_project_id, ticket_id, ignore_type, created_at`,
-		userID, asanaProjectID, ticketID, ignoreType,
+		userID, orgID, syncPairID, asanaProjectID, ticketID, ignoreType,
 	).Scan(&t.ID, &t.UserID, &t.AsanaProjectID, &t.TicketID, &t.IgnoreType, &t.CreatedAt)
 	if err != nil {
 		return nil, err
@@ -461,24 +827,24 @@ func (db *DB) AddIgnoredTicket(userID int, asanaProjectID, ticketID, ignoreType
 	return t, nil
 }
 
-func (db *DB) RemoveIgnoredTicket(userID int, asanaProjectID, ticketID, ignoreType string) error {
+func (db *DB) RemoveIgnoredTicket(userID, syncPairID int, ticketID, ignoreType string) error {
 	ctx := context.Background()
-	query := `DELETE FROM ignored_tickets WHERE user_id=$1 AND asana_project_id=$2 AND ticket_id=$3`
-	args := []interface{}{userID, asanaProjectID, ticketID}
+	query := `DELETE FROM ignored_tickets WHERE ` + scopeClause + ` AND sync_pair_id=$3 AND ticket_id=$4`
+	args := []interface{}{userID, db.organizationIDFor(userID), syncPairID, ticketID}
 	if ignoreType != "" {
-		query += ` AND ignore_type=$4`
+		query += ` AND ignore_type=$5`
 		args = append(args, ignoreType)
 	}
 	_, err := db.pool.Exec(ctx, query, args...)
 	return err
 }
 
-func (db *DB) GetIgnoredTickets(userID int, asanaProjectID string) ([]*IgnoredTicket, error) {
+func (db *DB) GetIgnoredTickets(userID, syncPairID int) ([]*IgnoredTicket, error) {
 	ctx := context.Background()
 	rows, err := db.pool.Query(ctx,
 		`SELECT id, user_id, asana_project_id, ticket_id, ignore_type, created_at
-		 FROM ignored_tickets WHERE user_id=$1 AND asana_project_id=$2`,
-		userID, asanaProjectID,
+		 FROM ignored_tickets WHERE `+scopeClause+` AND sync_pair_id=$3`,
+		userID, db.organizationIDFor(userID), syncPairID,
 	)
 	if err != nil {
 		return nil, err
@@ -496,12 +862,12 @@ func (db *DB) GetIgnoredTickets(userID int, asanaProjectID string) ([]*IgnoredTi
 	return tickets, nil
 }
 
-func (db *DB) IsTicketIgnored(userID int, asanaProjectID, ticketID string) (bool, string) {
+func (db *DB) IsTicketIgnored(userID, syncPairID int, ticketID string) (bool, string) {
 	ctx := context.Background()
 	var ignoreType string
 	err := db.pool.QueryRow(ctx,
-		`SELECT ignore_type FROM ignored_tickets WHERE user_id=$1 AND asana_project_id=$2 AND ticket_id=$3`,
-		userID, asanaProjectID, ticketID,
+		`SELECT ignore_type FROM ignored_tickets WHERE `+scopeClause+` AND sync_pair_id=$3 AND ticket_id=$4`,
+		userID, db.organizationIDFor(userID), syncPairID, ticketID,
 	).Scan(&ignoreType)
 	if err != nil {
 		return false, ""
@@ -509,18 +875,19 @@ func (db *DB) IsTicketIgnored(userID int, asanaProjectID, ticketID string) (bool
 	return true, ignoreType
 }
 
-func (db *DB) ClearIgnoredTickets(userID int, asanaProjectID, ignoreType string) error {
+func (db *DB) ClearIgnoredTickets(userID, syncPairID int, ignoreType string) error {
 	ctx := context.Background()
+	orgID := db.organizationIDFor(userID)
 	if ignoreType != "" {
 		_, err := db.pool.Exec(ctx,
-			`DELETE FROM ignored_tickets WHERE user_id=$1 AND asana_project_id=$2 AND ignore_type=$3`,
-			userID, asanaProjectID, ignoreType,
+			`DELETE FROM ignored_tickets WHERE `+scopeClause+` AND sync_pair_id=$3 AND ignore_type=$4`,
+			userID, orgID, syncPairID, ignoreType,
 		)
 		return err
 	}
 	_, err := db.pool.Exec(ctx,
-		`DELETE FROM ignored_tickets WHERE user_id=$1 AND asana_project_id=$2`,
-		userID, asanaProjectID,
+		`DELETE FROM ignored_tickets WHERE `+scopeClause+` AND sync_pair_id=$3`,
+		userID, orgID, syncPairID,
 	)
 	return err
 }
@@ -529,17 +896,23 @@ func (db *DB) ClearIgnoredTickets(userID int, asanaProjectID, ignoreType string)
 
 func (db *DB) CreateTicketMapping(userID int, asanaProjectID, asanaTaskID, youtrackProjectID, youtrackIssueID string) (*TicketMapping, error) {
 	ctx := context.Background()
+	orgID := db.organizationIDFor(userID)
+	conflict := `(user_id, asana_task_id) WHERE organization_id IS NULL`
+	if orgID != nil {
+		conflict = `(organization_id, asana_task_id) WHERE organization_id IS NOT NULL`
+	}
 	m := &TicketMapping{}
 	err := db.pool.QueryRow(ctx,
-		`INSERT INTO ticket_mappings (user_id, asana_project_id, asana_task_id, youtrack_project_id, youtrack_issue_id, created_at, updated_at)
-		 VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
-		 ON CONFLICT (user_id, asana_task_id) DO UPDATE
+		`INSERT INTO ticket_mappings (user_id, organization_id, asana_project_id, asana_task_id, youtrack_project_id, youtrack_issue_id, created_at, updated_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
+		 ON CONFLICT `+conflict+` DO UPDATE
 		   SET youtrack_project_id=EXCLUDED.youtrack_project_id,
 		       youtrack_issue_id=EXCLUDED.youtrack_issue_id,
+		       completed=CASE WHEN ticket_mappings.youtrack_issue_id=EXCLUDED.youtrack_issue_id THEN ticket_mappings.completed END,
 		       updated_at=NOW()
-		 RETURNING id, user_id, asana_project_id, asana_task_id, youtrack_project_id, youtrack_issue_id, created_at, updated_at`,
-		userID, asanaProjectID, asanaTaskID, youtrackProjectID, youtrackIssueID,
-	).Scan(&m.ID, &m.UserID, &m.AsanaProjectID, &m.AsanaTaskID, &m.YouTrackProjectID, &m.YouTrackIssueID, &m.CreatedAt, &m.UpdatedAt)
+		 RETURNING id, user_id, asana_project_id, asana_task_id, youtrack_project_id, youtrack_issue_id, completed, created_at, updated_at`,
+		userID, orgID, asanaProjectID, asanaTaskID, youtrackProjectID, youtrackIssueID,
+	).Scan(&m.ID, &m.UserID, &m.AsanaProjectID, &m.AsanaTaskID, &m.YouTrackProjectID, &m.YouTrackIssueID, &m.Completed, &m.CreatedAt, &m.UpdatedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -551,10 +924,10 @@ func (db *DB) GetTicketMappingByAsanaID(userID int, asanaTaskID string) (*Ticket
 	ctx := context.Background()
 	m := &TicketMapping{}
 	err := db.pool.QueryRow(ctx,
-		`SELECT id, user_id, asana_project_id, asana_task_id, youtrack_project_id, youtrack_issue_id, created_at, updated_at
-		 FROM ticket_mappings WHERE user_id=$1 AND asana_task_id=$2`,
-		userID, asanaTaskID,
-	).Scan(&m.ID, &m.UserID, &m.AsanaProjectID, &m.AsanaTaskID, &m.YouTrackProjectID, &m.YouTrackIssueID, &m.CreatedAt, &m.UpdatedAt)
+		`SELECT id, user_id, asana_project_id, asana_task_id, youtrack_project_id, youtrack_issue_id, completed, created_at, updated_at
+		 FROM ticket_mappings WHERE `+scopeClause+` AND asana_task_id=$3`,
+		userID, db.organizationIDFor(userID), asanaTaskID,
+	).Scan(&m.ID, &m.UserID, &m.AsanaProjectID, &m.AsanaTaskID, &m.YouTrackProjectID, &m.YouTrackIssueID, &m.Completed, &m.CreatedAt, &m.UpdatedAt)
 	if err != nil {
 		return nil, fmt.Errorf("mapping not found for Asana task %s", asanaTaskID)
 	}
@@ -565,10 +938,10 @@ func (db *DB) GetTicketMappingByYouTrackID(userID int, youtrackIssueID string) (
 	ctx := context.Background()
 	m := &TicketMapping{}
 	err := db.pool.QueryRow(ctx,
-		`SELECT id, user_id, asana_project_id, asana_task_id, youtrack_project_id, youtrack_issue_id, created_at, updated_at
-		 FROM ticket_mappings WHERE user_id=$1 AND youtrack_issue_id=$2`,
-		userID, youtrackIssueID,
-	).Scan(&m.ID, &m.UserID, &m.AsanaProjectID, &m.AsanaTaskID, &m.YouTrackProjectID, &m.YouTrackIssueID, &m.CreatedAt, &m.UpdatedAt)
+		`SELECT id, user_id, asana_project_id, asana_task_id, youtrack_project_id, youtrack_issue_id, completed, created_at, updated_at
+		 FROM ticket_mappings WHERE `+scopeClause+` AND youtrack_issue_id=$3`,
+		userID, db.organizationIDFor(userID), youtrackIssueID,
+	).Scan(&m.ID, &m.UserID, &m.AsanaProjectID, &m.AsanaTaskID, &m.YouTrackProjectID, &m.YouTrackIssueID, &m.Completed, &m.CreatedAt, &m.UpdatedAt)
 	if err != nil {
 		return nil, fmt.Errorf("mapping not found for YouTrack issue %s", youtrackIssueID)
 	}
@@ -578,9 +951,9 @@ func (db *DB) GetTicketMappingByYouTrackID(userID int, youtrackIssueID string) (
 func (db *DB) GetAllTicketMappings(userID int) ([]*TicketMapping, error) {
 	ctx := context.Background()
 	rows, err := db.pool.Query(ctx,
-		`SELECT id, user_id, asana_project_id, asana_task_id, youtrack_project_id, youtrack_issue_id, created_at, updated_at
-		 FROM ticket_mappings WHERE user_id=$1`,
-		userID,
+		`SELECT id, user_id, asana_project_id, asana_task_id, youtrack_project_id, youtrack_issue_id, completed, created_at, updated_at
+		 FROM ticket_mappings WHERE `+scopeClause,
+		userID, db.organizationIDFor(userID),
 	)
 	if err != nil {
 		return nil, err
@@ -590,7 +963,7 @@ func (db *DB) GetAllTicketMappings(userID int) ([]*TicketMapping, error) {
 	var mappings []*TicketMapping
 	for rows.Next() {
 		m := &TicketMapping{}
-		if err := rows.Scan(&m.ID, &m.UserID, &m.AsanaProjectID, &m.AsanaTaskID, &m.YouTrackProjectID, &m.YouTrackIssueID, &m.CreatedAt, &m.UpdatedAt); err != nil {
+		if err := rows.Scan(&m.ID, &m.UserID, &m.AsanaProjectID, &m.AsanaTaskID, &m.YouTrackProjectID, &m.YouTrackIssueID, &m.Completed, &m.CreatedAt, &m.UpdatedAt); err != nil {
 			continue
 		}
 		mappings = append(mappings, m)
@@ -601,8 +974,25 @@ func (db *DB) GetAllTicketMappings(userID int) ([]*TicketMapping, error) {
 func (db *DB) DeleteTicketMapping(userID, mappingID int) error {
 	ctx := context.Background()
 	result, err := db.pool.Exec(ctx,
-		`DELETE FROM ticket_mappings WHERE id=$1 AND user_id=$2`,
-		mappingID, userID,
+		`DELETE FROM ticket_mappings WHERE `+scopeClause+` AND id=$3`,
+		userID, db.organizationIDFor(userID), mappingID,
+	)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("mapping not found or access denied")
+	}
+	return nil
+}
+
+// SetTicketMappingCompleted records the completion both sides of a mapping
+// agree on
+func (db *DB) SetTicketMappingCompleted(userID, mappingID int, completed bool) error {
+	ctx := context.Background()
+	result, err := db.pool.Exec(ctx,
+		`UPDATE ticket_mappings SET completed=$4 WHERE `+scopeClause+` AND id=$3`,
+		userID, db.organizationIDFor(userID), mappingID, completed,
 	)
 	if err != nil {
 		return err
@@ -617,24 +1007,47 @@ func (db *DB) HasTicketMapping(userID int, asanaTaskID, youtrackIssueID string)
 	ctx := context.Background()
 	var exists bool
 	db.pool.QueryRow(ctx,
-		`SELECT EXISTS(SELECT 1 FROM ticket_mappings WHERE user_id=$1 AND asana_task_id=$2 AND youtrack_issue_id=$3)`,
-		userID, asanaTaskID, youtrackIssueID,
+		`SELECT EXISTS(SELECT 1 FROM ticket_mappings WHERE `+scopeClause+` AND asana_task_id=$3 AND youtrack_issue_id=$4)`,
+		userID, db.organizationIDFor(userID), asanaTaskID, youtrackIssueID,
 	).Scan(&exists)
 	return exists
 }
 
+// ReassignTicketMappings points the mappings of one YouTrack issue at another,
+// e.g. after merging a duplicate into it
+func (db *DB) ReassignTicketMappings(userID int, fromIssueID, toIssueID string) (int64, error) {
+	ctx := context.Background()
+	result, err := db.pool.Exec(ctx,
+		`UPDATE ticket_mappings SET youtrack_issue_id=$4, completed=NULL, updated_at=NOW()
+		 WHERE `+scopeClause+` AND youtrack_issue_id=$3`,
+		userID, db.organizationIDFor(userID), fromIssueID, toIssueID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	if result.RowsAffected() > 0 {
+		log.Printf("DB: Reassigned %d ticket mappings from %s to %s for user %d\n", result.RowsAffected(), fromIssueID, toIssueID, userID)
+	}
+	return result.RowsAffected(), nil
+}
+
 // ─── Reverse Ignored Ticket Operations ───────────────────────────────────────
 
-func (db *DB) AddReverseIgnoredTicket(userID int, youtrackProjectID, ticketID, ignoreType string) (*ReverseIgnoredTicket, error) {
+func (db *DB) AddReverseIgnoredTicket(userID, syncPairID int, youtrackProjectID, ticketID, ignoreType string) (*ReverseIgnoredTicket, error) {
 	ctx := context.Background()
+	orgID := db.organizationIDFor(userID)
+	conflict := `(user_id, sync_pair_id, ticket_id) WHERE organization_id IS NULL`
+	if orgID != nil {
+		conflict = `(organization_id, sync_pair_id, ticket_id) WHERE organization_id IS NOT NULL`
+	}
 	t := &ReverseIgnoredTicket{}
 	err := db.pool.QueryRow(ctx,
-		`INSERT INTO reverse_ignored_tickets (user_id, youtrack_project_id, ticket_id, ignore_type, created_at)
-		 VALUES ($1, $2, $3, $4, NOW())
-		 ON CONFLICT (user_id, youtrack_project_id, ticket_id) DO UPDATE
+		`INSERT INTO reverse_ignored_tickets (user_id, organization_id, sync_pair_id, youtrack_project_id, ticket_id, ignore_type, created_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, NOW())
+		 ON CONFLICT `+conflict+` DO UPDATE
 		   SET ignore_type=EXCLUDED.ignore_type
 		 RETURNING id, user_id, youtrack_project_id, ticket_id, ignore_type, created_at`,
-		userID, youtrackProjectID, ticketID, ignoreType,
+		userID, orgID, syncPairID, youtrackProjectID, ticketID, ignoreType,
 	).Scan(&t.ID, &t.UserID, &t.YouTrackProjectID, &t.TicketID, &t.IgnoreType, &t.CreatedAt)
 	if err != nil {
 		return nil, err
@@ -642,24 +1055,24 @@ func (db *DB) AddReverseIgnoredTicket(userID int, youtrackProjectID, ticketID, i
 	return t, nil
 }
 
-func (db *DB) RemoveReverseIgnoredTicket(userID int, youtrackProjectID, ticketID, ignoreType string) error {
+func (db *DB) RemoveReverseIgnoredTicket(userID, syncPairID int, ticketID, ignoreType string) error {
 	ctx := context.Background()
-	query := `DELETE FROM reverse_ignored_tickets WHERE user_id=$1 AND youtrack_project_id=$2 AND ticket_id=$3`
-	args := []interface{}{userID, youtrackProjectID, ticketID}
+	query := `DELETE FROM reverse_ignored_tickets WHERE ` + scopeClause + ` AND sync_pair_id=$3 AND ticket_id=$4`
+	args := []interface{}{userID, db.organizationIDFor(userID), syncPairID, ticketID}
 	if ignoreType != "" {
-		query += ` AND ignore_type=$4`
+		query += ` AND ignore_type=$5`
 		args = append(args, ignoreType)
 	}
 	_, err := db.pool.Exec(ctx, query, args...)
 	return err
 }
 
-func (db *DB) GetReverseIgnoredTickets(userID int, youtrackProjectID string) ([]*ReverseIgnoredTicket, error) {
+func (db *DB) GetReverseIgnoredTickets(userID, syncPairID int) ([]*ReverseIgnoredTicket, error) {
 	ctx := context.Background()
 	rows, err := db.pool.Query(ctx,
 		`SELECT id, user_id, youtrack_project_id, ticket_id, ignore_type, created_at
-		 FROM reverse_ignored_tickets WHERE user_id=$1 AND youtrack_project_id=$2`,
-		userID, youtrackProjectID,
+		 FROM reverse_ignored_tickets WHERE `+scopeClause+` AND sync_pair_id=$3`,
+		userID, db.organizationIDFor(userID), syncPairID,
 	)
 	if err != nil {
 		return nil, err
@@ -677,12 +1090,12 @@ func (db *DB) GetReverseIgnoredTickets(userID int, youtrackProjectID string) ([]
 	return tickets, nil
 }
 
-func (db *DB) IsReverseTicketIgnored(userID int, youtrackProjectID, ticketID string) (bool, string) {
+func (db *DB) IsReverseTicketIgnored(userID, syncPairID int, ticketID string) (bool, string) {
 	ctx := context.Background()
 	var ignoreType string
 	err := db.pool.QueryRow(ctx,
-		`SELECT ignore_type FROM reverse_ignored_tickets WHERE user_id=$1 AND youtrack_project_id=$2 AND ticket_id=$3`,
-		userID, youtrackProjectID, ticketID,
+		`SELECT ignore_type FROM reverse_ignored_tickets WHERE `+scopeClause+` AND sync_pair_id=$3 AND ticket_id=$4`,
+		userID, db.organizationIDFor(userID), syncPairID, ticketID,
 	).Scan(&ignoreType)
 	if err != nil {
 		return false, ""
@@ -690,18 +1103,19 @@ func (db *DB) IsReverseTicketIgnored(userID int, youtrackProjectID, ticketID str
 	return true, ignoreType
 }
 
-func (db *DB) ClearReverseIgnoredTickets(userID int, youtrackProjectID, ignoreType string) error {
+func (db *DB) ClearReverseIgnoredTickets(userID, syncPairID int, ignoreType string) error {
 	ctx := context.Background()
+	orgID := db.organizationIDFor(userID)
 	if ignoreType != "" {
 		_, err := db.pool.Exec(ctx,
-			`DELETE FROM reverse_ignored_tickets WHERE user_id=$1 AND youtrack_project_id=$2 AND ignore_type=$3`,
-			userID, youtrackProjectID, ignoreType,
+			`DELETE FROM reverse_ignored_tickets WHERE `+scopeClause+` AND sync_pair_id=$3 AND ignore_type=$4`,
+			userID, orgID, syncPairID, ignoreType,
 		)
 		return err
 	}
 	_, err := db.pool.Exec(ctx,
-		`DELETE FROM reverse_ignored_tickets WHERE user_id=$1 AND youtrack_project_id=$2`,
-		userID, youtrackProjectID,
+		`DELETE FROM reverse_ignored_tickets WHERE `+scopeClause+` AND sync_pair_id=$3`,
+		userID, orgID, syncPairID,
 	)
 	return err
 }
@@ -0,0 +1,290 @@
+package database
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+)
+
+// Credentials (Asana PAT, YouTrack token) are stored with envelope encryption:
+// every value gets its own random data key (DEK), the value is sealed with the
+// DEK using AES-256-GCM, and the DEK is sealed with a key-encryption key (KEK)
+// taken from the environment. Stored format:
+//
+//	enc:v1:<kek-id>:<base64 wrapped DEK>:<base64 ciphertext>
+//
+// Key rotation: set CREDENTIALS_ENCRYPTION_KEY to the new key (with a new
+// CREDENTIALS_ENCRYPTION_KEY_ID) and move the previous key into
+// CREDENTIALS_PREVIOUS_KEYS as "id:base64key[,id:base64key...]". On startup
+// ReencryptCredentials rewraps every value under the current key.
+
+const (
+	encryptedPrefix    = "enc:v1:"
+	defaultKeyID       = "k1"
+	credentialKeyBytes = 32
+)
+
+// credentialKeyring holds the current KEK and any previous KEKs kept for decryption
+type credentialKeyring struct {
+	currentID string
+	keys      map[string][]byte
+}
+
+var keyring *credentialKeyring
+
+// loadCredentialKeyring reads the KEKs from the environment.
+// Without CREDENTIALS_ENCRYPTION_KEY, credentials are stored in plaintext.
+func loadCredentialKeyring() (*credentialKeyring, error) {
+	current := os.Getenv("CREDENTIALS_ENCRYPTION_KEY")
+	if current == "" {
+		return nil, nil
+	}
+
+	kr := &credentialKeyring{
+		currentID: getEnvDefault("CREDENTIALS_ENCRYPTION_KEY_ID", defaultKeyID),
+		keys:      make(map[string][]byte),
+	}
+
+	key, err := decodeCredentialKey(current)
+	if err != nil {
+		return nil, fmt.Errorf("CREDENTIALS_ENCRYPTION_KEY: %w", err)
+	}
+	kr.keys[kr.currentID] = key
+
+	if previous := os.Getenv("CREDENTIALS_PREVIOUS_KEYS"); previous != "" {
+		for _, entry := range strings.Split(previous, ",") {
+			parts := strings.SplitN(strings.TrimSpace(entry), ":", 2)
+			if len(parts) != 2 || parts[0] == "" {
+				return nil, fmt.Errorf("CREDENTIALS_PREVIOUS_KEYS: expected id:base64key, got %q", entry)
+			}
+			if parts[0] == kr.currentID {
+				return nil, fmt.Errorf("CREDENTIALS_PREVIOUS_KEYS: key id %q is already the current key", parts[0])
+			}
+			oldKey, err := decodeCredentialKey(parts[1])
+			if err != nil {
+				return nil, fmt.Errorf("CREDENTIALS_PREVIOUS_KEYS[%s]: %w", parts[0], err)
+			}
+			kr.keys[parts[0]] = oldKey
+		}
+	}
+
+	return kr, nil
+}
+
+func decodeCredentialKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("key must be base64: %w", err)
+	}
+	if len(key) != credentialKeyBytes {
+		return nil, fmt.Errorf("key must be %d bytes, got %d", credentialKeyBytes, len(key))
+	}
+	return key, nil
+}
+
+// isEncryptedCredential reports whether a stored value is in envelope format
+func isEncryptedCredential(value string) bool {
+	return strings.HasPrefix(value, encryptedPrefix)
+}
+
+// credentialKeyID returns the KEK id a stored value was sealed with ("" for plaintext)
+func credentialKeyID(value string) string {
+	if !isEncryptedCredential(value) {
+		return ""
+	}
+	parts := strings.SplitN(strings.TrimPrefix(value, encryptedPrefix), ":", 2)
+	return parts[0]
+}
+
+// encryptCredential seals a plaintext credential for storage
+func encryptCredential(plaintext string) (string, error) {
+	if plaintext == "" || keyring == nil {
+		return plaintext, nil
+	}
+
+	dek := make([]byte, credentialKeyBytes)
+	if _, err := io.ReadFull(rand.Reader, dek); err != nil {
+		return "", err
+	}
+
+	ciphertext, err := sealGCM(dek, []byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+	wrappedDEK, err := sealGCM(keyring.keys[keyring.currentID], dek)
+	if err != nil {
+		return "", err
+	}
+
+	return encryptedPrefix + keyring.currentID + ":" +
+		base64.StdEncoding.EncodeToString(wrappedDEK) + ":" +
+		base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+// decryptCredential opens a stored credential. Plaintext values (written
+// before encryption was enabled) are returned unchanged.
+func decryptCredential(stored string) (string, error) {
+	if !isEncryptedCredential(stored) {
+		return stored, nil
+	}
+	if keyring == nil {
+		return "", fmt.Errorf("credential is encrypted but CREDENTIALS_ENCRYPTION_KEY is not set")
+	}
+
+	parts := strings.Split(strings.TrimPrefix(stored, encryptedPrefix), ":")
+	if len(parts) != 3 {
+		return "", fmt.Errorf("malformed encrypted credential")
+	}
+
+	kek, ok := keyring.keys[parts[0]]
+	if !ok {
+		return "", fmt.Errorf("unknown credential key id %q", parts[0])
+	}
+	wrappedDEK, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", fmt.Errorf("malformed encrypted credential: %w", err)
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", fmt.Errorf("malformed encrypted credential: %w", err)
+	}
+
+	dek, err := openGCM(kek, wrappedDEK)
+	if err != nil {
+		return "", fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+	plaintext, err := openGCM(dek, ciphertext)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt credential: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+// sealGCM encrypts with AES-256-GCM and prepends the nonce
+func sealGCM(key, plaintext []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// openGCM reverses sealGCM
+func openGCM(key, sealed []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
+
+// decryptCredentials replaces the stored (possibly encrypted) credentials with plaintext
+func (s *UserSettings) decryptCredentials() error {
+	asanaPAT, err := decryptCredential(s.AsanaPAT)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt Asana PAT: %w", err)
+	}
+	youtrackToken, err := decryptCredential(s.YouTrackToken)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt YouTrack token: %w", err)
+	}
+	s.AsanaPAT = asanaPAT
+	s.YouTrackToken = youtrackToken
+	return nil
+}
+
+// ReencryptCredentials encrypts plaintext credentials and rewraps values
+// sealed with a previous key under the current key. It returns the number of
+// settings rows rewritten.
+func (db *DB) ReencryptCredentials() (int, error) {
+	if keyring == nil {
+		return 0, nil
+	}
+	ctx := context.Background()
+
+	rows, err := db.pool.Query(ctx, `SELECT user_id, asana_pat, youtrack_token FROM user_settings`)
+	if err != nil {
+		return 0, err
+	}
+	type storedCredentials struct {
+		userID        int
+		asanaPAT      string
+		youtrackToken string
+	}
+	var pending []storedCredentials
+	for rows.Next() {
+		var c storedCredentials
+		if err := rows.Scan(&c.userID, &c.asanaPAT, &c.youtrackToken); err != nil {
+			continue
+		}
+		if needsReencryption(c.asanaPAT) || needsReencryption(c.youtrackToken) {
+			pending = append(pending, c)
+		}
+	}
+	rows.Close()
+
+	updated := 0
+	for _, c := range pending {
+		asanaPAT, err := reencryptCredential(c.asanaPAT)
+		if err != nil {
+			log.Printf("DB: Warning: cannot re-encrypt Asana PAT for user %d: %v\n", c.userID, err)
+			continue
+		}
+		youtrackToken, err := reencryptCredential(c.youtrackToken)
+		if err != nil {
+			log.Printf("DB: Warning: cannot re-encrypt YouTrack token for user %d: %v\n", c.userID, err)
+			continue
+		}
+		if _, err := db.pool.Exec(ctx,
+			`UPDATE user_settings SET asana_pat=$1, youtrack_token=$2 WHERE user_id=$3`,
+			asanaPAT, youtrackToken, c.userID,
+		); err != nil {
+			log.Printf("DB: Warning: failed to store re-encrypted credentials for user %d: %v\n", c.userID, err)
+			continue
+		}
+		updated++
+	}
+
+	if updated > 0 {
+		log.Printf("DB: Re-encrypted credentials for %d users under key %q\n", updated, keyring.currentID)
+	}
+	return updated, nil
+}
+
+func needsReencryption(stored string) bool {
+	return stored != "" && credentialKeyID(stored) != keyring.currentID
+}
+
+func reencryptCredential(stored string) (string, error) {
+	if !needsReencryption(stored) {
+		return stored, nil
+	}
+	plaintext, err := decryptCredential(stored)
+	if err != nil {
+		return "", err
+	}
+	return encryptCredential(plaintext)
+}
@@ -0,0 +1,182 @@
+package database
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+// useKeyring swaps the package keyring for the duration of a test
+func useKeyring(t *testing.T, kr *credentialKeyring) {
+	t.Helper()
+	previous := keyring
+	keyring = kr
+	t.Cleanup(func() { keyring = previous })
+}
+
+func testKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, credentialKeyBytes)
+}
+
+func TestCredentialRoundTrip(t *testing.T) {
+	useKeyring(t, &credentialKeyring{currentID: "k1", keys: map[string][]byte{"k1": testKey(1)}})
+
+	tests := []struct {
+		name      string
+		plaintext string
+	}{
+		{"asana pat", "1/1234567890:abcdefabcdefabcdef"},
+		{"youtrack token", "perm:dXNlcg==.NDItMQ==.abcdef"},
+		{"unicode", "tökén ✓"},
+		{"colons", "a:b:c"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stored, err := encryptCredential(tt.plaintext)
+			if err != nil {
+				t.Fatalf("encryptCredential: %v", err)
+			}
+			if !isEncryptedCredential(stored) || strings.Contains(stored, tt.plaintext) {
+				t.Fatalf("stored value %q is not sealed", stored)
+			}
+			if id := credentialKeyID(stored); id != "k1" {
+				t.Errorf("credentialKeyID = %q, want %q", id, "k1")
+			}
+
+			got, err := decryptCredential(stored)
+			if err != nil {
+				t.Fatalf("decryptCredential: %v", err)
+			}
+			if got != tt.plaintext {
+				t.Errorf("decryptCredential = %q, want %q", got, tt.plaintext)
+			}
+		})
+	}
+}
+
+func TestCredentialFreshDataKeys(t *testing.T) {
+	useKeyring(t, &credentialKeyring{currentID: "k1", keys: map[string][]byte{"k1": testKey(1)}})
+
+	a, err := encryptCredential("same secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := encryptCredential("same secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a == b {
+		t.Error("encrypting the same value twice produced the same ciphertext")
+	}
+}
+
+func TestCredentialWrongKey(t *testing.T) {
+	useKeyring(t, &credentialKeyring{currentID: "k1", keys: map[string][]byte{"k1": testKey(1)}})
+	stored, err := encryptCredential("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		keyring *credentialKeyring
+		wantErr string
+	}{
+		{
+			name:    "different key under the same id",
+			keyring: &credentialKeyring{currentID: "k1", keys: map[string][]byte{"k1": testKey(2)}},
+			wantErr: "failed to unwrap data key",
+		},
+		{
+			name:    "unknown key id",
+			keyring: &credentialKeyring{currentID: "k2", keys: map[string][]byte{"k2": testKey(1)}},
+			wantErr: `unknown credential key id "k1"`,
+		},
+		{
+			name:    "no keyring",
+			keyring: nil,
+			wantErr: "CREDENTIALS_ENCRYPTION_KEY is not set",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useKeyring(t, tt.keyring)
+			got, err := decryptCredential(stored)
+			if err == nil {
+				t.Fatalf("decryptCredential = %q, want error", got)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %q, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCredentialTampered(t *testing.T) {
+	useKeyring(t, &credentialKeyring{currentID: "k1", keys: map[string][]byte{"k1": testKey(1)}})
+	stored, err := encryptCredential("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parts := strings.Split(stored, ":")
+	ciphertext, err := base64.StdEncoding.DecodeString(parts[len(parts)-1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	ciphertext[len(ciphertext)-1] ^= 0xff
+	parts[len(parts)-1] = base64.StdEncoding.EncodeToString(ciphertext)
+
+	if got, err := decryptCredential(strings.Join(parts, ":")); err == nil {
+		t.Fatalf("decryptCredential = %q, want error", got)
+	}
+	if _, err := decryptCredential(encryptedPrefix + "k1:not-enough-parts"); err == nil {
+		t.Error("malformed value decrypted without error")
+	}
+}
+
+func TestCredentialPlaintextPassthrough(t *testing.T) {
+	useKeyring(t, nil)
+	stored, err := encryptCredential("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored != "secret" {
+		t.Errorf("encryptCredential without a keyring = %q, want plaintext", stored)
+	}
+
+	useKeyring(t, &credentialKeyring{currentID: "k1", keys: map[string][]byte{"k1": testKey(1)}})
+	if got, err := decryptCredential("legacy plaintext"); err != nil || got != "legacy plaintext" {
+		t.Errorf("decryptCredential(plaintext) = %q, %v", got, err)
+	}
+	if stored, _ := encryptCredential(""); stored != "" {
+		t.Errorf("encryptCredential(\"\") = %q, want empty", stored)
+	}
+}
+
+func TestCredentialKeyRotation(t *testing.T) {
+	useKeyring(t, &credentialKeyring{currentID: "k1", keys: map[string][]byte{"k1": testKey(1)}})
+	old, err := encryptCredential("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	useKeyring(t, &credentialKeyring{currentID: "k2", keys: map[string][]byte{"k1": testKey(1), "k2": testKey(2)}})
+	if !needsReencryption(old) || !needsReencryption("plaintext") || needsReencryption("") {
+		t.Fatal("needsReencryption did not flag values outside the current key")
+	}
+
+	rewrapped, err := reencryptCredential(old)
+	if err != nil {
+		t.Fatalf("reencryptCredential: %v", err)
+	}
+	if id := credentialKeyID(rewrapped); id != "k2" {
+		t.Errorf("rewrapped under %q, want %q", id, "k2")
+	}
+	if got, err := decryptCredential(rewrapped); err != nil || got != "secret" {
+		t.Errorf("decryptCredential(rewrapped) = %q, %v", got, err)
+	}
+}
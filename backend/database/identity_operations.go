@@ -0,0 +1,177 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log"
+)
+
+// ─── User Identity Operations ────────────────────────────────────────────────
+//
+// User identities are scoped like ignores (see scopeClause) but not per sync
+// pair: the same people work across all of a scope's projects.
+
+const userIdentityColumns = `id, user_id, asana_user_gid, asana_name, youtrack_user_id, youtrack_login, youtrack_name,
+	email, source, created_at, updated_at`
+
+func scanUserIdentity(row interface{ Scan(...interface{}) error }) (*UserIdentity, error) {
+	u := &UserIdentity{}
+	err := row.Scan(&u.ID, &u.UserID, &u.AsanaUserGID, &u.AsanaName, &u.YouTrackUserID, &u.YouTrackLogin, &u.YouTrackName,
+		&u.Email, &u.Source, &u.CreatedAt, &u.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// GetUserIdentities lists the scope's user identities by Asana name
+func (db *DB) GetUserIdentities(userID int) ([]*UserIdentity, error) {
+	ctx := context.Background()
+	rows, err := db.pool.Query(ctx,
+		`SELECT `+userIdentityColumns+` FROM user_identities WHERE `+scopeClause+` ORDER BY LOWER(asana_name), id`,
+		userID, db.organizationIDFor(userID),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var identities []*UserIdentity
+	for rows.Next() {
+		u, err := scanUserIdentity(rows)
+		if err != nil {
+			continue
+		}
+		identities = append(identities, u)
+	}
+	return identities, nil
+}
+
+// SaveUserIdentity links an Asana user to a YouTrack user, replacing the
+// Asana user's previous link. It fails if the YouTrack user is linked to
+// someone else.
+func (db *DB) SaveUserIdentity(userID int, identity *UserIdentity) (*UserIdentity, error) {
+	ctx := context.Background()
+	orgID := db.organizationIDFor(userID)
+	conflict := `(user_id, asana_user_gid) WHERE organization_id IS NULL`
+	if orgID != nil {
+		conflict = `(organization_id, asana_user_gid) WHERE organization_id IS NOT NULL`
+	}
+	if identity.Source == "" {
+		identity.Source = IdentitySourceManual
+	}
+
+	var linkedTo string
+	err := db.pool.QueryRow(ctx,
+		`SELECT asana_user_gid FROM user_identities WHERE `+scopeClause+` AND youtrack_user_id=$3 AND asana_user_gid<>$4`,
+		userID, orgID, identity.YouTrackUserID, identity.AsanaUserGID,
+	).Scan(&linkedTo)
+	if err == nil {
+		return nil, fmt.Errorf("YouTrack user %s is already linked to Asana user %s", identity.YouTrackUserID, linkedTo)
+	}
+
+	saved, err := scanUserIdentity(db.pool.QueryRow(ctx,
+		`INSERT INTO user_identities (user_id, organization_id, asana_user_gid, asana_name, youtrack_user_id, youtrack_login,
+		                              youtrack_name, email, source, created_at, updated_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW(), NOW())
+		 ON CONFLICT `+conflict+` DO UPDATE
+		   SET asana_name=EXCLUDED.asana_name, youtrack_user_id=EXCLUDED.youtrack_user_id, youtrack_login=EXCLUDED.youtrack_login,
+		       youtrack_name=EXCLUDED.youtrack_name, email=EXCLUDED.email, source=EXCLUDED.source, updated_at=NOW()
+		 RETURNING `+userIdentityColumns,
+		userID, orgID, identity.AsanaUserGID, identity.AsanaName, identity.YouTrackUserID, identity.YouTrackLogin,
+		identity.YouTrackName, identity.Email, identity.Source,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to save user identity: %w", err)
+	}
+
+	log.Printf("DB: Linked Asana user %s to YouTrack user %s (%s) by user %d\n", saved.AsanaUserGID, saved.YouTrackUserID, saved.Source, userID)
+	return saved, nil
+}
+
+func (db *DB) DeleteUserIdentity(userID, identityID int) error {
+	ctx := context.Background()
+	tag, err := db.pool.Exec(ctx,
+		`DELETE FROM user_identities WHERE `+scopeClause+` AND id=$3`,
+		userID, db.organizationIDFor(userID), identityID,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("user identity not found")
+	}
+	return nil
+}
+
+// ─── User Directory Operations ───────────────────────────────────────────────
+
+const directoryUserColumns = `id, platform, external_id, name, email, login, refreshed_at`
+
+func scanDirectoryUser(row interface{ Scan(...interface{}) error }) (*DirectoryUser, error) {
+	u := &DirectoryUser{}
+	if err := row.Scan(&u.ID, &u.Platform, &u.ExternalID, &u.Name, &u.Email, &u.Login, &u.RefreshedAt); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// GetDirectoryUsers lists the scope's directory, Asana users first, by name
+func (db *DB) GetDirectoryUsers(userID int) ([]*DirectoryUser, error) {
+	ctx := context.Background()
+	rows, err := db.pool.Query(ctx,
+		`SELECT `+directoryUserColumns+` FROM directory_users WHERE `+scopeClause+` ORDER BY platform, LOWER(name), id`,
+		userID, db.organizationIDFor(userID),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []*DirectoryUser
+	for rows.Next() {
+		u, err := scanDirectoryUser(rows)
+		if err != nil {
+			continue
+		}
+		users = append(users, u)
+	}
+	return users, nil
+}
+
+// ReplaceDirectoryUsers replaces the scope's directory of one platform with
+// the users just fetched from it
+func (db *DB) ReplaceDirectoryUsers(userID int, platform string, users []*DirectoryUser) error {
+	ctx := context.Background()
+	orgID := db.organizationIDFor(userID)
+
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx,
+		`DELETE FROM directory_users WHERE `+scopeClause+` AND platform=$3`,
+		userID, orgID, platform,
+	); err != nil {
+		return err
+	}
+	for _, u := range users {
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO directory_users (user_id, organization_id, platform, external_id, name, email, login, refreshed_at)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+			 ON CONFLICT DO NOTHING`,
+			userID, orgID, platform, u.ExternalID, u.Name, u.Email, u.Login,
+		); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	log.Printf("DB: Refreshed %d %s directory users for user %d\n", len(users), platform, userID)
+	return nil
+}
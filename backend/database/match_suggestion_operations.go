@@ -0,0 +1,148 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log"
+)
+
+// ─── Match Suggestion Operations ─────────────────────────────────────────────
+//
+// Match suggestions are scoped like ignores (see scopeClause) and belong to a
+// sync pair. A pair stays suggested while pending; once reviewed its status
+// only changes through a new review.
+
+const matchSuggestionColumns = `id, user_id, sync_pair_id, asana_task_id, asana_task_name, youtrack_issue_id, youtrack_summary,
+	match_method, score, status, reviewed_by, reviewed_at, created_at, updated_at`
+
+func scanMatchSuggestion(row interface{ Scan(...interface{}) error }) (*MatchSuggestion, error) {
+	s := &MatchSuggestion{}
+	err := row.Scan(&s.ID, &s.UserID, &s.SyncPairID, &s.AsanaTaskID, &s.AsanaTaskName, &s.YouTrackIssueID,
+		&s.YouTrackSummary, &s.MatchMethod, &s.Score, &s.Status, &s.ReviewedBy, &s.ReviewedAt, &s.CreatedAt, &s.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// SaveMatchSuggestion queues a suggestion, or refreshes the score of one that
+// is still pending. Reviewed pairs are left as they are.
+func (db *DB) SaveMatchSuggestion(userID, syncPairID int, s *MatchSuggestion) error {
+	ctx := context.Background()
+	orgID := db.organizationIDFor(userID)
+	conflict := `(user_id, sync_pair_id, asana_task_id, youtrack_issue_id) WHERE organization_id IS NULL`
+	if orgID != nil {
+		conflict = `(organization_id, sync_pair_id, asana_task_id, youtrack_issue_id) WHERE organization_id IS NOT NULL`
+	}
+	_, err := db.pool.Exec(ctx,
+		`INSERT INTO match_suggestions (user_id, organization_id, sync_pair_id, asana_task_id, asana_task_name,
+		                                youtrack_issue_id, youtrack_summary, match_method, score, status, created_at, updated_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, 'pending', NOW(), NOW())
+		 ON CONFLICT `+conflict+` DO UPDATE
+		   SET asana_task_name=EXCLUDED.asana_task_name, youtrack_summary=EXCLUDED.youtrack_summary,
+		       match_method=EXCLUDED.match_method, score=EXCLUDED.score, updated_at=NOW()
+		   WHERE match_suggestions.status='pending'`,
+		userID, orgID, syncPairID, s.AsanaTaskID, s.AsanaTaskName,
+		s.YouTrackIssueID, s.YouTrackSummary, s.MatchMethod, s.Score,
+	)
+	return err
+}
+
+// GetMatchSuggestions lists a pair's suggestions with the given status ("" for
+// all), best scores first
+func (db *DB) GetMatchSuggestions(userID, syncPairID int, status string) ([]*MatchSuggestion, error) {
+	ctx := context.Background()
+	query := `SELECT ` + matchSuggestionColumns + ` FROM match_suggestions WHERE ` + scopeClause + ` AND sync_pair_id=$3`
+	args := []interface{}{userID, db.organizationIDFor(userID), syncPairID}
+	if status != "" {
+		query += ` AND status=$4`
+		args = append(args, status)
+	}
+	query += ` ORDER BY score DESC, created_at`
+
+	rows, err := db.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var suggestions []*MatchSuggestion
+	for rows.Next() {
+		s, err := scanMatchSuggestion(rows)
+		if err != nil {
+			continue
+		}
+		suggestions = append(suggestions, s)
+	}
+	return suggestions, nil
+}
+
+func (db *DB) GetMatchSuggestion(userID, syncPairID, suggestionID int) (*MatchSuggestion, error) {
+	ctx := context.Background()
+	s, err := scanMatchSuggestion(db.pool.QueryRow(ctx,
+		`SELECT `+matchSuggestionColumns+` FROM match_suggestions WHERE `+scopeClause+` AND sync_pair_id=$3 AND id=$4`,
+		userID, db.organizationIDFor(userID), syncPairID, suggestionID,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("match suggestion not found")
+	}
+	return s, nil
+}
+
+// ReviewMatchSuggestion accepts or rejects a pending suggestion. Accepting one
+// drops the other pending suggestions for the same task or issue.
+func (db *DB) ReviewMatchSuggestion(userID, syncPairID, suggestionID int, status string) (*MatchSuggestion, error) {
+	ctx := context.Background()
+	orgID := db.organizationIDFor(userID)
+
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	reviewed, err := scanMatchSuggestion(tx.QueryRow(ctx,
+		`UPDATE match_suggestions
+		 SET status=$5, reviewed_by=$1, reviewed_at=NOW(), updated_at=NOW()
+		 WHERE `+scopeClause+` AND sync_pair_id=$3 AND id=$4 AND status='pending'
+		 RETURNING `+matchSuggestionColumns,
+		userID, orgID, syncPairID, suggestionID, status,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("pending match suggestion not found")
+	}
+
+	if status == MatchSuggestionAccepted {
+		if _, err := tx.Exec(ctx,
+			`DELETE FROM match_suggestions
+			 WHERE `+scopeClause+` AND sync_pair_id=$3 AND status='pending'
+			   AND (asana_task_id=$4 OR youtrack_issue_id=$5)`,
+			userID, orgID, syncPairID, reviewed.AsanaTaskID, reviewed.YouTrackIssueID,
+		); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	log.Printf("DB: Match suggestion %d (%s <-> %s) %s by user %d\n",
+		reviewed.ID, reviewed.AsanaTaskID, reviewed.YouTrackIssueID, status, userID)
+	return reviewed, nil
+}
+
+// DeletePendingMatchSuggestions drops the pending suggestions of tasks that
+// have been matched since
+func (db *DB) DeletePendingMatchSuggestions(userID, syncPairID int, asanaTaskIDs []string) error {
+	if len(asanaTaskIDs) == 0 {
+		return nil
+	}
+	ctx := context.Background()
+	_, err := db.pool.Exec(ctx,
+		`DELETE FROM match_suggestions
+		 WHERE `+scopeClause+` AND sync_pair_id=$3 AND status='pending' AND asana_task_id = ANY($4)`,
+		userID, db.organizationIDFor(userID), syncPairID, asanaTaskIDs,
+	)
+	return err
+}
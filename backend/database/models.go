@@ -3,6 +3,8 @@ package database
 import (
 	"database/sql/driver"
 	"encoding/json"
+	"math"
+	"strings"
 	"time"
 )
 
@@ -16,6 +18,25 @@ type User struct {
 	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
 }
 
+// UserSession represents a login session backed by a rotating refresh token
+type UserSession struct {
+	ID                int        `json:"id" db:"id"`
+	UserID            int        `json:"user_id" db:"user_id"`
+	RefreshTokenHash  string     `json:"-" db:"refresh_token_hash"`
+	PreviousTokenHash string     `json:"-" db:"previous_token_hash"` // Last rotated-out token, kept for reuse detection
+	UserAgent         string     `json:"user_agent" db:"user_agent"`
+	IPAddress         string     `json:"ip_address" db:"ip_address"`
+	CreatedAt         time.Time  `json:"created_at" db:"created_at"`
+	LastUsedAt        time.Time  `json:"last_used_at" db:"last_used_at"`
+	ExpiresAt         time.Time  `json:"expires_at" db:"expires_at"`
+	RevokedAt         *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+}
+
+// IsActive reports whether the session can still be used
+func (s *UserSession) IsActive() bool {
+	return s.RevokedAt == nil && time.Now().Before(s.ExpiresAt)
+}
+
 // UserSettings represents user configuration
 type UserSettings struct {
 	ID                  int                 `json:"id" db:"id"`
@@ -29,8 +50,22 @@ type UserSettings struct {
 	SyncBoardMembership bool                `json:"sync_board_membership" db:"sync_board_membership"`
 	CustomFieldMappings CustomFieldMappings `json:"custom_field_mappings" db:"custom_field_mappings"`
 	ColumnMappings      ColumnMappings      `json:"column_mappings" db:"column_mappings"`
+	MatchingConfig      MatchingConfig      `json:"matching_config" db:"-"`
+	OrphanPolicy        OrphanPolicy        `json:"orphan_policy" db:"-"`
+	CompletionConfig    CompletionConfig    `json:"completion_config" db:"-"`
+	TimeTracking        TimeTrackingConfig  `json:"time_tracking" db:"-"`
+	SprintConfig        SprintConfig        `json:"sprint_config" db:"-"`
 	CreatedAt           time.Time           `json:"created_at" db:"created_at"`
 	UpdatedAt           time.Time           `json:"updated_at" db:"updated_at"`
+
+	// The board configuration above comes from this sync pair
+	SyncPairID   int    `json:"sync_pair_id" db:"-"`
+	SyncPairName string `json:"sync_pair_name" db:"-"`
+
+	// Set when the user belongs to an organization; the sync pair is then
+	// one of the organization's shared ones
+	OrganizationID   *int   `json:"organization_id,omitempty" db:"-"`
+	OrganizationRole string `json:"organization_role,omitempty" db:"-"`
 }
 
 // CustomFieldMappings represents custom field mapping configuration
@@ -70,12 +105,27 @@ type ColumnMapping struct {
 	YouTrackStatus      string `json:"youtrack_status"`
 	DisplayOnly         bool   `json:"display_only"`
 	ReverseSyncPriority bool   `json:"reverse_sync_priority"` // If true, this column is prioritized for reverse sync when multiple Asana columns map to same YouTrack state
+	// IDs pinned when the mapping is saved, so renaming a section or state
+	// doesn't break it. Mappings saved before they existed match by name.
+	AsanaSectionGID string `json:"asana_section_gid,omitempty"`
+	YouTrackStateID string `json:"youtrack_state_id,omitempty"`
+	Terminal        bool   `json:"terminal"`        // work in this column is finished
+	Findings        bool   `json:"findings"`        // tasks here should be closed in YouTrack; implies display-only
+	Blocked         bool   `json:"blocked"`         // matched tickets here are reported as blocked
+	ReadyForStage   bool   `json:"ready_for_stage"` // tickets here wait for release to stage
 }
 
-// ColumnMappings represents bidirectional column mappings
+// AnyYouTrackState as the YouTrack status of a YouTrack-to-Asana mapping
+// makes its section the default for states without a mapping of their own
+const AnyYouTrackState = "*"
+
+// ColumnMappings represents bidirectional column mappings. YouTrackToAsana
+// picks the Asana section of tickets coming from YouTrack; several states may
+// share a section. Without it, AsanaToYouTrack is inverted, preferring
+// mappings marked ReverseSyncPriority.
 type ColumnMappings struct {
 	AsanaToYouTrack []ColumnMapping `json:"asana_to_youtrack"`
-	YouTrackToAsana []ColumnMapping `json:"youtrack_to_asana"` // For future bidirectional sync
+	YouTrackToAsana []ColumnMapping `json:"youtrack_to_asana"`
 }
 
 // Value implements the driver.Valuer interface for JSON storage
@@ -107,6 +157,237 @@ func (cm *ColumnMappings) Scan(value interface{}) error {
 	}
 }
 
+// MatchingConfig tunes the strategies analysis uses to pair Asana tasks with
+// YouTrack issues. Strategies are enabled by default; a zero threshold means
+// the strategy's default.
+type MatchingConfig struct {
+	Mapping     MatchingStrategyConfig `json:"mapping"`
+	AsanaID     MatchingStrategyConfig `json:"asana_id"`
+	Title       MatchingStrategyConfig `json:"title"`
+	Similarity  MatchingStrategyConfig `json:"similarity"`
+	Description MatchingStrategyConfig `json:"description"`
+
+	// Unmatched tasks whose best candidate scores at least this much are
+	// queued as match suggestions for review
+	SuggestionThreshold float64 `json:"suggestion_threshold"`
+}
+
+// MatchingStrategyConfig configures a single matching strategy
+type MatchingStrategyConfig struct {
+	Disabled  bool    `json:"disabled"`
+	Threshold float64 `json:"threshold"` // minimum confidence between 0 and 1
+}
+
+// Value implements the driver.Valuer interface for JSON storage
+func (mc MatchingConfig) Value() (driver.Value, error) {
+	return json.Marshal(mc)
+}
+
+// Scan implements the sql.Scanner interface for JSON retrieval
+func (mc *MatchingConfig) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, mc)
+	case string:
+		return json.Unmarshal([]byte(v), mc)
+	default:
+		*mc = MatchingConfig{}
+		return nil
+	}
+}
+
+// Orphan policy actions, applied to YouTrack issues whose Asana task vanished
+const (
+	OrphanActionNone     = ""         // only report them
+	OrphanActionClose    = "close"    // move them to a terminal state
+	OrphanActionTag      = "tag"      // tag them in YouTrack
+	OrphanActionRelink   = "relink"   // map them to the Asana task that matches them
+	OrphanActionRecreate = "recreate" // create a new Asana task for them
+)
+
+// DefaultOrphanTag is the YouTrack tag of the tag action when none is set
+const DefaultOrphanTag = "orphaned"
+
+// OrphanPolicy is what happens to a sync pair's orphaned YouTrack issues once
+// they have been orphaned for the grace period
+type OrphanPolicy struct {
+	Action     string `json:"action"`
+	GraceHours int    `json:"grace_hours"`
+	CloseState string `json:"close_state,omitempty"` // close action; empty for the first terminal column's state
+	Tag        string `json:"tag,omitempty"`         // tag action; empty for DefaultOrphanTag
+	// ReasonActions overrides Action for orphans whose task vanished for the
+	// given reason, e.g. {"deleted": "close", "removed": "relink"}
+	ReasonActions map[string]string `json:"reason_actions,omitempty"`
+}
+
+// ActionFor returns the action for orphans whose task vanished for the reason
+func (p OrphanPolicy) ActionFor(reason string) string {
+	if action, ok := p.ReasonActions[reason]; ok {
+		return action
+	}
+	return p.Action
+}
+
+// Why an orphaned issue's Asana task vanished from the sync pair's project
+const (
+	OrphanReasonUnknown  = ""         // not checked yet
+	OrphanReasonDeleted  = "deleted"  // the task no longer exists
+	OrphanReasonRemoved  = "removed"  // the task was taken out of the project
+	OrphanReasonArchived = "archived" // the task only belongs to archived projects
+)
+
+// CompletionConfig maps Asana task completion to YouTrack resolved states.
+// Without resolved states completion isn't synced.
+type CompletionConfig struct {
+	// ResolvedStates are the YouTrack states a completed task's issue may be
+	// in. The first is the one completing a task moves its issue to, unless
+	// the task's column already maps to one of them.
+	ResolvedStates []string `json:"resolved_states"`
+}
+
+// IsResolved reports whether a YouTrack state is one of the resolved states
+func (c CompletionConfig) IsResolved(state string) bool {
+	for _, resolved := range c.ResolvedStates {
+		if strings.EqualFold(resolved, strings.TrimSpace(state)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Units of the Asana number fields time is mirrored to
+const (
+	TimeUnitHours   = "hours"
+	TimeUnitMinutes = "minutes"
+)
+
+// TimeTrackingConfig mirrors YouTrack's Estimation and Spent time to Asana
+// number custom fields. An empty field name leaves that value alone.
+type TimeTrackingConfig struct {
+	EstimateField string `json:"estimate_field,omitempty"` // Asana field mirroring Estimation
+	SpentField    string `json:"spent_field,omitempty"`    // Asana field mirroring Spent time
+	Unit          string `json:"unit,omitempty"`           // TimeUnitHours or TimeUnitMinutes; empty for hours
+	// EstimateOnCreate sets the Estimation of issues created from Asana
+	// tasks from the task's EstimateField
+	EstimateOnCreate bool `json:"estimate_on_create"`
+}
+
+// Enabled reports whether any value is mirrored
+func (c TimeTrackingConfig) Enabled() bool {
+	return c.EstimateField != "" || c.SpentField != ""
+}
+
+// ToFieldValue converts minutes to the unit of the Asana fields
+func (c TimeTrackingConfig) ToFieldValue(minutes int) float64 {
+	if c.Unit == TimeUnitMinutes {
+		return float64(minutes)
+	}
+	return math.Round(float64(minutes)/60*100) / 100
+}
+
+// ToMinutes converts a value of the Asana fields to minutes
+func (c TimeTrackingConfig) ToMinutes(value float64) int {
+	if c.Unit == TimeUnitMinutes {
+		return int(math.Round(value))
+	}
+	return int(math.Round(value * 60))
+}
+
+// SprintCurrent maps to whichever sprint is current on the board
+const SprintCurrent = "@current"
+
+// SprintConfig maps Asana tasks to sprints of the sync pair's YouTrack board
+// and Asana story points to YouTrack's Story points field
+type SprintConfig struct {
+	// AsanaField names the Asana custom field holding a task's sprint. Empty
+	// to map the task's section instead.
+	AsanaField string `json:"asana_field,omitempty"`
+	// Sprints maps field values or section names to YouTrack sprint names or
+	// SprintCurrent. Tasks whose value isn't mapped stay in their sprint.
+	Sprints map[string]string `json:"sprints,omitempty"`
+	// StoryPointsField names the Asana number field mirrored to Story points
+	StoryPointsField string `json:"story_points_field,omitempty"`
+}
+
+// SprintFor returns the sprint an Asana field value or section maps to
+func (c SprintConfig) SprintFor(value string) (string, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", false
+	}
+	for key, sprint := range c.Sprints {
+		if strings.EqualFold(strings.TrimSpace(key), value) {
+			return sprint, true
+		}
+	}
+	return "", false
+}
+
+// Roles, from least to most privileged. Users outside an organization act
+// as admin of their own data.
+const (
+	RoleViewer   = "viewer"   // run analysis and read results
+	RoleOperator = "operator" // create, sync, ignore and map tickets
+	RoleAdmin    = "admin"    // bulk delete, rollback, column mappings and membership
+)
+
+var roleRanks = map[string]int{RoleViewer: 1, RoleOperator: 2, RoleAdmin: 3}
+
+// IsValidRole reports whether role is one of the known roles
+func IsValidRole(role string) bool {
+	_, ok := roleRanks[role]
+	return ok
+}
+
+// RoleAtLeast reports whether role grants everything required grants
+func RoleAtLeast(role, required string) bool {
+	return roleRanks[role] >= roleRanks[required]
+}
+
+// Organization groups users that share sync pairs, ticket mappings and
+// ignore lists. Credentials stay per user.
+type Organization struct {
+	ID        int       `json:"id" db:"id"`
+	Name      string    `json:"name" db:"name"`
+	CreatedBy *int      `json:"created_by" db:"created_by"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// SyncPair is a named connection between one Asana project and one YouTrack
+// project with its own board configuration and ignore lists. Pairs belong to
+// a user, or to an organization when OrganizationID is set.
+type SyncPair struct {
+	ID                  int                 `json:"id" db:"id"`
+	UserID              int                 `json:"user_id" db:"user_id"`
+	OrganizationID      *int                `json:"organization_id,omitempty" db:"organization_id"`
+	Name                string              `json:"name" db:"name"`
+	AsanaProjectID      string              `json:"asana_project_id" db:"asana_project_id"`
+	YouTrackProjectID   string              `json:"youtrack_project_id" db:"youtrack_project_id"`
+	YouTrackBoardID     string              `json:"youtrack_board_id" db:"youtrack_board_id"`
+	SyncBoardMembership bool                `json:"sync_board_membership" db:"sync_board_membership"`
+	CustomFieldMappings CustomFieldMappings `json:"custom_field_mappings" db:"custom_field_mappings"`
+	ColumnMappings      ColumnMappings      `json:"column_mappings" db:"column_mappings"`
+	MatchingConfig      MatchingConfig      `json:"matching_config" db:"matching_config"`
+	OrphanPolicy        OrphanPolicy        `json:"orphan_policy" db:"orphan_policy"`
+	CompletionConfig    CompletionConfig    `json:"completion_config" db:"completion_config"`
+	TimeTracking        TimeTrackingConfig  `json:"time_tracking" db:"time_tracking"`
+	SprintConfig        SprintConfig        `json:"sprint_config" db:"sprint_config"`
+	IsDefault           bool                `json:"is_default" db:"is_default"`
+	CreatedAt           time.Time           `json:"created_at" db:"created_at"`
+	UpdatedAt           time.Time           `json:"updated_at" db:"updated_at"`
+}
+
+// OrganizationMember represents a user's membership in an organization
+type OrganizationMember struct {
+	OrganizationID int       `json:"organization_id" db:"organization_id"`
+	UserID         int       `json:"user_id" db:"user_id"`
+	Username       string    `json:"username" db:"username"`
+	Email          string    `json:"email" db:"email"`
+	Role           string    `json:"role" db:"role"` // "viewer", "operator" or "admin"
+	JoinedAt       time.Time `json:"joined_at" db:"joined_at"`
+}
+
 // SyncOperation represents a sync operation record
 type SyncOperation struct {
 	ID            int                    `json:"id" db:"id"`
@@ -188,10 +469,117 @@ type TicketMapping struct {
 	AsanaTaskID       string    `json:"asana_task_id" db:"asana_task_id"`
 	YouTrackProjectID string    `json:"youtrack_project_id" db:"youtrack_project_id"`
 	YouTrackIssueID   string    `json:"youtrack_issue_id" db:"youtrack_issue_id"` // e.g., "ARD-340"
+	Completed         *bool     `json:"completed,omitempty" db:"completed"`       // completion both sides last agreed on; nil until sync compares them
 	CreatedAt         time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt         time.Time `json:"updated_at" db:"updated_at"`
 }
 
+// Match suggestion review states
+const (
+	MatchSuggestionPending  = "pending"
+	MatchSuggestionAccepted = "accepted"
+	MatchSuggestionRejected = "rejected"
+)
+
+// MatchSuggestion is a candidate pairing of an Asana task and a YouTrack issue
+// that analysis wasn't confident enough to bind on its own
+type MatchSuggestion struct {
+	ID              int        `json:"id" db:"id"`
+	UserID          int        `json:"user_id" db:"user_id"`
+	SyncPairID      int        `json:"sync_pair_id" db:"sync_pair_id"`
+	AsanaTaskID     string     `json:"asana_task_id" db:"asana_task_id"`
+	AsanaTaskName   string     `json:"asana_task_name" db:"asana_task_name"`
+	YouTrackIssueID string     `json:"youtrack_issue_id" db:"youtrack_issue_id"`
+	YouTrackSummary string     `json:"youtrack_summary" db:"youtrack_summary"`
+	MatchMethod     string     `json:"match_method" db:"match_method"`
+	Score           float64    `json:"score" db:"score"`
+	Status          string     `json:"status" db:"status"`
+	ReviewedBy      *int       `json:"reviewed_by,omitempty" db:"reviewed_by"`
+	ReviewedAt      *time.Time `json:"reviewed_at,omitempty" db:"reviewed_at"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// Orphaned issue states, for listing
+const (
+	OrphanStatusOpen     = "open"
+	OrphanStatusResolved = "resolved"
+)
+
+// OrphanedIssue is a YouTrack issue whose Asana task vanished. It stays open
+// until the issue is linked to an existing task again.
+type OrphanedIssue struct {
+	ID              int        `json:"id" db:"id"`
+	UserID          int        `json:"user_id" db:"user_id"`
+	SyncPairID      int        `json:"sync_pair_id" db:"sync_pair_id"`
+	YouTrackIssueID string     `json:"youtrack_issue_id" db:"youtrack_issue_id"`
+	YouTrackSummary string     `json:"youtrack_summary" db:"youtrack_summary"`
+	AsanaTaskID     string     `json:"asana_task_id" db:"asana_task_id"` // the vanished task
+	Reason          string     `json:"reason,omitempty" db:"reason"`     // why it vanished, e.g. OrphanReasonDeleted
+	FirstSeenAt     time.Time  `json:"first_seen_at" db:"first_seen_at"`
+	LastSeenAt      time.Time  `json:"last_seen_at" db:"last_seen_at"`
+	Action          string     `json:"action,omitempty" db:"action"`               // last action taken
+	ActionResult    string     `json:"action_result,omitempty" db:"action_result"` // e.g. the state set or the new task's GID
+	ActionError     string     `json:"action_error,omitempty" db:"action_error"`
+	ActedAt         *time.Time `json:"acted_at,omitempty" db:"acted_at"`
+	ResolvedAt      *time.Time `json:"resolved_at,omitempty" db:"resolved_at"`
+}
+
+// Where a user identity came from
+const (
+	IdentitySourceManual = "manual" // linked through the API
+	IdentitySourceEmail  = "email"  // seeded from matching emails
+)
+
+// UserIdentity links an Asana user to a YouTrack user. Identities are scoped
+// like ignores and shared by all sync pairs; each user is linked at most once
+// on either side.
+type UserIdentity struct {
+	ID             int       `json:"id" db:"id"`
+	UserID         int       `json:"user_id" db:"user_id"`
+	AsanaUserGID   string    `json:"asana_user_gid" db:"asana_user_gid"`
+	AsanaName      string    `json:"asana_name" db:"asana_name"`
+	YouTrackUserID string    `json:"youtrack_user_id" db:"youtrack_user_id"`
+	YouTrackLogin  string    `json:"youtrack_login" db:"youtrack_login"`
+	YouTrackName   string    `json:"youtrack_name" db:"youtrack_name"`
+	Email          string    `json:"email,omitempty" db:"email"`
+	Source         string    `json:"source" db:"source"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Directory platforms
+const (
+	DirectoryPlatformAsana    = "asana"
+	DirectoryPlatformYouTrack = "youtrack"
+)
+
+// DirectoryUser is a known Asana workspace user or YouTrack user, as of the
+// scope's last directory refresh. Login is only set for YouTrack users.
+type DirectoryUser struct {
+	ID          int       `json:"id" db:"id"`
+	Platform    string    `json:"platform" db:"platform"`
+	ExternalID  string    `json:"external_id" db:"external_id"`
+	Name        string    `json:"name" db:"name"`
+	Email       string    `json:"email,omitempty" db:"email"`
+	Login       string    `json:"login,omitempty" db:"login"`
+	RefreshedAt time.Time `json:"refreshed_at" db:"refreshed_at"`
+}
+
+// AnalysisRun is the compact record of one analysis: how many tickets landed
+// in each bucket and which ones. TicketIDs is only loaded for single runs.
+type AnalysisRun struct {
+	ID              int                 `json:"id" db:"id"`
+	UserID          int                 `json:"user_id" db:"user_id"`
+	SyncPairID      int                 `json:"sync_pair_id" db:"sync_pair_id"`
+	SelectedColumns string              `json:"selected_columns" db:"selected_columns"`
+	Counts          map[string]int      `json:"counts" db:"counts"`
+	TicketIDs       map[string][]string `json:"ticket_ids,omitempty" db:"ticket_ids"`
+	StartedAt       time.Time           `json:"started_at" db:"started_at"`
+	DurationMs      int                 `json:"duration_ms" db:"duration_ms"`
+	CreatedAt       time.Time           `json:"created_at" db:"created_at"`
+}
+
 // Project represents project information for dropdowns
 type Project struct {
 	ID   string `json:"id"`
@@ -225,7 +613,9 @@ type AsanaSection struct {
 
 // YouTrackState represents a YouTrack workflow state
 type YouTrackState struct {
-	Name string `json:"name"`
+	ID         string `json:"id,omitempty"`
+	Name       string `json:"name"`
+	IsResolved bool   `json:"is_resolved"`
 }
 
 // RollbackSnapshot represents a complete snapshot before sync operations
@@ -236,6 +626,44 @@ type RollbackSnapshot struct {
 	SnapshotData SnapshotData `json:"snapshot_data"`
 	CreatedAt    time.Time    `json:"created_at"`
 	ExpiresAt    time.Time    `json:"expires_at"`
+	Pinned       bool         `json:"pinned"`     // Pinned snapshots never expire and are exempt from limits
+	Compacted    bool         `json:"compacted"`  // Full ticket copies dropped, statuses kept
+	SizeBytes    int64        `json:"size_bytes"` // Stored size of snapshot_data
+}
+
+// IsExpired reports whether the snapshot is past its rollback window.
+// Pinned snapshots never expire.
+func (s *RollbackSnapshot) IsExpired() bool {
+	return !s.Pinned && time.Now().After(s.ExpiresAt)
+}
+
+// Default snapshot retention values, used when a user has no stored policy
+const (
+	DefaultSnapshotMaxAgeHours       = 24
+	DefaultSnapshotMaxCount          = 15
+	DefaultSnapshotCompactAfterHours = 6
+)
+
+// SnapshotRetentionPolicy controls how long and how many rollback snapshots are kept per user
+type SnapshotRetentionPolicy struct {
+	UserID            int       `json:"user_id"`
+	MaxAgeHours       int       `json:"max_age_hours"`
+	MaxCount          int       `json:"max_count"`
+	MaxStorageBytes   int64     `json:"max_storage_bytes"`   // 0 = unlimited
+	CompactAfterHours int       `json:"compact_after_hours"` // 0 = never compact
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// DefaultSnapshotRetentionPolicy returns the policy applied to users without a stored one
+func DefaultSnapshotRetentionPolicy(userID int) *SnapshotRetentionPolicy {
+	return &SnapshotRetentionPolicy{
+		UserID:            userID,
+		MaxAgeHours:       DefaultSnapshotMaxAgeHours,
+		MaxCount:          DefaultSnapshotMaxCount,
+		MaxStorageBytes:   0,
+		CompactAfterHours: DefaultSnapshotCompactAfterHours,
+	}
 }
 
 // SnapshotData contains all data needed for rollback
@@ -0,0 +1,432 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ─── Organization Operations ─────────────────────────────────────────────────
+
+const organizationColumns = `id, name, created_by, created_at, updated_at`
+
+func scanOrganization(row interface{ Scan(...interface{}) error }) (*Organization, error) {
+	o := &Organization{}
+	if err := row.Scan(&o.ID, &o.Name, &o.CreatedBy, &o.CreatedAt, &o.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// membershipTTL bounds how long a user's organization is remembered, in case
+// another instance changes it
+const membershipTTL = 30 * time.Second
+
+type cachedMembership struct {
+	orgID     *int
+	fetchedAt time.Time
+}
+
+// organizationIDFor returns the organization a user belongs to, or nil for
+// personal use. It is looked up on every scoped call, so it is cached briefly;
+// membership changes here drop the cache.
+func (db *DB) organizationIDFor(userID int) *int {
+	db.mutex.RLock()
+	cached, ok := db.memberships[userID]
+	db.mutex.RUnlock()
+	if ok && time.Since(cached.fetchedAt) < membershipTTL {
+		return cached.orgID
+	}
+
+	ctx := context.Background()
+	var orgID *int
+	var id int
+	err := db.pool.QueryRow(ctx,
+		`SELECT organization_id FROM organization_members WHERE user_id=$1`,
+		userID,
+	).Scan(&id)
+	if err == nil {
+		orgID = &id
+	} else if err != pgx.ErrNoRows {
+		// Don't remember a failed lookup as personal use
+		return nil
+	}
+
+	db.mutex.Lock()
+	if db.memberships == nil {
+		db.memberships = make(map[int]cachedMembership)
+	}
+	db.memberships[userID] = cachedMembership{orgID: orgID, fetchedAt: time.Now()}
+	db.mutex.Unlock()
+	return orgID
+}
+
+// forgetOrganizations drops the cached memberships after a membership change
+func (db *DB) forgetOrganizations() {
+	db.mutex.Lock()
+	db.memberships = nil
+	db.mutex.Unlock()
+}
+
+// CreateOrganization creates an organization with the user as its first admin.
+// The user's sync pairs become the shared ones and their ticket mappings and
+// ignore lists are moved into the organization.
+func (db *DB) CreateOrganization(userID int, name string) (*Organization, error) {
+	ctx := context.Background()
+
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var existing bool
+	tx.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM organization_members WHERE user_id=$1)`, userID).Scan(&existing)
+	if existing {
+		return nil, fmt.Errorf("user already belongs to an organization")
+	}
+
+	org, err := scanOrganization(tx.QueryRow(ctx,
+		`INSERT INTO organizations (name, created_by, created_at, updated_at)
+		 VALUES ($1, $2, NOW(), NOW())
+		 RETURNING `+organizationColumns,
+		name, userID,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create organization: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx,
+		`UPDATE sync_pairs SET organization_id=$1, updated_at=NOW() WHERE user_id=$2 AND organization_id IS NULL`,
+		org.ID, userID,
+	); err != nil {
+		return nil, fmt.Errorf("failed to share sync pairs with organization: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx,
+		`INSERT INTO organization_members (organization_id, user_id, role, joined_at) VALUES ($1, $2, $3, NOW())`,
+		org.ID, userID, RoleAdmin,
+	); err != nil {
+		return nil, fmt.Errorf("failed to add organization admin: %w", err)
+	}
+
+	if err := adoptPersonalData(ctx, tx, org.ID, userID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	db.forgetOrganizations()
+
+	log.Printf("DB: Organization created: %s (ID: %d) by user %d\n", name, org.ID, userID)
+	return org, nil
+}
+
+func (db *DB) GetOrganization(orgID int) (*Organization, error) {
+	ctx := context.Background()
+	org, err := scanOrganization(db.pool.QueryRow(ctx,
+		`SELECT `+organizationColumns+` FROM organizations WHERE id=$1`,
+		orgID,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("organization not found")
+	}
+	return org, nil
+}
+
+func (db *DB) UpdateOrganizationName(orgID int, name string) (*Organization, error) {
+	ctx := context.Background()
+	org, err := scanOrganization(db.pool.QueryRow(ctx,
+		`UPDATE organizations SET name=$1, updated_at=NOW() WHERE id=$2 RETURNING `+organizationColumns,
+		name, orgID,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("organization not found")
+	}
+	return org, nil
+}
+
+// orgScopedTables lists the tables scoped by organization_id, each with the
+// key columns of its personal unique indexes
+var orgScopedTables = []struct {
+	table string
+	keys  [][]string
+}{
+	{"ticket_mappings", [][]string{{"asana_task_id"}}},
+	{"ignored_tickets", [][]string{{"sync_pair_id", "ticket_id"}}},
+	{"reverse_ignored_tickets", [][]string{{"sync_pair_id", "ticket_id"}}},
+	{"match_suggestions", [][]string{{"sync_pair_id", "asana_task_id", "youtrack_issue_id"}}},
+	{"orphaned_issues", [][]string{{"sync_pair_id", "youtrack_issue_id"}}},
+	{"user_identities", [][]string{{"asana_user_gid"}, {"youtrack_user_id"}}},
+	{"directory_users", [][]string{{"platform", "external_id"}}},
+	{"analysis_runs", nil},
+	{"sync_pairs", nil},
+}
+
+// DeleteOrganization removes an organization. Shared sync pairs, mappings,
+// ignores and the like fall back to personal rows of the member who created
+// them; the foreign keys cascade, so this is the only path that keeps them.
+func (db *DB) DeleteOrganization(orgID int) error {
+	ctx := context.Background()
+
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// Personal rows shadowed by a shared one would collide once the shared row
+	// becomes personal again; the shared row is the newer truth, so keep it.
+	var cleanups []string
+	for _, t := range orgScopedTables {
+		for _, key := range t.keys {
+			match := "p.user_id=o.user_id"
+			for _, column := range key {
+				match += fmt.Sprintf(" AND p.%s=o.%s", column, column)
+			}
+			cleanups = append(cleanups, fmt.Sprintf(
+				`DELETE FROM %s p USING %s o WHERE o.organization_id=$1 AND p.organization_id IS NULL AND %s`,
+				t.table, t.table, match))
+		}
+	}
+	// A member keeps their own default pair over the organization's
+	cleanups = append(cleanups,
+		`UPDATE sync_pairs o SET is_default=false
+		 WHERE o.organization_id=$1 AND o.is_default
+		   AND EXISTS (SELECT 1 FROM sync_pairs p
+		               WHERE p.organization_id IS NULL AND p.user_id=o.user_id AND p.is_default)`)
+	for _, t := range orgScopedTables {
+		cleanups = append(cleanups, fmt.Sprintf(`UPDATE %s SET organization_id=NULL WHERE organization_id=$1`, t.table))
+	}
+	for _, query := range cleanups {
+		if _, err := tx.Exec(ctx, query, orgID); err != nil {
+			return err
+		}
+	}
+
+	result, err := tx.Exec(ctx, `DELETE FROM organizations WHERE id=$1`, orgID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("organization not found")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	db.forgetOrganizations()
+
+	log.Printf("DB: Organization deleted: %d\n", orgID)
+	return nil
+}
+
+// ─── Organization Member Operations ──────────────────────────────────────────
+
+const memberColumns = `m.organization_id, m.user_id, u.username, u.email, m.role, m.joined_at`
+
+// ErrMembershipNotFound is returned for users outside an organization
+var ErrMembershipNotFound = errors.New("membership not found")
+
+// GetUserMembership returns the user's organization membership, or
+// ErrMembershipNotFound for personal use. Other errors mean the lookup failed.
+func (db *DB) GetUserMembership(userID int) (*OrganizationMember, error) {
+	ctx := context.Background()
+	m := &OrganizationMember{}
+	err := db.pool.QueryRow(ctx,
+		`SELECT `+memberColumns+`
+		 FROM organization_members m JOIN users u ON u.id = m.user_id
+		 WHERE m.user_id=$1`,
+		userID,
+	).Scan(&m.OrganizationID, &m.UserID, &m.Username, &m.Email, &m.Role, &m.JoinedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrMembershipNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get membership: %w", err)
+	}
+	return m, nil
+}
+
+func (db *DB) GetOrganizationMembers(orgID int) ([]*OrganizationMember, error) {
+	ctx := context.Background()
+	rows, err := db.pool.Query(ctx,
+		`SELECT `+memberColumns+`
+		 FROM organization_members m JOIN users u ON u.id = m.user_id
+		 WHERE m.organization_id=$1
+		 ORDER BY m.joined_at`,
+		orgID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var members []*OrganizationMember
+	for rows.Next() {
+		m := &OrganizationMember{}
+		if err := rows.Scan(&m.OrganizationID, &m.UserID, &m.Username, &m.Email, &m.Role, &m.JoinedAt); err != nil {
+			continue
+		}
+		members = append(members, m)
+	}
+	return members, nil
+}
+
+// AddOrganizationMember adds a user to an organization. Their personal
+// mappings and ignores that don't clash with shared ones are moved in.
+func (db *DB) AddOrganizationMember(orgID, userID int, role string) (*OrganizationMember, error) {
+	ctx := context.Background()
+
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var existing bool
+	tx.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM organization_members WHERE user_id=$1)`, userID).Scan(&existing)
+	if existing {
+		return nil, fmt.Errorf("user already belongs to an organization")
+	}
+
+	if _, err := tx.Exec(ctx,
+		`INSERT INTO organization_members (organization_id, user_id, role, joined_at) VALUES ($1, $2, $3, NOW())`,
+		orgID, userID, role,
+	); err != nil {
+		return nil, fmt.Errorf("failed to add member: %w", err)
+	}
+
+	if err := adoptPersonalData(ctx, tx, orgID, userID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	db.forgetOrganizations()
+
+	log.Printf("DB: User %d joined organization %d as %s\n", userID, orgID, role)
+	return db.GetUserMembership(userID)
+}
+
+func (db *DB) UpdateOrganizationMemberRole(orgID, userID int, role string) error {
+	ctx := context.Background()
+	result, err := db.pool.Exec(ctx,
+		`UPDATE organization_members SET role=$1 WHERE organization_id=$2 AND user_id=$3`,
+		role, orgID, userID,
+	)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("member not found")
+	}
+	return nil
+}
+
+// RemoveOrganizationMember removes a user from an organization. Shared rows
+// they created stay with the organization.
+func (db *DB) RemoveOrganizationMember(orgID, userID int) error {
+	ctx := context.Background()
+	result, err := db.pool.Exec(ctx,
+		`DELETE FROM organization_members WHERE organization_id=$1 AND user_id=$2`,
+		orgID, userID,
+	)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("member not found")
+	}
+	db.forgetOrganizations()
+	log.Printf("DB: User %d left organization %d\n", userID, orgID)
+	return nil
+}
+
+func (db *DB) CountOrganizationAdmins(orgID int) int {
+	ctx := context.Background()
+	var count int
+	db.pool.QueryRow(ctx,
+		`SELECT COUNT(*) FROM organization_members WHERE organization_id=$1 AND role=$2`,
+		orgID, RoleAdmin,
+	).Scan(&count)
+	return count
+}
+
+// GetUserRole returns the user's organization role. Users outside an
+// organization own their data and are treated as admins. A failed lookup
+// returns "", which no role check accepts.
+func (db *DB) GetUserRole(userID int) string {
+	membership, err := db.GetUserMembership(userID)
+	if errors.Is(err, ErrMembershipNotFound) {
+		return RoleAdmin
+	}
+	if err != nil {
+		log.Printf("DB: Could not get role of user %d: %v\n", userID, err)
+		return ""
+	}
+	return membership.Role
+}
+
+// GetOrganizationMemberIDs returns the user IDs sharing a user's scope: the
+// organization's members, or just the user for personal use.
+func (db *DB) GetOrganizationMemberIDs(userID int) []int {
+	orgID := db.organizationIDFor(userID)
+	if orgID == nil {
+		return []int{userID}
+	}
+	members, err := db.GetOrganizationMembers(*orgID)
+	if err != nil || len(members) == 0 {
+		return []int{userID}
+	}
+	ids := make([]int, 0, len(members))
+	for _, m := range members {
+		ids = append(ids, m.UserID)
+	}
+	return ids
+}
+
+// adoptPersonalData moves a user's personal mappings and ignores into the
+// organization, skipping any the organization already has. Ignores follow
+// the organization's sync pair for the same projects; ignores of pairs the
+// organization has no counterpart for stay personal.
+func adoptPersonalData(ctx context.Context, tx pgx.Tx, orgID, userID int) error {
+	queries := []string{
+		`UPDATE ticket_mappings p SET organization_id=$1
+		 WHERE p.user_id=$2 AND p.organization_id IS NULL
+		   AND NOT EXISTS (SELECT 1 FROM ticket_mappings o
+		                   WHERE o.organization_id=$1 AND o.asana_task_id=p.asana_task_id)`,
+		`UPDATE ignored_tickets p SET organization_id=$1, sync_pair_id=target.id
+		 FROM sync_pairs pp
+		 CROSS JOIN LATERAL (SELECT op.id FROM sync_pairs op
+		                     WHERE op.organization_id=$1 AND op.asana_project_id=pp.asana_project_id
+		                       AND op.youtrack_project_id=pp.youtrack_project_id
+		                     ORDER BY op.id=pp.id DESC, op.id LIMIT 1) target
+		 WHERE pp.id=p.sync_pair_id AND p.user_id=$2 AND p.organization_id IS NULL
+		   AND NOT EXISTS (SELECT 1 FROM ignored_tickets o
+		                   WHERE o.organization_id=$1 AND o.sync_pair_id=target.id AND o.ticket_id=p.ticket_id)`,
+		`UPDATE reverse_ignored_tickets p SET organization_id=$1, sync_pair_id=target.id
+		 FROM sync_pairs pp
+		 CROSS JOIN LATERAL (SELECT op.id FROM sync_pairs op
+		                     WHERE op.organization_id=$1 AND op.asana_project_id=pp.asana_project_id
+		                       AND op.youtrack_project_id=pp.youtrack_project_id
+		                     ORDER BY op.id=pp.id DESC, op.id LIMIT 1) target
+		 WHERE pp.id=p.sync_pair_id AND p.user_id=$2 AND p.organization_id IS NULL
+		   AND NOT EXISTS (SELECT 1 FROM reverse_ignored_tickets o
+		                   WHERE o.organization_id=$1 AND o.sync_pair_id=target.id AND o.ticket_id=p.ticket_id)`,
+	}
+	for _, query := range queries {
+		if _, err := tx.Exec(ctx, query, orgID, userID); err != nil {
+			return fmt.Errorf("failed to share personal data with organization: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log"
+)
+
+// ─── Orphaned Issue Operations ───────────────────────────────────────────────
+//
+// Orphaned issues are scoped like ignores (see scopeClause) and belong to a
+// sync pair. Each analysis reports the full set of orphans; issues missing
+// from it have been linked again and are resolved. An issue that turns up
+// again after being resolved starts a new grace period.
+
+const orphanedIssueColumns = `id, user_id, sync_pair_id, youtrack_issue_id, youtrack_summary, asana_task_id, reason,
+	first_seen_at, last_seen_at, action, action_result, action_error, acted_at, resolved_at`
+
+func scanOrphanedIssue(row interface{ Scan(...interface{}) error }) (*OrphanedIssue, error) {
+	o := &OrphanedIssue{}
+	err := row.Scan(&o.ID, &o.UserID, &o.SyncPairID, &o.YouTrackIssueID, &o.YouTrackSummary, &o.AsanaTaskID, &o.Reason,
+		&o.FirstSeenAt, &o.LastSeenAt, &o.Action, &o.ActionResult, &o.ActionError, &o.ActedAt, &o.ResolvedAt)
+	if err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// SaveOrphanedIssues records the pair's current orphans, keeping the first
+// time each was seen, and resolves the open ones that are no longer orphaned
+func (db *DB) SaveOrphanedIssues(userID, syncPairID int, orphans []*OrphanedIssue) error {
+	ctx := context.Background()
+	orgID := db.organizationIDFor(userID)
+	conflict := `(user_id, sync_pair_id, youtrack_issue_id) WHERE organization_id IS NULL`
+	if orgID != nil {
+		conflict = `(organization_id, sync_pair_id, youtrack_issue_id) WHERE organization_id IS NOT NULL`
+	}
+
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	issueIDs := make([]string, 0, len(orphans))
+	for _, o := range orphans {
+		issueIDs = append(issueIDs, o.YouTrackIssueID)
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO orphaned_issues (user_id, organization_id, sync_pair_id, youtrack_issue_id, youtrack_summary,
+			                              asana_task_id, reason, first_seen_at, last_seen_at)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
+			 ON CONFLICT `+conflict+` DO UPDATE
+			   SET youtrack_summary=EXCLUDED.youtrack_summary, asana_task_id=EXCLUDED.asana_task_id, reason=EXCLUDED.reason, last_seen_at=NOW(),
+			       first_seen_at=CASE WHEN orphaned_issues.resolved_at IS NULL THEN orphaned_issues.first_seen_at ELSE NOW() END,
+			       action=CASE WHEN orphaned_issues.resolved_at IS NULL THEN orphaned_issues.action ELSE '' END,
+			       action_result=CASE WHEN orphaned_issues.resolved_at IS NULL THEN orphaned_issues.action_result ELSE '' END,
+			       action_error=CASE WHEN orphaned_issues.resolved_at IS NULL THEN orphaned_issues.action_error ELSE '' END,
+			       acted_at=CASE WHEN orphaned_issues.resolved_at IS NULL THEN orphaned_issues.acted_at ELSE NULL END,
+			       resolved_at=NULL`,
+			userID, orgID, syncPairID, o.YouTrackIssueID, o.YouTrackSummary, o.AsanaTaskID, o.Reason,
+		); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(ctx,
+		`UPDATE orphaned_issues SET resolved_at=NOW()
+		 WHERE `+scopeClause+` AND sync_pair_id=$3 AND resolved_at IS NULL AND NOT (youtrack_issue_id = ANY($4))`,
+		userID, orgID, syncPairID, issueIDs,
+	); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetOrphanedIssues lists a pair's orphaned issues with the given status
+// (OrphanStatusOpen, OrphanStatusResolved or "" for all), oldest first
+func (db *DB) GetOrphanedIssues(userID, syncPairID int, status string) ([]*OrphanedIssue, error) {
+	ctx := context.Background()
+	query := `SELECT ` + orphanedIssueColumns + ` FROM orphaned_issues WHERE ` + scopeClause + ` AND sync_pair_id=$3`
+	switch status {
+	case OrphanStatusOpen:
+		query += ` AND resolved_at IS NULL`
+	case OrphanStatusResolved:
+		query += ` AND resolved_at IS NOT NULL`
+	}
+	query += ` ORDER BY first_seen_at, id`
+
+	rows, err := db.pool.Query(ctx, query, userID, db.organizationIDFor(userID), syncPairID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var orphans []*OrphanedIssue
+	for rows.Next() {
+		o, err := scanOrphanedIssue(rows)
+		if err != nil {
+			continue
+		}
+		orphans = append(orphans, o)
+	}
+	return orphans, nil
+}
+
+func (db *DB) GetOrphanedIssue(userID, syncPairID, orphanID int) (*OrphanedIssue, error) {
+	ctx := context.Background()
+	o, err := scanOrphanedIssue(db.pool.QueryRow(ctx,
+		`SELECT `+orphanedIssueColumns+` FROM orphaned_issues WHERE `+scopeClause+` AND sync_pair_id=$3 AND id=$4`,
+		userID, db.organizationIDFor(userID), syncPairID, orphanID,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("orphaned issue not found")
+	}
+	return o, nil
+}
+
+// RecordOrphanAction stores the outcome of an action on an orphaned issue.
+// Successful actions that link the issue to a task again resolve it.
+func (db *DB) RecordOrphanAction(userID, syncPairID, orphanID int, action, result, actionError string, resolved bool) (*OrphanedIssue, error) {
+	ctx := context.Background()
+	o, err := scanOrphanedIssue(db.pool.QueryRow(ctx,
+		`UPDATE orphaned_issues
+		 SET action=$5, action_result=$6, action_error=$7, acted_at=NOW(),
+		     resolved_at=CASE WHEN $8::boolean THEN NOW() ELSE resolved_at END
+		 WHERE `+scopeClause+` AND sync_pair_id=$3 AND id=$4
+		 RETURNING `+orphanedIssueColumns,
+		userID, db.organizationIDFor(userID), syncPairID, orphanID, action, result, actionError, resolved,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("orphaned issue not found")
+	}
+
+	if actionError == "" {
+		log.Printf("DB: Orphaned issue %s: %s (%s) by user %d\n", o.YouTrackIssueID, action, result, userID)
+	}
+	return o, nil
+}
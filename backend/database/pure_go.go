@@ -237,7 +237,7 @@ func (db *PureGoDB) GetUserOperations(userID int, limit int) ([]*SyncOperation,
 
 	// Get operations in reverse order (newest first)
 	for i := db.nextOperationID - 1; i > 0 && count < limit; i-- {
-		if operation, exists := db.operations[i]; exists && operation.UserID == userID {
+		if operation, exists := db.operations[i]; exists && operation.UserID == userID && operation.OperationType != permissionDeniedOperation {
 			operations = append(operations, operation)
 			count++
 		}
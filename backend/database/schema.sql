@@ -25,6 +25,43 @@ CREATE TABLE IF NOT EXISTS user_settings (
     updated_at            TIMESTAMPTZ NOT NULL DEFAULT NOW()
 );
 
+CREATE TABLE IF NOT EXISTS organizations (
+    id                    SERIAL PRIMARY KEY,
+    name                  TEXT NOT NULL,
+    created_by            INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at            TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at            TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS organization_members (
+    id              SERIAL PRIMARY KEY,
+    organization_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    user_id         INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE UNIQUE,
+    role            TEXT NOT NULL DEFAULT 'operator',
+    joined_at       TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_organization_members_org_id ON organization_members(organization_id);
+
+-- Roles are viewer/operator/admin; "member" predates the viewer and operator split
+ALTER TABLE organization_members ALTER COLUMN role SET DEFAULT 'operator';
+UPDATE organization_members SET role='operator' WHERE role='member';
+
+CREATE TABLE IF NOT EXISTS user_sessions (
+    id                  SERIAL PRIMARY KEY,
+    user_id             INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    refresh_token_hash  TEXT NOT NULL UNIQUE,
+    previous_token_hash TEXT NOT NULL DEFAULT '',
+    user_agent          TEXT NOT NULL DEFAULT '',
+    ip_address          TEXT NOT NULL DEFAULT '',
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_used_at        TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at          TIMESTAMPTZ NOT NULL,
+    revoked_at          TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_sessions_user_id ON user_sessions(user_id);
+
 CREATE TABLE IF NOT EXISTS sync_operations (
     id               SERIAL PRIMARY KEY,
     user_id          INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
@@ -42,8 +79,7 @@ CREATE TABLE IF NOT EXISTS ignored_tickets (
     asana_project_id TEXT NOT NULL,
     ticket_id        TEXT NOT NULL,
     ignore_type      TEXT NOT NULL,
-    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
-    UNIQUE(user_id, asana_project_id, ticket_id)
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW()
 );
 
 CREATE TABLE IF NOT EXISTS ticket_mappings (
@@ -54,8 +90,7 @@ CREATE TABLE IF NOT EXISTS ticket_mappings (
     youtrack_project_id  TEXT NOT NULL,
     youtrack_issue_id    TEXT NOT NULL,
     created_at           TIMESTAMPTZ NOT NULL DEFAULT NOW(),
-    updated_at           TIMESTAMPTZ NOT NULL DEFAULT NOW(),
-    UNIQUE(user_id, asana_task_id)
+    updated_at           TIMESTAMPTZ NOT NULL DEFAULT NOW()
 );
 
 CREATE TABLE IF NOT EXISTS rollback_snapshots (
@@ -67,6 +102,19 @@ CREATE TABLE IF NOT EXISTS rollback_snapshots (
     expires_at    TIMESTAMPTZ NOT NULL
 );
 
+ALTER TABLE rollback_snapshots ADD COLUMN IF NOT EXISTS pinned BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE rollback_snapshots ADD COLUMN IF NOT EXISTS compacted BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS snapshot_retention_policies (
+    user_id             INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    max_age_hours       INTEGER NOT NULL DEFAULT 24,
+    max_count           INTEGER NOT NULL DEFAULT 15,
+    max_storage_bytes   BIGINT NOT NULL DEFAULT 0,
+    compact_after_hours INTEGER NOT NULL DEFAULT 6,
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at          TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
 CREATE TABLE IF NOT EXISTS audit_logs (
     id            SERIAL PRIMARY KEY,
     operation_id  INTEGER NOT NULL REFERENCES sync_operations(id) ON DELETE CASCADE,
@@ -86,8 +134,7 @@ CREATE TABLE IF NOT EXISTS reverse_ignored_tickets (
     youtrack_project_id  TEXT NOT NULL,
     ticket_id            TEXT NOT NULL,
     ignore_type          TEXT NOT NULL,
-    created_at           TIMESTAMPTZ NOT NULL DEFAULT NOW(),
-    UNIQUE(user_id, youtrack_project_id, ticket_id)
+    created_at           TIMESTAMPTZ NOT NULL DEFAULT NOW()
 );
 
 CREATE TABLE IF NOT EXISTS reverse_auto_create_settings (
@@ -100,3 +147,251 @@ CREATE TABLE IF NOT EXISTS reverse_auto_create_settings (
     created_at         TIMESTAMPTZ NOT NULL DEFAULT NOW(),
     updated_at         TIMESTAMPTZ NOT NULL DEFAULT NOW()
 );
+
+-- Ignores and ticket mappings are shared within an organization. Personal rows
+-- (organization_id NULL) stay unique per user, shared rows per organization.
+ALTER TABLE ignored_tickets ADD COLUMN IF NOT EXISTS organization_id INTEGER REFERENCES organizations(id) ON DELETE CASCADE;
+ALTER TABLE ticket_mappings ADD COLUMN IF NOT EXISTS organization_id INTEGER REFERENCES organizations(id) ON DELETE CASCADE;
+ALTER TABLE reverse_ignored_tickets ADD COLUMN IF NOT EXISTS organization_id INTEGER REFERENCES organizations(id) ON DELETE CASCADE;
+
+DO $$
+DECLARE c RECORD;
+BEGIN
+    FOR c IN
+        SELECT conrelid::regclass AS tbl, conname FROM pg_constraint
+        WHERE contype = 'u'
+          AND conrelid IN ('ignored_tickets'::regclass, 'ticket_mappings'::regclass, 'reverse_ignored_tickets'::regclass)
+    LOOP
+        EXECUTE format('ALTER TABLE %s DROP CONSTRAINT %I', c.tbl, c.conname);
+    END LOOP;
+END $$;
+
+CREATE UNIQUE INDEX IF NOT EXISTS ux_ticket_mappings_personal ON ticket_mappings(user_id, asana_task_id) WHERE organization_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS ux_ticket_mappings_org ON ticket_mappings(organization_id, asana_task_id) WHERE organization_id IS NOT NULL;
+
+-- Sync pairs are named Asana project <-> YouTrack project connections, each
+-- with its own board configuration and ignore lists. Every scope (user or
+-- organization) has one default pair, seeded from the single board
+-- configuration that predates pairs.
+CREATE TABLE IF NOT EXISTS sync_pairs (
+    id                    SERIAL PRIMARY KEY,
+    user_id               INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    organization_id       INTEGER REFERENCES organizations(id) ON DELETE CASCADE,
+    name                  TEXT NOT NULL,
+    asana_project_id      TEXT NOT NULL DEFAULT '',
+    youtrack_project_id   TEXT NOT NULL DEFAULT '',
+    youtrack_board_id     TEXT NOT NULL DEFAULT '',
+    sync_board_membership BOOLEAN NOT NULL DEFAULT false,
+    custom_field_mappings JSONB NOT NULL DEFAULT '{}',
+    column_mappings       JSONB NOT NULL DEFAULT '{}',
+    is_default            BOOLEAN NOT NULL DEFAULT false,
+    created_at            TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at            TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_sync_pairs_user_id ON sync_pairs(user_id);
+CREATE INDEX IF NOT EXISTS idx_sync_pairs_organization_id ON sync_pairs(organization_id);
+CREATE UNIQUE INDEX IF NOT EXISTS ux_sync_pairs_default_personal ON sync_pairs(user_id) WHERE is_default AND organization_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS ux_sync_pairs_default_org ON sync_pairs(organization_id) WHERE is_default AND organization_id IS NOT NULL;
+
+INSERT INTO sync_pairs (user_id, name, asana_project_id, youtrack_project_id, youtrack_board_id,
+                        sync_board_membership, custom_field_mappings, column_mappings, is_default)
+SELECT s.user_id, 'Default', s.asana_project_id, s.youtrack_project_id, s.youtrack_board_id,
+       s.sync_board_membership, s.custom_field_mappings, s.column_mappings, true
+FROM user_settings s
+WHERE NOT EXISTS (SELECT 1 FROM sync_pairs p WHERE p.user_id = s.user_id);
+
+-- Organizations used to carry the shared board configuration themselves
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM information_schema.columns
+               WHERE table_name = 'organizations' AND column_name = 'asana_project_id') THEN
+        INSERT INTO sync_pairs (user_id, organization_id, name, asana_project_id, youtrack_project_id, youtrack_board_id,
+                                sync_board_membership, custom_field_mappings, column_mappings, is_default)
+        SELECT owner.user_id, o.id, 'Default', o.asana_project_id, o.youtrack_project_id, o.youtrack_board_id,
+               o.sync_board_membership, o.custom_field_mappings, o.column_mappings, true
+        FROM organizations o
+        CROSS JOIN LATERAL (
+            SELECT COALESCE(o.created_by, (SELECT m.user_id FROM organization_members m
+                                           WHERE m.organization_id = o.id ORDER BY m.joined_at LIMIT 1)) AS user_id
+        ) owner
+        WHERE owner.user_id IS NOT NULL
+          AND NOT EXISTS (SELECT 1 FROM sync_pairs p WHERE p.organization_id = o.id);
+
+        ALTER TABLE organizations
+            DROP COLUMN asana_project_id,
+            DROP COLUMN youtrack_project_id,
+            DROP COLUMN youtrack_board_id,
+            DROP COLUMN sync_board_membership,
+            DROP COLUMN custom_field_mappings,
+            DROP COLUMN column_mappings;
+    END IF;
+END $$;
+
+-- Ignore lists belong to a sync pair. Existing ignores move to the default
+-- pair of their scope when it still points at the same project.
+ALTER TABLE ignored_tickets ADD COLUMN IF NOT EXISTS sync_pair_id INTEGER REFERENCES sync_pairs(id) ON DELETE CASCADE;
+ALTER TABLE reverse_ignored_tickets ADD COLUMN IF NOT EXISTS sync_pair_id INTEGER REFERENCES sync_pairs(id) ON DELETE CASCADE;
+
+UPDATE ignored_tickets i SET sync_pair_id = p.id
+FROM sync_pairs p
+WHERE i.sync_pair_id IS NULL AND p.is_default AND p.asana_project_id = i.asana_project_id
+  AND ((i.organization_id IS NULL AND p.organization_id IS NULL AND p.user_id = i.user_id)
+       OR p.organization_id = i.organization_id);
+UPDATE reverse_ignored_tickets i SET sync_pair_id = p.id
+FROM sync_pairs p
+WHERE i.sync_pair_id IS NULL AND p.is_default AND p.youtrack_project_id = i.youtrack_project_id
+  AND ((i.organization_id IS NULL AND p.organization_id IS NULL AND p.user_id = i.user_id)
+       OR p.organization_id = i.organization_id);
+
+DROP INDEX IF EXISTS ux_ignored_tickets_personal;
+DROP INDEX IF EXISTS ux_ignored_tickets_org;
+DROP INDEX IF EXISTS ux_reverse_ignored_tickets_personal;
+DROP INDEX IF EXISTS ux_reverse_ignored_tickets_org;
+
+CREATE UNIQUE INDEX IF NOT EXISTS ux_ignored_tickets_pair_personal ON ignored_tickets(user_id, sync_pair_id, ticket_id) WHERE organization_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS ux_ignored_tickets_pair_org ON ignored_tickets(organization_id, sync_pair_id, ticket_id) WHERE organization_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS ux_reverse_ignored_tickets_pair_personal ON reverse_ignored_tickets(user_id, sync_pair_id, ticket_id) WHERE organization_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS ux_reverse_ignored_tickets_pair_org ON reverse_ignored_tickets(organization_id, sync_pair_id, ticket_id) WHERE organization_id IS NOT NULL;
+
+-- Per-pair tuning of the ticket matching strategies used by analysis
+ALTER TABLE sync_pairs ADD COLUMN IF NOT EXISTS matching_config JSONB NOT NULL DEFAULT '{}';
+
+-- Candidate pairings analysis was unsure about, waiting for review. Rejected
+-- suggestions are kept as negative matches so the pair isn't offered again.
+CREATE TABLE IF NOT EXISTS match_suggestions (
+    id                SERIAL PRIMARY KEY,
+    user_id           INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    organization_id   INTEGER REFERENCES organizations(id) ON DELETE CASCADE,
+    sync_pair_id      INTEGER NOT NULL REFERENCES sync_pairs(id) ON DELETE CASCADE,
+    asana_task_id     TEXT NOT NULL,
+    asana_task_name   TEXT NOT NULL DEFAULT '',
+    youtrack_issue_id TEXT NOT NULL,
+    youtrack_summary  TEXT NOT NULL DEFAULT '',
+    match_method      TEXT NOT NULL,
+    score             DOUBLE PRECISION NOT NULL,
+    status            TEXT NOT NULL DEFAULT 'pending',
+    reviewed_by       INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_at       TIMESTAMPTZ,
+    created_at        TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at        TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_match_suggestions_pair_status ON match_suggestions(sync_pair_id, status);
+CREATE UNIQUE INDEX IF NOT EXISTS ux_match_suggestions_personal ON match_suggestions(user_id, sync_pair_id, asana_task_id, youtrack_issue_id) WHERE organization_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS ux_match_suggestions_org ON match_suggestions(organization_id, sync_pair_id, asana_task_id, youtrack_issue_id) WHERE organization_id IS NOT NULL;
+
+-- Compact summary of every analysis run: bucket counts and the ticket IDs in
+-- each bucket, kept for drift history and run-to-run diffs
+CREATE TABLE IF NOT EXISTS analysis_runs (
+    id               SERIAL PRIMARY KEY,
+    user_id          INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    organization_id  INTEGER REFERENCES organizations(id) ON DELETE CASCADE,
+    sync_pair_id     INTEGER NOT NULL REFERENCES sync_pairs(id) ON DELETE CASCADE,
+    selected_columns TEXT NOT NULL DEFAULT '',
+    counts           JSONB NOT NULL DEFAULT '{}',
+    ticket_ids       JSONB NOT NULL DEFAULT '{}',
+    started_at       TIMESTAMPTZ NOT NULL,
+    duration_ms      INTEGER NOT NULL DEFAULT 0,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_analysis_runs_pair_created ON analysis_runs(sync_pair_id, created_at DESC);
+
+-- Per-pair policy for YouTrack issues whose Asana task vanished
+ALTER TABLE sync_pairs ADD COLUMN IF NOT EXISTS orphan_policy JSONB NOT NULL DEFAULT '{}';
+
+-- YouTrack issues whose Asana task vanished, from when analysis first saw
+-- them until they are linked to a task again. The action taken on each, by
+-- the pair's orphan policy or by hand, is kept with it.
+CREATE TABLE IF NOT EXISTS orphaned_issues (
+    id                SERIAL PRIMARY KEY,
+    user_id           INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    organization_id   INTEGER REFERENCES organizations(id) ON DELETE CASCADE,
+    sync_pair_id      INTEGER NOT NULL REFERENCES sync_pairs(id) ON DELETE CASCADE,
+    youtrack_issue_id TEXT NOT NULL,
+    youtrack_summary  TEXT NOT NULL DEFAULT '',
+    asana_task_id     TEXT NOT NULL DEFAULT '',
+    first_seen_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_seen_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    action            TEXT NOT NULL DEFAULT '',
+    action_result     TEXT NOT NULL DEFAULT '',
+    action_error      TEXT NOT NULL DEFAULT '',
+    acted_at          TIMESTAMPTZ,
+    resolved_at       TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_orphaned_issues_pair_open ON orphaned_issues(sync_pair_id) WHERE resolved_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS ux_orphaned_issues_personal ON orphaned_issues(user_id, sync_pair_id, youtrack_issue_id) WHERE organization_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS ux_orphaned_issues_org ON orphaned_issues(organization_id, sync_pair_id, youtrack_issue_id) WHERE organization_id IS NOT NULL;
+
+-- Per-pair mapping of Asana completion to YouTrack resolved states
+ALTER TABLE sync_pairs ADD COLUMN IF NOT EXISTS completion_config JSONB NOT NULL DEFAULT '{}';
+
+-- Completion both sides of a mapping last agreed on, so sync can tell which
+-- side completed or reopened the ticket
+ALTER TABLE ticket_mappings ADD COLUMN IF NOT EXISTS completed BOOLEAN;
+
+-- Why an orphaned issue's Asana task vanished: deleted, removed or archived
+ALTER TABLE orphaned_issues ADD COLUMN IF NOT EXISTS reason TEXT NOT NULL DEFAULT '';
+
+-- Per-pair mirroring of YouTrack estimation and spent time to Asana fields
+ALTER TABLE sync_pairs ADD COLUMN IF NOT EXISTS time_tracking JSONB NOT NULL DEFAULT '{}';
+
+-- Per-pair mapping of Asana fields or sections to YouTrack sprints, and of
+-- Asana story points to YouTrack
+ALTER TABLE sync_pairs ADD COLUMN IF NOT EXISTS sprint_config JSONB NOT NULL DEFAULT '{}';
+
+-- Explicit links between Asana users and YouTrack users, shared by all sync
+-- pairs of a scope. Assignees, followers and watchers are translated through
+-- them instead of matching names.
+CREATE TABLE IF NOT EXISTS user_identities (
+    id               SERIAL PRIMARY KEY,
+    user_id          INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    organization_id  INTEGER REFERENCES organizations(id) ON DELETE CASCADE,
+    asana_user_gid   TEXT NOT NULL,
+    asana_name       TEXT NOT NULL DEFAULT '',
+    youtrack_user_id TEXT NOT NULL,
+    youtrack_login   TEXT NOT NULL DEFAULT '',
+    youtrack_name    TEXT NOT NULL DEFAULT '',
+    email            TEXT NOT NULL DEFAULT '',
+    source           TEXT NOT NULL DEFAULT 'manual',
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS ux_user_identities_asana_personal ON user_identities(user_id, asana_user_gid) WHERE organization_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS ux_user_identities_asana_org ON user_identities(organization_id, asana_user_gid) WHERE organization_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS ux_user_identities_youtrack_personal ON user_identities(user_id, youtrack_user_id) WHERE organization_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS ux_user_identities_youtrack_org ON user_identities(organization_id, youtrack_user_id) WHERE organization_id IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS directory_users (
+    id              SERIAL PRIMARY KEY,
+    user_id         INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    organization_id INTEGER REFERENCES organizations(id) ON DELETE CASCADE,
+    platform        TEXT NOT NULL,
+    external_id     TEXT NOT NULL,
+    name            TEXT NOT NULL DEFAULT '',
+    email           TEXT NOT NULL DEFAULT '',
+    login           TEXT NOT NULL DEFAULT '',
+    refreshed_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS ux_directory_users_personal ON directory_users(user_id, platform, external_id) WHERE organization_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS ux_directory_users_org ON directory_users(organization_id, platform, external_id) WHERE organization_id IS NOT NULL;
+
+-- Organization-scoped rows cascade with their organization; DeleteOrganization
+-- hands them back to their members first. The columns added along with
+-- organizations used to be set to NULL and get their foreign keys replaced.
+DO $$
+DECLARE
+    t TEXT;
+BEGIN
+    FOREACH t IN ARRAY ARRAY['ignored_tickets', 'ticket_mappings', 'reverse_ignored_tickets'] LOOP
+        IF EXISTS (SELECT 1 FROM pg_constraint WHERE conname = t || '_organization_id_fkey' AND confdeltype = 'n') THEN
+            EXECUTE format('ALTER TABLE %I DROP CONSTRAINT %I', t, t || '_organization_id_fkey');
+            EXECUTE format('ALTER TABLE %I ADD CONSTRAINT %I FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE',
+                           t, t || '_organization_id_fkey');
+        END IF;
+    END LOOP;
+END $$;
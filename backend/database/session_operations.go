@@ -0,0 +1,154 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+// ─── User Session Operations ─────────────────────────────────────────────────
+
+const sessionColumns = `id, user_id, refresh_token_hash, previous_token_hash, user_agent, ip_address, created_at, last_used_at, expires_at, revoked_at`
+
+func scanSession(row interface{ Scan(...interface{}) error }) (*UserSession, error) {
+	s := &UserSession{}
+	err := row.Scan(&s.ID, &s.UserID, &s.RefreshTokenHash, &s.PreviousTokenHash, &s.UserAgent, &s.IPAddress,
+		&s.CreatedAt, &s.LastUsedAt, &s.ExpiresAt, &s.RevokedAt)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (db *DB) CreateUserSession(userID int, refreshTokenHash, userAgent, ipAddress string, expiresAt time.Time) (*UserSession, error) {
+	ctx := context.Background()
+
+	// Drop sessions that can no longer be used before adding a new one
+	db.pool.Exec(ctx,
+		`DELETE FROM user_sessions WHERE user_id=$1 AND (expires_at < NOW() OR revoked_at < NOW() - INTERVAL '7 days')`,
+		userID,
+	)
+
+	s, err := scanSession(db.pool.QueryRow(ctx,
+		`INSERT INTO user_sessions (user_id, refresh_token_hash, user_agent, ip_address, created_at, last_used_at, expires_at)
+		 VALUES ($1, $2, $3, $4, NOW(), NOW(), $5)
+		 RETURNING `+sessionColumns,
+		userID, refreshTokenHash, userAgent, ipAddress, expiresAt,
+	))
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("DB: Created session ID %d for user %d\n", s.ID, userID)
+	return s, nil
+}
+
+func (db *DB) GetUserSessionByID(sessionID int) (*UserSession, error) {
+	ctx := context.Background()
+	s, err := scanSession(db.pool.QueryRow(ctx,
+		`SELECT `+sessionColumns+` FROM user_sessions WHERE id=$1`,
+		sessionID,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("session not found")
+	}
+	return s, nil
+}
+
+// GetUserSessionByTokenHash finds the session whose current or previous refresh token matches
+func (db *DB) GetUserSessionByTokenHash(tokenHash string) (*UserSession, error) {
+	ctx := context.Background()
+	s, err := scanSession(db.pool.QueryRow(ctx,
+		`SELECT `+sessionColumns+` FROM user_sessions
+		 WHERE refresh_token_hash=$1 OR (previous_token_hash<>'' AND previous_token_hash=$1)`,
+		tokenHash,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("session not found")
+	}
+	return s, nil
+}
+
+func (db *DB) GetActiveUserSessions(userID int) ([]*UserSession, error) {
+	ctx := context.Background()
+	rows, err := db.pool.Query(ctx,
+		`SELECT `+sessionColumns+` FROM user_sessions
+		 WHERE user_id=$1 AND revoked_at IS NULL AND expires_at > NOW()
+		 ORDER BY last_used_at DESC`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []*UserSession
+	for rows.Next() {
+		s, err := scanSession(rows)
+		if err != nil {
+			continue
+		}
+		sessions = append(sessions, s)
+	}
+	return sessions, nil
+}
+
+// RotateUserSessionToken swaps in a new refresh token, keeping the old one for reuse detection.
+// It only succeeds if currentHash is still the session's live token.
+func (db *DB) RotateUserSessionToken(sessionID int, currentHash, newHash string, expiresAt time.Time) error {
+	ctx := context.Background()
+	result, err := db.pool.Exec(ctx,
+		`UPDATE user_sessions
+		 SET refresh_token_hash=$1, previous_token_hash=$2, last_used_at=NOW(), expires_at=$3
+		 WHERE id=$4 AND refresh_token_hash=$2 AND revoked_at IS NULL`,
+		newHash, currentHash, expiresAt, sessionID,
+	)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("session not found")
+	}
+	return nil
+}
+
+func (db *DB) RevokeUserSession(userID, sessionID int) error {
+	ctx := context.Background()
+	result, err := db.pool.Exec(ctx,
+		`UPDATE user_sessions SET revoked_at=NOW() WHERE id=$1 AND user_id=$2 AND revoked_at IS NULL`,
+		sessionID, userID,
+	)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("session not found")
+	}
+	log.Printf("DB: Revoked session ID %d for user %d\n", sessionID, userID)
+	return nil
+}
+
+// RevokeAllUserSessions revokes every active session of the user except exceptSessionID (0 = none)
+func (db *DB) RevokeAllUserSessions(userID, exceptSessionID int) (int64, error) {
+	ctx := context.Background()
+	result, err := db.pool.Exec(ctx,
+		`UPDATE user_sessions SET revoked_at=NOW() WHERE user_id=$1 AND id<>$2 AND revoked_at IS NULL`,
+		userID, exceptSessionID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	log.Printf("DB: Revoked %d sessions for user %d\n", result.RowsAffected(), userID)
+	return result.RowsAffected(), nil
+}
+
+// IsUserSessionActive is the per-request check behind access token validation
+func (db *DB) IsUserSessionActive(sessionID int) bool {
+	ctx := context.Background()
+	var active bool
+	err := db.pool.QueryRow(ctx,
+		`SELECT revoked_at IS NULL AND expires_at > NOW() FROM user_sessions WHERE id=$1`,
+		sessionID,
+	).Scan(&active)
+	return err == nil && active
+}
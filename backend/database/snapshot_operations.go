@@ -6,15 +6,35 @@ import (
 	"fmt"
 	"log"
 	"time"
+
+	"github.com/jackc/pgx/v5"
 )
 
 // ─── Rollback Snapshot Operations ────────────────────────────────────────────
 
+const snapshotColumns = `id, operation_id, user_id, snapshot_data, created_at, expires_at, pinned, compacted, pg_column_size(snapshot_data)`
+
+func scanSnapshot(row interface{ Scan(...interface{}) error }) (*RollbackSnapshot, error) {
+	s := &RollbackSnapshot{}
+	var dataJSON []byte
+	err := row.Scan(&s.ID, &s.OperationID, &s.UserID, &dataJSON, &s.CreatedAt, &s.ExpiresAt, &s.Pinned, &s.Compacted, &s.SizeBytes)
+	if err != nil {
+		return nil, err
+	}
+	json.Unmarshal(dataJSON, &s.SnapshotData)
+	return s, nil
+}
+
 func (db *DB) CreateRollbackSnapshot(snapshot *RollbackSnapshot) (*RollbackSnapshot, error) {
 	ctx := context.Background()
 
+	policy, err := db.GetSnapshotRetentionPolicy(snapshot.UserID)
+	if err != nil {
+		return nil, err
+	}
+
 	snapshot.CreatedAt = time.Now()
-	snapshot.ExpiresAt = time.Now().Add(24 * time.Hour)
+	snapshot.ExpiresAt = snapshot.CreatedAt.Add(time.Duration(policy.MaxAgeHours) * time.Hour)
 
 	dataJSON, err := json.Marshal(snapshot.SnapshotData)
 	if err != nil {
@@ -31,65 +51,82 @@ func (db *DB) CreateRollbackSnapshot(snapshot *RollbackSnapshot) (*RollbackSnaps
 		return nil, err
 	}
 
-	// Enforce 15-snapshot limit per user (delete oldest beyond limit)
-	db.enforceSnapshotLimit(ctx, snapshot.UserID, 15)
+	// Enforce the user's count/storage limits (pinned snapshots are exempt)
+	db.enforceSnapshotRetention(ctx, policy)
 
 	log.Printf("DB: Created rollback snapshot ID %d for operation %d\n", snapshot.ID, snapshot.OperationID)
 	return snapshot, nil
 }
 
-func (db *DB) enforceSnapshotLimit(ctx context.Context, userID int, maxSnapshots int) {
+// enforceSnapshotRetention deletes the oldest unpinned snapshots beyond the
+// policy's count limit, then beyond its storage limit.
+func (db *DB) enforceSnapshotRetention(ctx context.Context, policy *SnapshotRetentionPolicy) {
 	_, err := db.pool.Exec(ctx,
 		`DELETE FROM rollback_snapshots
 		 WHERE id IN (
 		   SELECT id FROM rollback_snapshots
-		   WHERE user_id = $1
+		   WHERE user_id = $1 AND pinned = false
 		   ORDER BY created_at DESC
 		   OFFSET $2
 		 )`,
-		userID, maxSnapshots,
+		policy.UserID, policy.MaxCount,
 	)
 	if err != nil {
-		log.Printf("DB: Warning: failed to enforce snapshot limit: %v\n", err)
+		log.Printf("DB: Warning: failed to enforce snapshot count limit: %v\n", err)
+	}
+
+	if policy.MaxStorageBytes <= 0 {
+		return
+	}
+	result, err := db.pool.Exec(ctx,
+		`DELETE FROM rollback_snapshots
+		 WHERE id IN (
+		   SELECT id FROM (
+		     SELECT id, SUM(pg_column_size(snapshot_data)) OVER (ORDER BY created_at DESC) AS running_bytes
+		     FROM rollback_snapshots
+		     WHERE user_id = $1 AND pinned = false
+		   ) sized
+		   WHERE running_bytes > $2
+		 )`,
+		policy.UserID, policy.MaxStorageBytes,
+	)
+	if err != nil {
+		log.Printf("DB: Warning: failed to enforce snapshot storage limit: %v\n", err)
+		return
+	}
+	if result.RowsAffected() > 0 {
+		log.Printf("DB: Dropped %d snapshots over storage limit for user %d\n", result.RowsAffected(), policy.UserID)
 	}
 }
 
 func (db *DB) GetSnapshotByOperationID(operationID int) (*RollbackSnapshot, error) {
 	ctx := context.Background()
-	s := &RollbackSnapshot{}
-	var dataJSON []byte
-	err := db.pool.QueryRow(ctx,
-		`SELECT id, operation_id, user_id, snapshot_data, created_at, expires_at
-		 FROM rollback_snapshots WHERE operation_id=$1`,
+	s, err := scanSnapshot(db.pool.QueryRow(ctx,
+		`SELECT `+snapshotColumns+` FROM rollback_snapshots WHERE operation_id=$1`,
 		operationID,
-	).Scan(&s.ID, &s.OperationID, &s.UserID, &dataJSON, &s.CreatedAt, &s.ExpiresAt)
+	))
 	if err != nil {
 		return nil, fmt.Errorf("snapshot not found for operation %d", operationID)
 	}
-	json.Unmarshal(dataJSON, &s.SnapshotData)
 	return s, nil
 }
 
 func (db *DB) GetSnapshotByID(snapshotID int) (*RollbackSnapshot, error) {
 	ctx := context.Background()
-	s := &RollbackSnapshot{}
-	var dataJSON []byte
-	err := db.pool.QueryRow(ctx,
-		`SELECT id, operation_id, user_id, snapshot_data, created_at, expires_at
-		 FROM rollback_snapshots WHERE id=$1`,
+	s, err := scanSnapshot(db.pool.QueryRow(ctx,
+		`SELECT `+snapshotColumns+` FROM rollback_snapshots WHERE id=$1`,
 		snapshotID,
-	).Scan(&s.ID, &s.OperationID, &s.UserID, &dataJSON, &s.CreatedAt, &s.ExpiresAt)
+	))
 	if err != nil {
 		return nil, fmt.Errorf("snapshot not found")
 	}
-	json.Unmarshal(dataJSON, &s.SnapshotData)
 	return s, nil
 }
 
 func (db *DB) GetUserSnapshots(userID int, limit int) ([]*RollbackSnapshot, error) {
 	ctx := context.Background()
 	rows, err := db.pool.Query(ctx,
-		`SELECT id, operation_id, user_id, snapshot_data, created_at, expires_at
+		`SELECT `+snapshotColumns+`
 		 FROM rollback_snapshots WHERE user_id=$1 ORDER BY created_at DESC LIMIT $2`,
 		userID, limit,
 	)
@@ -100,12 +137,10 @@ func (db *DB) GetUserSnapshots(userID int, limit int) ([]*RollbackSnapshot, erro
 
 	var snapshots []*RollbackSnapshot
 	for rows.Next() {
-		s := &RollbackSnapshot{}
-		var dataJSON []byte
-		if err := rows.Scan(&s.ID, &s.OperationID, &s.UserID, &dataJSON, &s.CreatedAt, &s.ExpiresAt); err != nil {
+		s, err := scanSnapshot(rows)
+		if err != nil {
 			continue
 		}
-		json.Unmarshal(dataJSON, &s.SnapshotData)
 		snapshots = append(snapshots, s)
 	}
 	return snapshots, nil
@@ -131,6 +166,33 @@ func (db *DB) UpdateRollbackSnapshot(snapshot *RollbackSnapshot) error {
 	return nil
 }
 
+// SetSnapshotPinned pins or unpins a snapshot owned by the user.
+// Unpinning an already-expired snapshot gives it a fresh window from the user's policy.
+func (db *DB) SetSnapshotPinned(userID, snapshotID int, pinned bool) error {
+	ctx := context.Background()
+
+	policy, err := db.GetSnapshotRetentionPolicy(userID)
+	if err != nil {
+		return err
+	}
+
+	result, err := db.pool.Exec(ctx,
+		`UPDATE rollback_snapshots
+		 SET pinned=$1,
+		     expires_at=CASE WHEN $1 = false AND expires_at < NOW() THEN $2 ELSE expires_at END
+		 WHERE id=$3 AND user_id=$4`,
+		pinned, time.Now().Add(time.Duration(policy.MaxAgeHours)*time.Hour), snapshotID, userID,
+	)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("snapshot not found")
+	}
+	log.Printf("DB: Set pinned=%v on rollback snapshot ID %d\n", pinned, snapshotID)
+	return nil
+}
+
 func (db *DB) DeleteSnapshot(snapshotID int) error {
 	ctx := context.Background()
 	result, err := db.pool.Exec(ctx, `DELETE FROM rollback_snapshots WHERE id=$1`, snapshotID)
@@ -146,7 +208,7 @@ func (db *DB) DeleteSnapshot(snapshotID int) error {
 
 func (db *DB) CleanupExpiredSnapshots() error {
 	ctx := context.Background()
-	result, err := db.pool.Exec(ctx, `DELETE FROM rollback_snapshots WHERE expires_at < NOW()`)
+	result, err := db.pool.Exec(ctx, `DELETE FROM rollback_snapshots WHERE expires_at < NOW() AND pinned = false`)
 	if err != nil {
 		return err
 	}
@@ -156,6 +218,113 @@ func (db *DB) CleanupExpiredSnapshots() error {
 	return nil
 }
 
+// CompactSnapshots strips full ticket copies and captured settings from
+// snapshots older than their owner's compact_after_hours. Statuses, created
+// tickets, mapping and ignore changes are kept, so compacted snapshots can
+// still be rolled back.
+func (db *DB) CompactSnapshots() (int, error) {
+	ctx := context.Background()
+	rows, err := db.pool.Query(ctx,
+		`SELECT s.id FROM rollback_snapshots s
+		 LEFT JOIN snapshot_retention_policies p ON p.user_id = s.user_id
+		 WHERE s.compacted = false
+		   AND COALESCE(p.compact_after_hours, $1) > 0
+		   AND s.created_at < NOW() - make_interval(hours => COALESCE(p.compact_after_hours, $1))`,
+		DefaultSnapshotCompactAfterHours,
+	)
+	if err != nil {
+		return 0, err
+	}
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	rows.Close()
+
+	compacted := 0
+	for _, id := range ids {
+		s, err := db.GetSnapshotByID(id)
+		if err != nil {
+			continue
+		}
+		for i := range s.SnapshotData.OriginalTickets {
+			s.SnapshotData.OriginalTickets[i].OriginalData = nil
+		}
+		s.SnapshotData.ColumnMappings = nil
+
+		dataJSON, err := json.Marshal(s.SnapshotData)
+		if err != nil {
+			continue
+		}
+		if _, err := db.pool.Exec(ctx,
+			`UPDATE rollback_snapshots SET snapshot_data=$1, compacted=true WHERE id=$2`,
+			dataJSON, id,
+		); err != nil {
+			log.Printf("DB: Warning: failed to compact snapshot %d: %v\n", id, err)
+			continue
+		}
+		compacted++
+	}
+	if compacted > 0 {
+		log.Printf("DB: Compacted %d rollback snapshots\n", compacted)
+	}
+	return compacted, nil
+}
+
+// ─── Snapshot Retention Policy Operations ────────────────────────────────────
+
+// GetSnapshotRetentionPolicy returns the user's stored policy, or the defaults if none is stored
+func (db *DB) GetSnapshotRetentionPolicy(userID int) (*SnapshotRetentionPolicy, error) {
+	ctx := context.Background()
+	p := &SnapshotRetentionPolicy{}
+	err := db.pool.QueryRow(ctx,
+		`SELECT user_id, max_age_hours, max_count, max_storage_bytes, compact_after_hours, created_at, updated_at
+		 FROM snapshot_retention_policies WHERE user_id=$1`,
+		userID,
+	).Scan(&p.UserID, &p.MaxAgeHours, &p.MaxCount, &p.MaxStorageBytes, &p.CompactAfterHours, &p.CreatedAt, &p.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return DefaultSnapshotRetentionPolicy(userID), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// UpsertSnapshotRetentionPolicy stores the policy and applies it to existing snapshots
+func (db *DB) UpsertSnapshotRetentionPolicy(policy *SnapshotRetentionPolicy) (*SnapshotRetentionPolicy, error) {
+	ctx := context.Background()
+	p := &SnapshotRetentionPolicy{}
+	err := db.pool.QueryRow(ctx,
+		`INSERT INTO snapshot_retention_policies (user_id, max_age_hours, max_count, max_storage_bytes, compact_after_hours, created_at, updated_at)
+		 VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
+		 ON CONFLICT (user_id) DO UPDATE
+		   SET max_age_hours=$2, max_count=$3, max_storage_bytes=$4, compact_after_hours=$5, updated_at=NOW()
+		 RETURNING user_id, max_age_hours, max_count, max_storage_bytes, compact_after_hours, created_at, updated_at`,
+		policy.UserID, policy.MaxAgeHours, policy.MaxCount, policy.MaxStorageBytes, policy.CompactAfterHours,
+	).Scan(&p.UserID, &p.MaxAgeHours, &p.MaxCount, &p.MaxStorageBytes, &p.CompactAfterHours, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	// Re-derive expiry of existing snapshots from the new max age
+	_, err = db.pool.Exec(ctx,
+		`UPDATE rollback_snapshots SET expires_at = created_at + make_interval(hours => $1)
+		 WHERE user_id=$2`,
+		p.MaxAgeHours, p.UserID,
+	)
+	if err != nil {
+		log.Printf("DB: Warning: failed to re-apply snapshot expiry for user %d: %v\n", p.UserID, err)
+	}
+	db.enforceSnapshotRetention(ctx, p)
+
+	log.Printf("DB: Updated snapshot retention policy for user %d\n", p.UserID)
+	return p, nil
+}
+
 // ─── Audit Log Operations ─────────────────────────────────────────────────────
 
 func (db *DB) CreateAuditLogEntry(entry *AuditLogEntry) (*AuditLogEntry, error) {
@@ -175,6 +344,40 @@ func (db *DB) CreateAuditLogEntry(entry *AuditLogEntry) (*AuditLogEntry, error)
 	return entry, nil
 }
 
+// permissionDeniedOperation is the operation type of recorded denials; it is
+// left out of the operation history, which lists sync runs only
+const permissionDeniedOperation = "permission_denied"
+
+// LogPermissionDenied records a rejected request as a failed operation with a
+// "permission_denied" audit entry, kept out of the sync history.
+func (db *DB) LogPermissionDenied(userID int, userEmail, method, path, role, requiredRole string) error {
+	message := fmt.Sprintf("%s %s requires role %s (has %s)", method, path, requiredRole, role)
+	op, err := db.CreateOperation(userID, permissionDeniedOperation, map[string]interface{}{
+		"method":        method,
+		"path":          path,
+		"role":          role,
+		"required_role": requiredRole,
+	})
+	if err != nil {
+		return err
+	}
+	if err := db.UpdateOperationStatus(op.ID, "failed", &message); err != nil {
+		return err
+	}
+
+	_, err = db.CreateAuditLogEntry(&AuditLogEntry{
+		OperationID: op.ID,
+		TicketID:    method + " " + path,
+		Platform:    "api",
+		ActionType:  permissionDeniedOperation,
+		UserEmail:   userEmail,
+		OldValue:    role,
+		NewValue:    requiredRole,
+		FieldName:   "role",
+	})
+	return err
+}
+
 func (db *DB) GetAuditLogsByOperationID(operationID int) ([]*AuditLogEntry, error) {
 	ctx := context.Background()
 	rows, err := db.pool.Query(ctx,
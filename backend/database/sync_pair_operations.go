@@ -0,0 +1,274 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ─── Sync Pair Operations ────────────────────────────────────────────────────
+//
+// Sync pairs are scoped like ignores and mappings (see scopeClause): personal
+// pairs belong to a user, shared ones to their organization. A pair ID of 0
+// always means the scope's default pair.
+
+// ErrSyncPairNotFound is returned for pairs outside the user's scope
+var ErrSyncPairNotFound = errors.New("sync pair not found")
+
+const syncPairColumns = `id, user_id, organization_id, name, asana_project_id, youtrack_project_id, youtrack_board_id,
+	sync_board_membership, custom_field_mappings, column_mappings, matching_config, orphan_policy, completion_config, time_tracking, sprint_config, is_default, created_at, updated_at`
+
+func scanSyncPair(row interface{ Scan(...interface{}) error }) (*SyncPair, error) {
+	p := &SyncPair{}
+	var cfmJSON, cmJSON, mcJSON, opJSON, ccJSON, ttJSON, scJSON []byte
+	err := row.Scan(&p.ID, &p.UserID, &p.OrganizationID, &p.Name, &p.AsanaProjectID, &p.YouTrackProjectID,
+		&p.YouTrackBoardID, &p.SyncBoardMembership, &cfmJSON, &cmJSON, &mcJSON, &opJSON, &ccJSON, &ttJSON, &scJSON, &p.IsDefault, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	json.Unmarshal(cfmJSON, &p.CustomFieldMappings)
+	json.Unmarshal(cmJSON, &p.ColumnMappings)
+	json.Unmarshal(mcJSON, &p.MatchingConfig)
+	json.Unmarshal(opJSON, &p.OrphanPolicy)
+	json.Unmarshal(ccJSON, &p.CompletionConfig)
+	json.Unmarshal(ttJSON, &p.TimeTracking)
+	json.Unmarshal(scJSON, &p.SprintConfig)
+	return p, nil
+}
+
+// ensureDefaultSyncPair creates the scope's default pair from the user's
+// board settings if the scope has none, e.g. for new users or after leaving
+// an organization
+func (db *DB) ensureDefaultSyncPair(userID int) {
+	ctx := context.Background()
+	_, err := db.pool.Exec(ctx,
+		`INSERT INTO sync_pairs (user_id, organization_id, name, asana_project_id, youtrack_project_id, youtrack_board_id,
+		                         sync_board_membership, custom_field_mappings, column_mappings, is_default, created_at, updated_at)
+		 SELECT $1, $2::int, 'Default', s.asana_project_id, s.youtrack_project_id, s.youtrack_board_id,
+		        s.sync_board_membership, s.custom_field_mappings, s.column_mappings, true, NOW(), NOW()
+		 FROM user_settings s
+		 WHERE s.user_id=$1
+		   AND NOT EXISTS (SELECT 1 FROM sync_pairs WHERE `+scopeClause+` AND is_default)
+		 ON CONFLICT DO NOTHING`,
+		userID, db.organizationIDFor(userID),
+	)
+	if err != nil {
+		log.Printf("DB: Failed to create default sync pair for user %d: %v\n", userID, err)
+	}
+}
+
+func (db *DB) GetSyncPairs(userID int) ([]*SyncPair, error) {
+	ctx := context.Background()
+	db.ensureDefaultSyncPair(userID)
+
+	rows, err := db.pool.Query(ctx,
+		`SELECT `+syncPairColumns+` FROM sync_pairs WHERE `+scopeClause+` ORDER BY is_default DESC, name`,
+		userID, db.organizationIDFor(userID),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pairs []*SyncPair
+	for rows.Next() {
+		p, err := scanSyncPair(rows)
+		if err != nil {
+			continue
+		}
+		pairs = append(pairs, p)
+	}
+	return pairs, nil
+}
+
+// GetSyncPair returns a pair in the user's scope; pairID 0 returns the default pair
+func (db *DB) GetSyncPair(userID, pairID int) (*SyncPair, error) {
+	ctx := context.Background()
+	orgID := db.organizationIDFor(userID)
+
+	var pair *SyncPair
+	var err error
+	if pairID == 0 {
+		db.ensureDefaultSyncPair(userID)
+		pair, err = scanSyncPair(db.pool.QueryRow(ctx,
+			`SELECT `+syncPairColumns+` FROM sync_pairs WHERE `+scopeClause+` AND is_default`,
+			userID, orgID,
+		))
+	} else {
+		pair, err = scanSyncPair(db.pool.QueryRow(ctx,
+			`SELECT `+syncPairColumns+` FROM sync_pairs WHERE `+scopeClause+` AND id=$3`,
+			userID, orgID, pairID,
+		))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("sync pair not found")
+	}
+	return pair, nil
+}
+
+func (db *DB) CreateSyncPair(userID int, pair *SyncPair) (*SyncPair, error) {
+	ctx := context.Background()
+	cfmJSON, _ := json.Marshal(pair.CustomFieldMappings)
+	cmJSON, _ := json.Marshal(pair.ColumnMappings)
+	mcJSON, _ := json.Marshal(pair.MatchingConfig)
+	opJSON, _ := json.Marshal(pair.OrphanPolicy)
+	ccJSON, _ := json.Marshal(pair.CompletionConfig)
+	ttJSON, _ := json.Marshal(pair.TimeTracking)
+	scJSON, _ := json.Marshal(pair.SprintConfig)
+
+	created, err := scanSyncPair(db.pool.QueryRow(ctx,
+		`INSERT INTO sync_pairs (user_id, organization_id, name, asana_project_id, youtrack_project_id, youtrack_board_id,
+		                         sync_board_membership, custom_field_mappings, column_mappings, matching_config, orphan_policy, completion_config, time_tracking, sprint_config, is_default, created_at, updated_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, false, NOW(), NOW())
+		 RETURNING `+syncPairColumns,
+		userID, db.organizationIDFor(userID), pair.Name, pair.AsanaProjectID, pair.YouTrackProjectID,
+		pair.YouTrackBoardID, pair.SyncBoardMembership, cfmJSON, cmJSON, mcJSON, opJSON, ccJSON, ttJSON, scJSON,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sync pair: %w", err)
+	}
+
+	log.Printf("DB: Sync pair created: %s (ID: %d) by user %d\n", created.Name, created.ID, userID)
+	return created, nil
+}
+
+// UpdateSyncPair stores a pair's name, board configuration, matching settings,
+// orphan policy, completion mapping, time tracking and sprint mapping
+func (db *DB) UpdateSyncPair(userID int, pair *SyncPair) (*SyncPair, error) {
+	ctx := context.Background()
+	cfmJSON, _ := json.Marshal(pair.CustomFieldMappings)
+	cmJSON, _ := json.Marshal(pair.ColumnMappings)
+	mcJSON, _ := json.Marshal(pair.MatchingConfig)
+	opJSON, _ := json.Marshal(pair.OrphanPolicy)
+	ccJSON, _ := json.Marshal(pair.CompletionConfig)
+	ttJSON, _ := json.Marshal(pair.TimeTracking)
+	scJSON, _ := json.Marshal(pair.SprintConfig)
+
+	updated, err := scanSyncPair(db.pool.QueryRow(ctx,
+		`UPDATE sync_pairs
+		 SET name=$4, asana_project_id=$5, youtrack_project_id=$6, youtrack_board_id=$7,
+		     sync_board_membership=$8, custom_field_mappings=$9, column_mappings=$10, matching_config=$11, orphan_policy=$12, completion_config=$13, time_tracking=$14, sprint_config=$15, updated_at=NOW()
+		 WHERE `+scopeClause+` AND id=$3
+		 RETURNING `+syncPairColumns,
+		userID, db.organizationIDFor(userID), pair.ID, pair.Name, pair.AsanaProjectID, pair.YouTrackProjectID,
+		pair.YouTrackBoardID, pair.SyncBoardMembership, cfmJSON, cmJSON, mcJSON, opJSON, ccJSON, ttJSON, scJSON,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrSyncPairNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update sync pair: %w", err)
+	}
+	return updated, nil
+}
+
+// SetDefaultSyncPair makes a pair the one used when no pair is requested
+func (db *DB) SetDefaultSyncPair(userID, pairID int) error {
+	ctx := context.Background()
+	orgID := db.organizationIDFor(userID)
+
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var exists bool
+	tx.QueryRow(ctx,
+		`SELECT EXISTS(SELECT 1 FROM sync_pairs WHERE `+scopeClause+` AND id=$3)`,
+		userID, orgID, pairID,
+	).Scan(&exists)
+	if !exists {
+		return fmt.Errorf("sync pair not found")
+	}
+
+	if _, err := tx.Exec(ctx,
+		`UPDATE sync_pairs SET is_default=false, updated_at=NOW() WHERE `+scopeClause+` AND is_default AND id<>$3`,
+		userID, orgID, pairID,
+	); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx,
+		`UPDATE sync_pairs SET is_default=true, updated_at=NOW() WHERE id=$1`,
+		pairID,
+	); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	log.Printf("DB: Sync pair %d is now the default for user %d\n", pairID, userID)
+	return nil
+}
+
+// DeleteSyncPair removes a pair and its ignore lists. The default pair can't
+// be deleted; another pair has to become the default first.
+func (db *DB) DeleteSyncPair(userID, pairID int) error {
+	ctx := context.Background()
+	pair, err := db.GetSyncPair(userID, pairID)
+	if err != nil {
+		return err
+	}
+	if pair.IsDefault {
+		return fmt.Errorf("the default sync pair cannot be deleted")
+	}
+
+	if _, err := db.pool.Exec(ctx, `DELETE FROM sync_pairs WHERE id=$1`, pair.ID); err != nil {
+		return fmt.Errorf("failed to delete sync pair: %w", err)
+	}
+
+	log.Printf("DB: Sync pair deleted: %d by user %d\n", pair.ID, userID)
+	return nil
+}
+
+// updateSyncPairBoardConfig stores the board configuration edited through the
+// settings endpoint on the given pair
+func (db *DB) updateSyncPairBoardConfig(userID, pairID int, asanaProjectID, youtrackProjectID, youtrackBoardID string, syncBoardMembership bool, cfmJSON, cmJSON []byte) error {
+	ctx := context.Background()
+	pair, err := db.GetSyncPair(userID, pairID)
+	if err != nil {
+		return err
+	}
+	_, err = db.pool.Exec(ctx,
+		`UPDATE sync_pairs
+		 SET asana_project_id=$1, youtrack_project_id=$2, youtrack_board_id=$3,
+		     sync_board_membership=$4, custom_field_mappings=$5, column_mappings=$6, updated_at=NOW()
+		 WHERE id=$7`,
+		asanaProjectID, youtrackProjectID, youtrackBoardID, syncBoardMembership, cfmJSON, cmJSON, pair.ID,
+	)
+	return err
+}
+
+// applySyncPair replaces the board configuration with the one of the given
+// pair (0 for the default pair) and records the user's organization, if any
+func (db *DB) applySyncPair(s *UserSettings, pairID int) error {
+	if membership, err := db.GetUserMembership(s.UserID); err == nil {
+		s.OrganizationID = &membership.OrganizationID
+		s.OrganizationRole = membership.Role
+	}
+
+	pair, err := db.GetSyncPair(s.UserID, pairID)
+	if err != nil {
+		return err
+	}
+	s.AsanaProjectID = pair.AsanaProjectID
+	s.YouTrackProjectID = pair.YouTrackProjectID
+	s.YouTrackBoardID = pair.YouTrackBoardID
+	s.SyncBoardMembership = pair.SyncBoardMembership
+	s.CustomFieldMappings = pair.CustomFieldMappings
+	s.ColumnMappings = pair.ColumnMappings
+	s.MatchingConfig = pair.MatchingConfig
+	s.OrphanPolicy = pair.OrphanPolicy
+	s.CompletionConfig = pair.CompletionConfig
+	s.TimeTracking = pair.TimeTracking
+	s.SprintConfig = pair.SprintConfig
+	s.SyncPairID = pair.ID
+	s.SyncPairName = pair.Name
+	return nil
+}
@@ -0,0 +1,231 @@
+package legacy
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	configpkg "asana-youtrack-sync/config"
+	"asana-youtrack-sync/database"
+)
+
+// BucketIgnored holds the ignored tickets in an analysis run's summary
+const BucketIgnored = "ignored"
+
+const (
+	defaultHistoryLimit = 100
+	maxHistoryLimit     = 1000
+)
+
+// AnalysisHistoryService keeps a compact record of every analysis of a sync
+// pair so drift can be followed over time and two runs compared
+type AnalysisHistoryService struct {
+	db            *database.DB
+	configService *configpkg.Service
+}
+
+// NewAnalysisHistoryService creates a new analysis history service
+func NewAnalysisHistoryService(db *database.DB, configService *configpkg.Service) *AnalysisHistoryService {
+	return &AnalysisHistoryService{
+		db:            db,
+		configService: configService,
+	}
+}
+
+// BucketDiff lists the tickets that entered and left a bucket between two runs
+type BucketDiff struct {
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+	Change  int      `json:"change"` // difference in the bucket's count
+}
+
+// AnalysisDiff compares two analysis runs of the same sync pair
+type AnalysisDiff struct {
+	From    *database.AnalysisRun `json:"from"`
+	To      *database.AnalysisRun `json:"to"`
+	Buckets map[string]BucketDiff `json:"buckets"`
+}
+
+// record saves the summary of a finished analysis. Failures are only logged;
+// the analysis itself has succeeded.
+func (s *AnalysisHistoryService) record(userID, syncPairID int, analysis *TicketAnalysis, startedAt time.Time) {
+	counts, ticketIDs := summarizeAnalysis(analysis)
+	run := &database.AnalysisRun{
+		SelectedColumns: analysis.SelectedColumn,
+		Counts:          counts,
+		TicketIDs:       ticketIDs,
+		StartedAt:       startedAt,
+		DurationMs:      int(time.Since(startedAt).Milliseconds()),
+	}
+	if err := s.db.SaveAnalysisRun(userID, syncPairID, run); err != nil {
+		fmt.Printf("ANALYSIS: Failed to record analysis run: %v\n", err)
+	}
+}
+
+// summarizeAnalysis reduces an analysis to the ticket IDs in each bucket and
+// their counts. Asana buckets hold task GIDs; orphaned_youtrack holds issue
+// IDs.
+func summarizeAnalysis(analysis *TicketAnalysis) (map[string]int, map[string][]string) {
+	ticketIDs := map[string][]string{
+		BucketMatched:            {},
+		BucketMismatched:         {},
+		BucketMissingYouTrack:    {},
+		BucketFindingsTickets:    {},
+		BucketFindingsAlerts:     {},
+		BucketReadyForStage:      {},
+		BucketBlockedTickets:     {},
+		BucketOrphanedYouTrack:   {},
+		BucketAlreadyExists:      {},
+		BucketMissingBoard:       {},
+		BucketPriorityMismatches: {},
+		BucketMatchSuggestions:   {},
+		BucketSprintMismatches:   {},
+		BucketIgnored:            {},
+	}
+	add := func(bucket, id string) {
+		ticketIDs[bucket] = append(ticketIDs[bucket], id)
+	}
+
+	for _, t := range analysis.Matched {
+		add(BucketMatched, t.AsanaTask.GID)
+	}
+	for _, t := range analysis.Mismatched {
+		add(BucketMismatched, t.AsanaTask.GID)
+	}
+	for _, t := range analysis.MissingYouTrack {
+		add(BucketMissingYouTrack, t.GID)
+	}
+	for _, t := range analysis.FindingsTickets {
+		add(BucketFindingsTickets, t.GID)
+	}
+	for _, t := range analysis.FindingsAlerts {
+		add(BucketFindingsAlerts, t.AsanaTask.GID)
+	}
+	for _, t := range analysis.ReadyForStage {
+		add(BucketReadyForStage, t.GID)
+	}
+	for _, t := range analysis.BlockedTickets {
+		add(BucketBlockedTickets, t.AsanaTask.GID)
+	}
+	for _, issue := range analysis.OrphanedYouTrack {
+		add(BucketOrphanedYouTrack, issue.ID)
+	}
+	for _, t := range analysis.AlreadyExists {
+		add(BucketAlreadyExists, t.AsanaTask.GID)
+	}
+	for _, t := range analysis.MissingBoard {
+		add(BucketMissingBoard, t.AsanaTask.GID)
+	}
+	for _, t := range analysis.PriorityMismatches {
+		add(BucketPriorityMismatches, t.AsanaTask.GID)
+	}
+	for _, t := range analysis.MatchSuggestions {
+		add(BucketMatchSuggestions, t.AsanaTask.GID)
+	}
+	for _, t := range analysis.SprintMismatches {
+		add(BucketSprintMismatches, t.AsanaTask.GID)
+	}
+	for _, id := range analysis.Ignored {
+		add(BucketIgnored, id)
+	}
+
+	counts := make(map[string]int, len(ticketIDs))
+	for bucket, ids := range ticketIDs {
+		sort.Strings(ids)
+		counts[bucket] = len(ids)
+	}
+	return counts, ticketIDs
+}
+
+// GetHistory lists the current sync pair's analysis runs since the given time,
+// newest first. A limit of 0 uses the default.
+func (s *AnalysisHistoryService) GetHistory(userID int, since time.Time, limit int) ([]*database.AnalysisRun, error) {
+	if limit <= 0 {
+		limit = defaultHistoryLimit
+	}
+	if limit > maxHistoryLimit {
+		limit = maxHistoryLimit
+	}
+
+	settings, err := s.configService.GetSettings(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user settings: %w", err)
+	}
+
+	runs, err := s.db.GetAnalysisRuns(userID, settings.SyncPairID, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get analysis history: %w", err)
+	}
+	if runs == nil {
+		runs = []*database.AnalysisRun{}
+	}
+	return runs, nil
+}
+
+// Diff compares two runs of the current sync pair. A toID of 0 takes the
+// latest run; a fromID of 0 takes the run of the same columns before it.
+func (s *AnalysisHistoryService) Diff(userID, fromID, toID int) (*AnalysisDiff, error) {
+	settings, err := s.configService.GetSettings(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user settings: %w", err)
+	}
+
+	to, err := s.db.GetAnalysisRun(userID, settings.SyncPairID, toID)
+	if err != nil {
+		return nil, err
+	}
+	var from *database.AnalysisRun
+	if fromID == 0 {
+		from, err = s.db.GetPreviousAnalysisRun(userID, settings.SyncPairID, to)
+	} else {
+		from, err = s.db.GetAnalysisRun(userID, settings.SyncPairID, fromID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	buckets := make(map[string]BucketDiff)
+	for bucket := range mergeBucketNames(from.TicketIDs, to.TicketIDs) {
+		before := make(map[string]bool, len(from.TicketIDs[bucket]))
+		for _, id := range from.TicketIDs[bucket] {
+			before[id] = true
+		}
+		after := make(map[string]bool, len(to.TicketIDs[bucket]))
+		for _, id := range to.TicketIDs[bucket] {
+			after[id] = true
+		}
+
+		diff := BucketDiff{
+			Added:   []string{},
+			Removed: []string{},
+			Change:  to.Counts[bucket] - from.Counts[bucket],
+		}
+		for _, id := range to.TicketIDs[bucket] {
+			if !before[id] {
+				diff.Added = append(diff.Added, id)
+			}
+		}
+		for _, id := range from.TicketIDs[bucket] {
+			if !after[id] {
+				diff.Removed = append(diff.Removed, id)
+			}
+		}
+		buckets[bucket] = diff
+	}
+
+	// The ticket lists are in the bucket diffs; the runs only carry their summary
+	from.TicketIDs = nil
+	to.TicketIDs = nil
+	return &AnalysisDiff{From: from, To: to, Buckets: buckets}, nil
+}
+
+func mergeBucketNames(a, b map[string][]string) map[string]bool {
+	names := make(map[string]bool, len(a)+len(b))
+	for bucket := range a {
+		names[bucket] = true
+	}
+	for bucket := range b {
+		names[bucket] = true
+	}
+	return names
+}
@@ -1,6 +1,7 @@
 package legacy
 
 import (
+	"context"
 	"fmt"
 	"regexp"
 	"strings"
@@ -9,7 +10,7 @@ import (
 
 	configpkg "asana-youtrack-sync/config"
 	"asana-youtrack-sync/database"
-	"asana-youtrack-sync/utils"
+	"asana-youtrack-sync/metrics"
 )
 
 var nonAlphanumRe = regexp.MustCompile(`[^a-z0-9\s]`)
@@ -47,18 +48,26 @@ type AnalysisService struct {
 	asanaService    *AsanaService
 	youtrackService *YouTrackService
 	ignoreService   *IgnoreService
+	suggestions     *MatchSuggestionService
+	history         *AnalysisHistoryService
+	orphans         *OrphanService
 }
 
 // NewAnalysisService creates a new analysis service with all dependencies
 func NewAnalysisService(db *database.DB, configService *configpkg.Service) *AnalysisService {
 	asanaSvc := NewAsanaService(configService)
-	return &AnalysisService{
+	svc := &AnalysisService{
 		db:              db,
 		configService:   configService,
 		asanaService:    asanaSvc,
 		youtrackService: NewYouTrackService(configService, asanaSvc),
 		ignoreService:   NewIgnoreService(db, configService),
+		suggestions:     NewMatchSuggestionService(db, configService),
+		history:         NewAnalysisHistoryService(db, configService),
+		orphans:         NewOrphanService(db, configService),
 	}
+	svc.youtrackService.SetTicketMappings(db)
+	return svc
 }
 
 // normalizeTitle normalizes a title for fuzzy matching (strips all non-alphanumeric chars)
@@ -77,103 +86,28 @@ func titlesMatch(title1, title2 string) bool {
 	if n1 == n2 {
 		return true
 	}
-
-	words1 := strings.Fields(n1)
-	words2 := strings.Fields(n2)
-	if len(words1) == 0 || len(words2) == 0 {
-		return false
-	}
-
-	wordSet := make(map[string]bool, len(words1))
-	for _, w := range words1 {
-		wordSet[w] = true
-	}
-
-	overlap := 0
-	for _, w := range words2 {
-		if wordSet[w] {
-			overlap++
-		}
-	}
-
-	shorter := len(words1)
-	if len(words2) < shorter {
-		shorter = len(words2)
-	}
-	return float64(overlap)/float64(shorter) >= 0.92
-}
-
-// ytTitleEntry holds a YouTrack issue with its pre-computed normalized title and word set,
-// so title matching never re-normalizes the same YT issue more than once.
-type ytTitleEntry struct {
-	issue   YouTrackIssue
-	norm    string
-	words   []string
-	wordSet map[string]bool
-}
-
-// buildYTTitleIndex pre-computes normalized titles and word sets for all YT issues.
-// Returns the index slice and an exact-match map (normalizedTitle -> slice index) for O(1) lookups.
-func buildYTTitleIndex(issues []YouTrackIssue) ([]ytTitleEntry, map[string]int) {
-	idx := make([]ytTitleEntry, 0, len(issues))
-	exactMap := make(map[string]int, len(issues))
-	for _, issue := range issues {
-		n := normalizeTitle(issue.Summary)
-		words := strings.Fields(n)
-		ws := make(map[string]bool, len(words))
-		for _, w := range words {
-			ws[w] = true
-		}
-		exactMap[n] = len(idx)
-		idx = append(idx, ytTitleEntry{issue: issue, norm: n, words: words, wordSet: ws})
-	}
-	return idx, exactMap
+	return wordOverlap(strings.Fields(n1), strings.Fields(n2)) >= defaultTitleThreshold
 }
 
-// findByTitle returns the best matching YT issue for asanaTitle using the pre-built index.
-// Issues already in `used` are skipped. Exact normalized match is O(1); fuzzy is O(m) but
-// avoids re-normalizing YT titles on every call.
-func findByTitle(asanaTitle string, idx []ytTitleEntry, exactMap map[string]int, used map[string]bool) (ytTitleEntry, bool) {
-	n := normalizeTitle(asanaTitle)
-	if i, ok := exactMap[n]; ok && !used[idx[i].issue.ID] {
-		return idx[i], true
-	}
-	asanaWords := strings.Fields(n)
-	if len(asanaWords) == 0 {
-		return ytTitleEntry{}, false
-	}
-	asanaWordSet := make(map[string]bool, len(asanaWords))
-	for _, w := range asanaWords {
-		asanaWordSet[w] = true
-	}
-	for _, e := range idx {
-		if used[e.issue.ID] || len(e.words) == 0 {
-			continue
-		}
-		overlap := 0
-		for _, w := range e.words {
-			if asanaWordSet[w] {
-				overlap++
-			}
-		}
-		shorter := len(asanaWords)
-		if len(e.words) < shorter {
-			shorter = len(e.words)
-		}
-		if float64(overlap)/float64(shorter) >= 0.92 {
-			return e, true
-		}
+// PerformAnalysis performs comprehensive ticket analysis for a user.
+// An optional progress callback func(stage string, processed, total int) can be passed
+// after the columns; existing callers that omit it are unaffected.
+func (s *AnalysisService) PerformAnalysis(ctx context.Context, userID int, selectedColumns []string, progressFn ...func(string, int, int)) (*TicketAnalysis, error) {
+	var emit func(string, int, int)
+	if len(progressFn) > 0 {
+		emit = progressFn[0]
 	}
-	return ytTitleEntry{}, false
+	return s.StreamAnalysis(ctx, userID, selectedColumns, emit, nil)
 }
 
-// PerformAnalysis performs comprehensive ticket analysis for a user.
-// An optional progress callback func(stage string, processed, total int) can be passed
-// as the third argument; existing callers that omit it are unaffected.
-func (s *AnalysisService) PerformAnalysis(userID int, selectedColumns []string, progressFn ...func(string, int, int)) (*TicketAnalysis, error) {
+// StreamAnalysis performs the analysis like PerformAnalysis, handing each
+// ticket to onTicket as soon as its bucket is known. It stops with the
+// context's error when ctx is cancelled. Both callbacks may be nil.
+func (s *AnalysisService) StreamAnalysis(ctx context.Context, userID int, selectedColumns []string, progressFn func(string, int, int), onTicket func(AnalysisTicket)) (*TicketAnalysis, error) {
+	startedAt := time.Now()
 	emit := func(string, int, int) {} // no-op default
-	if len(progressFn) > 0 && progressFn[0] != nil {
-		emit = progressFn[0]
+	if progressFn != nil {
+		emit = progressFn
 	}
 
 	fmt.Printf("ANALYSIS: Starting analysis for user %d with columns: %v\n", userID, selectedColumns)
@@ -199,11 +133,11 @@ func (s *AnalysisService) PerformAnalysis(userID int, selectedColumns []string,
 	wg.Add(2)
 	go func() {
 		defer wg.Done()
-		allAsanaTasks, asanaErr = s.asanaService.GetTasks(userID)
+		allAsanaTasks, asanaErr = s.asanaService.GetTasks(ctx, userID)
 	}()
 	go func() {
 		defer wg.Done()
-		youTrackIssues, ytErr = s.youtrackService.GetIssues(userID)
+		youTrackIssues, ytErr = s.youtrackService.GetIssues(ctx, userID)
 	}()
 	wg.Wait()
 
@@ -213,18 +147,26 @@ func (s *AnalysisService) PerformAnalysis(userID int, selectedColumns []string,
 	if ytErr != nil {
 		return nil, fmt.Errorf("failed to get YouTrack issues: %w", ytErr)
 	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
 	// Step 2: Filter tasks by selected columns
-	asanaTasks := s.asanaService.FilterTasksByColumns(allAsanaTasks, selectedColumns)
+	workflow := DefaultWorkflow()
+	if settingsErr == nil {
+		workflow = NewWorkflow(userSettings.ColumnMappings.AsanaToYouTrack)
+	}
+	asanaTasks := workflow.FilterTasks(allAsanaTasks, selectedColumns)
 	total := len(asanaTasks)
 
 	emit("Matching tickets...", 0, total)
 
-	// Step 4: Build lookup maps - PRIORITIZE MAPPING TABLE, then DESCRIPTION, then TITLE
+	// Step 4: Match tasks to issues - the matching strategies run in order:
+	// mapping table, Asana ID in the description, title, title similarity,
+	// description fingerprint
 	youTrackMap := make(map[string]YouTrackIssue)
 	asanaMap := make(map[string]AsanaTask)
 
-	// Priority 1: Build map from ticket mappings (highest priority)
 	mappings, _ := s.db.GetAllTicketMappings(userID)
 	mappingAsanaToYT := make(map[string]string) // asana_task_id -> youtrack_issue_id
 	mappingYTToAsana := make(map[string]string) // youtrack_issue_id -> asana_task_id
@@ -236,69 +178,44 @@ func (s *AnalysisService) PerformAnalysis(userID int, selectedColumns []string,
 
 	fmt.Printf("ANALYSIS: Loaded %d ticket mappings from database\n", len(mappings))
 
-	// Priority 2 & 3: Build YouTrack map using mappings first, then description, then title matching
-	usedYouTrackIssues := make(map[string]bool) // Track which YouTrack issues are already mapped
-
-	for _, issue := range youTrackIssues {
-		// Check if this YouTrack issue has a database mapping
-		if asanaTaskID, hasMappingYT := mappingYTToAsana[issue.ID]; hasMappingYT {
-			youTrackMap[asanaTaskID] = issue
-			usedYouTrackIssues[issue.ID] = true
-			fmt.Printf("ANALYSIS: Mapped YouTrack issue '%s' to Asana task '%s' via DATABASE mapping\n", issue.ID, asanaTaskID)
-			continue
-		}
-
-		// Fallback to description extraction
-		asanaID := s.youtrackService.ExtractAsanaID(issue)
-		if asanaID != "" {
-			youTrackMap[asanaID] = issue
-			usedYouTrackIssues[issue.ID] = true
-			fmt.Printf("ANALYSIS: Mapped YouTrack issue '%s' to Asana ID '%s' via DESCRIPTION\n", issue.ID, asanaID)
-			// Self-heal: persist mapping so future analyses use DB (O(1) lookup)
-			if settingsErr == nil {
-				s.db.CreateTicketMapping(userID, userSettings.AsanaProjectID, asanaID, userSettings.YouTrackProjectID, issue.ID)
-				fmt.Printf("ANALYSIS: Self-healed mapping via DESCRIPTION: Asana %s <-> YT %s\n", asanaID, issue.ID)
-			}
-		}
+	var matchingConfig database.MatchingConfig
+	var completionConfig database.CompletionConfig
+	if settingsErr == nil {
+		matchingConfig = userSettings.MatchingConfig
+		completionConfig = userSettings.CompletionConfig
 	}
-
-	// Priority 3: Title-based matching using pre-built index (avoids re-normalizing YT titles per comparison)
-	ytAllIndex, ytExactMap := buildYTTitleIndex(youTrackIssues)
-	unmatchedCount := 0
-	for _, issue := range youTrackIssues {
-		if !usedYouTrackIssues[issue.ID] {
-			unmatchedCount++
-		}
+	matchers := TicketMatchers(matchingConfig)
+	pool := NewMatchPool(youTrackIssues, mappingYTToAsana, s.youtrackService.ExtractAsanaID)
+	if settingsErr == nil {
+		s.suggestions.applyToPool(userID, userSettings.SyncPairID, pool)
 	}
-	fmt.Printf("ANALYSIS: Found %d unmatched YouTrack issues, attempting TITLE matching via pre-built index\n", unmatchedCount)
+	matches := MatchTasks(asanaTasks, matchers, pool)
 
-	titleMatchCount := 0
+	methodCounts := make(map[string]int)
 	for _, task := range asanaTasks {
-		if _, alreadyMapped := youTrackMap[task.GID]; alreadyMapped {
+		m, found := matches[task.GID]
+		if !found {
 			continue
 		}
-		if e, found := findByTitle(task.Name, ytAllIndex, ytExactMap, usedYouTrackIssues); found {
-			youTrackMap[task.GID] = e.issue
-			usedYouTrackIssues[e.issue.ID] = true
-			titleMatchCount++
-			fmt.Printf("ANALYSIS: Mapped YouTrack issue '%s' to Asana task '%s' via TITLE matching ('%s' ≈ '%s')\n",
-				e.issue.ID, task.GID, e.issue.Summary, task.Name)
-			if settingsErr == nil {
-				s.db.CreateTicketMapping(userID, userSettings.AsanaProjectID, task.GID, userSettings.YouTrackProjectID, e.issue.ID)
-				fmt.Printf("ANALYSIS: Self-healed mapping via TITLE: Asana %s <-> YT %s\n", task.GID, e.issue.ID)
-			}
+		youTrackMap[task.GID] = m.Issue
+		methodCounts[m.Method]++
+		if m.Method != MatchMethodMapping {
+			fmt.Printf("ANALYSIS: Mapped YouTrack issue '%s' to Asana task '%s' via %s (confidence %.2f, '%s' ≈ '%s')\n",
+				m.Issue.ID, task.GID, m.Method, m.Confidence, m.Issue.Summary, task.Name)
+		}
+		// Self-heal: persist mapping so future analyses use DB (O(1) lookup)
+		if settingsErr == nil && m.Persistent() {
+			s.db.CreateTicketMapping(userID, userSettings.AsanaProjectID, task.GID, userSettings.YouTrackProjectID, m.Issue.ID)
+			fmt.Printf("ANALYSIS: Self-healed mapping via %s: Asana %s <-> YT %s\n", m.Method, task.GID, m.Issue.ID)
 		}
 	}
 
-	fmt.Printf("ANALYSIS: Title matching found %d additional matches\n", titleMatchCount)
-
 	// Build Asana map
 	for _, task := range asanaTasks {
 		asanaMap[task.GID] = task
 	}
 
-	fmt.Printf("ANALYSIS: Built YouTrack map with %d entries (DB: %d, Description: %d, Title: %d)\n",
-		len(youTrackMap), len(mappings), len(youTrackMap)-len(mappings)-titleMatchCount, titleMatchCount)
+	fmt.Printf("ANALYSIS: Built YouTrack map with %d entries %v\n", len(youTrackMap), methodCounts)
 
 	// Step 5: Initialize analysis result structure
 	analysis := &TicketAnalysis{
@@ -313,11 +230,18 @@ func (s *AnalysisService) PerformAnalysis(userID int, selectedColumns []string,
 		OrphanedYouTrack: []YouTrackIssue{},
 		Ignored:          s.ignoreService.GetIgnoredTickets(userID),
 		AlreadyExists:    []AlreadyExistsTicket{},
+		MatchSuggestions: []MatchSuggestionTicket{},
+		SprintMismatches: []SprintMismatch{},
 	}
+	stream := newAnalysisStream(analysis, matches, onTicket)
 
 	// Step 6: Process filtered Asana tasks
 	processed := 0
 	for _, task := range asanaTasks {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		stream.flush()
 		processed++
 		if processed%50 == 0 || processed == total {
 			emit("Analysing tickets...", processed, total)
@@ -327,35 +251,23 @@ func (s *AnalysisService) PerformAnalysis(userID int, selectedColumns []string,
 			continue
 		}
 
-		sectionName := s.asanaService.GetSectionName(task)
 		asanaTags := s.asanaService.GetTags(task)
 
 		// Handle special columns first
-		if strings.Contains(sectionName, "findings") {
-			s.processFindings(task, youTrackMap, analysis)
+		column := workflow.ColumnOf(task)
+		if column != nil && column.Findings {
+			s.processFindings(task, youTrackMap, workflow, analysis)
 			continue
 		}
 
 		// Handle "Ready for Stage" - sync with DEV status in YouTrack
-		if strings.Contains(sectionName, "ready for stage") {
+		if column != nil && column.ReadyForStage {
 			// PRIORITY FIX: Check database mapping first
 			_, hasDBMapping := mappingAsanaToYT[task.GID]
 			existingIssue, existsInYouTrack := youTrackMap[task.GID]
 
-			// If not found via DB mapping or description, try title matching via pre-built index
-			if !existsInYouTrack && !hasDBMapping {
-				if e, found := findByTitle(task.Name, ytAllIndex, ytExactMap, usedYouTrackIssues); found {
-					youTrackMap[task.GID] = e.issue
-					usedYouTrackIssues[e.issue.ID] = true
-					existingIssue = e.issue
-					existsInYouTrack = true
-					fmt.Printf("ANALYSIS: Mapped YouTrack issue '%s' to Ready for Stage task '%s' via TITLE matching ('%s' ≈ '%s')\n",
-						e.issue.ID, task.GID, e.issue.Summary, task.Name)
-				}
-			}
-
 			if existsInYouTrack {
-				s.processReadyForStageTicket(userID, task, existingIssue, asanaTags, analysis)
+				s.processReadyForStageTicket(ctx, userID, task, existingIssue, asanaTags, analysis)
 			} else if hasDBMapping {
 				// Has DB mapping but YouTrack issue not found in current fetch - treat as matched
 				fmt.Printf("ANALYSIS: Task '%s' (GID: %s) has DB mapping but YouTrack issue not in current results - treating as matched\n", task.Name, task.GID)
@@ -373,47 +285,89 @@ func (s *AnalysisService) PerformAnalysis(userID int, selectedColumns []string,
 		existingIssue, existsInYouTrack := youTrackMap[task.GID]
 
 		if existsInYouTrack {
-			s.processExistingTicket(userID, task, existingIssue, asanaTags, sectionName, analysis)
+			s.processExistingTicket(ctx, userID, task, existingIssue, asanaTags, column, completionConfig, analysis)
 		} else if hasDBMapping {
 			// Has DB mapping but YouTrack issue not found in current fetch - treat as matched
 			fmt.Printf("ANALYSIS: Task '%s' (GID: %s) has DB mapping but YouTrack issue not in current results - treating as matched\n", task.Name, task.GID)
 			// Don't add to MissingYouTrack since mapping exists in database
 		} else {
-			// Task already passed FilterTasksByColumns, so it IS in a selected column.
-			// isSyncableSection would return false for dynamic columns (e.g. "mobile done"),
-			// silently dropping the ticket. Trust the filter instead.
+			// Task already passed the workflow filter, so it IS in a selected column,
+			// including sections selected by name that aren't mapped columns.
 			fmt.Printf("ANALYSIS: Task '%s' (GID: %s) missing in YouTrack\n", task.Name, task.GID)
 			analysis.MissingYouTrack = append(analysis.MissingYouTrack, task)
 		}
 	}
 
-	// Step 6.5: Detect already_exists — missing tasks that have an unmapped YT issue by title
+	stream.flush()
+
+	// Step 6.5: Detect already_exists — missing tasks that have an unmapped YT issue
 	// These are pre-existing YT issues that were never linked via mapping/description
 	stillMissing := []AsanaTask{}
 	for _, task := range analysis.MissingYouTrack {
-		if e, found := findByTitle(task.Name, ytAllIndex, ytExactMap, usedYouTrackIssues); found {
-			analysis.AlreadyExists = append(analysis.AlreadyExists, AlreadyExistsTicket{
-				AsanaTask:     task,
-				YouTrackIssue: e.issue,
-				MatchMethod:   "title",
-			})
-			usedYouTrackIssues[e.issue.ID] = true
-			fmt.Printf("ANALYSIS: already_exists — Asana '%s' <-> YT '%s' (title match, no mapping)\n", task.GID, e.issue.ID)
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if m, found := MatchTask(task, matchers, pool); found {
+			alreadyExists := AlreadyExistsTicket{
+				AsanaTask:       task,
+				YouTrackIssue:   m.Issue,
+				MatchMethod:     legacyMatchMethod(m.Method),
+				MatchStrategy:   m.Method,
+				MatchConfidence: m.Confidence,
+			}
+			analysis.AlreadyExists = append(analysis.AlreadyExists, alreadyExists)
+			stream.ticket(BucketAlreadyExists, alreadyExists)
+			matches[task.GID] = m
+			fmt.Printf("ANALYSIS: already_exists — Asana '%s' <-> YT '%s' (%s match, no mapping)\n", task.GID, m.Issue.ID, m.Method)
 		} else {
 			stillMissing = append(stillMissing, task)
+			stream.ticket(BucketMissingYouTrack, task)
 		}
 	}
 	analysis.MissingYouTrack = stillMissing
 
+	// Step 6.6: Queue the likeliest candidates of the remaining missing tasks for
+	// review, so creating them doesn't duplicate an issue under another name
+	suggestions := make(map[string]TicketMatch)
+	suggestionThreshold := SuggestionThreshold(matchingConfig)
+	for _, task := range analysis.MissingYouTrack {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if m, found := SuggestMatch(task, matchers, pool, suggestionThreshold); found {
+			suggestions[task.GID] = m
+			suggestion := MatchSuggestionTicket{
+				AsanaTask:     task,
+				YouTrackIssue: m.Issue,
+				MatchMethod:   m.Method,
+				Score:         m.Confidence,
+			}
+			analysis.MatchSuggestions = append(analysis.MatchSuggestions, suggestion)
+			stream.ticket(BucketMatchSuggestions, suggestion)
+		}
+	}
+	if settingsErr == nil {
+		matchedTaskIDs := make([]string, 0, len(matches))
+		for taskID := range matches {
+			matchedTaskIDs = append(matchedTaskIDs, taskID)
+		}
+		s.suggestions.record(userID, userSettings.SyncPairID, suggestions, asanaMap, matchedTaskIDs)
+	}
+	fmt.Printf("ANALYSIS: %d match suggestions queued for review\n", len(suggestions))
+
 	// Step 7: Handle orphaned YouTrack issues
-	s.processOrphanedIssues(allAsanaTasks, asanaTasks, youTrackIssues, analysis)
+	vanished := s.processOrphanedIssues(allAsanaTasks, asanaTasks, youTrackIssues, mappingYTToAsana, workflow, analysis)
+	if settingsErr == nil {
+		s.orphans.record(ctx, userID, userSettings.SyncPairID, analysis.OrphanedYouTrack, vanished)
+	}
+	stream.flush()
 
 	fmt.Printf("ANALYSIS: Complete for user %d: %d matched, %d mismatched, %d missing, %d orphaned\n",
 		userID, len(analysis.Matched), len(analysis.Mismatched), len(analysis.MissingYouTrack), len(analysis.OrphanedYouTrack))
 
 	// Step 8: Populate MissingBoard — matched tickets not on the configured agile board
 	if settingsErr == nil && userSettings.SyncBoardMembership && userSettings.YouTrackBoardID != "" {
-		boardIDs, err := s.youtrackService.GetBoardIssueIDs(userID)
+		boardIDs, err := s.youtrackService.GetBoardIssueIDs(ctx, userID)
 		if err != nil {
 			fmt.Printf("ANALYSIS: Could not fetch board issue IDs: %v (skipping board check)\n", err)
 		} else {
@@ -430,17 +384,39 @@ func (s *AnalysisService) PerformAnalysis(userID int, selectedColumns []string,
 		}
 	}
 
+	// Step 9: Populate SprintMismatches — tickets mapped to another sprint than their issue's
+	if settingsErr == nil && len(userSettings.SprintConfig.Sprints) > 0 && userSettings.YouTrackBoardID != "" {
+		s.processSprintMismatches(ctx, userSettings, analysis)
+	}
+	stream.flush()
+
+	metrics.AnalysisDuration.Observe(time.Since(startedAt).Seconds(), metrics.UserLabel(userID))
+	analysis.startedAt = startedAt
+	if settingsErr == nil {
+		analysis.syncPairID = &userSettings.SyncPairID
+	}
+
 	return analysis, nil
 }
 
+// RecordRun adds a finished analysis to the sync pair's history. Only the
+// analyses users start are recorded, not the ones run to answer other
+// requests, so comparing with the previous run compares two real runs.
+func (s *AnalysisService) RecordRun(userID int, analysis *TicketAnalysis) {
+	if analysis.syncPairID == nil {
+		return
+	}
+	s.history.record(userID, *analysis.syncPairID, analysis, analysis.startedAt)
+}
+
 // processFindings handles findings tickets and creates alerts for active YouTrack issues
-func (s *AnalysisService) processFindings(task AsanaTask, youTrackMap map[string]YouTrackIssue, analysis *TicketAnalysis) {
+func (s *AnalysisService) processFindings(task AsanaTask, youTrackMap map[string]YouTrackIssue, workflow *Workflow, analysis *TicketAnalysis) {
 	analysis.FindingsTickets = append(analysis.FindingsTickets, task)
 
 	if existingIssue, exists := youTrackMap[task.GID]; exists {
 		youtrackStatus := s.youtrackService.GetStatus(existingIssue)
 
-		if IsActiveYouTrackStatus(youtrackStatus) {
+		if workflow.IsActiveState(youtrackStatus, s.youtrackService.GetStateID(existingIssue)) {
 			alert := FindingsAlert{
 				AsanaTask:      task,
 				YouTrackIssue:  existingIssue,
@@ -453,7 +429,7 @@ func (s *AnalysisService) processFindings(task AsanaTask, youTrackMap map[string
 }
 
 // processReadyForStageTicket processes tickets in "Ready for Stage"
-func (s *AnalysisService) processReadyForStageTicket(userID int, task AsanaTask, existingIssue YouTrackIssue, asanaTags []string, analysis *TicketAnalysis) {
+func (s *AnalysisService) processReadyForStageTicket(ctx context.Context, userID int, task AsanaTask, existingIssue YouTrackIssue, asanaTags []string, analysis *TicketAnalysis) {
 	if existingIssue.ID == "" {
 		fmt.Printf("ANALYSIS WARNING: Ready for Stage task '%s' (GID: %s) has empty YouTrack issue ID - treating as missing\n", task.Name, task.GID)
 		analysis.MissingYouTrack = append(analysis.MissingYouTrack, task)
@@ -467,18 +443,18 @@ func (s *AnalysisService) processReadyForStageTicket(userID int, task AsanaTask,
 	fmt.Printf("ANALYSIS: Processing Ready for Stage ticket '%s' - Expected YT: %s (mapped from Asana), Actual YT: %s\n",
 		task.Name, expectedYouTrackStatus, actualYouTrackStatus)
 
-	// Assignee comparison — uses name heuristics (exact, first-name) to handle
-	// cases where Asana has "Parv Bajaj" but YouTrack has just "Parv".
-	ytAssignee := s.youtrackService.GetAssignee(existingIssue)
+	// Assignee comparison — linked identities decide; without them, name
+	// heuristics handle Asana having "Parv Bajaj" but YouTrack just "Parv".
+	ytAssignees := s.youtrackService.GetAssignees(existingIssue)
 	asanaAssignee := task.Assignee.Name
 	var assigneeDiff *FieldDiff
 	assigneeMismatch := false
 	if asanaAssignee != "" {
-		if !assigneeNamesMatch(asanaAssignee, ytAssignee) {
+		if !assigneeMatches(loadIdentities(s.db, userID), task, ytAssignees) {
 			assigneeMismatch = true
 			assigneeDiff = &FieldDiff{
 				AsanaValue:    asanaAssignee,
-				YouTrackValue: ytAssignee,
+				YouTrackValue: assigneeNames(ytAssignees),
 				HasDiff:       true,
 			}
 		}
@@ -493,6 +469,7 @@ func (s *AnalysisService) processReadyForStageTicket(userID int, task AsanaTask,
 			AsanaTags:         asanaTags,
 			YouTrackSubsystem: "",
 			TagMismatch:       false,
+			TimeTracking:      s.youtrackService.GetTimeTotals(existingIssue),
 		}
 		analysis.Matched = append(analysis.Matched, matchedTicket)
 	} else {
@@ -512,7 +489,7 @@ func (s *AnalysisService) processReadyForStageTicket(userID int, task AsanaTask,
 }
 
 // computeDiffs computes title and description diffs between Asana and YouTrack
-func computeDiffs(task AsanaTask, issue YouTrackIssue) (titleDiff *FieldDiff, descDiff *FieldDiff) {
+func (s *AnalysisService) computeDiffs(ctx context.Context, userID int, task AsanaTask, issue YouTrackIssue) (titleDiff *FieldDiff, descDiff *FieldDiff) {
 	// Title diff — strip any YouTrack ID prefix (e.g. "ARD-341: ") from the Asana title
 	// before comparing. Reverse sync prepends this prefix when creating Asana tasks from
 	// YouTrack issues, so "ARD-341: Fix login bug" vs "Fix login bug" is not a real diff.
@@ -527,10 +504,7 @@ func computeDiffs(task AsanaTask, issue YouTrackIssue) (titleDiff *FieldDiff, de
 	}
 
 	// Description diff — convert Asana HTML to markdown for fair comparison
-	asanaDesc := task.Notes
-	if task.HTMLNotes != "" {
-		asanaDesc = utils.ConvertAsanaHTMLToYouTrackMarkdown(task.HTMLNotes)
-	}
+	asanaDesc := s.youtrackService.descriptionMarkdown(ctx, userID, task)
 	ytDesc := issue.Description
 	if strings.TrimSpace(asanaDesc) != strings.TrimSpace(ytDesc) && strings.TrimSpace(asanaDesc) != "" {
 		descDiff = &FieldDiff{
@@ -543,7 +517,7 @@ func computeDiffs(task AsanaTask, issue YouTrackIssue) (titleDiff *FieldDiff, de
 }
 
 // processExistingTicket processes tickets that exist in both systems
-func (s *AnalysisService) processExistingTicket(userID int, task AsanaTask, existingIssue YouTrackIssue, asanaTags []string, sectionName string, analysis *TicketAnalysis) {
+func (s *AnalysisService) processExistingTicket(ctx context.Context, userID int, task AsanaTask, existingIssue YouTrackIssue, asanaTags []string, column *WorkflowColumn, completionConfig database.CompletionConfig, analysis *TicketAnalysis) {
 	if existingIssue.ID == "" {
 		fmt.Printf("ANALYSIS WARNING: Task '%s' (GID: %s) has empty YouTrack issue ID - treating as missing\n", task.Name, task.GID)
 		// Task already passed FilterTasksByColumns — always add to missing regardless of section name
@@ -554,20 +528,20 @@ func (s *AnalysisService) processExistingTicket(userID int, task AsanaTask, exis
 	asanaStatus := s.asanaService.MapStateToYouTrackWithSettings(userID, task)
 	youtrackStatus := s.youtrackService.GetStatus(existingIssue)
 
-	titleDiff, descDiff := computeDiffs(task, existingIssue)
+	titleDiff, descDiff := s.computeDiffs(ctx, userID, task, existingIssue)
 
-	// Assignee comparison — uses name heuristics (exact, first-name) to handle
-	// cases where Asana has "Parv Bajaj" but YouTrack has just "Parv".
-	ytAssignee := s.youtrackService.GetAssignee(existingIssue)
+	// Assignee comparison — linked identities decide; without them, name
+	// heuristics handle Asana having "Parv Bajaj" but YouTrack just "Parv".
+	ytAssignees := s.youtrackService.GetAssignees(existingIssue)
 	asanaAssignee := task.Assignee.Name
 	var assigneeDiff *FieldDiff
 	assigneeMismatch := false
 	if asanaAssignee != "" {
-		if !assigneeNamesMatch(asanaAssignee, ytAssignee) {
+		if !assigneeMatches(loadIdentities(s.db, userID), task, ytAssignees) {
 			assigneeMismatch = true
 			assigneeDiff = &FieldDiff{
 				AsanaValue:    asanaAssignee,
-				YouTrackValue: ytAssignee,
+				YouTrackValue: assigneeNames(ytAssignees),
 				HasDiff:       true,
 			}
 		}
@@ -595,15 +569,18 @@ func (s *AnalysisService) processExistingTicket(userID int, task AsanaTask, exis
 		TagMismatch:       false,
 		TitleDiff:         titleDiff,
 		DescriptionDiff:   descDiff,
+		TimeTracking:      s.youtrackService.GetTimeTotals(existingIssue),
 	}
 
-	if strings.Contains(sectionName, "blocked") {
+	if column != nil && column.Blocked {
 		analysis.BlockedTickets = append(analysis.BlockedTickets, matchedTicket)
 		return
 	}
 
-	// Case-insensitive comparison for status matching; also check assignee
-	if strings.EqualFold(asanaStatus, youtrackStatus) && !assigneeMismatch {
+	// Case-insensitive comparison for status matching; a completed task's
+	// issue matches in any resolved state. Also check assignee.
+	statusMatches := strings.EqualFold(asanaStatus, youtrackStatus) || completionMatches(completionConfig, task, youtrackStatus)
+	if statusMatches && !assigneeMismatch {
 		analysis.Matched = append(analysis.Matched, matchedTicket)
 	} else {
 		mismatchedTicket := MismatchedTicket{
@@ -623,10 +600,12 @@ func (s *AnalysisService) processExistingTicket(userID int, task AsanaTask, exis
 	}
 }
 
-// processOrphanedIssues handles YouTrack issues without corresponding Asana tasks
-func (s *AnalysisService) processOrphanedIssues(allAsanaTasks, filteredTasks []AsanaTask, youTrackIssues []YouTrackIssue, analysis *TicketAnalysis) {
+// processOrphanedIssues handles YouTrack issues without corresponding Asana tasks.
+// It returns the orphans whose Asana task no longer exists, mapped to its GID.
+func (s *AnalysisService) processOrphanedIssues(allAsanaTasks, filteredTasks []AsanaTask, youTrackIssues []YouTrackIssue, mappingYTToAsana map[string]string, workflow *Workflow, analysis *TicketAnalysis) map[string]string {
+	vanished := make(map[string]string)
 	for _, issue := range youTrackIssues {
-		asanaID := s.youtrackService.ExtractAsanaID(issue)
+		asanaID := linkedAsanaID(issue, mappingYTToAsana, s.youtrackService.ExtractAsanaID)
 		if asanaID == "" {
 			continue
 		}
@@ -650,100 +629,28 @@ func (s *AnalysisService) processOrphanedIssues(allAsanaTasks, filteredTasks []A
 				}
 			}
 
-			if !filteredTaskExists {
-				if len(originalTask.Memberships) > 0 {
-					originalSectionName := strings.ToLower(originalTask.Memberships[0].Section.Name)
-					if s.isSyncableSection(originalSectionName) {
-						analysis.OrphanedYouTrack = append(analysis.OrphanedYouTrack, issue)
-					}
-				}
+			if !filteredTaskExists && workflow.IsSyncable(originalTask) {
+				analysis.OrphanedYouTrack = append(analysis.OrphanedYouTrack, issue)
 			}
 		} else {
 			analysis.OrphanedYouTrack = append(analysis.OrphanedYouTrack, issue)
+			vanished[issue.ID] = asanaID
 		}
 	}
-}
-
-// isSyncableSection checks if a section name is syncable
-func (s *AnalysisService) isSyncableSection(sectionName string) bool {
-	sectionLower := strings.ToLower(strings.TrimSpace(sectionName))
-
-	for _, col := range SyncableColumns {
-		colLower := strings.ToLower(col)
-
-		switch colLower {
-		case "backlog":
-			if strings.Contains(sectionLower, "backlog") &&
-				!strings.Contains(sectionLower, "dev") &&
-				!strings.Contains(sectionLower, "stage") &&
-				!strings.Contains(sectionLower, "blocked") &&
-				!strings.Contains(sectionLower, "progress") {
-				return true
-			}
-		case "in progress":
-			if strings.Contains(sectionLower, "in progress") ||
-				(strings.Contains(sectionLower, "progress") && !strings.Contains(sectionLower, "backlog")) {
-				return true
-			}
-		case "dev":
-			if strings.Contains(sectionLower, "dev") && !strings.Contains(sectionLower, "ready") {
-				return true
-			}
-		case "stage":
-			if strings.Contains(sectionLower, "stage") && !strings.Contains(sectionLower, "ready") {
-				return true
-			}
-		case "blocked":
-			if strings.Contains(sectionLower, "blocked") {
-				return true
-			}
-		case "ready for stage":
-			if strings.Contains(sectionLower, "ready") && strings.Contains(sectionLower, "stage") {
-				return true
-			}
-		case "prod":
-			if strings.Contains(sectionLower, "prod") {
-				return true
-			}
-		default:
-			if strings.Contains(sectionLower, colLower) {
-				return true
-			}
-		}
-	}
-	return false
+	return vanished
 }
 
 // GetTicketsByType returns tickets of a specific type
-func (s *AnalysisService) GetTicketsByType(userID int, ticketType string, column string) (interface{}, error) {
+func (s *AnalysisService) GetTicketsByType(ctx context.Context, userID int, ticketType string, column string) (interface{}, error) {
 	if ticketType == "ignored" {
 		return s.ignoreService.GetIgnoredTickets(userID), nil
 	}
 
-	// Reuse the same resolution logic as the HTTP handlers: convert underscores to
-	// spaces for dynamic columns (e.g. "to_do" → "to do", "mobile_done" → "mobile done").
-	aliases := map[string]string{
-		"backlog":         "backlog",
-		"in_progress":     "in progress",
-		"dev":             "dev",
-		"stage":           "stage",
-		"prod":            "prod",
-		"blocked":         "blocked",
-		"ready_for_stage": "ready for stage",
-		"findings":        "findings",
-	}
-	var columnsToAnalyze []string
-	if column == "" || column == "all_syncable" {
-		columnsToAnalyze = SyncableColumns
-	} else if mapped, ok := aliases[column]; ok {
-		columnsToAnalyze = []string{mapped}
-	} else {
-		dynamic := strings.ReplaceAll(column, "_", " ")
-		fmt.Printf("ANALYSIS: GetTicketsByType dynamic column '%s' → '%s'\n", column, dynamic)
-		columnsToAnalyze = []string{dynamic}
-	}
+	// Same resolution as the HTTP handlers: underscores stand for spaces
+	// (e.g. "to_do" → "to do", "mobile_done" → "mobile done")
+	columnsToAnalyze, _ := workflowFor(s.configService, userID).ResolveColumns(column)
 
-	analysis, err := s.PerformAnalysis(userID, columnsToAnalyze)
+	analysis, err := s.PerformAnalysis(ctx, userID, columnsToAnalyze)
 	if err != nil {
 		return nil, fmt.Errorf("analysis failed: %w", err)
 	}
@@ -788,12 +695,16 @@ func (s *AnalysisService) GetTicketsByType(userID int, ticketType string, column
 }
 
 // GetAnalysisSummary returns a comprehensive summary
-func (s *AnalysisService) GetAnalysisSummary(userID int, selectedColumns []string) (map[string]interface{}, error) {
-	analysis, err := s.PerformAnalysis(userID, selectedColumns)
+func (s *AnalysisService) GetAnalysisSummary(ctx context.Context, userID int, selectedColumns []string) (map[string]interface{}, error) {
+	analysis, err := s.PerformAnalysis(ctx, userID, selectedColumns)
 	if err != nil {
 		return nil, err
 	}
+	return analysisSummary(analysis), nil
+}
 
+// analysisSummary counts the tickets of each bucket of a finished analysis
+func analysisSummary(analysis *TicketAnalysis) map[string]interface{} {
 	tagMismatchCount := 0
 	statusMismatchCount := 0
 
@@ -827,12 +738,12 @@ func (s *AnalysisService) GetAnalysisSummary(userID int, selectedColumns []strin
 		"status_mismatches":   statusMismatchCount,
 		"total_tickets":       totalTickets,
 		"sync_health_percent": syncHealthPercentage,
-	}, nil
+	}
 }
 
 // GetDetailedAnalysis returns detailed analysis with breakdowns
-func (s *AnalysisService) GetDetailedAnalysis(userID int, selectedColumns []string) (map[string]interface{}, error) {
-	analysis, err := s.PerformAnalysis(userID, selectedColumns)
+func (s *AnalysisService) GetDetailedAnalysis(ctx context.Context, userID int, selectedColumns []string) (map[string]interface{}, error) {
+	analysis, err := s.PerformAnalysis(ctx, userID, selectedColumns)
 	if err != nil {
 		return nil, err
 	}
@@ -897,12 +808,12 @@ func (s *AnalysisService) analyzeTagUsage(analysis *TicketAnalysis) map[string]i
 }
 
 // GetColumnAnalysis returns analysis for a specific column
-func (s *AnalysisService) GetColumnAnalysis(userID int, column string) (map[string]interface{}, error) {
+func (s *AnalysisService) GetColumnAnalysis(ctx context.Context, userID int, column string) (map[string]interface{}, error) {
 	if column == "" {
 		return nil, fmt.Errorf("column parameter is required")
 	}
 
-	analysis, err := s.PerformAnalysis(userID, []string{column})
+	analysis, err := s.PerformAnalysis(ctx, userID, []string{column})
 	if err != nil {
 		return nil, err
 	}
@@ -935,16 +846,10 @@ func (s *AnalysisService) ValidateAnalysisRequest(userID int, columns []string)
 		return fmt.Errorf("YouTrack configuration incomplete")
 	}
 
-	if len(columns) > 0 {
-		validColumns := make(map[string]bool)
-		for _, col := range AllColumns {
-			validColumns[col] = true
-		}
-
-		for _, col := range columns {
-			if !validColumns[col] {
-				return fmt.Errorf("invalid column: %s", col)
-			}
+	workflow := NewWorkflow(settings.ColumnMappings.AsanaToYouTrack)
+	for _, col := range columns {
+		if workflow.Column(col) == nil {
+			return fmt.Errorf("invalid column: %s", col)
 		}
 	}
 
@@ -952,16 +857,16 @@ func (s *AnalysisService) ValidateAnalysisRequest(userID int, columns []string)
 }
 
 // GetAnalysisHealth returns the health status of the analysis system
-func (s *AnalysisService) GetAnalysisHealth(userID int) (map[string]interface{}, error) {
+func (s *AnalysisService) GetAnalysisHealth(ctx context.Context, userID int) (map[string]interface{}, error) {
 	asanaHealth := "healthy"
 	youtrackHealth := "healthy"
 
-	_, err := s.asanaService.GetTasks(userID)
+	_, err := s.asanaService.GetTasks(ctx, userID)
 	if err != nil {
 		asanaHealth = "unhealthy: " + err.Error()
 	}
 
-	_, err = s.youtrackService.GetIssues(userID)
+	_, err = s.youtrackService.GetIssues(ctx, userID)
 	if err != nil {
 		youtrackHealth = "unhealthy: " + err.Error()
 	}
@@ -981,11 +886,11 @@ func (s *AnalysisService) GetAnalysisHealth(userID int) (map[string]interface{},
 }
 
 // PerformAnalysisWithFiltering performs analysis with filtering and sorting
-func (s *AnalysisService) PerformAnalysisWithFiltering(userID int, selectedColumns []string, filter TicketFilter, sortOpts TicketSortOptions, progressFn ...func(string, int, int)) (*TicketAnalysis, error) {
+func (s *AnalysisService) PerformAnalysisWithFiltering(ctx context.Context, userID int, selectedColumns []string, filter TicketFilter, sortOpts TicketSortOptions, progressFn ...func(string, int, int)) (*TicketAnalysis, error) {
 	fmt.Printf("ANALYSIS: Starting analysis for user %d with columns: %v, filter: %+v, sort: %+v\n", userID, selectedColumns, filter, sortOpts)
 
 	// Perform base analysis
-	analysis, err := s.PerformAnalysis(userID, selectedColumns, progressFn...)
+	analysis, err := s.PerformAnalysis(ctx, userID, selectedColumns, progressFn...)
 	if err != nil {
 		return nil, err
 	}
@@ -1004,7 +909,7 @@ func (s *AnalysisService) PerformAnalysisWithFiltering(userID int, selectedColum
 }
 
 // Enhanced processExistingTicket (simplified - removed title/description change detection)
-func (s *AnalysisService) processExistingTicketEnhanced(task AsanaTask, existingIssue YouTrackIssue, asanaTags []string, sectionName string, analysis *TicketAnalysis, userID int) {
+func (s *AnalysisService) processExistingTicketEnhanced(ctx context.Context, task AsanaTask, existingIssue YouTrackIssue, asanaTags []string, column *WorkflowColumn, analysis *TicketAnalysis, userID int) {
 	if existingIssue.ID == "" {
 		fmt.Printf("ANALYSIS WARNING: Task '%s' (GID: %s) has empty YouTrack issue ID - treating as missing\n", task.Name, task.GID)
 		analysis.MissingYouTrack = append(analysis.MissingYouTrack, task)
@@ -1029,9 +934,10 @@ func (s *AnalysisService) processExistingTicketEnhanced(task AsanaTask, existing
 		AssigneeName:      assigneeName,
 		Priority:          priority,
 		CreatedAt:         createdAt,
+		TimeTracking:      s.youtrackService.GetTimeTotals(existingIssue),
 	}
 
-	if strings.Contains(sectionName, "blocked") {
+	if column != nil && column.Blocked {
 		analysis.BlockedTickets = append(analysis.BlockedTickets, matchedTicket)
 		return
 	}
@@ -1058,8 +964,8 @@ func (s *AnalysisService) processExistingTicketEnhanced(task AsanaTask, existing
 }
 
 // GetFilterOptions returns available filter options for the analysis
-func (s *AnalysisService) GetFilterOptions(userID int, selectedColumns []string) (map[string]interface{}, error) {
-	analysis, err := s.PerformAnalysis(userID, selectedColumns)
+func (s *AnalysisService) GetFilterOptions(ctx context.Context, userID int, selectedColumns []string) (map[string]interface{}, error) {
+	analysis, err := s.PerformAnalysis(ctx, userID, selectedColumns)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,166 @@
+package legacy
+
+import (
+	"context"
+	"sync"
+)
+
+// Analysis buckets, named after the TicketAnalysis fields they fill
+const (
+	BucketMatched            = "matched"
+	BucketMismatched         = "mismatched"
+	BucketMissingYouTrack    = "missing_youtrack"
+	BucketFindingsTickets    = "findings_tickets"
+	BucketFindingsAlerts     = "findings_alerts"
+	BucketReadyForStage      = "ready_for_stage"
+	BucketBlockedTickets     = "blocked_tickets"
+	BucketOrphanedYouTrack   = "orphaned_youtrack"
+	BucketAlreadyExists      = "already_exists"
+	BucketMissingBoard       = "missing_board"
+	BucketPriorityMismatches = "priority_mismatches"
+	BucketMatchSuggestions   = "match_suggestions"
+	BucketSprintMismatches   = "sprint_mismatches"
+)
+
+// WebSocket message types of an analysis started with /analyze/stream
+const (
+	MsgTypeAnalysisStart     = "analysis_start"
+	MsgTypeAnalysisProgress  = "analysis_progress"
+	MsgTypeAnalysisTickets   = "analysis_tickets"
+	MsgTypeAnalysisComplete  = "analysis_complete"
+	MsgTypeAnalysisError     = "analysis_error"
+	MsgTypeAnalysisCancelled = "analysis_cancelled"
+)
+
+// Notifier pushes messages to a user's WebSocket connections. It is
+// implemented by sync.WebSocketManager.
+type Notifier interface {
+	SendToUser(userID int, msgType string, data interface{})
+}
+
+// AnalysisTicket is a ticket that analysis has just put in a bucket
+type AnalysisTicket struct {
+	Bucket string      `json:"bucket"`
+	Ticket interface{} `json:"ticket"`
+}
+
+// analysisStream hands the tickets added to the analysis buckets to a
+// callback as analysis goes, so clients don't wait for the full result.
+// Missing, already-existing and suggested tickets are only final after the
+// fuzzy pass and are sent from there instead.
+type analysisStream struct {
+	analysis *TicketAnalysis
+	matches  map[string]TicketMatch
+	onTicket func(AnalysisTicket)
+	sent     map[string]int
+}
+
+func newAnalysisStream(analysis *TicketAnalysis, matches map[string]TicketMatch, onTicket func(AnalysisTicket)) *analysisStream {
+	if onTicket == nil {
+		onTicket = func(AnalysisTicket) {}
+	}
+	return &analysisStream{
+		analysis: analysis,
+		matches:  matches,
+		onTicket: onTicket,
+		sent:     make(map[string]int),
+	}
+}
+
+// ticket sends a single ticket
+func (st *analysisStream) ticket(bucket string, ticket interface{}) {
+	st.onTicket(AnalysisTicket{Bucket: bucket, Ticket: ticket})
+}
+
+// flush sends the tickets added since the last flush, recording on matched
+// ones how their YouTrack issue was found
+func (st *analysisStream) flush() {
+	a := st.analysis
+	st.send(BucketMatched, len(a.Matched), func(i int) interface{} {
+		st.annotate(&a.Matched[i])
+		return a.Matched[i]
+	})
+	st.send(BucketMismatched, len(a.Mismatched), func(i int) interface{} {
+		if m, ok := st.matches[a.Mismatched[i].AsanaTask.GID]; ok {
+			a.Mismatched[i].MatchMethod, a.Mismatched[i].MatchConfidence = m.Method, m.Confidence
+		}
+		return a.Mismatched[i]
+	})
+	st.send(BucketBlockedTickets, len(a.BlockedTickets), func(i int) interface{} {
+		st.annotate(&a.BlockedTickets[i])
+		return a.BlockedTickets[i]
+	})
+	st.send(BucketFindingsTickets, len(a.FindingsTickets), func(i int) interface{} { return a.FindingsTickets[i] })
+	st.send(BucketFindingsAlerts, len(a.FindingsAlerts), func(i int) interface{} { return a.FindingsAlerts[i] })
+	st.send(BucketReadyForStage, len(a.ReadyForStage), func(i int) interface{} { return a.ReadyForStage[i] })
+	st.send(BucketPriorityMismatches, len(a.PriorityMismatches), func(i int) interface{} { return a.PriorityMismatches[i] })
+	st.send(BucketOrphanedYouTrack, len(a.OrphanedYouTrack), func(i int) interface{} { return a.OrphanedYouTrack[i] })
+	st.send(BucketMissingBoard, len(a.MissingBoard), func(i int) interface{} { return a.MissingBoard[i] })
+	st.send(BucketSprintMismatches, len(a.SprintMismatches), func(i int) interface{} { return a.SprintMismatches[i] })
+}
+
+func (st *analysisStream) send(bucket string, n int, item func(i int) interface{}) {
+	for i := st.sent[bucket]; i < n; i++ {
+		st.ticket(bucket, item(i))
+	}
+	st.sent[bucket] = n
+}
+
+func (st *analysisStream) annotate(t *MatchedTicket) {
+	if m, ok := st.matches[t.AsanaTask.GID]; ok {
+		t.MatchMethod, t.MatchConfidence = m.Method, m.Confidence
+	}
+}
+
+// analysisRuns keeps the cancel functions of the running streamed analyses,
+// shared by all pair handlers
+type analysisRuns struct {
+	mutex   sync.Mutex
+	nextID  int
+	cancels map[int]map[int]context.CancelFunc // user ID -> run ID -> cancel
+}
+
+func newAnalysisRuns() *analysisRuns {
+	return &analysisRuns{cancels: make(map[int]map[int]context.CancelFunc)}
+}
+
+// start registers a run for the user. The returned context is cancelled by
+// cancel, by the parent, or by calling done when the run ends.
+func (r *analysisRuns) start(parent context.Context, userID int) (ctx context.Context, runID int, done func()) {
+	ctx, cancel := context.WithCancel(parent)
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.nextID++
+	runID = r.nextID
+	if r.cancels[userID] == nil {
+		r.cancels[userID] = make(map[int]context.CancelFunc)
+	}
+	r.cancels[userID][runID] = cancel
+
+	return ctx, runID, func() {
+		cancel()
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		delete(r.cancels[userID], runID)
+		if len(r.cancels[userID]) == 0 {
+			delete(r.cancels, userID)
+		}
+	}
+}
+
+// cancel stops one of the user's runs, or all of them for run ID 0, and
+// returns how many were stopped
+func (r *analysisRuns) cancel(userID, runID int) int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	cancelled := 0
+	for id, cancel := range r.cancels[userID] {
+		if runID == 0 || id == runID {
+			cancel()
+			cancelled++
+		}
+	}
+	return cancelled
+}
@@ -3,7 +3,9 @@ package legacy
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
@@ -23,8 +25,14 @@ type TaskCache struct {
 	mutex     sync.RWMutex
 }
 
-// Global cache for Asana tasks per user (userID -> cache)
-var asanaTaskCache = make(map[int]*TaskCache)
+// taskCacheKey identifies the tasks of one user's sync pair (0 for the default pair)
+type taskCacheKey struct {
+	UserID int
+	PairID int
+}
+
+// Global cache for Asana tasks per user and sync pair
+var asanaTaskCache = make(map[taskCacheKey]*TaskCache)
 var cacheMutex sync.RWMutex
 var cacheTTL = 2 * time.Minute // Cache expires after 2 minutes
 
@@ -32,6 +40,10 @@ var cacheTTL = 2 * time.Minute // Cache expires after 2 minutes
 var userEmailCache = make(map[int]map[string]string)
 var emailCacheMutex sync.RWMutex
 
+// workspaceUsersCache caches the users of each user's Asana workspace
+var workspaceUsersCache = make(map[taskCacheKey][]AsanaUser)
+var workspaceUsersMutex sync.RWMutex
+
 // AsanaService handles Asana API operations with user-specific settings
 type AsanaService struct {
 	configService *configpkg.Service
@@ -46,7 +58,7 @@ func NewAsanaService(configService *configpkg.Service) *AsanaService {
 
 // GetUserEmail fetches and caches the email for an Asana user GID.
 // Returns "" on any failure (best-effort).
-func (s *AsanaService) GetUserEmail(userID int, assigneeGID string) string {
+func (s *AsanaService) GetUserEmail(ctx context.Context, userID int, assigneeGID string) string {
 	if assigneeGID == "" {
 		return ""
 	}
@@ -66,13 +78,13 @@ func (s *AsanaService) GetUserEmail(userID int, assigneeGID string) string {
 	}
 
 	url := fmt.Sprintf("https://app.asana.com/api/1.0/users/%s?opt_fields=email,name", assigneeGID)
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return ""
 	}
 	req.Header.Set("Authorization", "Bearer "+settings.AsanaPAT)
 
-	client := &http.Client{Timeout: 10 * time.Second}
+	client := &http.Client{Timeout: 10 * time.Second, Transport: asanaTransport}
 	resp, err := client.Do(req)
 	if err != nil || resp.StatusCode != http.StatusOK {
 		return ""
@@ -100,18 +112,176 @@ func (s *AsanaService) GetUserEmail(userID int, assigneeGID string) string {
 	return email
 }
 
-// InvalidateCache clears the cache for a specific user
+// GetWorkspaceUsers fetches and caches the users of the workspace the
+// configured Asana project belongs to
+func (s *AsanaService) GetWorkspaceUsers(ctx context.Context, userID int) ([]AsanaUser, error) {
+	key := taskCacheKey{UserID: userID, PairID: s.configService.PairID()}
+
+	workspaceUsersMutex.RLock()
+	if cached, ok := workspaceUsersCache[key]; ok {
+		workspaceUsersMutex.RUnlock()
+		return cached, nil
+	}
+	workspaceUsersMutex.RUnlock()
+
+	settings, err := s.configService.GetSettings(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user settings: %w", err)
+	}
+	if settings.AsanaPAT == "" || settings.AsanaProjectID == "" {
+		return nil, fmt.Errorf("asana credentials not configured")
+	}
+
+	var project struct {
+		Data struct {
+			Workspace struct {
+				GID string `json:"gid"`
+			} `json:"workspace"`
+		} `json:"data"`
+	}
+	projectURL := fmt.Sprintf("https://app.asana.com/api/1.0/projects/%s?opt_fields=workspace.gid", settings.AsanaProjectID)
+	if err := s.getJSON(ctx, settings, projectURL, &project); err != nil {
+		return nil, fmt.Errorf("failed to get project workspace: %w", err)
+	}
+
+	var users []AsanaUser
+	offset := ""
+	for {
+		url := fmt.Sprintf("https://app.asana.com/api/1.0/users?workspace=%s&opt_fields=gid,name,email&limit=100", project.Data.Workspace.GID)
+		if offset != "" {
+			url += "&offset=" + offset
+		}
+		var page struct {
+			Data     []AsanaUser `json:"data"`
+			NextPage *struct {
+				Offset string `json:"offset"`
+			} `json:"next_page"`
+		}
+		if err := s.getJSON(ctx, settings, url, &page); err != nil {
+			return nil, fmt.Errorf("failed to get workspace users: %w", err)
+		}
+		users = append(users, page.Data...)
+		if page.NextPage == nil || page.NextPage.Offset == "" {
+			break
+		}
+		offset = page.NextPage.Offset
+	}
+
+	workspaceUsersMutex.Lock()
+	workspaceUsersCache[key] = users
+	workspaceUsersMutex.Unlock()
+
+	return users, nil
+}
+
+// FindUserGID returns the GID of the workspace user with the given email,
+// falling back to an exact name match. Returns "" when nobody matches.
+func (s *AsanaService) FindUserGID(ctx context.Context, userID int, email, name string) string {
+	users, err := s.GetWorkspaceUsers(ctx, userID)
+	if err != nil {
+		return ""
+	}
+	if email != "" {
+		for _, u := range users {
+			if strings.EqualFold(u.Email, email) {
+				return u.GID
+			}
+		}
+	}
+	if name != "" {
+		for _, u := range users {
+			if strings.EqualFold(strings.TrimSpace(u.Name), strings.TrimSpace(name)) {
+				return u.GID
+			}
+		}
+	}
+	return ""
+}
+
+// getJSON performs an authenticated GET against the Asana API
+func (s *AsanaService) getJSON(ctx context.Context, settings *configpkg.UserSettings, url string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+settings.AsanaPAT)
+	req.Header.Set("Accept", "application/json")
+
+	client := &http.Client{Timeout: 30 * time.Second, Transport: asanaTransport}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("%w - %s", errAsanaNotFound, string(body))
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("asana API error: %d - %s", resp.StatusCode, string(body))
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// errAsanaNotFound is returned by getJSON for resources that don't exist or
+// aren't visible to the token
+var errAsanaNotFound = errors.New("asana API error: 404")
+
+// sendJSON performs an authenticated write with the given data against the
+// Asana API
+func (s *AsanaService) sendJSON(ctx context.Context, settings *configpkg.UserSettings, method, url string, data map[string]interface{}) error {
+	jsonPayload, err := json.Marshal(map[string]interface{}{"data": data})
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewBuffer(jsonPayload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+settings.AsanaPAT)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	client := &http.Client{Timeout: 30 * time.Second, Transport: asanaTransport}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("asana update error: %d - %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+// InvalidateCache clears the cache for a specific user across all sync pairs
 func (s *AsanaService) InvalidateCache(userID int) {
 	cacheMutex.Lock()
 	defer cacheMutex.Unlock()
-	delete(asanaTaskCache, userID)
+	for key := range asanaTaskCache {
+		if key.UserID == userID {
+			delete(asanaTaskCache, key)
+		}
+	}
+	workspaceUsersMutex.Lock()
+	for key := range workspaceUsersCache {
+		if key.UserID == userID {
+			delete(workspaceUsersCache, key)
+		}
+	}
+	workspaceUsersMutex.Unlock()
 	fmt.Printf("CACHE: Invalidated cache for user %d\n", userID)
 }
 
 // getCachedTasks returns cached tasks if valid, or nil if cache is expired/missing
 func (s *AsanaService) getCachedTasks(userID int) []AsanaTask {
 	cacheMutex.RLock()
-	cache, exists := asanaTaskCache[userID]
+	cache, exists := asanaTaskCache[taskCacheKey{userID, s.configService.PairID()}]
 	cacheMutex.RUnlock()
 
 	if !exists {
@@ -134,7 +304,7 @@ func (s *AsanaService) setCachedTasks(userID int, tasks []AsanaTask) {
 	cacheMutex.Lock()
 	defer cacheMutex.Unlock()
 
-	asanaTaskCache[userID] = &TaskCache{
+	asanaTaskCache[taskCacheKey{userID, s.configService.PairID()}] = &TaskCache{
 		tasks:     tasks,
 		fetchedAt: time.Now(),
 	}
@@ -143,7 +313,7 @@ func (s *AsanaService) setCachedTasks(userID int, tasks []AsanaTask) {
 
 // GetTasks retrieves tasks from Asana using user settings with enhanced fields
 // Implements pagination to handle large result sets and caching
-func (s *AsanaService) GetTasks(userID int) ([]AsanaTask, error) {
+func (s *AsanaService) GetTasks(ctx context.Context, userID int) ([]AsanaTask, error) {
 	// Check cache first
 	if cachedTasks := s.getCachedTasks(userID); cachedTasks != nil {
 		return cachedTasks, nil
@@ -159,10 +329,10 @@ func (s *AsanaService) GetTasks(userID int) ([]AsanaTask, error) {
 	}
 
 	var allTasks []AsanaTask
-	client := &http.Client{Timeout: 60 * time.Second}
+	client := &http.Client{Timeout: 60 * time.Second, Transport: asanaTransport}
 
 	// Base URL with enhanced fields and pagination limit
-	baseURL := fmt.Sprintf("https://app.asana.com/api/1.0/projects/%s/tasks?opt_fields=gid,name,notes,html_notes,completed_at,created_at,modified_at,assignee.name,assignee.gid,memberships.section.gid,memberships.section.name,tags.gid,tags.name,custom_fields.name,custom_fields.display_value,custom_fields.text_value,custom_fields.number_value,custom_fields.enum_value.name,attachments.gid,attachments.name,attachments.download_url,attachments.view_url,attachments.resource_type,attachments.host,attachments.size&limit=100",
+	baseURL := fmt.Sprintf("https://app.asana.com/api/1.0/projects/%s/tasks?opt_fields=gid,name,notes,html_notes,completed_at,created_at,modified_at,assignee.name,assignee.gid,followers.gid,followers.name,memberships.section.gid,memberships.section.name,tags.gid,tags.name,custom_fields.name,custom_fields.display_value,custom_fields.text_value,custom_fields.number_value,custom_fields.enum_value.name,attachments.gid,attachments.name,attachments.download_url,attachments.view_url,attachments.resource_type,attachments.host,attachments.size&limit=100",
 		settings.AsanaProjectID)
 
 	nextPageURL := baseURL
@@ -181,7 +351,7 @@ func (s *AsanaService) GetTasks(userID int) ([]AsanaTask, error) {
 				fmt.Printf("PAGINATION: Retrying page %d (attempt %d/3) after error: %v\n", pageCount, attempt, lastErr)
 				time.Sleep(time.Duration(attempt) * 2 * time.Second)
 			}
-			req, err := http.NewRequest("GET", nextPageURL, nil)
+			req, err := http.NewRequestWithContext(ctx, "GET", nextPageURL, nil)
 			if err != nil {
 				return nil, fmt.Errorf("failed to create request: %w", err)
 			}
@@ -297,9 +467,8 @@ func (s *AsanaService) GetCreatedAt(task AsanaTask) time.Time {
 	return time.Time{}
 }
 
-// DeleteTask deletes an Asana task
 // UpdateTaskStatus updates only the status/section of an Asana task (for rollback)
-func (s *AsanaService) UpdateTaskStatus(userID int, taskID, sectionName string) error {
+func (s *AsanaService) UpdateTaskStatus(ctx context.Context, userID int, taskID, sectionName string) error {
 	settings, err := s.configService.GetSettings(userID)
 	if err != nil {
 		return fmt.Errorf("failed to get user settings: %w", err)
@@ -310,7 +479,7 @@ func (s *AsanaService) UpdateTaskStatus(userID int, taskID, sectionName string)
 	}
 
 	// Get all sections for the project to find the section GID
-	sections, err := s.GetSections(userID)
+	sections, err := s.GetSections(ctx, userID)
 	if err != nil {
 		return fmt.Errorf("failed to get sections: %w", err)
 	}
@@ -327,46 +496,106 @@ func (s *AsanaService) UpdateTaskStatus(userID int, taskID, sectionName string)
 		return fmt.Errorf("section '%s' not found in project", sectionName)
 	}
 
-	// Move task to the section
-	url := fmt.Sprintf("https://app.asana.com/api/1.0/sections/%s/addTask", targetSectionGID)
+	if err := s.MoveTaskToSection(ctx, userID, taskID, targetSectionGID); err != nil {
+		return err
+	}
 
-	payload := map[string]interface{}{
-		"data": map[string]interface{}{
-			"task": taskID,
-		},
+	fmt.Printf("Successfully updated Asana task %s to section '%s' for user %d\n", taskID, sectionName, userID)
+	return nil
+}
+
+// MoveTaskToSection moves an Asana task to the section with the given GID
+func (s *AsanaService) MoveTaskToSection(ctx context.Context, userID int, taskID, sectionGID string) error {
+	settings, err := s.configService.GetSettings(userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user settings: %w", err)
 	}
 
-	jsonPayload, err := json.Marshal(payload)
+	url := fmt.Sprintf("https://app.asana.com/api/1.0/sections/%s/addTask", sectionGID)
+	return s.sendJSON(ctx, settings, "POST", url, map[string]interface{}{"task": taskID})
+}
+
+// SetTaskCompleted completes or reopens an Asana task
+func (s *AsanaService) SetTaskCompleted(ctx context.Context, userID int, taskID string, completed bool) error {
+	settings, err := s.configService.GetSettings(userID)
 	if err != nil {
-		return fmt.Errorf("failed to marshal payload: %w", err)
+		return fmt.Errorf("failed to get user settings: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonPayload))
+	url := fmt.Sprintf("https://app.asana.com/api/1.0/tasks/%s", taskID)
+	if err := s.sendJSON(ctx, settings, "PUT", url, map[string]interface{}{"completed": completed}); err != nil {
+		return err
+	}
+
+	s.InvalidateCache(userID)
+	fmt.Printf("Set Asana task %s completed=%t for user %d\n", taskID, completed, userID)
+	return nil
+}
+
+// AddFollowers adds users to an Asana task's followers
+func (s *AsanaService) AddFollowers(ctx context.Context, userID int, taskID string, userGIDs []string) error {
+	settings, err := s.configService.GetSettings(userID)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return fmt.Errorf("failed to get user settings: %w", err)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+settings.AsanaPAT)
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/json")
+	url := fmt.Sprintf("https://app.asana.com/api/1.0/tasks/%s/addFollowers", taskID)
+	if err := s.sendJSON(ctx, settings, "POST", url, map[string]interface{}{"followers": userGIDs}); err != nil {
+		return err
+	}
 
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Do(req)
+	s.InvalidateCache(userID)
+	return nil
+}
+
+// SetTaskNumberFields sets number custom fields of an Asana task, by field GID
+func (s *AsanaService) SetTaskNumberFields(ctx context.Context, userID int, taskID string, values map[string]float64) error {
+	settings, err := s.configService.GetSettings(userID)
 	if err != nil {
-		return fmt.Errorf("request failed: %w", err)
+		return fmt.Errorf("failed to get user settings: %w", err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("asana update error: %d - %s", resp.StatusCode, string(body))
+	url := fmt.Sprintf("https://app.asana.com/api/1.0/tasks/%s", taskID)
+	if err := s.sendJSON(ctx, settings, "PUT", url, map[string]interface{}{"custom_fields": values}); err != nil {
+		return err
 	}
 
-	fmt.Printf("Successfully updated Asana task %s to section '%s' for user %d\n", taskID, sectionName, userID)
+	s.InvalidateCache(userID)
 	return nil
 }
 
-func (s *AsanaService) DeleteTask(userID int, taskID string) error {
+// AsanaProjectRef is a project an Asana task belongs to
+type AsanaProjectRef struct {
+	GID      string `json:"gid"`
+	Name     string `json:"name"`
+	Archived bool   `json:"archived"`
+}
+
+// GetTaskProjects returns the projects an Asana task belongs to. found is
+// false when the task no longer exists.
+func (s *AsanaService) GetTaskProjects(ctx context.Context, userID int, taskGID string) (projects []AsanaProjectRef, found bool, err error) {
+	settings, err := s.configService.GetSettings(userID)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get user settings: %w", err)
+	}
+
+	var result struct {
+		Data struct {
+			Projects []AsanaProjectRef `json:"projects"`
+		} `json:"data"`
+	}
+	url := fmt.Sprintf("https://app.asana.com/api/1.0/tasks/%s?opt_fields=projects.gid,projects.name,projects.archived", taskGID)
+	if err := s.getJSON(ctx, settings, url, &result); err != nil {
+		if errors.Is(err, errAsanaNotFound) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	return result.Data.Projects, true, nil
+}
+
+// DeleteTask deletes an Asana task
+func (s *AsanaService) DeleteTask(ctx context.Context, userID int, taskID string) error {
 	settings, err := s.configService.GetSettings(userID)
 	if err != nil {
 		return fmt.Errorf("failed to get user settings: %w", err)
@@ -378,7 +607,7 @@ func (s *AsanaService) DeleteTask(userID int, taskID string) error {
 
 	url := fmt.Sprintf("https://app.asana.com/api/1.0/tasks/%s", taskID)
 
-	req, err := http.NewRequest("DELETE", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "DELETE", url, nil)
 	if err != nil {
 		return fmt.Errorf("failed to create delete request: %w", err)
 	}
@@ -386,7 +615,7 @@ func (s *AsanaService) DeleteTask(userID int, taskID string) error {
 	req.Header.Set("Authorization", "Bearer "+settings.AsanaPAT)
 	req.Header.Set("Accept", "application/json")
 
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := &http.Client{Timeout: 30 * time.Second, Transport: asanaTransport}
 	resp, err := client.Do(req)
 	if err != nil {
 		return fmt.Errorf("delete request failed: %w", err)
@@ -431,7 +660,8 @@ func (s *AsanaService) MapStateToYouTrack(task AsanaTask) string {
 	return sectionName // Return the actual section name, mapping will be done in analysis
 }
 
-// MapStateToYouTrackWithSettings maps Asana section to YouTrack state using user's custom column mappings
+// MapStateToYouTrackWithSettings maps Asana section to YouTrack state using user's custom column mappings.
+// Completed tasks map to a resolved state when the pair maps completion.
 func (s *AsanaService) MapStateToYouTrackWithSettings(userID int, task AsanaTask) string {
 	if len(task.Memberships) == 0 {
 		return "" // No section
@@ -445,15 +675,14 @@ func (s *AsanaService) MapStateToYouTrackWithSettings(userID int, task AsanaTask
 
 	sectionName := task.Memberships[0].Section.Name
 
-	// Use custom column mappings from user settings
-	for _, mapping := range settings.ColumnMappings.AsanaToYouTrack {
-		// Case-insensitive comparison
-		if strings.EqualFold(mapping.AsanaColumn, sectionName) {
-			if mapping.DisplayOnly {
+	// Use custom column mappings from user settings, matched by section GID
+	if len(settings.ColumnMappings.AsanaToYouTrack) > 0 {
+		if column := NewWorkflow(settings.ColumnMappings.AsanaToYouTrack).ColumnOf(task); column != nil {
+			if column.DisplayOnly {
 				// Display-only columns return special marker
 				return "DISPLAY_ONLY"
 			}
-			return mapping.YouTrackStatus
+			return completedState(settings.CompletionConfig, task, column.YouTrackState)
 		}
 	}
 
@@ -462,67 +691,8 @@ func (s *AsanaService) MapStateToYouTrackWithSettings(userID int, task AsanaTask
 	return ""
 }
 
-// FilterTasksByColumns filters Asana tasks by specified columns
-func (s *AsanaService) FilterTasksByColumns(tasks []AsanaTask, selectedColumns []string) []AsanaTask {
-	if len(selectedColumns) == 0 {
-		return tasks
-	}
-	filtered := []AsanaTask{}
-
-	for _, task := range tasks {
-		if len(task.Memberships) > 0 {
-			sectionName := strings.ToLower(strings.TrimSpace(task.Memberships[0].Section.Name))
-
-			matchFound := false
-			for _, selectedCol := range selectedColumns {
-				selectedColLower := strings.ToLower(strings.TrimSpace(selectedCol))
-
-				var matches bool
-				switch selectedColLower {
-				case "backlog":
-					matches = strings.Contains(sectionName, "backlog") &&
-						!strings.Contains(sectionName, "dev") &&
-						!strings.Contains(sectionName, "stage") &&
-						!strings.Contains(sectionName, "blocked") &&
-						!strings.Contains(sectionName, "progress")
-				case "in progress":
-					matches = strings.Contains(sectionName, "in progress") ||
-						(strings.Contains(sectionName, "progress") && !strings.Contains(sectionName, "backlog"))
-				case "dev":
-					matches = strings.Contains(sectionName, "dev") &&
-						!strings.Contains(sectionName, "ready")
-				case "stage":
-					matches = strings.Contains(sectionName, "stage") &&
-						!strings.Contains(sectionName, "ready")
-				case "blocked":
-					matches = strings.Contains(sectionName, "blocked")
-				case "ready for stage":
-					matches = strings.Contains(sectionName, "ready") && strings.Contains(sectionName, "stage")
-				case "findings":
-					matches = strings.Contains(sectionName, "findings")
-				case "prod":
-					matches = strings.Contains(sectionName, "prod")
-				default:
-					matches = strings.Contains(sectionName, selectedColLower)
-				}
-
-				if matches {
-					matchFound = true
-					break
-				}
-			}
-
-			if matchFound {
-				filtered = append(filtered, task)
-			}
-		}
-	}
-
-	return filtered
-}
-
 // GetSections retrieves all sections (columns) from an Asana project
-func (s *AsanaService) GetSections(userID int) ([]database.AsanaSection, error) {
+func (s *AsanaService) GetSections(ctx context.Context, userID int) ([]database.AsanaSection, error) {
 	settings, err := s.configService.GetSettings(userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user settings: %w", err)
@@ -534,7 +704,7 @@ func (s *AsanaService) GetSections(userID int) ([]database.AsanaSection, error)
 
 	url := fmt.Sprintf("https://app.asana.com/api/1.0/projects/%s/sections", settings.AsanaProjectID)
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -542,7 +712,7 @@ func (s *AsanaService) GetSections(userID int) ([]database.AsanaSection, error)
 	req.Header.Set("Authorization", "Bearer "+settings.AsanaPAT)
 	req.Header.Set("Accept", "application/json")
 
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := &http.Client{Timeout: 30 * time.Second, Transport: asanaTransport}
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("API request failed: %w", err)
@@ -569,7 +739,7 @@ func (s *AsanaService) GetSections(userID int) ([]database.AsanaSection, error)
 // DownloadAttachment downloads an attachment from Asana and returns the file data
 // Note: Asana's download_url in the API might not be directly usable
 // We need to get the actual download URL through the attachment GID
-func (s *AsanaService) DownloadAttachment(userID int, attachmentGID string) ([]byte, error) {
+func (s *AsanaService) DownloadAttachment(ctx context.Context, userID int, attachmentGID string) ([]byte, error) {
 	settings, err := s.configService.GetSettings(userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user settings: %w", err)
@@ -582,7 +752,7 @@ func (s *AsanaService) DownloadAttachment(userID int, attachmentGID string) ([]b
 	// First, get the attachment details to get the proper download URL
 	attachmentURL := fmt.Sprintf("https://app.asana.com/api/1.0/attachments/%s", attachmentGID)
 
-	req, err := http.NewRequest("GET", attachmentURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", attachmentURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create attachment info request: %w", err)
 	}
@@ -590,7 +760,7 @@ func (s *AsanaService) DownloadAttachment(userID int, attachmentGID string) ([]b
 	req.Header.Set("Authorization", "Bearer "+settings.AsanaPAT)
 	req.Header.Set("Accept", "application/json")
 
-	client := &http.Client{Timeout: 60 * time.Second}
+	client := &http.Client{Timeout: 60 * time.Second, Transport: asanaTransport}
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get attachment info: %w", err)
@@ -620,7 +790,7 @@ func (s *AsanaService) DownloadAttachment(userID int, attachmentGID string) ([]b
 	fmt.Printf("Got download URL for attachment %s\n", attachmentGID)
 
 	// Now download the actual file (download_url is a signed URL, no auth needed)
-	req, err = http.NewRequest("GET", downloadURL, nil)
+	req, err = http.NewRequestWithContext(ctx, "GET", downloadURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create download request: %w", err)
 	}
@@ -647,7 +817,7 @@ func (s *AsanaService) DownloadAttachment(userID int, attachmentGID string) ([]b
 }
 
 // CreateTask creates a new task in Asana (for reverse sync)
-func (s *AsanaService) CreateTask(userID int, taskData map[string]interface{}) (string, error) {
+func (s *AsanaService) CreateTask(ctx context.Context, userID int, taskData map[string]interface{}) (string, error) {
 	settings, err := s.configService.GetSettings(userID)
 	if err != nil {
 		return "", fmt.Errorf("failed to get user settings: %w", err)
@@ -664,7 +834,7 @@ func (s *AsanaService) CreateTask(userID int, taskData map[string]interface{}) (
 		return "", fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return "", fmt.Errorf("failed to create request: %w", err)
 	}
@@ -673,7 +843,7 @@ func (s *AsanaService) CreateTask(userID int, taskData map[string]interface{}) (
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
 
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := &http.Client{Timeout: 30 * time.Second, Transport: asanaTransport}
 	resp, err := client.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("request failed: %w", err)
@@ -699,14 +869,14 @@ func (s *AsanaService) CreateTask(userID int, taskData map[string]interface{}) (
 }
 
 // AddTagToTask adds a tag to an Asana task
-func (s *AsanaService) AddTagToTask(userID int, taskID, tagName string) error {
+func (s *AsanaService) AddTagToTask(ctx context.Context, userID int, taskID, tagName string) error {
 	settings, err := s.configService.GetSettings(userID)
 	if err != nil {
 		return fmt.Errorf("failed to get user settings: %w", err)
 	}
 
 	// First, get or create the tag
-	tagID, err := s.getOrCreateTag(userID, tagName, settings)
+	tagID, err := s.getOrCreateTag(ctx, userID, tagName, settings)
 	if err != nil {
 		return fmt.Errorf("failed to get/create tag: %w", err)
 	}
@@ -725,7 +895,7 @@ func (s *AsanaService) AddTagToTask(userID int, taskID, tagName string) error {
 		return fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
@@ -734,7 +904,7 @@ func (s *AsanaService) AddTagToTask(userID int, taskID, tagName string) error {
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
 
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := &http.Client{Timeout: 30 * time.Second, Transport: asanaTransport}
 	resp, err := client.Do(req)
 	if err != nil {
 		return fmt.Errorf("request failed: %w", err)
@@ -750,11 +920,11 @@ func (s *AsanaService) AddTagToTask(userID int, taskID, tagName string) error {
 }
 
 // getOrCreateTag gets an existing tag or creates a new one
-func (s *AsanaService) getOrCreateTag(userID int, tagName string, settings *configpkg.UserSettings) (string, error) {
+func (s *AsanaService) getOrCreateTag(ctx context.Context, userID int, tagName string, settings *configpkg.UserSettings) (string, error) {
 	// Get all tags in the workspace
 	url := fmt.Sprintf("https://app.asana.com/api/1.0/workspaces/%s/tags?limit=100", settings.AsanaProjectID)
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return "", fmt.Errorf("failed to create request: %w", err)
 	}
@@ -762,7 +932,7 @@ func (s *AsanaService) getOrCreateTag(userID int, tagName string, settings *conf
 	req.Header.Set("Authorization", "Bearer "+settings.AsanaPAT)
 	req.Header.Set("Accept", "application/json")
 
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := &http.Client{Timeout: 30 * time.Second, Transport: asanaTransport}
 	resp, err := client.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("request failed: %w", err)
@@ -801,7 +971,7 @@ func (s *AsanaService) getOrCreateTag(userID int, tagName string, settings *conf
 		return "", fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	req, err = http.NewRequest("POST", createURL, bytes.NewBuffer(jsonData))
+	req, err = http.NewRequestWithContext(ctx, "POST", createURL, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return "", fmt.Errorf("failed to create request: %w", err)
 	}
@@ -835,7 +1005,7 @@ func (s *AsanaService) getOrCreateTag(userID int, tagName string, settings *conf
 }
 
 // UploadAttachment uploads an attachment to an Asana task
-func (s *AsanaService) UploadAttachment(userID int, taskID, filename string, fileData []byte) error {
+func (s *AsanaService) UploadAttachment(ctx context.Context, userID int, taskID, filename string, fileData []byte) error {
 	settings, err := s.configService.GetSettings(userID)
 	if err != nil {
 		return fmt.Errorf("failed to get user settings: %w", err)
@@ -862,7 +1032,7 @@ func (s *AsanaService) UploadAttachment(userID int, taskID, filename string, fil
 		return fmt.Errorf("failed to close writer: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", url, body)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, body)
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
@@ -871,7 +1041,7 @@ func (s *AsanaService) UploadAttachment(userID int, taskID, filename string, fil
 	req.Header.Set("Content-Type", writer.FormDataContentType())
 	req.Header.Set("Accept", "application/json")
 
-	client := &http.Client{Timeout: 120 * time.Second}
+	client := &http.Client{Timeout: 120 * time.Second, Transport: asanaTransport}
 	resp, err := client.Do(req)
 	if err != nil {
 		return fmt.Errorf("upload request failed: %w", err)
@@ -887,14 +1057,14 @@ func (s *AsanaService) UploadAttachment(userID int, taskID, filename string, fil
 }
 
 // GetAllTasks fetches all tasks from an Asana project with pagination support
-func (s *AsanaService) GetAllTasks(userID int, projectID string) ([]AsanaTask, error) {
+func (s *AsanaService) GetAllTasks(ctx context.Context, userID int, projectID string) ([]AsanaTask, error) {
 	settings, err := s.configService.GetSettings(userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user settings: %w", err)
 	}
 
 	var allTasks []AsanaTask
-	client := &http.Client{Timeout: 60 * time.Second}
+	client := &http.Client{Timeout: 60 * time.Second, Transport: asanaTransport}
 
 	baseURL := fmt.Sprintf("https://app.asana.com/api/1.0/projects/%s/tasks?opt_fields=gid,name,notes&limit=100", projectID)
 	nextPageURL := baseURL
@@ -904,7 +1074,7 @@ func (s *AsanaService) GetAllTasks(userID int, projectID string) ([]AsanaTask, e
 	for nextPageURL != "" && pageCount < maxPages {
 		pageCount++
 
-		req, err := http.NewRequest("GET", nextPageURL, nil)
+		req, err := http.NewRequestWithContext(ctx, "GET", nextPageURL, nil)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create request: %w", err)
 		}
@@ -949,13 +1119,13 @@ func (s *AsanaService) GetAllTasks(userID int, projectID string) ([]AsanaTask, e
 }
 
 // GetProjectSections gets all sections in an Asana project
-func (s *AsanaService) GetProjectSections(userID int, projectID string) ([]database.AsanaSection, error) {
-	return s.GetSections(userID)
+func (s *AsanaService) GetProjectSections(ctx context.Context, userID int, projectID string) ([]database.AsanaSection, error) {
+	return s.GetSections(ctx, userID)
 }
 
 // GetTaskByGID fetches a single Asana task by GID directly from the API.
 // First checks the in-memory task cache to avoid an extra API call.
-func (s *AsanaService) GetTaskByGID(userID int, taskGID string) (*AsanaTask, error) {
+func (s *AsanaService) GetTaskByGID(ctx context.Context, userID int, taskGID string) (*AsanaTask, error) {
 	// Check cache first — avoids API call if tasks were recently fetched
 	if cached := s.getCachedTasks(userID); cached != nil {
 		for _, t := range cached {
@@ -974,18 +1144,18 @@ func (s *AsanaService) GetTaskByGID(userID int, taskGID string) (*AsanaTask, err
 	}
 
 	url := fmt.Sprintf(
-		"https://app.asana.com/api/1.0/tasks/%s?opt_fields=gid,name,notes,html_notes,completed_at,created_at,modified_at,assignee.name,assignee.gid,memberships.section.gid,memberships.section.name,tags.gid,tags.name,custom_fields.name,custom_fields.display_value,custom_fields.text_value,custom_fields.number_value,custom_fields.enum_value.name,attachments.gid,attachments.name,attachments.download_url,attachments.view_url,attachments.resource_type,attachments.host,attachments.size",
+		"https://app.asana.com/api/1.0/tasks/%s?opt_fields=gid,name,notes,html_notes,completed_at,created_at,modified_at,assignee.name,assignee.gid,followers.gid,followers.name,memberships.section.gid,memberships.section.name,tags.gid,tags.name,custom_fields.name,custom_fields.display_value,custom_fields.text_value,custom_fields.number_value,custom_fields.enum_value.name,attachments.gid,attachments.name,attachments.download_url,attachments.view_url,attachments.resource_type,attachments.host,attachments.size",
 		taskGID,
 	)
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 	req.Header.Set("Authorization", "Bearer "+settings.AsanaPAT)
 	req.Header.Set("Accept", "application/json")
 
-	client := &http.Client{Timeout: 15 * time.Second}
+	client := &http.Client{Timeout: 15 * time.Second, Transport: asanaTransport}
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("asana request failed: %w", err)
@@ -1,56 +1,90 @@
 package legacy
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"time"
 
 	configpkg "asana-youtrack-sync/config"
 	"asana-youtrack-sync/database"
+	"asana-youtrack-sync/metrics"
 )
 
 const defaultAutoInterval = 600 // 10 minutes in seconds
 
-// Per-user operation mutex — prevents create and sync from running simultaneously for same user
+// autoKey identifies one user's automation on one sync pair
+type autoKey struct {
+	UserID int
+	PairID int
+}
+
+// Per-pair operation mutex — prevents create and sync from running simultaneously
+// on the same sync pair, whether started by one user or by two members of the
+// same organization
 var (
 	operationMapMu sync.Mutex
-	operationLocks = make(map[int]*sync.Mutex)
+	operationLocks = make(map[string]*sync.Mutex)
 )
 
-func getUserMutex(userID int) *sync.Mutex {
+func getScopeMutex(db *database.DB, userID, pairID int) *sync.Mutex {
+	key := fmt.Sprintf("user:%d:pair:%d", userID, pairID)
+	if membership, err := db.GetUserMembership(userID); err == nil {
+		key = fmt.Sprintf("org:%d:pair:%d", membership.OrganizationID, pairID)
+	}
+
 	operationMapMu.Lock()
 	defer operationMapMu.Unlock()
-	if _, ok := operationLocks[userID]; !ok {
-		operationLocks[userID] = &sync.Mutex{}
+	if _, ok := operationLocks[key]; !ok {
+		operationLocks[key] = &sync.Mutex{}
+	}
+	return operationLocks[key]
+}
+
+// runningOrganizationMember returns another member of the user's organization
+// for whom running[] is set on the same sync pair, or 0
+func runningOrganizationMember(db *database.DB, running map[autoKey]bool, userID, pairID int) int {
+	for _, memberID := range db.GetOrganizationMemberIDs(userID) {
+		if memberID != userID && running[autoKey{memberID, pairID}] {
+			return memberID
+		}
+	}
+	return 0
+}
+
+// resolvePairID turns a requested pair ID (0 for the default pair) into the
+// ID of a pair in the user's scope
+func resolvePairID(db *database.DB, userID, pairID int) (int, error) {
+	pair, err := db.GetSyncPair(userID, pairID)
+	if err != nil {
+		return 0, err
 	}
-	return operationLocks[userID]
+	return pair.ID, nil
 }
 
 // AutoSyncManager manages automatic synchronization
 type AutoSyncManager struct {
 	db            *database.DB
 	configService *configpkg.Service
-	syncService   *SyncService
-	running       map[int]bool      // userID -> running status
-	stopChannels  map[int]chan bool // userID -> stop channel
-	intervals     map[int]int       // userID -> interval in seconds
+	running       map[autoKey]bool               // (user, pair) -> running status
+	cancels       map[autoKey]context.CancelFunc // (user, pair) -> stops the loop and any run in progress
+	intervals     map[autoKey]int                // (user, pair) -> interval in seconds
 	mutex         sync.RWMutex
-	lastSync      map[int]time.Time // userID -> last sync time
-	syncCount     map[int]int       // userID -> total sync count
-	lastSyncCount map[int]int       // userID -> mismatched count from last sync run
+	lastSync      map[autoKey]time.Time // (user, pair) -> last sync time
+	syncCount     map[autoKey]int       // (user, pair) -> total sync count
+	lastSyncCount map[autoKey]int       // (user, pair) -> mismatched count from last sync run
 }
 
 // AutoCreateManager manages automatic ticket creation
 type AutoCreateManager struct {
 	db            *database.DB
 	configService *configpkg.Service
-	syncService   *SyncService
-	running       map[int]bool      // userID -> running status
-	stopChannels  map[int]chan bool // userID -> stop channel
-	intervals     map[int]int       // userID -> interval in seconds
+	running       map[autoKey]bool               // (user, pair) -> running status
+	cancels       map[autoKey]context.CancelFunc // (user, pair) -> stops the loop and any run in progress
+	intervals     map[autoKey]int                // (user, pair) -> interval in seconds
 	mutex         sync.RWMutex
-	lastCreate    map[int]time.Time // userID -> last create time
-	createCount   map[int]int       // userID -> total create count
+	lastCreate    map[autoKey]time.Time // (user, pair) -> last create time
+	createCount   map[autoKey]int       // (user, pair) -> total create count
 }
 
 // Global managers
@@ -66,24 +100,22 @@ func InitializeAutoManagers(db *database.DB, configService *configpkg.Service) {
 		autoSyncManager = &AutoSyncManager{
 			db:            db,
 			configService: configService,
-			syncService:   NewSyncService(db, configService),
-			running:       make(map[int]bool),
-			stopChannels:  make(map[int]chan bool),
-			intervals:     make(map[int]int),
-			lastSync:      make(map[int]time.Time),
-			syncCount:     make(map[int]int),
-			lastSyncCount: make(map[int]int),
+			running:       make(map[autoKey]bool),
+			cancels:       make(map[autoKey]context.CancelFunc),
+			intervals:     make(map[autoKey]int),
+			lastSync:      make(map[autoKey]time.Time),
+			syncCount:     make(map[autoKey]int),
+			lastSyncCount: make(map[autoKey]int),
 		}
 
 		autoCreateManager = &AutoCreateManager{
 			db:            db,
 			configService: configService,
-			syncService:   NewSyncService(db, configService),
-			running:       make(map[int]bool),
-			stopChannels:  make(map[int]chan bool),
-			intervals:     make(map[int]int),
-			lastCreate:    make(map[int]time.Time),
-			createCount:   make(map[int]int),
+			running:       make(map[autoKey]bool),
+			cancels:       make(map[autoKey]context.CancelFunc),
+			intervals:     make(map[autoKey]int),
+			lastCreate:    make(map[autoKey]time.Time),
+			createCount:   make(map[autoKey]int),
 		}
 	})
 }
@@ -91,14 +123,26 @@ func InitializeAutoManagers(db *database.DB, configService *configpkg.Service) {
 // AUTO SYNC METHODS
 // =================
 
-// StartAutoSync starts automatic synchronization for a user
-func (asm *AutoSyncManager) StartAutoSync(userID int, intervalSeconds int) error {
+// StartAutoSync starts automatic synchronization of a sync pair (0 for the
+// user's default pair)
+func (asm *AutoSyncManager) StartAutoSync(userID, pairID int, intervalSeconds int) error {
+	pairID, err := resolvePairID(asm.db, userID, pairID)
+	if err != nil {
+		return err
+	}
+	key := autoKey{userID, pairID}
+
 	asm.mutex.Lock()
 	defer asm.mutex.Unlock()
 
+	// Organization members share mappings, so only one of them may auto-sync a pair
+	if memberID := runningOrganizationMember(asm.db, asm.running, userID, pairID); memberID != 0 {
+		return fmt.Errorf("auto-sync is already running for user %d in your organization", memberID)
+	}
+
 	// Stop existing auto-sync if running
-	if asm.running[userID] {
-		asm.stopAutoSyncUnsafe(userID)
+	if asm.running[key] {
+		asm.stopAutoSyncUnsafe(key)
 	}
 
 	// Set default interval if not provided
@@ -106,104 +150,113 @@ func (asm *AutoSyncManager) StartAutoSync(userID int, intervalSeconds int) error
 		intervalSeconds = defaultAutoInterval
 	}
 
-	// Create stop channel
-	stopChan := make(chan bool)
-	asm.stopChannels[userID] = stopChan
-	asm.intervals[userID] = intervalSeconds
-	asm.running[userID] = true
+	// Cancelling the context stops the loop and aborts a sync in progress
+	ctx, cancel := context.WithCancel(context.Background())
+	asm.cancels[key] = cancel
+	asm.intervals[key] = intervalSeconds
+	asm.running[key] = true
+
+	syncService := NewSyncService(asm.db, asm.configService.ForPair(pairID))
 
-	fmt.Printf("AUTO-SYNC: Starting for user %d with %d second interval\n", userID, intervalSeconds)
+	fmt.Printf("AUTO-SYNC: Starting for user %d pair %d with %d second interval\n", userID, pairID, intervalSeconds)
 
-	// Stagger sync by 10 min if auto-create is already running for this user
-	if autoCreateManager != nil && autoCreateManager.IsRunning(userID) {
-		fmt.Printf("AUTO-SYNC: Auto-create running for user %d — delaying sync start by 10 min\n", userID)
+	// Stagger sync by 10 min if auto-create is already running for this pair
+	if autoCreateManager != nil && autoCreateManager.IsRunning(userID, pairID) {
+		fmt.Printf("AUTO-SYNC: Auto-create running for user %d pair %d — delaying sync start by 10 min\n", userID, pairID)
 		go func() {
 			select {
 			case <-time.After(10 * time.Minute):
-				asm.autoSyncLoop(userID, intervalSeconds, stopChan)
-			case <-stopChan:
+				asm.autoSyncLoop(ctx, key, syncService, intervalSeconds)
+			case <-ctx.Done():
 				// Stopped before stagger delay elapsed — don't start loop
 			}
 		}()
 	} else {
-		go asm.autoSyncLoop(userID, intervalSeconds, stopChan)
+		go asm.autoSyncLoop(ctx, key, syncService, intervalSeconds)
 	}
 
 	return nil
 }
 
-// StopAutoSync stops automatic synchronization for a user
-func (asm *AutoSyncManager) StopAutoSync(userID int) error {
+// StopAutoSync stops automatic synchronization of a sync pair, cancelling a
+// sync that is in progress
+func (asm *AutoSyncManager) StopAutoSync(userID, pairID int) error {
+	pairID, err := resolvePairID(asm.db, userID, pairID)
+	if err != nil {
+		return err
+	}
+
 	asm.mutex.Lock()
 	defer asm.mutex.Unlock()
 
-	return asm.stopAutoSyncUnsafe(userID)
+	return asm.stopAutoSyncUnsafe(autoKey{userID, pairID})
 }
 
 // stopAutoSyncUnsafe stops auto-sync without acquiring lock (internal use)
-func (asm *AutoSyncManager) stopAutoSyncUnsafe(userID int) error {
-	if !asm.running[userID] {
-		return fmt.Errorf("auto-sync not running for user %d", userID)
+func (asm *AutoSyncManager) stopAutoSyncUnsafe(key autoKey) error {
+	if !asm.running[key] {
+		return fmt.Errorf("auto-sync not running for user %d pair %d", key.UserID, key.PairID)
 	}
 
 	// Send stop signal
-	if stopChan, exists := asm.stopChannels[userID]; exists {
-		close(stopChan)
-		delete(asm.stopChannels, userID)
+	if cancel, exists := asm.cancels[key]; exists {
+		cancel()
+		delete(asm.cancels, key)
 	}
 
-	asm.running[userID] = false
-	delete(asm.intervals, userID)
+	asm.running[key] = false
+	delete(asm.intervals, key)
 
-	fmt.Printf("AUTO-SYNC: Stopped for user %d\n", userID)
+	fmt.Printf("AUTO-SYNC: Stopped for user %d pair %d\n", key.UserID, key.PairID)
 	return nil
 }
 
 // autoSyncLoop runs the automatic synchronization loop
-func (asm *AutoSyncManager) autoSyncLoop(userID int, intervalSeconds int, stopChan chan bool) {
+func (asm *AutoSyncManager) autoSyncLoop(ctx context.Context, key autoKey, syncService *SyncService, intervalSeconds int) {
 	ticker := time.NewTicker(time.Duration(intervalSeconds) * time.Second)
 	defer ticker.Stop()
 
-	fmt.Printf("AUTO-SYNC: Loop started for user %d\n", userID)
+	fmt.Printf("AUTO-SYNC: Loop started for user %d pair %d\n", key.UserID, key.PairID)
 
 	for {
 		select {
-		case <-stopChan:
-			fmt.Printf("AUTO-SYNC: Loop stopped for user %d\n", userID)
+		case <-ctx.Done():
+			fmt.Printf("AUTO-SYNC: Loop stopped for user %d pair %d\n", key.UserID, key.PairID)
 			return
 
-		case <-ticker.C:
-			fmt.Printf("AUTO-SYNC: Executing sync for user %d\n", userID)
+		case tick := <-ticker.C:
+			metrics.AutoLoopLag.Observe(time.Since(tick).Seconds(), "sync")
+			fmt.Printf("AUTO-SYNC: Executing sync for user %d pair %d\n", key.UserID, key.PairID)
 
 			// Perform sync operation
-			err := asm.performAutoSync(userID)
+			err := asm.performAutoSync(ctx, key, syncService)
 
 			asm.mutex.Lock()
-			asm.lastSync[userID] = time.Now()
+			asm.lastSync[key] = time.Now()
 			if err == nil {
-				asm.syncCount[userID]++
+				asm.syncCount[key]++
 			}
 			asm.mutex.Unlock()
 
 			if err != nil {
-				fmt.Printf("AUTO-SYNC: Error for user %d: %v\n", userID, err)
+				fmt.Printf("AUTO-SYNC: Error for user %d pair %d: %v\n", key.UserID, key.PairID, err)
 			} else {
-				fmt.Printf("AUTO-SYNC: Success for user %d\n", userID)
+				fmt.Printf("AUTO-SYNC: Success for user %d pair %d\n", key.UserID, key.PairID)
 			}
 		}
 	}
 }
 
 // performAutoSync performs the actual sync operation
-func (asm *AutoSyncManager) performAutoSync(userID int) error {
-	mu := getUserMutex(userID)
+func (asm *AutoSyncManager) performAutoSync(ctx context.Context, key autoKey, syncService *SyncService) error {
+	mu := getScopeMutex(asm.db, key.UserID, key.PairID)
 	if !mu.TryLock() {
-		fmt.Printf("AUTO-SYNC: Skipping user %d — operation already in progress\n", userID)
+		fmt.Printf("AUTO-SYNC: Skipping user %d pair %d — operation already in progress\n", key.UserID, key.PairID)
 		return nil
 	}
 	defer mu.Unlock()
 
-	err := asm.syncService.AutoSync(userID)
+	err := syncService.AutoSync(ctx, key.UserID)
 	if err != nil {
 		return fmt.Errorf("auto-sync failed: %w", err)
 	}
@@ -213,33 +266,44 @@ func (asm *AutoSyncManager) performAutoSync(userID int) error {
 
 // GetAutoSyncStatusDetailed returns detailed auto-sync status.
 // Does NOT run analysis — returns only in-memory state for fast response.
-func (asm *AutoSyncManager) GetAutoSyncStatusDetailed(userID int) map[string]interface{} {
-	baseStatus := asm.GetAutoSyncStatus(userID)
+func (asm *AutoSyncManager) GetAutoSyncStatusDetailed(userID, pairID int) map[string]interface{} {
+	baseStatus := asm.GetAutoSyncStatus(userID, pairID)
+
+	asm.mutex.RLock()
+	pendingCount := asm.lastSyncCount[autoKey{userID, baseStatus.PairID}]
+	asm.mutex.RUnlock()
 
 	return map[string]interface{}{
+		"pair_id":        baseStatus.PairID,
 		"running":        baseStatus.Running,
 		"interval":       baseStatus.Interval,
 		"last_sync":      baseStatus.LastSync,
 		"next_sync":      baseStatus.NextSync,
 		"sync_count":     baseStatus.SyncCount,
 		"last_sync_info": baseStatus.LastSyncInfo,
-		"pending_count":  asm.lastSyncCount[userID],
+		"pending_count":  pendingCount,
 	}
 }
 
-// GetAutoSyncStatus returns the current status of auto-sync for a user
-func (asm *AutoSyncManager) GetAutoSyncStatus(userID int) AutoSyncStatus {
+// GetAutoSyncStatus returns the current status of auto-sync for a sync pair
+func (asm *AutoSyncManager) GetAutoSyncStatus(userID, pairID int) AutoSyncStatus {
+	if resolved, err := resolvePairID(asm.db, userID, pairID); err == nil {
+		pairID = resolved
+	}
+	key := autoKey{userID, pairID}
+
 	asm.mutex.RLock()
 	defer asm.mutex.RUnlock()
 
 	status := AutoSyncStatus{
-		Running:      asm.running[userID],
-		Interval:     asm.intervals[userID],
-		SyncCount:    asm.syncCount[userID],
+		PairID:       pairID,
+		Running:      asm.running[key],
+		Interval:     asm.intervals[key],
+		SyncCount:    asm.syncCount[key],
 		LastSyncInfo: "No sync performed yet",
 	}
 
-	if lastSync, exists := asm.lastSync[userID]; exists {
+	if lastSync, exists := asm.lastSync[key]; exists {
 		status.LastSync = lastSync
 		if status.Running {
 			nextSync := lastSync.Add(time.Duration(status.Interval) * time.Second)
@@ -254,14 +318,26 @@ func (asm *AutoSyncManager) GetAutoSyncStatus(userID int) AutoSyncStatus {
 // AUTO CREATE METHODS
 // ===================
 
-// StartAutoCreate starts automatic ticket creation for a user
-func (acm *AutoCreateManager) StartAutoCreate(userID int, intervalSeconds int) error {
+// StartAutoCreate starts automatic ticket creation for a sync pair (0 for
+// the user's default pair)
+func (acm *AutoCreateManager) StartAutoCreate(userID, pairID int, intervalSeconds int) error {
+	pairID, err := resolvePairID(acm.db, userID, pairID)
+	if err != nil {
+		return err
+	}
+	key := autoKey{userID, pairID}
+
 	acm.mutex.Lock()
 	defer acm.mutex.Unlock()
 
+	// Organization members share mappings, so only one of them may auto-create for a pair
+	if memberID := runningOrganizationMember(acm.db, acm.running, userID, pairID); memberID != 0 {
+		return fmt.Errorf("auto-create is already running for user %d in your organization", memberID)
+	}
+
 	// Stop existing auto-create if running
-	if acm.running[userID] {
-		acm.stopAutoCreateUnsafe(userID)
+	if acm.running[key] {
+		acm.stopAutoCreateUnsafe(key)
 	}
 
 	// Set default interval if not provided
@@ -269,92 +345,101 @@ func (acm *AutoCreateManager) StartAutoCreate(userID int, intervalSeconds int) e
 		intervalSeconds = defaultAutoInterval
 	}
 
-	// Create stop channel
-	stopChan := make(chan bool)
-	acm.stopChannels[userID] = stopChan
-	acm.intervals[userID] = intervalSeconds
-	acm.running[userID] = true
+	// Cancelling the context stops the loop and aborts a create in progress
+	ctx, cancel := context.WithCancel(context.Background())
+	acm.cancels[key] = cancel
+	acm.intervals[key] = intervalSeconds
+	acm.running[key] = true
 
-	fmt.Printf("AUTO-CREATE: Starting for user %d with %d second interval\n", userID, intervalSeconds)
+	syncService := NewSyncService(acm.db, acm.configService.ForPair(pairID))
+
+	fmt.Printf("AUTO-CREATE: Starting for user %d pair %d with %d second interval\n", userID, pairID, intervalSeconds)
 
 	// Start the auto-create goroutine
-	go acm.autoCreateLoop(userID, intervalSeconds, stopChan)
+	go acm.autoCreateLoop(ctx, key, syncService, intervalSeconds)
 
 	return nil
 }
 
-// StopAutoCreate stops automatic ticket creation for a user
-func (acm *AutoCreateManager) StopAutoCreate(userID int) error {
+// StopAutoCreate stops automatic ticket creation for a sync pair, cancelling
+// a run that is in progress
+func (acm *AutoCreateManager) StopAutoCreate(userID, pairID int) error {
+	pairID, err := resolvePairID(acm.db, userID, pairID)
+	if err != nil {
+		return err
+	}
+
 	acm.mutex.Lock()
 	defer acm.mutex.Unlock()
 
-	return acm.stopAutoCreateUnsafe(userID)
+	return acm.stopAutoCreateUnsafe(autoKey{userID, pairID})
 }
 
 // stopAutoCreateUnsafe stops auto-create without acquiring lock (internal use)
-func (acm *AutoCreateManager) stopAutoCreateUnsafe(userID int) error {
-	if !acm.running[userID] {
-		return fmt.Errorf("auto-create not running for user %d", userID)
+func (acm *AutoCreateManager) stopAutoCreateUnsafe(key autoKey) error {
+	if !acm.running[key] {
+		return fmt.Errorf("auto-create not running for user %d pair %d", key.UserID, key.PairID)
 	}
 
 	// Send stop signal
-	if stopChan, exists := acm.stopChannels[userID]; exists {
-		close(stopChan)
-		delete(acm.stopChannels, userID)
+	if cancel, exists := acm.cancels[key]; exists {
+		cancel()
+		delete(acm.cancels, key)
 	}
 
-	acm.running[userID] = false
-	delete(acm.intervals, userID)
+	acm.running[key] = false
+	delete(acm.intervals, key)
 
-	fmt.Printf("AUTO-CREATE: Stopped for user %d\n", userID)
+	fmt.Printf("AUTO-CREATE: Stopped for user %d pair %d\n", key.UserID, key.PairID)
 	return nil
 }
 
 // autoCreateLoop runs the automatic ticket creation loop
-func (acm *AutoCreateManager) autoCreateLoop(userID int, intervalSeconds int, stopChan chan bool) {
+func (acm *AutoCreateManager) autoCreateLoop(ctx context.Context, key autoKey, syncService *SyncService, intervalSeconds int) {
 	ticker := time.NewTicker(time.Duration(intervalSeconds) * time.Second)
 	defer ticker.Stop()
 
-	fmt.Printf("AUTO-CREATE: Loop started for user %d\n", userID)
+	fmt.Printf("AUTO-CREATE: Loop started for user %d pair %d\n", key.UserID, key.PairID)
 
 	for {
 		select {
-		case <-stopChan:
-			fmt.Printf("AUTO-CREATE: Loop stopped for user %d\n", userID)
+		case <-ctx.Done():
+			fmt.Printf("AUTO-CREATE: Loop stopped for user %d pair %d\n", key.UserID, key.PairID)
 			return
 
-		case <-ticker.C:
-			fmt.Printf("AUTO-CREATE: Executing create for user %d\n", userID)
+		case tick := <-ticker.C:
+			metrics.AutoLoopLag.Observe(time.Since(tick).Seconds(), "create")
+			fmt.Printf("AUTO-CREATE: Executing create for user %d pair %d\n", key.UserID, key.PairID)
 
 			// Perform create operation
-			err := acm.performAutoCreate(userID)
+			err := acm.performAutoCreate(ctx, key, syncService)
 
 			acm.mutex.Lock()
-			acm.lastCreate[userID] = time.Now()
+			acm.lastCreate[key] = time.Now()
 			if err == nil {
-				acm.createCount[userID]++
+				acm.createCount[key]++
 			}
 			acm.mutex.Unlock()
 
 			if err != nil {
-				fmt.Printf("AUTO-CREATE: Error for user %d: %v\n", userID, err)
+				fmt.Printf("AUTO-CREATE: Error for user %d pair %d: %v\n", key.UserID, key.PairID, err)
 			} else {
-				fmt.Printf("AUTO-CREATE: Success for user %d\n", userID)
+				fmt.Printf("AUTO-CREATE: Success for user %d pair %d\n", key.UserID, key.PairID)
 			}
 		}
 	}
 }
 
 // performAutoCreate performs the actual ticket creation operation
-func (acm *AutoCreateManager) performAutoCreate(userID int) error {
-	mu := getUserMutex(userID)
+func (acm *AutoCreateManager) performAutoCreate(ctx context.Context, key autoKey, syncService *SyncService) error {
+	mu := getScopeMutex(acm.db, key.UserID, key.PairID)
 	if !mu.TryLock() {
-		fmt.Printf("AUTO-CREATE: Skipping user %d — operation already in progress\n", userID)
+		fmt.Printf("AUTO-CREATE: Skipping user %d pair %d — operation already in progress\n", key.UserID, key.PairID)
 		return nil
 	}
 	defer mu.Unlock()
 
-	result, err := acm.syncService.CreateMissingTickets(userID)
+	result, err := syncService.CreateMissingTickets(ctx, key.UserID)
 	if err != nil {
 		return fmt.Errorf("create operation failed: %w", err)
 	}
@@ -363,26 +448,32 @@ func (acm *AutoCreateManager) performAutoCreate(userID int) error {
 	// The result is already a map[string]interface{}, so we can access it directly
 	if created, exists := result["created"]; exists {
 		if createdCount, ok := created.(int); ok && createdCount > 0 {
-			fmt.Printf("AUTO-CREATE: Created %d tickets for user %d\n", createdCount, userID)
+			fmt.Printf("AUTO-CREATE: Created %d tickets for user %d pair %d\n", createdCount, key.UserID, key.PairID)
 		}
 	}
 
 	return nil
 }
 
-// GetAutoCreateStatus returns the current status of auto-create for a user
-func (acm *AutoCreateManager) GetAutoCreateStatus(userID int) AutoCreateStatus {
+// GetAutoCreateStatus returns the current status of auto-create for a sync pair
+func (acm *AutoCreateManager) GetAutoCreateStatus(userID, pairID int) AutoCreateStatus {
+	if resolved, err := resolvePairID(acm.db, userID, pairID); err == nil {
+		pairID = resolved
+	}
+	key := autoKey{userID, pairID}
+
 	acm.mutex.RLock()
 	defer acm.mutex.RUnlock()
 
 	status := AutoCreateStatus{
-		Running:        acm.running[userID],
-		Interval:       acm.intervals[userID],
-		CreateCount:    acm.createCount[userID],
+		PairID:         pairID,
+		Running:        acm.running[key],
+		Interval:       acm.intervals[key],
+		CreateCount:    acm.createCount[key],
 		LastCreateInfo: "No create performed yet",
 	}
 
-	if lastCreate, exists := acm.lastCreate[userID]; exists {
+	if lastCreate, exists := acm.lastCreate[key]; exists {
 		status.LastCreate = lastCreate
 		if status.Running {
 			nextCreate := lastCreate.Add(time.Duration(status.Interval) * time.Second)
@@ -407,9 +498,10 @@ func GetAutoCreateManager() *AutoCreateManager {
 	return autoCreateManager
 }
 
-// IsRunning returns true if auto-create is currently running for the given user
-func (acm *AutoCreateManager) IsRunning(userID int) bool {
+// IsRunning returns true if auto-create is currently running for the given
+// user and (resolved) sync pair
+func (acm *AutoCreateManager) IsRunning(userID, pairID int) bool {
 	acm.mutex.RLock()
 	defer acm.mutex.RUnlock()
-	return acm.running[userID]
+	return acm.running[autoKey{userID, pairID}]
 }
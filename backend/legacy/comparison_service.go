@@ -2,6 +2,7 @@
 package legacy
 
 import (
+	"context"
 	"strings"
 
 	configpkg "asana-youtrack-sync/config"
@@ -107,7 +108,7 @@ func sanitizeForComparison(text string) string {
 }
 
 // CheckMappingChanges checks all mapped tickets for changes
-func (cs *ComparisonService) CheckMappingChanges(userID int) ([]MappingChangeInfo, error) {
+func (cs *ComparisonService) CheckMappingChanges(ctx context.Context, userID int) ([]MappingChangeInfo, error) {
 	// Get all mappings
 	mappings, err := cs.db.GetAllTicketMappings(userID)
 	if err != nil {
@@ -115,13 +116,13 @@ func (cs *ComparisonService) CheckMappingChanges(userID int) ([]MappingChangeInf
 	}
 
 	// Get all Asana tasks
-	asanaTasks, err := cs.asanaService.GetTasks(userID)
+	asanaTasks, err := cs.asanaService.GetTasks(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
 
 	// Get all YouTrack issues
-	youtrackIssues, err := cs.youtrackService.GetIssues(userID)
+	youtrackIssues, err := cs.youtrackService.GetIssues(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,93 @@
+package legacy
+
+import (
+	"context"
+	"fmt"
+
+	configpkg "asana-youtrack-sync/config"
+	"asana-youtrack-sync/database"
+)
+
+// isCompleted reports whether the Asana task is marked complete
+func isCompleted(task AsanaTask) bool {
+	return task.CompletedAt != ""
+}
+
+// completedState returns the YouTrack state of a task whose column maps to
+// state: a completed task's issue goes to the first resolved state, unless
+// the column already maps to one
+func completedState(cc database.CompletionConfig, task AsanaTask, state string) string {
+	if !isCompleted(task) || len(cc.ResolvedStates) == 0 || cc.IsResolved(state) {
+		return state
+	}
+	return cc.ResolvedStates[0]
+}
+
+// completionMatches reports whether a completed task's issue is already in
+// one of the resolved states, whichever state the task's column maps to
+func completionMatches(cc database.CompletionConfig, task AsanaTask, youtrackState string) bool {
+	return isCompleted(task) && cc.IsResolved(youtrackState)
+}
+
+// reconcileCompletion syncs completion between a mapped task and its issue and
+// returns the state to update the issue to, along with the completion both
+// sides agree on once it is. The side whose completion differs from the one
+// they last agreed on changed it and wins. If both or neither did, Asana wins
+// as it does for every other field. Without resolved states, or when the
+// issue's state can't be told, the state is left as it is.
+func (s *SyncService) reconcileCompletion(ctx context.Context, userID int, settings *configpkg.UserSettings, mapping *database.TicketMapping, task AsanaTask, issue *YouTrackIssue, state string) (string, *bool, error) {
+	cc := settings.CompletionConfig
+	if len(cc.ResolvedStates) == 0 || mapping == nil || issue == nil {
+		return state, nil, nil
+	}
+	ytState := s.youtrackService.GetStatus(*issue)
+	if ytState == "Unknown" || ytState == "No State" {
+		return state, nil, nil
+	}
+
+	completed := isCompleted(task)
+	resolved := cc.IsResolved(ytState)
+	agreed := completed
+	switch {
+	case resolved != completed && mapping.Completed != nil && *mapping.Completed == completed:
+		// Resolved or reopened in YouTrack since they last agreed: bring the
+		// task along and leave the issue's state alone
+		if err := s.asanaService.SetTaskCompleted(ctx, userID, task.GID, resolved); err != nil {
+			return "", nil, fmt.Errorf("failed to update Asana completion: %w", err)
+		}
+		s.moveToStateSection(ctx, userID, settings, task, ytState, s.youtrackService.GetStateID(*issue))
+		agreed = resolved
+		state = ytState
+		fmt.Printf("SYNC: %s moved to '%s' in YouTrack — set Asana task %s completed=%t\n", issue.ID, ytState, task.GID, resolved)
+	case completed && resolved:
+		// Already resolved, maybe in another resolved state than the default
+		state = ytState
+	}
+	return state, &agreed, nil
+}
+
+// moveToStateSection moves the task to the section of the YouTrack state, so
+// its column doesn't move the issue back on the next sync
+func (s *SyncService) moveToStateSection(ctx context.Context, userID int, settings *configpkg.UserSettings, task AsanaTask, ytState, ytStateID string) {
+	sectionGID, err := s.reverseSync.mapYouTrackStateToAsanaSection(ctx, userID, ytState, ytStateID, settings)
+	if err != nil {
+		fmt.Printf("SYNC: No Asana section for YouTrack state '%s': %v\n", ytState, err)
+		return
+	}
+	if len(task.Memberships) > 0 && task.Memberships[0].Section.GID == sectionGID {
+		return
+	}
+	if err := s.asanaService.MoveTaskToSection(ctx, userID, task.GID, sectionGID); err != nil {
+		fmt.Printf("SYNC: Failed to move Asana task %s to the section of '%s': %v\n", task.GID, ytState, err)
+	}
+}
+
+// findIssue returns the issue with the given ID, or nil
+func findIssue(issues []YouTrackIssue, issueID string) *YouTrackIssue {
+	for i := range issues {
+		if issues[i].ID == issueID {
+			return &issues[i]
+		}
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package legacy
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -24,7 +25,7 @@ func NewDeleteService(configService *configpkg.Service) *DeleteService {
 }
 
 // PerformBulkDelete performs bulk deletion of tickets
-func (s *DeleteService) PerformBulkDelete(userID int, ticketIDs []string, source string) DeleteResponse {
+func (s *DeleteService) PerformBulkDelete(ctx context.Context, userID int, ticketIDs []string, source string) DeleteResponse {
 	response := DeleteResponse{
 		Source:         source,
 		RequestedCount: len(ticketIDs),
@@ -34,16 +35,16 @@ func (s *DeleteService) PerformBulkDelete(userID int, ticketIDs []string, source
 	for _, ticketID := range ticketIDs {
 		result := DeleteResult{
 			TicketID:   ticketID,
-			TicketName: s.getTicketName(userID, ticketID),
+			TicketName: s.getTicketName(ctx, userID, ticketID),
 		}
 
 		switch source {
 		case "asana":
-			s.deleteFromAsana(userID, ticketID, &result, &response)
+			s.deleteFromAsana(ctx, userID, ticketID, &result, &response)
 		case "youtrack":
-			s.deleteFromYouTrack(userID, ticketID, &result, &response)
+			s.deleteFromYouTrack(ctx, userID, ticketID, &result, &response)
 		case "both":
-			s.deleteFromBoth(userID, ticketID, &result, &response)
+			s.deleteFromBoth(ctx, userID, ticketID, &result, &response)
 		default:
 			result.Status = "failed"
 			result.Error = "Invalid source specified"
@@ -59,8 +60,8 @@ func (s *DeleteService) PerformBulkDelete(userID int, ticketIDs []string, source
 }
 
 // deleteFromAsana deletes a ticket from Asana only
-func (s *DeleteService) deleteFromAsana(userID int, ticketID string, result *DeleteResult, response *DeleteResponse) {
-	err := s.asanaService.DeleteTask(userID, ticketID)
+func (s *DeleteService) deleteFromAsana(ctx context.Context, userID int, ticketID string, result *DeleteResult, response *DeleteResponse) {
+	err := s.asanaService.DeleteTask(ctx, userID, ticketID)
 	if err != nil {
 		result.Status = "failed"
 		result.AsanaResult = "failed"
@@ -74,14 +75,14 @@ func (s *DeleteService) deleteFromAsana(userID int, ticketID string, result *Del
 }
 
 // deleteFromYouTrack deletes a ticket from YouTrack only
-func (s *DeleteService) deleteFromYouTrack(userID int, ticketID string, result *DeleteResult, response *DeleteResponse) {
+func (s *DeleteService) deleteFromYouTrack(ctx context.Context, userID int, ticketID string, result *DeleteResult, response *DeleteResponse) {
 	// First try to use as direct YouTrack issue ID
 	youtrackIssueID := ticketID
-	err := s.youtrackService.DeleteIssue(userID, youtrackIssueID)
+	err := s.youtrackService.DeleteIssue(ctx, userID, youtrackIssueID)
 
 	// If that fails, try to find YouTrack issue by Asana ID
 	if err != nil {
-		youtrackIssueID, findErr := s.youtrackService.FindIssueByAsanaID(userID, ticketID)
+		youtrackIssueID, findErr := s.youtrackService.FindIssueByAsanaID(ctx, userID, ticketID)
 		if findErr != nil {
 			result.Status = "failed"
 			result.YouTrackResult = "failed"
@@ -90,7 +91,7 @@ func (s *DeleteService) deleteFromYouTrack(userID int, ticketID string, result *
 			return
 		}
 
-		err = s.youtrackService.DeleteIssue(userID, youtrackIssueID)
+		err = s.youtrackService.DeleteIssue(ctx, userID, youtrackIssueID)
 		if err != nil {
 			result.Status = "failed"
 			result.YouTrackResult = "failed"
@@ -106,13 +107,13 @@ func (s *DeleteService) deleteFromYouTrack(userID int, ticketID string, result *
 }
 
 // deleteFromBoth deletes a ticket from both Asana and YouTrack
-func (s *DeleteService) deleteFromBoth(userID int, ticketID string, result *DeleteResult, response *DeleteResponse) {
+func (s *DeleteService) deleteFromBoth(ctx context.Context, userID int, ticketID string, result *DeleteResult, response *DeleteResponse) {
 	asanaSuccess := true
 	youtrackSuccess := true
 	var errors []string
 
 	// Delete from Asana
-	err := s.asanaService.DeleteTask(userID, ticketID)
+	err := s.asanaService.DeleteTask(ctx, userID, ticketID)
 	if err != nil {
 		asanaSuccess = false
 		result.AsanaResult = "failed"
@@ -122,13 +123,13 @@ func (s *DeleteService) deleteFromBoth(userID int, ticketID string, result *Dele
 	}
 
 	// Delete from YouTrack
-	youtrackIssueID, findErr := s.youtrackService.FindIssueByAsanaID(userID, ticketID)
+	youtrackIssueID, findErr := s.youtrackService.FindIssueByAsanaID(ctx, userID, ticketID)
 	if findErr != nil {
 		youtrackSuccess = false
 		result.YouTrackResult = "not_found"
 		errors = append(errors, fmt.Sprintf("YouTrack: %v", findErr))
 	} else {
-		err = s.youtrackService.DeleteIssue(userID, youtrackIssueID)
+		err = s.youtrackService.DeleteIssue(ctx, userID, youtrackIssueID)
 		if err != nil {
 			youtrackSuccess = false
 			result.YouTrackResult = "failed"
@@ -156,9 +157,9 @@ func (s *DeleteService) deleteFromBoth(userID int, ticketID string, result *Dele
 }
 
 // getTicketName gets the name/title of a ticket for deletion reporting
-func (s *DeleteService) getTicketName(userID int, ticketID string) string {
+func (s *DeleteService) getTicketName(ctx context.Context, userID int, ticketID string) string {
 	// Try to get from Asana tasks
-	tasks, err := s.asanaService.GetTasks(userID)
+	tasks, err := s.asanaService.GetTasks(ctx, userID)
 	if err == nil {
 		for _, task := range tasks {
 			if task.GID == ticketID {
@@ -168,7 +169,7 @@ func (s *DeleteService) getTicketName(userID int, ticketID string) string {
 	}
 
 	// Try to get from YouTrack issues
-	issues, err := s.youtrackService.GetIssues(userID)
+	issues, err := s.youtrackService.GetIssues(ctx, userID)
 	if err == nil {
 		for _, issue := range issues {
 			asanaID := s.youtrackService.ExtractAsanaID(issue)
@@ -217,7 +218,7 @@ func (s *DeleteService) ValidateDeleteRequest(req DeleteTicketsRequest) error {
 }
 
 // GetDeletionPreview provides a preview of what will be deleted
-func (s *DeleteService) GetDeletionPreview(userID int, ticketIDs []string, source string) (map[string]interface{}, error) {
+func (s *DeleteService) GetDeletionPreview(ctx context.Context, userID int, ticketIDs []string, source string) (map[string]interface{}, error) {
 	preview := map[string]interface{}{
 		"source":          source,
 		"requested_count": len(ticketIDs),
@@ -229,7 +230,7 @@ func (s *DeleteService) GetDeletionPreview(userID int, ticketIDs []string, sourc
 	for _, ticketID := range ticketIDs {
 		item := map[string]interface{}{
 			"ticket_id":   ticketID,
-			"ticket_name": s.getTicketName(userID, ticketID),
+			"ticket_name": s.getTicketName(ctx, userID, ticketID),
 		}
 
 		switch source {
@@ -242,8 +243,8 @@ func (s *DeleteService) GetDeletionPreview(userID int, ticketIDs []string, sourc
 		}
 
 		// Check if ticket exists in each system
-		item["exists_in_asana"] = s.ticketExistsInAsana(userID, ticketID)
-		item["exists_in_youtrack"] = s.ticketExistsInYouTrack(userID, ticketID)
+		item["exists_in_asana"] = s.ticketExistsInAsana(ctx, userID, ticketID)
+		item["exists_in_youtrack"] = s.ticketExistsInYouTrack(ctx, userID, ticketID)
 
 		previewItems = append(previewItems, item)
 	}
@@ -253,8 +254,8 @@ func (s *DeleteService) GetDeletionPreview(userID int, ticketIDs []string, sourc
 }
 
 // ticketExistsInAsana checks if a ticket exists in Asana
-func (s *DeleteService) ticketExistsInAsana(userID int, ticketID string) bool {
-	tasks, err := s.asanaService.GetTasks(userID)
+func (s *DeleteService) ticketExistsInAsana(ctx context.Context, userID int, ticketID string) bool {
+	tasks, err := s.asanaService.GetTasks(ctx, userID)
 	if err != nil {
 		return false
 	}
@@ -268,9 +269,9 @@ func (s *DeleteService) ticketExistsInAsana(userID int, ticketID string) bool {
 }
 
 // ticketExistsInYouTrack checks if a ticket exists in YouTrack
-func (s *DeleteService) ticketExistsInYouTrack(userID int, ticketID string) bool {
+func (s *DeleteService) ticketExistsInYouTrack(ctx context.Context, userID int, ticketID string) bool {
 	// Try direct YouTrack ID lookup
-	issues, err := s.youtrackService.GetIssues(userID)
+	issues, err := s.youtrackService.GetIssues(ctx, userID)
 	if err != nil {
 		return false
 	}
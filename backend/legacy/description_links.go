@@ -0,0 +1,65 @@
+package legacy
+
+import (
+	"context"
+	"strings"
+
+	"asana-youtrack-sync/database"
+	"asana-youtrack-sync/utils"
+)
+
+// descriptionLinks builds the lookups that translate @-mentions (matched like
+// assignees) and task links (through ticket mappings, unless db is nil)
+// between Asana and YouTrack descriptions
+func descriptionLinks(ctx context.Context, userID int, db *database.DB, youtrackService *YouTrackService, asanaService *AsanaService) *utils.DescriptionLinks {
+	links := &utils.DescriptionLinks{
+		YouTrackLogin: func(asanaGID, name string) string {
+			ytUser, err := youtrackService.ResolveYouTrackUserByName(ctx, userID, name, asanaGID)
+			if err != nil {
+				return ""
+			}
+			return ytUser.Login
+		},
+	}
+
+	if asanaService != nil {
+		links.AsanaUser = func(login string) string {
+			identities := loadIdentities(db, userID)
+			if identity := identities.asanaFor(YouTrackUser{Login: login}); identity != nil {
+				return identity.AsanaUserGID
+			}
+			if identities.hasDirectory() {
+				return ""
+			}
+			users, err := youtrackService.GetAllUsers(ctx, userID)
+			if err != nil {
+				return ""
+			}
+			for _, u := range users {
+				if strings.EqualFold(u.Login, login) {
+					return asanaService.FindUserGID(ctx, userID, u.Email, u.FullName)
+				}
+			}
+			return ""
+		}
+	}
+
+	if db != nil {
+		links.YouTrackIssue = func(asanaGID string) string {
+			mapping, err := db.GetTicketMappingByAsanaID(userID, asanaGID)
+			if err != nil {
+				return ""
+			}
+			return mapping.YouTrackIssueID
+		}
+		links.AsanaTask = func(issueID string) string {
+			mapping, err := db.GetTicketMappingByYouTrackID(userID, issueID)
+			if err != nil {
+				return ""
+			}
+			return mapping.AsanaTaskID
+		}
+	}
+
+	return links
+}
@@ -0,0 +1,371 @@
+package legacy
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	configpkg "asana-youtrack-sync/config"
+	"asana-youtrack-sync/database"
+)
+
+// duplicateSummaryThreshold is how similar two normalized summaries have to be
+// for the issues to count as created twice
+const duplicateSummaryThreshold = 0.95
+
+// Why issues were grouped as duplicates
+const (
+	DuplicateReasonAsanaID = "asana_id" // same Asana ID in the description
+	DuplicateReasonSummary = "summary"  // near-identical summaries
+)
+
+// Merge modes for the extra issues of a group
+const (
+	MergeModeMark   = "mark"   // link as "duplicates" of the kept issue
+	MergeModeDelete = "delete" // delete them
+)
+
+// DuplicateGroup is a set of YouTrack issues that track the same Asana task.
+// Keep is the suggested survivor: the mapped issue if there is one, otherwise
+// the oldest.
+type DuplicateGroup struct {
+	AsanaTaskID string          `json:"asana_task_id,omitempty"`
+	Reasons     []string        `json:"reasons"`
+	Keep        YouTrackIssue   `json:"keep"`
+	Duplicates  []YouTrackIssue `json:"duplicates"`
+}
+
+// MergeDuplicatesRequest merges duplicate issues into the one to keep
+type MergeDuplicatesRequest struct {
+	KeepIssueID       string   `json:"keep_issue_id"`
+	DuplicateIssueIDs []string `json:"duplicate_issue_ids"`
+	Mode              string   `json:"mode"` // MergeModeMark (default) or MergeModeDelete
+}
+
+// MergeResult reports what a merge moved and which duplicates failed
+type MergeResult struct {
+	KeepIssueID        string            `json:"keep_issue_id"`
+	Mode               string            `json:"mode"`
+	Merged             []string          `json:"merged"`
+	Failed             map[string]string `json:"failed,omitempty"`
+	AttachmentsMoved   int               `json:"attachments_moved"`
+	CommentsMoved      int               `json:"comments_moved"`
+	MappingsReassigned int64             `json:"mappings_reassigned"`
+}
+
+// DuplicateService finds YouTrack issues that were created more than once for
+// the same Asana task, e.g. by a manual create racing auto-create, and merges
+// them
+type DuplicateService struct {
+	db              *database.DB
+	configService   *configpkg.Service
+	youtrackService *YouTrackService
+}
+
+// NewDuplicateService creates a new duplicate service
+func NewDuplicateService(db *database.DB, configService *configpkg.Service) *DuplicateService {
+	return &DuplicateService{
+		db:              db,
+		configService:   configService,
+		youtrackService: NewYouTrackService(configService),
+	}
+}
+
+// ScanDuplicates groups the project's issues that point to the same Asana task
+// or have near-identical summaries. Issues naming different Asana tasks are
+// never grouped, however similar their summaries.
+func (s *DuplicateService) ScanDuplicates(ctx context.Context, userID int) ([]DuplicateGroup, error) {
+	issues, err := s.youtrackService.GetIssues(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get YouTrack issues: %w", err)
+	}
+
+	mapped := make(map[string]bool)
+	mappings, _ := s.db.GetAllTicketMappings(userID)
+	for _, m := range mappings {
+		mapped[m.YouTrackIssueID] = true
+	}
+
+	groups := newIssueGroups(len(issues))
+	titles := make([]string, len(issues))
+	grams := make([]map[string]int, len(issues))
+	byAsanaID := make(map[string]int)
+	for i, issue := range issues {
+		titles[i] = normalizeTitle(stripYouTrackPrefix(issue.Summary))
+		grams[i] = trigrams(titles[i])
+		if asanaID := s.youtrackService.ExtractAsanaID(issue); asanaID != "" {
+			groups.asanaID[i] = asanaID
+			if first, ok := byAsanaID[asanaID]; ok {
+				groups.union(first, i, DuplicateReasonAsanaID)
+			} else {
+				byAsanaID[asanaID] = i
+			}
+		}
+	}
+
+	for i := range issues {
+		if titles[i] == "" {
+			continue
+		}
+		for j := i + 1; j < len(issues); j++ {
+			if titles[j] == "" || lengthRatio(titles[i], titles[j]) < duplicateSummaryThreshold {
+				continue
+			}
+			if titles[i] == titles[j] || summarySimilarity(titles[i], titles[j], grams[i], grams[j]) >= duplicateSummaryThreshold {
+				groups.union(i, j, DuplicateReasonSummary)
+			}
+		}
+	}
+
+	members := make(map[int][]int)
+	for i := range issues {
+		root := groups.find(i)
+		members[root] = append(members[root], i)
+	}
+
+	var result []DuplicateGroup
+	for root, indexes := range members {
+		if len(indexes) < 2 {
+			continue
+		}
+		sort.Slice(indexes, func(a, b int) bool {
+			ia, ib := issues[indexes[a]], issues[indexes[b]]
+			if mapped[ia.ID] != mapped[ib.ID] {
+				return mapped[ia.ID]
+			}
+			return ia.Created < ib.Created
+		})
+		group := DuplicateGroup{
+			AsanaTaskID: groups.asanaID[root],
+			Reasons:     groups.reasonList(root),
+			Keep:        issues[indexes[0]],
+		}
+		for _, i := range indexes[1:] {
+			group.Duplicates = append(group.Duplicates, issues[i])
+		}
+		result = append(result, group)
+	}
+
+	sort.Slice(result, func(a, b int) bool { return result[a].Keep.Created < result[b].Keep.Created })
+	fmt.Printf("DUPLICATES: Found %d duplicate groups among %d YouTrack issues for user %d\n", len(result), len(issues), userID)
+	return result, nil
+}
+
+// summarySimilarity compares two normalized summaries by trigrams and edit
+// distance, whichever is closer
+func summarySimilarity(a, b string, gramsA, gramsB map[string]int) float64 {
+	score := trigramSimilarity(gramsA, gramsB)
+	if score < duplicateSummaryThreshold {
+		if ratio := levenshteinRatio(a, b); ratio > score {
+			score = ratio
+		}
+	}
+	return score
+}
+
+// MergeDuplicates moves the attachments and comments of the duplicates to the
+// kept issue, marks or deletes the duplicates and points their ticket mappings
+// at the kept issue. A failing duplicate doesn't stop the others.
+func (s *DuplicateService) MergeDuplicates(ctx context.Context, userID int, req MergeDuplicatesRequest) (*MergeResult, error) {
+	req.KeepIssueID = strings.TrimSpace(req.KeepIssueID)
+	if req.KeepIssueID == "" || len(req.DuplicateIssueIDs) == 0 {
+		return nil, fmt.Errorf("keep_issue_id and duplicate_issue_ids are required")
+	}
+	if req.Mode == "" {
+		req.Mode = MergeModeMark
+	}
+	if req.Mode != MergeModeMark && req.Mode != MergeModeDelete {
+		return nil, fmt.Errorf("invalid mode: %s (use %q or %q)", req.Mode, MergeModeMark, MergeModeDelete)
+	}
+
+	// Only issues of the pair's project can be merged, so a request can't
+	// move comments out of or delete issues in other projects
+	issues, err := s.youtrackService.GetIssues(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get YouTrack issues: %w", err)
+	}
+	inProject := make(map[string]bool, len(issues))
+	for _, issue := range issues {
+		inProject[issue.ID] = true
+	}
+	if !inProject[req.KeepIssueID] {
+		return nil, fmt.Errorf("issue %s is not in the sync pair's YouTrack project", req.KeepIssueID)
+	}
+	for _, duplicateID := range req.DuplicateIssueIDs {
+		if duplicateID = strings.TrimSpace(duplicateID); duplicateID != "" && !inProject[duplicateID] {
+			return nil, fmt.Errorf("issue %s is not in the sync pair's YouTrack project", duplicateID)
+		}
+	}
+
+	result := &MergeResult{
+		KeepIssueID: req.KeepIssueID,
+		Mode:        req.Mode,
+		Merged:      []string{},
+		Failed:      make(map[string]string),
+	}
+
+	for _, duplicateID := range req.DuplicateIssueIDs {
+		duplicateID = strings.TrimSpace(duplicateID)
+		if duplicateID == "" || duplicateID == req.KeepIssueID {
+			continue
+		}
+		if err := s.mergeInto(ctx, userID, req.KeepIssueID, duplicateID, req.Mode, result); err != nil {
+			fmt.Printf("DUPLICATES: Failed to merge %s into %s: %v\n", duplicateID, req.KeepIssueID, err)
+			result.Failed[duplicateID] = err.Error()
+			continue
+		}
+		result.Merged = append(result.Merged, duplicateID)
+	}
+
+	s.youtrackService.InvalidateIssueCache(userID)
+	fmt.Printf("DUPLICATES: Merged %d issues into %s for user %d (%d attachments, %d comments, %d mappings)\n",
+		len(result.Merged), req.KeepIssueID, userID, result.AttachmentsMoved, result.CommentsMoved, result.MappingsReassigned)
+	return result, nil
+}
+
+func (s *DuplicateService) mergeInto(ctx context.Context, userID int, keepID, duplicateID, mode string, result *MergeResult) error {
+	settings, err := s.configService.GetSettings(userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user settings: %w", err)
+	}
+
+	attachments, err := s.youtrackService.GetIssueAttachments(ctx, userID, duplicateID)
+	if err != nil {
+		return fmt.Errorf("failed to get attachments: %w", err)
+	}
+	existing := s.youtrackService.getExistingAttachmentNames(ctx, settings, keepID)
+	for _, attachment := range attachments {
+		if existing[attachment.Name] {
+			continue
+		}
+		data, err := s.youtrackService.DownloadAttachment(ctx, userID, duplicateID, attachment.URL)
+		if err != nil {
+			return fmt.Errorf("failed to download attachment %s: %w", attachment.Name, err)
+		}
+		if err := s.youtrackService.UploadAttachment(ctx, userID, keepID, attachment.Name, data); err != nil {
+			return fmt.Errorf("failed to move attachment %s: %w", attachment.Name, err)
+		}
+		result.AttachmentsMoved++
+	}
+
+	comments, err := s.youtrackService.GetIssueComments(ctx, userID, duplicateID)
+	if err != nil {
+		return fmt.Errorf("failed to get comments: %w", err)
+	}
+	// A retried merge skips the comments an earlier attempt already moved
+	keepComments, err := s.youtrackService.GetIssueComments(ctx, userID, keepID)
+	if err != nil {
+		return fmt.Errorf("failed to get comments of %s: %w", keepID, err)
+	}
+	moved := make(map[string]bool)
+	for _, comment := range keepComments {
+		if strings.HasPrefix(comment.Text, movedCommentMarker(duplicateID)) {
+			moved[strings.TrimSpace(comment.Text)] = true
+		}
+	}
+	for _, comment := range comments {
+		text := movedCommentText(duplicateID, comment)
+		if moved[strings.TrimSpace(text)] {
+			continue
+		}
+		if err := s.youtrackService.AddComment(ctx, userID, keepID, text); err != nil {
+			return fmt.Errorf("failed to move comment %s: %w", comment.ID, err)
+		}
+		result.CommentsMoved++
+	}
+
+	reassigned, err := s.db.ReassignTicketMappings(userID, duplicateID, keepID)
+	if err != nil {
+		return fmt.Errorf("failed to reassign ticket mappings: %w", err)
+	}
+	result.MappingsReassigned += reassigned
+
+	if mode == MergeModeDelete {
+		return s.youtrackService.DeleteIssue(ctx, userID, duplicateID)
+	}
+	return s.youtrackService.MarkAsDuplicate(ctx, userID, duplicateID, keepID)
+}
+
+// movedCommentMarker starts every comment moved from the duplicate
+func movedCommentMarker(duplicateID string) string {
+	return "_Moved from " + duplicateID + " — "
+}
+
+// movedCommentText is a duplicate's comment as posted on the kept issue
+func movedCommentText(duplicateID string, comment YouTrackComment) string {
+	author := comment.Author.FullName
+	if author == "" {
+		author = comment.Author.Login
+	}
+	created := time.UnixMilli(comment.Created).UTC().Format("2006-01-02 15:04")
+	return fmt.Sprintf("%s%s, %s:_\n\n%s", movedCommentMarker(duplicateID), author, created, comment.Text)
+}
+
+// issueGroups is a union-find over issue indexes that refuses to join groups
+// naming different Asana tasks
+type issueGroups struct {
+	parent  []int
+	asanaID map[int]string // Asana ID of each group root, if known
+	reasons map[int]map[string]bool
+}
+
+func newIssueGroups(n int) *issueGroups {
+	g := &issueGroups{
+		parent:  make([]int, n),
+		asanaID: make(map[int]string),
+		reasons: make(map[int]map[string]bool),
+	}
+	for i := range g.parent {
+		g.parent[i] = i
+	}
+	return g
+}
+
+func (g *issueGroups) find(i int) int {
+	for g.parent[i] != i {
+		g.parent[i] = g.parent[g.parent[i]]
+		i = g.parent[i]
+	}
+	return i
+}
+
+func (g *issueGroups) union(a, b int, reason string) {
+	ra, rb := g.find(a), g.find(b)
+	if ra == rb {
+		g.addReason(ra, reason)
+		return
+	}
+	idA, idB := g.asanaID[ra], g.asanaID[rb]
+	if idA != "" && idB != "" && idA != idB {
+		return
+	}
+
+	g.parent[rb] = ra
+	if idA == "" {
+		g.asanaID[ra] = idB
+	}
+	for r := range g.reasons[rb] {
+		g.addReason(ra, r)
+	}
+	g.addReason(ra, reason)
+	delete(g.asanaID, rb)
+	delete(g.reasons, rb)
+}
+
+func (g *issueGroups) addReason(root int, reason string) {
+	if g.reasons[root] == nil {
+		g.reasons[root] = make(map[string]bool)
+	}
+	g.reasons[root][reason] = true
+}
+
+func (g *issueGroups) reasonList(root int) []string {
+	var reasons []string
+	for r := range g.reasons[root] {
+		reasons = append(reasons, r)
+	}
+	sort.Strings(reasons)
+	return reasons
+}
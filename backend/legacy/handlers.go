@@ -1,9 +1,11 @@
 package legacy
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -12,9 +14,12 @@ import (
 	configpkg "asana-youtrack-sync/config"
 	"asana-youtrack-sync/database"
 	"asana-youtrack-sync/utils"
+
+	"github.com/gorilla/mux"
 )
 
-// Handler manages all legacy API endpoints
+// Handler manages all legacy API endpoints. Its services work on the
+// user's default sync pair; ForPair returns handlers bound to other pairs.
 type Handler struct {
 	db              *database.DB
 	configService   *configpkg.Service
@@ -22,11 +27,26 @@ type Handler struct {
 	syncService     *SyncService
 	deleteService   *DeleteService
 	ignoreService   *IgnoreService
+	suggestions     *MatchSuggestionService
+	orphans         *OrphanService
+	identities      *IdentityService
+	duplicates      *DuplicateService
+	history         *AnalysisHistoryService
 	snapshotService interface {
 		CreatePreSyncSnapshot(userID, operationID int, syncType string) (*database.RollbackSnapshot, error)
 		RecordTicketCreation(operationID int, platform, ticketID string, mappingID int) error
 		RecordTicketUpdate(operationID int, platform, ticketID, oldStatus, newStatus string, originalData map[string]interface{}) error
 	}
+	pairHandlers *pairHandlerCache
+	notifier     Notifier
+	analysisRuns *analysisRuns
+}
+
+// pairHandlerCache keeps one pair-bound Handler per sync pair so that the
+// services' caches survive between requests
+type pairHandlerCache struct {
+	mutex    sync.Mutex
+	handlers map[int]*Handler
 }
 
 // NewHandler creates a new legacy handler with all services
@@ -42,7 +62,71 @@ func NewHandler(db *database.DB, configService *configpkg.Service, snapshotServi
 		syncService:     NewSyncService(db, configService),
 		deleteService:   NewDeleteService(configService),
 		ignoreService:   NewIgnoreService(db, configService),
+		suggestions:     NewMatchSuggestionService(db, configService),
+		orphans:         NewOrphanService(db, configService),
+		identities:      NewIdentityService(db, configService),
+		duplicates:      NewDuplicateService(db, configService),
+		history:         NewAnalysisHistoryService(db, configService),
 		snapshotService: snapshotService,
+		pairHandlers:    &pairHandlerCache{handlers: make(map[int]*Handler)},
+		analysisRuns:    newAnalysisRuns(),
+	}
+}
+
+// SetNotifier sets where analyses started with StartAnalysisStream report to.
+// It must be called before the handler serves requests.
+func (h *Handler) SetNotifier(notifier Notifier) {
+	h.notifier = notifier
+}
+
+// ForPair returns a handler whose services work on the given sync pair
+// (0 for the user's default pair)
+func (h *Handler) ForPair(pairID int) *Handler {
+	if pairID == 0 {
+		return h
+	}
+
+	h.pairHandlers.mutex.Lock()
+	defer h.pairHandlers.mutex.Unlock()
+
+	if handler, ok := h.pairHandlers.handlers[pairID]; ok {
+		return handler
+	}
+	handler := NewHandler(h.db, h.configService.ForPair(pairID), h.snapshotService)
+	handler.pairHandlers = h.pairHandlers
+	handler.notifier = h.notifier
+	handler.analysisRuns = h.analysisRuns
+	h.pairHandlers.handlers[pairID] = handler
+	return handler
+}
+
+// ForgetPair drops the cached handler of a deleted sync pair
+func (h *Handler) ForgetPair(pairID int) {
+	h.pairHandlers.mutex.Lock()
+	defer h.pairHandlers.mutex.Unlock()
+	delete(h.pairHandlers.handlers, pairID)
+}
+
+// PairRoute wraps a handler method so it runs against the sync pair named by
+// the request's pair_id query parameter
+func (h *Handler) PairRoute(method func(*Handler, http.ResponseWriter, *http.Request)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, ok := auth.GetUserFromContext(r)
+		if !ok || r.Method == "OPTIONS" {
+			method(h, w, r)
+			return
+		}
+
+		pairID, err := h.configService.RequestPairID(r, user.UserID)
+		if err == configpkg.ErrSyncPairNotFound {
+			utils.SendNotFound(w, err.Error())
+			return
+		}
+		if err != nil {
+			utils.SendBadRequest(w, err.Error())
+			return
+		}
+		method(h.ForPair(pairID), w, r)
 	}
 }
 
@@ -91,6 +175,8 @@ func (h *Handler) StatusCheck(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	workflow := NewWorkflow(settings.ColumnMappings.AsanaToYouTrack)
+
 	// Check if APIs are properly configured
 	asanaConfigured := settings.AsanaPAT != "" && settings.AsanaProjectID != ""
 	youtrackConfigured := settings.YouTrackBaseURL != "" &&
@@ -106,9 +192,10 @@ func (h *Handler) StatusCheck(w http.ResponseWriter, r *http.Request) {
 		"asana_project":       settings.AsanaProjectID,
 		"youtrack_project":    settings.YouTrackProjectID,
 		"columns": map[string]interface{}{
-			"syncable":     SyncableColumns,
-			"display_only": DisplayOnlyColumns,
+			"syncable":     workflow.SyncableColumnNames(),
+			"display_only": workflow.DisplayOnlyColumnNames(),
 		},
+		"workflow":        workflow,
 		"ignored_tickets": h.ignoreService.CountIgnored(user.UserID),
 		"endpoints": []string{
 			"GET /analyze - Analyze ticket differences",
@@ -124,33 +211,6 @@ func (h *Handler) StatusCheck(w http.ResponseWriter, r *http.Request) {
 	utils.SendSuccess(w, response, "Service status retrieved")
 }
 
-// resolveColumns maps a URL ?column= parameter to the slice of Asana section names to analyze.
-// Handles both hardcoded legacy aliases and dynamic user-defined column names (e.g. "mobile_done").
-func resolveColumns(columnFilter string) (columns []string, mappedName string) {
-	if columnFilter == "" || columnFilter == "all_syncable" {
-		return SyncableColumns, "all_syncable"
-	}
-	// Hardcoded aliases for commonly used column slugs
-	aliases := map[string]string{
-		"backlog":         "backlog",
-		"in_progress":     "in progress",
-		"dev":             "dev",
-		"stage":           "stage",
-		"prod":            "prod",
-		"blocked":         "blocked",
-		"ready_for_stage": "ready for stage",
-		"findings":        "findings",
-	}
-	if mapped, ok := aliases[columnFilter]; ok {
-		return []string{mapped}, mapped
-	}
-	// Dynamic column from DB: convert underscores to spaces.
-	// FilterTasksByColumns handles arbitrary names via strings.Contains.
-	dynamic := strings.ReplaceAll(columnFilter, "_", " ")
-	fmt.Printf("ANALYZE: Dynamic column '%s' → '%s'\n", columnFilter, dynamic)
-	return []string{dynamic}, dynamic
-}
-
 // AnalyzeTickets performs comprehensive ticket analysis
 func (h *Handler) AnalyzeTickets(w http.ResponseWriter, r *http.Request) {
 	user, ok := auth.GetUserFromContext(r)
@@ -162,22 +222,17 @@ func (h *Handler) AnalyzeTickets(w http.ResponseWriter, r *http.Request) {
 	// Get column filter from query parameters
 	columnFilter := r.URL.Query().Get("column")
 	fmt.Printf("ANALYZE: User %d analyzing with column filter: '%s'\n", user.UserID, columnFilter)
-	columnsToAnalyze, mappedColumnName := resolveColumns(columnFilter)
+	columnsToAnalyze, mappedColumnName := workflowFor(h.configService, user.UserID).ResolveColumns(columnFilter)
 
 	// Perform analysis
-	analysis, err := h.analysisService.PerformAnalysis(user.UserID, columnsToAnalyze)
+	analysis, err := h.analysisService.PerformAnalysis(r.Context(), user.UserID, columnsToAnalyze)
 	if err != nil {
 		fmt.Printf("ANALYZE: Analysis failed for user %d: %v\n", user.UserID, err)
 		utils.SendInternalError(w, fmt.Sprintf("Analysis failed: %v", err))
 		return
 	}
 
-	// Get summary statistics
-	summary, err := h.analysisService.GetAnalysisSummary(user.UserID, columnsToAnalyze)
-	if err != nil {
-		utils.SendInternalError(w, fmt.Sprintf("Failed to get summary: %v", err))
-		return
-	}
+	h.analysisService.RecordRun(user.UserID, analysis)
 
 	fmt.Printf("ANALYZE: Complete for user %d - %d matched, %d mismatched, %d missing\n",
 		user.UserID, len(analysis.Matched), len(analysis.Mismatched), len(analysis.MissingYouTrack))
@@ -187,14 +242,15 @@ func (h *Handler) AnalyzeTickets(w http.ResponseWriter, r *http.Request) {
 		"column_filter":    columnFilter,
 		"mapped_column":    mappedColumnName,
 		"analyzed_columns": columnsToAnalyze,
-		"summary":          summary,
+		"summary":          analysisSummary(analysis),
 	}
 
 	utils.SendSuccess(w, response, "Analysis completed successfully")
 }
 
-// AnalyzeWithProgress streams analysis progress via Server-Sent Events, then sends
-// the full analysis result as the final event.
+// AnalyzeWithProgress streams analysis progress and each classified ticket via
+// Server-Sent Events, then sends the full analysis result as the final event.
+// Disconnecting or POST /analyze/cancel stops the analysis.
 func (h *Handler) AnalyzeWithProgress(w http.ResponseWriter, r *http.Request) {
 	if r.Method == "OPTIONS" {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
@@ -250,7 +306,11 @@ func (h *Handler) AnalyzeWithProgress(w http.ResponseWriter, r *http.Request) {
 	}()
 
 	columnFilter := r.URL.Query().Get("column")
-	columnsToAnalyze, mappedColumnName := resolveColumns(columnFilter)
+	columnsToAnalyze, mappedColumnName := workflowFor(h.configService, user.UserID).ResolveColumns(columnFilter)
+
+	ctx, runID, done := h.analysisRuns.start(r.Context(), user.UserID)
+	defer done()
+	sendEvent(map[string]interface{}{"run_id": runID})
 
 	progressFn := func(stage string, processed, total int) {
 		sendEvent(map[string]interface{}{
@@ -259,25 +319,139 @@ func (h *Handler) AnalyzeWithProgress(w http.ResponseWriter, r *http.Request) {
 			"total":     total,
 		})
 	}
+	ticketFn := func(t AnalysisTicket) {
+		sendEvent(t)
+	}
 
-	analysis, err := h.analysisService.PerformAnalysis(user.UserID, columnsToAnalyze, progressFn)
+	analysis, err := h.analysisService.StreamAnalysis(ctx, user.UserID, columnsToAnalyze, progressFn, ticketFn)
+	if ctx.Err() != nil {
+		fmt.Printf("ANALYZE: Run %d for user %d cancelled\n", runID, user.UserID)
+		sendEvent(map[string]interface{}{"cancelled": true, "run_id": runID})
+		return
+	}
 	if err != nil {
 		sendEvent(map[string]interface{}{"error": err.Error()})
 		return
 	}
 
-	summary, _ := h.analysisService.GetAnalysisSummary(user.UserID, columnsToAnalyze)
+	h.analysisService.RecordRun(user.UserID, analysis)
 
 	sendEvent(map[string]interface{}{
 		"done":             true,
 		"analysis":         analysis,
-		"summary":          summary,
+		"summary":          analysisSummary(analysis),
 		"column_filter":    columnFilter,
 		"mapped_column":    mappedColumnName,
 		"analyzed_columns": columnsToAnalyze,
 	})
 }
 
+// analysisTicketBatch is how many tickets StartAnalysisStream sends per
+// WebSocket message, so large projects don't overflow the broadcast queue
+const analysisTicketBatch = 50
+
+// StartAnalysisStream starts an analysis in the background that reports its
+// progress and tickets over the user's WebSocket connections, and returns the
+// run ID to cancel it with
+func (h *Handler) StartAnalysisStream(w http.ResponseWriter, r *http.Request) {
+	user, ok := auth.GetUserFromContext(r)
+	if !ok {
+		utils.SendUnauthorized(w, "Authentication required")
+		return
+	}
+	if h.notifier == nil {
+		utils.SendInternalError(w, "WebSocket notifications are not available")
+		return
+	}
+
+	columnFilter := r.URL.Query().Get("column")
+	columnsToAnalyze, mappedColumnName := workflowFor(h.configService, user.UserID).ResolveColumns(columnFilter)
+
+	ctx, runID, done := h.analysisRuns.start(context.Background(), user.UserID)
+	userID := user.UserID
+	notify := func(msgType string, data map[string]interface{}) {
+		data["run_id"] = runID
+		h.notifier.SendToUser(userID, msgType, data)
+	}
+
+	go func() {
+		defer done()
+
+		var batch []AnalysisTicket
+		sendBatch := func() {
+			if len(batch) > 0 {
+				notify(MsgTypeAnalysisTickets, map[string]interface{}{"tickets": batch})
+				batch = nil
+			}
+		}
+		progressFn := func(stage string, processed, total int) {
+			sendBatch()
+			notify(MsgTypeAnalysisProgress, map[string]interface{}{
+				"stage":     stage,
+				"processed": processed,
+				"total":     total,
+			})
+		}
+		ticketFn := func(t AnalysisTicket) {
+			batch = append(batch, t)
+			if len(batch) >= analysisTicketBatch {
+				sendBatch()
+			}
+		}
+
+		notify(MsgTypeAnalysisStart, map[string]interface{}{"analyzed_columns": columnsToAnalyze})
+		analysis, err := h.analysisService.StreamAnalysis(ctx, userID, columnsToAnalyze, progressFn, ticketFn)
+		if ctx.Err() != nil {
+			fmt.Printf("ANALYZE: Run %d for user %d cancelled\n", runID, userID)
+			notify(MsgTypeAnalysisCancelled, map[string]interface{}{})
+			return
+		}
+		if err != nil {
+			notify(MsgTypeAnalysisError, map[string]interface{}{"error": err.Error()})
+			return
+		}
+		sendBatch()
+
+		h.analysisService.RecordRun(userID, analysis)
+		notify(MsgTypeAnalysisComplete, map[string]interface{}{
+			"analysis":         analysis,
+			"summary":          analysisSummary(analysis),
+			"column_filter":    columnFilter,
+			"mapped_column":    mappedColumnName,
+			"analyzed_columns": columnsToAnalyze,
+		})
+	}()
+
+	utils.SendSuccess(w, map[string]interface{}{"run_id": runID}, "Analysis started")
+}
+
+// CancelAnalysis stops the user's running streamed analysis given by
+// ?run_id=, or all of them
+func (h *Handler) CancelAnalysis(w http.ResponseWriter, r *http.Request) {
+	user, ok := auth.GetUserFromContext(r)
+	if !ok {
+		utils.SendUnauthorized(w, "Authentication required")
+		return
+	}
+
+	runID := 0
+	if v := r.URL.Query().Get("run_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil || id <= 0 {
+			utils.SendBadRequest(w, "Invalid run_id")
+			return
+		}
+		runID = id
+	}
+
+	cancelled := h.analysisRuns.cancel(user.UserID, runID)
+	if runID != 0 && cancelled == 0 {
+		utils.SendNotFound(w, "Analysis run not found")
+		return
+	}
+	utils.SendSuccess(w, map[string]interface{}{"cancelled": cancelled}, "Analysis cancelled")
+}
+
 // GetTicketsByType returns tickets of a specific type
 func (h *Handler) GetTicketsByType(w http.ResponseWriter, r *http.Request) {
 	user, ok := auth.GetUserFromContext(r)
@@ -295,7 +469,7 @@ func (h *Handler) GetTicketsByType(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get tickets by type
-	tickets, err := h.analysisService.GetTicketsByType(user.UserID, ticketType, column)
+	tickets, err := h.analysisService.GetTicketsByType(r.Context(), user.UserID, ticketType, column)
 	if err != nil {
 		utils.SendInternalError(w, fmt.Sprintf("Failed to get tickets: %v", err))
 		return
@@ -348,7 +522,7 @@ func (h *Handler) CreateMissingTickets(w http.ResponseWriter, r *http.Request) {
 	// Resolve column name using shared helper (handles dynamic columns like "to_do" → "to do")
 	var mappedColumn string
 	if columnFilter != "" && columnFilter != "all_syncable" {
-		cols, _ := resolveColumns(columnFilter)
+		cols, _ := workflowFor(h.configService, user.UserID).ResolveColumns(columnFilter)
 		if len(cols) > 0 {
 			mappedColumn = cols[0]
 		}
@@ -381,7 +555,7 @@ func (h *Handler) CreateMissingTickets(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	result, err := h.syncService.CreateMissingTickets(user.UserID, mappedColumn)
+	result, err := h.syncService.CreateMissingTickets(r.Context(), user.UserID, mappedColumn)
 	if err != nil {
 		// Update operation status to failed
 		if operation != nil {
@@ -448,7 +622,7 @@ func (h *Handler) CreateSingleTicket(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	result, err := h.syncService.CreateSingleTicket(user.UserID, req.TaskID)
+	result, err := h.syncService.CreateSingleTicket(r.Context(), user.UserID, req.TaskID)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
 			utils.SendNotFound(w, err.Error())
@@ -491,7 +665,7 @@ func (h *Handler) SyncMismatchedTickets(w http.ResponseWriter, r *http.Request)
 
 	var mappedColumn string
 	if columnFilter != "" && columnFilter != "all_syncable" {
-		cols, _ := resolveColumns(columnFilter)
+		cols, _ := workflowFor(h.configService, user.UserID).ResolveColumns(columnFilter)
 		if len(cols) > 0 {
 			mappedColumn = cols[0]
 		}
@@ -500,7 +674,7 @@ func (h *Handler) SyncMismatchedTickets(w http.ResponseWriter, r *http.Request)
 
 	if r.Method == "GET" {
 		// Return available mismatched tickets for preview
-		result, err := h.syncService.GetMismatchedTickets(user.UserID, mappedColumn)
+		result, err := h.syncService.GetMismatchedTickets(r.Context(), user.UserID, mappedColumn)
 		if err != nil {
 			utils.SendInternalError(w, fmt.Sprintf("Failed to get mismatched tickets: %v", err))
 			return
@@ -554,7 +728,7 @@ func (h *Handler) SyncMismatchedTickets(w http.ResponseWriter, r *http.Request)
 		}
 	}
 
-	result, err := h.syncService.SyncMismatchedTickets(user.UserID, requests, mappedColumn)
+	result, err := h.syncService.SyncMismatchedTickets(r.Context(), user.UserID, requests, mappedColumn)
 	if err != nil {
 		// Update operation status to failed
 		if operation != nil {
@@ -585,12 +759,39 @@ func (h *Handler) SyncMismatchedTickets(w http.ResponseWriter, r *http.Request)
 		"total":  len(requests),
 	}
 
+	h.auditAssigneeChanges(operation.ID, user.Email, result)
+
 	// Mark as completed
 	h.db.UpdateOperationStatus(operation.ID, "completed", nil)
 
 	utils.SendSuccess(w, result, "Sync operation completed")
 }
 
+// auditAssigneeChanges records the assignee changes of a sync in the audit
+// log, with people named as the user directory knows them
+func (h *Handler) auditAssigneeChanges(operationID int, userEmail string, result map[string]interface{}) {
+	results, _ := result["results"].([]map[string]interface{})
+	for _, ticket := range results {
+		change, ok := ticket["assignee_change"].(map[string]string)
+		if !ok {
+			continue
+		}
+		issueID, _ := ticket["youtrack_issue_id"].(string)
+		if _, err := h.db.CreateAuditLogEntry(&database.AuditLogEntry{
+			OperationID: operationID,
+			TicketID:    issueID,
+			Platform:    "youtrack",
+			ActionType:  "updated",
+			UserEmail:   userEmail,
+			OldValue:    change["old"],
+			NewValue:    change["new"],
+			FieldName:   "assignee",
+		}); err != nil {
+			fmt.Printf("WARNING: Failed to audit assignee change of %s: %v\n", issueID, err)
+		}
+	}
+}
+
 // ManageIgnoredTickets manages ignored tickets (both temporary and permanent)
 func (h *Handler) ManageIgnoredTickets(w http.ResponseWriter, r *http.Request) {
 	user, ok := auth.GetUserFromContext(r)
@@ -668,7 +869,7 @@ func (h *Handler) DeleteTickets(w http.ResponseWriter, r *http.Request) {
 		len(req.TicketIDs), req.Source, user.UserID)
 
 	// Perform bulk deletion
-	response := h.deleteService.PerformBulkDelete(user.UserID, req.TicketIDs, req.Source)
+	response := h.deleteService.PerformBulkDelete(r.Context(), user.UserID, req.TicketIDs, req.Source)
 
 	// Set appropriate HTTP status based on result
 	httpStatus := http.StatusOK
@@ -698,7 +899,7 @@ func (h *Handler) GetSyncStats(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	stats, err := h.syncService.GetSyncStats(user.UserID)
+	stats, err := h.syncService.GetSyncStats(r.Context(), user.UserID)
 	if err != nil {
 		utils.SendInternalError(w, fmt.Sprintf("Failed to get sync stats: %v", err))
 		return
@@ -715,7 +916,7 @@ func (h *Handler) GetSyncableTickets(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	result, err := h.syncService.GetSyncableTickets(user.UserID)
+	result, err := h.syncService.GetSyncableTickets(r.Context(), user.UserID)
 	if err != nil {
 		utils.SendInternalError(w, fmt.Sprintf("Failed to get syncable tickets: %v", err))
 		return
@@ -744,7 +945,7 @@ func (h *Handler) SyncByColumn(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	result, err := h.syncService.SyncTicketsByColumn(user.UserID, column)
+	result, err := h.syncService.SyncTicketsByColumn(r.Context(), user.UserID, column)
 	if err != nil {
 		utils.SendInternalError(w, fmt.Sprintf("Column sync failed: %v", err))
 		return
@@ -773,7 +974,7 @@ func (h *Handler) CreateByColumn(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	result, err := h.syncService.CreateTicketsByColumn(user.UserID, column)
+	result, err := h.syncService.CreateTicketsByColumn(r.Context(), user.UserID, column)
 	if err != nil {
 		utils.SendInternalError(w, fmt.Sprintf("Column create failed: %v", err))
 		return
@@ -804,7 +1005,7 @@ func (h *Handler) GetDeletionPreview(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	preview, err := h.deleteService.GetDeletionPreview(user.UserID, ticketIDs, source)
+	preview, err := h.deleteService.GetDeletionPreview(r.Context(), user.UserID, ticketIDs, source)
 	if err != nil {
 		utils.SendInternalError(w, fmt.Sprintf("Failed to get deletion preview: %v", err))
 		return
@@ -829,7 +1030,7 @@ func (h *Handler) GetSyncPreview(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	preview, err := h.syncService.GetSyncPreview(user.UserID, ticketIDs)
+	preview, err := h.syncService.GetSyncPreview(r.Context(), user.UserID, ticketIDs)
 	if err != nil {
 		utils.SendInternalError(w, fmt.Sprintf("Failed to get sync preview: %v", err))
 		return
@@ -878,10 +1079,10 @@ func (h *Handler) AnalyzeTicketsEnhanced(w http.ResponseWriter, r *http.Request)
 	var columnsToAnalyze []string
 	var mappedColumnName string
 
-	columnsToAnalyze, mappedColumnName = resolveColumns(columnFilter)
+	columnsToAnalyze, mappedColumnName = workflowFor(h.configService, user.UserID).ResolveColumns(columnFilter)
 
 	// Perform analysis with filtering and sorting
-	analysis, err := h.analysisService.PerformAnalysisWithFiltering(user.UserID, columnsToAnalyze, filter, sortOpts)
+	analysis, err := h.analysisService.PerformAnalysisWithFiltering(r.Context(), user.UserID, columnsToAnalyze, filter, sortOpts)
 	if err != nil {
 		fmt.Printf("ANALYZE: Analysis failed for user %d: %v\n", user.UserID, err)
 		utils.SendInternalError(w, fmt.Sprintf("Analysis failed: %v", err))
@@ -889,14 +1090,14 @@ func (h *Handler) AnalyzeTicketsEnhanced(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Get summary statistics
-	summary, err := h.analysisService.GetAnalysisSummary(user.UserID, columnsToAnalyze)
+	summary, err := h.analysisService.GetAnalysisSummary(r.Context(), user.UserID, columnsToAnalyze)
 	if err != nil {
 		utils.SendInternalError(w, fmt.Sprintf("Failed to get summary: %v", err))
 		return
 	}
 
 	// Get available filter options
-	filterOptions, err := h.analysisService.GetFilterOptions(user.UserID, columnsToAnalyze)
+	filterOptions, err := h.analysisService.GetFilterOptions(r.Context(), user.UserID, columnsToAnalyze)
 	if err != nil {
 		fmt.Printf("ANALYZE: Failed to get filter options: %v\n", err)
 	}
@@ -931,9 +1132,9 @@ func (h *Handler) GetFilterOptions(w http.ResponseWriter, r *http.Request) {
 	columnFilter := r.URL.Query().Get("column")
 	var columnsToAnalyze []string
 
-	columnsToAnalyze, _ = resolveColumns(columnFilter)
+	columnsToAnalyze, _ = workflowFor(h.configService, user.UserID).ResolveColumns(columnFilter)
 
-	filterOptions, err := h.analysisService.GetFilterOptions(user.UserID, columnsToAnalyze)
+	filterOptions, err := h.analysisService.GetFilterOptions(r.Context(), user.UserID, columnsToAnalyze)
 	if err != nil {
 		utils.SendInternalError(w, fmt.Sprintf("Failed to get filter options: %v", err))
 		return
@@ -1034,7 +1235,7 @@ func (h *Handler) GetYouTrackStatesRaw(w http.ResponseWriter, r *http.Request) {
 	}
 
 	youtrackService := NewYouTrackService(h.configService)
-	issues, err := youtrackService.GetIssues(user.UserID)
+	issues, err := youtrackService.GetIssues(r.Context(), user.UserID)
 	if err != nil {
 		utils.SendInternalError(w, fmt.Sprintf("Failed to get YouTrack issues: %v", err))
 		return
@@ -1047,11 +1248,10 @@ func (h *Handler) GetYouTrackStatesRaw(w http.ResponseWriter, r *http.Request) {
 		for _, field := range issue.CustomFields {
 			if field.Name == "State" {
 				info := map[string]interface{}{
-					"issue_id":          issue.ID,
-					"issue_summary":     issue.Summary,
-					"raw_field_value":   field.Value,
-					"extracted_status":  youtrackService.GetStatus(issue),
-					"normalized_status": youtrackService.GetStatusNormalized(issue),
+					"issue_id":         issue.ID,
+					"issue_summary":    issue.Summary,
+					"raw_field_value":  field.Value,
+					"extracted_status": youtrackService.GetStatus(issue),
 				}
 				stateInfo = append(stateInfo, info)
 			}
@@ -1105,6 +1305,368 @@ func (h *Handler) MapTicket(w http.ResponseWriter, r *http.Request) {
 	utils.SendSuccess(w, map[string]interface{}{"success": true, "message": "Mapping saved"}, "Mapping created successfully")
 }
 
+// ListMatchSuggestions returns the sync pair's match suggestions, optionally
+// filtered by ?status=pending|accepted|rejected
+func (h *Handler) ListMatchSuggestions(w http.ResponseWriter, r *http.Request) {
+	user, ok := auth.GetUserFromContext(r)
+	if !ok {
+		utils.SendUnauthorized(w, "Authentication required")
+		return
+	}
+
+	suggestions, err := h.suggestions.GetSuggestions(user.UserID, r.URL.Query().Get("status"))
+	if err != nil {
+		utils.SendBadRequest(w, err.Error())
+		return
+	}
+
+	utils.SendSuccess(w, map[string]interface{}{
+		"suggestions": suggestions,
+		"count":       len(suggestions),
+	}, "Match suggestions retrieved successfully")
+}
+
+// AcceptMatchSuggestion maps the tickets of a pending suggestion
+func (h *Handler) AcceptMatchSuggestion(w http.ResponseWriter, r *http.Request) {
+	h.reviewMatchSuggestion(w, r, h.suggestions.Accept, "Match suggestion accepted")
+}
+
+// RejectMatchSuggestion remembers a pending suggestion as a negative match
+func (h *Handler) RejectMatchSuggestion(w http.ResponseWriter, r *http.Request) {
+	h.reviewMatchSuggestion(w, r, h.suggestions.Reject, "Match suggestion rejected")
+}
+
+func (h *Handler) reviewMatchSuggestion(w http.ResponseWriter, r *http.Request, review func(userID, suggestionID int) (*database.MatchSuggestion, error), message string) {
+	user, ok := auth.GetUserFromContext(r)
+	if !ok {
+		utils.SendUnauthorized(w, "Authentication required")
+		return
+	}
+
+	suggestionID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utils.SendBadRequest(w, "Invalid match suggestion ID")
+		return
+	}
+
+	suggestion, err := review(user.UserID, suggestionID)
+	if err != nil {
+		utils.SendBadRequest(w, err.Error())
+		return
+	}
+
+	fmt.Printf("MATCH-SUGGESTION: Asana %s <-> YT %s %s by user %d\n",
+		suggestion.AsanaTaskID, suggestion.YouTrackIssueID, suggestion.Status, user.UserID)
+	utils.SendSuccess(w, suggestion, message)
+}
+
+// ListOrphans returns the sync pair's YouTrack issues whose Asana task
+// vanished, optionally filtered by ?status=open|resolved
+func (h *Handler) ListOrphans(w http.ResponseWriter, r *http.Request) {
+	user, ok := auth.GetUserFromContext(r)
+	if !ok {
+		utils.SendUnauthorized(w, "Authentication required")
+		return
+	}
+
+	orphans, err := h.orphans.GetOrphans(user.UserID, r.URL.Query().Get("status"))
+	if err != nil {
+		utils.SendBadRequest(w, err.Error())
+		return
+	}
+
+	utils.SendSuccess(w, map[string]interface{}{
+		"orphans": orphans,
+		"count":   len(orphans),
+	}, "Orphaned issues retrieved successfully")
+}
+
+// ApplyOrphanPolicy applies the sync pair's orphan policy now instead of
+// waiting for the next auto-sync
+func (h *Handler) ApplyOrphanPolicy(w http.ResponseWriter, r *http.Request) {
+	user, ok := auth.GetUserFromContext(r)
+	if !ok {
+		utils.SendUnauthorized(w, "Authentication required")
+		return
+	}
+
+	result, err := h.orphans.ApplyPolicy(r.Context(), user.UserID)
+	if err != nil {
+		utils.SendInternalError(w, err.Error())
+		return
+	}
+
+	utils.SendSuccess(w, result, "Orphan policy applied")
+}
+
+// OrphanAction takes an action on one orphaned issue, whatever the policy.
+// relink takes an optional asana_task_id; without it the issue is relinked to
+// the unlinked task the matching strategies pair it with.
+func (h *Handler) OrphanAction(w http.ResponseWriter, r *http.Request) {
+	user, ok := auth.GetUserFromContext(r)
+	if !ok {
+		utils.SendUnauthorized(w, "Authentication required")
+		return
+	}
+
+	orphanID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utils.SendBadRequest(w, "Invalid orphaned issue ID")
+		return
+	}
+
+	var req struct {
+		Action      string `json:"action"`
+		AsanaTaskID string `json:"asana_task_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.SendBadRequest(w, "Invalid request body")
+		return
+	}
+
+	orphan, err := h.orphans.Act(r.Context(), user.UserID, orphanID, strings.TrimSpace(req.Action), strings.TrimSpace(req.AsanaTaskID))
+	if err != nil {
+		utils.SendBadRequest(w, err.Error())
+		return
+	}
+	if orphan.ActionError != "" {
+		utils.SendBadRequest(w, orphan.ActionError)
+		return
+	}
+
+	fmt.Printf("ORPHANS: %s applied to YT %s by user %d\n", orphan.Action, orphan.YouTrackIssueID, user.UserID)
+	utils.SendSuccess(w, orphan, "Orphan action applied")
+}
+
+// ListIdentities returns the links between Asana users and YouTrack users
+func (h *Handler) ListIdentities(w http.ResponseWriter, r *http.Request) {
+	user, ok := auth.GetUserFromContext(r)
+	if !ok {
+		utils.SendUnauthorized(w, "Authentication required")
+		return
+	}
+
+	identities, err := h.identities.GetIdentities(user.UserID)
+	if err != nil {
+		utils.SendInternalError(w, err.Error())
+		return
+	}
+
+	utils.SendSuccess(w, map[string]interface{}{
+		"identities": identities,
+		"count":      len(identities),
+	}, "User identities retrieved successfully")
+}
+
+// LinkIdentity links an Asana user to a YouTrack user, given by ID or login,
+// replacing the Asana user's previous link
+func (h *Handler) LinkIdentity(w http.ResponseWriter, r *http.Request) {
+	user, ok := auth.GetUserFromContext(r)
+	if !ok {
+		utils.SendUnauthorized(w, "Authentication required")
+		return
+	}
+
+	var req struct {
+		AsanaUserGID string `json:"asana_user_gid"`
+		YouTrackUser string `json:"youtrack_user"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.SendBadRequest(w, "Invalid request body")
+		return
+	}
+
+	identity, err := h.identities.Link(r.Context(), user.UserID, strings.TrimSpace(req.AsanaUserGID), strings.TrimSpace(req.YouTrackUser))
+	if err != nil {
+		utils.SendBadRequest(w, err.Error())
+		return
+	}
+
+	fmt.Printf("IDENTITIES: Asana %s linked to YT %s by user %d\n", identity.AsanaUserGID, identity.YouTrackLogin, user.UserID)
+	utils.SendSuccess(w, identity, "User identity linked")
+}
+
+// UnlinkIdentity removes a link between an Asana user and a YouTrack user
+func (h *Handler) UnlinkIdentity(w http.ResponseWriter, r *http.Request) {
+	user, ok := auth.GetUserFromContext(r)
+	if !ok {
+		utils.SendUnauthorized(w, "Authentication required")
+		return
+	}
+
+	identityID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utils.SendBadRequest(w, "Invalid identity ID")
+		return
+	}
+
+	if err := h.identities.Unlink(user.UserID, identityID); err != nil {
+		utils.SendBadRequest(w, err.Error())
+		return
+	}
+
+	utils.SendSuccess(w, nil, "User identity unlinked")
+}
+
+// GetIdentityDirectory returns the stored user directory, flagging the users
+// on either side who are not linked
+func (h *Handler) GetIdentityDirectory(w http.ResponseWriter, r *http.Request) {
+	user, ok := auth.GetUserFromContext(r)
+	if !ok {
+		utils.SendUnauthorized(w, "Authentication required")
+		return
+	}
+
+	directory, err := h.identities.Directory(user.UserID)
+	if err != nil {
+		utils.SendInternalError(w, err.Error())
+		return
+	}
+
+	utils.SendSuccess(w, directory, "User directory retrieved successfully")
+}
+
+// RefreshIdentityDirectory pulls the Asana and YouTrack users into the
+// directory and links those with the same email
+func (h *Handler) RefreshIdentityDirectory(w http.ResponseWriter, r *http.Request) {
+	user, ok := auth.GetUserFromContext(r)
+	if !ok {
+		utils.SendUnauthorized(w, "Authentication required")
+		return
+	}
+
+	directory, err := h.identities.Refresh(r.Context(), user.UserID)
+	if err != nil {
+		utils.SendInternalError(w, err.Error())
+		return
+	}
+
+	utils.SendSuccess(w, directory, fmt.Sprintf("User directory refreshed, %d users linked by email", len(directory.Linked)))
+}
+
+// GetAnalysisHistory lists the sync pair's recorded analysis runs, newest
+// first. ?since takes an RFC 3339 time or a duration like 24h; ?limit caps the
+// number of runs.
+func (h *Handler) GetAnalysisHistory(w http.ResponseWriter, r *http.Request) {
+	user, ok := auth.GetUserFromContext(r)
+	if !ok {
+		utils.SendUnauthorized(w, "Authentication required")
+		return
+	}
+
+	var since time.Time
+	if s := r.URL.Query().Get("since"); s != "" {
+		if d, err := time.ParseDuration(s); err == nil {
+			since = time.Now().Add(-d)
+		} else if t, err := time.Parse(time.RFC3339, s); err == nil {
+			since = t
+		} else {
+			utils.SendBadRequest(w, "Invalid since: use an RFC 3339 time or a duration like 24h")
+			return
+		}
+	}
+
+	limit := 0
+	if s := r.URL.Query().Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			utils.SendBadRequest(w, "Invalid limit")
+			return
+		}
+		limit = n
+	}
+
+	runs, err := h.history.GetHistory(user.UserID, since, limit)
+	if err != nil {
+		utils.SendInternalError(w, err.Error())
+		return
+	}
+
+	utils.SendSuccess(w, map[string]interface{}{
+		"runs":  runs,
+		"count": len(runs),
+	}, "Analysis history retrieved successfully")
+}
+
+// DiffAnalysisRuns lists the tickets that entered or left each bucket between
+// two analysis runs. ?to defaults to the latest run and ?from to the run of
+// the same columns before it.
+func (h *Handler) DiffAnalysisRuns(w http.ResponseWriter, r *http.Request) {
+	user, ok := auth.GetUserFromContext(r)
+	if !ok {
+		utils.SendUnauthorized(w, "Authentication required")
+		return
+	}
+
+	var ids [2]int
+	for i, param := range []string{"from", "to"} {
+		if s := r.URL.Query().Get(param); s != "" {
+			id, err := strconv.Atoi(s)
+			if err != nil || id <= 0 {
+				utils.SendBadRequest(w, fmt.Sprintf("Invalid %s run ID", param))
+				return
+			}
+			ids[i] = id
+		}
+	}
+
+	diff, err := h.history.Diff(user.UserID, ids[0], ids[1])
+	if err != nil {
+		utils.SendNotFound(w, err.Error())
+		return
+	}
+
+	utils.SendSuccess(w, diff, fmt.Sprintf("Compared analysis runs %d and %d", diff.From.ID, diff.To.ID))
+}
+
+// ScanDuplicates lists groups of YouTrack issues that were created more than
+// once for the same Asana task
+func (h *Handler) ScanDuplicates(w http.ResponseWriter, r *http.Request) {
+	user, ok := auth.GetUserFromContext(r)
+	if !ok {
+		utils.SendUnauthorized(w, "Authentication required")
+		return
+	}
+
+	groups, err := h.duplicates.ScanDuplicates(r.Context(), user.UserID)
+	if err != nil {
+		utils.SendInternalError(w, fmt.Sprintf("Duplicate scan failed: %v", err))
+		return
+	}
+	if groups == nil {
+		groups = []DuplicateGroup{}
+	}
+
+	utils.SendSuccess(w, map[string]interface{}{
+		"groups": groups,
+		"count":  len(groups),
+	}, "Duplicate scan completed")
+}
+
+// MergeDuplicates merges duplicate YouTrack issues into the one to keep
+func (h *Handler) MergeDuplicates(w http.ResponseWriter, r *http.Request) {
+	user, ok := auth.GetUserFromContext(r)
+	if !ok {
+		utils.SendUnauthorized(w, "Authentication required")
+		return
+	}
+
+	var req MergeDuplicatesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.SendBadRequest(w, "Invalid JSON body")
+		return
+	}
+
+	result, err := h.duplicates.MergeDuplicates(r.Context(), user.UserID, req)
+	if err != nil {
+		utils.SendBadRequest(w, err.Error())
+		return
+	}
+
+	h.analysisService.youtrackService.InvalidateIssueCache(user.UserID)
+	utils.SendSuccess(w, result, fmt.Sprintf("Merged %d duplicates into %s", len(result.Merged), result.KeepIssueID))
+}
+
 // AddToBoard adds a list of YouTrack issues to the configured agile board
 func (h *Handler) AddToBoard(w http.ResponseWriter, r *http.Request) {
 	user, ok := auth.GetUserFromContext(r)
@@ -1130,7 +1692,7 @@ func (h *Handler) AddToBoard(w http.ResponseWriter, r *http.Request) {
 	ytService := NewYouTrackService(h.configService)
 	results := map[string]string{}
 	for _, issueID := range req.IssueIDs {
-		if err := ytService.AssignIssueToBoard(user.UserID, issueID); err != nil {
+		if err := ytService.AssignIssueToBoard(r.Context(), user.UserID, issueID); err != nil {
 			results[issueID] = "failed: " + err.Error()
 		} else {
 			results[issueID] = "ok"
@@ -1176,7 +1738,7 @@ func (h *Handler) SyncPriorities(w http.ResponseWriter, r *http.Request) {
 		if item.YouTrackIssueID == "" || item.Priority == "" {
 			continue
 		}
-		err := ytService.SyncPriority(settings, item.YouTrackIssueID, item.Priority)
+		err := ytService.SyncPriority(r.Context(), settings, item.YouTrackIssueID, item.Priority)
 		entry := map[string]interface{}{
 			"youtrack_issue_id": item.YouTrackIssueID,
 			"priority":          item.Priority,
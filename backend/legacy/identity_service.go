@@ -0,0 +1,489 @@
+package legacy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	configpkg "asana-youtrack-sync/config"
+	"asana-youtrack-sync/database"
+)
+
+// identityDirectoryTTL is how long auto-sync trusts the stored directory
+// before refreshing it
+const identityDirectoryTTL = 24 * time.Hour
+
+// identityIndex looks user identities up from either side, along with the
+// directory of known users
+type identityIndex struct {
+	byAsana       map[string]*database.UserIdentity  // by Asana user GID
+	byYouTrack    map[string]*database.UserIdentity  // by YouTrack user ID and lower-case login
+	asanaUsers    map[string]*database.DirectoryUser // by Asana user GID
+	youtrackUsers map[string]*database.DirectoryUser // by YouTrack user ID
+	refreshedAt   time.Time                          // of the oldest directory entry
+}
+
+// identityCache holds each user's identities between changes; any change
+// clears it for everybody, as organization members share their identities
+var identityCache = make(map[int]*identityIndex)
+var identityCacheMutex sync.RWMutex
+
+func invalidateIdentities() {
+	identityCacheMutex.Lock()
+	identityCache = make(map[int]*identityIndex)
+	identityCacheMutex.Unlock()
+}
+
+// loadIdentities returns the user's identities and directory. A nil db or a
+// failed load gives an empty index, so callers fall back to matching names.
+func loadIdentities(db *database.DB, userID int) *identityIndex {
+	identityCacheMutex.RLock()
+	if cached, ok := identityCache[userID]; ok {
+		identityCacheMutex.RUnlock()
+		return cached
+	}
+	identityCacheMutex.RUnlock()
+
+	index := &identityIndex{
+		byAsana:       make(map[string]*database.UserIdentity),
+		byYouTrack:    make(map[string]*database.UserIdentity),
+		asanaUsers:    make(map[string]*database.DirectoryUser),
+		youtrackUsers: make(map[string]*database.DirectoryUser),
+	}
+	if db == nil {
+		return index
+	}
+	identities, err := db.GetUserIdentities(userID)
+	if err != nil {
+		fmt.Printf("IDENTITIES: Failed to load identities for user %d: %v\n", userID, err)
+		return index
+	}
+	for _, identity := range identities {
+		index.byAsana[identity.AsanaUserGID] = identity
+		index.byYouTrack[identity.YouTrackUserID] = identity
+		if identity.YouTrackLogin != "" {
+			index.byYouTrack[strings.ToLower(identity.YouTrackLogin)] = identity
+		}
+	}
+	users, err := db.GetDirectoryUsers(userID)
+	if err != nil {
+		fmt.Printf("IDENTITIES: Failed to load the user directory for user %d: %v\n", userID, err)
+	}
+	for _, u := range users {
+		if u.Platform == database.DirectoryPlatformAsana {
+			index.asanaUsers[u.ExternalID] = u
+		} else {
+			index.youtrackUsers[u.ExternalID] = u
+		}
+		if index.refreshedAt.IsZero() || u.RefreshedAt.Before(index.refreshedAt) {
+			index.refreshedAt = u.RefreshedAt
+		}
+	}
+
+	identityCacheMutex.Lock()
+	identityCache[userID] = index
+	identityCacheMutex.Unlock()
+	return index
+}
+
+// hasDirectory reports whether the user directory has been pulled. Once it
+// has, users are only matched through their identities.
+func (ix *identityIndex) hasDirectory() bool {
+	return len(ix.asanaUsers) > 0 || len(ix.youtrackUsers) > 0
+}
+
+// empty reports whether nobody is linked yet
+func (ix *identityIndex) empty() bool {
+	return len(ix.byAsana) == 0
+}
+
+// youTrackFor returns the identity of an Asana user, or nil
+func (ix *identityIndex) youTrackFor(asanaGID string) *database.UserIdentity {
+	if asanaGID == "" {
+		return nil
+	}
+	return ix.byAsana[asanaGID]
+}
+
+// asanaFor returns the identity of a YouTrack user, or nil
+func (ix *identityIndex) asanaFor(user YouTrackUser) *database.UserIdentity {
+	if identity, ok := ix.byYouTrack[user.ID]; ok && user.ID != "" {
+		return identity
+	}
+	if user.Login != "" {
+		return ix.byYouTrack[strings.ToLower(user.Login)]
+	}
+	return nil
+}
+
+// assigneeMatches reports whether the task's assignee is one of the issue's
+// assignees. A linked assignee is compared through its identity; otherwise
+// names are compared, with the first-name fallback only while nobody is
+// linked and there is no directory.
+func assigneeMatches(identities *identityIndex, task AsanaTask, assignees []YouTrackUser) bool {
+	if identity := identities.youTrackFor(task.Assignee.GID); identity != nil {
+		for _, u := range assignees {
+			if u.ID == identity.YouTrackUserID || (u.Login != "" && strings.EqualFold(u.Login, identity.YouTrackLogin)) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, u := range assignees {
+		if identities.empty() && !identities.hasDirectory() && assigneeNamesMatch(task.Assignee.Name, u.FullName) {
+			return true
+		}
+		if strings.EqualFold(strings.TrimSpace(task.Assignee.Name), strings.TrimSpace(u.FullName)) {
+			return true
+		}
+	}
+	return false
+}
+
+// assigneeNames joins the names of an issue's assignees for display
+func assigneeNames(assignees []YouTrackUser) string {
+	names := make([]string, 0, len(assignees))
+	for _, u := range assignees {
+		names = append(names, u.FullName)
+	}
+	return strings.Join(names, ", ")
+}
+
+// describeYouTrackUser names a YouTrack user for audit logs, with the email
+// the directory knows for them
+func (ix *identityIndex) describeYouTrackUser(u YouTrackUser) string {
+	email := u.Email
+	if email == "" {
+		if known, ok := ix.youtrackUsers[u.ID]; ok {
+			email = known.Email
+		} else if identity := ix.asanaFor(u); identity != nil {
+			email = identity.Email
+		}
+	}
+	if email == "" {
+		return u.FullName
+	}
+	return fmt.Sprintf("%s <%s>", u.FullName, email)
+}
+
+// IdentityDirectory is the stored user directory: who is linked, and who on
+// either side is not
+type IdentityDirectory struct {
+	Identities       []*database.UserIdentity  `json:"identities"`
+	UnlinkedAsana    []*database.DirectoryUser `json:"unlinked_asana"`
+	UnlinkedYouTrack []*database.DirectoryUser `json:"unlinked_youtrack"`
+	// Zero until the directory is first refreshed
+	RefreshedAt time.Time `json:"refreshed_at"`
+	// Set by a refresh: the identities it linked by email, and why others
+	// could not be
+	Linked []*database.UserIdentity `json:"linked,omitempty"`
+	Errors []string                 `json:"errors,omitempty"`
+}
+
+// IdentityService manages the links between Asana users and YouTrack users
+type IdentityService struct {
+	db              *database.DB
+	asanaService    *AsanaService
+	youtrackService *YouTrackService
+}
+
+// NewIdentityService creates a new identity service
+func NewIdentityService(db *database.DB, configService *configpkg.Service) *IdentityService {
+	asanaSvc := NewAsanaService(configService)
+	return &IdentityService{
+		db:              db,
+		asanaService:    asanaSvc,
+		youtrackService: NewYouTrackService(configService, asanaSvc),
+	}
+}
+
+// GetIdentities lists the scope's user identities
+func (s *IdentityService) GetIdentities(userID int) ([]*database.UserIdentity, error) {
+	identities, err := s.db.GetUserIdentities(userID)
+	if err != nil {
+		return nil, err
+	}
+	if identities == nil {
+		identities = []*database.UserIdentity{}
+	}
+	return identities, nil
+}
+
+// Link links an Asana workspace user to a YouTrack user, given by ID or
+// login. Both must exist.
+func (s *IdentityService) Link(ctx context.Context, userID int, asanaGID, youtrackUser string) (*database.UserIdentity, error) {
+	if asanaGID == "" || youtrackUser == "" {
+		return nil, fmt.Errorf("asana_user_gid and youtrack_user are required")
+	}
+
+	asanaUsers, err := s.asanaService.GetWorkspaceUsers(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Asana users: %w", err)
+	}
+	var asanaUser *AsanaUser
+	for i := range asanaUsers {
+		if asanaUsers[i].GID == asanaGID {
+			asanaUser = &asanaUsers[i]
+			break
+		}
+	}
+	if asanaUser == nil {
+		return nil, fmt.Errorf("Asana user %s not found in the workspace", asanaGID)
+	}
+
+	ytUsers, err := s.youtrackService.GetAllUsers(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get YouTrack users: %w", err)
+	}
+	var ytUser *YouTrackUser
+	for i := range ytUsers {
+		if ytUsers[i].ID == youtrackUser || strings.EqualFold(ytUsers[i].Login, youtrackUser) {
+			ytUser = &ytUsers[i]
+			break
+		}
+	}
+	if ytUser == nil {
+		return nil, fmt.Errorf("YouTrack user %s not found", youtrackUser)
+	}
+
+	return saveIdentity(s.db, userID, *asanaUser, *ytUser, database.IdentitySourceManual)
+}
+
+// Unlink removes a user identity
+func (s *IdentityService) Unlink(userID, identityID int) error {
+	if err := s.db.DeleteUserIdentity(userID, identityID); err != nil {
+		return err
+	}
+	invalidateIdentities()
+	return nil
+}
+
+// Directory returns the stored user directory without calling either API
+func (s *IdentityService) Directory(userID int) (*IdentityDirectory, error) {
+	return buildDirectory(s.db, userID)
+}
+
+// Refresh pulls the Asana workspace users and YouTrack users into the
+// directory and links the users whose emails match
+func (s *IdentityService) Refresh(ctx context.Context, userID int) (*IdentityDirectory, error) {
+	return refreshDirectory(ctx, s.db, s.asanaService, s.youtrackService, userID)
+}
+
+func buildDirectory(db *database.DB, userID int) (*IdentityDirectory, error) {
+	identities, err := db.GetUserIdentities(userID)
+	if err != nil {
+		return nil, err
+	}
+	users, err := db.GetDirectoryUsers(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	linked := make(map[string]bool)
+	for _, identity := range identities {
+		linked[database.DirectoryPlatformAsana+":"+identity.AsanaUserGID] = true
+		linked[database.DirectoryPlatformYouTrack+":"+identity.YouTrackUserID] = true
+	}
+	directory := &IdentityDirectory{
+		Identities:       identities,
+		UnlinkedAsana:    []*database.DirectoryUser{},
+		UnlinkedYouTrack: []*database.DirectoryUser{},
+	}
+	if directory.Identities == nil {
+		directory.Identities = []*database.UserIdentity{}
+	}
+	for _, u := range users {
+		if directory.RefreshedAt.IsZero() || u.RefreshedAt.Before(directory.RefreshedAt) {
+			directory.RefreshedAt = u.RefreshedAt
+		}
+		if linked[u.Platform+":"+u.ExternalID] {
+			continue
+		}
+		if u.Platform == database.DirectoryPlatformAsana {
+			directory.UnlinkedAsana = append(directory.UnlinkedAsana, u)
+		} else {
+			directory.UnlinkedYouTrack = append(directory.UnlinkedYouTrack, u)
+		}
+	}
+	return directory, nil
+}
+
+// refreshDirectory refetches both user lists, stores them and links every
+// unlinked Asana user to the unlinked YouTrack user with the same email
+func refreshDirectory(ctx context.Context, db *database.DB, asanaService *AsanaService, youtrackService *YouTrackService, userID int) (*IdentityDirectory, error) {
+	invalidateUsers(userID)
+	asanaUsers, err := asanaService.GetWorkspaceUsers(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Asana users: %w", err)
+	}
+	ytUsers, err := youtrackService.GetAllUsers(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get YouTrack users: %w", err)
+	}
+
+	entries := make([]*database.DirectoryUser, 0, len(asanaUsers))
+	for _, u := range asanaUsers {
+		entries = append(entries, &database.DirectoryUser{ExternalID: u.GID, Name: u.Name, Email: u.Email})
+	}
+	if err := db.ReplaceDirectoryUsers(userID, database.DirectoryPlatformAsana, entries); err != nil {
+		return nil, fmt.Errorf("failed to store Asana users: %w", err)
+	}
+	entries = make([]*database.DirectoryUser, 0, len(ytUsers))
+	byEmail := make(map[string]YouTrackUser)
+	for _, u := range ytUsers {
+		entries = append(entries, &database.DirectoryUser{ExternalID: u.ID, Name: u.FullName, Email: u.Email, Login: u.Login})
+		if email := strings.ToLower(strings.TrimSpace(u.Email)); email != "" {
+			byEmail[email] = u
+		}
+	}
+	if err := db.ReplaceDirectoryUsers(userID, database.DirectoryPlatformYouTrack, entries); err != nil {
+		return nil, fmt.Errorf("failed to store YouTrack users: %w", err)
+	}
+	invalidateIdentities()
+
+	identities := loadIdentities(db, userID)
+	var linked []*database.UserIdentity
+	var errs []string
+	for _, asanaUser := range asanaUsers {
+		if identities.youTrackFor(asanaUser.GID) != nil {
+			continue
+		}
+		ytUser, ok := byEmail[strings.ToLower(strings.TrimSpace(asanaUser.Email))]
+		if !ok || identities.asanaFor(ytUser) != nil {
+			continue
+		}
+		identity, err := saveIdentity(db, userID, asanaUser, ytUser, database.IdentitySourceEmail)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		linked = append(linked, identity)
+	}
+
+	directory, err := buildDirectory(db, userID)
+	if err != nil {
+		return nil, err
+	}
+	directory.Linked = linked
+	directory.Errors = errs
+
+	fmt.Printf("IDENTITIES: Refreshed directory for user %d: %d Asana and %d YouTrack users, %d linked by email, %d+%d unlinked\n",
+		userID, len(asanaUsers), len(ytUsers), len(linked), len(directory.UnlinkedAsana), len(directory.UnlinkedYouTrack))
+	return directory, nil
+}
+
+// invalidateUsers drops the cached user lists of both sides, so a refresh
+// sees people who joined or left since
+func invalidateUsers(userID int) {
+	usersCacheMutex.Lock()
+	delete(usersCache, userID)
+	usersCacheMutex.Unlock()
+
+	workspaceUsersMutex.Lock()
+	for key := range workspaceUsersCache {
+		if key.UserID == userID {
+			delete(workspaceUsersCache, key)
+		}
+	}
+	workspaceUsersMutex.Unlock()
+}
+
+func saveIdentity(db *database.DB, userID int, asanaUser AsanaUser, ytUser YouTrackUser, source string) (*database.UserIdentity, error) {
+	email := asanaUser.Email
+	if email == "" {
+		email = ytUser.Email
+	}
+	identity, err := db.SaveUserIdentity(userID, &database.UserIdentity{
+		AsanaUserGID:   asanaUser.GID,
+		AsanaName:      asanaUser.Name,
+		YouTrackUserID: ytUser.ID,
+		YouTrackLogin:  ytUser.Login,
+		YouTrackName:   ytUser.FullName,
+		Email:          email,
+		Source:         source,
+	})
+	if err != nil {
+		return nil, err
+	}
+	invalidateIdentities()
+	return identity, nil
+}
+
+// assigneeChange describes how syncing the task will change the issue's
+// assignee, naming people as the directory knows them, or returns nil when
+// the task's assignee isn't linked or already assigned
+func (s *SyncService) assigneeChange(userID int, task AsanaTask, issue *YouTrackIssue) map[string]string {
+	identities := loadIdentities(s.db, userID)
+	identity := identities.youTrackFor(task.Assignee.GID)
+	if identity == nil || issue == nil {
+		return nil
+	}
+	current := s.youtrackService.GetAssignees(*issue)
+	if assigneeMatches(identities, task, current) {
+		return nil
+	}
+
+	old := make([]string, 0, len(current))
+	for _, u := range current {
+		old = append(old, identities.describeYouTrackUser(u))
+	}
+	return map[string]string{
+		"old": strings.Join(old, ", "),
+		"new": identities.describeYouTrackUser(YouTrackUser{
+			ID:       identity.YouTrackUserID,
+			Login:    identity.YouTrackLogin,
+			FullName: identity.YouTrackName,
+		}),
+	}
+}
+
+// syncFollowers makes the task's followers watch the issue and the issue's
+// watchers follow the task, through the linked identities. People are only
+// ever added, so unfollowing on one side doesn't undo the other.
+func (s *SyncService) syncFollowers(ctx context.Context, userID int, task AsanaTask, issueID string) error {
+	identities := loadIdentities(s.db, userID)
+	if identities.empty() {
+		return nil
+	}
+
+	watchers, err := s.youtrackService.GetWatchers(ctx, userID, issueID)
+	if err != nil {
+		return fmt.Errorf("failed to get watchers of %s: %w", issueID, err)
+	}
+
+	following := make(map[string]bool)
+	for _, follower := range task.Followers {
+		following[follower.GID] = true
+	}
+	watching := make(map[string]bool)
+	var toFollow []string
+	for _, watcher := range watchers {
+		watching[watcher.User.ID] = true
+		if identity := identities.asanaFor(watcher.User); identity != nil && !following[identity.AsanaUserGID] {
+			following[identity.AsanaUserGID] = true
+			toFollow = append(toFollow, identity.AsanaUserGID)
+		}
+	}
+
+	for _, follower := range task.Followers {
+		identity := identities.youTrackFor(follower.GID)
+		if identity == nil || watching[identity.YouTrackUserID] {
+			continue
+		}
+		if err := s.youtrackService.AddWatcher(ctx, userID, issueID, identity.YouTrackUserID); err != nil {
+			return fmt.Errorf("failed to add %s as watcher of %s: %w", identity.YouTrackLogin, issueID, err)
+		}
+		watching[identity.YouTrackUserID] = true
+		fmt.Printf("SYNC: %s follows Asana task %s — added as watcher of %s\n", follower.Name, task.GID, issueID)
+	}
+
+	if len(toFollow) > 0 {
+		if err := s.asanaService.AddFollowers(ctx, userID, task.GID, toFollow); err != nil {
+			return fmt.Errorf("failed to add followers to Asana task %s: %w", task.GID, err)
+		}
+		fmt.Printf("SYNC: Added %d watchers of %s as followers of Asana task %s\n", len(toFollow), issueID, task.GID)
+	}
+	return nil
+}
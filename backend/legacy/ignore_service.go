@@ -20,14 +20,14 @@ func NewIgnoreService(db *database.DB, configService *configpkg.Service) *Ignore
 	}
 }
 
-// IsIgnored checks if a ticket is ignored (temporarily or forever) for a user's current project
+// IsIgnored checks if a ticket is ignored (temporarily or forever) for the user's current sync pair
 func (s *IgnoreService) IsIgnored(userID int, ticketID string) bool {
 	settings, err := s.configService.GetSettings(userID)
 	if err != nil || settings.AsanaProjectID == "" {
 		return false
 	}
 
-	isIgnored, _ := s.db.IsTicketIgnored(userID, settings.AsanaProjectID, ticketID)
+	isIgnored, _ := s.db.IsTicketIgnored(userID, settings.SyncPairID, ticketID)
 	return isIgnored
 }
 
@@ -38,7 +38,7 @@ func (s *IgnoreService) IsTemporarilyIgnored(userID int, ticketID string) bool {
 		return false
 	}
 
-	isIgnored, ignoreType := s.db.IsTicketIgnored(userID, settings.AsanaProjectID, ticketID)
+	isIgnored, ignoreType := s.db.IsTicketIgnored(userID, settings.SyncPairID, ticketID)
 	return isIgnored && ignoreType == "temp"
 }
 
@@ -49,7 +49,7 @@ func (s *IgnoreService) IsForeverIgnored(userID int, ticketID string) bool {
 		return false
 	}
 
-	isIgnored, ignoreType := s.db.IsTicketIgnored(userID, settings.AsanaProjectID, ticketID)
+	isIgnored, ignoreType := s.db.IsTicketIgnored(userID, settings.SyncPairID, ticketID)
 	return isIgnored && ignoreType == "forever"
 }
 
@@ -64,7 +64,7 @@ func (s *IgnoreService) AddTemporaryIgnore(userID int, ticketID string) error {
 		return fmt.Errorf("no Asana project configured")
 	}
 
-	_, err = s.db.AddIgnoredTicket(userID, settings.AsanaProjectID, ticketID, "temp")
+	_, err = s.db.AddIgnoredTicket(userID, settings.SyncPairID, settings.AsanaProjectID, ticketID, "temp")
 	return err
 }
 
@@ -79,7 +79,7 @@ func (s *IgnoreService) AddForeverIgnore(userID int, ticketID string) error {
 		return fmt.Errorf("no Asana project configured")
 	}
 
-	_, err = s.db.AddIgnoredTicket(userID, settings.AsanaProjectID, ticketID, "forever")
+	_, err = s.db.AddIgnoredTicket(userID, settings.SyncPairID, settings.AsanaProjectID, ticketID, "forever")
 	return err
 }
 
@@ -94,7 +94,7 @@ func (s *IgnoreService) RemoveTemporaryIgnore(userID int, ticketID string) error
 		return fmt.Errorf("no Asana project configured")
 	}
 
-	return s.db.RemoveIgnoredTicket(userID, settings.AsanaProjectID, ticketID, "temp")
+	return s.db.RemoveIgnoredTicket(userID, settings.SyncPairID, ticketID, "temp")
 }
 
 // RemoveForeverIgnore removes a ticket from permanent ignore list
@@ -108,17 +108,17 @@ func (s *IgnoreService) RemoveForeverIgnore(userID int, ticketID string) error {
 		return fmt.Errorf("no Asana project configured")
 	}
 
-	return s.db.RemoveIgnoredTicket(userID, settings.AsanaProjectID, ticketID, "forever")
+	return s.db.RemoveIgnoredTicket(userID, settings.SyncPairID, ticketID, "forever")
 }
 
-// GetTemporarilyIgnored returns all temporarily ignored ticket IDs for user's current project
+// GetTemporarilyIgnored returns all temporarily ignored ticket IDs for the user's current sync pair
 func (s *IgnoreService) GetTemporarilyIgnored(userID int) []string {
 	settings, err := s.configService.GetSettings(userID)
 	if err != nil || settings.AsanaProjectID == "" {
 		return []string{}
 	}
 
-	ignoredTickets, err := s.db.GetIgnoredTickets(userID, settings.AsanaProjectID)
+	ignoredTickets, err := s.db.GetIgnoredTickets(userID, settings.SyncPairID)
 	if err != nil {
 		return []string{}
 	}
@@ -133,14 +133,14 @@ func (s *IgnoreService) GetTemporarilyIgnored(userID int) []string {
 	return tempIgnored
 }
 
-// GetForeverIgnored returns all permanently ignored ticket IDs for user's current project
+// GetForeverIgnored returns all permanently ignored ticket IDs for the user's current sync pair
 func (s *IgnoreService) GetForeverIgnored(userID int) []string {
 	settings, err := s.configService.GetSettings(userID)
 	if err != nil || settings.AsanaProjectID == "" {
 		return []string{}
 	}
 
-	ignoredTickets, err := s.db.GetIgnoredTickets(userID, settings.AsanaProjectID)
+	ignoredTickets, err := s.db.GetIgnoredTickets(userID, settings.SyncPairID)
 	if err != nil {
 		return []string{}
 	}
@@ -155,14 +155,14 @@ func (s *IgnoreService) GetForeverIgnored(userID int) []string {
 	return foreverIgnored
 }
 
-// GetIgnoredTickets returns all ignored ticket IDs (temp + forever) for user's current project
+// GetIgnoredTickets returns all ignored ticket IDs (temp + forever) for the user's current sync pair
 func (s *IgnoreService) GetIgnoredTickets(userID int) []string {
 	settings, err := s.configService.GetSettings(userID)
 	if err != nil || settings.AsanaProjectID == "" {
 		return []string{}
 	}
 
-	ignoredTickets, err := s.db.GetIgnoredTickets(userID, settings.AsanaProjectID)
+	ignoredTickets, err := s.db.GetIgnoredTickets(userID, settings.SyncPairID)
 	if err != nil {
 		return []string{}
 	}
@@ -175,7 +175,7 @@ func (s *IgnoreService) GetIgnoredTickets(userID int) []string {
 	return allIgnored
 }
 
-// ClearTemporaryIgnores clears all temporary ignores for user's current project
+// ClearTemporaryIgnores clears all temporary ignores for the user's current sync pair
 func (s *IgnoreService) ClearTemporaryIgnores(userID int) error {
 	settings, err := s.configService.GetSettings(userID)
 	if err != nil {
@@ -186,10 +186,10 @@ func (s *IgnoreService) ClearTemporaryIgnores(userID int) error {
 		return fmt.Errorf("no Asana project configured")
 	}
 
-	return s.db.ClearIgnoredTickets(userID, settings.AsanaProjectID, "temp")
+	return s.db.ClearIgnoredTickets(userID, settings.SyncPairID, "temp")
 }
 
-// ClearForeverIgnores clears all permanent ignores for user's current project
+// ClearForeverIgnores clears all permanent ignores for the user's current sync pair
 func (s *IgnoreService) ClearForeverIgnores(userID int) error {
 	settings, err := s.configService.GetSettings(userID)
 	if err != nil {
@@ -200,10 +200,10 @@ func (s *IgnoreService) ClearForeverIgnores(userID int) error {
 		return fmt.Errorf("no Asana project configured")
 	}
 
-	return s.db.ClearIgnoredTickets(userID, settings.AsanaProjectID, "forever")
+	return s.db.ClearIgnoredTickets(userID, settings.SyncPairID, "forever")
 }
 
-// ClearAllIgnores clears all ignores (temporary and permanent) for user's current project
+// ClearAllIgnores clears all ignores (temporary and permanent) for the user's current sync pair
 func (s *IgnoreService) ClearAllIgnores(userID int) error {
 	settings, err := s.configService.GetSettings(userID)
 	if err != nil {
@@ -214,10 +214,10 @@ func (s *IgnoreService) ClearAllIgnores(userID int) error {
 		return fmt.Errorf("no Asana project configured")
 	}
 
-	return s.db.ClearIgnoredTickets(userID, settings.AsanaProjectID, "")
+	return s.db.ClearIgnoredTickets(userID, settings.SyncPairID, "")
 }
 
-// GetIgnoreStatus returns the ignore status for user's current project
+// GetIgnoreStatus returns the ignore status for the user's current sync pair
 func (s *IgnoreService) GetIgnoreStatus(userID int) map[string]interface{} {
 	tempIgnored := s.GetTemporarilyIgnored(userID)
 	foreverIgnored := s.GetForeverIgnored(userID)
@@ -267,13 +267,13 @@ func (s *IgnoreService) MoveToTemporary(userID int, ticketID string) error {
 	return s.AddTemporaryIgnore(userID, ticketID)
 }
 
-// HasAnyIgnored checks if there are any ignored tickets for user's current project
+// HasAnyIgnored checks if there are any ignored tickets for the user's current sync pair
 func (s *IgnoreService) HasAnyIgnored(userID int) bool {
 	ignored := s.GetIgnoredTickets(userID)
 	return len(ignored) > 0
 }
 
-// CountIgnored returns the total count of ignored tickets for user's current project
+// CountIgnored returns the total count of ignored tickets for the user's current sync pair
 func (s *IgnoreService) CountIgnored(userID int) int {
 	ignored := s.GetIgnoredTickets(userID)
 	return len(ignored)
@@ -0,0 +1,124 @@
+package legacy
+
+import (
+	"fmt"
+
+	configpkg "asana-youtrack-sync/config"
+	"asana-youtrack-sync/database"
+)
+
+// MatchSuggestionService manages the review queue of pairings analysis wasn't
+// confident about. Accepting a suggestion maps the tickets; rejecting it keeps
+// the pair from being suggested or fuzzy-matched again.
+type MatchSuggestionService struct {
+	db            *database.DB
+	configService *configpkg.Service
+}
+
+// NewMatchSuggestionService creates a new match suggestion service
+func NewMatchSuggestionService(db *database.DB, configService *configpkg.Service) *MatchSuggestionService {
+	return &MatchSuggestionService{
+		db:            db,
+		configService: configService,
+	}
+}
+
+// GetSuggestions lists the current sync pair's suggestions with the given
+// status ("" for all)
+func (s *MatchSuggestionService) GetSuggestions(userID int, status string) ([]*database.MatchSuggestion, error) {
+	switch status {
+	case "", database.MatchSuggestionPending, database.MatchSuggestionAccepted, database.MatchSuggestionRejected:
+	default:
+		return nil, fmt.Errorf("invalid status: %s", status)
+	}
+
+	settings, err := s.configService.GetSettings(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user settings: %w", err)
+	}
+
+	suggestions, err := s.db.GetMatchSuggestions(userID, settings.SyncPairID, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get match suggestions: %w", err)
+	}
+	if suggestions == nil {
+		suggestions = []*database.MatchSuggestion{}
+	}
+	return suggestions, nil
+}
+
+// Accept maps the suggested tickets to each other
+func (s *MatchSuggestionService) Accept(userID, suggestionID int) (*database.MatchSuggestion, error) {
+	settings, err := s.configService.GetSettings(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user settings: %w", err)
+	}
+
+	suggestion, err := s.db.GetMatchSuggestion(userID, settings.SyncPairID, suggestionID)
+	if err != nil {
+		return nil, err
+	}
+	if suggestion.Status != database.MatchSuggestionPending {
+		return nil, fmt.Errorf("match suggestion was already %s", suggestion.Status)
+	}
+
+	if _, err := s.db.CreateTicketMapping(userID, settings.AsanaProjectID, suggestion.AsanaTaskID,
+		settings.YouTrackProjectID, suggestion.YouTrackIssueID); err != nil {
+		return nil, fmt.Errorf("failed to create mapping: %w", err)
+	}
+
+	return s.db.ReviewMatchSuggestion(userID, settings.SyncPairID, suggestionID, database.MatchSuggestionAccepted)
+}
+
+// Reject records the suggestion as a negative match
+func (s *MatchSuggestionService) Reject(userID, suggestionID int) (*database.MatchSuggestion, error) {
+	settings, err := s.configService.GetSettings(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user settings: %w", err)
+	}
+
+	return s.db.ReviewMatchSuggestion(userID, settings.SyncPairID, suggestionID, database.MatchSuggestionRejected)
+}
+
+// PendingTaskIDs returns the Asana tasks that have a suggestion awaiting review
+func (s *MatchSuggestionService) PendingTaskIDs(userID int) map[string]bool {
+	pending := make(map[string]bool)
+	settings, err := s.configService.GetSettings(userID)
+	if err != nil {
+		return pending
+	}
+	suggestions, _ := s.db.GetMatchSuggestions(userID, settings.SyncPairID, database.MatchSuggestionPending)
+	for _, suggestion := range suggestions {
+		pending[suggestion.AsanaTaskID] = true
+	}
+	return pending
+}
+
+// applyToPool marks the current sync pair's rejected suggestions in the pool
+func (s *MatchSuggestionService) applyToPool(userID, syncPairID int, pool *MatchPool) {
+	rejected, _ := s.db.GetMatchSuggestions(userID, syncPairID, database.MatchSuggestionRejected)
+	for _, suggestion := range rejected {
+		pool.Reject(suggestion.AsanaTaskID, suggestion.YouTrackIssueID)
+	}
+}
+
+// record queues suggestions for the given unmatched tasks and drops pending
+// suggestions of tasks that have been matched since
+func (s *MatchSuggestionService) record(userID, syncPairID int, suggestions map[string]TicketMatch, tasks map[string]AsanaTask, matchedTaskIDs []string) {
+	for taskID, m := range suggestions {
+		err := s.db.SaveMatchSuggestion(userID, syncPairID, &database.MatchSuggestion{
+			AsanaTaskID:     taskID,
+			AsanaTaskName:   tasks[taskID].Name,
+			YouTrackIssueID: m.Issue.ID,
+			YouTrackSummary: m.Issue.Summary,
+			MatchMethod:     m.Method,
+			Score:           m.Confidence,
+		})
+		if err != nil {
+			fmt.Printf("ANALYSIS: Failed to save match suggestion %s <-> %s: %v\n", taskID, m.Issue.ID, err)
+		}
+	}
+	if err := s.db.DeletePendingMatchSuggestions(userID, syncPairID, matchedTaskIDs); err != nil {
+		fmt.Printf("ANALYSIS: Failed to prune match suggestions: %v\n", err)
+	}
+}
@@ -0,0 +1,486 @@
+package legacy
+
+import (
+	"hash/fnv"
+	"strings"
+
+	"asana-youtrack-sync/database"
+)
+
+// Match methods reported on matched tickets, in the order analysis tries them
+const (
+	MatchMethodMapping     = "mapping"     // ticket mapping stored in the database
+	MatchMethodAsanaID     = "asana_id"    // "Asana ID:" line in the YouTrack description
+	MatchMethodTitle       = "title"       // normalized title and word overlap
+	MatchMethodSimilarity  = "similarity"  // character trigram / edit distance on titles
+	MatchMethodDescription = "description" // fingerprint of the description text
+)
+
+// Default confidence thresholds of the fuzzy strategies
+const (
+	defaultTitleThreshold       = 0.92
+	defaultSimilarityThreshold  = 0.85
+	defaultDescriptionThreshold = 0.9
+	defaultSuggestionThreshold  = 0.6
+)
+
+// minFingerprintShingles keeps short descriptions ("TBD", a single link) from
+// being matched by fingerprint
+const minFingerprintShingles = 8
+
+// TicketMatcher is one strategy for finding the YouTrack issue that belongs to
+// an Asana task. Match returns the best free candidate in the pool and its
+// confidence between 0 and 1, or nil when no candidate reaches the strategy's
+// threshold.
+type TicketMatcher interface {
+	Name() string
+	Match(task AsanaTask, pool *MatchPool) (*MatchCandidate, float64)
+}
+
+// candidateScorer is implemented by the fuzzy strategies, which can report
+// their best candidate for any minimum score, not just their threshold
+type candidateScorer interface {
+	best(task AsanaTask, pool *MatchPool, minScore float64) (*MatchCandidate, float64)
+}
+
+// TicketMatch is the YouTrack issue found for an Asana task and how it was found
+type TicketMatch struct {
+	Issue      YouTrackIssue
+	Method     string
+	Confidence float64
+}
+
+// Persistent reports whether the match is reliable enough to be stored as a
+// ticket mapping. Similarity and fingerprint matches are only used for the
+// current analysis so a wrong guess doesn't become permanent.
+func (m TicketMatch) Persistent() bool {
+	return m.Method == MatchMethodAsanaID || m.Method == MatchMethodTitle
+}
+
+// legacyMatchMethod reduces a strategy to the match_method values of
+// already-exists tickets from before match strategies: "description" for
+// fingerprint matches and "title" for everything else
+func legacyMatchMethod(method string) string {
+	if method == MatchMethodDescription {
+		return MatchMethodDescription
+	}
+	return MatchMethodTitle
+}
+
+// MatchCandidate is a YouTrack issue with the keys the strategies compare
+type MatchCandidate struct {
+	Issue YouTrackIssue
+
+	// owner is the Asana task the issue is tied to by a ticket mapping or its
+	// description, if any; fuzzy strategies never offer it to other tasks
+	owner string
+	title string
+	words []string
+
+	trigrams    map[string]int
+	fingerprint map[uint64]bool
+}
+
+// MatchPool holds the YouTrack issues of an analysis and which of them are
+// already matched
+type MatchPool struct {
+	candidates []*MatchCandidate
+	byMapping  map[string]*MatchCandidate   // Asana task GID -> mapped issue
+	byAsanaID  map[string]*MatchCandidate   // Asana task GID -> issue naming it in its description
+	byTitle    map[string][]*MatchCandidate // normalized title -> issues
+	used       map[string]bool
+	rejected   map[string]bool // rejectedKey(task, issue) of rejected suggestions
+}
+
+// NewMatchPool indexes the YouTrack issues. mappings maps YouTrack issue IDs to
+// Asana task GIDs.
+func NewMatchPool(issues []YouTrackIssue, mappings map[string]string, extractAsanaID func(YouTrackIssue) string) *MatchPool {
+	pool := &MatchPool{
+		byMapping: make(map[string]*MatchCandidate),
+		byAsanaID: make(map[string]*MatchCandidate),
+		byTitle:   make(map[string][]*MatchCandidate),
+		used:      make(map[string]bool),
+		rejected:  make(map[string]bool),
+	}
+	for _, issue := range issues {
+		c := &MatchCandidate{Issue: issue, title: normalizeTitle(stripYouTrackPrefix(issue.Summary))}
+		c.words = strings.Fields(c.title)
+		if asanaID, ok := mappings[issue.ID]; ok {
+			c.owner = asanaID
+			pool.byMapping[asanaID] = c
+		} else if asanaID := extractAsanaID(issue); asanaID != "" {
+			c.owner = asanaID
+			pool.byAsanaID[asanaID] = c
+		}
+		pool.byTitle[c.title] = append(pool.byTitle[c.title], c)
+		pool.candidates = append(pool.candidates, c)
+	}
+	return pool
+}
+
+// Use marks an issue as matched
+func (p *MatchPool) Use(issueID string) {
+	p.used[issueID] = true
+}
+
+// Used reports whether an issue is already matched
+func (p *MatchPool) Used(issueID string) bool {
+	return p.used[issueID]
+}
+
+// Reject keeps fuzzy strategies from pairing the task and issue again
+func (p *MatchPool) Reject(asanaTaskID, issueID string) {
+	p.rejected[rejectedKey(asanaTaskID, issueID)] = true
+}
+
+func rejectedKey(asanaTaskID, issueID string) string {
+	return asanaTaskID + "\x00" + issueID
+}
+
+// free reports whether a fuzzy strategy may offer the candidate for the task
+func (p *MatchPool) free(c *MatchCandidate, task AsanaTask) bool {
+	return !p.used[c.Issue.ID] && (c.owner == "" || c.owner == task.GID) &&
+		!p.rejected[rejectedKey(task.GID, c.Issue.ID)]
+}
+
+// TicketMatchers returns the enabled strategies in the order they are tried
+func TicketMatchers(cfg database.MatchingConfig) []TicketMatcher {
+	var matchers []TicketMatcher
+	if !cfg.Mapping.Disabled {
+		matchers = append(matchers, mappingMatcher{})
+	}
+	if !cfg.AsanaID.Disabled {
+		matchers = append(matchers, asanaIDMatcher{})
+	}
+	if !cfg.Title.Disabled {
+		matchers = append(matchers, titleMatcher{threshold: thresholdOr(cfg.Title.Threshold, defaultTitleThreshold)})
+	}
+	if !cfg.Similarity.Disabled {
+		matchers = append(matchers, similarityMatcher{threshold: thresholdOr(cfg.Similarity.Threshold, defaultSimilarityThreshold)})
+	}
+	if !cfg.Description.Disabled {
+		matchers = append(matchers, descriptionMatcher{threshold: thresholdOr(cfg.Description.Threshold, defaultDescriptionThreshold)})
+	}
+	return matchers
+}
+
+func thresholdOr(threshold, fallback float64) float64 {
+	if threshold <= 0 {
+		return fallback
+	}
+	return threshold
+}
+
+// MatchTasks matches the tasks strategy by strategy, so a more reliable
+// strategy always gets the first pick of issues, and marks the matched issues
+// as used
+func MatchTasks(tasks []AsanaTask, matchers []TicketMatcher, pool *MatchPool) map[string]TicketMatch {
+	matches := make(map[string]TicketMatch)
+	for _, matcher := range matchers {
+		for _, task := range tasks {
+			if _, matched := matches[task.GID]; matched {
+				continue
+			}
+			if c, confidence := matcher.Match(task, pool); c != nil {
+				matches[task.GID] = TicketMatch{Issue: c.Issue, Method: matcher.Name(), Confidence: confidence}
+				pool.Use(c.Issue.ID)
+			}
+		}
+	}
+	return matches
+}
+
+// MatchTask returns the first match the strategies find for a single task and
+// marks the issue as used
+func MatchTask(task AsanaTask, matchers []TicketMatcher, pool *MatchPool) (TicketMatch, bool) {
+	for _, matcher := range matchers {
+		if c, confidence := matcher.Match(task, pool); c != nil {
+			pool.Use(c.Issue.ID)
+			return TicketMatch{Issue: c.Issue, Method: matcher.Name(), Confidence: confidence}, true
+		}
+	}
+	return TicketMatch{}, false
+}
+
+// SuggestMatch returns the best candidate any fuzzy strategy finds for the
+// task with a score of at least minScore. The issue stays available.
+func SuggestMatch(task AsanaTask, matchers []TicketMatcher, pool *MatchPool, minScore float64) (TicketMatch, bool) {
+	var suggestion TicketMatch
+	for _, matcher := range matchers {
+		scorer, ok := matcher.(candidateScorer)
+		if !ok {
+			continue
+		}
+		if c, score := scorer.best(task, pool, minScore); c != nil && score > suggestion.Confidence {
+			suggestion = TicketMatch{Issue: c.Issue, Method: matcher.Name(), Confidence: score}
+		}
+	}
+	return suggestion, suggestion.Method != ""
+}
+
+// SuggestionThreshold returns the minimum score for match suggestions
+func SuggestionThreshold(cfg database.MatchingConfig) float64 {
+	return thresholdOr(cfg.SuggestionThreshold, defaultSuggestionThreshold)
+}
+
+// ─── Strategies ──────────────────────────────────────────────────────────────
+
+// mappingMatcher uses the ticket mappings stored in the database
+type mappingMatcher struct{}
+
+func (mappingMatcher) Name() string { return MatchMethodMapping }
+
+func (mappingMatcher) Match(task AsanaTask, pool *MatchPool) (*MatchCandidate, float64) {
+	if c := pool.byMapping[task.GID]; c != nil && !pool.Used(c.Issue.ID) {
+		return c, 1
+	}
+	return nil, 0
+}
+
+// asanaIDMatcher uses the Asana ID written into the description of issues
+// created by the sync
+type asanaIDMatcher struct{}
+
+func (asanaIDMatcher) Name() string { return MatchMethodAsanaID }
+
+func (asanaIDMatcher) Match(task AsanaTask, pool *MatchPool) (*MatchCandidate, float64) {
+	if c := pool.byAsanaID[task.GID]; c != nil && !pool.Used(c.Issue.ID) {
+		return c, 1
+	}
+	return nil, 0
+}
+
+// titleMatcher matches identical normalized titles, or titles whose words
+// mostly overlap
+type titleMatcher struct {
+	threshold float64
+}
+
+func (titleMatcher) Name() string { return MatchMethodTitle }
+
+func (m titleMatcher) Match(task AsanaTask, pool *MatchPool) (*MatchCandidate, float64) {
+	return m.best(task, pool, m.threshold)
+}
+
+func (titleMatcher) best(task AsanaTask, pool *MatchPool, minScore float64) (*MatchCandidate, float64) {
+	title := normalizeTitle(stripYouTrackPrefix(task.Name))
+	if title != "" {
+		for _, c := range pool.byTitle[title] {
+			if pool.free(c, task) {
+				return c, 1
+			}
+		}
+	}
+
+	words := strings.Fields(title)
+	var best *MatchCandidate
+	bestScore := 0.0
+	for _, c := range pool.candidates {
+		if !pool.free(c, task) {
+			continue
+		}
+		if score := wordOverlap(words, c.words); score >= minScore && score > bestScore {
+			best, bestScore = c, score
+		}
+	}
+	return best, bestScore
+}
+
+// similarityMatcher catches renamed tickets and typos: titles are compared by
+// shared character trigrams and by edit distance, whichever is closer
+type similarityMatcher struct {
+	threshold float64
+}
+
+func (similarityMatcher) Name() string { return MatchMethodSimilarity }
+
+func (m similarityMatcher) Match(task AsanaTask, pool *MatchPool) (*MatchCandidate, float64) {
+	return m.best(task, pool, m.threshold)
+}
+
+func (similarityMatcher) best(task AsanaTask, pool *MatchPool, minScore float64) (*MatchCandidate, float64) {
+	title := normalizeTitle(stripYouTrackPrefix(task.Name))
+	if title == "" {
+		return nil, 0
+	}
+	grams := trigrams(title)
+
+	var best *MatchCandidate
+	bestScore := 0.0
+	for _, c := range pool.candidates {
+		if !pool.free(c, task) || c.title == "" {
+			continue
+		}
+		if c.trigrams == nil {
+			c.trigrams = trigrams(c.title)
+		}
+		score := trigramSimilarity(grams, c.trigrams)
+		// The edit distance ratio can't exceed the length ratio, so skip it
+		// when that alone rules the pair out
+		if score < minScore && lengthRatio(title, c.title) >= minScore {
+			if ratio := levenshteinRatio(title, c.title); ratio > score {
+				score = ratio
+			}
+		}
+		if score >= minScore && score > bestScore {
+			best, bestScore = c, score
+		}
+	}
+	return best, bestScore
+}
+
+// descriptionMatcher matches tasks whose description text is (almost) fully
+// contained in an issue's description, or the other way round
+type descriptionMatcher struct {
+	threshold float64
+}
+
+func (descriptionMatcher) Name() string { return MatchMethodDescription }
+
+func (m descriptionMatcher) Match(task AsanaTask, pool *MatchPool) (*MatchCandidate, float64) {
+	return m.best(task, pool, m.threshold)
+}
+
+func (descriptionMatcher) best(task AsanaTask, pool *MatchPool, minScore float64) (*MatchCandidate, float64) {
+	fingerprint := descriptionFingerprint(task.Notes)
+	if len(fingerprint) < minFingerprintShingles {
+		return nil, 0
+	}
+
+	var best *MatchCandidate
+	bestScore := 0.0
+	for _, c := range pool.candidates {
+		if !pool.free(c, task) {
+			continue
+		}
+		if c.fingerprint == nil {
+			c.fingerprint = descriptionFingerprint(c.Issue.Description)
+		}
+		if len(c.fingerprint) < minFingerprintShingles {
+			continue
+		}
+		if score := fingerprintContainment(fingerprint, c.fingerprint); score >= minScore && score > bestScore {
+			best, bestScore = c, score
+		}
+	}
+	return best, bestScore
+}
+
+// ─── Similarity measures ─────────────────────────────────────────────────────
+
+// wordOverlap is the share of the shorter title's words found in the other one
+func wordOverlap(words1, words2 []string) float64 {
+	if len(words1) == 0 || len(words2) == 0 {
+		return 0
+	}
+	wordSet := make(map[string]bool, len(words1))
+	for _, w := range words1 {
+		wordSet[w] = true
+	}
+	overlap := 0
+	for _, w := range words2 {
+		if wordSet[w] {
+			overlap++
+		}
+	}
+	shorter := len(words1)
+	if len(words2) < shorter {
+		shorter = len(words2)
+	}
+	return float64(overlap) / float64(shorter)
+}
+
+// trigrams counts the character trigrams of a normalized title, padded so
+// that short words still produce some
+func trigrams(s string) map[string]int {
+	runes := []rune("  " + s + " ")
+	grams := make(map[string]int, len(runes))
+	for i := 0; i+3 <= len(runes); i++ {
+		grams[string(runes[i:i+3])]++
+	}
+	return grams
+}
+
+// trigramSimilarity is the Dice coefficient of two trigram multisets
+func trigramSimilarity(a, b map[string]int) float64 {
+	shared, total := 0, 0
+	for gram, n := range a {
+		total += n
+		if m := b[gram]; m > 0 {
+			shared += min(n, m)
+		}
+	}
+	for _, n := range b {
+		total += n
+	}
+	if total == 0 {
+		return 0
+	}
+	return 2 * float64(shared) / float64(total)
+}
+
+func lengthRatio(a, b string) float64 {
+	la, lb := len([]rune(a)), len([]rune(b))
+	if la == 0 || lb == 0 {
+		return 0
+	}
+	return float64(min(la, lb)) / float64(max(la, lb))
+}
+
+// levenshteinRatio is 1 minus the edit distance relative to the longer string
+func levenshteinRatio(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longer := max(len(ra), len(rb))
+	if longer == 0 {
+		return 1
+	}
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return 1 - float64(prev[len(rb)])/float64(longer)
+}
+
+// descriptionFingerprint hashes the three-word shingles of a description.
+// Normalizing first makes Asana plain text and YouTrack markdown comparable,
+// and the sync's own "Asana ID" line only adds a few shingles.
+func descriptionFingerprint(text string) map[uint64]bool {
+	words := strings.Fields(normalizeTitle(text))
+	fingerprint := make(map[uint64]bool)
+	for i := 0; i+3 <= len(words); i++ {
+		h := fnv.New64a()
+		h.Write([]byte(strings.Join(words[i:i+3], " ")))
+		fingerprint[h.Sum64()] = true
+	}
+	return fingerprint
+}
+
+// fingerprintContainment is the share of the smaller fingerprint found in the
+// larger one
+func fingerprintContainment(a, b map[uint64]bool) float64 {
+	if len(b) < len(a) {
+		a, b = b, a
+	}
+	if len(a) == 0 {
+		return 0
+	}
+	shared := 0
+	for shingle := range a {
+		if b[shingle] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a))
+}
@@ -0,0 +1,430 @@
+package legacy
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	configpkg "asana-youtrack-sync/config"
+	"asana-youtrack-sync/database"
+)
+
+// OrphanService tracks YouTrack issues whose Asana task vanished and applies
+// the sync pair's orphan policy to them once their grace period is over
+type OrphanService struct {
+	db              *database.DB
+	configService   *configpkg.Service
+	asanaService    *AsanaService
+	youtrackService *YouTrackService
+	reverseSync     *ReverseSyncService
+}
+
+// NewOrphanService creates a new orphan service
+func NewOrphanService(db *database.DB, configService *configpkg.Service) *OrphanService {
+	asanaSvc := NewAsanaService(configService)
+	youtrackSvc := NewYouTrackService(configService, asanaSvc)
+	youtrackSvc.SetTicketMappings(db)
+	return &OrphanService{
+		db:              db,
+		configService:   configService,
+		asanaService:    asanaSvc,
+		youtrackService: youtrackSvc,
+		reverseSync:     NewReverseSyncService(db, youtrackSvc, asanaSvc, configService),
+	}
+}
+
+// OrphanPolicyResult reports a run of the orphan policy
+type OrphanPolicyResult struct {
+	Action  string                    `json:"action"` // the default action; ReasonActions may override it
+	Due     int                       `json:"due"`    // open orphans past their grace period
+	Applied int                       `json:"applied"`
+	Failed  int                       `json:"failed"`
+	Orphans []*database.OrphanedIssue `json:"orphans"` // the orphans acted on, as updated
+}
+
+// linkedAsanaID is the Asana task an issue belongs to: the one it is mapped
+// to, else the one its description names
+func linkedAsanaID(issue YouTrackIssue, mappingYTToAsana map[string]string, extractAsanaID func(YouTrackIssue) string) string {
+	if asanaID, ok := mappingYTToAsana[issue.ID]; ok {
+		return asanaID
+	}
+	return extractAsanaID(issue)
+}
+
+// vanishedTaskOrphans returns the issues linked to Asana tasks that no longer
+// exist, mapped to the GID of their vanished task
+func vanishedTaskOrphans(allAsanaTasks []AsanaTask, issues []YouTrackIssue, mappingYTToAsana map[string]string, extractAsanaID func(YouTrackIssue) string) map[string]string {
+	taskExists := make(map[string]bool, len(allAsanaTasks))
+	for _, task := range allAsanaTasks {
+		taskExists[task.GID] = true
+	}
+
+	orphans := make(map[string]string)
+	for _, issue := range issues {
+		if asanaID := linkedAsanaID(issue, mappingYTToAsana, extractAsanaID); asanaID != "" && !taskExists[asanaID] {
+			orphans[issue.ID] = asanaID
+		}
+	}
+	return orphans
+}
+
+// record saves the orphans found by an analysis, with why their task
+// vanished. Failures are only logged; the analysis itself has succeeded.
+func (s *OrphanService) record(ctx context.Context, userID, syncPairID int, orphans []YouTrackIssue, vanished map[string]string) {
+	known := make(map[string]string) // issue ID -> reason already found for its task
+	if open, err := s.db.GetOrphanedIssues(userID, syncPairID, database.OrphanStatusOpen); err == nil {
+		for _, o := range open {
+			if o.Reason != database.OrphanReasonUnknown && vanished[o.YouTrackIssueID] == o.AsanaTaskID {
+				known[o.YouTrackIssueID] = o.Reason
+			}
+		}
+	}
+
+	records := []*database.OrphanedIssue{}
+	for _, issue := range orphans {
+		asanaID, ok := vanished[issue.ID]
+		if !ok {
+			continue
+		}
+		reason, ok := known[issue.ID]
+		if !ok {
+			reason = s.vanishReason(ctx, userID, asanaID)
+		}
+		records = append(records, &database.OrphanedIssue{
+			YouTrackIssueID: issue.ID,
+			YouTrackSummary: issue.Summary,
+			AsanaTaskID:     asanaID,
+			Reason:          reason,
+		})
+	}
+	if err := s.db.SaveOrphanedIssues(userID, syncPairID, records); err != nil {
+		fmt.Printf("ANALYSIS: Failed to record orphaned issues: %v\n", err)
+	}
+}
+
+// vanishReason tells why a task is no longer in the pair's project: it was
+// deleted, it only belongs to archived projects, or it was taken out of the
+// project. It is unknown if Asana can't be asked.
+func (s *OrphanService) vanishReason(ctx context.Context, userID int, asanaTaskID string) string {
+	projects, found, err := s.asanaService.GetTaskProjects(ctx, userID, asanaTaskID)
+	switch {
+	case err != nil:
+		fmt.Printf("ANALYSIS: Could not check vanished Asana task %s: %v\n", asanaTaskID, err)
+		return database.OrphanReasonUnknown
+	case !found:
+		return database.OrphanReasonDeleted
+	case len(projects) == 0:
+		return database.OrphanReasonRemoved
+	}
+	for _, project := range projects {
+		if !project.Archived {
+			return database.OrphanReasonRemoved
+		}
+	}
+	return database.OrphanReasonArchived
+}
+
+// GetOrphans lists the current sync pair's orphaned issues with the given
+// status ("" for all)
+func (s *OrphanService) GetOrphans(userID int, status string) ([]*database.OrphanedIssue, error) {
+	switch status {
+	case "", database.OrphanStatusOpen, database.OrphanStatusResolved:
+	default:
+		return nil, fmt.Errorf("invalid status: %s", status)
+	}
+
+	settings, err := s.configService.GetSettings(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user settings: %w", err)
+	}
+
+	orphans, err := s.db.GetOrphanedIssues(userID, settings.SyncPairID, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get orphaned issues: %w", err)
+	}
+	if orphans == nil {
+		orphans = []*database.OrphanedIssue{}
+	}
+	return orphans, nil
+}
+
+// ApplyPolicy applies the sync pair's orphan policy to the open orphans past
+// their grace period that haven't been acted on yet, each with the action for
+// the reason its task vanished. Orphans whose last action failed are retried.
+func (s *OrphanService) ApplyPolicy(ctx context.Context, userID int) (*OrphanPolicyResult, error) {
+	settings, err := s.configService.GetSettings(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user settings: %w", err)
+	}
+	policy := settings.OrphanPolicy
+	result := &OrphanPolicyResult{Action: policy.Action, Orphans: []*database.OrphanedIssue{}}
+	if policy.Action == database.OrphanActionNone && len(policy.ReasonActions) == 0 {
+		return result, nil
+	}
+
+	open, err := s.db.GetOrphanedIssues(userID, settings.SyncPairID, database.OrphanStatusOpen)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get orphaned issues: %w", err)
+	}
+	deadline := time.Now().Add(-time.Duration(policy.GraceHours) * time.Hour)
+	var due []*database.OrphanedIssue
+	for _, o := range open {
+		if policy.ActionFor(o.Reason) == database.OrphanActionNone || o.FirstSeenAt.After(deadline) || (o.Action != "" && o.ActionError == "") {
+			continue
+		}
+		due = append(due, o)
+	}
+	result.Due = len(due)
+	if len(due) == 0 {
+		return result, nil
+	}
+
+	board, err := s.loadBoard(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, o := range due {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		issue, stillOrphaned := board.orphan(o.YouTrackIssueID)
+		if !stillOrphaned {
+			// Linked again since the last analysis, which will resolve it
+			continue
+		}
+
+		updated, err := s.act(ctx, userID, settings, board, o, issue, policy.ActionFor(o.Reason), "")
+		if err != nil {
+			return nil, err
+		}
+		if updated.ActionError != "" {
+			result.Failed++
+		} else {
+			result.Applied++
+		}
+		result.Orphans = append(result.Orphans, updated)
+	}
+
+	fmt.Printf("ORPHANS: Applied the orphan policy to %d of %d due orphans for user %d (%d failed)\n",
+		result.Applied, result.Due, userID, result.Failed)
+	return result, nil
+}
+
+// Act takes an action on one orphaned issue now, whatever the policy and
+// grace period. asanaTaskID picks the task to relink to; without it relink
+// looks for the task that matches the issue.
+func (s *OrphanService) Act(ctx context.Context, userID, orphanID int, action, asanaTaskID string) (*database.OrphanedIssue, error) {
+	switch action {
+	case database.OrphanActionClose, database.OrphanActionTag, database.OrphanActionRelink, database.OrphanActionRecreate:
+	default:
+		return nil, fmt.Errorf("invalid action: %q", action)
+	}
+
+	settings, err := s.configService.GetSettings(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user settings: %w", err)
+	}
+	o, err := s.db.GetOrphanedIssue(userID, settings.SyncPairID, orphanID)
+	if err != nil {
+		return nil, err
+	}
+	if o.ResolvedAt != nil {
+		return nil, fmt.Errorf("orphaned issue %s is already linked to a task again", o.YouTrackIssueID)
+	}
+
+	board, err := s.loadBoard(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	issue, stillOrphaned := board.orphan(o.YouTrackIssueID)
+	if !stillOrphaned {
+		return nil, fmt.Errorf("issue %s is no longer orphaned", o.YouTrackIssueID)
+	}
+	return s.act(ctx, userID, settings, board, o, issue, action, asanaTaskID)
+}
+
+// act runs an action and records its outcome. Only failing to record it is
+// returned as an error; the action's own failure is kept on the orphan, with
+// whatever result the action got to before failing.
+func (s *OrphanService) act(ctx context.Context, userID int, settings *configpkg.UserSettings, board *orphanBoard, o *database.OrphanedIssue, issue YouTrackIssue, action, asanaTaskID string) (*database.OrphanedIssue, error) {
+	var actionResult string
+	var err error
+	switch action {
+	case database.OrphanActionClose:
+		actionResult, err = s.close(ctx, userID, settings, issue)
+	case database.OrphanActionTag:
+		actionResult, err = s.tag(ctx, userID, settings, issue)
+	case database.OrphanActionRelink:
+		actionResult, err = s.relink(ctx, userID, settings, board, o, issue, asanaTaskID)
+	case database.OrphanActionRecreate:
+		actionResult, err = s.recreate(ctx, userID, settings, o, issue)
+	}
+
+	actionError := ""
+	if err != nil {
+		actionError = err.Error()
+		fmt.Printf("ORPHANS: %s failed for %s: %v\n", action, issue.ID, err)
+	}
+	linked := err == nil && (action == database.OrphanActionRelink || action == database.OrphanActionRecreate)
+	return s.db.RecordOrphanAction(userID, settings.SyncPairID, o.ID, action, actionResult, actionError, linked)
+}
+
+// close moves the issue to the policy's close state, or the YouTrack state of
+// the workflow's first terminal column
+func (s *OrphanService) close(ctx context.Context, userID int, settings *configpkg.UserSettings, issue YouTrackIssue) (string, error) {
+	state := settings.OrphanPolicy.CloseState
+	if state == "" {
+		for _, c := range NewWorkflow(settings.ColumnMappings.AsanaToYouTrack).Columns {
+			if c.Terminal && c.YouTrackState != "" {
+				state = c.YouTrackState
+				break
+			}
+		}
+	}
+	if state == "" {
+		return "", fmt.Errorf("no close state: set one in the orphan policy or mark a column terminal")
+	}
+	if err := s.youtrackService.UpdateIssueStatus(ctx, userID, issue.ID, state); err != nil {
+		return "", err
+	}
+	return state, nil
+}
+
+func (s *OrphanService) tag(ctx context.Context, userID int, settings *configpkg.UserSettings, issue YouTrackIssue) (string, error) {
+	tag := settings.OrphanPolicy.Tag
+	if tag == "" {
+		tag = database.DefaultOrphanTag
+	}
+	if err := s.youtrackService.AddTag(ctx, userID, issue.ID, tag); err != nil {
+		return "", err
+	}
+	return tag, nil
+}
+
+// relink maps the issue to the given Asana task, or to the unlinked task the
+// pair's matching strategies pair it with
+func (s *OrphanService) relink(ctx context.Context, userID int, settings *configpkg.UserSettings, board *orphanBoard, o *database.OrphanedIssue, issue YouTrackIssue, asanaTaskID string) (string, error) {
+	if asanaTaskID == "" {
+		task, found := board.matchUnlinkedTask(issue, settings.MatchingConfig)
+		if !found {
+			return "", fmt.Errorf("no matching Asana task found")
+		}
+		asanaTaskID = task.GID
+	} else {
+		if _, err := s.asanaService.GetTaskByGID(ctx, userID, asanaTaskID); err != nil {
+			return "", fmt.Errorf("asana task %s not found: %w", asanaTaskID, err)
+		}
+		if existing, err := s.db.GetTicketMappingByAsanaID(userID, asanaTaskID); err == nil && existing.YouTrackIssueID != issue.ID {
+			return "", fmt.Errorf("asana task %s is already mapped to %s", asanaTaskID, existing.YouTrackIssueID)
+		}
+	}
+
+	if err := s.link(userID, settings, o, issue, asanaTaskID); err != nil {
+		return "", err
+	}
+	return asanaTaskID, nil
+}
+
+// recreate creates a new Asana task from the issue, as reverse sync does. If
+// linking fails the new task's GID is still returned, so it is kept on the
+// orphan and a retry links that task instead of creating another one.
+func (s *OrphanService) recreate(ctx context.Context, userID int, settings *configpkg.UserSettings, o *database.OrphanedIssue, issue YouTrackIssue) (string, error) {
+	if o.Action == database.OrphanActionRecreate && o.ActionError != "" && o.ActionResult != "" {
+		if _, err := s.asanaService.GetTaskByGID(ctx, userID, o.ActionResult); err == nil {
+			return o.ActionResult, s.link(userID, settings, o, issue, o.ActionResult)
+		}
+	}
+
+	asanaTaskID, err := s.reverseSync.CreateSingleAsanaTicket(ctx, userID, issue, settings)
+	if err != nil {
+		return "", err
+	}
+	s.asanaService.InvalidateCache(userID)
+	return asanaTaskID, s.link(userID, settings, o, issue, asanaTaskID)
+}
+
+// link maps the issue to its new task and drops the vanished task's mapping
+func (s *OrphanService) link(userID int, settings *configpkg.UserSettings, o *database.OrphanedIssue, issue YouTrackIssue, asanaTaskID string) error {
+	if old, err := s.db.GetTicketMappingByAsanaID(userID, o.AsanaTaskID); err == nil && old.YouTrackIssueID == issue.ID {
+		if err := s.db.DeleteTicketMapping(userID, old.ID); err != nil {
+			fmt.Printf("ORPHANS: Failed to drop mapping of vanished task %s: %v\n", o.AsanaTaskID, err)
+		}
+	}
+	if _, err := s.db.CreateTicketMapping(userID, settings.AsanaProjectID, asanaTaskID, settings.YouTrackProjectID, issue.ID); err != nil {
+		return fmt.Errorf("failed to create mapping: %w", err)
+	}
+	fmt.Printf("ORPHANS: Linked %s to Asana task %s (was %s)\n", issue.ID, asanaTaskID, o.AsanaTaskID)
+	return nil
+}
+
+// orphanBoard is a snapshot of both sides, to check that orphans are still
+// orphaned before acting on them
+type orphanBoard struct {
+	tasks            []AsanaTask
+	issues           map[string]YouTrackIssue
+	vanished         map[string]string // orphaned issue ID -> vanished task GID
+	linkedTasks      map[string]bool   // task GIDs some issue is linked to
+	mappingYTToAsana map[string]string
+}
+
+func (s *OrphanService) loadBoard(ctx context.Context, userID int) (*orphanBoard, error) {
+	tasks, err := s.asanaService.GetTasks(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Asana tasks: %w", err)
+	}
+	issues, err := s.youtrackService.GetIssues(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get YouTrack issues: %w", err)
+	}
+
+	mappings, _ := s.db.GetAllTicketMappings(userID)
+	board := &orphanBoard{
+		tasks:            tasks,
+		issues:           make(map[string]YouTrackIssue, len(issues)),
+		linkedTasks:      make(map[string]bool),
+		mappingYTToAsana: make(map[string]string, len(mappings)),
+	}
+	for _, m := range mappings {
+		board.mappingYTToAsana[m.YouTrackIssueID] = m.AsanaTaskID
+		board.linkedTasks[m.AsanaTaskID] = true
+	}
+	for _, issue := range issues {
+		board.issues[issue.ID] = issue
+		if asanaID := linkedAsanaID(issue, board.mappingYTToAsana, s.youtrackService.ExtractAsanaID); asanaID != "" {
+			board.linkedTasks[asanaID] = true
+		}
+	}
+	board.vanished = vanishedTaskOrphans(tasks, issues, board.mappingYTToAsana, s.youtrackService.ExtractAsanaID)
+	return board, nil
+}
+
+// orphan returns the issue if it still exists and is still orphaned
+func (b *orphanBoard) orphan(issueID string) (YouTrackIssue, bool) {
+	issue, exists := b.issues[issueID]
+	if !exists {
+		return YouTrackIssue{}, false
+	}
+	_, orphaned := b.vanished[issueID]
+	return issue, orphaned
+}
+
+// matchUnlinkedTask runs the fuzzy matching strategies between the issue and
+// the tasks no issue is linked to. The issue's own link to its vanished task
+// is left out so it doesn't hold the issue back.
+func (b *orphanBoard) matchUnlinkedTask(issue YouTrackIssue, cfg database.MatchingConfig) (AsanaTask, bool) {
+	var unlinked []AsanaTask
+	for _, task := range b.tasks {
+		if !b.linkedTasks[task.GID] {
+			unlinked = append(unlinked, task)
+		}
+	}
+
+	pool := NewMatchPool([]YouTrackIssue{issue}, nil, func(YouTrackIssue) string { return "" })
+	matches := MatchTasks(unlinked, TicketMatchers(cfg), pool)
+	for _, task := range unlinked {
+		if m, found := matches[task.GID]; found && m.Issue.ID == issue.ID {
+			return task, true
+		}
+	}
+	return AsanaTask{}, false
+}
@@ -1,6 +1,7 @@
 package legacy
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"strings"
@@ -28,7 +29,7 @@ func NewReverseAnalysisService(db *database.DB, youtrackService *YouTrackService
 }
 
 // PerformReverseAnalysis analyzes YouTrack tickets and categorizes them as Matched or Missing in Asana
-func (s *ReverseAnalysisService) PerformReverseAnalysis(userID int, creatorFilter string) (*ReverseTicketAnalysis, error) {
+func (s *ReverseAnalysisService) PerformReverseAnalysis(ctx context.Context, userID int, creatorFilter string) (*ReverseTicketAnalysis, error) {
 	log.Printf("[Reverse Analysis] Starting analysis for userID: %d, creator filter: %s", userID, creatorFilter)
 
 	analysis := &ReverseTicketAnalysis{
@@ -42,7 +43,7 @@ func (s *ReverseAnalysisService) PerformReverseAnalysis(userID int, creatorFilte
 	}
 
 	// 1. Fetch YouTrack issues with creator filter
-	ytIssues, err := s.youtrackService.GetIssuesByCreator(userID, creatorFilter)
+	ytIssues, err := s.youtrackService.GetIssuesByCreator(ctx, userID, creatorFilter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch YouTrack issues: %w", err)
 	}
@@ -77,7 +78,7 @@ func (s *ReverseAnalysisService) PerformReverseAnalysis(userID int, creatorFilte
 	log.Printf("[Reverse Analysis] Loaded %d existing mappings from database", len(mappings))
 
 	// 3. Fetch all Asana tasks to verify existence and check titles
-	asanaTasks, err := s.asanaService.GetAllTasks(userID, settings.AsanaProjectID)
+	asanaTasks, err := s.asanaService.GetAllTasks(ctx, userID, settings.AsanaProjectID)
 	if err != nil {
 		log.Printf("[Reverse Analysis] Warning: Failed to fetch Asana tasks: %v", err)
 		asanaTasks = []AsanaTask{}
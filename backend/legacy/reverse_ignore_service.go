@@ -27,7 +27,7 @@ func (s *ReverseIgnoreService) IsIgnored(userID int, ticketID string) bool {
 		return false
 	}
 
-	isIgnored, _ := s.db.IsReverseTicketIgnored(userID, settings.YouTrackProjectID, ticketID)
+	isIgnored, _ := s.db.IsReverseTicketIgnored(userID, settings.SyncPairID, ticketID)
 	return isIgnored
 }
 
@@ -38,7 +38,7 @@ func (s *ReverseIgnoreService) IsTemporarilyIgnored(userID int, ticketID string)
 		return false
 	}
 
-	isIgnored, ignoreType := s.db.IsReverseTicketIgnored(userID, settings.YouTrackProjectID, ticketID)
+	isIgnored, ignoreType := s.db.IsReverseTicketIgnored(userID, settings.SyncPairID, ticketID)
 	return isIgnored && ignoreType == "temp"
 }
 
@@ -49,7 +49,7 @@ func (s *ReverseIgnoreService) IsForeverIgnored(userID int, ticketID string) boo
 		return false
 	}
 
-	isIgnored, ignoreType := s.db.IsReverseTicketIgnored(userID, settings.YouTrackProjectID, ticketID)
+	isIgnored, ignoreType := s.db.IsReverseTicketIgnored(userID, settings.SyncPairID, ticketID)
 	return isIgnored && ignoreType == "forever"
 }
 
@@ -64,7 +64,7 @@ func (s *ReverseIgnoreService) AddTemporaryIgnore(userID int, ticketID string) e
 		return fmt.Errorf("no YouTrack project configured")
 	}
 
-	_, err = s.db.AddReverseIgnoredTicket(userID, settings.YouTrackProjectID, ticketID, "temp")
+	_, err = s.db.AddReverseIgnoredTicket(userID, settings.SyncPairID, settings.YouTrackProjectID, ticketID, "temp")
 	return err
 }
 
@@ -79,7 +79,7 @@ func (s *ReverseIgnoreService) AddForeverIgnore(userID int, ticketID string) err
 		return fmt.Errorf("no YouTrack project configured")
 	}
 
-	_, err = s.db.AddReverseIgnoredTicket(userID, settings.YouTrackProjectID, ticketID, "forever")
+	_, err = s.db.AddReverseIgnoredTicket(userID, settings.SyncPairID, settings.YouTrackProjectID, ticketID, "forever")
 	return err
 }
 
@@ -94,7 +94,7 @@ func (s *ReverseIgnoreService) RemoveTemporaryIgnore(userID int, ticketID string
 		return fmt.Errorf("no YouTrack project configured")
 	}
 
-	return s.db.RemoveReverseIgnoredTicket(userID, settings.YouTrackProjectID, ticketID, "temp")
+	return s.db.RemoveReverseIgnoredTicket(userID, settings.SyncPairID, ticketID, "temp")
 }
 
 // RemoveForeverIgnore removes a ticket from permanent ignore list
@@ -108,7 +108,7 @@ func (s *ReverseIgnoreService) RemoveForeverIgnore(userID int, ticketID string)
 		return fmt.Errorf("no YouTrack project configured")
 	}
 
-	return s.db.RemoveReverseIgnoredTicket(userID, settings.YouTrackProjectID, ticketID, "forever")
+	return s.db.RemoveReverseIgnoredTicket(userID, settings.SyncPairID, ticketID, "forever")
 }
 
 // GetTemporarilyIgnored returns all temporarily ignored ticket IDs for user's current YouTrack project
@@ -118,7 +118,7 @@ func (s *ReverseIgnoreService) GetTemporarilyIgnored(userID int) []string {
 		return []string{}
 	}
 
-	ignoredTickets, err := s.db.GetReverseIgnoredTickets(userID, settings.YouTrackProjectID)
+	ignoredTickets, err := s.db.GetReverseIgnoredTickets(userID, settings.SyncPairID)
 	if err != nil {
 		return []string{}
 	}
@@ -140,7 +140,7 @@ func (s *ReverseIgnoreService) GetForeverIgnored(userID int) []string {
 		return []string{}
 	}
 
-	ignoredTickets, err := s.db.GetReverseIgnoredTickets(userID, settings.YouTrackProjectID)
+	ignoredTickets, err := s.db.GetReverseIgnoredTickets(userID, settings.SyncPairID)
 	if err != nil {
 		return []string{}
 	}
@@ -162,7 +162,7 @@ func (s *ReverseIgnoreService) GetIgnoredTickets(userID int) []string {
 		return []string{}
 	}
 
-	ignoredTickets, err := s.db.GetReverseIgnoredTickets(userID, settings.YouTrackProjectID)
+	ignoredTickets, err := s.db.GetReverseIgnoredTickets(userID, settings.SyncPairID)
 	if err != nil {
 		return []string{}
 	}
@@ -186,7 +186,7 @@ func (s *ReverseIgnoreService) ClearTemporaryIgnores(userID int) error {
 		return fmt.Errorf("no YouTrack project configured")
 	}
 
-	return s.db.ClearReverseIgnoredTickets(userID, settings.YouTrackProjectID, "temp")
+	return s.db.ClearReverseIgnoredTickets(userID, settings.SyncPairID, "temp")
 }
 
 // ClearForeverIgnores clears all permanent ignores for user's current YouTrack project
@@ -200,7 +200,7 @@ func (s *ReverseIgnoreService) ClearForeverIgnores(userID int) error {
 		return fmt.Errorf("no YouTrack project configured")
 	}
 
-	return s.db.ClearReverseIgnoredTickets(userID, settings.YouTrackProjectID, "forever")
+	return s.db.ClearReverseIgnoredTickets(userID, settings.SyncPairID, "forever")
 }
 
 // ClearAllIgnores clears all ignores (temporary and permanent) for user's current YouTrack project
@@ -214,7 +214,7 @@ func (s *ReverseIgnoreService) ClearAllIgnores(userID int) error {
 		return fmt.Errorf("no YouTrack project configured")
 	}
 
-	return s.db.ClearReverseIgnoredTickets(userID, settings.YouTrackProjectID, "")
+	return s.db.ClearReverseIgnoredTickets(userID, settings.SyncPairID, "")
 }
 
 // GetIgnoreStatus returns the ignore status for user's current YouTrack project
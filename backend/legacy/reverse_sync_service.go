@@ -1,14 +1,15 @@
 package legacy
 
 import (
+	"context"
 	"fmt"
 	"log"
-	"regexp"
 	"strings"
 	"time"
 
 	configpkg "asana-youtrack-sync/config"
 	"asana-youtrack-sync/database"
+	"asana-youtrack-sync/utils"
 )
 
 type ReverseSyncService struct {
@@ -38,17 +39,17 @@ func NewReverseSyncService(db *database.DB, youtrackService *YouTrackService, as
 }
 
 // GetYouTrackUsers fetches all users from YouTrack for the dropdown
-func (s *ReverseSyncService) GetYouTrackUsers(userID int) ([]YouTrackUser, error) {
-	return s.youtrackService.GetAllUsers(userID)
+func (s *ReverseSyncService) GetYouTrackUsers(ctx context.Context, userID int) ([]YouTrackUser, error) {
+	return s.youtrackService.GetAllUsers(ctx, userID)
 }
 
 // PerformReverseAnalysis analyzes tickets created by specific user(s)
-func (s *ReverseSyncService) PerformReverseAnalysis(userID int, creatorFilter string) (*ReverseTicketAnalysis, error) {
-	return s.analysisService.PerformReverseAnalysis(userID, creatorFilter)
+func (s *ReverseSyncService) PerformReverseAnalysis(ctx context.Context, userID int, creatorFilter string) (*ReverseTicketAnalysis, error) {
+	return s.analysisService.PerformReverseAnalysis(ctx, userID, creatorFilter)
 }
 
 // CreateMissingAsanaTickets creates all missing tickets from YouTrack to Asana
-func (s *ReverseSyncService) CreateMissingAsanaTickets(userID int, analysis *ReverseTicketAnalysis) (*ReverseSyncResult, error) {
+func (s *ReverseSyncService) CreateMissingAsanaTickets(ctx context.Context, userID int, analysis *ReverseTicketAnalysis) (*ReverseSyncResult, error) {
 	result := &ReverseSyncResult{
 		TotalTickets:    len(analysis.MissingAsana),
 		SuccessCount:    0,
@@ -63,10 +64,13 @@ func (s *ReverseSyncService) CreateMissingAsanaTickets(userID int, analysis *Rev
 	}
 
 	for i, ytIssue := range analysis.MissingAsana {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		log.Printf("[Reverse Sync] Creating Asana task %d/%d: %s", i+1, len(analysis.MissingAsana), ytIssue.ID)
 
 		// Create the ticket in Asana
-		asanaTaskID, err := s.CreateSingleAsanaTicket(userID, ytIssue, settings)
+		asanaTaskID, err := s.CreateSingleAsanaTicket(ctx, userID, ytIssue, settings)
 		if err != nil {
 			log.Printf("[Reverse Sync] Failed to create task for %s: %v", ytIssue.ID, err)
 			result.FailedCount++
@@ -100,9 +104,9 @@ func (s *ReverseSyncService) CreateMissingAsanaTickets(userID int, analysis *Rev
 }
 
 // CreateSingleAsanaTicket creates a single ticket in Asana from YouTrack issue
-func (s *ReverseSyncService) CreateSingleAsanaTicket(userID int, ytIssue YouTrackIssue, settings *configpkg.UserSettings) (string, error) {
+func (s *ReverseSyncService) CreateSingleAsanaTicket(ctx context.Context, userID int, ytIssue YouTrackIssue, settings *configpkg.UserSettings) (string, error) {
 	// 1. Get the Asana section (column) based on YouTrack state
-	asanaSection, err := s.mapYouTrackStateToAsanaSection(userID, ytIssue.State, settings)
+	asanaSection, err := s.mapYouTrackStateToAsanaSection(ctx, userID, ytIssue.State, ytIssue.StateID, settings)
 	if err != nil {
 		return "", fmt.Errorf("failed to map state: %w", err)
 	}
@@ -110,55 +114,10 @@ func (s *ReverseSyncService) CreateSingleAsanaTicket(userID int, ytIssue YouTrac
 	// 2. Keep the YouTrack title format with ID prefix (e.g., "ARD-123 Fix bug")
 	taskTitle := fmt.Sprintf("%s %s", ytIssue.ID, ytIssue.Summary)
 
-	// 3. Clean up description - remove ALL markdown formatting
-	plainDescription := ytIssue.Description
-
-	// Remove inline image markdown patterns:
-	// ![alt text](image.png)
-	// ![alt text](image.png){width=70%}
-	// ![](image.png){width=70%}
-	plainDescription = regexp.MustCompile(`!\[[^\]]*\]\([^)]+\)(?:\{[^}]*\})?`).ReplaceAllString(plainDescription, "")
-
-	// Also remove any leftover patterns like ".png){width=70%}" that might remain
-	plainDescription = regexp.MustCompile(`\.[a-zA-Z]{3,4}\)\{[^}]+\}`).ReplaceAllString(plainDescription, "")
-
-	// Remove code blocks: ```code``` -> code
-	plainDescription = regexp.MustCompile("```[\\s\\S]*?```").ReplaceAllStringFunc(plainDescription, func(match string) string {
-		// Extract content between ``` markers
-		content := strings.TrimPrefix(match, "```")
-		content = strings.TrimSuffix(content, "```")
-		// Remove language identifier if present (e.g., ```javascript)
-		lines := strings.Split(content, "\n")
-		if len(lines) > 0 && !strings.Contains(lines[0], " ") {
-			lines = lines[1:] // Skip first line if it's a language identifier
-		}
-		return strings.TrimSpace(strings.Join(lines, "\n"))
-	})
-
-	// Remove inline code: `code` -> code
-	plainDescription = regexp.MustCompile("`([^`]+)`").ReplaceAllString(plainDescription, "$1")
-
-	// Remove blockquotes: > quote -> quote
-	plainDescription = regexp.MustCompile(`(?m)^>\s*(.*)$`).ReplaceAllString(plainDescription, "$1")
-
-	// Remove bold formatting: **text** -> text
-	plainDescription = regexp.MustCompile(`\*\*([^\*]+)\*\*`).ReplaceAllString(plainDescription, "$1")
-
-	// Remove italic formatting: *text* -> text
-	plainDescription = regexp.MustCompile(`\*([^\*\n]+)\*`).ReplaceAllString(plainDescription, "$1")
-
-	// Remove strikethrough: ~~text~~ -> text
-	plainDescription = regexp.MustCompile(`~~([^~]+)~~`).ReplaceAllString(plainDescription, "$1")
-
-	// Remove underline: _text_ -> text
-	plainDescription = regexp.MustCompile(`_([^_\n]+)_`).ReplaceAllString(plainDescription, "$1")
-
-	// Remove links but keep the text: [text](url) -> text
-	plainDescription = regexp.MustCompile(`\[([^\]]+)\]\([^\)]+\)`).ReplaceAllString(plainDescription, "$1")
-
-	plainDescription = strings.TrimSpace(plainDescription)
-
-	log.Printf("[Reverse Sync] Using plain description for %s: %s", ytIssue.ID, plainDescription)
+	// 3. Convert the markdown description to Asana rich text, turning @login
+	// mentions and mapped issue IDs into Asana mentions and task links
+	links := descriptionLinks(ctx, userID, s.db, s.youtrackService, s.asanaService)
+	htmlDescription := utils.ConvertYouTrackMarkdownToAsanaHTMLWithLinks(ytIssue.Description, links)
 
 	// 4. Map YouTrack subsystem to Asana tags
 	asanaTags, err := s.mapSubsystemToAsanaTags(userID, ytIssue.Subsystem, settings)
@@ -167,12 +126,27 @@ func (s *ReverseSyncService) CreateSingleAsanaTicket(userID int, ytIssue YouTrac
 		asanaTags = []string{} // Skip tags if mapping fails
 	}
 
-	// 5. Create the task in Asana with plain text (HTML doesn't work with Asana API)
+	// 5. Create the task in Asana with the rich text description
 	taskData := map[string]interface{}{
 		"name":     taskTitle,
-		"notes":    plainDescription,
 		"projects": []string{settings.AsanaProjectID},
 	}
+	if htmlDescription != "" {
+		taskData["html_notes"] = htmlDescription
+	}
+	if settings.CompletionConfig.IsResolved(ytIssue.State) {
+		taskData["completed"] = true
+	}
+
+	// Assign the task to the Asana user linked to the issue's assignee
+	identities := loadIdentities(s.db, userID)
+	for _, assignee := range s.youtrackService.GetAssignees(ytIssue) {
+		if identity := identities.asanaFor(assignee); identity != nil {
+			taskData["assignee"] = identity.AsanaUserGID
+			break
+		}
+		log.Printf("[Reverse Sync] Assignee %s of %s is not linked to an Asana user", assignee.FullName, ytIssue.ID)
+	}
 
 	// Add section/column
 	if asanaSection != "" {
@@ -184,14 +158,21 @@ func (s *ReverseSyncService) CreateSingleAsanaTicket(userID int, ytIssue YouTrac
 		}
 	}
 
-	asanaTaskID, err := s.asanaService.CreateTask(userID, taskData)
+	asanaTaskID, err := s.asanaService.CreateTask(ctx, userID, taskData)
+	if err != nil && htmlDescription != "" {
+		// Asana rejects rich text it can't parse; fall back to plain text
+		log.Printf("[Reverse Sync] Rich text rejected for %s, retrying with plain text: %v", ytIssue.ID, err)
+		delete(taskData, "html_notes")
+		taskData["notes"] = utils.ConvertYouTrackMarkdownToPlainText(ytIssue.Description)
+		asanaTaskID, err = s.asanaService.CreateTask(ctx, userID, taskData)
+	}
 	if err != nil {
 		return "", fmt.Errorf("failed to create Asana task: %w", err)
 	}
 
 	// 6. Add tags to the created task
 	for _, tagName := range asanaTags {
-		err := s.asanaService.AddTagToTask(userID, asanaTaskID, tagName)
+		err := s.asanaService.AddTagToTask(ctx, userID, asanaTaskID, tagName)
 		if err != nil {
 			log.Printf("[Reverse Sync] Warning: Failed to add tag '%s' to task %s: %v", tagName, asanaTaskID, err)
 		}
@@ -200,7 +181,7 @@ func (s *ReverseSyncService) CreateSingleAsanaTicket(userID int, ytIssue YouTrac
 	// 7. Sync attachments from YouTrack to Asana
 	if len(ytIssue.Attachments) > 0 {
 		log.Printf("[Reverse Sync] Syncing %d attachments for %s", len(ytIssue.Attachments), ytIssue.ID)
-		err := s.syncAttachmentsToAsana(userID, ytIssue.ID, asanaTaskID, ytIssue.Attachments)
+		err := s.syncAttachmentsToAsana(ctx, userID, ytIssue.ID, asanaTaskID, ytIssue.Attachments)
 		if err != nil {
 			log.Printf("[Reverse Sync] Warning: Failed to sync attachments: %v", err)
 		}
@@ -209,45 +190,85 @@ func (s *ReverseSyncService) CreateSingleAsanaTicket(userID int, ytIssue YouTrac
 	return asanaTaskID, nil
 }
 
-// mapYouTrackStateToAsanaSection maps YouTrack state to Asana section using reverse column mappings
-func (s *ReverseSyncService) mapYouTrackStateToAsanaSection(userID int, ytState string, settings *configpkg.UserSettings) (string, error) {
-	// First, look for priority mappings
-	var priorityMapping *database.ColumnMapping
-	var fallbackMappings []database.ColumnMapping
-
-	for _, mapping := range settings.ColumnMappings.AsanaToYouTrack {
-		if strings.EqualFold(mapping.YouTrackStatus, ytState) {
-			if mapping.ReverseSyncPriority {
-				priorityMapping = &mapping
-				break // Use the first priority mapping found
-			}
-			fallbackMappings = append(fallbackMappings, mapping)
-		}
-	}
-
-	// Use priority mapping if exists, otherwise use first fallback
+// mapYouTrackStateToAsanaSection maps YouTrack state to Asana section GID. An
+// explicit YouTrack-to-Asana mapping table wins, falling back to its default
+// section; without one the Asana-to-YouTrack mappings are inverted.
+func (s *ReverseSyncService) mapYouTrackStateToAsanaSection(ctx context.Context, userID int, ytState, ytStateID string, settings *configpkg.UserSettings) (string, error) {
 	var selectedMapping *database.ColumnMapping
-	if priorityMapping != nil {
-		selectedMapping = priorityMapping
-	} else if len(fallbackMappings) > 0 {
-		selectedMapping = &fallbackMappings[0]
+	if len(settings.ColumnMappings.YouTrackToAsana) > 0 {
+		selectedMapping = reverseColumnMapping(settings.ColumnMappings.YouTrackToAsana, ytState, ytStateID)
 	} else {
+		selectedMapping = invertedColumnMapping(settings.ColumnMappings.AsanaToYouTrack, ytState, ytStateID)
+	}
+	if selectedMapping == nil {
 		return "", fmt.Errorf("no mapping found for YouTrack state: %s", ytState)
 	}
 
-	// Find the Asana section ID by name
-	sections, err := s.asanaService.GetProjectSections(userID, settings.AsanaProjectID)
+	return s.asanaSectionGID(ctx, userID, selectedMapping, settings)
+}
+
+// asanaSectionGID returns the section of a mapping: its pinned GID, or for
+// mappings saved before GIDs were pinned, the section with the mapped name
+func (s *ReverseSyncService) asanaSectionGID(ctx context.Context, userID int, mapping *database.ColumnMapping, settings *configpkg.UserSettings) (string, error) {
+	if mapping.AsanaSectionGID != "" {
+		return mapping.AsanaSectionGID, nil
+	}
+
+	sections, err := s.asanaService.GetProjectSections(ctx, userID, settings.AsanaProjectID)
 	if err != nil {
 		return "", fmt.Errorf("failed to get Asana sections: %w", err)
 	}
-
 	for _, section := range sections {
-		if strings.EqualFold(section.Name, selectedMapping.AsanaColumn) {
+		if strings.EqualFold(strings.TrimSpace(section.Name), strings.TrimSpace(mapping.AsanaColumn)) {
 			return section.GID, nil
 		}
 	}
+	return "", fmt.Errorf("Asana section not found: %s", mapping.AsanaColumn)
+}
+
+// mappingMatchesState reports whether a mapping is for the given YouTrack
+// state, comparing IDs where both sides have one
+func mappingMatchesState(mapping database.ColumnMapping, ytState, ytStateID string) bool {
+	if mapping.YouTrackStateID != "" && ytStateID != "" {
+		return mapping.YouTrackStateID == ytStateID
+	}
+	return strings.EqualFold(strings.TrimSpace(mapping.YouTrackStatus), strings.TrimSpace(ytState))
+}
+
+// reverseColumnMapping picks the YouTrack-to-Asana mapping of a state, or the
+// default one (AnyYouTrackState) if the state has none
+func reverseColumnMapping(mappings []database.ColumnMapping, ytState, ytStateID string) *database.ColumnMapping {
+	var fallback *database.ColumnMapping
+	for i, mapping := range mappings {
+		if strings.TrimSpace(mapping.YouTrackStatus) == database.AnyYouTrackState {
+			if fallback == nil {
+				fallback = &mappings[i]
+			}
+			continue
+		}
+		if mappingMatchesState(mapping, ytState, ytStateID) {
+			return &mappings[i]
+		}
+	}
+	return fallback
+}
 
-	return "", fmt.Errorf("Asana section not found: %s", selectedMapping.AsanaColumn)
+// invertedColumnMapping picks the Asana-to-YouTrack mapping of a state,
+// preferring one marked ReverseSyncPriority when several columns share it
+func invertedColumnMapping(mappings []database.ColumnMapping, ytState, ytStateID string) *database.ColumnMapping {
+	var fallback *database.ColumnMapping
+	for i, mapping := range mappings {
+		if !mappingMatchesState(mapping, ytState, ytStateID) {
+			continue
+		}
+		if mapping.ReverseSyncPriority {
+			return &mappings[i]
+		}
+		if fallback == nil {
+			fallback = &mappings[i]
+		}
+	}
+	return fallback
 }
 
 // mapSubsystemToAsanaTags maps YouTrack subsystem to Asana tags using reverse tag mappings
@@ -271,20 +292,20 @@ func (s *ReverseSyncService) mapSubsystemToAsanaTags(userID int, subsystem strin
 }
 
 // syncAttachmentsToAsana downloads attachments from YouTrack and uploads them to Asana
-func (s *ReverseSyncService) syncAttachmentsToAsana(userID int, ytIssueID, asanaTaskID string, attachments []YouTrackAttachment) error {
+func (s *ReverseSyncService) syncAttachmentsToAsana(ctx context.Context, userID int, ytIssueID, asanaTaskID string, attachments []YouTrackAttachment) error {
 	successCount := 0
 	for i, attachment := range attachments {
 		log.Printf("[Reverse Sync] Processing attachment %d/%d: %s", i+1, len(attachments), attachment.Name)
 
 		// Download from YouTrack using the URL from the API response
-		fileData, err := s.youtrackService.DownloadAttachment(userID, ytIssueID, attachment.URL)
+		fileData, err := s.youtrackService.DownloadAttachment(ctx, userID, ytIssueID, attachment.URL)
 		if err != nil {
 			log.Printf("[Reverse Sync] Failed to download attachment %s: %v", attachment.Name, err)
 			continue
 		}
 
 		// Upload to Asana
-		err = s.asanaService.UploadAttachment(userID, asanaTaskID, attachment.Name, fileData)
+		err = s.asanaService.UploadAttachment(ctx, userID, asanaTaskID, attachment.Name, fileData)
 		if err != nil {
 			log.Printf("[Reverse Sync] Failed to upload attachment %s to Asana: %v", attachment.Name, err)
 			continue
@@ -305,7 +326,7 @@ func (s *ReverseSyncService) syncAttachmentsToAsana(userID int, ytIssueID, asana
 }
 
 // CreateSelectedAsanaTickets creates only selected tickets from the analysis
-func (s *ReverseSyncService) CreateSelectedAsanaTickets(userID int, selectedIssueIDs []string, analysis *ReverseTicketAnalysis) (*ReverseSyncResult, error) {
+func (s *ReverseSyncService) CreateSelectedAsanaTickets(ctx context.Context, userID int, selectedIssueIDs []string, analysis *ReverseTicketAnalysis) (*ReverseSyncResult, error) {
 	result := &ReverseSyncResult{
 		TotalTickets:    len(selectedIssueIDs),
 		SuccessCount:    0,
@@ -326,6 +347,9 @@ func (s *ReverseSyncService) CreateSelectedAsanaTickets(userID int, selectedIssu
 	}
 
 	for i, issueID := range selectedIssueIDs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		ytIssue, exists := issueMap[issueID]
 		if !exists {
 			log.Printf("[Reverse Sync] Issue not found in analysis: %s", issueID)
@@ -340,7 +364,7 @@ func (s *ReverseSyncService) CreateSelectedAsanaTickets(userID int, selectedIssu
 
 		log.Printf("[Reverse Sync] Creating selected task %d/%d: %s", i+1, len(selectedIssueIDs), ytIssue.ID)
 
-		asanaTaskID, err := s.CreateSingleAsanaTicket(userID, ytIssue, settings)
+		asanaTaskID, err := s.CreateSingleAsanaTicket(ctx, userID, ytIssue, settings)
 		if err != nil {
 			log.Printf("[Reverse Sync] Failed to create task for %s: %v", ytIssue.ID, err)
 			result.FailedCount++
@@ -0,0 +1,277 @@
+package legacy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	configpkg "asana-youtrack-sync/config"
+	"asana-youtrack-sync/database"
+)
+
+// storyPointsField is the name of YouTrack's story points field
+const storyPointsField = "Story points"
+
+// agileSprint is a sprint of a YouTrack agile board
+type agileSprint struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Archived bool   `json:"archived"`
+	Start    int64  `json:"start"`
+	Finish   int64  `json:"finish"`
+	Issues   []struct {
+		ID         string `json:"id"`
+		IDReadable string `json:"idReadable"`
+	} `json:"issues"`
+}
+
+// has reports whether the issue is in the sprint
+func (sp *agileSprint) has(issueID string) bool {
+	for _, issue := range sp.Issues {
+		if issue.ID == issueID || issue.IDReadable == issueID {
+			return true
+		}
+	}
+	return false
+}
+
+// agileBoard is a YouTrack agile board with its sprints
+type agileBoard struct {
+	ID            string `json:"id"`
+	Name          string `json:"name"`
+	CurrentSprint *struct {
+		ID string `json:"id"`
+	} `json:"currentSprint"`
+	Sprints []agileSprint `json:"sprints"`
+}
+
+// sprint returns the unarchived sprint with the given name, or the current
+// sprint for database.SprintCurrent. Without a current sprint set on the
+// board, it is the one whose dates include today.
+func (b *agileBoard) sprint(name string) *agileSprint {
+	if name == database.SprintCurrent {
+		now := time.Now().UnixMilli()
+		var dated *agileSprint
+		for i := range b.Sprints {
+			sp := &b.Sprints[i]
+			if b.CurrentSprint != nil && sp.ID == b.CurrentSprint.ID {
+				return sp
+			}
+			if !sp.Archived && dated == nil && sp.Start <= now && now < sp.Finish {
+				dated = sp
+			}
+		}
+		return dated
+	}
+	for i := range b.Sprints {
+		if !b.Sprints[i].Archived && strings.EqualFold(strings.TrimSpace(b.Sprints[i].Name), strings.TrimSpace(name)) {
+			return &b.Sprints[i]
+		}
+	}
+	return nil
+}
+
+// issueSprints returns the names of the unarchived sprints the issue is in
+func (b *agileBoard) issueSprints(issueID string) []string {
+	names := []string{}
+	for i := range b.Sprints {
+		if !b.Sprints[i].Archived && b.Sprints[i].has(issueID) {
+			names = append(names, b.Sprints[i].Name)
+		}
+	}
+	return names
+}
+
+// getAgileBoard loads the configured agile board and its sprints, with the
+// issues in each sprint if withIssues is set
+func (s *YouTrackService) getAgileBoard(ctx context.Context, settings *configpkg.UserSettings, withIssues bool) (*agileBoard, error) {
+	sprintFields := "id,name,archived,start,finish"
+	if withIssues {
+		sprintFields += ",issues(id,idReadable)"
+	}
+	url := fmt.Sprintf("%s/api/agiles/%s?fields=id,name,currentSprint(id),sprints(%s)",
+		settings.YouTrackBaseURL, settings.YouTrackBoardID, sprintFields)
+
+	var board agileBoard
+	if err := s.doJSON(ctx, settings, "GET", url, nil, &board); err != nil {
+		return nil, fmt.Errorf("failed to get agile board: %w", err)
+	}
+	if board.Name == "" {
+		return nil, fmt.Errorf("could not get agile board name")
+	}
+	return &board, nil
+}
+
+// syncBoard loads the agile board once for a bulk sync, with the sprints'
+// issues when tasks can map to sprints. Returns nil when updates don't touch
+// the board, or if it can't be loaded, so each update fetches it itself.
+func (s *YouTrackService) syncBoard(ctx context.Context, settings *configpkg.UserSettings) *agileBoard {
+	if settings == nil || settings.YouTrackBoardID == "" {
+		return nil
+	}
+	if !settings.SyncBoardMembership && len(settings.SprintConfig.Sprints) == 0 {
+		return nil
+	}
+	board, err := s.getAgileBoard(ctx, settings, len(settings.SprintConfig.Sprints) > 0)
+	if err != nil {
+		fmt.Printf("SYNC: Could not fetch agile board: %v\n", err)
+		return nil
+	}
+	return board
+}
+
+// moveIssueToSprint takes the issue out of the board's other unarchived
+// sprints and adds it to the target sprint
+func (s *YouTrackService) moveIssueToSprint(ctx context.Context, settings *configpkg.UserSettings, board *agileBoard, issueID string, target *agileSprint) error {
+	for i := range board.Sprints {
+		sp := &board.Sprints[i]
+		if sp.ID == target.ID || sp.Archived || !sp.has(issueID) {
+			continue
+		}
+		payload := map[string]interface{}{
+			"query":  fmt.Sprintf("remove Board %s %s", commandName(board.Name), commandName(sp.Name)),
+			"issues": []map[string]interface{}{{"idReadable": issueID}},
+		}
+		if err := s.doJSON(ctx, settings, "POST", settings.YouTrackBaseURL+"/api/commands", payload, nil); err != nil {
+			return fmt.Errorf("failed to remove %s from sprint '%s': %w", issueID, sp.Name, err)
+		}
+	}
+	if target.has(issueID) {
+		return nil
+	}
+	return s.addIssueToAgileBoardUsingCommand(ctx, settings, issueID, board.Name, target.Name)
+}
+
+// taskSprint returns the sprint the task's sprint field, or its section, maps
+// to. Returns "" if it isn't mapped.
+func taskSprint(sc database.SprintConfig, task AsanaTask) string {
+	if len(sc.Sprints) == 0 {
+		return ""
+	}
+	var value string
+	if sc.AsanaField != "" {
+		for _, field := range task.CustomFields {
+			if !strings.EqualFold(strings.TrimSpace(field.Name), sc.AsanaField) {
+				continue
+			}
+			switch {
+			case field.EnumValue.Name != "":
+				value = field.EnumValue.Name
+			case field.TextValue != "":
+				value = field.TextValue
+			default:
+				value = field.DisplayValue
+			}
+		}
+	} else if len(task.Memberships) > 0 {
+		value = task.Memberships[0].Section.Name
+	}
+	sprint, _ := sc.SprintFor(value)
+	return sprint
+}
+
+// GetStoryPoints reads an issue's Story points field
+func (s *YouTrackService) GetStoryPoints(issue YouTrackIssue) (float64, bool) {
+	for _, field := range issue.CustomFields {
+		if strings.EqualFold(field.Name, storyPointsField) {
+			points, ok := field.Value.(float64)
+			return points, ok
+		}
+	}
+	return 0, false
+}
+
+// SetStoryPoints sets an issue's Story points field
+func (s *YouTrackService) SetStoryPoints(ctx context.Context, userID int, issueID string, points float64) error {
+	settings, err := s.configService.GetSettings(userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user settings: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/api/issues/%s?fields=id", settings.YouTrackBaseURL, issueID)
+	payload := map[string]interface{}{
+		"customFields": []map[string]interface{}{{
+			"name":  storyPointsField,
+			"$type": "SimpleIssueCustomField",
+			"value": points,
+		}},
+	}
+	if err := s.doJSON(ctx, settings, "POST", url, payload, nil); err != nil {
+		return fmt.Errorf("failed to set story points of %s: %w", issueID, err)
+	}
+	s.InvalidateIssueCache(userID)
+	return nil
+}
+
+// storyPointsFromTask sets a new issue's Story points from the task's story
+// points field, when the sync pair maps one. Failures are only logged.
+func (s *YouTrackService) storyPointsFromTask(ctx context.Context, userID int, settings *configpkg.UserSettings, issueID string, task AsanaTask) {
+	field := settings.SprintConfig.StoryPointsField
+	if field == "" {
+		return
+	}
+	if _, points, ok := taskNumberField(task, field); ok && points > 0 {
+		if err := s.SetStoryPoints(ctx, userID, issueID, points); err != nil {
+			fmt.Printf("Warning: %v\n", err)
+		}
+	}
+}
+
+// syncStoryPoints copies the task's story points to its issue when they
+// differ. A task without story points leaves the issue alone.
+func (s *SyncService) syncStoryPoints(ctx context.Context, userID int, sc database.SprintConfig, task AsanaTask, issue *YouTrackIssue) error {
+	if sc.StoryPointsField == "" || issue == nil {
+		return nil
+	}
+	_, points, ok := taskNumberField(task, sc.StoryPointsField)
+	if !ok || points <= 0 {
+		return nil
+	}
+	if current, ok := s.youtrackService.GetStoryPoints(*issue); ok && current == points {
+		return nil
+	}
+	if err := s.youtrackService.SetStoryPoints(ctx, userID, issue.ID, points); err != nil {
+		return err
+	}
+	fmt.Printf("SYNC: Set story points of %s to %g from Asana task %s\n", issue.ID, points, task.GID)
+	return nil
+}
+
+// processSprintMismatches reports matched and mismatched tickets whose task
+// maps to a sprint their issue isn't in
+func (s *AnalysisService) processSprintMismatches(ctx context.Context, settings *configpkg.UserSettings, analysis *TicketAnalysis) {
+	board, err := s.youtrackService.getAgileBoard(ctx, settings, true)
+	if err != nil {
+		fmt.Printf("ANALYSIS: Could not fetch board sprints: %v (skipping sprint check)\n", err)
+		return
+	}
+
+	check := func(task AsanaTask, issue YouTrackIssue) {
+		sprint := taskSprint(settings.SprintConfig, task)
+		if sprint == "" {
+			return
+		}
+		target := board.sprint(sprint)
+		if target != nil && target.has(issue.ID) {
+			return
+		}
+		name := sprint
+		if target != nil {
+			name = target.Name
+		}
+		analysis.SprintMismatches = append(analysis.SprintMismatches, SprintMismatch{
+			AsanaTask:       task,
+			YouTrackIssue:   issue,
+			AsanaSprint:     name,
+			YouTrackSprints: board.issueSprints(issue.ID),
+		})
+	}
+	for _, t := range analysis.Matched {
+		check(t.AsanaTask, t.YouTrackIssue)
+	}
+	for _, t := range analysis.Mismatched {
+		check(t.AsanaTask, t.YouTrackIssue)
+	}
+	fmt.Printf("ANALYSIS: %d tickets in different sprints\n", len(analysis.SprintMismatches))
+}
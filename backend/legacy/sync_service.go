@@ -1,7 +1,9 @@
 package legacy
 
 import (
+	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	configpkg "asana-youtrack-sync/config"
@@ -30,39 +32,48 @@ type SyncService struct {
 	youtrackService *YouTrackService
 	analysisService *AnalysisService
 	ignoreService   *IgnoreService
+	suggestions     *MatchSuggestionService
+	orphans         *OrphanService
+	reverseSync     *ReverseSyncService
 }
 
 // NewSyncService creates a new sync service
 func NewSyncService(db *database.DB, configService *configpkg.Service) *SyncService {
 	asanaSvc := NewAsanaService(configService)
-	return &SyncService{
+	svc := &SyncService{
 		db:              db,
 		configService:   configService,
 		asanaService:    asanaSvc,
 		youtrackService: NewYouTrackService(configService, asanaSvc),
 		analysisService: NewAnalysisService(db, configService),
 		ignoreService:   NewIgnoreService(db, configService),
+		suggestions:     NewMatchSuggestionService(db, configService),
+		orphans:         NewOrphanService(db, configService),
 	}
+	svc.youtrackService.SetTicketMappings(db)
+	svc.reverseSync = NewReverseSyncService(db, svc.youtrackService, asanaSvc, configService)
+	return svc
 }
 
 // CreateMissingTickets creates missing tickets in YouTrack.
 // Optimized: skips full PerformAnalysis — fetches Asana tasks, checks DB mappings, creates only truly new ones.
-func (s *SyncService) CreateMissingTickets(userID int, column ...string) (map[string]interface{}, error) {
+func (s *SyncService) CreateMissingTickets(ctx context.Context, userID int, column ...string) (map[string]interface{}, error) {
+	workflow := workflowFor(s.configService, userID)
 	var columnsToProcess []string
 	if len(column) > 0 && column[0] != "" && column[0] != "all_syncable" {
 		columnsToProcess = []string{column[0]}
 		fmt.Printf("CREATE: Creating tickets for specific column: %s (user %d)\n", column[0], userID)
 	} else {
-		columnsToProcess = SyncableColumns
+		columnsToProcess = workflow.SyncableColumnNames()
 		fmt.Printf("CREATE: Creating tickets for all syncable columns (user %d)\n", userID)
 	}
 
 	// Fetch and filter Asana tasks (cached — fast)
-	allTasks, err := s.asanaService.GetTasks(userID)
+	allTasks, err := s.asanaService.GetTasks(ctx, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get Asana tasks: %w", err)
 	}
-	filteredTasks := s.asanaService.FilterTasksByColumns(allTasks, columnsToProcess)
+	filteredTasks := workflow.FilterTasks(allTasks, columnsToProcess)
 
 	settings, err := s.configService.GetSettings(userID)
 	if err != nil {
@@ -76,9 +87,12 @@ func (s *SyncService) CreateMissingTickets(userID int, column ...string) (map[st
 		mappedGIDs[m.AsanaTaskID] = true
 	}
 
+	// Tasks with a match suggestion awaiting review may already exist in YouTrack
+	pendingGIDs := s.suggestions.PendingTaskIDs(userID)
+
 	// Fetch YT issues ONCE before the loop — CreateIssueWithReturn invalidates cache,
 	// so fetching inside the loop would hit the live API on every iteration after the first create.
-	ytIssues, _ := s.youtrackService.GetIssues(userID)
+	ytIssues, _ := s.youtrackService.GetIssues(ctx, userID)
 	// Build a title→ID map for O(1) duplicate detection per task
 	ytTitleMap := make(map[string]string, len(ytIssues)) // normalized title -> YT issue ID
 	ytSummaryMap := make(map[string]string, len(ytIssues)) // YT issue ID -> original summary
@@ -90,24 +104,26 @@ func (s *SyncService) CreateMissingTickets(userID int, column ...string) (map[st
 		}
 	}
 
-	results := []map[string]interface{}{}
-	created := 0
-	skipped := 0
+	// Decide on every task first; only the creates run in parallel. Tasks that
+	// share a title are created by one worker in order, so the first creates
+	// the issue and the rest map to it instead of creating duplicates.
+	results := make([]map[string]interface{}, len(filteredTasks))
+	var titleGroups [][]int
+	titleGroup := make(map[string]int) // normalized title -> index in titleGroups
 
-	for _, task := range filteredTasks {
+	for i, task := range filteredTasks {
 		asanaTags := s.asanaService.GetTags(task)
 		result := map[string]interface{}{
 			"task_id":    task.GID,
 			"task_name":  task.Name,
 			"asana_tags": asanaTags,
 		}
+		results[i] = result
 
 		// Skip if already mapped in DB (prevents double-create)
 		if mappedGIDs[task.GID] {
 			result["status"] = "skipped"
 			result["reason"] = "Already mapped"
-			skipped++
-			results = append(results, result)
 			continue
 		}
 
@@ -115,16 +131,18 @@ func (s *SyncService) CreateMissingTickets(userID int, column ...string) (map[st
 		if !isTicketStable(task) {
 			result["status"] = "skipped"
 			result["reason"] = fmt.Sprintf("Ticket modified recently — waiting for stability (modified_at: %s)", task.ModifiedAt)
-			skipped++
-			results = append(results, result)
 			continue
 		}
 
 		if s.ignoreService.IsIgnored(userID, task.GID) {
 			result["status"] = "skipped"
 			result["reason"] = "Ticket is ignored"
-			skipped++
-			results = append(results, result)
+			continue
+		}
+
+		if pendingGIDs[task.GID] {
+			result["status"] = "skipped"
+			result["reason"] = "Match suggestion pending review"
 			continue
 		}
 
@@ -132,44 +150,49 @@ func (s *SyncService) CreateMissingTickets(userID int, column ...string) (map[st
 		normTask := normalizeTitle(task.Name)
 		if issueID, exists := ytTitleMap[normTask]; exists {
 			s.db.CreateTicketMapping(userID, settings.AsanaProjectID, task.GID, settings.YouTrackProjectID, issueID)
-			mappedGIDs[task.GID] = true
 			result["status"] = "already_exists"
 			result["youtrack_issue_id"] = issueID
 			result["youtrack_summary"] = ytSummaryMap[issueID]
-			skipped++
-			results = append(results, result)
 			continue
 		}
 
-		// No existing match — create it
-		createdIssueID, createErr := s.youtrackService.CreateIssueWithReturn(userID, task)
-		if createErr != nil {
-			result["status"] = "failed"
-			result["error"] = createErr.Error()
-		} else {
-			result["status"] = "created"
-			result["youtrack_issue_id"] = createdIssueID
+		if g, exists := titleGroup[normTask]; exists && normTask != "" {
+			titleGroups[g] = append(titleGroups[g], i)
+			continue
+		}
+		titleGroup[normTask] = len(titleGroups)
+		titleGroups = append(titleGroups, []int{i})
+	}
 
-			_, mappingErr := s.db.CreateTicketMapping(
-				userID, settings.AsanaProjectID, task.GID, settings.YouTrackProjectID, createdIssueID,
-			)
-			if mappingErr != nil {
-				fmt.Printf("WARNING: Created ticket but failed to create mapping: %v\n", mappingErr)
-			} else {
-				result["mapping_created"] = true
-				mappedGIDs[task.GID] = true
-				// Add newly created issue to title map so subsequent tasks in same batch don't re-create it
-				ytTitleMap[normalizeTitle(task.Name)] = createdIssueID
-				fmt.Printf("Created mapping: Asana %s <-> YouTrack %s\n", task.GID, createdIssueID)
+	// No existing match — create them
+	runPool(ctx, bulkWorkers, len(titleGroups), func(g int) {
+		createdIssueID := ""
+		for _, i := range titleGroups[g] {
+			task, result := filteredTasks[i], results[i]
+			if createdIssueID == "" {
+				createdIssueID = s.createTicket(ctx, userID, settings, task, result)
+				continue
 			}
+			s.db.CreateTicketMapping(userID, settings.AsanaProjectID, task.GID, settings.YouTrackProjectID, createdIssueID)
+			result["status"] = "already_exists"
+			result["youtrack_issue_id"] = createdIssueID
+		}
+	})
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
-			if len(asanaTags) > 0 {
-				tagMapper := NewTagMapperForUser(userID, s.configService)
-				result["mapped_subsystem"] = tagMapper.MapTagToSubsystem(asanaTags[0])
-			}
+	recordRunMetrics("create", userID, results)
+
+	created := 0
+	skipped := 0
+	for _, result := range results {
+		switch result["status"] {
+		case "created":
 			created++
+		case "skipped", "already_exists":
+			skipped++
 		}
-		results = append(results, result)
 	}
 
 	return map[string]interface{}{
@@ -179,12 +202,45 @@ func (s *SyncService) CreateMissingTickets(userID int, column ...string) (map[st
 		"total":   len(filteredTasks),
 		"column":  columnsToProcess,
 		"results": results,
+		"errors":  ticketErrors(results, "task_id"),
 	}, nil
 }
 
+// createTicket creates the task's YouTrack issue and maps it, recording the
+// outcome in result. It returns the issue ID once the mapping is saved.
+func (s *SyncService) createTicket(ctx context.Context, userID int, settings *configpkg.UserSettings, task AsanaTask, result map[string]interface{}) string {
+	createdIssueID, createErr := s.youtrackService.CreateIssueWithReturn(ctx, userID, task)
+	if createErr != nil {
+		result["status"] = "failed"
+		result["error"] = createErr.Error()
+		return ""
+	}
+
+	result["status"] = "created"
+	result["youtrack_issue_id"] = createdIssueID
+
+	mapped := ""
+	_, mappingErr := s.db.CreateTicketMapping(
+		userID, settings.AsanaProjectID, task.GID, settings.YouTrackProjectID, createdIssueID,
+	)
+	if mappingErr != nil {
+		fmt.Printf("WARNING: Created ticket but failed to create mapping: %v\n", mappingErr)
+	} else {
+		result["mapping_created"] = true
+		mapped = createdIssueID
+		fmt.Printf("Created mapping: Asana %s <-> YouTrack %s\n", task.GID, createdIssueID)
+	}
+
+	if asanaTags := s.asanaService.GetTags(task); len(asanaTags) > 0 {
+		tagMapper := NewTagMapperForUser(userID, s.configService)
+		result["mapped_subsystem"] = tagMapper.MapTagToSubsystem(asanaTags[0])
+	}
+	return mapped
+}
+
 // CreateSingleTicket creates a single ticket in YouTrack.
 // Optimized: fetches only the one Asana task by GID, uses cached YT issues for duplicate check.
-func (s *SyncService) CreateSingleTicket(userID int, taskID string) (map[string]interface{}, error) {
+func (s *SyncService) CreateSingleTicket(ctx context.Context, userID int, taskID string) (map[string]interface{}, error) {
 	// Check DB mapping first — instant skip if already created
 	if _, mappingErr := s.db.GetTicketMappingByAsanaID(userID, taskID); mappingErr == nil {
 		return map[string]interface{}{
@@ -203,7 +259,7 @@ func (s *SyncService) CreateSingleTicket(userID int, taskID string) (map[string]
 	}
 
 	// Fetch single task directly — no full project fetch
-	targetTask, err := s.asanaService.GetTaskByGID(userID, taskID)
+	targetTask, err := s.asanaService.GetTaskByGID(ctx, userID, taskID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get Asana task: %w", err)
 	}
@@ -216,7 +272,7 @@ func (s *SyncService) CreateSingleTicket(userID int, taskID string) (map[string]
 	}
 
 	// Duplicate check using cached YT issues — no extra API call
-	allIssues, issErr := s.youtrackService.GetIssues(userID)
+	allIssues, issErr := s.youtrackService.GetIssues(ctx, userID)
 	if issErr == nil {
 		for _, issue := range allIssues {
 			if titlesMatch(targetTask.Name, issue.Summary) {
@@ -237,7 +293,7 @@ func (s *SyncService) CreateSingleTicket(userID int, taskID string) (map[string]
 	}
 
 	// Create issue in YouTrack
-	createdIssueID, err := s.youtrackService.CreateIssueWithReturn(userID, *targetTask)
+	createdIssueID, err := s.youtrackService.CreateIssueWithReturn(ctx, userID, *targetTask)
 	if err != nil {
 		return map[string]interface{}{
 			"status":     "failed",
@@ -277,7 +333,7 @@ func (s *SyncService) CreateSingleTicket(userID int, taskID string) (map[string]
 
 // SyncMismatchedTickets synchronizes mismatched tickets
 // Optimized: Uses DB mappings + cached Asana tasks instead of full PerformAnalysis
-func (s *SyncService) SyncMismatchedTickets(userID int, requests []SyncRequest, column ...string) (map[string]interface{}, error) {
+func (s *SyncService) SyncMismatchedTickets(ctx context.Context, userID int, requests []SyncRequest, column ...string) (map[string]interface{}, error) {
 	columnInfo := "all_syncable"
 	if len(column) > 0 && column[0] != "" && column[0] != "all_syncable" {
 		columnInfo = column[0]
@@ -285,7 +341,7 @@ func (s *SyncService) SyncMismatchedTickets(userID int, requests []SyncRequest,
 	fmt.Printf("SYNC: Syncing %d tickets for column: %s (user %d) — using DB mappings, no full analysis\n", len(requests), columnInfo, userID)
 
 	// Get all Asana tasks (cached — no API call if cache is fresh)
-	allTasks, err := s.asanaService.GetTasks(userID)
+	allTasks, err := s.asanaService.GetTasks(ctx, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get Asana tasks: %w", err)
 	}
@@ -300,31 +356,39 @@ func (s *SyncService) SyncMismatchedTickets(userID int, requests []SyncRequest,
 	syncSettings, _ := s.configService.GetSettings(userID)
 	// Lazily populated on first fallback need — avoids API call when all tickets have DB mappings
 	var ytIssuesCache []YouTrackIssue
-	var ytIssuesFetched bool
+	var ytIssuesOnce sync.Once
 	getYTIssues := func() []YouTrackIssue {
-		if !ytIssuesFetched {
-			ytIssuesCache, _ = s.youtrackService.GetIssues(userID)
-			ytIssuesFetched = true
-		}
+		ytIssuesOnce.Do(func() {
+			ytIssuesCache, _ = s.youtrackService.GetIssues(ctx, userID)
+		})
 		return ytIssuesCache
 	}
+	// The agile board is likewise fetched once, when the first update needs it
+	var board *agileBoard
+	var boardOnce sync.Once
+	getBoard := func() *agileBoard {
+		boardOnce.Do(func() {
+			board = s.youtrackService.syncBoard(ctx, syncSettings)
+		})
+		return board
+	}
 
-	results := []map[string]interface{}{}
-	synced := 0
-
-	for _, req := range requests {
+	// Tickets are synced in parallel; each worker fills its request's slot
+	results := make([]map[string]interface{}, len(requests))
+	runPool(ctx, bulkWorkers, len(requests), func(i int) {
+		req := requests[i]
 		result := map[string]interface{}{
 			"ticket_id": req.TicketID,
 			"action":    req.Action,
 		}
+		results[i] = result
 
 		switch req.Action {
 		case "sync":
 			if s.ignoreService.IsIgnored(userID, req.TicketID) {
 				result["status"] = "skipped"
 				result["reason"] = "Ticket is ignored"
-				results = append(results, result)
-				continue
+				return
 			}
 
 			// Look up the Asana task from cache
@@ -332,8 +396,7 @@ func (s *SyncService) SyncMismatchedTickets(userID int, requests []SyncRequest,
 			if !taskExists {
 				result["status"] = "failed"
 				result["error"] = "Asana task not found in cache"
-				results = append(results, result)
-				continue
+				return
 			}
 
 			// Look up the YouTrack issue ID — try DB mapping first, then description, then title
@@ -346,7 +409,7 @@ func (s *SyncService) SyncMismatchedTickets(userID int, requests []SyncRequest,
 			} else {
 				// Priority 2: Search YouTrack by Asana ID in description
 				// getYTIssues() fetches once and caches — safe to call multiple times
-				foundID, searchErr := s.youtrackService.FindIssueByAsanaID(userID, req.TicketID)
+				foundID, searchErr := s.youtrackService.FindIssueByAsanaID(ctx, userID, req.TicketID)
 				if searchErr == nil {
 					youtrackIssueID = foundID
 					if syncSettings != nil {
@@ -369,18 +432,61 @@ func (s *SyncService) SyncMismatchedTickets(userID int, requests []SyncRequest,
 			if youtrackIssueID == "" {
 				result["status"] = "failed"
 				result["error"] = "Could not find matching YouTrack issue (no DB mapping, description match, or title match)"
-				results = append(results, result)
-				continue
+				return
+			}
+
+			// Sync completion both ways first; it may keep the issue's state
+			state := s.asanaService.MapStateToYouTrackWithSettings(userID, asanaTask)
+			var agreed *bool
+			if syncSettings != nil && len(syncSettings.CompletionConfig.ResolvedStates) > 0 && state != "DISPLAY_ONLY" && state != "" {
+				var completionErr error
+				state, agreed, completionErr = s.reconcileCompletion(ctx, userID, syncSettings, mapping, asanaTask, findIssue(getYTIssues(), youtrackIssueID), state)
+				if completionErr != nil {
+					result["status"] = "failed"
+					result["error"] = completionErr.Error()
+					return
+				}
+			}
+
+			// Audit linked assignees only; checking first spares the issue fetch
+			var assigneeChange map[string]string
+			if loadIdentities(s.db, userID).youTrackFor(asanaTask.Assignee.GID) != nil {
+				assigneeChange = s.assigneeChange(userID, asanaTask, findIssue(getYTIssues(), youtrackIssueID))
 			}
 
 			// Update the YouTrack issue directly
-			err = s.youtrackService.UpdateIssue(userID, youtrackIssueID, asanaTask)
+			err := s.youtrackService.updateIssue(ctx, userID, youtrackIssueID, asanaTask, state, getBoard())
 			if err != nil {
 				result["status"] = "failed"
 				result["error"] = err.Error()
 			} else {
 				result["status"] = "synced"
 				result["youtrack_issue_id"] = youtrackIssueID
+				if assigneeChange != nil {
+					result["assignee_change"] = assigneeChange
+				}
+				if agreed != nil && (mapping.Completed == nil || *mapping.Completed != *agreed) {
+					if err := s.db.SetTicketMappingCompleted(userID, mapping.ID, *agreed); err != nil {
+						fmt.Printf("SYNC: Failed to record completion of %s: %v\n", req.TicketID, err)
+					}
+				}
+				if syncSettings != nil && syncSettings.TimeTracking.Enabled() {
+					totals, err := s.mirrorTimeTracking(ctx, userID, syncSettings.TimeTracking, asanaTask, findIssue(getYTIssues(), youtrackIssueID))
+					if err != nil {
+						fmt.Printf("SYNC: %v\n", err)
+					}
+					if totals != nil {
+						result["time_tracking"] = totals
+					}
+				}
+				if syncSettings != nil && syncSettings.SprintConfig.StoryPointsField != "" {
+					if err := s.syncStoryPoints(ctx, userID, syncSettings.SprintConfig, asanaTask, findIssue(getYTIssues(), youtrackIssueID)); err != nil {
+						fmt.Printf("SYNC: %v\n", err)
+					}
+				}
+				if err := s.syncFollowers(ctx, userID, asanaTask, youtrackIssueID); err != nil {
+					fmt.Printf("SYNC: %v\n", err)
+				}
 
 				asanaTags := s.asanaService.GetTags(asanaTask)
 				if len(asanaTags) > 0 {
@@ -392,7 +498,6 @@ func (s *SyncService) SyncMismatchedTickets(userID int, requests []SyncRequest,
 						"mapped_subsystem": mappedSubsystem,
 					}
 				}
-				synced++
 			}
 
 		case "ignore_temp":
@@ -417,8 +522,18 @@ func (s *SyncService) SyncMismatchedTickets(userID int, requests []SyncRequest,
 			result["status"] = "failed"
 			result["error"] = "Invalid action"
 		}
+	})
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	recordRunMetrics("sync", userID, results)
 
-		results = append(results, result)
+	synced := 0
+	for _, result := range results {
+		if result["status"] == "synced" {
+			synced++
+		}
 	}
 
 	return map[string]interface{}{
@@ -427,20 +542,21 @@ func (s *SyncService) SyncMismatchedTickets(userID int, requests []SyncRequest,
 		"total":   len(requests),
 		"column":  columnInfo,
 		"results": results,
+		"errors":  ticketErrors(results, "ticket_id"),
 	}, nil
 }
 
 // GetMismatchedTickets returns mismatched tickets for preview
-func (s *SyncService) GetMismatchedTickets(userID int, column ...string) (map[string]interface{}, error) {
+func (s *SyncService) GetMismatchedTickets(ctx context.Context, userID int, column ...string) (map[string]interface{}, error) {
 	var columnsToAnalyze []string
 
 	if len(column) > 0 && column[0] != "" && column[0] != "all_syncable" {
 		columnsToAnalyze = []string{column[0]}
 	} else {
-		columnsToAnalyze = SyncableColumns
+		columnsToAnalyze = workflowFor(s.configService, userID).SyncableColumnNames()
 	}
 
-	analysis, err := s.analysisService.PerformAnalysis(userID, columnsToAnalyze)
+	analysis, err := s.analysisService.PerformAnalysis(ctx, userID, columnsToAnalyze)
 	if err != nil {
 		return nil, fmt.Errorf("analysis failed: %w", err)
 	}
@@ -489,7 +605,7 @@ func (s *SyncService) ValidateSyncRequests(requests []SyncRequest) error {
 
 // GetSyncableTickets returns tickets that can be synced.
 // Optimized: reads DB mappings — no full PerformAnalysis.
-func (s *SyncService) GetSyncableTickets(userID int) (map[string]interface{}, error) {
+func (s *SyncService) GetSyncableTickets(ctx context.Context, userID int) (map[string]interface{}, error) {
 	mappings, err := s.db.GetAllTicketMappings(userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get mappings: %w", err)
@@ -515,7 +631,7 @@ func (s *SyncService) GetSyncableTickets(userID int) (map[string]interface{}, er
 
 // GetSyncStats returns synchronization statistics.
 // Optimized: reads DB mappings — no full PerformAnalysis.
-func (s *SyncService) GetSyncStats(userID int) (map[string]interface{}, error) {
+func (s *SyncService) GetSyncStats(ctx context.Context, userID int) (map[string]interface{}, error) {
 	mappings, err := s.db.GetAllTicketMappings(userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get mappings: %w", err)
@@ -540,34 +656,19 @@ func (s *SyncService) GetSyncStats(userID int) (map[string]interface{}, error) {
 
 // SyncTicketsByColumn syncs tickets from a specific column.
 // Optimized: uses DB mappings + cached Asana tasks — no full PerformAnalysis.
-func (s *SyncService) SyncTicketsByColumn(userID int, column string) (map[string]interface{}, error) {
-	var columnsToProcess []string
-	if column == "" || column == "all_syncable" {
-		columnsToProcess = SyncableColumns
-	} else {
-		columnMap := map[string]string{
-			"backlog":         "backlog",
-			"in_progress":     "in progress",
-			"dev":             "dev",
-			"stage":           "stage",
-			"prod":            "prod",
-			"blocked":         "blocked",
-			"ready_for_stage": "ready for stage",
-			"findings":        "findings",
-		}
-		mapped, exists := columnMap[column]
-		if !exists {
-			return nil, fmt.Errorf("invalid column: %s", column)
-		}
-		columnsToProcess = []string{mapped}
+func (s *SyncService) SyncTicketsByColumn(ctx context.Context, userID int, column string) (map[string]interface{}, error) {
+	workflow := workflowFor(s.configService, userID)
+	columnsToProcess, mapped := workflow.ResolveColumns(column)
+	if mapped != "all_syncable" && workflow.Column(mapped) == nil {
+		return nil, fmt.Errorf("invalid column: %s", column)
 	}
 
 	// Get Asana tasks filtered to this column (cached)
-	allTasks, err := s.asanaService.GetTasks(userID)
+	allTasks, err := s.asanaService.GetTasks(ctx, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get Asana tasks: %w", err)
 	}
-	filteredTasks := s.asanaService.FilterTasksByColumns(allTasks, columnsToProcess)
+	filteredTasks := workflow.FilterTasks(allTasks, columnsToProcess)
 
 	// Build GID set for fast lookup
 	columnGIDs := make(map[string]AsanaTask, len(filteredTasks))
@@ -599,7 +700,7 @@ func (s *SyncService) SyncTicketsByColumn(userID int, column string) (map[string
 		}, nil
 	}
 
-	result, err := s.SyncMismatchedTickets(userID, syncRequests, column)
+	result, err := s.SyncMismatchedTickets(ctx, userID, syncRequests, column)
 	if err != nil {
 		return nil, err
 	}
@@ -609,14 +710,14 @@ func (s *SyncService) SyncTicketsByColumn(userID int, column string) (map[string
 
 // CreateTicketsByColumn creates missing tickets from a specific column.
 // Optimized: skips full PerformAnalysis — uses DB mappings to avoid double-creates.
-func (s *SyncService) CreateTicketsByColumn(userID int, column string) (map[string]interface{}, error) {
+func (s *SyncService) CreateTicketsByColumn(ctx context.Context, userID int, column string) (map[string]interface{}, error) {
 	// Delegate to CreateMissingTickets which now has the optimized path
-	return s.CreateMissingTickets(userID, column)
+	return s.CreateMissingTickets(ctx, userID, column)
 }
 
 // GetSyncPreview provides a preview of what would be synced.
 // Optimized: checks DB mappings — no full PerformAnalysis.
-func (s *SyncService) GetSyncPreview(userID int, ticketIDs []string) (map[string]interface{}, error) {
+func (s *SyncService) GetSyncPreview(ctx context.Context, userID int, ticketIDs []string) (map[string]interface{}, error) {
 	preview := []map[string]interface{}{}
 
 	for _, ticketID := range ticketIDs {
@@ -643,16 +744,15 @@ func (s *SyncService) GetSyncPreview(userID int, ticketIDs []string) (map[string
 	}, nil
 }
 
-// AutoSync performs auto-sync for all mapped tickets.
+// AutoSync performs auto-sync for all mapped tickets, then applies the
+// orphan policy to orphans past their grace period. A stale user directory is
+// refreshed first.
 // Optimized: reads DB mappings directly — no full PerformAnalysis.
-func (s *SyncService) AutoSync(userID int) error {
+func (s *SyncService) AutoSync(ctx context.Context, userID int) error {
 	mappings, err := s.db.GetAllTicketMappings(userID)
 	if err != nil {
 		return fmt.Errorf("failed to get ticket mappings: %w", err)
 	}
-	if len(mappings) == 0 {
-		return nil
-	}
 
 	var syncRequests []SyncRequest
 	for _, m := range mappings {
@@ -664,15 +764,23 @@ func (s *SyncService) AutoSync(userID int) error {
 		}
 	}
 
-	if len(syncRequests) == 0 {
-		return nil
+	// Keep the user directory fresh, so new people get linked by email
+	if identities := loadIdentities(s.db, userID); identities.hasDirectory() && time.Since(identities.refreshedAt) > identityDirectoryTTL {
+		if _, err := refreshDirectory(ctx, s.db, s.asanaService, s.youtrackService, userID); err != nil {
+			fmt.Printf("AUTO-SYNC: Failed to refresh the user directory for user %d: %v\n", userID, err)
+		}
 	}
 
-	_, err = s.SyncMismatchedTickets(userID, syncRequests)
-	if err != nil {
-		return fmt.Errorf("sync operation failed: %w", err)
+	if len(syncRequests) > 0 {
+		_, err = s.SyncMismatchedTickets(ctx, userID, syncRequests)
+		if err != nil {
+			return fmt.Errorf("sync operation failed: %w", err)
+		}
+		fmt.Printf("AUTO-SYNC: Processed %d mapped tickets for user %d\n", len(syncRequests), userID)
 	}
 
-	fmt.Printf("AUTO-SYNC: Processed %d mapped tickets for user %d\n", len(syncRequests), userID)
+	if _, err := s.orphans.ApplyPolicy(ctx, userID); err != nil {
+		fmt.Printf("AUTO-SYNC: Failed to apply orphan policy for user %d: %v\n", userID, err)
+	}
 	return nil
 }
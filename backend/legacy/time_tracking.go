@@ -0,0 +1,90 @@
+package legacy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	configpkg "asana-youtrack-sync/config"
+	"asana-youtrack-sync/database"
+)
+
+// taskNumberField returns the GID and value of the task's custom field with
+// the given name
+func taskNumberField(task AsanaTask, name string) (gid string, value float64, ok bool) {
+	for _, field := range task.CustomFields {
+		if strings.EqualFold(strings.TrimSpace(field.Name), name) {
+			return field.GID, field.NumberValue, true
+		}
+	}
+	return "", 0, false
+}
+
+// mirrorTimeTracking copies the issue's estimation and spent time to the
+// task's configured number fields and returns the totals it mirrored. Spent
+// time is summed from the work items when the issue tracks time but has no
+// Spent time field. Values the issue doesn't have leave the fields alone.
+func (s *SyncService) mirrorTimeTracking(ctx context.Context, userID int, tt database.TimeTrackingConfig, task AsanaTask, issue *YouTrackIssue) (*TimeTotals, error) {
+	if !tt.Enabled() || issue == nil {
+		return nil, nil
+	}
+	totals := s.youtrackService.GetTimeTotals(*issue)
+	if totals == nil {
+		return nil, nil
+	}
+	if tt.SpentField != "" && totals.SpentMinutes == nil {
+		items, err := s.youtrackService.GetWorkItems(ctx, userID, issue.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get work items of %s: %w", issue.ID, err)
+		}
+		if len(items) > 0 {
+			spent := 0
+			for _, item := range items {
+				spent += item.Duration.Minutes
+			}
+			totals.SpentMinutes = &spent
+		}
+	}
+
+	values := make(map[string]float64)
+	for _, f := range []struct {
+		name    string
+		minutes *int
+	}{{tt.EstimateField, totals.EstimateMinutes}, {tt.SpentField, totals.SpentMinutes}} {
+		if f.name == "" || f.minutes == nil {
+			continue
+		}
+		gid, current, ok := taskNumberField(task, f.name)
+		if !ok {
+			fmt.Printf("SYNC: Asana task %s has no field '%s' to mirror time to\n", task.GID, f.name)
+			continue
+		}
+		if value := tt.ToFieldValue(*f.minutes); value != current {
+			values[gid] = value
+		}
+	}
+	if len(values) == 0 {
+		return totals, nil
+	}
+	if err := s.asanaService.SetTaskNumberFields(ctx, userID, task.GID, values); err != nil {
+		return totals, fmt.Errorf("failed to mirror time of %s to Asana: %w", issue.ID, err)
+	}
+	fmt.Printf("SYNC: Mirrored time of %s to Asana task %s\n", issue.ID, task.GID)
+	return totals, nil
+}
+
+// estimateFromTask sets a new issue's Estimation from the task's estimate
+// field, when the sync pair asks for it. Failures are only logged.
+func (s *YouTrackService) estimateFromTask(ctx context.Context, userID int, settings *configpkg.UserSettings, issueID string, task AsanaTask) {
+	tt := settings.TimeTracking
+	if !tt.EstimateOnCreate || tt.EstimateField == "" {
+		return
+	}
+	_, value, ok := taskNumberField(task, tt.EstimateField)
+	if !ok || value <= 0 {
+		return
+	}
+	if err := s.SetEstimation(ctx, userID, issueID, tt.ToMinutes(value)); err != nil {
+		fmt.Printf("Warning: Failed to set estimation of %s: %v\n", issueID, err)
+	}
+}
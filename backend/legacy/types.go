@@ -2,7 +2,6 @@
 package legacy
 
 import (
-	"strings"
 	"time"
 
 	"asana-youtrack-sync/database"
@@ -21,6 +20,10 @@ type AsanaTask struct {
 		GID  string `json:"gid"`
 		Name string `json:"name"`
 	} `json:"assignee"`
+	Followers []struct {
+		GID  string `json:"gid"`
+		Name string `json:"name"`
+	} `json:"followers"`
 	Memberships []struct {
 		Section struct {
 			GID  string `json:"gid"`
@@ -32,11 +35,11 @@ type AsanaTask struct {
 		Name string `json:"name"`
 	} `json:"tags"`
 	CustomFields []struct {
-		GID          string `json:"gid"`
-		Name         string `json:"name"`
-		DisplayValue string `json:"display_value"`
-		TextValue    string `json:"text_value"`
-		NumberValue  int    `json:"number_value"`
+		GID          string  `json:"gid"`
+		Name         string  `json:"name"`
+		DisplayValue string  `json:"display_value"`
+		TextValue    string  `json:"text_value"`
+		NumberValue  float64 `json:"number_value"`
 		EnumValue    struct {
 			GID  string `json:"gid"`
 			Name string `json:"name"`
@@ -75,6 +78,7 @@ type YouTrackIssue struct {
 	Created              int64                  `json:"created"`
 	Updated              int64                  `json:"updated"`
 	State                string                 `json:"state"`
+	StateID              string                 `json:"state_id,omitempty"`
 	Subsystem            string                 `json:"subsystem"`
 	CreatedBy            string                 `json:"created_by"`
 	Attachments          []YouTrackAttachment   `json:"attachments"`
@@ -98,6 +102,31 @@ type YouTrackAttachment struct {
 	Extension string `json:"extension"`
 }
 
+// YouTrackWorkItem is time logged on a YouTrack issue
+type YouTrackWorkItem struct {
+	ID       string `json:"id"`
+	Date     int64  `json:"date"`
+	Text     string `json:"text"`
+	Duration struct {
+		Minutes int `json:"minutes"`
+	} `json:"duration"`
+	Author struct {
+		Login    string `json:"login"`
+		FullName string `json:"fullName"`
+	} `json:"author"`
+}
+
+// YouTrackComment is a comment on a YouTrack issue
+type YouTrackComment struct {
+	ID      string `json:"id"`
+	Text    string `json:"text"`
+	Created int64  `json:"created"`
+	Author  struct {
+		Login    string `json:"login"`
+		FullName string `json:"fullName"`
+	} `json:"author"`
+}
+
 type YouTrackUser struct {
 	ID       string `json:"id"`
 	RingID   string `json:"ringId"`
@@ -106,6 +135,19 @@ type YouTrackUser struct {
 	Email    string `json:"email"`
 }
 
+// YouTrackWatcher is a user watching a YouTrack issue
+type YouTrackWatcher struct {
+	ID   string       `json:"id"`
+	User YouTrackUser `json:"user"`
+}
+
+// AsanaUser is a member of the Asana workspace
+type AsanaUser struct {
+	GID   string `json:"gid"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
 // FieldDiff holds Asana vs YouTrack value comparison for a single field
 type FieldDiff struct {
 	AsanaValue    string `json:"asana_value"`
@@ -115,9 +157,20 @@ type FieldDiff struct {
 
 // AlreadyExistsTicket represents an Asana task that exists in YT but isn't mapped
 type AlreadyExistsTicket struct {
+	AsanaTask       AsanaTask     `json:"asana_task"`
+	YouTrackIssue   YouTrackIssue `json:"youtrack_issue"`
+	MatchMethod     string        `json:"match_method"`   // "title" or "description", see legacyMatchMethod
+	MatchStrategy   string        `json:"match_strategy"` // one of the MatchMethod* constants
+	MatchConfidence float64       `json:"match_confidence"`
+}
+
+// MatchSuggestionTicket is a missing task with a likely, but uncertain,
+// YouTrack counterpart queued for review
+type MatchSuggestionTicket struct {
 	AsanaTask     AsanaTask     `json:"asana_task"`
 	YouTrackIssue YouTrackIssue `json:"youtrack_issue"`
-	MatchMethod   string        `json:"match_method"` // "title" or "description"
+	MatchMethod   string        `json:"match_method"`
+	Score         float64       `json:"score"`
 }
 
 // MissingBoardTicket represents a ticket synced in both systems but not on the configured agile board
@@ -136,6 +189,15 @@ type PriorityMismatch struct {
 	YTPriority    string        `json:"yt_priority"`
 }
 
+// SprintMismatch is a ticket whose Asana task maps to another sprint than the
+// ones its YouTrack issue is in
+type SprintMismatch struct {
+	AsanaTask       AsanaTask     `json:"asana_task"`
+	YouTrackIssue   YouTrackIssue `json:"youtrack_issue"`
+	AsanaSprint     string        `json:"asana_sprint"`
+	YouTrackSprints []string      `json:"youtrack_sprints"`
+}
+
 // Analysis result structures
 type TicketAnalysis struct {
 	SelectedColumn     string               `json:"selected_column"`
@@ -151,6 +213,13 @@ type TicketAnalysis struct {
 	AlreadyExists      []AlreadyExistsTicket `json:"already_exists"`
 	MissingBoard       []MissingBoardTicket `json:"missing_board"`
 	PriorityMismatches []PriorityMismatch   `json:"priority_mismatches"`
+	MatchSuggestions   []MatchSuggestionTicket `json:"match_suggestions"`
+	SprintMismatches   []SprintMismatch     `json:"sprint_mismatches"`
+
+	// Set by StreamAnalysis for RecordRun; syncPairID is nil when the
+	// settings couldn't be loaded
+	startedAt  time.Time
+	syncPairID *int
 }
 
 type MatchedTicket struct {
@@ -166,6 +235,20 @@ type MatchedTicket struct {
 	CreatedAt       time.Time  `json:"created_at"`
 	TitleDiff       *FieldDiff `json:"title_diff,omitempty"`
 	DescriptionDiff *FieldDiff `json:"description_diff,omitempty"`
+	// How the YouTrack issue was matched to the Asana task
+	MatchMethod     string  `json:"match_method,omitempty"`
+	MatchConfidence float64 `json:"match_confidence,omitempty"`
+	// The issue's estimation and spent time, when it tracks time
+	TimeTracking *TimeTotals `json:"time_tracking,omitempty"`
+}
+
+// TimeTotals is the time tracked on a YouTrack issue. A nil total means the
+// issue has no such value.
+type TimeTotals struct {
+	EstimateMinutes *int   `json:"estimate_minutes"`
+	SpentMinutes    *int   `json:"spent_minutes"`
+	Estimate        string `json:"estimate,omitempty"` // as YouTrack presents it, e.g. "1d 2h"
+	Spent           string `json:"spent,omitempty"`
 }
 
 type MismatchedTicket struct {
@@ -184,6 +267,9 @@ type MismatchedTicket struct {
 	DescriptionDiff  *FieldDiff `json:"description_diff,omitempty"`
 	AssigneeDiff     *FieldDiff `json:"assignee_diff,omitempty"`
 	AssigneeMismatch bool       `json:"assignee_mismatch"`
+	// How the YouTrack issue was matched to the Asana task
+	MatchMethod     string  `json:"match_method,omitempty"`
+	MatchConfidence float64 `json:"match_confidence,omitempty"`
 }
 
 type FindingsAlert struct {
@@ -254,6 +340,7 @@ type AutoSyncRequest struct {
 }
 
 type AutoSyncStatus struct {
+	PairID       int       `json:"pair_id"`
 	Running      bool      `json:"running"`
 	Interval     int       `json:"interval"`
 	LastSync     time.Time `json:"last_sync"`
@@ -269,6 +356,7 @@ type AutoCreateRequest struct {
 }
 
 type AutoCreateStatus struct {
+	PairID         int       `json:"pair_id"`
 	Running        bool      `json:"running"`
 	Interval       int       `json:"interval"`
 	LastCreate     time.Time `json:"last_create"`
@@ -291,10 +379,9 @@ type TagMapping struct {
 	YouTrackSubsystem string `json:"youtrack_subsystem"`
 }
 
-// Column definitions
+// Columns of the default workflow, used by sync pairs without column mappings
 var SyncableColumns = []string{"backlog", "in progress", "dev", "stage", "prod", "blocked", "ready for stage"}
 var DisplayOnlyColumns = []string{"findings"}
-var AllColumns = append(SyncableColumns, DisplayOnlyColumns...)
 
 // Default tag-to-subsystem mapping
 var DefaultTagMapping = map[string]string{
@@ -315,36 +402,6 @@ var DefaultTagMapping = map[string]string{
 	"Performance": "performance",
 }
 
-// Helper functions for columns
-func IsSyncableColumn(sectionName string) bool {
-	for _, col := range SyncableColumns {
-		if col == sectionName {
-			return true
-		}
-	}
-	return false
-}
-
-func IsDisplayOnlyColumn(sectionName string) bool {
-	for _, col := range DisplayOnlyColumns {
-		if col == sectionName {
-			return true
-		}
-	}
-	return false
-}
-
-func IsActiveYouTrackStatus(status string) bool {
-	activeStatuses := []string{"backlog", "in progress", "dev", "stage", "blocked", "prod", "ready for stage"}
-	statusLower := strings.ToLower(strings.TrimSpace(status))
-	for _, activeStatus := range activeStatuses {
-		if statusLower == activeStatus {
-			return true
-		}
-	}
-	return false
-}
-
 // Reverse Sync (YouTrack -> Asana) data structures
 type ReverseTicketAnalysis struct {
 	Matched      []ReverseMatchedTicket `json:"matched"`
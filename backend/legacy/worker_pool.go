@@ -0,0 +1,230 @@
+package legacy
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"asana-youtrack-sync/metrics"
+)
+
+// Per-platform request limits, shared by every service in the process. Asana
+// throttles at 1500 requests per minute on paid plans and answers 429 with a
+// Retry-After header; YouTrack has no published limit but slows down under
+// parallel writes.
+const (
+	asanaMaxConcurrent    = 8
+	asanaMinInterval      = 40 * time.Millisecond
+	youtrackMaxConcurrent = 6
+	youtrackMinInterval   = 20 * time.Millisecond
+	maxRateLimitRetries   = 3
+	defaultRetryAfter     = 5 * time.Second
+)
+
+// bulkWorkers is how many tickets bulk create and sync process at once.
+// Most of their calls go to YouTrack, so more workers would only queue up.
+const bulkWorkers = youtrackMaxConcurrent
+
+var (
+	asanaTransport    = newPlatformTransport("asana", asanaMaxConcurrent, asanaMinInterval)
+	youtrackTransport = newPlatformTransport("youtrack", youtrackMaxConcurrent, youtrackMinInterval)
+)
+
+// platformTransport limits how many requests run against a platform at once
+// and how closely they start after each other. A 429 pauses the whole
+// platform for Retry-After and the request is retried.
+type platformTransport struct {
+	platform string
+	slots    chan struct{}
+	interval time.Duration
+	mutex    sync.Mutex
+	next     time.Time // earliest start of the next request
+	base     http.RoundTripper
+}
+
+func newPlatformTransport(platform string, maxConcurrent int, interval time.Duration) *platformTransport {
+	return &platformTransport{
+		platform: platform,
+		slots:    make(chan struct{}, maxConcurrent),
+		interval: interval,
+		base:     http.DefaultTransport,
+	}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *platformTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	select {
+	case t.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-t.slots }()
+
+	for attempt := 0; ; attempt++ {
+		if err := t.wait(ctx); err != nil {
+			return nil, err
+		}
+
+		start := time.Now()
+		resp, err := t.base.RoundTrip(req)
+		t.observe(req, resp, err, time.Since(start))
+		if err != nil || resp.StatusCode != http.StatusTooManyRequests || attempt == maxRateLimitRetries {
+			return resp, err
+		}
+
+		// The body of a retried request has to be sent again
+		if req.Body != nil && req.Body != http.NoBody {
+			if req.GetBody == nil {
+				return resp, nil
+			}
+			body, err := req.GetBody()
+			if err != nil {
+				return resp, nil
+			}
+			req = req.Clone(ctx)
+			req.Body = body
+		}
+		resp.Body.Close()
+
+		retryAfter := defaultRetryAfter
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+			retryAfter = time.Duration(seconds) * time.Second
+		}
+		fmt.Printf("RATE-LIMIT: %s returned 429, pausing for %s (retry %d/%d)\n", t.platform, retryAfter, attempt+1, maxRateLimitRetries)
+		t.pause(retryAfter)
+	}
+}
+
+// observe records a request in the API metrics
+func (t *platformTransport) observe(req *http.Request, resp *http.Response, err error, elapsed time.Duration) {
+	endpoint := apiEndpoint(req.URL)
+	status := "error"
+	if err == nil {
+		status = strconv.Itoa(resp.StatusCode)
+	}
+	metrics.APIRequests.Inc(t.platform, endpoint, status)
+	metrics.APIRequestDuration.Observe(elapsed.Seconds(), t.platform, endpoint)
+}
+
+// apiIDSegmentRe matches path segments that identify a single object: Asana
+// GIDs, YouTrack database IDs (2-45) and readable issue IDs (ARD-341)
+var apiIDSegmentRe = regexp.MustCompile(`^([0-9]+|[0-9]+-[0-9]+|[A-Za-z][A-Za-z0-9_]*-[0-9]+)$`)
+
+// apiEndpoint reduces a request URL to a metrics label: the path from /api/
+// on, with object IDs replaced by :id. Requests outside the API, such as
+// attachment downloads from storage, are labelled "other".
+func apiEndpoint(u *url.URL) string {
+	i := strings.Index(u.Path, "/api/")
+	if i < 0 {
+		return "other"
+	}
+	segments := strings.Split(strings.Trim(u.Path[i:], "/"), "/")
+	for j, segment := range segments {
+		if apiIDSegmentRe.MatchString(segment) {
+			segments[j] = ":id"
+		}
+	}
+	return "/" + strings.Join(segments, "/")
+}
+
+// wait blocks until the request may start, keeping starts interval apart
+func (t *platformTransport) wait(ctx context.Context) error {
+	t.mutex.Lock()
+	now := time.Now()
+	start := t.next
+	if start.Before(now) {
+		start = now
+	}
+	t.next = start.Add(t.interval)
+	t.mutex.Unlock()
+
+	delay := time.Until(start)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// pause holds back all requests to the platform for d
+func (t *platformTransport) pause(d time.Duration) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if until := time.Now().Add(d); until.After(t.next) {
+		t.next = until
+	}
+}
+
+// runPool calls work for each index in 0..n-1 on up to workers goroutines and
+// waits for them. Work that hasn't started when ctx is cancelled is skipped;
+// the caller writes results by index, so their order doesn't depend on
+// scheduling.
+func runPool(ctx context.Context, workers, n int, work func(i int)) {
+	if workers > n {
+		workers = n
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				work(i)
+			}
+		}()
+	}
+
+feed:
+	for i := 0; i < n; i++ {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(indexes)
+	wg.Wait()
+}
+
+// ticketErrors lists the failed tickets of a bulk result for the response's
+// error report
+func ticketErrors(results []map[string]interface{}, idKey string) []map[string]interface{} {
+	errors := []map[string]interface{}{}
+	for _, result := range results {
+		if result["status"] == "failed" {
+			errors = append(errors, map[string]interface{}{
+				"ticket_id": result[idKey],
+				"error":     result["error"],
+			})
+		}
+	}
+	return errors
+}
+
+// recordRunMetrics counts a bulk run and the outcome of each of its tickets
+func recordRunMetrics(operation string, userID int, results []map[string]interface{}) {
+	user := metrics.UserLabel(userID)
+	metrics.Runs.Inc(operation, user)
+	for _, result := range results {
+		status, _ := result["status"].(string)
+		if status == "" {
+			status = "unknown"
+		}
+		metrics.Tickets.Inc(operation, status, user)
+	}
+}
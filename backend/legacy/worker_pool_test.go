@@ -0,0 +1,198 @@
+package legacy
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// rateLimitedServer answers the first request with 429 and Retry-After: 1 and
+// every later one with 200, recording when each request arrived and its body
+type rateLimitedServer struct {
+	*httptest.Server
+	mutex     sync.Mutex
+	hits      []time.Time
+	bodies    []string
+	throttled chan struct{} // closed once the 429 is sent
+}
+
+func newRateLimitedServer(t *testing.T) *rateLimitedServer {
+	s := &rateLimitedServer{throttled: make(chan struct{})}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		s.mutex.Lock()
+		s.hits = append(s.hits, time.Now())
+		s.bodies = append(s.bodies, string(body))
+		first := len(s.hits) == 1
+		s.mutex.Unlock()
+
+		if first {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			close(s.throttled)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *rateLimitedServer) recorded() ([]time.Time, []string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]time.Time(nil), s.hits...), append([]string(nil), s.bodies...)
+}
+
+// Retry-After is whole seconds; allow for timer and clock slack
+const minRetryPause = 900 * time.Millisecond
+
+func TestPlatformTransportRetriesAfter429(t *testing.T) {
+	server := newRateLimitedServer(t)
+	client := &http.Client{Transport: newPlatformTransport("test", 2, 0)}
+
+	resp, err := client.Post(server.URL+"/api/issues", "application/json", strings.NewReader(`{"summary":"x"}`))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d after the retry", resp.StatusCode, http.StatusOK)
+	}
+
+	hits, bodies := server.recorded()
+	if len(hits) != 2 {
+		t.Fatalf("server got %d requests, want 2", len(hits))
+	}
+	if gap := hits[1].Sub(hits[0]); gap < minRetryPause {
+		t.Errorf("retried after %s, want at least the Retry-After of 1s", gap)
+	}
+	for i, body := range bodies {
+		if body != `{"summary":"x"}` {
+			t.Errorf("request %d body = %q, want the original body replayed", i+1, body)
+		}
+	}
+}
+
+func TestPlatformTransport429PausesWholePlatform(t *testing.T) {
+	server := newRateLimitedServer(t)
+	transport := newPlatformTransport("test", 2, 0)
+	client := &http.Client{Transport: transport}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if resp, err := client.Get(server.URL + "/api/first"); err == nil {
+			resp.Body.Close()
+		}
+	}()
+
+	// A second request started during the pause waits for it too, although
+	// it never got a 429 itself
+	<-server.throttled
+	throttledAt := time.Now()
+	for !transport.paused() {
+		time.Sleep(time.Millisecond)
+	}
+	resp, err := client.Get(server.URL + "/api/second")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+	wg.Wait()
+
+	hits, _ := server.recorded()
+	if len(hits) != 3 {
+		t.Fatalf("server got %d requests, want 3", len(hits))
+	}
+	for _, hit := range hits[1:] {
+		if gap := hit.Sub(throttledAt); gap < minRetryPause {
+			t.Errorf("request reached the server %s after the 429, want it held back by the pause", gap)
+		}
+	}
+}
+
+// paused reports whether a 429 is holding the platform's requests back
+func (t *platformTransport) paused() bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return time.Until(t.next) > minRetryPause/2
+}
+
+// onceReader is a request body http.NewRequest can't rewind
+type onceReader struct{ io.Reader }
+
+func TestPlatformTransportDoesNotRetryUnreplayableBody(t *testing.T) {
+	server := newRateLimitedServer(t)
+	client := &http.Client{Transport: newPlatformTransport("test", 2, 0)}
+
+	req, err := http.NewRequest("POST", server.URL+"/api/issues", onceReader{strings.NewReader("payload")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("status = %d, want the 429 passed through", resp.StatusCode)
+	}
+	if hits, _ := server.recorded(); len(hits) != 1 {
+		t.Errorf("server got %d requests, want 1", len(hits))
+	}
+}
+
+func TestRunPool(t *testing.T) {
+	tests := []struct {
+		name    string
+		workers int
+		n       int
+	}{
+		{"more items than workers", 3, 20},
+		{"more workers than items", 8, 2},
+		{"no items", 4, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			done := make([]int32, tt.n)
+			runPool(context.Background(), tt.workers, tt.n, func(i int) {
+				atomic.AddInt32(&done[i], 1)
+			})
+			for i, count := range done {
+				if count != 1 {
+					t.Errorf("item %d ran %d times, want once", i, count)
+				}
+			}
+		})
+	}
+}
+
+func TestRunPoolSkipsWorkAfterCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	const n = 100
+	var ran int32
+	runPool(ctx, 1, n, func(i int) {
+		atomic.AddInt32(&ran, 1)
+		if i == 4 {
+			cancel()
+		}
+	})
+
+	// The single worker may already have been handed the next index when the
+	// context was cancelled, but nothing after that
+	if got := atomic.LoadInt32(&ran); got < 5 || got > 6 {
+		t.Errorf("ran %d items, want the 5 before cancelling and at most one more", got)
+	}
+}
@@ -0,0 +1,259 @@
+package legacy
+
+import (
+	"strings"
+
+	configpkg "asana-youtrack-sync/config"
+	"asana-youtrack-sync/database"
+)
+
+// Workflow is the board a sync pair works with: its Asana columns and what
+// each one means for sync. It is built from the pair's column mappings; pairs
+// without mappings get the default columns.
+type Workflow struct {
+	Columns []WorkflowColumn `json:"columns"`
+
+	// legacyNames also matches the section name variants analysis accepted
+	// before column mappings existed (see legacyColumnName). Only the default
+	// workflow sets it, so boards that never saved mappings keep working.
+	legacyNames bool
+}
+
+// WorkflowColumn is one Asana column of a workflow
+type WorkflowColumn struct {
+	Name            string `json:"name"` // lower-cased Asana section name
+	SectionGID      string `json:"section_gid,omitempty"`
+	YouTrackState   string `json:"youtrack_state,omitempty"`
+	YouTrackStateID string `json:"youtrack_state_id,omitempty"`
+	Syncable        bool   `json:"syncable"`        // tickets are created and synced
+	DisplayOnly     bool   `json:"display_only"`    // shown by analysis, never written
+	Terminal        bool   `json:"terminal"`        // work here is finished
+	Findings        bool   `json:"findings"`        // alert while the YouTrack issue is still active
+	Blocked         bool   `json:"blocked"`         // matched tickets are reported as blocked
+	ReadyForStage   bool   `json:"ready_for_stage"` // waiting for release to stage
+}
+
+// NewWorkflow builds the workflow of the given Asana-to-YouTrack mappings
+func NewWorkflow(mappings []database.ColumnMapping) *Workflow {
+	if len(mappings) == 0 {
+		return DefaultWorkflow()
+	}
+
+	w := &Workflow{}
+	for _, m := range mappings {
+		name := normalizeColumnName(m.AsanaColumn)
+		if name == "" {
+			continue
+		}
+		displayOnly := m.DisplayOnly || m.Findings
+		w.Columns = append(w.Columns, WorkflowColumn{
+			Name:            name,
+			SectionGID:      m.AsanaSectionGID,
+			YouTrackState:   m.YouTrackStatus,
+			YouTrackStateID: m.YouTrackStateID,
+			Syncable:        !displayOnly,
+			DisplayOnly:     displayOnly,
+			Terminal:        m.Terminal,
+			Findings:        m.Findings,
+			Blocked:         m.Blocked,
+			ReadyForStage:   m.ReadyForStage,
+		})
+	}
+	return w
+}
+
+// DefaultWorkflow is the workflow of pairs without column mappings: the
+// SyncableColumns, each mapped to the YouTrack state of the same name and
+// "blocked" and "ready for stage" flagged as such, and the DisplayOnlyColumns
+func DefaultWorkflow() *Workflow {
+	w := &Workflow{legacyNames: true}
+	for _, name := range SyncableColumns {
+		w.Columns = append(w.Columns, WorkflowColumn{
+			Name:          name,
+			YouTrackState: name,
+			Syncable:      true,
+			Blocked:       name == "blocked",
+			ReadyForStage: name == "ready for stage",
+		})
+	}
+	for _, name := range DisplayOnlyColumns {
+		w.Columns = append(w.Columns, WorkflowColumn{Name: name, DisplayOnly: true, Findings: name == "findings"})
+	}
+	return w
+}
+
+// workflowFor returns the workflow of the user's current sync pair
+func workflowFor(configService *configpkg.Service, userID int) *Workflow {
+	settings, err := configService.GetSettings(userID)
+	if err != nil {
+		return DefaultWorkflow()
+	}
+	return NewWorkflow(settings.ColumnMappings.AsanaToYouTrack)
+}
+
+func normalizeColumnName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// Column returns the column with the given name, or nil
+func (w *Workflow) Column(name string) *WorkflowColumn {
+	name = normalizeColumnName(name)
+	for i := range w.Columns {
+		if w.Columns[i].Name == name {
+			return &w.Columns[i]
+		}
+	}
+	return nil
+}
+
+// ColumnOf returns the column of the task's section, or nil. Columns with a
+// pinned section GID match by GID, others by exact name, and the default
+// workflow falls back to the pre-mapping name variants.
+func (w *Workflow) ColumnOf(task AsanaTask) *WorkflowColumn {
+	if len(task.Memberships) == 0 {
+		return nil
+	}
+	section := task.Memberships[0].Section
+	name := normalizeColumnName(section.Name)
+	for i := range w.Columns {
+		c := &w.Columns[i]
+		if c.SectionGID != "" && section.GID != "" {
+			if c.SectionGID == section.GID {
+				return c
+			}
+			continue
+		}
+		if c.Name == name {
+			return c
+		}
+	}
+	if w.legacyNames {
+		return w.Column(legacyColumnName(name))
+	}
+	return nil
+}
+
+// legacyColumnName returns the default column a section name falls into
+// under the rules analysis used before column mappings, e.g. "Backlog 🚀",
+// "DEV - QA" or "In Progress (web)", or "" if it falls into none
+func legacyColumnName(section string) string {
+	switch {
+	case strings.Contains(section, "findings"):
+		return "findings"
+	case strings.Contains(section, "backlog") && !strings.Contains(section, "dev") &&
+		!strings.Contains(section, "stage") && !strings.Contains(section, "blocked") &&
+		!strings.Contains(section, "progress"):
+		return "backlog"
+	case strings.Contains(section, "progress") && !strings.Contains(section, "backlog"):
+		return "in progress"
+	case strings.Contains(section, "dev") && !strings.Contains(section, "ready"):
+		return "dev"
+	case strings.Contains(section, "stage") && !strings.Contains(section, "ready"):
+		return "stage"
+	case strings.Contains(section, "prod"):
+		return "prod"
+	case strings.Contains(section, "blocked"):
+		return "blocked"
+	case strings.Contains(section, "ready") && strings.Contains(section, "stage"):
+		return "ready for stage"
+	}
+	return ""
+}
+
+// IsSyncable reports whether the task is in a syncable column
+func (w *Workflow) IsSyncable(task AsanaTask) bool {
+	c := w.ColumnOf(task)
+	return c != nil && c.Syncable
+}
+
+// IsActiveState reports whether a YouTrack state is work in progress, that
+// is a syncable column that isn't terminal maps to it. States are compared
+// by ID where both sides have one.
+func (w *Workflow) IsActiveState(stateName, stateID string) bool {
+	for _, c := range w.Columns {
+		if !c.Syncable || c.Terminal {
+			continue
+		}
+		if c.YouTrackStateID != "" && stateID != "" {
+			if c.YouTrackStateID == stateID {
+				return true
+			}
+			continue
+		}
+		if c.YouTrackState != "" && strings.EqualFold(strings.TrimSpace(c.YouTrackState), strings.TrimSpace(stateName)) {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterTasks keeps the tasks in the named columns. A name that isn't a
+// column of the workflow selects sections with exactly that name.
+func (w *Workflow) FilterTasks(tasks []AsanaTask, columnNames []string) []AsanaTask {
+	if len(columnNames) == 0 {
+		return tasks
+	}
+
+	selected := make(map[*WorkflowColumn]bool)
+	sectionNames := make(map[string]bool)
+	for _, name := range columnNames {
+		if c := w.Column(name); c != nil {
+			selected[c] = true
+		} else {
+			sectionNames[normalizeColumnName(name)] = true
+		}
+	}
+
+	filtered := []AsanaTask{}
+	for _, task := range tasks {
+		if len(task.Memberships) == 0 {
+			continue
+		}
+		if c := w.ColumnOf(task); (c != nil && selected[c]) || sectionNames[normalizeColumnName(task.Memberships[0].Section.Name)] {
+			filtered = append(filtered, task)
+		}
+	}
+	return filtered
+}
+
+// ResolveColumns maps a ?column= parameter to the names of the columns to
+// work on: all syncable columns for "" or "all_syncable", otherwise the
+// column whose name the parameter spells with underscores for spaces
+func (w *Workflow) ResolveColumns(columnFilter string) (columns []string, mappedName string) {
+	if columnFilter == "" || columnFilter == "all_syncable" {
+		return w.SyncableColumnNames(), "all_syncable"
+	}
+	name := normalizeColumnName(strings.ReplaceAll(columnFilter, "_", " "))
+	return []string{name}, name
+}
+
+// ColumnNames lists every column of the workflow
+func (w *Workflow) ColumnNames() []string {
+	names := make([]string, 0, len(w.Columns))
+	for _, c := range w.Columns {
+		names = append(names, c.Name)
+	}
+	return names
+}
+
+// SyncableColumnNames lists the columns whose tickets are created and synced
+func (w *Workflow) SyncableColumnNames() []string {
+	names := []string{}
+	for _, c := range w.Columns {
+		if c.Syncable {
+			names = append(names, c.Name)
+		}
+	}
+	return names
+}
+
+// DisplayOnlyColumnNames lists the columns analysis only reports on
+func (w *Workflow) DisplayOnlyColumnNames() []string {
+	names := []string{}
+	for _, c := range w.Columns {
+		if c.DisplayOnly {
+			names = append(names, c.Name)
+		}
+	}
+	return names
+}
@@ -2,6 +2,7 @@ package legacy
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -22,6 +23,7 @@ const youTrackCacheTTL = 2 * time.Minute
 type YouTrackService struct {
 	configService        *config.Service
 	asanaService         *AsanaService // optional; used for email-based assignee lookup
+	db                   *database.DB  // optional; used to link mapped tasks in descriptions and resolve linked users
 	cachedIssues         map[int][]YouTrackIssue
 	cacheExpiry          map[int]time.Time
 	cacheMutex           sync.RWMutex
@@ -68,8 +70,23 @@ func (s *YouTrackService) InvalidateIssueCache(userID int) {
 	delete(s.cacheExpiry, userID)
 }
 
+// SetTicketMappings lets descriptions link Asana tasks to the YouTrack issues
+// they are mapped to
+func (s *YouTrackService) SetTicketMappings(db *database.DB) {
+	s.db = db
+}
+
+// descriptionMarkdown converts the task's rich text notes to YouTrack
+// markdown, resolving mentions and task links, or returns the plain notes
+func (s *YouTrackService) descriptionMarkdown(ctx context.Context, userID int, task AsanaTask) string {
+	if task.HTMLNotes == "" {
+		return task.Notes
+	}
+	return utils.ConvertAsanaHTMLToYouTrackMarkdownWithLinks(task.HTMLNotes, descriptionLinks(ctx, userID, s.db, s, s.asanaService))
+}
+
 // GetIssues retrieves issues from YouTrack using user settings (with 2-min cache)
-func (s *YouTrackService) GetIssues(userID int) ([]YouTrackIssue, error) {
+func (s *YouTrackService) GetIssues(ctx context.Context, userID int) ([]YouTrackIssue, error) {
 	if cached, ok := s.getCachedIssues(userID); ok {
 		fmt.Printf("YT-CACHE: Returning %d cached issues for user %d\n", len(cached), userID)
 		return cached, nil
@@ -87,7 +104,7 @@ func (s *YouTrackService) GetIssues(userID int) ([]YouTrackIssue, error) {
 	fmt.Printf("Getting YouTrack issues for user %d from project: %s\n", userID, settings.YouTrackProjectID)
 
 	// Try multiple approaches to get issues
-	approaches := []func(*config.UserSettings) ([]YouTrackIssue, error){
+	approaches := []func(context.Context, *config.UserSettings) ([]YouTrackIssue, error){
 		s.getIssuesWithProjectKey,
 		s.getIssuesWithQuery,
 		s.getIssuesSimpleCloud,
@@ -95,7 +112,7 @@ func (s *YouTrackService) GetIssues(userID int) ([]YouTrackIssue, error) {
 	}
 
 	for i, approach := range approaches {
-		issues, err := approach(settings)
+		issues, err := approach(ctx, settings)
 		if err == nil && len(issues) > 0 {
 			fmt.Printf("YT: Approach %d fetched %d issues for user %d\n", i+1, len(issues), userID)
 			s.setCachedIssues(userID, issues)
@@ -112,9 +129,9 @@ func (s *YouTrackService) GetIssues(userID int) ([]YouTrackIssue, error) {
 }
 
 // getIssuesWithProjectKey tries direct project key approach
-func (s *YouTrackService) getIssuesWithProjectKey(settings *config.UserSettings) ([]YouTrackIssue, error) {
+func (s *YouTrackService) getIssuesWithProjectKey(ctx context.Context, settings *config.UserSettings) ([]YouTrackIssue, error) {
 	query := fmt.Sprintf("project: {%s}", settings.YouTrackProjectID)
-	fields := "id,summary,description,created,updated,customFields(id,name,$type,value(name,localizedName,description,id,$type,color,fullName,ringId)),project(shortName)"
+	fields := "id,summary,description,created,updated,customFields(id,name,$type,value(name,localizedName,description,id,$type,color,fullName,login,ringId,minutes,presentation)),project(shortName)"
 
 	encodedQuery := strings.ReplaceAll(query, " ", "%20")
 	encodedQuery = strings.ReplaceAll(encodedQuery, "{", "%7B")
@@ -123,11 +140,11 @@ func (s *YouTrackService) getIssuesWithProjectKey(settings *config.UserSettings)
 	baseURL := fmt.Sprintf("%s/api/issues?fields=%s&query=%s",
 		settings.YouTrackBaseURL, fields, encodedQuery)
 
-	return s.makeRequestPaginated(settings, baseURL)
+	return s.makeRequestPaginated(ctx, settings, baseURL)
 }
 
 // getIssuesWithQuery tries query-based approach
-func (s *YouTrackService) getIssuesWithQuery(settings *config.UserSettings) ([]YouTrackIssue, error) {
+func (s *YouTrackService) getIssuesWithQuery(ctx context.Context, settings *config.UserSettings) ([]YouTrackIssue, error) {
 	queries := []string{
 		fmt.Sprintf("project: {%s}", settings.YouTrackProjectID),
 		fmt.Sprintf("project:%s", settings.YouTrackProjectID),
@@ -135,7 +152,7 @@ func (s *YouTrackService) getIssuesWithQuery(settings *config.UserSettings) ([]Y
 		fmt.Sprintf("#%s", settings.YouTrackProjectID),
 	}
 
-	fields := "id,summary,description,created,updated,customFields(id,name,$type,value(name,localizedName,description,id,$type,color,fullName,ringId)),project(shortName)"
+	fields := "id,summary,description,created,updated,customFields(id,name,$type,value(name,localizedName,description,id,$type,color,fullName,login,ringId,minutes,presentation)),project(shortName)"
 
 	for _, query := range queries {
 		encodedQuery := strings.ReplaceAll(query, " ", "%20")
@@ -147,7 +164,7 @@ func (s *YouTrackService) getIssuesWithQuery(settings *config.UserSettings) ([]Y
 		baseURL := fmt.Sprintf("%s/api/issues?fields=%s&query=%s",
 			settings.YouTrackBaseURL, fields, encodedQuery)
 
-		if issues, err := s.makeRequestPaginated(settings, baseURL); err == nil && len(issues) > 0 {
+		if issues, err := s.makeRequestPaginated(ctx, settings, baseURL); err == nil && len(issues) > 0 {
 			return issues, nil
 		}
 	}
@@ -156,25 +173,25 @@ func (s *YouTrackService) getIssuesWithQuery(settings *config.UserSettings) ([]Y
 }
 
 // getIssuesSimpleCloud tries simple issues endpoint with project filter in query
-func (s *YouTrackService) getIssuesSimpleCloud(settings *config.UserSettings) ([]YouTrackIssue, error) {
+func (s *YouTrackService) getIssuesSimpleCloud(ctx context.Context, settings *config.UserSettings) ([]YouTrackIssue, error) {
 	query := strings.ReplaceAll(fmt.Sprintf("project:%s", settings.YouTrackProjectID), " ", "%20")
-	baseURL := fmt.Sprintf("%s/api/issues?fields=id,summary,description,created,updated,customFields(id,name,$type,value(name,localizedName,description,id,$type,color,fullName,ringId)),project(shortName)&query=%s",
+	baseURL := fmt.Sprintf("%s/api/issues?fields=id,summary,description,created,updated,customFields(id,name,$type,value(name,localizedName,description,id,$type,color,fullName,login,ringId,minutes,presentation)),project(shortName)&query=%s",
 		settings.YouTrackBaseURL, query)
 
-	return s.makeRequestPaginated(settings, baseURL)
+	return s.makeRequestPaginated(ctx, settings, baseURL)
 }
 
 // getIssuesViaProjects tries project-specific endpoint
-func (s *YouTrackService) getIssuesViaProjects(settings *config.UserSettings) ([]YouTrackIssue, error) {
+func (s *YouTrackService) getIssuesViaProjects(ctx context.Context, settings *config.UserSettings) ([]YouTrackIssue, error) {
 	baseURLs := []string{
-		fmt.Sprintf("%s/api/admin/projects/%s/issues?fields=id,summary,description,created,updated,customFields(id,name,$type,value(name,localizedName,description,id,$type,color,fullName,ringId)),project(shortName)",
+		fmt.Sprintf("%s/api/admin/projects/%s/issues?fields=id,summary,description,created,updated,customFields(id,name,$type,value(name,localizedName,description,id,$type,color,fullName,login,ringId,minutes,presentation)),project(shortName)",
 			settings.YouTrackBaseURL, settings.YouTrackProjectID),
-		fmt.Sprintf("%s/api/projects/%s/issues?fields=id,summary,description,created,updated,customFields(id,name,$type,value(name,localizedName,description,id,$type,color,fullName,ringId)),project(shortName)",
+		fmt.Sprintf("%s/api/projects/%s/issues?fields=id,summary,description,created,updated,customFields(id,name,$type,value(name,localizedName,description,id,$type,color,fullName,login,ringId,minutes,presentation)),project(shortName)",
 			settings.YouTrackBaseURL, settings.YouTrackProjectID),
 	}
 
 	for _, baseURL := range baseURLs {
-		if issues, err := s.makeRequestPaginated(settings, baseURL); err == nil && len(issues) > 0 {
+		if issues, err := s.makeRequestPaginated(ctx, settings, baseURL); err == nil && len(issues) > 0 {
 			return issues, nil
 		}
 	}
@@ -183,13 +200,13 @@ func (s *YouTrackService) getIssuesViaProjects(settings *config.UserSettings) ([
 }
 
 // makeRequestPaginated fetches all pages from a YouTrack issues endpoint using $skip
-func (s *YouTrackService) makeRequestPaginated(settings *config.UserSettings, baseURL string) ([]YouTrackIssue, error) {
+func (s *YouTrackService) makeRequestPaginated(ctx context.Context, settings *config.UserSettings, baseURL string) ([]YouTrackIssue, error) {
 	var all []YouTrackIssue
 	pageSize := 500
 	skip := 0
 	for {
 		url := fmt.Sprintf("%s&$top=%d&$skip=%d", baseURL, pageSize, skip)
-		page, err := s.makeRequest(settings, url)
+		page, err := s.makeRequest(ctx, settings, url)
 		if err != nil {
 			if skip == 0 {
 				return nil, err // first page failed — real error
@@ -207,8 +224,8 @@ func (s *YouTrackService) makeRequestPaginated(settings *config.UserSettings, ba
 }
 
 // makeRequest makes HTTP request to YouTrack API
-func (s *YouTrackService) makeRequest(settings *config.UserSettings, url string) ([]YouTrackIssue, error) {
-	req, err := http.NewRequest("GET", url, nil)
+func (s *YouTrackService) makeRequest(ctx context.Context, settings *config.UserSettings, url string) ([]YouTrackIssue, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -217,7 +234,7 @@ func (s *YouTrackService) makeRequest(settings *config.UserSettings, url string)
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("Cache-Control", "no-cache")
 
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := &http.Client{Timeout: 30 * time.Second, Transport: youtrackTransport}
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("network error: %w", err)
@@ -239,7 +256,7 @@ func (s *YouTrackService) makeRequest(settings *config.UserSettings, url string)
 }
 
 // CreateIssue creates a new YouTrack issue
-func (s *YouTrackService) CreateIssue(userID int, task AsanaTask) error {
+func (s *YouTrackService) CreateIssue(ctx context.Context, userID int, task AsanaTask) error {
 	settings, err := s.configService.GetSettings(userID)
 	if err != nil {
 		return fmt.Errorf("failed to get user settings: %w", err)
@@ -264,10 +281,7 @@ func (s *YouTrackService) CreateIssue(userID int, task AsanaTask) error {
 	sanitizedTitle := utils.SanitizeTitle(task.Name)
 
 	// Convert HTML notes to YouTrack markdown if available, otherwise use plain notes
-	description := task.Notes
-	if task.HTMLNotes != "" {
-		description = utils.ConvertAsanaHTMLToYouTrackMarkdown(task.HTMLNotes)
-	}
+	description := s.descriptionMarkdown(ctx, userID, task)
 
 	payload := map[string]interface{}{
 		"$type":       "Issue",
@@ -300,7 +314,7 @@ func (s *YouTrackService) CreateIssue(userID int, task AsanaTask) error {
 		subsystem := tagMapper.MapTagToSubsystem(primaryTag)
 		if subsystem != "" {
 			// Get the subsystem field and value IDs from YouTrack
-			fieldID, valueID, err := s.GetSubsystemFieldInfo(userID, subsystem)
+			fieldID, valueID, err := s.GetSubsystemFieldInfo(ctx, userID, subsystem)
 			if err != nil {
 				fmt.Printf("Warning: Failed to get subsystem field info for '%s': %v\n", subsystem, err)
 				// Don't fail the whole operation, just skip the subsystem field
@@ -325,7 +339,7 @@ func (s *YouTrackService) CreateIssue(userID int, task AsanaTask) error {
 	}
 
 	// Create the issue and get the ID
-	issueID, err := s.createIssueAndGetID(settings, payload)
+	issueID, err := s.createIssueAndGetID(ctx, settings, payload)
 	if err != nil {
 		return err
 	}
@@ -334,16 +348,19 @@ func (s *YouTrackService) CreateIssue(userID int, task AsanaTask) error {
 
 	// Auto-assign agile board if configured
 	if settings.YouTrackBoardID != "" {
-		if err := s.assignIssueToAgileBoard(settings, issueID); err != nil {
+		if err := s.assignIssueToAgileBoard(ctx, settings, nil, issueID, taskSprint(settings.SprintConfig, task)); err != nil {
 			fmt.Printf("Warning: Failed to assign issue to agile board: %v\n", err)
 			// Don't fail the whole operation if agile board assignment fails
 		}
 	}
 
+	s.estimateFromTask(ctx, userID, settings, issueID, task)
+	s.storyPointsFromTask(ctx, userID, settings, issueID, task)
+
 	// Process attachments - download from Asana and upload to YouTrack
 	if len(task.Attachments) > 0 {
 		asanaService := NewAsanaService(s.configService)
-		if err := s.ProcessAttachments(userID, issueID, task, asanaService); err != nil {
+		if err := s.ProcessAttachments(ctx, userID, issueID, task, asanaService); err != nil {
 			fmt.Printf("Warning: Failed to process attachments: %v\n", err)
 			// Don't fail the whole operation if attachment processing fails
 		}
@@ -353,7 +370,7 @@ func (s *YouTrackService) CreateIssue(userID int, task AsanaTask) error {
 }
 
 // CreateIssueWithReturn creates a new YouTrack issue and returns the issue ID
-func (s *YouTrackService) CreateIssueWithReturn(userID int, task AsanaTask) (string, error) {
+func (s *YouTrackService) CreateIssueWithReturn(ctx context.Context, userID int, task AsanaTask) (string, error) {
 	settings, err := s.configService.GetSettings(userID)
 	if err != nil {
 		return "", fmt.Errorf("failed to get user settings: %w", err)
@@ -378,10 +395,7 @@ func (s *YouTrackService) CreateIssueWithReturn(userID int, task AsanaTask) (str
 	sanitizedTitle := utils.SanitizeTitle(task.Name)
 
 	// Convert HTML notes to YouTrack markdown if available, otherwise use plain notes
-	description := task.Notes
-	if task.HTMLNotes != "" {
-		description = utils.ConvertAsanaHTMLToYouTrackMarkdown(task.HTMLNotes)
-	}
+	description := s.descriptionMarkdown(ctx, userID, task)
 
 	payload := map[string]interface{}{
 		"$type":       "Issue",
@@ -414,7 +428,7 @@ func (s *YouTrackService) CreateIssueWithReturn(userID int, task AsanaTask) (str
 		subsystem := tagMapper.MapTagToSubsystem(primaryTag)
 		if subsystem != "" {
 			// Get the subsystem field and value IDs from YouTrack
-			fieldID, valueID, err := s.GetSubsystemFieldInfo(userID, subsystem)
+			fieldID, valueID, err := s.GetSubsystemFieldInfo(ctx, userID, subsystem)
 			if err != nil {
 				fmt.Printf("Warning: Failed to get subsystem field info for '%s': %v\n", subsystem, err)
 				// Don't fail the whole operation, just skip the subsystem field
@@ -436,11 +450,11 @@ func (s *YouTrackService) CreateIssueWithReturn(userID int, task AsanaTask) (str
 
 	// Set assignee from Asana task (best-effort)
 	if task.Assignee.Name != "" {
-		ytUser, resolveErr := s.ResolveYouTrackUserByName(userID, task.Assignee.Name, task.Assignee.GID)
+		ytUser, resolveErr := s.ResolveYouTrackUserByName(ctx, userID, task.Assignee.Name, task.Assignee.GID)
 		if resolveErr != nil {
 			fmt.Printf("Warning: Could not resolve assignee '%s': %v\n", task.Assignee.Name, resolveErr)
 		} else {
-			assigneeFieldID, fieldErr := s.GetAssigneeFieldID(userID)
+			assigneeFieldID, fieldErr := s.GetAssigneeFieldID(ctx, userID)
 			if fieldErr != nil {
 				fmt.Printf("Warning: Could not discover assignee field ID: %v\n", fieldErr)
 			} else {
@@ -461,7 +475,7 @@ func (s *YouTrackService) CreateIssueWithReturn(userID int, task AsanaTask) (str
 	}
 
 	// Create the issue and get the ID
-	issueID, err := s.createIssueAndGetID(settings, payload)
+	issueID, err := s.createIssueAndGetID(ctx, settings, payload)
 	if err != nil {
 		return "", err
 	}
@@ -473,16 +487,19 @@ func (s *YouTrackService) CreateIssueWithReturn(userID int, task AsanaTask) (str
 
 	// Auto-assign agile board if configured
 	if settings.YouTrackBoardID != "" {
-		if err := s.assignIssueToAgileBoard(settings, issueID); err != nil {
+		if err := s.assignIssueToAgileBoard(ctx, settings, nil, issueID, taskSprint(settings.SprintConfig, task)); err != nil {
 			fmt.Printf("Warning: Failed to assign issue to agile board: %v\n", err)
 			// Don't fail the whole operation if agile board assignment fails
 		}
 	}
 
+	s.estimateFromTask(ctx, userID, settings, issueID, task)
+	s.storyPointsFromTask(ctx, userID, settings, issueID, task)
+
 	// Process attachments - download from Asana and upload to YouTrack
 	if len(task.Attachments) > 0 {
 		asanaService := NewAsanaService(s.configService)
-		if err := s.ProcessAttachments(userID, issueID, task, asanaService); err != nil {
+		if err := s.ProcessAttachments(ctx, userID, issueID, task, asanaService); err != nil {
 			fmt.Printf("Warning: Failed to process attachments: %v\n", err)
 			// Don't fail the whole operation if attachment processing fails
 		}
@@ -492,7 +509,7 @@ func (s *YouTrackService) CreateIssueWithReturn(userID int, task AsanaTask) (str
 }
 
 // Helper method to create issue and return its ID
-func (s *YouTrackService) createIssueAndGetID(settings *config.UserSettings, payload map[string]interface{}) (string, error) {
+func (s *YouTrackService) createIssueAndGetID(ctx context.Context, settings *config.UserSettings, payload map[string]interface{}) (string, error) {
 	jsonPayload, err := json.Marshal(payload)
 	if err != nil {
 		return "", fmt.Errorf("failed to marshal payload: %w", err)
@@ -500,7 +517,7 @@ func (s *YouTrackService) createIssueAndGetID(settings *config.UserSettings, pay
 
 	url := fmt.Sprintf("%s/api/issues", settings.YouTrackBaseURL)
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonPayload))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonPayload))
 	if err != nil {
 		return "", fmt.Errorf("failed to create request: %w", err)
 	}
@@ -509,7 +526,7 @@ func (s *YouTrackService) createIssueAndGetID(settings *config.UserSettings, pay
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
 
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := &http.Client{Timeout: 30 * time.Second, Transport: youtrackTransport}
 	resp, err := client.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("request failed: %w", err)
@@ -531,7 +548,7 @@ func (s *YouTrackService) createIssueAndGetID(settings *config.UserSettings, pay
 				}
 				payload["fields"] = filteredFields
 			}
-			return s.createIssueAndGetID(settings, payload)
+			return s.createIssueAndGetID(ctx, settings, payload)
 		}
 		return "", fmt.Errorf("youtrack API error: %d - %s", resp.StatusCode, bodyStr)
 	}
@@ -549,86 +566,44 @@ func (s *YouTrackService) createIssueAndGetID(settings *config.UserSettings, pay
 	return createdIssue.ID, nil
 }
 
-// assignIssueToAgileBoard assigns an issue to the configured agile board using YouTrack commands API
-func (s *YouTrackService) assignIssueToAgileBoard(settings *config.UserSettings, issueID string) error {
-	// First, get the agile board details to get board name and sprints
-	url := fmt.Sprintf("%s/api/agiles/%s?fields=id,name,sprints(id,name,archived,finish,start)",
-		settings.YouTrackBaseURL,
-		settings.YouTrackBoardID)
-
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("Authorization", "Bearer "+settings.YouTrackToken)
-	req.Header.Set("Accept", "application/json")
-
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, _ := io.ReadAll(resp.Body)
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to get agile board: %d - %s", resp.StatusCode, string(body))
-	}
-
-	var agileBoard map[string]interface{}
-	if err := json.Unmarshal(body, &agileBoard); err != nil {
-		return fmt.Errorf("failed to parse agile board: %w", err)
-	}
-
-	// Get the board name
-	boardName, ok := agileBoard["name"].(string)
-	if !ok || boardName == "" {
-		return fmt.Errorf("could not get agile board name")
+// assignIssueToAgileBoard assigns an issue to the configured agile board using
+// the YouTrack commands API. A mapped sprint moves the issue to that sprint;
+// otherwise it goes to the first non-archived sprint. The board is fetched
+// unless a bulk run passes the one it loaded (see syncBoard).
+func (s *YouTrackService) assignIssueToAgileBoard(ctx context.Context, settings *config.UserSettings, board *agileBoard, issueID, sprint string) error {
+	if board == nil {
+		var err error
+		if board, err = s.getAgileBoard(ctx, settings, sprint != ""); err != nil {
+			return err
+		}
 	}
 
-	// Get sprints from the agile board
-	sprints, ok := agileBoard["sprints"].([]interface{})
-	if !ok || len(sprints) == 0 {
-		// If no sprints, just add the issue to the agile board without a specific sprint
-		return s.addIssueToAgileBoardUsingCommand(settings, issueID, boardName, "")
+	if sprint != "" {
+		if target := board.sprint(sprint); target != nil {
+			return s.moveIssueToSprint(ctx, settings, board, issueID, target)
+		}
+		fmt.Printf("Warning: Sprint '%s' not found on agile board '%s'\n", sprint, board.Name)
 	}
 
 	// Find the first non-archived sprint
-	var targetSprintName string
-	for _, sprintInterface := range sprints {
-		sprint, ok := sprintInterface.(map[string]interface{})
-		if !ok {
-			continue
+	for _, sp := range board.Sprints {
+		if !sp.Archived && sp.Name != "" {
+			return s.addIssueToAgileBoardUsingCommand(ctx, settings, issueID, board.Name, sp.Name)
 		}
-		archived, _ := sprint["archived"].(bool)
-		if !archived {
-			sprintName, _ := sprint["name"].(string)
-			if sprintName != "" {
-				targetSprintName = sprintName
-				break
-			}
-		}
-	}
-
-	if targetSprintName == "" {
-		// No active sprint found, add to board without sprint
-		return s.addIssueToAgileBoardUsingCommand(settings, issueID, boardName, "")
 	}
 
-	// Add the issue to the agile board and sprint using the commands API
-	return s.addIssueToAgileBoardUsingCommand(settings, issueID, boardName, targetSprintName)
+	// No active sprint found, add to board without sprint
+	return s.addIssueToAgileBoardUsingCommand(ctx, settings, issueID, board.Name, "")
 }
 
 // addIssueToAgileBoardUsingCommand adds an issue to agile board using YouTrack's commands API
-func (s *YouTrackService) addIssueToAgileBoardUsingCommand(settings *config.UserSettings, issueID, boardName, sprintName string) error {
+func (s *YouTrackService) addIssueToAgileBoardUsingCommand(ctx context.Context, settings *config.UserSettings, issueID, boardName, sprintName string) error {
 	// Build the command string
 	var command string
 	if sprintName != "" {
-		command = fmt.Sprintf("add Board %s %s", boardName, sprintName)
+		command = fmt.Sprintf("add Board %s %s", commandName(boardName), commandName(sprintName))
 	} else {
-		command = fmt.Sprintf("add Board %s", boardName)
+		command = fmt.Sprintf("add Board %s", commandName(boardName))
 	}
 
 	// Use the YouTrack commands API
@@ -648,7 +623,7 @@ func (s *YouTrackService) addIssueToAgileBoardUsingCommand(settings *config.User
 		return fmt.Errorf("failed to marshal command payload: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", commandURL, bytes.NewBuffer(jsonPayload))
+	req, err := http.NewRequestWithContext(ctx, "POST", commandURL, bytes.NewBuffer(jsonPayload))
 	if err != nil {
 		return fmt.Errorf("failed to create command request: %w", err)
 	}
@@ -657,7 +632,7 @@ func (s *YouTrackService) addIssueToAgileBoardUsingCommand(settings *config.User
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
 
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := &http.Client{Timeout: 30 * time.Second, Transport: youtrackTransport}
 	commandResp, err := client.Do(req)
 	if err != nil {
 		return fmt.Errorf("command request failed: %w", err)
@@ -679,14 +654,27 @@ func (s *YouTrackService) addIssueToAgileBoardUsingCommand(settings *config.User
 }
 
 // UpdateIssue updates an existing YouTrack issue
-func (s *YouTrackService) UpdateIssue(userID int, issueID string, task AsanaTask) error {
+func (s *YouTrackService) UpdateIssue(ctx context.Context, userID int, issueID string, task AsanaTask) error {
+	state := NewAsanaService(s.configService).MapStateToYouTrackWithSettings(userID, task)
+	return s.UpdateIssueWithState(ctx, userID, issueID, task, state)
+}
+
+// UpdateIssueWithState updates an existing YouTrack issue from the task like
+// UpdateIssue, but moves it to the given state instead of the one the task's
+// column maps to
+func (s *YouTrackService) UpdateIssueWithState(ctx context.Context, userID int, issueID string, task AsanaTask, state string) error {
+	return s.updateIssue(ctx, userID, issueID, task, state, nil)
+}
+
+// updateIssue is UpdateIssueWithState with the agile board of a bulk run, or
+// nil to fetch it when the issue's board membership is synced
+func (s *YouTrackService) updateIssue(ctx context.Context, userID int, issueID string, task AsanaTask, state string, board *agileBoard) error {
 	settings, err := s.configService.GetSettings(userID)
 	if err != nil {
 		return fmt.Errorf("failed to get user settings: %w", err)
 	}
 
 	asanaService := NewAsanaService(s.configService)
-	state := asanaService.MapStateToYouTrackWithSettings(userID, task)
 
 	// Check if column is display-only or unmapped
 	if state == "DISPLAY_ONLY" {
@@ -700,10 +688,7 @@ func (s *YouTrackService) UpdateIssue(userID int, issueID string, task AsanaTask
 	sanitizedTitle := utils.SanitizeTitle(task.Name)
 
 	// Convert HTML notes to YouTrack markdown if available, otherwise use plain notes
-	description := task.Notes
-	if task.HTMLNotes != "" {
-		description = utils.ConvertAsanaHTMLToYouTrackMarkdown(task.HTMLNotes)
-	}
+	description := s.descriptionMarkdown(ctx, userID, task)
 
 	payload := map[string]interface{}{
 		"$type":       "Issue",
@@ -732,7 +717,7 @@ func (s *YouTrackService) UpdateIssue(userID int, issueID string, task AsanaTask
 		subsystem := tagMapper.MapTagToSubsystem(primaryTag)
 		if subsystem != "" {
 			// Get the subsystem field and value IDs from YouTrack
-			fieldID, valueID, err := s.GetSubsystemFieldInfo(userID, subsystem)
+			fieldID, valueID, err := s.GetSubsystemFieldInfo(ctx, userID, subsystem)
 			if err != nil {
 				fmt.Printf("Warning: Failed to get subsystem field info for '%s': %v\n", subsystem, err)
 				// Don't fail the whole operation, just skip the subsystem field
@@ -754,11 +739,11 @@ func (s *YouTrackService) UpdateIssue(userID int, issueID string, task AsanaTask
 
 	// Set assignee from Asana task (best-effort, mirrors subsystem pattern)
 	if task.Assignee.Name != "" {
-		ytUser, resolveErr := s.ResolveYouTrackUserByName(userID, task.Assignee.Name, task.Assignee.GID)
+		ytUser, resolveErr := s.ResolveYouTrackUserByName(ctx, userID, task.Assignee.Name, task.Assignee.GID)
 		if resolveErr != nil {
 			fmt.Printf("Warning: Could not resolve assignee '%s': %v\n", task.Assignee.Name, resolveErr)
 		} else {
-			assigneeFieldID, fieldErr := s.GetAssigneeFieldID(userID)
+			assigneeFieldID, fieldErr := s.GetAssigneeFieldID(ctx, userID)
 			if fieldErr != nil {
 				fmt.Printf("Warning: Could not discover assignee field ID: %v\n", fieldErr)
 			} else {
@@ -778,13 +763,15 @@ func (s *YouTrackService) UpdateIssue(userID int, issueID string, task AsanaTask
 		payload["fields"] = customFields
 	}
 
-	if err := s.createOrUpdateIssue(settings, issueID, payload); err != nil {
+	if err := s.createOrUpdateIssue(ctx, settings, issueID, payload); err != nil {
 		return err
 	}
 
-	// Add to configured board if sync_board_membership is enabled (additive, idempotent)
-	if settings.SyncBoardMembership && settings.YouTrackBoardID != "" {
-		if err := s.assignIssueToAgileBoard(settings, issueID); err != nil {
+	// Add to configured board if sync_board_membership is enabled (additive,
+	// idempotent), and move to the task's sprint if it maps to one
+	sprint := taskSprint(settings.SprintConfig, task)
+	if (settings.SyncBoardMembership || sprint != "") && settings.YouTrackBoardID != "" {
+		if err := s.assignIssueToAgileBoard(ctx, settings, board, issueID, sprint); err != nil {
 			fmt.Printf("Warning: Failed to add issue %s to board: %v\n", issueID, err)
 		}
 	}
@@ -795,7 +782,7 @@ func (s *YouTrackService) UpdateIssue(userID int, issueID string, task AsanaTask
 }
 
 // UpdateIssueStatus updates only the status of a YouTrack issue (for rollback)
-func (s *YouTrackService) UpdateIssueStatus(userID int, issueID, status string) error {
+func (s *YouTrackService) UpdateIssueStatus(ctx context.Context, userID int, issueID, status string) error {
 	settings, err := s.configService.GetSettings(userID)
 	if err != nil {
 		return fmt.Errorf("failed to get user settings: %w", err)
@@ -815,11 +802,11 @@ func (s *YouTrackService) UpdateIssueStatus(userID int, issueID, status string)
 		},
 	}
 
-	return s.createOrUpdateIssue(settings, issueID, payload)
+	return s.createOrUpdateIssue(ctx, settings, issueID, payload)
 }
 
 // DeleteIssue deletes a YouTrack issue
-func (s *YouTrackService) DeleteIssue(userID int, issueID string) error {
+func (s *YouTrackService) DeleteIssue(ctx context.Context, userID int, issueID string) error {
 	settings, err := s.configService.GetSettings(userID)
 	if err != nil {
 		return fmt.Errorf("failed to get user settings: %w", err)
@@ -831,7 +818,7 @@ func (s *YouTrackService) DeleteIssue(userID int, issueID string) error {
 
 	url := fmt.Sprintf("%s/api/issues/%s", settings.YouTrackBaseURL, issueID)
 
-	req, err := http.NewRequest("DELETE", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "DELETE", url, nil)
 	if err != nil {
 		return fmt.Errorf("failed to create delete request: %w", err)
 	}
@@ -839,7 +826,7 @@ func (s *YouTrackService) DeleteIssue(userID int, issueID string) error {
 	req.Header.Set("Authorization", "Bearer "+settings.YouTrackToken)
 	req.Header.Set("Accept", "application/json")
 
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := &http.Client{Timeout: 30 * time.Second, Transport: youtrackTransport}
 	resp, err := client.Do(req)
 	if err != nil {
 		return fmt.Errorf("delete request failed: %w", err)
@@ -856,7 +843,7 @@ func (s *YouTrackService) DeleteIssue(userID int, issueID string) error {
 }
 
 // createOrUpdateIssue creates or updates a YouTrack issue
-func (s *YouTrackService) createOrUpdateIssue(settings *config.UserSettings, issueID string, payload map[string]interface{}) error {
+func (s *YouTrackService) createOrUpdateIssue(ctx context.Context, settings *config.UserSettings, issueID string, payload map[string]interface{}) error {
 	jsonPayload, err := json.Marshal(payload)
 	if err != nil {
 		return fmt.Errorf("failed to marshal payload: %w", err)
@@ -869,7 +856,7 @@ func (s *YouTrackService) createOrUpdateIssue(settings *config.UserSettings, iss
 		url = fmt.Sprintf("%s/api/issues/%s", settings.YouTrackBaseURL, issueID)
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonPayload))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonPayload))
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
@@ -878,7 +865,7 @@ func (s *YouTrackService) createOrUpdateIssue(settings *config.UserSettings, iss
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
 
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := &http.Client{Timeout: 30 * time.Second, Transport: youtrackTransport}
 	resp, err := client.Do(req)
 	if err != nil {
 		return fmt.Errorf("request failed: %w", err)
@@ -890,7 +877,7 @@ func (s *YouTrackService) createOrUpdateIssue(settings *config.UserSettings, iss
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
 		bodyStr := string(body)
 		if strings.Contains(bodyStr, "incompatible-issue-custom-field-name-Subsystem") {
-			return s.createOrUpdateIssueWithoutSubsystem(settings, issueID, payload)
+			return s.createOrUpdateIssueWithoutSubsystem(ctx, settings, issueID, payload)
 		}
 		return fmt.Errorf("youtrack API error: %d - %s", resp.StatusCode, bodyStr)
 	}
@@ -899,7 +886,7 @@ func (s *YouTrackService) createOrUpdateIssue(settings *config.UserSettings, iss
 }
 
 // createOrUpdateIssueWithoutSubsystem fallback for systems without Subsystem field
-func (s *YouTrackService) createOrUpdateIssueWithoutSubsystem(settings *config.UserSettings, issueID string, payload map[string]interface{}) error {
+func (s *YouTrackService) createOrUpdateIssueWithoutSubsystem(ctx context.Context, settings *config.UserSettings, issueID string, payload map[string]interface{}) error {
 	// Remove subsystem from custom fields
 	if customFields, ok := payload["fields"].([]map[string]interface{}); ok {
 		var filteredFields []map[string]interface{}
@@ -923,7 +910,7 @@ func (s *YouTrackService) createOrUpdateIssueWithoutSubsystem(settings *config.U
 		url = fmt.Sprintf("%s/api/issues/%s", settings.YouTrackBaseURL, issueID)
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonPayload))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonPayload))
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
@@ -932,7 +919,7 @@ func (s *YouTrackService) createOrUpdateIssueWithoutSubsystem(settings *config.U
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
 
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := &http.Client{Timeout: 30 * time.Second, Transport: youtrackTransport}
 	resp, err := client.Do(req)
 	if err != nil {
 		return fmt.Errorf("request failed: %w", err)
@@ -950,7 +937,7 @@ func (s *YouTrackService) createOrUpdateIssueWithoutSubsystem(settings *config.U
 }
 
 // IsDuplicateTicket checks if a ticket with the given title already exists
-func (s *YouTrackService) IsDuplicateTicket(userID int, title string) bool {
+func (s *YouTrackService) IsDuplicateTicket(ctx context.Context, userID int, title string) bool {
 	settings, err := s.configService.GetSettings(userID)
 	if err != nil {
 		return false
@@ -962,7 +949,7 @@ func (s *YouTrackService) IsDuplicateTicket(userID int, title string) bool {
 	url := fmt.Sprintf("%s/api/issues?fields=id,summary&query=%s&top=5",
 		settings.YouTrackBaseURL, encodedQuery)
 
-	issues, err := s.makeRequest(settings, url)
+	issues, err := s.makeRequest(ctx, settings, url)
 	if err != nil {
 		return false
 	}
@@ -1003,6 +990,20 @@ func (s *YouTrackService) GetStatus(issue YouTrackIssue) string {
 	return "Unknown"
 }
 
+// GetStateID returns the ID of the issue's State value, or "" if it has none
+func (s *YouTrackService) GetStateID(issue YouTrackIssue) string {
+	for _, field := range issue.CustomFields {
+		if field.Name == "State" {
+			if value, ok := field.Value.(map[string]interface{}); ok {
+				if id, ok := value["id"].(string); ok {
+					return id
+				}
+			}
+		}
+	}
+	return ""
+}
+
 // GetPriority reads the Priority custom field value from a YouTrack issue.
 // Returns "" if the field is absent or has no value.
 func (s *YouTrackService) GetPriority(issue YouTrackIssue) string {
@@ -1023,7 +1024,7 @@ func (s *YouTrackService) GetPriority(issue YouTrackIssue) string {
 
 // SyncPriority sets the Priority custom field on a YouTrack issue via the Commands API.
 // priorityName should match the bundle element name in YouTrack (e.g. "P1", "A3").
-func (s *YouTrackService) SyncPriority(settings *config.UserSettings, issueID, priorityName string) error {
+func (s *YouTrackService) SyncPriority(ctx context.Context, settings *config.UserSettings, issueID, priorityName string) error {
 	commandURL := fmt.Sprintf("%s/api/commands", settings.YouTrackBaseURL)
 	payload := map[string]interface{}{
 		"query": fmt.Sprintf("Priority %s", priorityName),
@@ -1035,7 +1036,7 @@ func (s *YouTrackService) SyncPriority(settings *config.UserSettings, issueID, p
 	if err != nil {
 		return fmt.Errorf("marshal failed: %w", err)
 	}
-	req, err := http.NewRequest("POST", commandURL, bytes.NewBuffer(jsonPayload))
+	req, err := http.NewRequestWithContext(ctx, "POST", commandURL, bytes.NewBuffer(jsonPayload))
 	if err != nil {
 		return fmt.Errorf("request creation failed: %w", err)
 	}
@@ -1043,7 +1044,7 @@ func (s *YouTrackService) SyncPriority(settings *config.UserSettings, issueID, p
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
 
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := &http.Client{Timeout: 30 * time.Second, Transport: youtrackTransport}
 	resp, err := client.Do(req)
 	if err != nil {
 		return fmt.Errorf("command request failed: %w", err)
@@ -1057,41 +1058,6 @@ func (s *YouTrackService) SyncPriority(settings *config.UserSettings, issueID, p
 	return nil
 }
 
-// GetStatusNormalized gets the status and normalizes it for comparison
-// This handles different variations of the same status
-func (s *YouTrackService) GetStatusNormalized(issue YouTrackIssue) string {
-	status := s.GetStatus(issue)
-
-	// Normalize common variations
-	statusLower := strings.ToLower(strings.TrimSpace(status))
-
-	// Map common variations to standard names
-	statusMap := map[string]string{
-		"backlog":     "Backlog",
-		"open":        "Backlog",
-		"to do":       "Backlog",
-		"todo":        "Backlog",
-		"in progress": "In Progress",
-		"inprogress":  "In Progress",
-		"in-progress": "In Progress",
-		"dev":         "DEV",
-		"development": "DEV",
-		"in dev":      "DEV",
-		"stage":       "STAGE",
-		"staging":     "STAGE",
-		"in stage":    "STAGE",
-		"blocked":     "Blocked",
-		"on hold":     "Blocked",
-	}
-
-	if normalized, exists := statusMap[statusLower]; exists {
-		return normalized
-	}
-
-	// Return original if no mapping found
-	return status
-}
-
 // GetAssignee extracts the assignee fullName from a YouTrack issue's custom fields
 func (s *YouTrackService) GetAssignee(issue YouTrackIssue) string {
 	for _, field := range issue.CustomFields {
@@ -1110,8 +1076,48 @@ func (s *YouTrackService) GetAssignee(issue YouTrackIssue) string {
 	return ""
 }
 
+// GetAssignees returns the users in an issue's Assignee field, which may
+// hold one user or, on multi-user fields, several
+func (s *YouTrackService) GetAssignees(issue YouTrackIssue) []YouTrackUser {
+	toUser := func(v interface{}) (YouTrackUser, bool) {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return YouTrackUser{}, false
+		}
+		u := YouTrackUser{}
+		u.ID, _ = m["id"].(string)
+		u.Login, _ = m["login"].(string)
+		u.RingID, _ = m["ringId"].(string)
+		u.FullName, _ = m["fullName"].(string)
+		if u.FullName == "" {
+			u.FullName, _ = m["name"].(string)
+		}
+		return u, u.ID != "" || u.FullName != ""
+	}
+
+	var users []YouTrackUser
+	for _, field := range issue.CustomFields {
+		if field.Name != "Assignee" {
+			continue
+		}
+		switch value := field.Value.(type) {
+		case map[string]interface{}:
+			if u, ok := toUser(value); ok {
+				users = append(users, u)
+			}
+		case []interface{}:
+			for _, v := range value {
+				if u, ok := toUser(v); ok {
+					users = append(users, u)
+				}
+			}
+		}
+	}
+	return users
+}
+
 // GetAssigneeFieldID dynamically discovers the Assignee custom field ID from cached issues
-func (s *YouTrackService) GetAssigneeFieldID(userID int) (string, error) {
+func (s *YouTrackService) GetAssigneeFieldID(ctx context.Context, userID int) (string, error) {
 	s.cacheMutex.RLock()
 	if id, ok := s.assigneeFieldIDCache[userID]; ok {
 		s.cacheMutex.RUnlock()
@@ -1119,7 +1125,7 @@ func (s *YouTrackService) GetAssigneeFieldID(userID int) (string, error) {
 	}
 	s.cacheMutex.RUnlock()
 
-	issues, err := s.GetIssues(userID)
+	issues, err := s.GetIssues(ctx, userID)
 	if err != nil {
 		return "", fmt.Errorf("cannot discover assignee field: %w", err)
 	}
@@ -1154,16 +1160,38 @@ func assigneeNamesMatch(asanaName, ytName string) bool {
 }
 
 // ResolveYouTrackUserByName finds a YouTrack user matching the given Asana assignee.
-// Match priority:
-//  1. Exact case-insensitive full name ("Parv Bajaj" == "Parv Bajaj")
-//  2. First-name match          ("Parv Bajaj" first word == "Parv" first word)
-//  3. Email match               (requires asanaGID so we can fetch email from Asana)
-func (s *YouTrackService) ResolveYouTrackUserByName(userID int, asanaName string, asanaGID ...string) (*YouTrackUser, error) {
-	users, err := s.GetAllUsers(userID)
+// Once the user directory has been pulled, only linked identities match, so
+// the result doesn't depend on who happens to share a name. Before that,
+// match priority:
+//  1. Linked identity           (requires asanaGID; see user_identities)
+//  2. Exact case-insensitive full name ("Parv Bajaj" == "Parv Bajaj")
+//  3. First-name match          ("Parv Bajaj" first word == "Parv" first word),
+//     only while no identities are linked
+//  4. Email match               (requires asanaGID so we can fetch email from Asana)
+func (s *YouTrackService) ResolveYouTrackUserByName(ctx context.Context, userID int, asanaName string, asanaGID ...string) (*YouTrackUser, error) {
+	users, err := s.GetAllUsers(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
 
+	gid := ""
+	if len(asanaGID) > 0 {
+		gid = asanaGID[0]
+	}
+	identities := loadIdentities(s.db, userID)
+	if identity := identities.youTrackFor(gid); identity != nil {
+		for i, u := range users {
+			if u.ID == identity.YouTrackUserID {
+				fmt.Printf("ASSIGNEE: identity match '%s' → YT user '%s'\n", asanaName, u.FullName)
+				return &users[i], nil
+			}
+		}
+		return nil, fmt.Errorf("YouTrack user %s linked to '%s' no longer exists", identity.YouTrackLogin, asanaName)
+	}
+	if identities.hasDirectory() {
+		return nil, fmt.Errorf("Asana user '%s' is not linked to a YouTrack user", asanaName)
+	}
+
 	needle := strings.ToLower(strings.TrimSpace(asanaName))
 	asanaFirstName := ""
 	if parts := strings.Fields(needle); len(parts) > 0 {
@@ -1174,13 +1202,13 @@ func (s *YouTrackService) ResolveYouTrackUserByName(userID int, asanaName string
 
 	for i, u := range users {
 		ytName := strings.ToLower(strings.TrimSpace(u.FullName))
-		// Priority 1: exact full name
+		// Priority 2: exact full name
 		if ytName == needle {
 			fmt.Printf("ASSIGNEE: exact match '%s' → YT user '%s'\n", asanaName, u.FullName)
 			return &users[i], nil
 		}
-		// Priority 2: first-name match (keep scanning for an exact match)
-		if firstNameMatch == nil && asanaFirstName != "" {
+		// Priority 3: first-name match (keep scanning for an exact match)
+		if firstNameMatch == nil && asanaFirstName != "" && identities.empty() {
 			ytParts := strings.Fields(ytName)
 			if len(ytParts) > 0 && ytParts[0] == asanaFirstName {
 				firstNameMatch = &users[i]
@@ -1193,13 +1221,9 @@ func (s *YouTrackService) ResolveYouTrackUserByName(userID int, asanaName string
 		return firstNameMatch, nil
 	}
 
-	// Priority 3: email match (only if asanaGID supplied and asanaService available)
-	gid := ""
-	if len(asanaGID) > 0 {
-		gid = asanaGID[0]
-	}
+	// Priority 4: email match (only if asanaGID supplied and asanaService available)
 	if gid != "" && s.asanaService != nil {
-		asanaEmail := strings.ToLower(s.asanaService.GetUserEmail(userID, gid))
+		asanaEmail := strings.ToLower(s.asanaService.GetUserEmail(ctx, userID, gid))
 		fmt.Printf("ASSIGNEE: name match failed for '%s', trying email (asana email: '%s', %d YT users)\n", asanaName, asanaEmail, len(users))
 		if asanaEmail != "" {
 			for i, u := range users {
@@ -1238,8 +1262,8 @@ func (s *YouTrackService) ExtractAsanaID(issue YouTrackIssue) string {
 }
 
 // FindIssueByAsanaID finds YouTrack issue by Asana task ID
-func (s *YouTrackService) FindIssueByAsanaID(userID int, asanaTaskID string) (string, error) {
-	issues, err := s.GetIssues(userID)
+func (s *YouTrackService) FindIssueByAsanaID(ctx context.Context, userID int, asanaTaskID string) (string, error) {
+	issues, err := s.GetIssues(ctx, userID)
 	if err != nil {
 		return "", fmt.Errorf("failed to get YouTrack issues: %w", err)
 	}
@@ -1255,7 +1279,7 @@ func (s *YouTrackService) FindIssueByAsanaID(userID int, asanaTaskID string) (st
 }
 
 // GetStates retrieves all workflow states from a YouTrack project
-func (s *YouTrackService) GetStates(userID int) ([]database.YouTrackState, error) {
+func (s *YouTrackService) GetStates(ctx context.Context, userID int) ([]database.YouTrackState, error) {
 	settings, err := s.configService.GetSettings(userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user settings: %w", err)
@@ -1266,10 +1290,10 @@ func (s *YouTrackService) GetStates(userID int) ([]database.YouTrackState, error
 	}
 
 	// Fetch the project's custom fields to get the State field
-	url := fmt.Sprintf("%s/api/admin/projects/%s/customFields?fields=field(name,fieldType(id)),bundle(values(name))",
+	url := fmt.Sprintf("%s/api/admin/projects/%s/customFields?fields=field(name,fieldType(id)),bundle(values(id,name,isResolved))",
 		settings.YouTrackBaseURL, settings.YouTrackProjectID)
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -1277,7 +1301,7 @@ func (s *YouTrackService) GetStates(userID int) ([]database.YouTrackState, error
 	req.Header.Set("Authorization", "Bearer "+settings.YouTrackToken)
 	req.Header.Set("Accept", "application/json")
 
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := &http.Client{Timeout: 30 * time.Second, Transport: youtrackTransport}
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("API request failed: %w", err)
@@ -1306,8 +1330,12 @@ func (s *YouTrackService) GetStates(userID int) ([]database.YouTrackState, error
 						for _, val := range values {
 							if valMap, ok := val.(map[string]interface{}); ok {
 								if stateName, ok := valMap["name"].(string); ok {
+									stateID, _ := valMap["id"].(string)
+									isResolved, _ := valMap["isResolved"].(bool)
 									states = append(states, database.YouTrackState{
-										Name: stateName,
+										ID:         stateID,
+										Name:       stateName,
+										IsResolved: isResolved,
 									})
 								}
 							}
@@ -1323,7 +1351,7 @@ func (s *YouTrackService) GetStates(userID int) ([]database.YouTrackState, error
 }
 
 // GetBoards retrieves all agile boards from YouTrack
-func (s *YouTrackService) GetBoards(userID int) ([]database.YouTrackBoard, error) {
+func (s *YouTrackService) GetBoards(ctx context.Context, userID int) ([]database.YouTrackBoard, error) {
 	settings, err := s.configService.GetSettings(userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user settings: %w", err)
@@ -1336,7 +1364,7 @@ func (s *YouTrackService) GetBoards(userID int) ([]database.YouTrackBoard, error
 	url := fmt.Sprintf("%s/api/agiles?$top=-1&fields=id,name,sprintsSettings(disableSprints),projects(id)",
 		settings.YouTrackBaseURL)
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -1344,7 +1372,7 @@ func (s *YouTrackService) GetBoards(userID int) ([]database.YouTrackBoard, error
 	req.Header.Set("Authorization", "Bearer "+settings.YouTrackToken)
 	req.Header.Set("Accept", "application/json")
 
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := &http.Client{Timeout: 30 * time.Second, Transport: youtrackTransport}
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("API request failed: %w", err)
@@ -1378,7 +1406,7 @@ func (s *YouTrackService) GetBoards(userID int) ([]database.YouTrackBoard, error
 }
 
 // GetBoardIssueIDs returns a set of YouTrack issue IDs currently on the configured agile board
-func (s *YouTrackService) GetBoardIssueIDs(userID int) (map[string]bool, error) {
+func (s *YouTrackService) GetBoardIssueIDs(ctx context.Context, userID int) (map[string]bool, error) {
 	settings, err := s.configService.GetSettings(userID)
 	if err != nil || settings.YouTrackBoardID == "" {
 		return nil, fmt.Errorf("board not configured")
@@ -1387,14 +1415,14 @@ func (s *YouTrackService) GetBoardIssueIDs(userID int) (map[string]bool, error)
 	url := fmt.Sprintf("%s/api/agiles/%s/sprints?fields=id,name,archived,issues(id)&$top=-1",
 		settings.YouTrackBaseURL, settings.YouTrackBoardID)
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 	req.Header.Set("Authorization", "Bearer "+settings.YouTrackToken)
 	req.Header.Set("Accept", "application/json")
 
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := &http.Client{Timeout: 30 * time.Second, Transport: youtrackTransport}
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
@@ -1429,16 +1457,16 @@ func (s *YouTrackService) GetBoardIssueIDs(userID int) (map[string]bool, error)
 }
 
 // AssignIssueToBoard is a public wrapper that adds a single issue to the configured board
-func (s *YouTrackService) AssignIssueToBoard(userID int, issueID string) error {
+func (s *YouTrackService) AssignIssueToBoard(ctx context.Context, userID int, issueID string) error {
 	settings, err := s.configService.GetSettings(userID)
 	if err != nil {
 		return fmt.Errorf("failed to get settings: %w", err)
 	}
-	return s.assignIssueToAgileBoard(settings, issueID)
+	return s.assignIssueToAgileBoard(ctx, settings, nil, issueID, "")
 }
 
 // UploadAttachment uploads an attachment to a YouTrack issue
-func (s *YouTrackService) UploadAttachment(userID int, issueID string, filename string, fileData []byte) error {
+func (s *YouTrackService) UploadAttachment(ctx context.Context, userID int, issueID string, filename string, fileData []byte) error {
 	settings, err := s.configService.GetSettings(userID)
 	if err != nil {
 		return fmt.Errorf("failed to get user settings: %w", err)
@@ -1471,7 +1499,7 @@ func (s *YouTrackService) UploadAttachment(userID int, issueID string, filename
 	// Upload to YouTrack
 	url := fmt.Sprintf("%s/api/issues/%s/attachments?fields=id,name,size", settings.YouTrackBaseURL, issueID)
 
-	req, err := http.NewRequest("POST", url, body)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, body)
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
@@ -1480,7 +1508,7 @@ func (s *YouTrackService) UploadAttachment(userID int, issueID string, filename
 	req.Header.Set("Content-Type", writer.FormDataContentType())
 	req.Header.Set("Accept", "application/json")
 
-	client := &http.Client{Timeout: 120 * time.Second} // Longer timeout for uploads
+	client := &http.Client{Timeout: 120 * time.Second, Transport: youtrackTransport} // Longer timeout for uploads
 	resp, err := client.Do(req)
 	if err != nil {
 		return fmt.Errorf("upload request failed: %w", err)
@@ -1498,16 +1526,16 @@ func (s *YouTrackService) UploadAttachment(userID int, issueID string, filename
 }
 
 // getExistingAttachmentNames fetches the set of filenames already attached to a YT issue
-func (s *YouTrackService) getExistingAttachmentNames(settings *config.UserSettings, issueID string) map[string]bool {
+func (s *YouTrackService) getExistingAttachmentNames(ctx context.Context, settings *config.UserSettings, issueID string) map[string]bool {
 	url := fmt.Sprintf("%s/api/issues/%s/attachments?fields=name", settings.YouTrackBaseURL, issueID)
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil
 	}
 	req.Header.Set("Authorization", "Bearer "+settings.YouTrackToken)
 	req.Header.Set("Accept", "application/json")
 
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := &http.Client{Timeout: 30 * time.Second, Transport: youtrackTransport}
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil
@@ -1534,7 +1562,7 @@ func (s *YouTrackService) getExistingAttachmentNames(settings *config.UserSettin
 }
 
 // ProcessAttachments downloads attachments from Asana and uploads them to YouTrack (deduped by filename)
-func (s *YouTrackService) ProcessAttachments(userID int, issueID string, task AsanaTask, asanaService *AsanaService) error {
+func (s *YouTrackService) ProcessAttachments(ctx context.Context, userID int, issueID string, task AsanaTask, asanaService *AsanaService) error {
 	if len(task.Attachments) == 0 {
 		return nil
 	}
@@ -1550,7 +1578,7 @@ func (s *YouTrackService) ProcessAttachments(userID int, issueID string, task As
 	// Fetch existing attachment names for dedup
 	var existingNames map[string]bool
 	if settings != nil {
-		existingNames = s.getExistingAttachmentNames(settings, issueID)
+		existingNames = s.getExistingAttachmentNames(ctx, settings, issueID)
 	}
 
 	successCount := 0
@@ -1578,7 +1606,7 @@ func (s *YouTrackService) ProcessAttachments(userID int, issueID string, task As
 
 		// Download from Asana
 		fmt.Printf("Downloading attachment '%s' from Asana...\n", attachment.Name)
-		fileData, err := asanaService.DownloadAttachment(userID, attachment.GID)
+		fileData, err := asanaService.DownloadAttachment(ctx, userID, attachment.GID)
 		if err != nil {
 			fmt.Printf("Warning: Failed to download attachment '%s': %v\n", attachment.Name, err)
 			failCount++
@@ -1587,7 +1615,7 @@ func (s *YouTrackService) ProcessAttachments(userID int, issueID string, task As
 
 		// Upload to YouTrack
 		fmt.Printf("Uploading attachment '%s' to YouTrack...\n", attachment.Name)
-		err = s.UploadAttachment(userID, issueID, attachment.Name, fileData)
+		err = s.UploadAttachment(ctx, userID, issueID, attachment.Name, fileData)
 		if err != nil {
 			fmt.Printf("Warning: Failed to upload attachment '%s': %v\n", attachment.Name, err)
 			failCount++
@@ -1606,7 +1634,7 @@ func (s *YouTrackService) ProcessAttachments(userID int, issueID string, task As
 var usersCache = make(map[int][]YouTrackUser)
 var usersCacheMutex sync.RWMutex
 
-func (s *YouTrackService) GetAllUsers(userID int) ([]YouTrackUser, error) {
+func (s *YouTrackService) GetAllUsers(ctx context.Context, userID int) ([]YouTrackUser, error) {
 	// Check cache first
 	usersCacheMutex.RLock()
 	if cached, ok := usersCache[userID]; ok {
@@ -1627,7 +1655,7 @@ func (s *YouTrackService) GetAllUsers(userID int) ([]YouTrackUser, error) {
 	// $top=-1 fetches all users; without it YouTrack defaults to 42
 	url := fmt.Sprintf("%s/api/users?fields=id,ringId,login,fullName,email&$top=-1", settings.YouTrackBaseURL)
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -1635,7 +1663,7 @@ func (s *YouTrackService) GetAllUsers(userID int) ([]YouTrackUser, error) {
 	req.Header.Set("Authorization", "Bearer "+settings.YouTrackToken)
 	req.Header.Set("Accept", "application/json")
 
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := &http.Client{Timeout: 30 * time.Second, Transport: youtrackTransport}
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
@@ -1661,7 +1689,7 @@ func (s *YouTrackService) GetAllUsers(userID int) ([]YouTrackUser, error) {
 }
 
 // GetIssuesByCreator fetches YouTrack issues filtered by creator
-func (s *YouTrackService) GetIssuesByCreator(userID int, creatorFilter string) ([]YouTrackIssue, error) {
+func (s *YouTrackService) GetIssuesByCreator(ctx context.Context, userID int, creatorFilter string) ([]YouTrackIssue, error) {
 	settings, err := s.configService.GetSettings(userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user settings: %w", err)
@@ -1681,7 +1709,7 @@ func (s *YouTrackService) GetIssuesByCreator(userID int, creatorFilter string) (
 
 	// Include all necessary fields including attachments and created by
 	fields := "id,idReadable,summary,description,created,updated," +
-		"customFields(name,value(name,id))," +
+		"customFields(name,value(name,id,login,fullName,ringId))," +
 		"attachments(id,name,size,mimeType,url,extension)," +
 		"reporter(fullName,login)," +
 		"project(shortName)"
@@ -1694,7 +1722,7 @@ func (s *YouTrackService) GetIssuesByCreator(userID int, creatorFilter string) (
 	url := fmt.Sprintf("%s/api/issues?fields=%s&query=%s&$top=500",
 		settings.YouTrackBaseURL, fields, encodedQuery)
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -1702,7 +1730,7 @@ func (s *YouTrackService) GetIssuesByCreator(userID int, creatorFilter string) (
 	req.Header.Set("Authorization", "Bearer "+settings.YouTrackToken)
 	req.Header.Set("Accept", "application/json")
 
-	client := &http.Client{Timeout: 60 * time.Second}
+	client := &http.Client{Timeout: 60 * time.Second, Transport: youtrackTransport}
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
@@ -1737,8 +1765,12 @@ func (s *YouTrackService) GetIssuesByCreator(userID int, creatorFilter string) (
 			Updated:             getInt64(rawIssue, "updated"),
 		}
 
-		// Extract State and Subsystem from customFields
+		// Extract State and Subsystem from customFields, keeping the raw
+		// fields for readers like GetAssignees
 		if customFields, ok := rawIssue["customFields"].([]interface{}); ok {
+			if raw, err := json.Marshal(customFields); err == nil {
+				json.Unmarshal(raw, &issue.CustomFields)
+			}
 			for _, field := range customFields {
 				if fieldMap, ok := field.(map[string]interface{}); ok {
 					fieldName := getString(fieldMap, "name")
@@ -1746,6 +1778,7 @@ func (s *YouTrackService) GetIssuesByCreator(userID int, creatorFilter string) (
 					if fieldName == "State" {
 						if value, ok := fieldMap["value"].(map[string]interface{}); ok {
 							issue.State = getString(value, "name")
+							issue.StateID = getString(value, "id")
 						}
 					} else if fieldName == "Subsystem" {
 						if value, ok := fieldMap["value"].(map[string]interface{}); ok {
@@ -1789,7 +1822,7 @@ func (s *YouTrackService) GetIssuesByCreator(userID int, creatorFilter string) (
 }
 
 // DownloadAttachment downloads an attachment from a YouTrack issue using the attachment URL
-func (s *YouTrackService) DownloadAttachment(userID int, issueID, attachmentURL string) ([]byte, error) {
+func (s *YouTrackService) DownloadAttachment(ctx context.Context, userID int, issueID, attachmentURL string) ([]byte, error) {
 	settings, err := s.configService.GetSettings(userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user settings: %w", err)
@@ -1799,14 +1832,14 @@ func (s *YouTrackService) DownloadAttachment(userID int, issueID, attachmentURL
 	// We need to prepend the base URL
 	fullURL := settings.YouTrackBaseURL + attachmentURL
 
-	req, err := http.NewRequest("GET", fullURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", fullURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Authorization", "Bearer "+settings.YouTrackToken)
 
-	client := &http.Client{Timeout: 120 * time.Second}
+	client := &http.Client{Timeout: 120 * time.Second, Transport: youtrackTransport}
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("download request failed: %w", err)
@@ -1825,6 +1858,238 @@ func (s *YouTrackService) DownloadAttachment(userID int, issueID, attachmentURL
 	return data, nil
 }
 
+// GetIssueAttachments lists the attachments of a YouTrack issue
+func (s *YouTrackService) GetIssueAttachments(ctx context.Context, userID int, issueID string) ([]YouTrackAttachment, error) {
+	settings, err := s.configService.GetSettings(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user settings: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/api/issues/%s/attachments?fields=id,name,size,mimeType,url,extension", settings.YouTrackBaseURL, issueID)
+	var attachments []YouTrackAttachment
+	if err := s.doJSON(ctx, settings, "GET", url, nil, &attachments); err != nil {
+		return nil, err
+	}
+	return attachments, nil
+}
+
+// GetIssueComments lists the comments of a YouTrack issue, oldest first
+func (s *YouTrackService) GetIssueComments(ctx context.Context, userID int, issueID string) ([]YouTrackComment, error) {
+	settings, err := s.configService.GetSettings(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user settings: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/api/issues/%s/comments?fields=id,text,created,author(login,fullName)", settings.YouTrackBaseURL, issueID)
+	var comments []YouTrackComment
+	if err := s.doJSON(ctx, settings, "GET", url, nil, &comments); err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+// AddComment adds a comment to a YouTrack issue
+func (s *YouTrackService) AddComment(ctx context.Context, userID int, issueID, text string) error {
+	settings, err := s.configService.GetSettings(userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user settings: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/api/issues/%s/comments?fields=id", settings.YouTrackBaseURL, issueID)
+	return s.doJSON(ctx, settings, "POST", url, map[string]interface{}{"text": text}, nil)
+}
+
+// MarkAsDuplicate links an issue as a duplicate of another one
+func (s *YouTrackService) MarkAsDuplicate(ctx context.Context, userID int, duplicateID, originalID string) error {
+	settings, err := s.configService.GetSettings(userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user settings: %w", err)
+	}
+
+	payload := map[string]interface{}{
+		"query":  "duplicates " + originalID,
+		"issues": []map[string]interface{}{{"idReadable": duplicateID}},
+	}
+	if err := s.doJSON(ctx, settings, "POST", settings.YouTrackBaseURL+"/api/commands", payload, nil); err != nil {
+		return fmt.Errorf("failed to mark %s as duplicate of %s: %w", duplicateID, originalID, err)
+	}
+	return nil
+}
+
+// Names of YouTrack's time tracking fields
+const (
+	estimationField = "Estimation"
+	spentTimeField  = "Spent time"
+)
+
+// GetTimeTotals reads an issue's Estimation and Spent time fields. Returns nil
+// if the issue has neither, i.e. its project doesn't track time.
+func (s *YouTrackService) GetTimeTotals(issue YouTrackIssue) *TimeTotals {
+	var totals *TimeTotals
+	for _, field := range issue.CustomFields {
+		estimation := strings.EqualFold(field.Name, estimationField)
+		if !estimation && !strings.EqualFold(field.Name, spentTimeField) {
+			continue
+		}
+		if totals == nil {
+			totals = &TimeTotals{}
+		}
+		value, ok := field.Value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		minutes, hasMinutes := value["minutes"].(float64)
+		presentation, _ := value["presentation"].(string)
+		m := int(minutes)
+		if estimation {
+			totals.Estimate = presentation
+			if hasMinutes {
+				totals.EstimateMinutes = &m
+			}
+		} else {
+			totals.Spent = presentation
+			if hasMinutes {
+				totals.SpentMinutes = &m
+			}
+		}
+	}
+	return totals
+}
+
+// GetWorkItems returns the time logged on an issue
+func (s *YouTrackService) GetWorkItems(ctx context.Context, userID int, issueID string) ([]YouTrackWorkItem, error) {
+	settings, err := s.configService.GetSettings(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user settings: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/api/issues/%s/timeTracking/workItems?fields=id,date,text,duration(minutes),author(login,fullName)&$top=-1",
+		settings.YouTrackBaseURL, issueID)
+	var items []YouTrackWorkItem
+	if err := s.doJSON(ctx, settings, "GET", url, nil, &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// SetEstimation sets an issue's Estimation field
+func (s *YouTrackService) SetEstimation(ctx context.Context, userID int, issueID string, minutes int) error {
+	settings, err := s.configService.GetSettings(userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user settings: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/api/issues/%s?fields=id", settings.YouTrackBaseURL, issueID)
+	payload := map[string]interface{}{
+		"customFields": []map[string]interface{}{{
+			"name":  estimationField,
+			"$type": "PeriodIssueCustomField",
+			"value": map[string]interface{}{"$type": "PeriodValue", "minutes": minutes},
+		}},
+	}
+	if err := s.doJSON(ctx, settings, "POST", url, payload, nil); err != nil {
+		return fmt.Errorf("failed to set estimation of %s: %w", issueID, err)
+	}
+	s.InvalidateIssueCache(userID)
+	return nil
+}
+
+// GetWatchers returns the users watching an issue
+func (s *YouTrackService) GetWatchers(ctx context.Context, userID int, issueID string) ([]YouTrackWatcher, error) {
+	settings, err := s.configService.GetSettings(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user settings: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/api/issues/%s/watchers/issueWatchers?fields=id,user(id,ringId,login,fullName,email)&$top=-1",
+		settings.YouTrackBaseURL, issueID)
+	var watchers []YouTrackWatcher
+	if err := s.doJSON(ctx, settings, "GET", url, nil, &watchers); err != nil {
+		return nil, err
+	}
+	return watchers, nil
+}
+
+// AddWatcher subscribes a user to an issue, as starring it would
+func (s *YouTrackService) AddWatcher(ctx context.Context, userID int, issueID, ytUserID string) error {
+	settings, err := s.configService.GetSettings(userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user settings: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/api/issues/%s/watchers/issueWatchers?fields=id", settings.YouTrackBaseURL, issueID)
+	payload := map[string]interface{}{
+		"user":      map[string]interface{}{"id": ytUserID},
+		"isStarred": true,
+	}
+	return s.doJSON(ctx, settings, "POST", url, payload, nil)
+}
+
+// AddTag tags an issue, creating the tag if needed
+func (s *YouTrackService) AddTag(ctx context.Context, userID int, issueID, tag string) error {
+	settings, err := s.configService.GetSettings(userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user settings: %w", err)
+	}
+
+	payload := map[string]interface{}{
+		"query":  "tag " + commandName(tag),
+		"issues": []map[string]interface{}{{"idReadable": issueID}},
+	}
+	if err := s.doJSON(ctx, settings, "POST", settings.YouTrackBaseURL+"/api/commands", payload, nil); err != nil {
+		return fmt.Errorf("failed to tag %s as %s: %w", issueID, tag, err)
+	}
+	return nil
+}
+
+// commandName quotes a name for a YouTrack command: names with spaces go in
+// braces
+func commandName(name string) string {
+	if strings.ContainsAny(name, " \t") {
+		return "{" + name + "}"
+	}
+	return name
+}
+
+// doJSON sends a YouTrack API request with an optional JSON payload and
+// decodes the response into out, if given
+func (s *YouTrackService) doJSON(ctx context.Context, settings *config.UserSettings, method, url string, payload, out interface{}) error {
+	var body io.Reader
+	if payload != nil {
+		jsonPayload, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("failed to marshal payload: %w", err)
+		}
+		body = bytes.NewBuffer(jsonPayload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+settings.YouTrackToken)
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second, Transport: youtrackTransport}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("youtrack API error: %d - %s", resp.StatusCode, string(respBody))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
 // Helper functions for extracting values from interface{} maps
 func getString(m map[string]interface{}, key string) string {
 	if val, ok := m[key].(string); ok {
@@ -1844,7 +2109,7 @@ func getInt64(m map[string]interface{}, key string) int64 {
 }
 
 // GetSubsystemFieldInfo retrieves the subsystem field ID and enum value details from YouTrack
-func (s *YouTrackService) GetSubsystemFieldInfo(userID int, subsystemName string) (fieldID string, valueID string, err error) {
+func (s *YouTrackService) GetSubsystemFieldInfo(ctx context.Context, userID int, subsystemName string) (fieldID string, valueID string, err error) {
 	// Hardcoded field ID for Subsystem (from your YouTrack setup)
 	fieldID = "172-17"
 
@@ -1881,7 +2146,7 @@ func (s *YouTrackService) GetSubsystemFieldInfo(userID int, subsystemName string
 	url := fmt.Sprintf("%s/api/admin/projects/%s?fields=customFields(field(name),id,bundle(values(id,name)))",
 		settings.YouTrackBaseURL, settings.YouTrackProjectID)
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return "", "", fmt.Errorf("failed to create request: %w", err)
 	}
@@ -1889,7 +2154,7 @@ func (s *YouTrackService) GetSubsystemFieldInfo(userID int, subsystemName string
 	req.Header.Set("Authorization", "Bearer "+settings.YouTrackToken)
 	req.Header.Set("Accept", "application/json")
 
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := &http.Client{Timeout: 30 * time.Second, Transport: youtrackTransport}
 	resp, err := client.Do(req)
 	if err != nil {
 		return "", "", fmt.Errorf("API request failed: %w", err)
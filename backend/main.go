@@ -1,6 +1,7 @@
 package main
 
 import (
+	"crypto/subtle"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -10,6 +11,7 @@ import (
 	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/joho/godotenv"
 
 	"asana-youtrack-sync/auth"
@@ -18,6 +20,8 @@ import (
 	"asana-youtrack-sync/database"
 	"asana-youtrack-sync/legacy"
 	"asana-youtrack-sync/mapping"
+	"asana-youtrack-sync/metrics"
+	"asana-youtrack-sync/organization"
 	"asana-youtrack-sync/sync"
 	"asana-youtrack-sync/utils"
 )
@@ -68,10 +72,15 @@ func main() {
 	rollbackRestoreService := sync.NewRollbackRestoreService(db, snapshotService, auditService)
 
 	log.Println("✅ Core services initialized")
-	log.Println("✅ Rollback & Audit services initialized (15-snapshot limit, 24h expiration)")
+	log.Println("✅ Rollback & Audit services initialized (per-user snapshot retention policies)")
+
+	// Compact aged snapshots and drop expired ones in the background
+	go snapshotService.RunRetentionJob(1 * time.Hour)
+	log.Println("✅ Snapshot retention job started")
 
 	// Initialize legacy handler with database and user-specific settings
 	legacyHandler = legacy.NewHandler(db, configService, snapshotService)
+	configService.OnSyncPairDeleted(legacyHandler.ForgetPair)
 	log.Println("✅ Legacy handler initialized with enhanced features and snapshot support")
 
 	// Initialize legacy services for rollback
@@ -86,8 +95,13 @@ func main() {
 	wsManager := sync.NewWebSocketManager()
 	wsManager.SetTokenValidator(authService)
 	go wsManager.Run()
+	legacyHandler.SetNotifier(wsManager)
 	log.Println("✅ WebSocket manager started")
 
+	// Expose sync health to Prometheus
+	registerMetrics(wsManager)
+	log.Println("✅ Metrics registered")
+
 	// Initialize handlers
 	authHandler := auth.NewHandler(authService)
 	configHandler := configpkg.NewHandler(configService)
@@ -125,6 +139,52 @@ func main() {
 	log.Fatal(server.ListenAndServe())
 }
 
+// registerMetrics sets up the metrics read at scrape time and how many users
+// get series of their own (METRICS_USER_LABEL_LIMIT, default 0: none)
+func registerMetrics(wsManager *sync.WebSocketManager) {
+	if limit, err := strconv.Atoi(getEnvDefault("METRICS_USER_LABEL_LIMIT", "0")); err == nil {
+		metrics.SetUserLabelLimit(limit)
+	}
+
+	metrics.NewGaugeFunc("sync_websocket_connected_users", "Users with at least one open WebSocket connection.",
+		func() float64 { return float64(wsManager.GetConnectedUsers()) })
+
+	pool := func(stat func(*pgxpool.Stat) float64) func() float64 {
+		return func() float64 { return stat(db.PoolStats()) }
+	}
+	metrics.NewGaugeFunc("sync_db_pool_total_conns", "Open database connections.",
+		pool(func(s *pgxpool.Stat) float64 { return float64(s.TotalConns()) }))
+	metrics.NewGaugeFunc("sync_db_pool_acquired_conns", "Database connections in use.",
+		pool(func(s *pgxpool.Stat) float64 { return float64(s.AcquiredConns()) }))
+	metrics.NewGaugeFunc("sync_db_pool_idle_conns", "Idle database connections.",
+		pool(func(s *pgxpool.Stat) float64 { return float64(s.IdleConns()) }))
+	metrics.NewGaugeFunc("sync_db_pool_max_conns", "Maximum size of the database pool.",
+		pool(func(s *pgxpool.Stat) float64 { return float64(s.MaxConns()) }))
+	metrics.NewCounterFunc("sync_db_pool_acquires_total", "Connections acquired from the database pool.",
+		pool(func(s *pgxpool.Stat) float64 { return float64(s.AcquireCount()) }))
+	metrics.NewCounterFunc("sync_db_pool_empty_acquires_total", "Acquires that had to wait for a connection.",
+		pool(func(s *pgxpool.Stat) float64 { return float64(s.EmptyAcquireCount()) }))
+	metrics.NewCounterFunc("sync_db_pool_acquire_seconds_total", "Time spent waiting to acquire connections.",
+		pool(func(s *pgxpool.Stat) float64 { return s.AcquireDuration().Seconds() }))
+}
+
+// metricsHandler serves /metrics, asking for METRICS_TOKEN as a bearer token
+// when it is set
+func metricsHandler() http.Handler {
+	token := os.Getenv("METRICS_TOKEN")
+	handler := metrics.Handler()
+	if token == "" {
+		return handler
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte("Bearer "+token)) != 1 {
+			utils.SendUnauthorized(w, "Metrics token required")
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
 func registerRoutes(
 	router *mux.Router,
 	authHandler *auth.Handler,
@@ -149,9 +209,13 @@ func registerRoutes(
 	// Health check (public)
 	router.HandleFunc("/health", legacyHandler.HealthCheck).Methods("GET", "OPTIONS")
 
+	// Prometheus metrics (public unless METRICS_TOKEN is set)
+	router.Handle("/metrics", metricsHandler()).Methods("GET")
+
 	// PUBLIC Authentication routes
 	router.HandleFunc("/api/auth/register", authHandler.Register).Methods("POST", "OPTIONS")
 	router.HandleFunc("/api/auth/login", authHandler.Login).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/auth/refresh", authHandler.RefreshToken).Methods("POST", "OPTIONS")
 
 	// ========================================================================
 	// PROTECTED AUTHENTICATION ROUTES
@@ -160,10 +224,12 @@ func registerRoutes(
 	protectedAuth := router.PathPrefix("/api/auth").Subrouter()
 	protectedAuth.Use(authService.Middleware)
 
-	protectedAuth.HandleFunc("/refresh", authHandler.RefreshToken).Methods("POST", "OPTIONS")
 	protectedAuth.HandleFunc("/me", authHandler.GetProfile).Methods("GET", "OPTIONS")
 	protectedAuth.HandleFunc("/change-password", authHandler.ChangePassword).Methods("POST", "OPTIONS")
 	protectedAuth.HandleFunc("/logout", authHandler.Logout).Methods("POST", "OPTIONS")
+	protectedAuth.HandleFunc("/sessions", authHandler.ListSessions).Methods("GET", "OPTIONS")
+	protectedAuth.HandleFunc("/sessions/revoke-others", authHandler.RevokeOtherSessions).Methods("POST", "OPTIONS")
+	protectedAuth.HandleFunc("/sessions/{id}", authHandler.RevokeSession).Methods("DELETE", "OPTIONS")
 	protectedAuth.HandleFunc("/account/summary", authHandler.GetAccountDataSummary).Methods("GET", "OPTIONS")
 	protectedAuth.HandleFunc("/account/delete", authHandler.DeleteAccount).Methods("POST", "OPTIONS")
 
@@ -181,12 +247,29 @@ func registerRoutes(
 	mappingHandler := mapping.NewHandler(mappingService)
 	mappingHandler.RegisterRoutes(router, authService)
 
+	// ========================================================================
+	// ORGANIZATION ROUTES (Protected)
+	// ========================================================================
+
+	organizationService := organization.NewService(db)
+	organizationHandler := organization.NewHandler(organizationService)
+	organizationHandler.RegisterRoutes(router, authService)
+
 	// ========================================================================
 	// WEBSOCKET ENDPOINT
 	// ========================================================================
 
 	router.HandleFunc("/ws", wsManager.HandleWebSocket).Methods("GET")
 
+	// ========================================================================
+	// ROLE REQUIREMENTS
+	// ========================================================================
+	// Routes without a wrapper are open to every role (viewers only analyze
+	// and read). Operators change tickets; admins run destructive operations.
+
+	operator := func(h http.HandlerFunc) http.HandlerFunc { return authService.RequireRole(auth.RoleOperator, h) }
+	admin := func(h http.HandlerFunc) http.HandlerFunc { return authService.RequireRole(auth.RoleAdmin, h) }
+
 	// ========================================================================
 	// NEW SYNC API ROUTES (Protected)
 	// ========================================================================
@@ -194,11 +277,16 @@ func registerRoutes(
 	syncAPI := router.PathPrefix("/api/sync").Subrouter()
 	syncAPI.Use(authService.Middleware)
 
-	syncAPI.HandleFunc("/start", handleSyncStart(wsManager, rollbackService)).Methods("POST", "OPTIONS")
+	syncAPI.HandleFunc("/start", operator(handleSyncStart(wsManager, rollbackService))).Methods("POST", "OPTIONS")
 	syncAPI.HandleFunc("/status/{id}", handleSyncStatus(rollbackService)).Methods("GET", "OPTIONS")
 	syncAPI.HandleFunc("/history", handleSyncHistory(rollbackService)).Methods("GET", "OPTIONS")
-	syncAPI.HandleFunc("/rollback/{id}", sync.HandleRollback(rollbackRestoreService, youtrackService, asanaService, wsManager)).Methods("POST", "OPTIONS")
+	syncAPI.HandleFunc("/rollback/{id}", admin(sync.HandleRollback(rollbackRestoreService, youtrackService, asanaService, wsManager))).Methods("POST", "OPTIONS")
 	syncAPI.HandleFunc("/snapshot/{id}", sync.HandleGetSnapshotSummary(snapshotService)).Methods("GET", "OPTIONS")
+	syncAPI.HandleFunc("/snapshots", sync.HandleListSnapshots(snapshotService)).Methods("GET", "OPTIONS")
+	syncAPI.HandleFunc("/snapshots/retention", sync.HandleRetentionPolicy(snapshotService)).Methods("GET", "OPTIONS")
+	syncAPI.HandleFunc("/snapshots/retention", admin(sync.HandleRetentionPolicy(snapshotService))).Methods("PUT")
+	syncAPI.HandleFunc("/snapshots/{id}/pin", operator(sync.HandleSetSnapshotPinned(snapshotService, true))).Methods("POST", "OPTIONS")
+	syncAPI.HandleFunc("/snapshots/{id}/unpin", operator(sync.HandleSetSnapshotPinned(snapshotService, false))).Methods("POST", "OPTIONS")
 	syncAPI.HandleFunc("/operation/{id}/logs", sync.HandleGetOperationAuditLogs(auditService)).Methods("GET", "OPTIONS")
 
 	// ========================================================================
@@ -219,13 +307,20 @@ func registerRoutes(
 	legacyAPI := router.PathPrefix("").Subrouter()
 	legacyAPI.Use(authService.Middleware) // All legacy routes now require auth
 
+	// Legacy endpoints work on the sync pair named by ?pair_id (default pair if omitted)
+	pair := legacyHandler.PairRoute
+
 	// Core analysis and sync endpoints
-	legacyAPI.HandleFunc("/status", legacyHandler.StatusCheck).Methods("GET", "OPTIONS")
-	legacyAPI.HandleFunc("/analyze", legacyHandler.AnalyzeTickets).Methods("GET", "OPTIONS")
-	legacyAPI.HandleFunc("/analyze/progress", legacyHandler.AnalyzeWithProgress).Methods("GET", "OPTIONS")
+	legacyAPI.HandleFunc("/status", pair((*legacy.Handler).StatusCheck)).Methods("GET", "OPTIONS")
+	legacyAPI.HandleFunc("/analyze", pair((*legacy.Handler).AnalyzeTickets)).Methods("GET", "OPTIONS")
+	legacyAPI.HandleFunc("/analyze/progress", pair((*legacy.Handler).AnalyzeWithProgress)).Methods("GET", "OPTIONS")
+	legacyAPI.HandleFunc("/analyze/stream", pair((*legacy.Handler).StartAnalysisStream)).Methods("POST", "OPTIONS")
+	legacyAPI.HandleFunc("/analyze/cancel", legacyHandler.CancelAnalysis).Methods("POST", "OPTIONS")
+	legacyAPI.HandleFunc("/analysis/history", pair((*legacy.Handler).GetAnalysisHistory)).Methods("GET", "OPTIONS")
+	legacyAPI.HandleFunc("/analysis/diff", pair((*legacy.Handler).DiffAnalysisRuns)).Methods("GET", "OPTIONS")
 
 	// ENHANCED: Analysis with filtering and sorting
-	legacyAPI.HandleFunc("/analyze/enhanced", legacyHandler.AnalyzeTicketsEnhanced).Methods("GET", "POST", "OPTIONS")
+	legacyAPI.HandleFunc("/analyze/enhanced", pair((*legacy.Handler).AnalyzeTicketsEnhanced)).Methods("GET", "POST", "OPTIONS")
 
 	// STUB: GetChangedMappings - returns empty array for backwards compatibility
 	legacyAPI.HandleFunc("/changed-mappings", func(w http.ResponseWriter, r *http.Request) {
@@ -240,41 +335,56 @@ func registerRoutes(
 	}).Methods("GET", "OPTIONS")
 
 	// ENHANCED: Get available filter options
-	legacyAPI.HandleFunc("/filter-options", legacyHandler.GetFilterOptions).Methods("GET", "OPTIONS")
+	legacyAPI.HandleFunc("/filter-options", pair((*legacy.Handler).GetFilterOptions)).Methods("GET", "OPTIONS")
 
 	// ========================================================================
 	// 🔍 NEW: COLUMN VERIFICATION & DEBUG ENDPOINTS
 	// ========================================================================
-	// legacyAPI.HandleFunc("/verify-columns", legacyHandler.VerifyColumnsAndMapping).Methods("GET", "OPTIONS")
-	// legacyAPI.HandleFunc("/column-report", legacyHandler.GetColumnMappingReport).Methods("GET", "OPTIONS")
-	legacyAPI.HandleFunc("/youtrack-states", legacyHandler.GetYouTrackStatesRaw).Methods("GET", "OPTIONS")
+	// Mappings are checked against the live boards by GET /api/settings/validate
+	legacyAPI.HandleFunc("/youtrack-states", pair((*legacy.Handler).GetYouTrackStatesRaw)).Methods("GET", "OPTIONS")
 	// ========================================================================
 
-	legacyAPI.HandleFunc("/create", legacyHandler.CreateMissingTickets).Methods("GET", "POST", "OPTIONS")
-	legacyAPI.HandleFunc("/create-single", legacyHandler.CreateSingleTicket).Methods("POST", "OPTIONS")
-	legacyAPI.HandleFunc("/sync", legacyHandler.SyncMismatchedTickets).Methods("GET", "POST", "OPTIONS")
+	legacyAPI.HandleFunc("/create", operator(pair((*legacy.Handler).CreateMissingTickets))).Methods("GET", "POST", "OPTIONS")
+	legacyAPI.HandleFunc("/create-single", operator(pair((*legacy.Handler).CreateSingleTicket))).Methods("POST", "OPTIONS")
+	legacyAPI.HandleFunc("/sync", operator(pair((*legacy.Handler).SyncMismatchedTickets))).Methods("GET", "POST", "OPTIONS")
 
 	// ENHANCED: Sync with change detection
-	legacyAPI.HandleFunc("/sync/enhanced", handleEnhancedSync).Methods("GET", "POST", "OPTIONS")
-
-	legacyAPI.HandleFunc("/add-to-board", legacyHandler.AddToBoard).Methods("POST", "OPTIONS")
-	legacyAPI.HandleFunc("/sync-priorities", legacyHandler.SyncPriorities).Methods("POST", "OPTIONS")
-	legacyAPI.HandleFunc("/map-ticket", legacyHandler.MapTicket).Methods("POST", "OPTIONS")
-	legacyAPI.HandleFunc("/ignore", legacyHandler.ManageIgnoredTickets).Methods("GET", "POST", "OPTIONS")
-	legacyAPI.HandleFunc("/tickets", legacyHandler.GetTicketsByType).Methods("GET", "OPTIONS")
-	legacyAPI.HandleFunc("/delete-tickets", legacyHandler.DeleteTickets).Methods("POST", "OPTIONS")
+	legacyAPI.HandleFunc("/sync/enhanced", operator(handleEnhancedSync)).Methods("GET", "POST", "OPTIONS")
+
+	legacyAPI.HandleFunc("/add-to-board", operator(pair((*legacy.Handler).AddToBoard))).Methods("POST", "OPTIONS")
+	legacyAPI.HandleFunc("/sync-priorities", operator(pair((*legacy.Handler).SyncPriorities))).Methods("POST", "OPTIONS")
+	legacyAPI.HandleFunc("/map-ticket", operator(pair((*legacy.Handler).MapTicket))).Methods("POST", "OPTIONS")
+	legacyAPI.HandleFunc("/match-suggestions", pair((*legacy.Handler).ListMatchSuggestions)).Methods("GET", "OPTIONS")
+	legacyAPI.HandleFunc("/match-suggestions/{id}/accept", operator(pair((*legacy.Handler).AcceptMatchSuggestion))).Methods("POST", "OPTIONS")
+	legacyAPI.HandleFunc("/match-suggestions/{id}/reject", operator(pair((*legacy.Handler).RejectMatchSuggestion))).Methods("POST", "OPTIONS")
+	legacyAPI.HandleFunc("/orphans", pair((*legacy.Handler).ListOrphans)).Methods("GET", "OPTIONS")
+	legacyAPI.HandleFunc("/orphans/apply", operator(pair((*legacy.Handler).ApplyOrphanPolicy))).Methods("POST", "OPTIONS")
+	legacyAPI.HandleFunc("/orphans/{id}/action", operator(pair((*legacy.Handler).OrphanAction))).Methods("POST", "OPTIONS")
+	legacyAPI.HandleFunc("/identities", pair((*legacy.Handler).ListIdentities)).Methods("GET", "OPTIONS")
+	legacyAPI.HandleFunc("/identities", operator(pair((*legacy.Handler).LinkIdentity))).Methods("POST", "OPTIONS")
+	legacyAPI.HandleFunc("/identities/directory", pair((*legacy.Handler).GetIdentityDirectory)).Methods("GET", "OPTIONS")
+	legacyAPI.HandleFunc("/identities/directory/refresh", operator(pair((*legacy.Handler).RefreshIdentityDirectory))).Methods("POST", "OPTIONS")
+	legacyAPI.HandleFunc("/identities/{id}", operator(pair((*legacy.Handler).UnlinkIdentity))).Methods("DELETE", "OPTIONS")
+	legacyAPI.HandleFunc("/duplicates", pair((*legacy.Handler).ScanDuplicates)).Methods("GET", "OPTIONS")
+	legacyAPI.HandleFunc("/duplicates/merge", admin(pair((*legacy.Handler).MergeDuplicates))).Methods("POST", "OPTIONS")
+	legacyAPI.HandleFunc("/ignore", pair((*legacy.Handler).ManageIgnoredTickets)).Methods("GET", "OPTIONS")
+	legacyAPI.HandleFunc("/ignore", operator(pair((*legacy.Handler).ManageIgnoredTickets))).Methods("POST")
+	legacyAPI.HandleFunc("/tickets", pair((*legacy.Handler).GetTicketsByType)).Methods("GET", "OPTIONS")
+	legacyAPI.HandleFunc("/delete-tickets", admin(pair((*legacy.Handler).DeleteTickets))).Methods("POST", "OPTIONS")
 
 	// Additional endpoints
-	legacyAPI.HandleFunc("/sync-stats", legacyHandler.GetSyncStats).Methods("GET", "OPTIONS")
-	legacyAPI.HandleFunc("/syncable-tickets", legacyHandler.GetSyncableTickets).Methods("GET", "OPTIONS")
-	legacyAPI.HandleFunc("/sync-by-column", legacyHandler.SyncByColumn).Methods("POST", "OPTIONS")
-	legacyAPI.HandleFunc("/create-by-column", legacyHandler.CreateByColumn).Methods("POST", "OPTIONS")
-	legacyAPI.HandleFunc("/deletion-preview", legacyHandler.GetDeletionPreview).Methods("GET", "OPTIONS")
-	legacyAPI.HandleFunc("/sync-preview", legacyHandler.GetSyncPreview).Methods("GET", "OPTIONS")
+	legacyAPI.HandleFunc("/sync-stats", pair((*legacy.Handler).GetSyncStats)).Methods("GET", "OPTIONS")
+	legacyAPI.HandleFunc("/syncable-tickets", pair((*legacy.Handler).GetSyncableTickets)).Methods("GET", "OPTIONS")
+	legacyAPI.HandleFunc("/sync-by-column", operator(pair((*legacy.Handler).SyncByColumn))).Methods("POST", "OPTIONS")
+	legacyAPI.HandleFunc("/create-by-column", operator(pair((*legacy.Handler).CreateByColumn))).Methods("POST", "OPTIONS")
+	legacyAPI.HandleFunc("/deletion-preview", pair((*legacy.Handler).GetDeletionPreview)).Methods("GET", "OPTIONS")
+	legacyAPI.HandleFunc("/sync-preview", pair((*legacy.Handler).GetSyncPreview)).Methods("GET", "OPTIONS")
 
 	// Auto-sync endpoints
-	legacyAPI.HandleFunc("/auto-sync", handleAutoSync).Methods("GET", "POST", "OPTIONS")
-	legacyAPI.HandleFunc("/auto-create", handleAutoCreate).Methods("GET", "POST", "OPTIONS")
+	legacyAPI.HandleFunc("/auto-sync", handleAutoSync).Methods("GET", "OPTIONS")
+	legacyAPI.HandleFunc("/auto-sync", operator(handleAutoSync)).Methods("POST")
+	legacyAPI.HandleFunc("/auto-create", handleAutoCreate).Methods("GET", "OPTIONS")
+	legacyAPI.HandleFunc("/auto-create", operator(handleAutoCreate)).Methods("POST")
 
 	// ENHANCED: Detailed auto-sync status
 	legacyAPI.HandleFunc("/auto-sync/detailed", handleAutoSyncDetailed).Methods("GET", "OPTIONS")
@@ -284,18 +394,18 @@ func registerRoutes(
 	// ========================================================================
 	legacyAPI.HandleFunc("/reverse-sync/users", handleGetYouTrackUsers).Methods("GET", "OPTIONS")
 	legacyAPI.HandleFunc("/reverse-sync/analyze", handleReverseAnalysis).Methods("POST", "OPTIONS")
-	legacyAPI.HandleFunc("/reverse-sync/create", handleReverseCreateTickets).Methods("POST", "OPTIONS")
+	legacyAPI.HandleFunc("/reverse-sync/create", operator(handleReverseCreateTickets)).Methods("POST", "OPTIONS")
 
 	// Reverse Sync - Ignored Tickets
 	legacyAPI.HandleFunc("/reverse-sync/ignored/status", handleGetReverseIgnoredStatus).Methods("GET", "OPTIONS")
-	legacyAPI.HandleFunc("/reverse-sync/ignored", handleReverseIgnoreAction).Methods("POST", "OPTIONS")
-	legacyAPI.HandleFunc("/reverse-sync/ignored/clear", handleClearReverseIgnored).Methods("POST", "OPTIONS")
+	legacyAPI.HandleFunc("/reverse-sync/ignored", operator(handleReverseIgnoreAction)).Methods("POST", "OPTIONS")
+	legacyAPI.HandleFunc("/reverse-sync/ignored/clear", admin(handleClearReverseIgnored)).Methods("POST", "OPTIONS")
 
 	// Reverse Sync - Auto-Create
 	legacyAPI.HandleFunc("/reverse-sync/auto-create/status", handleGetReverseAutoCreateStatus).Methods("GET", "OPTIONS")
-	legacyAPI.HandleFunc("/reverse-sync/auto-create/start", handleStartReverseAutoCreate).Methods("POST", "OPTIONS")
-	legacyAPI.HandleFunc("/reverse-sync/auto-create/stop", handleStopReverseAutoCreate).Methods("POST", "OPTIONS")
-	legacyAPI.HandleFunc("/reverse-sync/auto-create/settings", handleUpdateReverseAutoCreateSettings).Methods("POST", "OPTIONS")
+	legacyAPI.HandleFunc("/reverse-sync/auto-create/start", operator(handleStartReverseAutoCreate)).Methods("POST", "OPTIONS")
+	legacyAPI.HandleFunc("/reverse-sync/auto-create/stop", operator(handleStopReverseAutoCreate)).Methods("POST", "OPTIONS")
+	legacyAPI.HandleFunc("/reverse-sync/auto-create/settings", operator(handleUpdateReverseAutoCreateSettings)).Methods("POST", "OPTIONS")
 
 	// ========================================================================
 	// STATIC FILE SERVING
@@ -323,6 +433,21 @@ func registerRoutes(
 // ENHANCED SYNC HANDLER
 // ============================================================================
 
+// requestPairID resolves the request's optional pair_id query parameter
+// against the user's sync pairs, replying with an error if it is invalid
+func requestPairID(w http.ResponseWriter, r *http.Request, userID int) (int, bool) {
+	pairID, err := configService.RequestPairID(r, userID)
+	if err == configpkg.ErrSyncPairNotFound {
+		utils.SendNotFound(w, err.Error())
+		return 0, false
+	}
+	if err != nil {
+		utils.SendBadRequest(w, err.Error())
+		return 0, false
+	}
+	return pairID, true
+}
+
 func handleEnhancedSync(w http.ResponseWriter, r *http.Request) {
 	user, ok := auth.GetUserFromContext(r)
 	if !ok {
@@ -330,6 +455,12 @@ func handleEnhancedSync(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	pairID, ok := requestPairID(w, r, user.UserID)
+	if !ok {
+		return
+	}
+	syncService := legacyHandler.ForPair(pairID).GetSyncService()
+
 	columnFilter := r.URL.Query().Get("column")
 	var mappedColumn string
 	if columnFilter != "" && columnFilter != "all_syncable" {
@@ -350,7 +481,7 @@ func handleEnhancedSync(w http.ResponseWriter, r *http.Request) {
 
 	if r.Method == "GET" {
 		// Return available mismatched tickets
-		result, err := legacyHandler.GetSyncService().GetMismatchedTickets(user.UserID, mappedColumn)
+		result, err := syncService.GetMismatchedTickets(r.Context(), user.UserID, mappedColumn)
 		if err != nil {
 			utils.SendInternalError(w, fmt.Sprintf("Failed to get mismatched tickets: %v", err))
 			return
@@ -371,13 +502,12 @@ func handleEnhancedSync(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	syncService := legacyHandler.GetSyncService()
 	if err := syncService.ValidateSyncRequests(requests); err != nil {
 		utils.SendBadRequest(w, err.Error())
 		return
 	}
 
-	result, err := syncService.SyncMismatchedTickets(user.UserID, requests, mappedColumn)
+	result, err := syncService.SyncMismatchedTickets(r.Context(), user.UserID, requests, mappedColumn)
 	if err != nil {
 		utils.SendInternalError(w, fmt.Sprintf("Sync failed: %v", err))
 		return
@@ -397,10 +527,15 @@ func handleAutoSync(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	pairID, ok := requestPairID(w, r, user.UserID)
+	if !ok {
+		return
+	}
+
 	switch r.Method {
 	case "GET":
 		manager := legacy.GetAutoSyncManager()
-		status := manager.GetAutoSyncStatus(user.UserID)
+		status := manager.GetAutoSyncStatus(user.UserID, pairID)
 		utils.SendSuccess(w, status, "Auto-sync status retrieved")
 
 	case "POST":
@@ -419,23 +554,23 @@ func handleAutoSync(w http.ResponseWriter, r *http.Request) {
 				interval = 15
 			}
 
-			err := manager.StartAutoSync(user.UserID, interval)
+			err := manager.StartAutoSync(user.UserID, pairID, interval)
 			if err != nil {
 				utils.SendInternalError(w, "Failed to start auto-sync: "+err.Error())
 				return
 			}
 
-			status := manager.GetAutoSyncStatus(user.UserID)
+			status := manager.GetAutoSyncStatus(user.UserID, pairID)
 			utils.SendSuccess(w, status, "Auto-sync started successfully")
 
 		case "stop":
-			err := manager.StopAutoSync(user.UserID)
+			err := manager.StopAutoSync(user.UserID, pairID)
 			if err != nil {
 				utils.SendBadRequest(w, "Failed to stop auto-sync: "+err.Error())
 				return
 			}
 
-			status := manager.GetAutoSyncStatus(user.UserID)
+			status := manager.GetAutoSyncStatus(user.UserID, pairID)
 			utils.SendSuccess(w, status, "Auto-sync stopped successfully")
 
 		default:
@@ -455,8 +590,13 @@ func handleAutoSyncDetailed(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	pairID, ok := requestPairID(w, r, user.UserID)
+	if !ok {
+		return
+	}
+
 	manager := legacy.GetAutoSyncManager()
-	status := manager.GetAutoSyncStatusDetailed(user.UserID)
+	status := manager.GetAutoSyncStatusDetailed(user.UserID, pairID)
 	utils.SendSuccess(w, status, "Detailed auto-sync status retrieved")
 }
 
@@ -467,10 +607,15 @@ func handleAutoCreate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	pairID, ok := requestPairID(w, r, user.UserID)
+	if !ok {
+		return
+	}
+
 	switch r.Method {
 	case "GET":
 		manager := legacy.GetAutoCreateManager()
-		status := manager.GetAutoCreateStatus(user.UserID)
+		status := manager.GetAutoCreateStatus(user.UserID, pairID)
 		utils.SendSuccess(w, status, "Auto-create status retrieved")
 
 	case "POST":
@@ -489,23 +634,23 @@ func handleAutoCreate(w http.ResponseWriter, r *http.Request) {
 				interval = 15
 			}
 
-			err := manager.StartAutoCreate(user.UserID, interval)
+			err := manager.StartAutoCreate(user.UserID, pairID, interval)
 			if err != nil {
 				utils.SendInternalError(w, "Failed to start auto-create: "+err.Error())
 				return
 			}
 
-			status := manager.GetAutoCreateStatus(user.UserID)
+			status := manager.GetAutoCreateStatus(user.UserID, pairID)
 			utils.SendSuccess(w, status, "Auto-create started successfully")
 
 		case "stop":
-			err := manager.StopAutoCreate(user.UserID)
+			err := manager.StopAutoCreate(user.UserID, pairID)
 			if err != nil {
 				utils.SendBadRequest(w, "Failed to stop auto-create: "+err.Error())
 				return
 			}
 
-			status := manager.GetAutoCreateStatus(user.UserID)
+			status := manager.GetAutoCreateStatus(user.UserID, pairID)
 			utils.SendSuccess(w, status, "Auto-create stopped successfully")
 
 		default:
@@ -686,6 +831,20 @@ func performSync(operation *sync.SyncOperation, wsManager *sync.WebSocketManager
 // REVERSE SYNC HANDLERS (YouTrack → Asana)
 // ============================================================================
 
+// reverseSyncServiceFor builds a reverse sync service bound to the sync pair
+// named by the request's pair_id query parameter
+func reverseSyncServiceFor(w http.ResponseWriter, r *http.Request, userID int) (*legacy.ReverseSyncService, bool) {
+	pairID, ok := requestPairID(w, r, userID)
+	if !ok {
+		return nil, false
+	}
+
+	pairConfig := configService.ForPair(pairID)
+	youtrackService := legacy.NewYouTrackService(pairConfig)
+	asanaService := legacy.NewAsanaService(pairConfig)
+	return legacy.NewReverseSyncService(db, youtrackService, asanaService, pairConfig), true
+}
+
 func handleGetYouTrackUsers(w http.ResponseWriter, r *http.Request) {
 	user, ok := auth.GetUserFromContext(r)
 	if !ok {
@@ -694,11 +853,12 @@ func handleGetYouTrackUsers(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Initialize reverse sync service
-	youtrackService := legacy.NewYouTrackService(configService)
-	asanaService := legacy.NewAsanaService(configService)
-	reverseSyncService := legacy.NewReverseSyncService(db, youtrackService, asanaService, configService)
+	reverseSyncService, ok := reverseSyncServiceFor(w, r, user.UserID)
+	if !ok {
+		return
+	}
 
-	users, err := reverseSyncService.GetYouTrackUsers(user.UserID)
+	users, err := reverseSyncService.GetYouTrackUsers(r.Context(), user.UserID)
 	if err != nil {
 		utils.SendInternalError(w, fmt.Sprintf("Failed to fetch YouTrack users: %v", err))
 		return
@@ -721,11 +881,12 @@ func handleReverseAnalysis(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Initialize reverse sync service
-	youtrackService := legacy.NewYouTrackService(configService)
-	asanaService := legacy.NewAsanaService(configService)
-	reverseSyncService := legacy.NewReverseSyncService(db, youtrackService, asanaService, configService)
+	reverseSyncService, ok := reverseSyncServiceFor(w, r, user.UserID)
+	if !ok {
+		return
+	}
 
-	analysis, err := reverseSyncService.PerformReverseAnalysis(user.UserID, req.CreatorFilter)
+	analysis, err := reverseSyncService.PerformReverseAnalysis(r.Context(), user.UserID, req.CreatorFilter)
 	if err != nil {
 		utils.SendInternalError(w, fmt.Sprintf("Failed to perform reverse analysis: %v", err))
 		return
@@ -748,14 +909,15 @@ func handleReverseCreateTickets(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Initialize reverse sync service
-	youtrackService := legacy.NewYouTrackService(configService)
-	asanaService := legacy.NewAsanaService(configService)
-	reverseSyncService := legacy.NewReverseSyncService(db, youtrackService, asanaService, configService)
+	reverseSyncService, ok := reverseSyncServiceFor(w, r, user.UserID)
+	if !ok {
+		return
+	}
 
 	// First, get the analysis to have the full context
 	// We need to extract the creator filter from the request or use "All"
 	// For now, we'll re-analyze with "All" to get the full list
-	analysis, err := reverseSyncService.PerformReverseAnalysis(user.UserID, "All")
+	analysis, err := reverseSyncService.PerformReverseAnalysis(r.Context(), user.UserID, "All")
 	if err != nil {
 		utils.SendInternalError(w, fmt.Sprintf("Failed to get analysis: %v", err))
 		return
@@ -765,10 +927,10 @@ func handleReverseCreateTickets(w http.ResponseWriter, r *http.Request) {
 
 	// If no specific IDs provided, create all missing tickets
 	if len(req.SelectedIssueIDs) == 0 {
-		result, err = reverseSyncService.CreateMissingAsanaTickets(user.UserID, analysis)
+		result, err = reverseSyncService.CreateMissingAsanaTickets(r.Context(), user.UserID, analysis)
 	} else {
 		// Create only selected tickets
-		result, err = reverseSyncService.CreateSelectedAsanaTickets(user.UserID, req.SelectedIssueIDs, analysis)
+		result, err = reverseSyncService.CreateSelectedAsanaTickets(r.Context(), user.UserID, req.SelectedIssueIDs, analysis)
 	}
 
 	if err != nil {
@@ -790,9 +952,10 @@ func handleGetReverseIgnoredStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	youtrackService := legacy.NewYouTrackService(configService)
-	asanaService := legacy.NewAsanaService(configService)
-	reverseSyncService := legacy.NewReverseSyncService(db, youtrackService, asanaService, configService)
+	reverseSyncService, ok := reverseSyncServiceFor(w, r, user.UserID)
+	if !ok {
+		return
+	}
 	status := reverseSyncService.ReverseIgnoreService.GetIgnoreStatus(user.UserID)
 	utils.SendSuccess(w, status, "Retrieved reverse ignored status")
 }
@@ -815,9 +978,10 @@ func handleReverseIgnoreAction(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	youtrackService := legacy.NewYouTrackService(configService)
-	asanaService := legacy.NewAsanaService(configService)
-	reverseSyncService := legacy.NewReverseSyncService(db, youtrackService, asanaService, configService)
+	reverseSyncService, ok := reverseSyncServiceFor(w, r, user.UserID)
+	if !ok {
+		return
+	}
 	err := reverseSyncService.ReverseIgnoreService.ProcessIgnoreRequest(user.UserID, req.TicketID, req.Action, req.IgnoreType)
 	if err != nil {
 		utils.SendInternalError(w, fmt.Sprintf("Failed to process ignore request: %v", err))
@@ -843,9 +1007,10 @@ func handleClearReverseIgnored(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	youtrackService := legacy.NewYouTrackService(configService)
-	asanaService := legacy.NewAsanaService(configService)
-	reverseSyncService := legacy.NewReverseSyncService(db, youtrackService, asanaService, configService)
+	reverseSyncService, ok := reverseSyncServiceFor(w, r, user.UserID)
+	if !ok {
+		return
+	}
 	var err error
 	switch req.IgnoreType {
 	case "temp":
@@ -1000,16 +1165,25 @@ func handleAPIDocs(w http.ResponseWriter, r *http.Request) {
 		"title":       "Enhanced Asana YouTrack Sync API",
 		"version":     "4.1.0",
 		"description": "Full-featured synchronization with filtering, sorting, and change detection",
+		"roles": map[string]string{
+			"viewer":   "Run analysis and read results, settings, history and audit logs",
+			"operator": "Viewer + create, sync, ignore and map tickets, auto-sync/auto-create, pin snapshots",
+			"admin":    "Operator + bulk delete, rollback, clear reverse ignores, column mappings, retention policy, membership",
+			"note":     "Roles come from organization membership; users outside an organization are admins of their own data. Denials return 403 and are written to the audit log as permission_denied.",
+		},
 		"endpoints": map[string]interface{}{
 			"authentication": map[string]string{
-				"POST /api/auth/register":        "Register new user",
-				"POST /api/auth/login":           "Login user",
-				"POST /api/auth/refresh":         "Refresh token",
-				"GET  /api/auth/me":              "Get user profile",
-				"POST /api/auth/change-password": "Change password",
-				"POST /api/auth/logout":          "Logout user",
-				"GET  /api/auth/account/summary": "Get account data summary",
-				"POST /api/auth/account/delete":  "Delete account",
+				"POST /api/auth/register":               "Register new user",
+				"POST /api/auth/login":                  "Login user",
+				"POST /api/auth/refresh":                "Exchange refresh token for new access/refresh tokens (public)",
+				"GET  /api/auth/me":                     "Get user profile",
+				"POST /api/auth/change-password":        "Change password",
+				"POST /api/auth/logout":                 "Logout user (revokes current session)",
+				"GET  /api/auth/sessions":               "List active sessions",
+				"DELETE /api/auth/sessions/{id}":        "Revoke a session",
+				"POST /api/auth/sessions/revoke-others": "Revoke all other sessions",
+				"GET  /api/auth/account/summary":        "Get account data summary",
+				"POST /api/auth/account/delete":         "Delete account",
 			},
 			"settings": map[string]string{
 				"GET  /api/settings":                   "Get user settings",
@@ -1017,6 +1191,16 @@ func handleAPIDocs(w http.ResponseWriter, r *http.Request) {
 				"GET  /api/settings/asana/projects":    "Get Asana projects",
 				"GET  /api/settings/youtrack/projects": "Get YouTrack projects",
 				"POST /api/settings/test-connections":  "Test API connections",
+				"GET  /api/settings/validate":          "Check column, status, priority and tag mappings against the live boards",
+			},
+			"sync_pairs": map[string]string{
+				"GET    /api/settings/sync-pairs":              "List sync pairs (default first)",
+				"POST   /api/settings/sync-pairs":              "Create sync pair (admin)",
+				"GET    /api/settings/sync-pairs/{id}":         "Get sync pair",
+				"PUT    /api/settings/sync-pairs/{id}":         "Update sync pair name and board configuration (admin)",
+				"DELETE /api/settings/sync-pairs/{id}":         "Delete sync pair and its ignore lists (admin)",
+				"POST   /api/settings/sync-pairs/{id}/default": "Make sync pair the default (admin)",
+				"note": "Settings, analysis, sync, ignore, auto-sync/auto-create and reverse-sync endpoints take an optional ?pair_id=; without it they use the default pair",
 			},
 			"ticket_mappings": map[string]string{
 				"POST   /api/mappings":                    "Create manual ticket mapping",
@@ -1025,9 +1209,16 @@ func handleAPIDocs(w http.ResponseWriter, r *http.Request) {
 				"GET    /api/mappings/asana/{taskId}":     "Get mapping by Asana task ID",
 				"GET    /api/mappings/youtrack/{issueId}": "Get mapping by YouTrack issue ID",
 			},
+			"organizations": map[string]string{
+				"POST   /api/organizations":                           "Create organization (shares your sync pairs, mappings and ignores)",
+				"GET    /api/organizations/current":                   "Get your organization and its members",
+				"PUT    /api/organizations/current":                   "Rename organization (admin)",
+				"DELETE /api/organizations/current":                   "Dissolve organization (admin)",
+				"POST   /api/organizations/current/members":           "Add member by username or email (admin)",
+				"PUT    /api/organizations/current/members/{user_id}": "Change member role (admin)",
+				"DELETE /api/organizations/current/members/{user_id}": "Remove member (admin) or leave (self)",
+			},
 			"column_verification": map[string]string{
-				"GET  /verify-columns":    "Verify column detection and mapping (detailed JSON)",
-				"GET  /column-report":     "Get human-readable column mapping report",
 				"GET  /youtrack-states":   "Get raw YouTrack state information (debug)",
 				"POST /validate-mappings": "Validate and cleanup invalid ticket mappings",
 			},
@@ -1041,13 +1232,18 @@ func handleAPIDocs(w http.ResponseWriter, r *http.Request) {
 				"GET      /auto-sync/detailed": "Get detailed auto-sync status",
 			},
 			"new_sync": map[string]string{
-				"POST /api/sync/start":         "Start sync operation",
-				"GET  /api/sync/status/{id}":   "Get sync status",
-				"GET  /api/sync/history":       "Get sync history",
-				"POST /api/sync/rollback/{id}": "Rollback sync operation",
+				"POST /api/sync/start":                  "Start sync operation",
+				"GET  /api/sync/status/{id}":            "Get sync status",
+				"GET  /api/sync/history":                "Get sync history",
+				"POST /api/sync/rollback/{id}":          "Rollback sync operation",
+				"GET  /api/sync/snapshots":              "List rollback snapshots",
+				"GET/PUT /api/sync/snapshots/retention": "Get or update snapshot retention policy",
+				"POST /api/sync/snapshots/{id}/pin":     "Pin snapshot (never expires)",
+				"POST /api/sync/snapshots/{id}/unpin":   "Unpin snapshot",
 			},
 			"legacy_api": map[string]string{
 				"GET  /health":           "Health check (public)",
+				"GET  /metrics":          "Prometheus metrics (public unless METRICS_TOKEN is set)",
 				"GET  /status":           "Service status (protected)",
 				"GET  /analyze":          "Basic ticket analysis",
 				"POST /create":           "Create missing tickets",
@@ -1121,15 +1317,18 @@ func logRouteRegistration() {
 	log.Println("🛣️  Routes registered successfully:")
 	log.Println("   📖 PUBLIC:")
 	log.Println("      GET  /health - Health check")
+	log.Println("      GET  /metrics - Prometheus metrics (METRICS_TOKEN if set)")
 	log.Println("      POST /api/auth/register - User registration")
 	log.Println("      POST /api/auth/login - User login")
+	log.Println("      POST /api/auth/refresh - Rotate refresh token")
 	log.Println("   🔒 PROTECTED (require Bearer token):")
 	log.Println("      POST /api/auth/* - Auth management")
 	log.Println("      */   /api/settings/* - User settings")
+	log.Println("      */   /api/settings/sync-pairs/* - Sync pairs (select with ?pair_id=)")
 	log.Println("      */   /api/mappings/* - Ticket mappings")
+	log.Println("      */   /api/organizations/* - Organizations and members")
 	log.Println("   🔍 COLUMN VERIFICATION (NEW):")
-	log.Println("      GET  /verify-columns - Detailed column verification")
-	log.Println("      GET  /column-report - Human-readable mapping report")
+	log.Println("      GET  /api/settings/validate - Check mappings against live boards")
 	log.Println("      GET  /youtrack-states - Raw YouTrack state debugging")
 	log.Println("      POST /validate-mappings - Cleanup invalid mappings")
 	log.Println("   🎯 ENHANCED FEATURES:")
@@ -35,9 +35,9 @@ func (h *Handler) RegisterRoutes(router *mux.Router, authService *auth.Service)
 	mappings.Use(authService.Middleware)
 
 	// Register routes
-	mappings.HandleFunc("", h.CreateMapping).Methods("POST", "OPTIONS")
+	mappings.HandleFunc("", authService.RequireRole(auth.RoleOperator, h.CreateMapping)).Methods("POST", "OPTIONS")
 	mappings.HandleFunc("", h.GetAllMappings).Methods("GET", "OPTIONS")
-	mappings.HandleFunc("/{id}", h.DeleteMapping).Methods("DELETE", "OPTIONS")
+	mappings.HandleFunc("/{id}", authService.RequireRole(auth.RoleOperator, h.DeleteMapping)).Methods("DELETE", "OPTIONS")
 	mappings.HandleFunc("/asana/{taskId}", h.GetByAsanaID).Methods("GET", "OPTIONS")
 	mappings.HandleFunc("/youtrack/{issueId}", h.GetByYouTrackID).Methods("GET", "OPTIONS")
 }
@@ -0,0 +1,145 @@
+package metrics
+
+import (
+	"math"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func render(c collector) string {
+	var b strings.Builder
+	c.write(&b)
+	return b.String()
+}
+
+func TestCounterVecOutput(t *testing.T) {
+	c := NewCounterVec("test_requests_total", "Requests handled.", "method", "status")
+	c.Inc("GET", "200")
+	c.Inc("GET", "200")
+	c.Add(0.5, "POST", "500")
+
+	want := `# HELP test_requests_total Requests handled.
+# TYPE test_requests_total counter
+test_requests_total{method="GET",status="200"} 2
+test_requests_total{method="POST",status="500"} 0.5
+`
+	if got := render(c); got != want {
+		t.Errorf("output:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestCounterVecWithoutLabels(t *testing.T) {
+	c := NewCounterVec("test_events_total", "Events.")
+	c.Inc()
+
+	want := "# HELP test_events_total Events.\n# TYPE test_events_total counter\ntest_events_total 1\n"
+	if got := render(c); got != want {
+		t.Errorf("output:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestHistogramVecOutput(t *testing.T) {
+	h := NewHistogramVec("test_duration_seconds", "Durations.", []float64{0.1, 1}, "route")
+	h.Observe(0.05, "/a")
+	h.Observe(0.5, "/a")
+	h.Observe(3, "/a")
+
+	want := `# HELP test_duration_seconds Durations.
+# TYPE test_duration_seconds histogram
+test_duration_seconds_bucket{route="/a",le="0.1"} 1
+test_duration_seconds_bucket{route="/a",le="1"} 2
+test_duration_seconds_bucket{route="/a",le="+Inf"} 3
+test_duration_seconds_sum{route="/a"} 3.55
+test_duration_seconds_count{route="/a"} 3
+`
+	if got := render(h); got != want {
+		t.Errorf("output:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestGaugeAndCounterFuncOutput(t *testing.T) {
+	NewGaugeFunc("test_queue_depth", "Queued jobs.", func() float64 { return 7 })
+	NewCounterFunc("test_hits_total", "Cache hits.", func() float64 { return 1e6 })
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{
+		"# TYPE test_queue_depth gauge\ntest_queue_depth 7\n",
+		"# TYPE test_hits_total counter\ntest_hits_total 1e+06\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("output is missing %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestLabelEscaping(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{"plain", "GET", `GET`},
+		{"quote", `say "hi"`, `say \"hi\"`},
+		{"backslash", `C:\path`, `C:\\path`},
+		{"newline", "line1\nline2", `line1\nline2`},
+		{"backslash before quote", `\"`, `\\\"`},
+		{"unicode", "größe ✓", "größe ✓"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := escapeLabel(tt.value); got != tt.want {
+				t.Errorf("escapeLabel(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLabelEscapingInOutput(t *testing.T) {
+	c := NewCounterVec("test_escaped_total", "Help with a \\ backslash\nand a newline.", "path")
+	c.Inc("/a\"b\\c\nd")
+
+	want := `# HELP test_escaped_total Help with a \\ backslash\nand a newline.
+# TYPE test_escaped_total counter
+test_escaped_total{path="/a\"b\\c\nd"} 1
+`
+	if got := render(c); got != want {
+		t.Errorf("output:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	tests := []struct {
+		value float64
+		want  string
+	}{
+		{0, "0"},
+		{42, "42"},
+		{0.25, "0.25"},
+		{1e6, "1e+06"},
+		{math.Inf(1), "+Inf"},
+	}
+
+	for _, tt := range tests {
+		if got := formatValue(tt.value); got != tt.want {
+			t.Errorf("formatValue(%v) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestWrongLabelCountPanics(t *testing.T) {
+	c := NewCounterVec("test_panics_total", "Panics.", "a", "b")
+	defer func() {
+		if recover() == nil {
+			t.Error("Inc with too few label values did not panic")
+		}
+	}()
+	c.Inc("only-one")
+}
@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"strconv"
+	"sync"
+)
+
+// Metrics of the sync engine. Series labelled by user go through UserLabel so
+// the number of series stays bounded.
+var (
+	APIRequests = NewCounterVec("sync_api_requests_total",
+		"Requests sent to the Asana and YouTrack APIs, by endpoint and response status.",
+		"platform", "endpoint", "status")
+	APIRequestDuration = NewHistogramVec("sync_api_request_duration_seconds",
+		"Latency of requests to the Asana and YouTrack APIs.",
+		DefaultBuckets, "platform", "endpoint")
+	AnalysisDuration = NewHistogramVec("sync_analysis_duration_seconds",
+		"Duration of completed ticket analyses.",
+		[]float64{1, 2.5, 5, 10, 20, 30, 60, 120, 300}, "user")
+	Runs = NewCounterVec("sync_runs_total",
+		"Bulk create and sync runs.",
+		"operation", "user")
+	Tickets = NewCounterVec("sync_tickets_total",
+		"Tickets handled by bulk create and sync runs, by outcome.",
+		"operation", "result", "user")
+	AutoLoopLag = NewHistogramVec("sync_auto_loop_lag_seconds",
+		"Delay between an auto-sync or auto-create tick and the start of its run.",
+		[]float64{0.1, 1, 5, 15, 30, 60, 120, 300, 600}, "loop")
+)
+
+// OtherUsers is the user label of users past the per-user limit
+const OtherUsers = "other"
+
+var userLabels = struct {
+	sync.Mutex
+	limit int
+	seen  map[int]bool
+}{seen: make(map[int]bool)}
+
+// SetUserLabelLimit sets how many users get series of their own; later users
+// share the "other" series. 0, the default, aggregates every user under "all".
+func SetUserLabelLimit(limit int) {
+	userLabels.Lock()
+	defer userLabels.Unlock()
+	userLabels.limit = limit
+}
+
+// UserLabel returns the user label value for userID
+func UserLabel(userID int) string {
+	userLabels.Lock()
+	defer userLabels.Unlock()
+
+	if userLabels.limit <= 0 {
+		return "all"
+	}
+	if !userLabels.seen[userID] {
+		if len(userLabels.seen) >= userLabels.limit {
+			return OtherUsers
+		}
+		userLabels.seen[userID] = true
+	}
+	return strconv.Itoa(userID)
+}
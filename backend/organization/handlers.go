@@ -0,0 +1,271 @@
+package organization
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"asana-youtrack-sync/auth"
+	"asana-youtrack-sync/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// Handler handles organization HTTP requests
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new organization handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// RegisterRoutes registers organization routes
+func (h *Handler) RegisterRoutes(router *mux.Router, authService *auth.Service) {
+	orgs := router.PathPrefix("/api/organizations").Subrouter()
+
+	// Add CORS middleware
+	orgs.Use(utils.CORSMiddleware)
+
+	// Apply authentication middleware
+	orgs.Use(authService.Middleware)
+
+	// Register routes
+	orgs.HandleFunc("", h.CreateOrganization).Methods("POST", "OPTIONS")
+	orgs.HandleFunc("/current", h.GetCurrent).Methods("GET", "OPTIONS")
+	orgs.HandleFunc("/current", h.UpdateOrganization).Methods("PUT", "OPTIONS")
+	orgs.HandleFunc("/current", h.DeleteOrganization).Methods("DELETE", "OPTIONS")
+	orgs.HandleFunc("/current/members", h.AddMember).Methods("POST", "OPTIONS")
+	orgs.HandleFunc("/current/members/{user_id}", h.UpdateMember).Methods("PUT", "OPTIONS")
+	orgs.HandleFunc("/current/members/{user_id}", h.RemoveMember).Methods("DELETE", "OPTIONS")
+}
+
+// sendServiceError maps service errors to HTTP responses
+func (h *Handler) sendServiceError(w http.ResponseWriter, r *http.Request, err error, fallback string) {
+	switch err {
+	case ErrNotInOrganization, ErrMemberNotFound, ErrUserNotFound:
+		utils.SendNotFound(w, err.Error())
+	case ErrAdminRequired:
+		if user, ok := auth.GetUserFromContext(r); ok {
+			h.service.LogPermissionDenied(user.UserID, user.Email, r.Method, r.URL.Path)
+		}
+		utils.SendForbidden(w, err.Error())
+	case ErrAlreadyInOrg, ErrLastAdmin:
+		utils.SendConflict(w, err.Error())
+	case ErrInvalidRole, ErrNameRequired:
+		utils.SendBadRequest(w, err.Error())
+	default:
+		utils.SendInternalError(w, fallback)
+	}
+}
+
+// CreateOrganization handles POST /api/organizations
+func (h *Handler) CreateOrganization(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	user, ok := auth.GetUserFromContext(r)
+	if !ok {
+		utils.SendUnauthorized(w, "Authentication required")
+		return
+	}
+
+	var req CreateOrganizationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.SendBadRequest(w, "Invalid request body")
+		return
+	}
+
+	org, err := h.service.CreateOrganization(user.UserID, req)
+	if err != nil {
+		h.sendServiceError(w, r, err, "Failed to create organization")
+		return
+	}
+
+	utils.LogInfo("organization_created", map[string]interface{}{
+		"user_id":         user.UserID,
+		"organization_id": org.ID,
+	})
+	utils.SendCreated(w, org, "Organization created successfully")
+}
+
+// GetCurrent handles GET /api/organizations/current
+func (h *Handler) GetCurrent(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	user, ok := auth.GetUserFromContext(r)
+	if !ok {
+		utils.SendUnauthorized(w, "Authentication required")
+		return
+	}
+
+	org, err := h.service.GetCurrent(user.UserID)
+	if err != nil {
+		h.sendServiceError(w, r, err, "Failed to get organization")
+		return
+	}
+
+	utils.SendSuccess(w, org, "Organization retrieved successfully")
+}
+
+// UpdateOrganization handles PUT /api/organizations/current
+func (h *Handler) UpdateOrganization(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	user, ok := auth.GetUserFromContext(r)
+	if !ok {
+		utils.SendUnauthorized(w, "Authentication required")
+		return
+	}
+
+	var req UpdateOrganizationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.SendBadRequest(w, "Invalid request body")
+		return
+	}
+
+	org, err := h.service.UpdateOrganization(user.UserID, req)
+	if err != nil {
+		h.sendServiceError(w, r, err, "Failed to update organization")
+		return
+	}
+
+	utils.SendSuccess(w, org, "Organization updated successfully")
+}
+
+// DeleteOrganization handles DELETE /api/organizations/current
+func (h *Handler) DeleteOrganization(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	user, ok := auth.GetUserFromContext(r)
+	if !ok {
+		utils.SendUnauthorized(w, "Authentication required")
+		return
+	}
+
+	if err := h.service.DeleteOrganization(user.UserID); err != nil {
+		h.sendServiceError(w, r, err, "Failed to delete organization")
+		return
+	}
+
+	utils.LogInfo("organization_deleted", map[string]interface{}{
+		"user_id": user.UserID,
+	})
+	utils.SendSuccess(w, nil, "Organization deleted successfully")
+}
+
+// AddMember handles POST /api/organizations/current/members
+func (h *Handler) AddMember(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	user, ok := auth.GetUserFromContext(r)
+	if !ok {
+		utils.SendUnauthorized(w, "Authentication required")
+		return
+	}
+
+	var req AddMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.SendBadRequest(w, "Invalid request body")
+		return
+	}
+	if req.User == "" {
+		utils.SendBadRequest(w, "user (username or email) is required")
+		return
+	}
+
+	member, err := h.service.AddMember(user.UserID, req)
+	if err != nil {
+		h.sendServiceError(w, r, err, "Failed to add member")
+		return
+	}
+
+	utils.LogInfo("organization_member_added", map[string]interface{}{
+		"user_id":         user.UserID,
+		"member_id":       member.UserID,
+		"organization_id": member.OrganizationID,
+		"role":            member.Role,
+	})
+	utils.SendCreated(w, member, "Member added successfully")
+}
+
+// UpdateMember handles PUT /api/organizations/current/members/{user_id}
+func (h *Handler) UpdateMember(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	user, ok := auth.GetUserFromContext(r)
+	if !ok {
+		utils.SendUnauthorized(w, "Authentication required")
+		return
+	}
+
+	memberID, err := strconv.Atoi(mux.Vars(r)["user_id"])
+	if err != nil {
+		utils.SendBadRequest(w, "Invalid user ID")
+		return
+	}
+
+	var req UpdateMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.SendBadRequest(w, "Invalid request body")
+		return
+	}
+
+	if err := h.service.UpdateMemberRole(user.UserID, memberID, req); err != nil {
+		h.sendServiceError(w, r, err, "Failed to update member")
+		return
+	}
+
+	utils.SendSuccess(w, nil, "Member updated successfully")
+}
+
+// RemoveMember handles DELETE /api/organizations/current/members/{user_id}
+func (h *Handler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	user, ok := auth.GetUserFromContext(r)
+	if !ok {
+		utils.SendUnauthorized(w, "Authentication required")
+		return
+	}
+
+	memberID, err := strconv.Atoi(mux.Vars(r)["user_id"])
+	if err != nil {
+		utils.SendBadRequest(w, "Invalid user ID")
+		return
+	}
+
+	if err := h.service.RemoveMember(user.UserID, memberID); err != nil {
+		h.sendServiceError(w, r, err, "Failed to remove member")
+		return
+	}
+
+	utils.LogInfo("organization_member_removed", map[string]interface{}{
+		"user_id":   user.UserID,
+		"member_id": memberID,
+	})
+	utils.SendSuccess(w, nil, "Member removed successfully")
+}
@@ -0,0 +1,238 @@
+package organization
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"asana-youtrack-sync/database"
+	"asana-youtrack-sync/utils"
+)
+
+var (
+	ErrNotInOrganization = errors.New("you are not a member of an organization")
+	ErrAlreadyInOrg      = errors.New("user already belongs to an organization")
+	ErrAdminRequired     = errors.New("organization admin role required")
+	ErrLastAdmin         = errors.New("an organization needs at least one admin; promote another member first")
+	ErrInvalidRole       = errors.New("role must be 'viewer', 'operator' or 'admin'")
+	ErrUserNotFound      = errors.New("user not found")
+	ErrMemberNotFound    = errors.New("member not found")
+	ErrNameRequired      = errors.New("organization name is required")
+)
+
+// CreateOrganizationRequest represents a request to create an organization
+type CreateOrganizationRequest struct {
+	Name string `json:"name"`
+}
+
+// UpdateOrganizationRequest represents a request to rename an organization
+type UpdateOrganizationRequest struct {
+	Name string `json:"name"`
+}
+
+// AddMemberRequest adds an existing user by username or email
+type AddMemberRequest struct {
+	User string `json:"user"`
+	Role string `json:"role"`
+}
+
+// UpdateMemberRequest changes a member's role
+type UpdateMemberRequest struct {
+	Role string `json:"role"`
+}
+
+// OrganizationResponse is the organization as seen by one of its members
+type OrganizationResponse struct {
+	*database.Organization
+	Role    string                         `json:"role"`
+	Members []*database.OrganizationMember `json:"members"`
+}
+
+// Service handles organization management
+type Service struct {
+	db *database.DB
+}
+
+// NewService creates a new organization service
+func NewService(db *database.DB) *Service {
+	return &Service{db: db}
+}
+
+// CreateOrganization creates an organization with the user as admin. The
+// user's sync pairs, mappings and ignores become the shared ones.
+func (s *Service) CreateOrganization(userID int, req CreateOrganizationRequest) (*OrganizationResponse, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, ErrNameRequired
+	}
+	if _, err := s.db.GetUserMembership(userID); err == nil {
+		return nil, ErrAlreadyInOrg
+	} else if !errors.Is(err, database.ErrMembershipNotFound) {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	if _, err := s.db.CreateOrganization(userID, name); err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return s.GetCurrent(userID)
+}
+
+// GetCurrent returns the user's organization with its members
+func (s *Service) GetCurrent(userID int) (*OrganizationResponse, error) {
+	membership, err := s.db.GetUserMembership(userID)
+	if err != nil {
+		return nil, ErrNotInOrganization
+	}
+
+	org, err := s.db.GetOrganization(membership.OrganizationID)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	members, err := s.db.GetOrganizationMembers(org.ID)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return &OrganizationResponse{
+		Organization: org,
+		Role:         membership.Role,
+		Members:      members,
+	}, nil
+}
+
+// UpdateOrganization renames the user's organization
+func (s *Service) UpdateOrganization(userID int, req UpdateOrganizationRequest) (*OrganizationResponse, error) {
+	membership, err := s.requireAdmin(userID)
+	if err != nil {
+		return nil, err
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, ErrNameRequired
+	}
+
+	if _, err := s.db.UpdateOrganizationName(membership.OrganizationID, name); err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return s.GetCurrent(userID)
+}
+
+// DeleteOrganization dissolves the user's organization. Shared mappings and
+// ignores go back to the members who created them.
+func (s *Service) DeleteOrganization(userID int) error {
+	membership, err := s.requireAdmin(userID)
+	if err != nil {
+		return err
+	}
+	return s.db.DeleteOrganization(membership.OrganizationID)
+}
+
+// AddMember adds an existing user to the admin's organization
+func (s *Service) AddMember(userID int, req AddMemberRequest) (*database.OrganizationMember, error) {
+	membership, err := s.requireAdmin(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	role := req.Role
+	if role == "" {
+		role = database.RoleOperator
+	}
+	if !validRole(role) {
+		return nil, ErrInvalidRole
+	}
+
+	identifier := strings.TrimSpace(req.User)
+	user, err := s.db.GetUserByUsername(identifier)
+	if err != nil {
+		user, err = s.db.GetUserByEmail(identifier)
+		if err != nil {
+			return nil, ErrUserNotFound
+		}
+	}
+	if _, err := s.db.GetUserMembership(user.ID); err == nil {
+		return nil, ErrAlreadyInOrg
+	} else if !errors.Is(err, database.ErrMembershipNotFound) {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return s.db.AddOrganizationMember(membership.OrganizationID, user.ID, role)
+}
+
+// UpdateMemberRole changes a member's role
+func (s *Service) UpdateMemberRole(userID, memberID int, req UpdateMemberRequest) error {
+	membership, err := s.requireAdmin(userID)
+	if err != nil {
+		return err
+	}
+	if !validRole(req.Role) {
+		return ErrInvalidRole
+	}
+
+	target, err := s.db.GetUserMembership(memberID)
+	if err != nil || target.OrganizationID != membership.OrganizationID {
+		return ErrMemberNotFound
+	}
+	if target.Role == database.RoleAdmin && req.Role != database.RoleAdmin &&
+		s.db.CountOrganizationAdmins(membership.OrganizationID) <= 1 {
+		return ErrLastAdmin
+	}
+
+	return s.db.UpdateOrganizationMemberRole(membership.OrganizationID, memberID, req.Role)
+}
+
+// RemoveMember removes a member. Admins can remove anyone; members can only
+// remove themselves (leave). The last member leaving dissolves the organization.
+func (s *Service) RemoveMember(userID, memberID int) error {
+	membership, err := s.db.GetUserMembership(userID)
+	if err != nil {
+		return ErrNotInOrganization
+	}
+	if memberID != userID && membership.Role != database.RoleAdmin {
+		return ErrAdminRequired
+	}
+
+	target, err := s.db.GetUserMembership(memberID)
+	if err != nil || target.OrganizationID != membership.OrganizationID {
+		return ErrMemberNotFound
+	}
+
+	members, err := s.db.GetOrganizationMembers(membership.OrganizationID)
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	if len(members) == 1 {
+		return s.db.DeleteOrganization(membership.OrganizationID)
+	}
+	if target.Role == database.RoleAdmin && s.db.CountOrganizationAdmins(membership.OrganizationID) <= 1 {
+		return ErrLastAdmin
+	}
+
+	return s.db.RemoveOrganizationMember(membership.OrganizationID, memberID)
+}
+
+// LogPermissionDenied records a rejected admin-only request in the audit log
+func (s *Service) LogPermissionDenied(userID int, email, method, path string) {
+	role := s.db.GetUserRole(userID)
+	if err := s.db.LogPermissionDenied(userID, email, method, path, role, database.RoleAdmin); err != nil {
+		utils.LogError("permission_denied_audit", map[string]interface{}{
+			"user_id": userID,
+			"error":   err.Error(),
+		})
+	}
+}
+
+func (s *Service) requireAdmin(userID int) (*database.OrganizationMember, error) {
+	membership, err := s.db.GetUserMembership(userID)
+	if err != nil {
+		return nil, ErrNotInOrganization
+	}
+	if membership.Role != database.RoleAdmin {
+		return nil, ErrAdminRequired
+	}
+	return membership, nil
+}
+
+func validRole(role string) bool {
+	return database.IsValidRole(role)
+}
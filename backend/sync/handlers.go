@@ -52,6 +52,7 @@ func HandleRollback(
 
 		// Perform rollback
 		result, err := rollbackRestoreService.PerformRollback(
+			r.Context(),
 			operationID,
 			userID,
 			user.Email,
@@ -285,3 +286,111 @@ func HandleGetOperationAuditLogs(auditService *AuditService) http.HandlerFunc {
 		})
 	}
 }
+
+// HandleListSnapshots lists the current user's rollback snapshots
+func HandleListSnapshots(snapshotService *SnapshotService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, ok := auth.GetUserFromContext(r)
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		limit := 50
+		if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+			if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+				limit = l
+			}
+		}
+
+		snapshots, err := snapshotService.ListSnapshots(user.UserID, limit)
+		if err != nil {
+			http.Error(w, "Failed to list snapshots: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":   true,
+			"snapshots": snapshots,
+			"count":     len(snapshots),
+		})
+	}
+}
+
+// HandleSetSnapshotPinned pins or unpins a snapshot so it is exempt from expiry and limits
+func HandleSetSnapshotPinned(snapshotService *SnapshotService, pinned bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, ok := auth.GetUserFromContext(r)
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		vars := mux.Vars(r)
+		snapshotID, err := strconv.Atoi(vars["id"])
+		if err != nil {
+			http.Error(w, "Invalid snapshot ID", http.StatusBadRequest)
+			return
+		}
+
+		if err := snapshotService.SetSnapshotPinned(user.UserID, snapshotID, pinned); err != nil {
+			http.Error(w, "Failed to update snapshot: "+err.Error(), http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":     true,
+			"snapshot_id": snapshotID,
+			"pinned":      pinned,
+		})
+	}
+}
+
+// HandleRetentionPolicy gets (GET) or updates (PUT) the user's snapshot retention policy
+func HandleRetentionPolicy(snapshotService *SnapshotService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, ok := auth.GetUserFromContext(r)
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		var policy *database.SnapshotRetentionPolicy
+		var err error
+
+		switch r.Method {
+		case "GET":
+			policy, err = snapshotService.GetRetentionPolicy(user.UserID)
+			if err != nil {
+				http.Error(w, "Failed to get retention policy: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+		case "PUT":
+			var req database.SnapshotRetentionPolicy
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, "Invalid request body", http.StatusBadRequest)
+				return
+			}
+			req.UserID = user.UserID
+
+			policy, err = snapshotService.UpdateRetentionPolicy(&req)
+			if err != nil {
+				http.Error(w, "Failed to update retention policy: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"policy":  policy,
+		})
+	}
+}
@@ -180,9 +180,15 @@ func (rs *RollbackService) CanRollback(operationID int) (bool, string) {
 		return false, "Operation is not completed"
 	}
 
-	// Check if operation is too old (optional business rule)
-	if time.Since(operation.CreatedAt) > 24*time.Hour {
-		return false, "Operation is too old to rollback (>24 hours)"
+	// Check the snapshot against the user's retention policy
+	if snapshot, err := rs.db.GetSnapshotByOperationID(operationID); err == nil {
+		if snapshot.IsExpired() {
+			return false, "Operation snapshot has expired"
+		}
+	} else if policy, err := rs.db.GetSnapshotRetentionPolicy(operation.UserID); err == nil {
+		if time.Since(operation.CreatedAt) > time.Duration(policy.MaxAgeHours)*time.Hour {
+			return false, fmt.Sprintf("Operation is too old to rollback (>%d hours)", policy.MaxAgeHours)
+		}
 	}
 
 	return true, ""
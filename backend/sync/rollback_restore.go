@@ -2,6 +2,7 @@ package sync
 
 import (
 	"asana-youtrack-sync/database"
+	"context"
 	"fmt"
 	"log"
 	"time"
@@ -35,7 +36,7 @@ type RollbackResult struct {
 }
 
 // PerformRollback executes the complete rollback operation
-func (rrs *RollbackRestoreService) PerformRollback(operationID, userID int, userEmail string, youtrackService YouTrackDeleter, asanaService AsanaDeleter) (*RollbackResult, error) {
+func (rrs *RollbackRestoreService) PerformRollback(ctx context.Context, operationID, userID int, userEmail string, youtrackService YouTrackDeleter, asanaService AsanaDeleter) (*RollbackResult, error) {
 	result := &RollbackResult{
 		Success: false,
 		Errors:  []string{},
@@ -64,8 +65,8 @@ func (rrs *RollbackRestoreService) PerformRollback(operationID, userID int, user
 	}
 
 	// Check expiration
-	if time.Now().After(snapshot.ExpiresAt) {
-		return nil, fmt.Errorf("snapshot has expired, cannot rollback operations older than 24 hours")
+	if snapshot.IsExpired() {
+		return nil, fmt.Errorf("snapshot expired at %s, outside the retention window", snapshot.ExpiresAt.Format(time.RFC3339))
 	}
 
 	log.Printf("RollbackRestore: Starting rollback for operation %d\n", operationID)
@@ -86,9 +87,9 @@ func (rrs *RollbackRestoreService) PerformRollback(operationID, userID int, user
 	for _, created := range snapshot.SnapshotData.CreatedTickets {
 		var err error
 		if created.Platform == "youtrack" {
-			err = youtrackService.DeleteIssue(userID, created.TicketID)
+			err = youtrackService.DeleteIssue(ctx, userID, created.TicketID)
 		} else if created.Platform == "asana" {
-			err = asanaService.DeleteTask(userID, created.TicketID)
+			err = asanaService.DeleteTask(ctx, userID, created.TicketID)
 		}
 
 		if err != nil {
@@ -108,9 +109,9 @@ func (rrs *RollbackRestoreService) PerformRollback(operationID, userID int, user
 	for _, ticketState := range snapshot.SnapshotData.OriginalTickets {
 		var err error
 		if ticketState.Platform == "youtrack" {
-			err = youtrackService.UpdateIssueStatus(userID, ticketState.TicketID, ticketState.OriginalStatus)
+			err = youtrackService.UpdateIssueStatus(ctx, userID, ticketState.TicketID, ticketState.OriginalStatus)
 		} else if ticketState.Platform == "asana" {
-			err = asanaService.UpdateTaskStatus(userID, ticketState.TicketID, ticketState.OriginalStatus)
+			err = asanaService.UpdateTaskStatus(ctx, userID, ticketState.TicketID, ticketState.OriginalStatus)
 		}
 
 		if err != nil {
@@ -163,11 +164,11 @@ func (rrs *RollbackRestoreService) PerformRollback(operationID, userID int, user
 		}
 
 		// Remove current ignore state
-		rrs.db.RemoveIgnoredTicket(userID, settings.AsanaProjectID, ignoreChange.TicketID, "")
+		rrs.db.RemoveIgnoredTicket(userID, settings.SyncPairID, ignoreChange.TicketID, "")
 
 		// Restore old ignore state
 		if ignoreChange.OldIgnoreType != "none" {
-			_, err := rrs.db.AddIgnoredTicket(userID, settings.AsanaProjectID, ignoreChange.TicketID, ignoreChange.OldIgnoreType)
+			_, err := rrs.db.AddIgnoredTicket(userID, settings.SyncPairID, settings.AsanaProjectID, ignoreChange.TicketID, ignoreChange.OldIgnoreType)
 			if err != nil {
 				result.Errors = append(result.Errors, fmt.Sprintf("Failed to restore ignore state for %s: %v", ignoreChange.TicketID, err))
 			} else {
@@ -223,8 +224,8 @@ func (rrs *RollbackRestoreService) CanRollback(operationID int) (bool, string) {
 		return false, "Snapshot not found"
 	}
 
-	if time.Now().After(snapshot.ExpiresAt) {
-		return false, "Snapshot expired (>24 hours old)"
+	if snapshot.IsExpired() {
+		return false, fmt.Sprintf("Snapshot expired at %s (pin snapshots or extend the retention policy to keep them longer)", snapshot.ExpiresAt.Format(time.RFC3339))
 	}
 
 	return true, ""
@@ -232,11 +233,11 @@ func (rrs *RollbackRestoreService) CanRollback(operationID int) (bool, string) {
 
 // Interface definitions for external services (for dependency injection)
 type YouTrackDeleter interface {
-	DeleteIssue(userID int, issueID string) error
-	UpdateIssueStatus(userID int, issueID, status string) error
+	DeleteIssue(ctx context.Context, userID int, issueID string) error
+	UpdateIssueStatus(ctx context.Context, userID int, issueID, status string) error
 }
 
 type AsanaDeleter interface {
-	DeleteTask(userID int, taskID string) error
-	UpdateTaskStatus(userID int, taskID, status string) error
+	DeleteTask(ctx context.Context, userID int, taskID string) error
+	UpdateTaskStatus(ctx context.Context, userID int, taskID, status string) error
 }
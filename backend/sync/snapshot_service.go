@@ -39,7 +39,7 @@ func (ss *SnapshotService) CreatePreSyncSnapshot(userID, operationID int, syncTy
 		snapshot.SnapshotData.ColumnMappings = settings.ColumnMappings
 	}
 
-	// Create the snapshot (this will enforce the 15-snapshot limit)
+	// Create the snapshot (this will enforce the user's retention policy)
 	createdSnapshot, err := ss.db.CreateRollbackSnapshot(snapshot)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create snapshot: %w", err)
@@ -193,12 +193,16 @@ func (ss *SnapshotService) GetSnapshotSummary(operationID int) (*SnapshotSummary
 	if operation.Status != "completed" {
 		canRollback = false
 	}
-	if time.Now().After(snapshot.ExpiresAt) {
+	if snapshot.IsExpired() {
 		canRollback = false
 	}
 
 	summary.CanRollback = canRollback
-	summary.RollbackDeadline = snapshot.ExpiresAt
+	summary.Pinned = snapshot.Pinned
+	summary.Compacted = snapshot.Compacted
+	if !snapshot.Pinned {
+		summary.RollbackDeadline = snapshot.ExpiresAt
+	}
 
 	return summary, nil
 }
@@ -212,6 +216,8 @@ type SnapshotSummary struct {
 	MappingsChanged  int       `json:"mappings_changed"`
 	IgnoreChanges    int       `json:"ignore_changes"`
 	CanRollback      bool      `json:"can_rollback"`
+	Pinned           bool      `json:"pinned"`
+	Compacted        bool      `json:"compacted"`
 	RollbackDeadline time.Time `json:"rollback_deadline,omitempty"`
 }
 
@@ -219,3 +225,92 @@ type SnapshotSummary struct {
 func (ss *SnapshotService) CleanupExpiredSnapshots() error {
 	return ss.db.CleanupExpiredSnapshots()
 }
+
+// ListSnapshots returns the user's most recent snapshots, newest first
+func (ss *SnapshotService) ListSnapshots(userID, limit int) ([]*SnapshotListItem, error) {
+	snapshots, err := ss.db.GetUserSnapshots(userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	items := make([]*SnapshotListItem, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		items = append(items, &SnapshotListItem{
+			ID:             snapshot.ID,
+			OperationID:    snapshot.OperationID,
+			CreatedAt:      snapshot.CreatedAt,
+			ExpiresAt:      snapshot.ExpiresAt,
+			Pinned:         snapshot.Pinned,
+			Compacted:      snapshot.Compacted,
+			Expired:        snapshot.IsExpired(),
+			SizeBytes:      snapshot.SizeBytes,
+			TicketsCreated: len(snapshot.SnapshotData.CreatedTickets),
+			TicketsUpdated: len(snapshot.SnapshotData.OriginalTickets),
+		})
+	}
+	return items, nil
+}
+
+// SnapshotListItem is a lightweight view of a snapshot for listing
+type SnapshotListItem struct {
+	ID             int       `json:"id"`
+	OperationID    int       `json:"operation_id"`
+	CreatedAt      time.Time `json:"created_at"`
+	ExpiresAt      time.Time `json:"expires_at"`
+	Pinned         bool      `json:"pinned"`
+	Compacted      bool      `json:"compacted"`
+	Expired        bool      `json:"expired"`
+	SizeBytes      int64     `json:"size_bytes"`
+	TicketsCreated int       `json:"tickets_created"`
+	TicketsUpdated int       `json:"tickets_updated"`
+}
+
+// SetSnapshotPinned pins or unpins one of the user's snapshots
+func (ss *SnapshotService) SetSnapshotPinned(userID, snapshotID int, pinned bool) error {
+	return ss.db.SetSnapshotPinned(userID, snapshotID, pinned)
+}
+
+// GetRetentionPolicy returns the user's snapshot retention policy
+func (ss *SnapshotService) GetRetentionPolicy(userID int) (*database.SnapshotRetentionPolicy, error) {
+	return ss.db.GetSnapshotRetentionPolicy(userID)
+}
+
+// UpdateRetentionPolicy validates and stores the user's snapshot retention policy
+func (ss *SnapshotService) UpdateRetentionPolicy(policy *database.SnapshotRetentionPolicy) (*database.SnapshotRetentionPolicy, error) {
+	if policy.MaxAgeHours < 1 || policy.MaxAgeHours > maxSnapshotAgeHours {
+		return nil, fmt.Errorf("max_age_hours must be between 1 and %d", maxSnapshotAgeHours)
+	}
+	if policy.MaxCount < 1 || policy.MaxCount > maxSnapshotCount {
+		return nil, fmt.Errorf("max_count must be between 1 and %d", maxSnapshotCount)
+	}
+	if policy.MaxStorageBytes < 0 {
+		return nil, fmt.Errorf("max_storage_bytes cannot be negative")
+	}
+	if policy.CompactAfterHours < 0 {
+		return nil, fmt.Errorf("compact_after_hours cannot be negative")
+	}
+	return ss.db.UpsertSnapshotRetentionPolicy(policy)
+}
+
+// Upper bounds for user-configurable retention
+const (
+	maxSnapshotAgeHours = 24 * 365
+	maxSnapshotCount    = 500
+)
+
+// RunRetentionJob compacts aged snapshots and removes expired ones on every tick.
+// It blocks, so start it in its own goroutine.
+func (ss *SnapshotService) RunRetentionJob(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := ss.db.CompactSnapshots(); err != nil {
+			log.Printf("SnapshotService: Compaction failed: %v\n", err)
+		}
+		if err := ss.db.CleanupExpiredSnapshots(); err != nil {
+			log.Printf("SnapshotService: Expired snapshot cleanup failed: %v\n", err)
+		}
+		<-ticker.C
+	}
+}
@@ -1,359 +1,212 @@
 package utils
 
 import (
-	"regexp"
+	"fmt"
 	"strings"
 )
 
-// ConvertAsanaHTMLToYouTrackMarkdown converts Asana HTML formatting to YouTrack markdown
+// ConvertAsanaHTMLToYouTrackMarkdown converts Asana rich text (html_notes) to YouTrack markdown
 func ConvertAsanaHTMLToYouTrackMarkdown(htmlText string) string {
+	return ConvertAsanaHTMLToYouTrackMarkdownWithLinks(htmlText, nil)
+}
+
+// ConvertAsanaHTMLToYouTrackMarkdownWithLinks converts Asana rich text to
+// YouTrack markdown, turning mentions and task links that links can resolve
+// into @login and issue IDs
+func ConvertAsanaHTMLToYouTrackMarkdownWithLinks(htmlText string, links *DescriptionLinks) string {
 	if htmlText == "" {
 		return ""
 	}
+	return renderMarkdown(links.toYouTrack(parseAsanaHTML(htmlText)))
+}
 
-	text := htmlText
-
-	// Convert HTML paragraphs to line breaks
-	text = regexp.MustCompile(`<\/p>\s*<p>`).ReplaceAllString(text, "\n\n")
-	text = strings.ReplaceAll(text, "<p>", "")
-	text = strings.ReplaceAll(text, "</p>", "\n")
-
-	// Convert line breaks
-	text = strings.ReplaceAll(text, "<br>", "\n")
-	text = strings.ReplaceAll(text, "<br/>", "\n")
-	text = strings.ReplaceAll(text, "<br />", "\n")
-
-	// Convert bold formatting
-	// <strong>text</strong> or <b>text</b> -> *text*
-	text = regexp.MustCompile(`<strong>(.*?)</strong>`).ReplaceAllString(text, "*$1*")
-	text = regexp.MustCompile(`<b>(.*?)</b>`).ReplaceAllString(text, "*$1*")
-
-	// Convert italic formatting
-	// <em>text</em> or <i>text</i> -> _text_
-	text = regexp.MustCompile(`<em>(.*?)</em>`).ReplaceAllString(text, "_$1_")
-	text = regexp.MustCompile(`<i>(.*?)</i>`).ReplaceAllString(text, "_$1_")
-
-	// Convert strikethrough formatting
-	// <s>text</s> or <strike>text</strike> or <del>text</del> -> ~~text~~
-	text = regexp.MustCompile(`<s>(.*?)</s>`).ReplaceAllString(text, "~~$1~~")
-	text = regexp.MustCompile(`<strike>(.*?)</strike>`).ReplaceAllString(text, "~~$1~~")
-	text = regexp.MustCompile(`<del>(.*?)</del>`).ReplaceAllString(text, "~~$1~~")
-
-	// Convert inline code
-	// <code>text</code> -> `text`
-	text = regexp.MustCompile(`<code>(.*?)</code>`).ReplaceAllString(text, "`$1`")
-
-	// Convert code blocks
-	// <pre>code</pre> -> ```\ncode\n```
-	text = regexp.MustCompile(`<pre>(.*?)</pre>`).ReplaceAllString(text, "```\n$1\n```")
-
-	// Convert unordered lists
-	// <ul><li>item</li></ul> -> * item
-	text = regexp.MustCompile(`<ul>\s*`).ReplaceAllString(text, "")
-	text = regexp.MustCompile(`\s*</ul>`).ReplaceAllString(text, "\n")
-	text = regexp.MustCompile(`<li>(.*?)</li>`).ReplaceAllString(text, "* $1\n")
-
-	// Convert ordered lists
-	// <ol><li>item</li></ol> -> 1. item
-	olPattern := regexp.MustCompile(`<ol>(.*?)</ol>`)
-	text = olPattern.ReplaceAllStringFunc(text, func(match string) string {
-		items := regexp.MustCompile(`<li>(.*?)</li>`).FindAllStringSubmatch(match, -1)
-		result := ""
-		for i, item := range items {
-			if len(item) > 1 {
-				result += strings.Repeat(" ", 0) + string(rune(i+1)) + ". " + item[1] + "\n"
-			}
-		}
-		return result
-	})
-
-	// Convert blockquotes
-	// <blockquote>text</blockquote> -> > text
-	text = regexp.MustCompile(`<blockquote>(.*?)</blockquote>`).ReplaceAllStringFunc(text, func(match string) string {
-		content := regexp.MustCompile(`<blockquote>(.*?)</blockquote>`).FindStringSubmatch(match)
-		if len(content) > 1 {
-			lines := strings.Split(strings.TrimSpace(content[1]), "\n")
-			for i, line := range lines {
-				lines[i] = "> " + strings.TrimSpace(line)
-			}
-			return strings.Join(lines, "\n")
-		}
-		return match
-	})
-
-	// Convert headings
-	// <h1>text</h1> -> # text
-	// <h2>text</h2> -> ## text
-	// <h3>text</h3> -> ### text
-	text = regexp.MustCompile(`<h1>(.*?)</h1>`).ReplaceAllString(text, "# $1\n")
-	text = regexp.MustCompile(`<h2>(.*?)</h2>`).ReplaceAllString(text, "## $1\n")
-	text = regexp.MustCompile(`<h3>(.*?)</h3>`).ReplaceAllString(text, "### $1\n")
-	text = regexp.MustCompile(`<h4>(.*?)</h4>`).ReplaceAllString(text, "#### $1\n")
-	text = regexp.MustCompile(`<h5>(.*?)</h5>`).ReplaceAllString(text, "##### $1\n")
-	text = regexp.MustCompile(`<h6>(.*?)</h6>`).ReplaceAllString(text, "###### $1\n")
-
-	// Convert horizontal rules
-	text = strings.ReplaceAll(text, "<hr>", "---\n")
-	text = strings.ReplaceAll(text, "<hr/>", "---\n")
-	text = strings.ReplaceAll(text, "<hr />", "---\n")
-
-	// Convert links
-	// <a href="url">text</a> -> [text](url)
-	text = regexp.MustCompile(`<a href="(.*?)">(.*?)</a>`).ReplaceAllString(text, "[$2]($1)")
-
-	// Clean up any remaining HTML tags
-	text = regexp.MustCompile(`<[^>]+>`).ReplaceAllString(text, "")
-
-	// Clean up multiple newlines
-	text = regexp.MustCompile(`\n{3,}`).ReplaceAllString(text, "\n\n")
-
-	// Decode HTML entities
-	text = strings.ReplaceAll(text, "&nbsp;", " ")
-	text = strings.ReplaceAll(text, "&lt;", "<")
-	text = strings.ReplaceAll(text, "&gt;", ">")
-	text = strings.ReplaceAll(text, "&amp;", "&")
-	text = strings.ReplaceAll(text, "&quot;", "\"")
-	text = strings.ReplaceAll(text, "&#39;", "'")
-
-	return strings.TrimSpace(text)
+// ConvertYouTrackMarkdownToAsanaHTML converts YouTrack markdown to Asana rich text.
+// Converting the result back with ConvertAsanaHTMLToYouTrackMarkdown returns
+// the same markdown, so descriptions don't drift between syncs.
+func ConvertYouTrackMarkdownToAsanaHTML(markdown string) string {
+	return ConvertYouTrackMarkdownToAsanaHTMLWithLinks(markdown, nil)
 }
 
-// VERSION 1: Clean HTML with <body> wrapper
-func ConvertYouTrackWikifiedToAsanaHTML_V1(wikified string) string {
-	if wikified == "" {
+// ConvertYouTrackMarkdownToAsanaHTMLWithLinks converts YouTrack markdown to
+// Asana rich text, turning @login mentions and issue IDs that links can
+// resolve into Asana mentions and task links
+func ConvertYouTrackMarkdownToAsanaHTMLWithLinks(markdown string, links *DescriptionLinks) string {
+	if markdown == "" {
 		return ""
 	}
-	text := wikified
-	text = regexp.MustCompile(`<div[^>]*class="[^"]*wiki[^"]*"[^>]*>`).ReplaceAllString(text, "")
-	text = strings.Replace(text, "</div>", "", 1)
-	text = strings.ReplaceAll(text, `\n`, "")
-	text = strings.ReplaceAll(text, "\n", "")
-	text = regexp.MustCompile(`<img[^>]*>`).ReplaceAllString(text, "")
-	text = regexp.MustCompile(`!\[.*?\]\([^)]+\)(?:\{[^}]+\})?`).ReplaceAllString(text, "")
-	text = regexp.MustCompile(`<p>\s*</p>`).ReplaceAllString(text, "")
-	text = regexp.MustCompile(`<br\s*/?>(\s*<br\s*/?>)+`).ReplaceAllString(text, "<br/>")
-	text = strings.TrimSpace(text)
-	if text != "" {
-		text = "<body>" + text + "</body>"
-	}
-	return text
+	return renderAsanaHTML(links.toAsana(parseMarkdown(markdown)))
 }
 
-// VERSION 2: Clean HTML WITHOUT <body> wrapper
-func ConvertYouTrackWikifiedToAsanaHTML_V2(wikified string) string {
+// ConvertYouTrackWikifiedToAsanaHTML converts YouTrack's rendered description
+// (wikifiedDescription) to Asana rich text
+func ConvertYouTrackWikifiedToAsanaHTML(wikified string) string {
 	if wikified == "" {
 		return ""
 	}
-	text := wikified
-	text = regexp.MustCompile(`<div[^>]*class="[^"]*wiki[^"]*"[^>]*>`).ReplaceAllString(text, "")
-	text = strings.Replace(text, "</div>", "", 1)
-	text = strings.ReplaceAll(text, `\n`, "")
-	text = strings.ReplaceAll(text, "\n", "")
-	text = regexp.MustCompile(`<img[^>]*>`).ReplaceAllString(text, "")
-	text = regexp.MustCompile(`!\[.*?\]\([^)]+\)(?:\{[^}]+\})?`).ReplaceAllString(text, "")
-	text = regexp.MustCompile(`<p>\s*</p>`).ReplaceAllString(text, "")
-	text = regexp.MustCompile(`<br\s*/?>(\s*<br\s*/?>)+`).ReplaceAllString(text, "<br/>")
-	return strings.TrimSpace(text)
+	return renderAsanaHTML(parseWikifiedHTML(wikified))
 }
 
-// VERSION 3: Keep images, add <body>
-func ConvertYouTrackWikifiedToAsanaHTML_V3(wikified string) string {
-	if wikified == "" {
+// ConvertYouTrackMarkdownToPlainText strips markdown formatting, keeping
+// list markers and link targets readable
+func ConvertYouTrackMarkdownToPlainText(markdown string) string {
+	if markdown == "" {
 		return ""
 	}
-	text := wikified
-	text = regexp.MustCompile(`<div[^>]*class="[^"]*wiki[^"]*"[^>]*>`).ReplaceAllString(text, "")
-	text = strings.Replace(text, "</div>", "", 1)
-	text = strings.ReplaceAll(text, `\n`, "")
-	text = strings.ReplaceAll(text, "\n", "")
-	// Keep images
-	text = regexp.MustCompile(`!\[.*?\]\([^)]+\)(?:\{[^}]+\})?`).ReplaceAllString(text, "")
-	text = strings.TrimSpace(text)
-	if text != "" {
-		text = "<body>" + text + "</body>"
-	}
-	return text
+	return renderPlainText(parseMarkdown(markdown))
 }
 
-// VERSION 4: Minimal processing with <body>
-func ConvertYouTrackWikifiedToAsanaHTML_V4(wikified string) string {
-	if wikified == "" {
+// ─── Asana HTML Rendering ───────────────────────────────────────────────────
+//
+// Asana accepts a small subset of HTML inside <body>: newlines instead of <p>
+// and <br>, only h1 and h2 headings, and images and mentions referenced by
+// data-asana-gid.
+
+func renderAsanaHTML(blocks []*richBlock) string {
+	// Normalize again once images are replaced, so e.g. the space before an
+	// image that renders as nothing doesn't end up at the start of a line
+	content := renderAsanaBlocks(normalizeBlocks(mapBlockInlines(blocks, asanaImages)))
+	if content == "" {
 		return ""
 	}
-	text := wikified
-	text = regexp.MustCompile(`<div[^>]*>`).ReplaceAllString(text, "")
-	text = strings.ReplaceAll(text, "</div>", "")
-	text = strings.ReplaceAll(text, `\n`, "")
-	text = strings.TrimSpace(text)
-	if text != "" {
-		text = "<body>" + text + "</body>"
-	}
-	return text
+	return "<body>" + content + "</body>"
 }
 
-// VERSION 5: Strip all HTML, keep text only
-func ConvertYouTrackWikifiedToAsanaHTML_V5(wikified string) string {
-	if wikified == "" {
-		return ""
+func renderAsanaBlocks(blocks []*richBlock) string {
+	var b strings.Builder
+	for i, block := range blocks {
+		if i > 0 && block.Kind == blockParagraph && blocks[i-1].Kind == blockParagraph {
+			b.WriteString("\n\n")
+		}
+		b.WriteString(renderAsanaBlock(block))
 	}
-	// Extract text content only
-	text := regexp.MustCompile(`<[^>]*>`).ReplaceAllString(wikified, "")
-	text = strings.ReplaceAll(text, `\n`, "\n")
-	return strings.TrimSpace(text)
+	return b.String()
 }
 
-// Main function that tries all versions
-func ConvertYouTrackWikifiedToAsanaHTML(wikified string) string {
-	return ConvertYouTrackWikifiedToAsanaHTML_V2(wikified)
-}
+func renderAsanaBlock(block *richBlock) string {
+	switch block.Kind {
+	case blockParagraph:
+		return renderAsanaInlines(block.Inlines)
 
-// ConvertYouTrackMarkdownToAsanaHTML converts YouTrack markdown to Asana HTML formatting
-func ConvertYouTrackMarkdownToAsanaHTML(markdown string) string {
-	if markdown == "" {
-		return ""
-	}
+	case blockHeading:
+		level := block.Level
+		if level > 2 {
+			level = 2
+		}
+		return fmt.Sprintf("<h%d>%s</h%d>", level, renderAsanaInlines(block.Inlines), level)
 
-	text := markdown
+	case blockRule:
+		return "<hr/>"
 
-	// Step 1: Process line by line to handle structure (lists, blockquotes, paragraphs)
-	lines := strings.Split(text, "\n")
-	var result []string
-	inList := false
-	listType := ""
+	case blockCode:
+		return "<pre>" + escapeHTMLText(block.Text) + "</pre>"
 
-	for i, line := range lines {
-		trimmed := strings.TrimSpace(line)
+	case blockQuote:
+		return "<blockquote>" + renderAsanaBlocks(block.Children) + "</blockquote>"
 
-		// Check for heading markers first
-		if regexp.MustCompile(`^#{1,6}\s+`).MatchString(trimmed) {
-			// Close any open list
-			if inList {
-				if listType == "ul" {
-					result = append(result, "</ul>")
-				} else {
-					result = append(result, "</ol>")
-				}
-				inList = false
-				listType = ""
-			}
-			result = append(result, line) // Keep as-is for now
-			continue
+	case blockList:
+		tag := "ul"
+		if block.Ordered {
+			tag = "ol"
 		}
-
-		// Check for horizontal rule
-		if regexp.MustCompile(`^(---+|\*\*\*+)$`).MatchString(trimmed) {
-			if inList {
-				if listType == "ul" {
-					result = append(result, "</ul>")
-				} else {
-					result = append(result, "</ol>")
-				}
-				inList = false
-				listType = ""
+		var b strings.Builder
+		b.WriteString("<" + tag + ">")
+		for _, item := range block.Items {
+			b.WriteString("<li>" + renderAsanaBlocks(item) + "</li>")
+		}
+		b.WriteString("</" + tag + ">")
+		return b.String()
+
+	case blockTable:
+		var b strings.Builder
+		b.WriteString("<table>")
+		for _, row := range block.Rows {
+			b.WriteString("<tr>")
+			for _, cell := range row {
+				b.WriteString("<td>" + renderAsanaInlines(cell) + "</td>")
 			}
-			result = append(result, line)
-			continue
+			b.WriteString("</tr>")
 		}
+		b.WriteString("</table>")
+		return b.String()
+	}
+	return ""
+}
 
-		// Check for unordered list item
-		if regexp.MustCompile(`^[\*\-]\s+`).MatchString(trimmed) {
-			item := regexp.MustCompile(`^[\*\-]\s+`).ReplaceAllString(trimmed, "")
-			if !inList || listType != "ul" {
-				if inList && listType == "ol" {
-					result = append(result, "</ol>")
-				}
-				result = append(result, "<ul>")
-				inList = true
-				listType = "ul"
-			}
-			result = append(result, "<li>"+item+"</li>")
-		} else if regexp.MustCompile(`^\d+\.\s+`).MatchString(trimmed) {
-			// Ordered list item
-			item := regexp.MustCompile(`^\d+\.\s+`).ReplaceAllString(trimmed, "")
-			if !inList || listType != "ol" {
-				if inList && listType == "ul" {
-					result = append(result, "</ul>")
-				}
-				result = append(result, "<ol>")
-				inList = true
-				listType = "ol"
-			}
-			result = append(result, "<li>"+item+"</li>")
-		} else {
-			// Not a list item - close list if open
-			if inList {
-				if listType == "ul" {
-					result = append(result, "</ul>")
-				} else {
-					result = append(result, "</ol>")
+func renderAsanaInlines(nodes []*richInline) string {
+	var b strings.Builder
+	for _, n := range nodes {
+		switch n.Kind {
+		case inlineText:
+			b.WriteString(escapeHTMLText(n.Text))
+		case inlineBreak:
+			b.WriteString("\n")
+		case inlineBold:
+			b.WriteString("<strong>" + renderAsanaInlines(n.Children) + "</strong>")
+		case inlineItalic:
+			b.WriteString("<em>" + renderAsanaInlines(n.Children) + "</em>")
+		case inlineUnderline:
+			b.WriteString("<u>" + renderAsanaInlines(n.Children) + "</u>")
+		case inlineStrike:
+			b.WriteString("<s>" + renderAsanaInlines(n.Children) + "</s>")
+		case inlineCode:
+			b.WriteString("<code>" + escapeHTMLText(n.Text) + "</code>")
+		case inlineLink:
+			b.WriteString(`<a href="` + escapeHTMLAttr(n.Href) + `"`)
+			if n.GID != "" {
+				b.WriteString(` data-asana-gid="` + escapeHTMLAttr(n.GID) + `"`)
+				if n.GIDType != "" {
+					b.WriteString(` data-asana-type="` + escapeHTMLAttr(n.GIDType) + `"`)
 				}
-				inList = false
-				listType = ""
 			}
+			b.WriteString(">" + renderAsanaInlines(n.Children) + "</a>")
+		case inlineImage:
+			b.WriteString(renderAsanaImage(n))
+		}
+	}
+	return b.String()
+}
 
-			// Check for blockquote
-			if strings.HasPrefix(trimmed, ">") {
-				quoted := strings.TrimSpace(strings.TrimPrefix(trimmed, ">"))
-				result = append(result, "<blockquote>"+quoted+"</blockquote>")
-			} else if trimmed != "" {
-				// Regular paragraph
-				result = append(result, "<p>"+line+"</p>")
-			} else {
-				// Empty line
-				if i > 0 && i < len(lines)-1 {
-					result = append(result, "<br>")
+// asanaImages replaces images Asana doesn't know about with links, or just
+// their alt text when the source is relative (e.g. a YouTrack attachment URL)
+func asanaImages(nodes []*richInline) []*richInline {
+	var out []*richInline
+	for _, n := range nodes {
+		switch {
+		case n.Kind == inlineImage && n.GID == "":
+			if strings.HasPrefix(n.Href, "http://") || strings.HasPrefix(n.Href, "https://") {
+				text := n.Text
+				if text == "" {
+					text = n.Href
 				}
+				out = append(out, &richInline{Kind: inlineLink, Href: n.Href, Children: []*richInline{{Kind: inlineText, Text: text}}})
+			} else if n.Text != "" {
+				out = append(out, &richInline{Kind: inlineText, Text: n.Text})
 			}
+			continue
+		case n.Kind == inlineLink || isFormattingKind(n.Kind):
+			n.Children = asanaImages(n.Children)
 		}
+		out = append(out, n)
 	}
+	return out
+}
 
-	// Close any open lists
-	if inList {
-		if listType == "ul" {
-			result = append(result, "</ul>")
-		} else {
-			result = append(result, "</ol>")
-		}
+// renderAsanaImage writes an inline image Asana knows about
+func renderAsanaImage(n *richInline) string {
+	s := `<img data-asana-gid="` + escapeHTMLAttr(n.GID) + `"`
+	if n.Href != "" {
+		s += ` src="` + escapeHTMLAttr(n.Href) + `"`
 	}
-
-	text = strings.Join(result, "\n")
-
-	// Step 2: Convert headings
-	text = regexp.MustCompile(`(?m)^######\s+(.*?)$`).ReplaceAllString(text, "<h6>$1</h6>")
-	text = regexp.MustCompile(`(?m)^#####\s+(.*?)$`).ReplaceAllString(text, "<h5>$1</h5>")
-	text = regexp.MustCompile(`(?m)^####\s+(.*?)$`).ReplaceAllString(text, "<h4>$1</h4>")
-	text = regexp.MustCompile(`(?m)^###\s+(.*?)$`).ReplaceAllString(text, "<h3>$1</h3>")
-	text = regexp.MustCompile(`(?m)^##\s+(.*?)$`).ReplaceAllString(text, "<h2>$1</h2>")
-	text = regexp.MustCompile(`(?m)^#\s+(.*?)$`).ReplaceAllString(text, "<h1>$1</h1>")
-
-	// Step 3: Convert horizontal rules
-	text = regexp.MustCompile(`(?m)^---+$`).ReplaceAllString(text, "<hr>")
-	text = regexp.MustCompile(`(?m)^\*\*\*+$`).ReplaceAllString(text, "<hr>")
-
-	// Step 4: Convert code blocks (must be before inline code)
-	text = regexp.MustCompile("```([\\s\\S]*?)```").ReplaceAllString(text, "<pre>$1</pre>")
-
-	// Step 5: Convert inline code
-	text = regexp.MustCompile("`([^`]+)`").ReplaceAllString(text, "<code>$1</code>")
-
-	// Step 6: Convert bold formatting (must be before single asterisk)
-	text = regexp.MustCompile(`\*\*([^\*]+)\*\*`).ReplaceAllString(text, "<strong>$1</strong>")
-	text = regexp.MustCompile(`\*([^\*\n]+)\*`).ReplaceAllString(text, "<strong>$1</strong>")
-
-	// Step 7: Convert italic formatting
-	text = regexp.MustCompile(`_([^_\n]+)_`).ReplaceAllString(text, "<em>$1</em>")
-
-	// Step 8: Convert strikethrough
-	text = regexp.MustCompile(`~~([^~]+)~~`).ReplaceAllString(text, "<s>$1</s>")
-
-	// Step 9: Convert links
-	text = regexp.MustCompile(`\[([^\]]+)\]\(([^\)]+)\)`).ReplaceAllString(text, `<a href="$2">$1</a>`)
-
-	// Wrap in <body> tag as required by Asana API
-	text = strings.TrimSpace(text)
-	if text != "" {
-		text = "<body>" + text + "</body>"
+	if n.Text != "" {
+		s += ` alt="` + escapeHTMLAttr(n.Text) + `"`
 	}
+	return s + ">"
+}
+
+func escapeHTMLText(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
 
-	return text
+func escapeHTMLAttr(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", `"`, "&quot;").Replace(s)
 }
@@ -0,0 +1,202 @@
+package utils
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+// testLinks resolves one user (jdoe / 111) and one task (ARD-7 / 222)
+var testLinks = &DescriptionLinks{
+	YouTrackLogin: func(asanaGID, name string) string {
+		if asanaGID == "111" {
+			return "jdoe"
+		}
+		return ""
+	},
+	YouTrackIssue: func(asanaGID string) string {
+		if asanaGID == "222" {
+			return "ARD-7"
+		}
+		return ""
+	},
+	AsanaUser: func(login string) string {
+		if login == "jdoe" {
+			return "111"
+		}
+		return ""
+	},
+	AsanaTask: func(issueID string) string {
+		if issueID == "ARD-7" {
+			return "222"
+		}
+		return ""
+	},
+}
+
+func TestConvertAsanaHTMLToYouTrackMarkdown(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{
+			name: "empty",
+			html: "",
+			want: "",
+		},
+		{
+			name: "inline formatting",
+			html: `<body><strong>bold</strong> and <em>it</em> <code>x</code></body>`,
+			want: "**bold** and _it_ `x`",
+		},
+		{
+			name: "nested bullet list",
+			html: `<body><ul><li>one<ul><li>nested</li></ul></li><li>two</li></ul></body>`,
+			want: "- one\n  - nested\n- two",
+		},
+		{
+			name: "nested ordered list",
+			html: `<body><ol><li>first<ol><li>inner</li></ol></li></ol></body>`,
+			want: "1. first\n   1. inner",
+		},
+		{
+			name: "multi-line pre keeps indentation",
+			html: "<body><pre>line 1\n  line 2\nline 3</pre></body>",
+			want: "```\nline 1\n  line 2\nline 3\n```",
+		},
+		{
+			name: "link with extra attributes",
+			html: `<body>See <a href="https://example.com/a?b=1&amp;c=2" target="_blank" rel="noopener">the docs</a>.</body>`,
+			want: "See [the docs](https://example.com/a?b=1&c=2).",
+		},
+		{
+			name: "table",
+			html: `<body><table><tr><td>a</td><td>b</td></tr><tr><td>1</td><td>2</td></tr></table></body>`,
+			want: "| a | b |\n| --- | --- |\n| 1 | 2 |",
+		},
+		{
+			name: "inline image keeps its attachment gid",
+			html: `<body>Before <img data-asana-gid="333" src="https://example.com/i.png" alt="shot"> after</body>`,
+			want: "Before ![shot](https://example.com/i.png \"asana:333\") after",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ConvertAsanaHTMLToYouTrackMarkdown(tt.html); got != tt.want {
+				t.Errorf("ConvertAsanaHTMLToYouTrackMarkdown(%q) = %q, want %q", tt.html, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertYouTrackMarkdownToAsanaHTML(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		want     string
+	}{
+		{
+			name:     "empty",
+			markdown: "",
+			want:     "",
+		},
+		{
+			name:     "nested bullet list",
+			markdown: "- one\n  - nested\n- two",
+			want:     `<body><ul><li>one<ul><li>nested</li></ul></li><li>two</li></ul></body>`,
+		},
+		{
+			name:     "fenced code block",
+			markdown: "```\nline 1\n  line 2\n```",
+			want:     "<body><pre>line 1\n  line 2</pre></body>",
+		},
+		{
+			name:     "link title is dropped",
+			markdown: `[docs](https://example.com "t")`,
+			want:     `<body><a href="https://example.com">docs</a></body>`,
+		},
+		{
+			name:     "table",
+			markdown: "| a | b |\n| --- | --- |\n| 1 | 2 |",
+			want:     `<body><table><tr><td>a</td><td>b</td></tr><tr><td>1</td><td>2</td></tr></table></body>`,
+		},
+		{
+			name:     "image without attachment becomes a link",
+			markdown: "![shot](https://example.com/i.png)",
+			want:     `<body><a href="https://example.com/i.png">shot</a></body>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ConvertYouTrackMarkdownToAsanaHTML(tt.markdown); got != tt.want {
+				t.Errorf("ConvertYouTrackMarkdownToAsanaHTML(%q) = %q, want %q", tt.markdown, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertMentionsAndTaskLinks(t *testing.T) {
+	html := `<body>Ping <a data-asana-gid="111" data-asana-type="user">@John Doe</a> about <a data-asana-gid="222" data-asana-type="task">Task</a></body>`
+	wantMarkdown := "Ping @jdoe about ARD-7"
+	wantHTML := `<body>Ping <a href="https://app.asana.com/0/profile/111" data-asana-gid="111" data-asana-type="user">@jdoe</a> about <a href="https://app.asana.com/0/0/222" data-asana-gid="222" data-asana-type="task">ARD-7</a></body>`
+
+	markdown := ConvertAsanaHTMLToYouTrackMarkdownWithLinks(html, testLinks)
+	if markdown != wantMarkdown {
+		t.Fatalf("markdown = %q, want %q", markdown, wantMarkdown)
+	}
+	if got := ConvertYouTrackMarkdownToAsanaHTMLWithLinks(markdown, testLinks); got != wantHTML {
+		t.Errorf("html = %q, want %q", got, wantHTML)
+	}
+
+	// Unresolved references are left alone
+	if got := ConvertYouTrackMarkdownToAsanaHTMLWithLinks("ask @nobody about XYZ-1", testLinks); got != "<body>ask @nobody about XYZ-1</body>" {
+		t.Errorf("unresolved references changed: %q", got)
+	}
+}
+
+// FuzzRoundTrip checks that descriptions stop changing after one round trip,
+// so syncing a description back and forth never makes it drift
+func FuzzRoundTrip(f *testing.F) {
+	seeds := []string{
+		"plain text",
+		"**bold** and _it_ `x`",
+		"- one\n  - nested\n- two",
+		"1. first\n   1. inner",
+		"```\nline 1\n  line 2\n```",
+		"See [the docs](https://example.com/a?b=1&c=2).",
+		"ping @jdoe about ARD-7",
+		"| a | b |\n| --- | --- |\n| 1 | 2 |",
+		"Before ![shot](https://example.com/i.png \"asana:333\") after",
+		"# Title\n\n> quoted\n\ntext",
+		`<body><ul><li>one<ul><li>nested</li></ul></li></ul></body>`,
+		"<body><pre>a\n  b</pre></body>",
+		`<body><a href="https://example.com" target="_blank">x</a></body>`,
+		`<body><a data-asana-gid="111" data-asana-type="user">@John</a></body>`,
+		`<body><img data-asana-gid="333" src="https://example.com/i.png" alt="shot"></body>`,
+	}
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		if !utf8.ValidString(input) {
+			t.Skip()
+		}
+
+		// Markdown side: md -> html -> md settles after one round
+		markdown := ConvertAsanaHTMLToYouTrackMarkdownWithLinks(ConvertYouTrackMarkdownToAsanaHTMLWithLinks(input, testLinks), testLinks)
+		again := ConvertAsanaHTMLToYouTrackMarkdownWithLinks(ConvertYouTrackMarkdownToAsanaHTMLWithLinks(markdown, testLinks), testLinks)
+		if again != markdown {
+			t.Errorf("markdown not stable for %q:\nfirst:  %q\nsecond: %q", input, markdown, again)
+		}
+
+		// HTML side: html -> md -> html settles after one round
+		html := ConvertYouTrackMarkdownToAsanaHTMLWithLinks(ConvertAsanaHTMLToYouTrackMarkdownWithLinks(input, testLinks), testLinks)
+		htmlAgain := ConvertYouTrackMarkdownToAsanaHTMLWithLinks(ConvertAsanaHTMLToYouTrackMarkdownWithLinks(html, testLinks), testLinks)
+		if htmlAgain != html {
+			t.Errorf("html not stable for %q:\nfirst:  %q\nsecond: %q", input, html, htmlAgain)
+		}
+	})
+}
@@ -0,0 +1,559 @@
+package utils
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ─── HTML Tokenizer ─────────────────────────────────────────────────────────
+
+type htmlTokenKind int
+
+const (
+	htmlTextToken htmlTokenKind = iota
+	htmlStartToken
+	htmlEndToken
+)
+
+type htmlToken struct {
+	Kind        htmlTokenKind
+	Data        string // tag name (lower case) or decoded text
+	Attrs       map[string]string
+	SelfClosing bool
+}
+
+// tokenizeHTML splits HTML into text, start tag and end tag tokens. Comments,
+// doctypes and the content of script and style elements are dropped.
+func tokenizeHTML(s string) []htmlToken {
+	var tokens []htmlToken
+	var text strings.Builder
+
+	flushText := func() {
+		if text.Len() > 0 {
+			tokens = append(tokens, htmlToken{Kind: htmlTextToken, Data: html.UnescapeString(text.String())})
+			text.Reset()
+		}
+	}
+
+	i := 0
+	for i < len(s) {
+		lt := strings.IndexByte(s[i:], '<')
+		if lt < 0 {
+			text.WriteString(s[i:])
+			break
+		}
+		text.WriteString(s[i : i+lt])
+		i += lt
+		rest := s[i:]
+
+		switch {
+		case strings.HasPrefix(rest, "<!--"):
+			end := strings.Index(rest[4:], "-->")
+			if end < 0 {
+				i = len(s)
+			} else {
+				i += 4 + end + 3
+			}
+			continue
+		case strings.HasPrefix(rest, "<!") || strings.HasPrefix(rest, "<?"):
+			end := strings.IndexByte(rest, '>')
+			if end < 0 {
+				i = len(s)
+			} else {
+				i += end + 1
+			}
+			continue
+		case strings.HasPrefix(rest, "</") && len(rest) > 2 && isASCIILetter(rest[2]):
+			end := strings.IndexByte(rest, '>')
+			if end < 0 {
+				text.WriteString(rest)
+				i = len(s)
+				continue
+			}
+			flushText()
+			name := strings.ToLower(strings.TrimSpace(strings.Fields(rest[2:end] + " ")[0]))
+			tokens = append(tokens, htmlToken{Kind: htmlEndToken, Data: name})
+			i += end + 1
+			continue
+		case len(rest) > 1 && isASCIILetter(rest[1]):
+			token, n, ok := parseStartTag(rest)
+			if !ok {
+				text.WriteString("<")
+				i++
+				continue
+			}
+			flushText()
+			tokens = append(tokens, token)
+			i += n
+			if token.Data == "script" || token.Data == "style" {
+				closing := strings.Index(strings.ToLower(s[i:]), "</"+token.Data)
+				if closing < 0 {
+					i = len(s)
+				} else {
+					i += closing
+				}
+			}
+			continue
+		}
+
+		text.WriteString("<")
+		i++
+	}
+	flushText()
+	return tokens
+}
+
+// parseStartTag parses a start tag at the beginning of s and returns the
+// token and the number of bytes it spans
+func parseStartTag(s string) (htmlToken, int, bool) {
+	i := 1
+	for i < len(s) && !isHTMLSpace(s[i]) && s[i] != '>' && s[i] != '/' {
+		i++
+	}
+	token := htmlToken{Kind: htmlStartToken, Data: strings.ToLower(s[1:i]), Attrs: map[string]string{}}
+
+	for i < len(s) {
+		for i < len(s) && isHTMLSpace(s[i]) {
+			i++
+		}
+		if i >= len(s) {
+			return token, 0, false
+		}
+		if s[i] == '>' {
+			return token, i + 1, true
+		}
+		if s[i] == '/' {
+			if i+1 < len(s) && s[i+1] == '>' {
+				token.SelfClosing = true
+				return token, i + 2, true
+			}
+			i++
+			continue
+		}
+
+		start := i
+		for i < len(s) && !isHTMLSpace(s[i]) && s[i] != '=' && s[i] != '>' && !(s[i] == '/' && i+1 < len(s) && s[i+1] == '>') {
+			i++
+		}
+		name := strings.ToLower(s[start:i])
+		for i < len(s) && isHTMLSpace(s[i]) {
+			i++
+		}
+		value := ""
+		if i < len(s) && s[i] == '=' {
+			i++
+			for i < len(s) && isHTMLSpace(s[i]) {
+				i++
+			}
+			if i < len(s) && (s[i] == '"' || s[i] == '\'') {
+				quote := s[i]
+				end := strings.IndexByte(s[i+1:], quote)
+				if end < 0 {
+					return token, 0, false
+				}
+				value = s[i+1 : i+1+end]
+				i += end + 2
+			} else {
+				start := i
+				for i < len(s) && !isHTMLSpace(s[i]) && s[i] != '>' {
+					i++
+				}
+				value = s[start:i]
+			}
+		}
+		if name != "" {
+			token.Attrs[name] = html.UnescapeString(value)
+		}
+	}
+	return token, 0, false
+}
+
+func isASCIILetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isHTMLSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+// ─── HTML Tree ──────────────────────────────────────────────────────────────
+
+type htmlNode struct {
+	Tag      string // empty for text nodes
+	Attrs    map[string]string
+	Text     string
+	Children []*htmlNode
+}
+
+var htmlVoidElements = map[string]bool{
+	"br": true, "hr": true, "img": true, "input": true, "meta": true,
+	"link": true, "wbr": true, "col": true, "area": true, "source": true,
+}
+
+var htmlBlockElements = map[string]bool{
+	"body": true, "div": true, "p": true, "section": true, "article": true,
+	"header": true, "footer": true, "main": true, "aside": true, "nav": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"ul": true, "ol": true, "li": true, "pre": true, "blockquote": true,
+	"hr": true, "table": true, "thead": true, "tbody": true, "tfoot": true,
+	"tr": true, "td": true, "th": true, "dl": true, "dt": true, "dd": true,
+	"figure": true, "figcaption": true, "details": true, "summary": true,
+}
+
+// parseHTMLTree builds an element tree from HTML, closing unclosed list
+// items, paragraphs, table rows and cells the way browsers do
+func parseHTMLTree(s string) *htmlNode {
+	root := &htmlNode{Tag: "#root"}
+	stack := []*htmlNode{root}
+
+	current := func() *htmlNode { return stack[len(stack)-1] }
+	popTo := func(i int) { stack = stack[:i] }
+	// openIndex finds an open element, not looking past scope boundaries
+	openIndex := func(tag string, boundaries ...string) int {
+		for i := len(stack) - 1; i > 0; i-- {
+			if stack[i].Tag == tag {
+				return i
+			}
+			for _, b := range boundaries {
+				if stack[i].Tag == b {
+					return -1
+				}
+			}
+		}
+		return -1
+	}
+
+	for _, token := range tokenizeHTML(s) {
+		switch token.Kind {
+		case htmlTextToken:
+			parent := current()
+			if n := len(parent.Children); n > 0 && parent.Children[n-1].Tag == "" {
+				parent.Children[n-1].Text += token.Data
+			} else {
+				parent.Children = append(parent.Children, &htmlNode{Text: token.Data})
+			}
+
+		case htmlStartToken:
+			switch token.Data {
+			case "li":
+				if i := openIndex("li", "ul", "ol"); i > 0 {
+					popTo(i)
+				}
+			case "tr":
+				if i := openIndex("tr", "table"); i > 0 {
+					popTo(i)
+				}
+			case "td", "th":
+				if i := openIndex("td", "tr", "table"); i > 0 {
+					popTo(i)
+				}
+				if i := openIndex("th", "tr", "table"); i > 0 {
+					popTo(i)
+				}
+			}
+			if htmlBlockElements[token.Data] {
+				if i := openIndex("p", "li", "td", "th", "blockquote", "div"); i > 0 {
+					popTo(i)
+				}
+			}
+
+			node := &htmlNode{Tag: token.Data, Attrs: token.Attrs}
+			current().Children = append(current().Children, node)
+			if !token.SelfClosing && !htmlVoidElements[token.Data] {
+				stack = append(stack, node)
+			}
+
+		case htmlEndToken:
+			if i := openIndex(token.Data); i > 0 {
+				popTo(i)
+			}
+		}
+	}
+	return root
+}
+
+// textContent returns the text of a node and its descendants, with <br> as
+// a newline
+func (n *htmlNode) textContent() string {
+	if n.Tag == "" {
+		return n.Text
+	}
+	if n.Tag == "br" {
+		return "\n"
+	}
+	var b strings.Builder
+	for _, c := range n.Children {
+		b.WriteString(c.textContent())
+	}
+	return b.String()
+}
+
+// ─── HTML To Document ───────────────────────────────────────────────────────
+
+var (
+	htmlWhitespacePattern = regexp.MustCompile(`[ \t\r\n\f]+`)
+	codeLanguagePattern   = regexp.MustCompile(`(?:^|\s)(?:language|lang)-([\w+#.-]+)`)
+)
+
+// htmlReader converts an HTML tree into the document model. Asana rich text
+// uses newlines in text as line breaks, while regular HTML (e.g. YouTrack's
+// wikified descriptions) collapses whitespace and uses <p> and <br>.
+type htmlReader struct {
+	preserveNewlines bool
+}
+
+func parseAsanaHTML(s string) []*richBlock {
+	r := &htmlReader{preserveNewlines: true}
+	return normalizeBlocks(r.blocks(parseHTMLTree(s).Children))
+}
+
+func parseWikifiedHTML(s string) []*richBlock {
+	r := &htmlReader{}
+	return normalizeBlocks(r.blocks(parseHTMLTree(s).Children))
+}
+
+func (r *htmlReader) blocks(nodes []*htmlNode) []*richBlock {
+	var blocks []*richBlock
+	var inlines []*richInline
+
+	flush := func() {
+		if r.preserveNewlines {
+			blocks = append(blocks, splitParagraphs(inlines)...)
+		} else if len(inlines) > 0 {
+			blocks = append(blocks, &richBlock{Kind: blockParagraph, Inlines: inlines})
+		}
+		inlines = nil
+	}
+
+	for _, n := range nodes {
+		if n.Tag != "" && htmlBlockElements[n.Tag] {
+			flush()
+			blocks = append(blocks, r.block(n)...)
+			continue
+		}
+		inlines = append(inlines, r.inline(n)...)
+	}
+	flush()
+	return blocks
+}
+
+func (r *htmlReader) block(n *htmlNode) []*richBlock {
+	switch n.Tag {
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		level, _ := strconv.Atoi(n.Tag[1:])
+		return []*richBlock{{Kind: blockHeading, Level: level, Inlines: r.flatInlines(n.Children)}}
+
+	case "ul", "ol":
+		list := &richBlock{Kind: blockList, Ordered: n.Tag == "ol"}
+		var loose []*htmlNode
+		for _, c := range n.Children {
+			if c.Tag == "li" {
+				if len(loose) > 0 {
+					list.Items = append(list.Items, r.blocks(loose))
+					loose = nil
+				}
+				list.Items = append(list.Items, r.blocks(c.Children))
+				continue
+			}
+			if c.Tag == "" && strings.TrimSpace(c.Text) == "" {
+				continue
+			}
+			loose = append(loose, c)
+		}
+		if len(loose) > 0 {
+			list.Items = append(list.Items, r.blocks(loose))
+		}
+		return []*richBlock{list}
+
+	case "pre":
+		code := &richBlock{Kind: blockCode, Text: strings.TrimPrefix(n.textContent(), "\n")}
+		for _, c := range n.Children {
+			if c.Tag == "code" {
+				if m := codeLanguagePattern.FindStringSubmatch(c.Attrs["class"]); m != nil {
+					code.Lang = m[1]
+				}
+			}
+		}
+		if m := codeLanguagePattern.FindStringSubmatch(n.Attrs["class"]); m != nil && code.Lang == "" {
+			code.Lang = m[1]
+		}
+		return []*richBlock{code}
+
+	case "blockquote":
+		return []*richBlock{{Kind: blockQuote, Children: r.blocks(n.Children)}}
+
+	case "hr":
+		return []*richBlock{{Kind: blockRule}}
+
+	case "table":
+		table := &richBlock{Kind: blockTable}
+		r.tableRows(n, table)
+		return []*richBlock{table}
+
+	case "li":
+		// A list item outside of a list
+		return []*richBlock{{Kind: blockList, Items: [][]*richBlock{r.blocks(n.Children)}}}
+	}
+	return r.blocks(n.Children)
+}
+
+func (r *htmlReader) tableRows(n *htmlNode, table *richBlock) {
+	for _, c := range n.Children {
+		switch c.Tag {
+		case "thead", "tbody", "tfoot":
+			r.tableRows(c, table)
+		case "tr":
+			var row [][]*richInline
+			for _, cell := range c.Children {
+				if cell.Tag == "td" || cell.Tag == "th" {
+					row = append(row, r.flatInlines(cell.Children))
+				}
+			}
+			table.Rows = append(table.Rows, row)
+		}
+	}
+}
+
+// flatInlines converts content that can only hold inline elements, such as
+// headings and table cells; nested blocks become separate lines
+func (r *htmlReader) flatInlines(nodes []*htmlNode) []*richInline {
+	var out []*richInline
+	for _, b := range normalizeBlocks(r.blocks(nodes)) {
+		if len(out) > 0 {
+			out = append(out, &richInline{Kind: inlineBreak})
+		}
+		out = append(out, blockInlines(b)...)
+	}
+	return out
+}
+
+// blockInlines flattens a block into inline content
+func blockInlines(b *richBlock) []*richInline {
+	switch b.Kind {
+	case blockParagraph, blockHeading:
+		return b.Inlines
+	case blockCode:
+		return []*richInline{{Kind: inlineCode, Text: b.Text}}
+	case blockQuote:
+		var out []*richInline
+		for _, c := range b.Children {
+			if len(out) > 0 {
+				out = append(out, &richInline{Kind: inlineBreak})
+			}
+			out = append(out, blockInlines(c)...)
+		}
+		return out
+	case blockList:
+		var out []*richInline
+		for _, item := range b.Items {
+			for _, c := range item {
+				if len(out) > 0 {
+					out = append(out, &richInline{Kind: inlineBreak})
+				}
+				out = append(out, blockInlines(c)...)
+			}
+		}
+		return out
+	case blockTable:
+		var out []*richInline
+		for _, row := range b.Rows {
+			for i, cell := range row {
+				if i > 0 {
+					out = append(out, &richInline{Kind: inlineText, Text: " | "})
+				} else if len(out) > 0 {
+					out = append(out, &richInline{Kind: inlineBreak})
+				}
+				out = append(out, cell...)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+func (r *htmlReader) inlines(nodes []*htmlNode) []*richInline {
+	var out []*richInline
+	for _, n := range nodes {
+		out = append(out, r.inline(n)...)
+	}
+	return out
+}
+
+func (r *htmlReader) inline(n *htmlNode) []*richInline {
+	if n.Tag == "" {
+		return r.text(n.Text)
+	}
+	if htmlBlockElements[n.Tag] {
+		return r.flatInlines([]*htmlNode{n})
+	}
+
+	switch n.Tag {
+	case "br":
+		return []*richInline{{Kind: inlineBreak}}
+	case "strong", "b":
+		return []*richInline{{Kind: inlineBold, Children: r.inlines(n.Children)}}
+	case "em", "i":
+		return []*richInline{{Kind: inlineItalic, Children: r.inlines(n.Children)}}
+	case "u", "ins":
+		return []*richInline{{Kind: inlineUnderline, Children: r.inlines(n.Children)}}
+	case "s", "strike", "del":
+		return []*richInline{{Kind: inlineStrike, Children: r.inlines(n.Children)}}
+	case "code", "tt", "kbd", "samp":
+		return []*richInline{{Kind: inlineCode, Text: n.textContent()}}
+	case "a":
+		return r.link(n)
+	case "img":
+		return []*richInline{{
+			Kind: inlineImage,
+			Href: n.Attrs["src"],
+			Text: n.Attrs["alt"],
+			GID:  n.Attrs["data-asana-gid"],
+		}}
+	}
+	return r.inlines(n.Children)
+}
+
+func (r *htmlReader) link(n *htmlNode) []*richInline {
+	link := &richInline{
+		Kind:     inlineLink,
+		Href:     strings.TrimSpace(n.Attrs["href"]),
+		GID:      n.Attrs["data-asana-gid"],
+		GIDType:  n.Attrs["data-asana-type"],
+		Children: r.inlines(n.Children),
+	}
+
+	// Mentions get their canonical URL so the markdown side can recognize them
+	switch {
+	case link.GID != "" && (link.GIDType == asanaGIDTypeUser || link.GIDType == asanaGIDTypeTask):
+	case link.GID != "" && link.Href == "":
+		link.GIDType = asanaGIDTypeTask
+	default:
+		link.GID, link.GIDType = asanaLinkGID(link.Href)
+	}
+	canonicalAsanaLink(link)
+
+	if link.Href == "" {
+		return link.Children
+	}
+	return []*richInline{link}
+}
+
+func (r *htmlReader) text(s string) []*richInline {
+	if !r.preserveNewlines {
+		return []*richInline{{Kind: inlineText, Text: htmlWhitespacePattern.ReplaceAllString(s, " ")}}
+	}
+
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	var out []*richInline
+	for i, line := range strings.Split(s, "\n") {
+		if i > 0 {
+			out = append(out, &richInline{Kind: inlineBreak})
+		}
+		if line != "" {
+			out = append(out, &richInline{Kind: inlineText, Text: line})
+		}
+	}
+	return out
+}
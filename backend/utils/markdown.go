@@ -0,0 +1,973 @@
+package utils
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ─── Markdown Block Parsing ─────────────────────────────────────────────────
+//
+// The parser covers the markdown YouTrack writes and the renderer below
+// produces: ATX and setext headings, fenced code, block quotes, nested lists,
+// pipe tables, thematic breaks and paragraphs.
+
+var (
+	mdFencePattern      = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})\\s*([^`]*)$")
+	mdHeadingPattern    = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	mdRulePattern       = regexp.MustCompile(`^ {0,3}(?:(?:-[ \t]*){3,}|(?:\*[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	mdSetextPattern     = regexp.MustCompile(`^ {0,3}(=+|-+)[ \t]*$`)
+	mdQuotePattern      = regexp.MustCompile(`^ {0,3}>`)
+	mdListItemPattern   = regexp.MustCompile(`^( {0,3})([-+*]|\d{1,9}[.)])([ \t]+|$)`)
+	mdTableDelimPattern = regexp.MustCompile(`^[ \t]*\|?[ \t]*:?-+:?[ \t]*(\|[ \t]*:?-+:?[ \t]*)*\|?[ \t]*$`)
+	mdEntityPattern     = regexp.MustCompile(`^&(?:[a-zA-Z][a-zA-Z0-9]{1,31}|#[0-9]{1,7}|#[xX][0-9a-fA-F]{1,6});`)
+	mdAutolinkPattern   = regexp.MustCompile(`^<((?:https?|mailto|ftp):[^\s<>]*)>`)
+	mdInlineTagPattern  = regexp.MustCompile(`(?i)^<(/?)(u|br)\s*/?>`)
+	mdImageAttrsPattern = regexp.MustCompile(`^\{[^}\n]*\}`)
+	mdImageTitlePattern = regexp.MustCompile(`^asana:(\d+)$`)
+)
+
+func parseMarkdown(s string) []*richBlock {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.ReplaceAll(s, "\t", "    ")
+	return normalizeBlocks(parseMarkdownBlocks(strings.Split(s, "\n")))
+}
+
+func parseMarkdownBlocks(lines []string) []*richBlock {
+	var blocks []*richBlock
+	i := 0
+	for i < len(lines) {
+		line := lines[i]
+		if strings.TrimSpace(line) == "" {
+			i++
+			continue
+		}
+
+		if m := mdFencePattern.FindStringSubmatch(line); m != nil {
+			block, next := parseMarkdownFence(lines, i, m)
+			blocks = append(blocks, block)
+			i = next
+			continue
+		}
+		if m := mdHeadingPattern.FindStringSubmatch(line); m != nil {
+			blocks = append(blocks, &richBlock{Kind: blockHeading, Level: len(m[1]), Inlines: parseMarkdownInlines(m[2])})
+			i++
+			continue
+		}
+		if mdRulePattern.MatchString(line) {
+			blocks = append(blocks, &richBlock{Kind: blockRule})
+			i++
+			continue
+		}
+		if mdQuotePattern.MatchString(line) {
+			var quoted []string
+			for i < len(lines) && mdQuotePattern.MatchString(lines[i]) {
+				content := strings.TrimLeft(lines[i], " ")[1:]
+				quoted = append(quoted, strings.TrimPrefix(content, " "))
+				i++
+			}
+			blocks = append(blocks, &richBlock{Kind: blockQuote, Children: parseMarkdownBlocks(quoted)})
+			continue
+		}
+		if mdListItemPattern.MatchString(line) {
+			block, next := parseMarkdownList(lines, i)
+			blocks = append(blocks, block)
+			i = next
+			continue
+		}
+		if isMarkdownTableStart(lines, i) {
+			block, next := parseMarkdownTable(lines, i)
+			blocks = append(blocks, block)
+			i = next
+			continue
+		}
+
+		// Paragraph, possibly turned into a setext heading by its underline
+		var para []string
+		for i < len(lines) && strings.TrimSpace(lines[i]) != "" {
+			if len(para) > 0 {
+				if m := mdSetextPattern.FindStringSubmatch(lines[i]); m != nil {
+					level := 1
+					if m[1][0] == '-' {
+						level = 2
+					}
+					blocks = append(blocks, &richBlock{Kind: blockHeading, Level: level, Inlines: parseMarkdownInlines(strings.Join(para, " "))})
+					para = nil
+					i++
+					break
+				}
+				if startsMarkdownBlock(lines, i) {
+					break
+				}
+			}
+			para = append(para, strings.TrimSpace(lines[i]))
+			i++
+		}
+		if len(para) > 0 {
+			blocks = append(blocks, &richBlock{Kind: blockParagraph, Inlines: parseMarkdownInlines(strings.Join(para, "\n"))})
+		}
+	}
+	return blocks
+}
+
+// startsMarkdownBlock reports whether a line interrupts a paragraph
+func startsMarkdownBlock(lines []string, i int) bool {
+	line := lines[i]
+	return mdFencePattern.MatchString(line) ||
+		mdHeadingPattern.MatchString(line) ||
+		mdRulePattern.MatchString(line) ||
+		mdQuotePattern.MatchString(line) ||
+		mdListItemPattern.MatchString(line) ||
+		isMarkdownTableStart(lines, i)
+}
+
+func parseMarkdownFence(lines []string, start int, m []string) (*richBlock, int) {
+	fence := m[1]
+	indent := len(lines[start]) - len(strings.TrimLeft(lines[start], " "))
+	block := &richBlock{Kind: blockCode}
+	if fields := strings.Fields(m[2]); len(fields) > 0 {
+		block.Lang = fields[0]
+	}
+
+	var content []string
+	i := start + 1
+	for ; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if strings.HasPrefix(trimmed, fence[:3]) && strings.Trim(trimmed, fence[:1]) == "" && len(trimmed) >= len(fence) {
+			i++
+			break
+		}
+		line := lines[i]
+		for j := 0; j < indent && strings.HasPrefix(line, " "); j++ {
+			line = line[1:]
+		}
+		content = append(content, line)
+	}
+	block.Text = strings.Join(content, "\n")
+	return block, i
+}
+
+func parseMarkdownList(lines []string, start int) (*richBlock, int) {
+	first := mdListItemPattern.FindStringSubmatch(lines[start])
+	ordered := !strings.ContainsAny(first[2], "-+*")
+	list := &richBlock{Kind: blockList, Ordered: ordered}
+
+	i := start
+	for i < len(lines) {
+		m := mdListItemPattern.FindStringSubmatch(lines[i])
+		if m == nil || strings.ContainsAny(m[2], "-+*") == ordered {
+			break
+		}
+
+		// Content starts after the marker and one to four spaces
+		spaces := len(m[3])
+		if spaces > 4 || spaces == 0 {
+			spaces = 1
+		}
+		contentCol := len(m[1]) + len(m[2]) + spaces
+		firstLine := ""
+		if contentCol < len(lines[i]) {
+			firstLine = lines[i][contentCol:]
+		}
+
+		item := []string{firstLine}
+		i++
+		for i < len(lines) {
+			line := lines[i]
+			if strings.TrimSpace(line) == "" {
+				// A blank line continues the item only if indented content follows
+				j := i
+				for j < len(lines) && strings.TrimSpace(lines[j]) == "" {
+					j++
+				}
+				if j < len(lines) && leadingSpaces(lines[j]) >= contentCol {
+					for ; i < j; i++ {
+						item = append(item, "")
+					}
+					continue
+				}
+				break
+			}
+			if leadingSpaces(line) >= contentCol {
+				item = append(item, line[contentCol:])
+				i++
+				continue
+			}
+			// Lazy continuation of a paragraph
+			last := item[len(item)-1]
+			if strings.TrimSpace(last) != "" && !startsMarkdownBlock(lines, i) && !mdFencePattern.MatchString(item[0]) {
+				item = append(item, strings.TrimSpace(line))
+				i++
+				continue
+			}
+			break
+		}
+		list.Items = append(list.Items, parseMarkdownBlocks(item))
+
+		// Blank lines between items of the same list
+		j := i
+		for j < len(lines) && strings.TrimSpace(lines[j]) == "" {
+			j++
+		}
+		if j < len(lines) {
+			if next := mdListItemPattern.FindStringSubmatch(lines[j]); next != nil && strings.ContainsAny(next[2], "-+*") != ordered {
+				i = j
+				continue
+			}
+		}
+		break
+	}
+	return list, i
+}
+
+func leadingSpaces(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+func isMarkdownTableStart(lines []string, i int) bool {
+	return i+1 < len(lines) &&
+		strings.Contains(lines[i], "|") &&
+		strings.Contains(lines[i+1], "-") &&
+		mdTableDelimPattern.MatchString(lines[i+1])
+}
+
+func parseMarkdownTable(lines []string, start int) (*richBlock, int) {
+	table := &richBlock{Kind: blockTable}
+	table.Rows = append(table.Rows, parseMarkdownTableRow(lines[start]))
+	i := start + 2
+	for i < len(lines) && strings.TrimSpace(lines[i]) != "" && strings.Contains(lines[i], "|") {
+		table.Rows = append(table.Rows, parseMarkdownTableRow(lines[i]))
+		i++
+	}
+	return table, i
+}
+
+func parseMarkdownTableRow(line string) [][]*richInline {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, "\\|") {
+		line = line[:len(line)-1]
+	}
+
+	var cells [][]*richInline
+	var cell strings.Builder
+	for i := 0; i < len(line); i++ {
+		if line[i] == '\\' && i+1 < len(line) && line[i+1] == '|' {
+			cell.WriteByte('|')
+			i++
+			continue
+		}
+		if line[i] == '|' {
+			cells = append(cells, parseMarkdownInlines(strings.TrimSpace(cell.String())))
+			cell.Reset()
+			continue
+		}
+		cell.WriteByte(line[i])
+	}
+	cells = append(cells, parseMarkdownInlines(strings.TrimSpace(cell.String())))
+	return cells
+}
+
+// ─── Markdown Inline Parsing ────────────────────────────────────────────────
+
+type mdInlineParser struct {
+	s   string
+	pos int
+	// failed records, per closing delimiter, the earliest position from which
+	// it was searched for and not found, so unmatched delimiters stay linear
+	failed map[string]int
+}
+
+func parseMarkdownInlines(s string) []*richInline {
+	p := &mdInlineParser{s: s, failed: map[string]int{}}
+	nodes, _ := p.parse("")
+	return nodes
+}
+
+func (p *mdInlineParser) parse(closer string) ([]*richInline, bool) {
+	var nodes []*richInline
+	var text strings.Builder
+
+	flush := func() {
+		if text.Len() > 0 {
+			nodes = append(nodes, &richInline{Kind: inlineText, Text: text.String()})
+			text.Reset()
+		}
+	}
+	emit := func(n ...*richInline) {
+		flush()
+		nodes = append(nodes, n...)
+	}
+
+	for p.pos < len(p.s) {
+		if closer != "" && p.atCloser(closer) {
+			flush()
+			p.pos += len(closer)
+			return nodes, true
+		}
+		if failedAt, ok := p.failed[closer]; ok && closer != "" && p.pos >= failedAt {
+			// Already known not to close from here
+			return nodes, false
+		}
+
+		c := p.s[p.pos]
+		switch c {
+		case '\\':
+			if p.pos+1 < len(p.s) && isASCIIPunct(p.s[p.pos+1]) {
+				text.WriteByte(p.s[p.pos+1])
+				p.pos += 2
+				continue
+			}
+			if p.pos+1 < len(p.s) && p.s[p.pos+1] == '\n' {
+				emit(&richInline{Kind: inlineBreak})
+				p.pos += 2
+				continue
+			}
+
+		case '\n':
+			emit(&richInline{Kind: inlineBreak})
+			p.pos++
+			continue
+
+		case '`':
+			if n, ok := p.codeSpan(); ok {
+				emit(n)
+				continue
+			}
+			run := p.run('`')
+			text.WriteString(p.s[p.pos : p.pos+run])
+			p.pos += run
+			continue
+
+		case '*':
+			// "***" usually opens italic text that starts with bold
+			if strings.HasPrefix(p.s[p.pos:], "***") {
+				failed := make(map[string]int, len(p.failed))
+				for k, v := range p.failed {
+					failed[k] = v
+				}
+				if n, ok := p.delimited("*", inlineItalic); ok {
+					emit(n)
+					continue
+				}
+				// Failures seen inside the italic attempt don't hold for bold
+				p.failed = failed
+			}
+			if strings.HasPrefix(p.s[p.pos:], "**") {
+				if n, ok := p.delimited("**", inlineBold); ok {
+					emit(n)
+					continue
+				}
+				text.WriteString("**")
+				p.pos += 2
+				continue
+			}
+			if n, ok := p.delimited("*", inlineItalic); ok {
+				emit(n)
+				continue
+			}
+
+		case '_':
+			if p.canOpenUnderscore() {
+				if n, ok := p.delimited("_", inlineItalic); ok {
+					emit(n)
+					continue
+				}
+			}
+
+		case '~':
+			if strings.HasPrefix(p.s[p.pos:], "~~") {
+				if n, ok := p.delimited("~~", inlineStrike); ok {
+					emit(n)
+					continue
+				}
+				text.WriteString("~~")
+				p.pos += 2
+				continue
+			}
+
+		case '[':
+			if n, ok := p.link(); ok {
+				emit(n)
+				continue
+			}
+
+		case '!':
+			if strings.HasPrefix(p.s[p.pos:], "![") {
+				if n, ok := p.image(); ok {
+					emit(n)
+					continue
+				}
+			}
+
+		case '<':
+			if m := mdAutolinkPattern.FindStringSubmatch(p.s[p.pos:]); m != nil {
+				link := &richInline{Kind: inlineLink, Href: m[1], Children: []*richInline{{Kind: inlineText, Text: m[1]}}}
+				link.GID, link.GIDType = asanaLinkGID(m[1])
+				canonicalAsanaLink(link)
+				emit(link)
+				p.pos += len(m[0])
+				continue
+			}
+			if m := mdInlineTagPattern.FindStringSubmatch(p.s[p.pos:]); m != nil {
+				tag := strings.ToLower(m[2])
+				if tag == "br" {
+					emit(&richInline{Kind: inlineBreak})
+					p.pos += len(m[0])
+					continue
+				}
+				if m[1] == "" {
+					start := p.pos
+					p.pos += len(m[0])
+					if n, ok := p.delimitedFrom("</u>", inlineUnderline, start); ok {
+						emit(n)
+						continue
+					}
+					p.pos = start
+				}
+			}
+
+		case '&':
+			if m := mdEntityPattern.FindString(p.s[p.pos:]); m != "" {
+				text.WriteString(html.UnescapeString(m))
+				p.pos += len(m)
+				continue
+			}
+		}
+
+		_, size := utf8.DecodeRuneInString(p.s[p.pos:])
+		text.WriteString(p.s[p.pos : p.pos+size])
+		p.pos += size
+	}
+	flush()
+	return nodes, closer == ""
+}
+
+// atCloser reports whether the closing delimiter is at the current position
+func (p *mdInlineParser) atCloser(closer string) bool {
+	rest := p.s[p.pos:]
+	switch closer {
+	case "]":
+		return strings.HasPrefix(rest, "]")
+	case "</u>":
+		return len(rest) >= 4 && strings.EqualFold(rest[:4], "</u>")
+	case "*":
+		// In "***" the single asterisk closes first, e.g. **bold *italic***
+		return strings.HasPrefix(rest, "*") && (!strings.HasPrefix(rest, "**") || strings.HasPrefix(rest, "***")) && !p.prevIsSpace()
+	case "_":
+		return strings.HasPrefix(rest, "_") && !p.prevIsSpace() && !isAlnumAt(p.s, p.pos+1)
+	default:
+		return strings.HasPrefix(rest, closer) && !p.prevIsSpace()
+	}
+}
+
+// delimited parses emphasis-like content opened at the current position
+func (p *mdInlineParser) delimited(delim string, kind richInlineKind) (*richInline, bool) {
+	start := p.pos
+	// An opener must be followed by non-whitespace
+	after := start + len(delim)
+	if after >= len(p.s) || isSpaceAt(p.s, after) {
+		return nil, false
+	}
+	p.pos = after
+	n, ok := p.delimitedFrom(delim, kind, start)
+	if !ok {
+		p.pos = start
+	}
+	return n, ok
+}
+
+// delimitedFrom parses content up to the closer; start is where the opener began
+func (p *mdInlineParser) delimitedFrom(closer string, kind richInlineKind, start int) (*richInline, bool) {
+	if failedAt, ok := p.failed[closer]; ok && p.pos >= failedAt {
+		return nil, false
+	}
+	from := p.pos
+	children, ok := p.parse(closer)
+	if !ok || len(children) == 0 {
+		if !ok {
+			if failedAt, seen := p.failed[closer]; !seen || from < failedAt {
+				p.failed[closer] = from
+			}
+		}
+		p.pos = start
+		return nil, false
+	}
+	return &richInline{Kind: kind, Children: children}, true
+}
+
+func (p *mdInlineParser) codeSpan() (*richInline, bool) {
+	run := p.run('`')
+	rest := p.s[p.pos+run:]
+	for i := 0; i < len(rest); {
+		j := strings.IndexByte(rest[i:], '`')
+		if j < 0 {
+			return nil, false
+		}
+		i += j
+		closing := 0
+		for i+closing < len(rest) && rest[i+closing] == '`' {
+			closing++
+		}
+		if closing == run {
+			content := strings.ReplaceAll(rest[:i], "\n", " ")
+			if len(content) >= 2 && content[0] == ' ' && content[len(content)-1] == ' ' && strings.TrimSpace(content) != "" {
+				content = content[1 : len(content)-1]
+			}
+			p.pos += run + i + closing
+			return &richInline{Kind: inlineCode, Text: content}, true
+		}
+		i += closing
+	}
+	return nil, false
+}
+
+func (p *mdInlineParser) run(c byte) int {
+	n := 0
+	for p.pos+n < len(p.s) && p.s[p.pos+n] == c {
+		n++
+	}
+	return n
+}
+
+// bracketed parses link text or image alt text up to the closing bracket
+func (p *mdInlineParser) bracketed() ([]*richInline, bool) {
+	if failedAt, ok := p.failed["]"]; ok && p.pos >= failedAt {
+		return nil, false
+	}
+	from := p.pos
+	children, ok := p.parse("]")
+	if !ok {
+		if failedAt, seen := p.failed["]"]; !seen || from < failedAt {
+			p.failed["]"] = from
+		}
+	}
+	return children, ok
+}
+
+func (p *mdInlineParser) link() (*richInline, bool) {
+	start := p.pos
+	p.pos++
+	children, ok := p.bracketed()
+	if !ok {
+		p.pos = start
+		return nil, false
+	}
+	href, _, ok := p.destination()
+	if !ok {
+		p.pos = start
+		return nil, false
+	}
+	link := &richInline{Kind: inlineLink, Href: href, Children: children}
+	link.GID, link.GIDType = asanaLinkGID(href)
+	canonicalAsanaLink(link)
+	return link, true
+}
+
+func (p *mdInlineParser) image() (*richInline, bool) {
+	start := p.pos
+	p.pos += 2
+	alt, ok := p.bracketed()
+	if !ok {
+		p.pos = start
+		return nil, false
+	}
+	src, title, ok := p.destination()
+	if !ok {
+		p.pos = start
+		return nil, false
+	}
+	// YouTrack attribute blocks such as {width=70%} have no Asana equivalent
+	if m := mdImageAttrsPattern.FindString(p.s[p.pos:]); m != "" {
+		p.pos += len(m)
+	}
+	image := &richInline{Kind: inlineImage, Href: src, Text: plainInlines(alt)}
+	if m := mdImageTitlePattern.FindStringSubmatch(title); m != nil {
+		image.GID = m[1]
+	}
+	return image, true
+}
+
+// destination parses "(url "title")" after the closing bracket of a link
+func (p *mdInlineParser) destination() (string, string, bool) {
+	s := p.s
+	i := p.pos
+	if i >= len(s) || s[i] != '(' {
+		return "", "", false
+	}
+	i++
+	for i < len(s) && s[i] == ' ' {
+		i++
+	}
+
+	var dest strings.Builder
+	if i < len(s) && s[i] == '<' {
+		end := strings.IndexAny(s[i+1:], ">\n")
+		if end < 0 || s[i+1+end] != '>' {
+			return "", "", false
+		}
+		dest.WriteString(s[i+1 : i+1+end])
+		i += end + 2
+	} else {
+		depth := 0
+		for i < len(s) {
+			c := s[i]
+			if c == '\\' && i+1 < len(s) && isASCIIPunct(s[i+1]) {
+				dest.WriteByte(s[i+1])
+				i += 2
+				continue
+			}
+			if c == ' ' || c == '\n' || c < 0x20 {
+				break
+			}
+			if c == '(' {
+				depth++
+			}
+			if c == ')' {
+				if depth == 0 {
+					break
+				}
+				depth--
+			}
+			dest.WriteByte(c)
+			i++
+		}
+	}
+
+	for i < len(s) && (s[i] == ' ' || s[i] == '\n') {
+		i++
+	}
+	title := ""
+	if i < len(s) && (s[i] == '"' || s[i] == '\'') {
+		quote := s[i]
+		end := strings.IndexByte(s[i+1:], quote)
+		if end < 0 {
+			return "", "", false
+		}
+		title = s[i+1 : i+1+end]
+		i += end + 2
+		for i < len(s) && s[i] == ' ' {
+			i++
+		}
+	}
+	if i >= len(s) || s[i] != ')' {
+		return "", "", false
+	}
+	p.pos = i + 1
+	return html.UnescapeString(dest.String()), title, true
+}
+
+func (p *mdInlineParser) canOpenUnderscore() bool {
+	if p.pos > 0 {
+		prev, _ := utf8.DecodeLastRuneInString(p.s[:p.pos])
+		if unicode.IsLetter(prev) || unicode.IsDigit(prev) {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *mdInlineParser) prevIsSpace() bool {
+	if p.pos == 0 {
+		return true
+	}
+	prev, _ := utf8.DecodeLastRuneInString(p.s[:p.pos])
+	return unicode.IsSpace(prev)
+}
+
+func isSpaceAt(s string, i int) bool {
+	r, _ := utf8.DecodeRuneInString(s[i:])
+	return unicode.IsSpace(r)
+}
+
+func isAlnumAt(s string, i int) bool {
+	if i >= len(s) {
+		return false
+	}
+	r, _ := utf8.DecodeRuneInString(s[i:])
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func isASCIIPunct(c byte) bool {
+	return strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", c) >= 0
+}
+
+// ─── Markdown Rendering ─────────────────────────────────────────────────────
+
+func renderMarkdown(blocks []*richBlock) string {
+	return renderMarkdownBlocks(blocks, false)
+}
+
+func renderMarkdownBlocks(blocks []*richBlock, inListItem bool) string {
+	var b strings.Builder
+	for i, block := range blocks {
+		if i > 0 {
+			// A nested list follows its item's text directly
+			if inListItem && block.Kind == blockList && blocks[i-1].Kind == blockParagraph {
+				b.WriteString("\n")
+			} else {
+				b.WriteString("\n\n")
+			}
+		}
+		b.WriteString(renderMarkdownBlock(block))
+	}
+	return b.String()
+}
+
+func renderMarkdownBlock(block *richBlock) string {
+	switch block.Kind {
+	case blockParagraph:
+		return renderMarkdownInlines(block.Inlines, false)
+
+	case blockHeading:
+		text := renderMarkdownInlines(block.Inlines, true)
+		if strings.HasSuffix(text, "#") {
+			text = text[:len(text)-1] + "\\#"
+		}
+		return strings.Repeat("#", block.Level) + " " + text
+
+	case blockRule:
+		return "---"
+
+	case blockCode:
+		fence := "```"
+		for strings.Contains(block.Text, fence) {
+			fence += "`"
+		}
+		if block.Text == "" {
+			return fence + block.Lang + "\n" + fence
+		}
+		return fence + block.Lang + "\n" + block.Text + "\n" + fence
+
+	case blockQuote:
+		lines := strings.Split(renderMarkdownBlocks(block.Children, false), "\n")
+		for i, line := range lines {
+			if line == "" {
+				lines[i] = ">"
+			} else {
+				lines[i] = "> " + line
+			}
+		}
+		return strings.Join(lines, "\n")
+
+	case blockList:
+		var items []string
+		for i, item := range block.Items {
+			marker := "- "
+			if block.Ordered {
+				marker = fmt.Sprintf("%d. ", i+1)
+			}
+			content := renderMarkdownBlocks(item, true)
+			if len(item) > 0 && item[0].Kind == blockRule {
+				// "- ---" would read as a rule rather than a list item
+				content = "***" + strings.TrimPrefix(content, "---")
+			}
+			items = append(items, prefixLines(content, marker, strings.Repeat(" ", len(marker))))
+		}
+		return strings.Join(items, "\n")
+
+	case blockTable:
+		var lines []string
+		for i, row := range block.Rows {
+			line := "|"
+			for _, cell := range row {
+				if text := renderMarkdownCell(cell); text != "" {
+					line += " " + text + " |"
+				} else {
+					line += " |"
+				}
+			}
+			lines = append(lines, line)
+			if i == 0 {
+				lines = append(lines, "|"+strings.Repeat(" --- |", len(row)))
+			}
+		}
+		return strings.Join(lines, "\n")
+	}
+	return ""
+}
+
+// mdWriter renders inline content, tracking the line start for block-syntax
+// escaping. singleLine is used for headings and table cells, where line
+// breaks have to be written as <br>.
+type mdWriter struct {
+	b           strings.Builder
+	atLineStart bool
+	singleLine  bool
+	inTable     bool
+}
+
+func renderMarkdownInlines(nodes []*richInline, singleLine bool) string {
+	w := &mdWriter{atLineStart: !singleLine, singleLine: singleLine}
+	w.inlines(nodes)
+	return w.b.String()
+}
+
+// renderMarkdownCell renders a table cell, where pipes in code spans need
+// escaping as well
+func renderMarkdownCell(nodes []*richInline) string {
+	w := &mdWriter{singleLine: true, inTable: true}
+	w.inlines(nodes)
+	return w.b.String()
+}
+
+func (w *mdWriter) write(s string) {
+	if s == "" {
+		return
+	}
+	w.b.WriteString(s)
+	w.atLineStart = false
+}
+
+func (w *mdWriter) lastRune() rune {
+	r, _ := utf8.DecodeLastRuneInString(w.b.String())
+	return r
+}
+
+func (w *mdWriter) inlines(nodes []*richInline) {
+	for i, n := range nodes {
+		var next *richInline
+		if i+1 < len(nodes) {
+			next = nodes[i+1]
+		}
+		w.inline(n, next)
+	}
+}
+
+func (w *mdWriter) inline(n, next *richInline) {
+	switch n.Kind {
+	case inlineText:
+		w.write(escapeMarkdownText(n.Text, w.atLineStart))
+
+	case inlineBreak:
+		if w.singleLine {
+			w.write("<br>")
+			return
+		}
+		w.b.WriteString("\n")
+		w.atLineStart = true
+
+	case inlineBold:
+		w.write("**")
+		w.inlines(n.Children)
+		w.write("**")
+
+	case inlineItalic:
+		// Underscores don't work inside words; fall back to asterisks there
+		delim := "_"
+		if isWordRune(w.lastRune()) || (next != nil && isWordRune(firstRune(next))) {
+			delim = "*"
+		}
+		w.write(delim)
+		w.inlines(n.Children)
+		w.write(delim)
+
+	case inlineUnderline:
+		w.write("<u>")
+		w.inlines(n.Children)
+		w.write("</u>")
+
+	case inlineStrike:
+		w.write("~~")
+		w.inlines(n.Children)
+		w.write("~~")
+
+	case inlineCode:
+		w.write(markdownCodeSpan(n.Text, w.inTable))
+
+	case inlineLink:
+		w.write("[")
+		w.inlines(n.Children)
+		w.write("](" + escapeMarkdownURL(n.Href) + ")")
+
+	case inlineImage:
+		alt := escapeMarkdownText(n.Text, false)
+		dest := escapeMarkdownURL(n.Href)
+		if n.GID != "" {
+			dest += ` "asana:` + n.GID + `"`
+		}
+		w.write("![" + alt + "](" + dest + ")")
+	}
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// firstRune returns the first character an inline node renders as text
+func firstRune(n *richInline) rune {
+	switch n.Kind {
+	case inlineText:
+		r, _ := utf8.DecodeRuneInString(n.Text)
+		return r
+	case inlineBold, inlineItalic, inlineStrike, inlineCode, inlineLink, inlineImage, inlineUnderline:
+		return '*'
+	}
+	return ' '
+}
+
+func markdownCodeSpan(text string, inTable bool) string {
+	fence := "`"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+	if strings.HasPrefix(text, "`") || strings.HasSuffix(text, "`") ||
+		(strings.HasPrefix(text, " ") && strings.HasSuffix(text, " ")) {
+		text = " " + text + " "
+	}
+	if inTable {
+		text = strings.ReplaceAll(text, "|", "\\|")
+	}
+	return fence + text + fence
+}
+
+func escapeMarkdownURL(href string) string {
+	return strings.NewReplacer(" ", "%20", "\\", "%5C", "(", "%28", ")", "%29", "<", "%3C", ">", "%3E", "|", "%7C", "\n", "").Replace(href)
+}
+
+var (
+	mdOrderedStartPattern = regexp.MustCompile(`^(\d+)([.)])`)
+	mdLineStartChars      = "#>+-="
+)
+
+// escapeMarkdownText escapes characters that markdown would read as syntax.
+// Underscores are only escaped where they could start or end emphasis, so
+// identifiers like snake_case stay readable.
+func escapeMarkdownText(text string, atLineStart bool) string {
+	var b strings.Builder
+	runes := []rune(text)
+	for i, r := range runes {
+		switch r {
+		case '\\', '*', '`', '[', ']', '<', '~', '|':
+			b.WriteByte('\\')
+		case '_':
+			if i == 0 || i == len(runes)-1 || !isWordRune(runes[i-1]) || !isWordRune(runes[i+1]) {
+				b.WriteByte('\\')
+			}
+		case '&':
+			if mdEntityPattern.MatchString(string(runes[i:])) {
+				b.WriteByte('\\')
+			}
+		case '!':
+			// A link may follow and would turn into an image
+			if i == len(runes)-1 {
+				b.WriteByte('\\')
+			}
+		}
+		b.WriteRune(r)
+	}
+	escaped := b.String()
+
+	if atLineStart && escaped != "" {
+		if strings.ContainsRune(mdLineStartChars, rune(escaped[0])) {
+			return "\\" + escaped
+		}
+		if m := mdOrderedStartPattern.FindStringSubmatch(escaped); m != nil {
+			return m[1] + "\\" + escaped[len(m[1]):]
+		}
+	}
+	return escaped
+}
@@ -0,0 +1,139 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+// DescriptionLinks translates Asana @-mentions and task links to YouTrack
+// logins and issue IDs, and back. Any lookup may be nil; a lookup returning ""
+// leaves the mention or link as it is.
+type DescriptionLinks struct {
+	// YouTrackLogin returns the YouTrack login of a mentioned Asana user
+	YouTrackLogin func(asanaGID, name string) string
+	// YouTrackIssue returns the YouTrack issue ID mapped to an Asana task
+	YouTrackIssue func(asanaGID string) string
+	// AsanaUser returns the Asana user GID for a YouTrack login
+	AsanaUser func(login string) string
+	// AsanaTask returns the Asana task GID mapped to a YouTrack issue ID
+	AsanaTask func(issueID string) string
+}
+
+// youTrackReferencePattern finds @login mentions and issue IDs (e.g. ARD-123)
+// in YouTrack text. The leading group keeps e-mail addresses and longer words
+// from matching.
+var youTrackReferencePattern = regexp.MustCompile(`(^|[^\w@.\-])(?:@(\w(?:[\w.\-]*\w)?)|([A-Z][A-Z0-9_]*-\d+)\b)`)
+
+// toYouTrack replaces resolvable Asana mentions with @login and task links
+// with the mapped issue ID, which YouTrack links by itself
+func (l *DescriptionLinks) toYouTrack(blocks []*richBlock) []*richBlock {
+	if l == nil {
+		return blocks
+	}
+	return normalizeBlocks(mapBlockInlines(blocks, l.youTrackInlines))
+}
+
+func (l *DescriptionLinks) youTrackInlines(nodes []*richInline) []*richInline {
+	var out []*richInline
+	for _, n := range nodes {
+		if n.Kind == inlineLink {
+			if ref := l.youTrackReference(n); ref != "" {
+				out = append(out, &richInline{Kind: inlineText, Text: ref})
+				continue
+			}
+		}
+		if isFormattingKind(n.Kind) {
+			n.Children = l.youTrackInlines(n.Children)
+		}
+		out = append(out, n)
+	}
+	return out
+}
+
+func (l *DescriptionLinks) youTrackReference(n *richInline) string {
+	switch n.GIDType {
+	case asanaGIDTypeUser:
+		if l.YouTrackLogin == nil {
+			return ""
+		}
+		name := strings.TrimPrefix(strings.TrimSpace(plainInlines(n.Children)), "@")
+		if login := l.YouTrackLogin(n.GID, name); login != "" {
+			return "@" + login
+		}
+	case asanaGIDTypeTask:
+		if l.YouTrackIssue != nil {
+			return l.YouTrackIssue(n.GID)
+		}
+	}
+	return ""
+}
+
+// toAsana turns @login mentions and issue IDs that resolve into Asana
+// mentions and task links
+func (l *DescriptionLinks) toAsana(blocks []*richBlock) []*richBlock {
+	if l == nil {
+		return blocks
+	}
+	return normalizeBlocks(mapBlockInlines(blocks, l.asanaInlines))
+}
+
+func (l *DescriptionLinks) asanaInlines(nodes []*richInline) []*richInline {
+	var out []*richInline
+	for _, n := range nodes {
+		switch {
+		case n.Kind == inlineText:
+			out = append(out, l.asanaText(n.Text)...)
+			continue
+		case isFormattingKind(n.Kind):
+			n.Children = l.asanaInlines(n.Children)
+		}
+		out = append(out, n)
+	}
+	return out
+}
+
+func (l *DescriptionLinks) asanaText(text string) []*richInline {
+	var out []*richInline
+	last := 0
+	for _, m := range youTrackReferencePattern.FindAllStringSubmatchIndex(text, -1) {
+		link := &richInline{Kind: inlineLink}
+		switch {
+		case m[4] >= 0 && l.AsanaUser != nil:
+			login := text[m[4]:m[5]]
+			link.GID, link.GIDType = l.AsanaUser(login), asanaGIDTypeUser
+			link.Children = []*richInline{{Kind: inlineText, Text: "@" + login}}
+		case m[6] >= 0 && l.AsanaTask != nil:
+			issueID := text[m[6]:m[7]]
+			link.GID, link.GIDType = l.AsanaTask(issueID), asanaGIDTypeTask
+			link.Children = []*richInline{{Kind: inlineText, Text: issueID}}
+		}
+		if link.GID == "" {
+			continue
+		}
+		canonicalAsanaLink(link)
+		// m[3] is the end of the leading character, where the reference starts
+		out = append(out, &richInline{Kind: inlineText, Text: text[last:m[3]]}, link)
+		last = m[1]
+	}
+	if last < len(text) {
+		out = append(out, &richInline{Kind: inlineText, Text: text[last:]})
+	}
+	return out
+}
+
+// mapBlockInlines applies fn to every run of inline content in the blocks
+func mapBlockInlines(blocks []*richBlock, fn func([]*richInline) []*richInline) []*richBlock {
+	for _, b := range blocks {
+		b.Inlines = fn(b.Inlines)
+		b.Children = mapBlockInlines(b.Children, fn)
+		for i, item := range b.Items {
+			b.Items[i] = mapBlockInlines(item, fn)
+		}
+		for _, row := range b.Rows {
+			for i, cell := range row {
+				row[i] = fn(cell)
+			}
+		}
+	}
+	return blocks
+}
@@ -0,0 +1,547 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Rich text is converted through a small document model shared by the
+// Asana HTML, YouTrack wikified HTML and YouTrack markdown converters. Every
+// converter parses into this model and renders out of it, so converting a
+// description back and forth always lands on the same text.
+
+type richBlockKind int
+
+const (
+	blockParagraph richBlockKind = iota
+	blockHeading
+	blockList
+	blockCode
+	blockQuote
+	blockRule
+	blockTable
+)
+
+type richInlineKind int
+
+const (
+	inlineText richInlineKind = iota
+	inlineBreak
+	inlineBold
+	inlineItalic
+	inlineUnderline
+	inlineStrike
+	inlineCode
+	inlineLink
+	inlineImage
+)
+
+// richBlock is a block-level element. Which fields are used depends on Kind.
+type richBlock struct {
+	Kind     richBlockKind
+	Level    int               // heading level
+	Ordered  bool              // ordered list
+	Items    [][]*richBlock    // list items
+	Inlines  []*richInline     // paragraph and heading content
+	Text     string            // code block content
+	Lang     string            // code block language
+	Children []*richBlock      // blockquote content
+	Rows     [][][]*richInline // table rows of cells
+}
+
+// richInline is an inline element. Text holds the text of text and code
+// nodes and the alt text of images; Href holds link targets and image sources.
+type richInline struct {
+	Kind     richInlineKind
+	Text     string
+	Href     string
+	GID      string // Asana object behind a mention, task link or inline image
+	GIDType  string // "user" or "task" for links
+	Children []*richInline
+}
+
+const (
+	asanaGIDTypeUser = "user"
+	asanaGIDTypeTask = "task"
+)
+
+var (
+	asanaProfileURLPattern = regexp.MustCompile(`^https://app\.asana\.com/0/profile/(\d+)/?$`)
+	asanaTaskURLPattern    = regexp.MustCompile(`^https://app\.asana\.com/0/\d+/(\d+)(?:/f)?/?$`)
+)
+
+// asanaProfileURL and asanaTaskURL are the canonical links for mentions; the
+// markdown side identifies mentions by these URLs
+func asanaProfileURL(gid string) string {
+	return fmt.Sprintf("https://app.asana.com/0/profile/%s", gid)
+}
+
+func asanaTaskURL(gid string) string {
+	return fmt.Sprintf("https://app.asana.com/0/0/%s", gid)
+}
+
+// asanaLinkGID recognizes links to Asana users and tasks
+func asanaLinkGID(href string) (string, string) {
+	if m := asanaProfileURLPattern.FindStringSubmatch(href); m != nil {
+		return m[1], asanaGIDTypeUser
+	}
+	if m := asanaTaskURLPattern.FindStringSubmatch(href); m != nil {
+		return m[1], asanaGIDTypeTask
+	}
+	return "", ""
+}
+
+// canonicalAsanaLink points links to Asana users and tasks at their
+// canonical URL, so the same mention always renders the same way
+func canonicalAsanaLink(n *richInline) {
+	switch n.GIDType {
+	case asanaGIDTypeUser:
+		n.Href = asanaProfileURL(n.GID)
+	case asanaGIDTypeTask:
+		n.Href = asanaTaskURL(n.GID)
+	}
+}
+
+func isFormattingKind(kind richInlineKind) bool {
+	switch kind {
+	case inlineBold, inlineItalic, inlineUnderline, inlineStrike:
+		return true
+	}
+	return false
+}
+
+// ─── Normalization ──────────────────────────────────────────────────────────
+//
+// Normalization brings a parsed document into the single shape each renderer
+// produces, e.g. no empty formatting, no nested bold, no whitespace at the
+// edges of emphasis and no empty paragraphs.
+
+func normalizeBlocks(blocks []*richBlock) []*richBlock {
+	var out []*richBlock
+	for _, b := range blocks {
+		switch b.Kind {
+		case blockParagraph:
+			b.Inlines = normalizeParagraph(b.Inlines)
+			if len(b.Inlines) == 0 {
+				continue
+			}
+		case blockHeading:
+			b.Inlines = normalizeParagraph(b.Inlines)
+			if len(b.Inlines) == 0 {
+				continue
+			}
+			if b.Level < 1 {
+				b.Level = 1
+			}
+			if b.Level > 6 {
+				b.Level = 6
+			}
+		case blockList:
+			var items [][]*richBlock
+			for _, item := range b.Items {
+				// An empty item can't follow a paragraph in markdown
+				if item = normalizeBlocks(item); len(item) > 0 {
+					items = append(items, item)
+				}
+			}
+			if len(items) == 0 {
+				continue
+			}
+			b.Items = items
+			// Adjacent lists of the same kind read as one list in markdown
+			if n := len(out); n > 0 && out[n-1].Kind == blockList && out[n-1].Ordered == b.Ordered {
+				out[n-1].Items = append(out[n-1].Items, b.Items...)
+				continue
+			}
+		case blockCode:
+			b.Text = trimTrailingLineSpace(strings.ReplaceAll(b.Text, "\r\n", "\n"))
+			b.Text = strings.Trim(b.Text, "\n")
+			b.Lang = strings.TrimSpace(b.Lang)
+		case blockQuote:
+			b.Children = normalizeBlocks(b.Children)
+			if len(b.Children) == 0 {
+				continue
+			}
+		case blockTable:
+			width := 0
+			var rows [][][]*richInline
+			for _, row := range b.Rows {
+				if len(row) == 0 {
+					continue
+				}
+				var cells [][]*richInline
+				for _, cell := range row {
+					cells = append(cells, normalizeParagraph(cell))
+				}
+				rows = append(rows, cells)
+				if len(cells) > width {
+					width = len(cells)
+				}
+			}
+			if len(rows) == 0 {
+				continue
+			}
+			for i := range rows {
+				for len(rows[i]) < width {
+					rows[i] = append(rows[i], nil)
+				}
+			}
+			b.Rows = rows
+		}
+		out = append(out, b)
+	}
+	return out
+}
+
+func trimTrailingLineSpace(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRightFunc(line, unicode.IsSpace)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// normalizeParagraph normalizes the content of a paragraph, heading or table
+// cell: inline normalization plus trimming of whitespace around line breaks
+func normalizeParagraph(nodes []*richInline) []*richInline {
+	nodes = normalizeInlines(nodes)
+
+	// Line breaks inside formatting can't span paragraphs; collapse repeats
+	nodes = collapseBreaks(nodes)
+
+	// Trim spaces at the start and end of every line, then drop whatever
+	// became empty
+	leaves := inlineLeaves(nodes, nil)
+	for i, n := range leaves {
+		if n.Kind != inlineText {
+			continue
+		}
+		if i == 0 || leaves[i-1].Kind == inlineBreak {
+			n.Text = strings.TrimLeftFunc(n.Text, unicode.IsSpace)
+		}
+		if i == len(leaves)-1 || leaves[i+1].Kind == inlineBreak {
+			n.Text = strings.TrimRightFunc(n.Text, unicode.IsSpace)
+		}
+	}
+	nodes = collapseBreaks(normalizeInlines(nodes))
+
+	// No leading or trailing line breaks
+	for len(nodes) > 0 && nodes[0].Kind == inlineBreak {
+		nodes = nodes[1:]
+	}
+	for len(nodes) > 0 && nodes[len(nodes)-1].Kind == inlineBreak {
+		nodes = nodes[:len(nodes)-1]
+	}
+	return nodes
+}
+
+func normalizeInlines(nodes []*richInline) []*richInline {
+	var out []*richInline
+	for _, n := range nodes {
+		switch {
+		case n.Kind == inlineText:
+			if n.Text == "" {
+				continue
+			}
+		case n.Kind == inlineCode:
+			n.Text = strings.ReplaceAll(n.Text, "\n", " ")
+			if strings.TrimSpace(n.Text) == "" {
+				n = &richInline{Kind: inlineText, Text: n.Text}
+			} else if last := len(out) - 1; last >= 0 && out[last].Kind == inlineCode {
+				// Adjacent code spans would read as one in markdown
+				out[last].Text += n.Text
+				continue
+			}
+		case n.Kind == inlineLink:
+			// Links can't nest in markdown
+			n.Children = normalizeInlines(flattenKind(n.Children, inlineLink))
+			lead, trail := hoistWhitespace(n)
+			if len(n.Children) == 0 {
+				n.Children = []*richInline{{Kind: inlineText, Text: n.Href}}
+			}
+			out = appendInline(out, lead...)
+			out = appendInline(out, n)
+			out = appendInline(out, trail...)
+			continue
+		case isFormattingKind(n.Kind):
+			n.Children = normalizeInlines(flattenKind(n.Children, n.Kind))
+			if len(n.Children) == 0 {
+				continue
+			}
+			// Whitespace at the edges of emphasis moves outside of it
+			lead, trail := hoistWhitespace(n)
+			if len(n.Children) == 0 {
+				out = appendInline(out, lead...)
+				out = appendInline(out, trail...)
+				continue
+			}
+			out = appendInline(out, lead...)
+			if n.Kind == inlineBold && len(n.Children) > 1 && n.Children[0].Kind == inlineItalic && isWordRune(firstRune(n.Children[1])) {
+				// Markdown would open this with an ambiguous "***"; italics
+				// around the bold part say the same thing
+				italic := &richInline{Kind: inlineItalic, Children: []*richInline{{Kind: inlineBold, Children: n.Children[0].Children}}}
+				out = appendInline(out, normalizeInlines([]*richInline{italic, {Kind: inlineBold, Children: n.Children[1:]}})...)
+			} else {
+				out = appendInline(out, n)
+			}
+			out = appendInline(out, trail...)
+			continue
+		}
+		out = appendInline(out, n)
+	}
+	return out
+}
+
+// appendInline appends nodes, merging adjacent text and adjacent formatting
+// of the same kind
+func appendInline(out []*richInline, nodes ...*richInline) []*richInline {
+	for _, n := range nodes {
+		if len(out) > 0 {
+			last := out[len(out)-1]
+			if last.Kind == inlineText && n.Kind == inlineText {
+				last.Text += n.Text
+				continue
+			}
+			if isFormattingKind(n.Kind) && last.Kind == n.Kind {
+				last.Children = normalizeInlines(append(last.Children, n.Children...))
+				continue
+			}
+		}
+		out = append(out, n)
+	}
+	return out
+}
+
+// flattenKind removes nested formatting of the same kind at any depth, e.g.
+// bold in italic in bold
+func flattenKind(nodes []*richInline, kind richInlineKind) []*richInline {
+	var out []*richInline
+	for _, n := range nodes {
+		if n.Kind == kind {
+			out = append(out, flattenKind(n.Children, kind)...)
+			continue
+		}
+		if isFormattingKind(n.Kind) || n.Kind == inlineLink {
+			n.Children = flattenKind(n.Children, kind)
+		}
+		out = append(out, n)
+	}
+	return out
+}
+
+// inlineLeaves lists text, break, code and image nodes in document order
+func inlineLeaves(nodes []*richInline, leaves []*richInline) []*richInline {
+	for _, n := range nodes {
+		if isFormattingKind(n.Kind) || n.Kind == inlineLink {
+			leaves = inlineLeaves(n.Children, leaves)
+			continue
+		}
+		leaves = append(leaves, n)
+	}
+	return leaves
+}
+
+// hoistWhitespace takes leading and trailing whitespace and line breaks out
+// of a formatting node and returns them
+func hoistWhitespace(n *richInline) ([]*richInline, []*richInline) {
+	var lead, trail []*richInline
+	for len(n.Children) > 0 {
+		first := n.Children[0]
+		if first.Kind == inlineBreak {
+			lead = append(lead, first)
+			n.Children = n.Children[1:]
+			continue
+		}
+		if first.Kind == inlineText {
+			trimmed := strings.TrimLeftFunc(first.Text, unicode.IsSpace)
+			if trimmed != first.Text {
+				lead = append(lead, &richInline{Kind: inlineText, Text: first.Text[:len(first.Text)-len(trimmed)]})
+				first.Text = trimmed
+			}
+			if first.Text == "" {
+				n.Children = n.Children[1:]
+				continue
+			}
+		}
+		if isFormattingKind(first.Kind) || first.Kind == inlineLink {
+			inner, _ := hoistWhitespace(first)
+			lead = append(lead, inner...)
+			if len(first.Children) == 0 {
+				n.Children = n.Children[1:]
+				continue
+			}
+		}
+		break
+	}
+	for len(n.Children) > 0 {
+		last := n.Children[len(n.Children)-1]
+		if last.Kind == inlineBreak {
+			trail = append([]*richInline{last}, trail...)
+			n.Children = n.Children[:len(n.Children)-1]
+			continue
+		}
+		if last.Kind == inlineText {
+			trimmed := strings.TrimRightFunc(last.Text, unicode.IsSpace)
+			if trimmed != last.Text {
+				trail = append([]*richInline{{Kind: inlineText, Text: last.Text[len(trimmed):]}}, trail...)
+				last.Text = trimmed
+			}
+			if last.Text == "" {
+				n.Children = n.Children[:len(n.Children)-1]
+				continue
+			}
+		}
+		if isFormattingKind(last.Kind) || last.Kind == inlineLink {
+			_, inner := hoistWhitespace(last)
+			trail = append(inner, trail...)
+			if len(last.Children) == 0 {
+				n.Children = n.Children[:len(n.Children)-1]
+				continue
+			}
+		}
+		break
+	}
+	return lead, trail
+}
+
+// collapseBreaks turns runs of line breaks into a single break. Paragraphs
+// are split on blank lines before this runs, so any run left is inside
+// formatting or a heading.
+func collapseBreaks(nodes []*richInline) []*richInline {
+	var out []*richInline
+	for _, n := range nodes {
+		if n.Kind == inlineBreak && len(out) > 0 && out[len(out)-1].Kind == inlineBreak {
+			continue
+		}
+		if isFormattingKind(n.Kind) || n.Kind == inlineLink {
+			n.Children = collapseBreaks(n.Children)
+		}
+		out = append(out, n)
+	}
+	return out
+}
+
+// splitParagraphs splits inline content on blank lines (two or more line
+// breaks with only whitespace between them) into paragraphs
+func splitParagraphs(nodes []*richInline) []*richBlock {
+	var blocks []*richBlock
+	var current []*richInline
+	breaks := 0
+	var pending []*richInline
+
+	flush := func() {
+		if len(current) > 0 {
+			blocks = append(blocks, &richBlock{Kind: blockParagraph, Inlines: current})
+		}
+		current = nil
+	}
+
+	for _, n := range nodes {
+		if n.Kind == inlineBreak {
+			breaks++
+			pending = append(pending, n)
+			continue
+		}
+		if n.Kind == inlineText && strings.TrimSpace(n.Text) == "" && breaks > 0 {
+			pending = append(pending, n)
+			continue
+		}
+		if breaks >= 2 {
+			flush()
+		} else {
+			current = append(current, pending...)
+		}
+		breaks = 0
+		pending = nil
+		current = append(current, n)
+	}
+	flush()
+	return blocks
+}
+
+// ─── Plain Text Rendering ───────────────────────────────────────────────────
+
+func renderPlainText(blocks []*richBlock) string {
+	return renderPlainTextBlocks(blocks, false)
+}
+
+func renderPlainTextBlocks(blocks []*richBlock, inListItem bool) string {
+	var parts []string
+	for i, b := range blocks {
+		if inListItem && i > 0 && b.Kind == blockList && blocks[i-1].Kind == blockParagraph {
+			parts[len(parts)-1] += "\n" + renderPlainTextBlocks([]*richBlock{b}, false)
+			continue
+		}
+		switch b.Kind {
+		case blockParagraph, blockHeading:
+			parts = append(parts, plainInlines(b.Inlines))
+		case blockCode:
+			parts = append(parts, b.Text)
+		case blockQuote:
+			parts = append(parts, renderPlainText(b.Children))
+		case blockRule:
+			parts = append(parts, "---")
+		case blockList:
+			var items []string
+			for i, item := range b.Items {
+				marker := "- "
+				if b.Ordered {
+					marker = fmt.Sprintf("%d. ", i+1)
+				}
+				items = append(items, prefixLines(renderPlainTextBlocks(item, true), marker, strings.Repeat(" ", len(marker))))
+			}
+			parts = append(parts, strings.Join(items, "\n"))
+		case blockTable:
+			var rows []string
+			for _, row := range b.Rows {
+				var cells []string
+				for _, cell := range row {
+					cells = append(cells, plainInlines(cell))
+				}
+				rows = append(rows, strings.Join(cells, " | "))
+			}
+			parts = append(parts, strings.Join(rows, "\n"))
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+func plainInlines(nodes []*richInline) string {
+	var b strings.Builder
+	for _, n := range nodes {
+		switch n.Kind {
+		case inlineText, inlineCode:
+			b.WriteString(n.Text)
+		case inlineBreak:
+			b.WriteString("\n")
+		case inlineImage:
+			b.WriteString(n.Text)
+		case inlineLink:
+			text := plainInlines(n.Children)
+			b.WriteString(text)
+			if n.GID == "" && text != n.Href {
+				b.WriteString(" (" + n.Href + ")")
+			}
+		default:
+			b.WriteString(plainInlines(n.Children))
+		}
+	}
+	return b.String()
+}
+
+// prefixLines prefixes the first line with first and every other non-empty
+// line with rest
+func prefixLines(text, first, rest string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		switch {
+		case i == 0:
+			lines[i] = strings.TrimRight(first+line, " ")
+		case line != "":
+			lines[i] = rest + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
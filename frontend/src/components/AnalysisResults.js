@@ -936,7 +936,7 @@ const AnalysisResults = ({
                       ↳ <span className="font-medium text-gray-700">{ytIssueId}</span>{' '}
                       {item.youtrack_issue?.summary}
                     </div>
-                    <div className="text-xs text-gray-400 mb-3">via: {item.match_method}</div>
+                    <div className="text-xs text-gray-400 mb-3">via: {item.match_strategy || item.match_method}</div>
                     {isMappingDone ? (
                       <div className="text-green-600 text-sm font-medium text-center py-1">✓ Mapped</div>
                     ) : (
@@ -342,7 +342,13 @@ const UserSettings = ({ onBack }) => {
     clearMessages();
 
     try {
-      await updateUserSettings(settings);
+      // Stored credentials come back masked and an empty value keeps them,
+      // so emptying a saved field has to ask for it to be removed
+      await updateUserSettings({
+        ...settings,
+        clear_asana_pat: !settings.asana_pat && !!initialSettings?.asana_pat,
+        clear_youtrack_token: !settings.youtrack_token && !!initialSettings?.youtrack_token
+      });
       setInitialSettings(settings); // Update initial settings after successful save
       setHasUnsavedChanges(false); // Reset unsaved changes flag - this will disable the Save button
       // Keep connectionStatus as true so the Save button remains visible but disabled
@@ -1,7 +1,7 @@
 // Fixed AuthContext - Replace frontend/src/contexts/AuthContext.js
 
 import React, { createContext, useContext, useReducer, useEffect } from 'react';
-import { getCurrentUser, isAuthenticated, clearAuth, createWebSocketConnection } from '../services/api';
+import { getCurrentUser, isAuthenticated, clearAuth, ensureFreshToken, createWebSocketConnection } from '../services/api';
 
 // Auth context
 const AuthContext = createContext();
@@ -148,7 +148,8 @@ export const AuthProvider = ({ children }) => {
       dispatch({ type: AUTH_ACTIONS.SET_INITIALIZING, payload: { initializing: true } });
       
       try {
-        if (isAuthenticated()) {
+        // A stored access token may have expired since the last visit
+        if (isAuthenticated() && await ensureFreshToken()) {
           console.log('🔍 Auth: Token found, getting current user...');
           const response = await getCurrentUser();
           const user = response.data || response.user || response;
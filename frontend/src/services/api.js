@@ -20,6 +20,11 @@ const getAuthHeaders = () => {
   return headers;
 };
 
+// Access tokens are short-lived; the refresh token renews them shortly before they expire
+const REFRESH_MARGIN_MS = 60 * 1000;
+let refreshTimer = null;
+let refreshInFlight = null;
+
 const setAuthToken = (token) => {
   authToken = token;
   if (token) {
@@ -29,17 +34,106 @@ const setAuthToken = (token) => {
   } else {
     localStorage.removeItem('auth_token');
     localStorage.removeItem('token');
+    localStorage.removeItem('refresh_token');
+    localStorage.removeItem('token_expires_at');
+    clearTimeout(refreshTimer);
+    refreshTimer = null;
+  }
+};
+
+const scheduleRefresh = () => {
+  clearTimeout(refreshTimer);
+  const expiresAt = Date.parse(localStorage.getItem('token_expires_at') || '');
+  if (!localStorage.getItem('refresh_token') || Number.isNaN(expiresAt)) {
+    return;
+  }
+  const delay = Math.max(expiresAt - Date.now() - REFRESH_MARGIN_MS, 5000);
+  refreshTimer = setTimeout(() => { refreshSession(); }, delay);
+};
+
+// Stores the tokens of a login or refresh response
+const setAuthSession = (data) => {
+  if (!data?.token) {
+    return;
+  }
+  setAuthToken(data.token);
+  if (data.refresh_token) {
+    localStorage.setItem('refresh_token', data.refresh_token);
+  }
+  if (data.expires_at) {
+    localStorage.setItem('token_expires_at', data.expires_at);
+  }
+  scheduleRefresh();
+};
+
+// Exchanges the refresh token for a new token pair; resolves to false when
+// the session is gone
+export const refreshSession = () => {
+  const refreshToken = localStorage.getItem('refresh_token');
+  if (!refreshToken) {
+    return Promise.resolve(false);
   }
+  if (!refreshInFlight) {
+    refreshInFlight = fetch(`${API_BASE}/api/auth/refresh`, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify({ refresh_token: refreshToken }),
+    })
+      .then(async (response) => {
+        if (!response.ok) {
+          if (response.status === 401) {
+            setAuthToken(null);
+          }
+          return false;
+        }
+        const result = await response.json();
+        setAuthSession(result.data || result);
+        return true;
+      })
+      .catch(() => false)
+      .finally(() => { refreshInFlight = null; });
+  }
+  return refreshInFlight;
+};
+
+// Refreshes the access token first if it has expired or is about to
+export const ensureFreshToken = async () => {
+  const expiresAt = Date.parse(localStorage.getItem('token_expires_at') || '');
+  if (!Number.isNaN(expiresAt) && expiresAt - Date.now() < REFRESH_MARGIN_MS) {
+    return refreshSession();
+  }
+  return !!authToken;
+};
+
+// Sends an authenticated request. A 401 may only mean the access token has
+// expired, so after a successful refresh the request is sent once more with
+// the new token.
+export const authFetch = async (url, options = {}) => {
+  const response = await fetch(url, options);
+  if (response.status !== 401 || !(await refreshSession())) {
+    return response;
+  }
+  return fetch(url, {
+    ...options,
+    headers: { ...options.headers, Authorization: getAuthHeaders().Authorization },
+  });
 };
 
 const handleAuthError = (response) => {
   if (response.status === 401) {
-    setAuthToken(null);
-    window.location.href = '/';
+    // The access token may just have expired; only sign out if the session is gone too
+    refreshSession().then((refreshed) => {
+      if (!refreshed) {
+        setAuthToken(null);
+        window.location.href = '/';
+      }
+    });
   }
   return response;
 };
 
+scheduleRefresh();
+
 // ============================================================================
 // AUTHENTICATION ENDPOINTS (unchanged)
 // ============================================================================
@@ -57,9 +151,7 @@ export const register = async (userData) => {
   }
 
   const result = await response.json();
-  if (result.data?.token || result.token) {
-    setAuthToken(result.data?.token || result.token);
-  }
+  setAuthSession(result.data?.token ? result.data : result);
   return result;
 };
 
@@ -76,10 +168,7 @@ export const login = async (credentials) => {
   }
 
   const result = await response.json();
-  const token = result.data?.token || result.token;
-  if (token) {
-    setAuthToken(token);
-  }
+  setAuthSession(result.data?.token ? result.data : result);
   return result;
 };
 
@@ -103,7 +192,7 @@ export const getCurrentUser = async () => {
     throw new Error('Not authenticated');
   }
 
-  const response = await fetch(`${API_BASE}/api/auth/me`, {
+  const response = await authFetch(`${API_BASE}/api/auth/me`, {
     headers: getAuthHeaders(),
   });
 
@@ -120,7 +209,7 @@ export const changePassword = async (oldPassword, newPassword) => {
     throw new Error('Not authenticated');
   }
 
-  const response = await fetch(`${API_BASE}/api/auth/change-password`, {
+  const response = await authFetch(`${API_BASE}/api/auth/change-password`, {
     method: 'POST',
     headers: getAuthHeaders(),
     body: JSON.stringify({
@@ -143,7 +232,7 @@ export const deleteAccount = async (deleteData) => {
     throw new Error('Not authenticated');
   }
 
-  const response = await fetch(`${API_BASE}/api/auth/account/delete`, {
+  const response = await authFetch(`${API_BASE}/api/auth/account/delete`, {
     method: 'POST',
     headers: getAuthHeaders(),
     body: JSON.stringify(deleteData),
@@ -169,7 +258,7 @@ export const getUserSettings = async () => {
     throw new Error('Not authenticated');
   }
 
-  const response = await fetch(`${API_BASE}/api/settings`, {
+  const response = await authFetch(`${API_BASE}/api/settings`, {
     headers: getAuthHeaders(),
   });
 
@@ -182,7 +271,7 @@ export const getUserSettings = async () => {
 };
 
 export const updateUserSettings = async (settings) => {
-  const response = await fetch(`${API_BASE}/api/settings`, {
+  const response = await authFetch(`${API_BASE}/api/settings`, {
     method: 'PUT',
     headers: getAuthHeaders(),
     body: JSON.stringify(settings),
@@ -198,7 +287,7 @@ export const updateUserSettings = async (settings) => {
 };
 
 export const getAsanaProjects = async () => {
-  const response = await fetch(`${API_BASE}/api/settings/asana/projects`, {
+  const response = await authFetch(`${API_BASE}/api/settings/asana/projects`, {
     headers: getAuthHeaders(),
   });
 
@@ -211,7 +300,7 @@ export const getAsanaProjects = async () => {
 };
 
 export const getYouTrackProjects = async () => {
-  const response = await fetch(`${API_BASE}/api/settings/youtrack/projects`, {
+  const response = await authFetch(`${API_BASE}/api/settings/youtrack/projects`, {
     headers: getAuthHeaders(),
   });
 
@@ -224,7 +313,7 @@ export const getYouTrackProjects = async () => {
 };
 
 export const testConnections = async () => {
-  const response = await fetch(`${API_BASE}/api/settings/test-connections`, {
+  const response = await authFetch(`${API_BASE}/api/settings/test-connections`, {
     method: 'POST',
     headers: getAuthHeaders(),
   });
@@ -242,7 +331,7 @@ export const testConnections = async () => {
 // ============================================================================
 
 export const createMapping = async (asanaUrl, youtrackUrl) => {
-  const response = await fetch(`${API_BASE}/api/mappings`, {
+  const response = await authFetch(`${API_BASE}/api/mappings`, {
     method: 'POST',
     headers: getAuthHeaders(),
     body: JSON.stringify({
@@ -261,7 +350,7 @@ export const createMapping = async (asanaUrl, youtrackUrl) => {
 };
 
 export const getAllMappings = async () => {
-  const response = await fetch(`${API_BASE}/api/mappings`, {
+  const response = await authFetch(`${API_BASE}/api/mappings`, {
     headers: getAuthHeaders(),
   });
 
@@ -274,7 +363,7 @@ export const getAllMappings = async () => {
 };
 
 export const deleteMapping = async (id) => {
-  const response = await fetch(`${API_BASE}/api/mappings/${id}`, {
+  const response = await authFetch(`${API_BASE}/api/mappings/${id}`, {
     method: 'DELETE',
     headers: getAuthHeaders(),
   });
@@ -289,7 +378,7 @@ export const deleteMapping = async (id) => {
 };
 
 export const findMappingByAsanaId = async (taskId) => {
-  const response = await fetch(`${API_BASE}/api/mappings/asana/${taskId}`, {
+  const response = await authFetch(`${API_BASE}/api/mappings/asana/${taskId}`, {
     headers: getAuthHeaders(),
   });
 
@@ -302,7 +391,7 @@ export const findMappingByAsanaId = async (taskId) => {
 };
 
 export const findMappingByYouTrackId = async (issueId) => {
-  const response = await fetch(`${API_BASE}/api/mappings/youtrack/${issueId}`, {
+  const response = await authFetch(`${API_BASE}/api/mappings/youtrack/${issueId}`, {
     headers: getAuthHeaders(),
   });
 
@@ -319,7 +408,7 @@ export const findMappingByYouTrackId = async (issueId) => {
 // ============================================================================
 
 export const startSync = async (syncData) => {
-  const response = await fetch(`${API_BASE}/api/sync/start`, {
+  const response = await authFetch(`${API_BASE}/api/sync/start`, {
     method: 'POST',
     headers: getAuthHeaders(),
     body: JSON.stringify(syncData),
@@ -334,7 +423,7 @@ export const startSync = async (syncData) => {
 };
 
 export const getSyncStatus = async (operationId) => {
-  const response = await fetch(`${API_BASE}/api/sync/status/${operationId}`, {
+  const response = await authFetch(`${API_BASE}/api/sync/status/${operationId}`, {
     headers: getAuthHeaders(),
   });
 
@@ -359,7 +448,7 @@ export const getHealth = async () => {
 };
 
 export const getStatus = async () => {
-  const response = await fetch(`${API_BASE}/status`, {
+  const response = await authFetch(`${API_BASE}/status`, {
     headers: getAuthHeaders()
   });
   if (!response.ok) {
@@ -376,7 +465,7 @@ export const analyzeTicketsWithProgress = async (columnFilter = '', onProgress)
     ? `${API_BASE}/analyze/progress?column=${encodeURIComponent(columnFilter)}`
     : `${API_BASE}/analyze/progress`;
 
-  const response = await fetch(url, { headers: getAuthHeaders() });
+  const response = await authFetch(url, { headers: getAuthHeaders() });
   if (!response.ok) {
     handleAuthError(response);
     throw new Error(`Analysis failed: ${response.status}`);
@@ -414,7 +503,7 @@ export const analyzeTickets = async (columnFilter = '') => {
     url += `?column=${encodeURIComponent(columnFilter)}`;
   }
 
-  const response = await fetch(url, { headers: getAuthHeaders() });
+  const response = await authFetch(url, { headers: getAuthHeaders() });
 
   if (!response.ok) {
     handleAuthError(response);
@@ -430,7 +519,7 @@ export const createMissingTickets = async (column = '') => {
     url += `?column=${encodeURIComponent(column)}`;
   }
   
-  const response = await fetch(url, {
+  const response = await authFetch(url, {
     method: 'POST',
     headers: getAuthHeaders(),
   });
@@ -443,7 +532,7 @@ export const createMissingTickets = async (column = '') => {
 };
 
 export const createSingleTicket = async (taskId) => {
-  const response = await fetch(`${API_BASE}/create-single`, {
+  const response = await authFetch(`${API_BASE}/create-single`, {
     method: 'POST',
     headers: getAuthHeaders(),
     body: JSON.stringify({ task_id: taskId }),
@@ -463,7 +552,7 @@ export const syncTickets = async (tickets, column = '') => {
     url += `?column=${encodeURIComponent(column)}`;
   }
 
-  const response = await fetch(url, {
+  const response = await authFetch(url, {
     method: 'POST',
     headers: getAuthHeaders(),
     body: JSON.stringify(tickets),
@@ -486,7 +575,7 @@ export const getTicketsByType = async (type, column = '') => {
     params.append('column', column);
   }
   
-  const response = await fetch(`${API_BASE}/tickets?${params}`, {
+  const response = await authFetch(`${API_BASE}/tickets?${params}`, {
     headers: getAuthHeaders()
   });
   
@@ -507,7 +596,7 @@ export const deleteTickets = async (ticketIds, source) => {
     throw new Error('source must be one of: asana, youtrack, both');
   }
 
-  const response = await fetch(`${API_BASE}/delete-tickets`, {
+  const response = await authFetch(`${API_BASE}/delete-tickets`, {
     method: 'POST',
     headers: getAuthHeaders(),
     body: JSON.stringify({
@@ -528,7 +617,7 @@ export const deleteTickets = async (ticketIds, source) => {
 };
 
 export const ignoreTicket = async (ticketId, type = 'forever') => {
-  const response = await fetch(`${API_BASE}/ignore`, {
+  const response = await authFetch(`${API_BASE}/ignore`, {
     method: 'POST',
     headers: getAuthHeaders(),
     body: JSON.stringify({ 
@@ -546,7 +635,7 @@ export const ignoreTicket = async (ticketId, type = 'forever') => {
 };
 
 export const unignoreTicket = async (ticketId, type = 'forever') => {
-  const response = await fetch(`${API_BASE}/ignore`, {
+  const response = await authFetch(`${API_BASE}/ignore`, {
     method: 'POST',
     headers: getAuthHeaders(),
     body: JSON.stringify({ 
@@ -564,7 +653,7 @@ export const unignoreTicket = async (ticketId, type = 'forever') => {
 };
 
 export const mapTicket = async (asanaTaskId, youtrackIssueId) => {
-  const response = await fetch(`${API_BASE}/map-ticket`, {
+  const response = await authFetch(`${API_BASE}/map-ticket`, {
     method: 'POST',
     headers: getAuthHeaders(),
     body: JSON.stringify({
@@ -580,7 +669,7 @@ export const mapTicket = async (asanaTaskId, youtrackIssueId) => {
 };
 
 export const getAutoSyncStatus = async () => {
-  const response = await fetch(`${API_BASE}/auto-sync`, {
+  const response = await authFetch(`${API_BASE}/auto-sync`, {
     headers: getAuthHeaders()
   });
   
@@ -592,7 +681,7 @@ export const getAutoSyncStatus = async () => {
 };
 
 export const startAutoSync = async (interval = 15) => {
-  const response = await fetch(`${API_BASE}/auto-sync`, {
+  const response = await authFetch(`${API_BASE}/auto-sync`, {
     method: 'POST',
     headers: getAuthHeaders(),
     body: JSON.stringify({ action: 'start', interval }),
@@ -606,7 +695,7 @@ export const startAutoSync = async (interval = 15) => {
 };
 
 export const stopAutoSync = async () => {
-  const response = await fetch(`${API_BASE}/auto-sync`, {
+  const response = await authFetch(`${API_BASE}/auto-sync`, {
     method: 'POST',
     headers: getAuthHeaders(),
     body: JSON.stringify({ action: 'stop' }),
@@ -620,7 +709,7 @@ export const stopAutoSync = async () => {
 };
 
 export const getAutoCreateStatus = async () => {
-  const response = await fetch(`${API_BASE}/auto-create`, {
+  const response = await authFetch(`${API_BASE}/auto-create`, {
     headers: getAuthHeaders()
   });
   
@@ -632,7 +721,7 @@ export const getAutoCreateStatus = async () => {
 };
 
 export const startAutoCreate = async (interval = 15) => {
-  const response = await fetch(`${API_BASE}/auto-create`, {
+  const response = await authFetch(`${API_BASE}/auto-create`, {
     method: 'POST',
     headers: getAuthHeaders(),
     body: JSON.stringify({ action: 'start', interval }),
@@ -646,7 +735,7 @@ export const startAutoCreate = async (interval = 15) => {
 };
 
 export const stopAutoCreate = async () => {
-  const response = await fetch(`${API_BASE}/auto-create`, {
+  const response = await authFetch(`${API_BASE}/auto-create`, {
     method: 'POST',
     headers: getAuthHeaders(),
     body: JSON.stringify({ action: 'stop' }),
@@ -695,11 +784,13 @@ export const auth = {
   deleteAccount,
   isAuthenticated,
   getToken,
-  clearAuth
+  clearAuth,
+  refreshSession,
+  ensureFreshToken
 };
 
 export const getAsanaSections = async () => {
-  const response = await fetch(`${API_BASE}/api/settings/columns/asana`, {
+  const response = await authFetch(`${API_BASE}/api/settings/columns/asana`, {
     headers: getAuthHeaders(),
   });
 
@@ -712,7 +803,7 @@ export const getAsanaSections = async () => {
 };
 
 export const getYouTrackStates = async () => {
-  const response = await fetch(`${API_BASE}/api/settings/columns/youtrack`, {
+  const response = await authFetch(`${API_BASE}/api/settings/columns/youtrack`, {
     headers: getAuthHeaders(),
   });
 
@@ -725,7 +816,7 @@ export const getYouTrackStates = async () => {
 };
 
 export const getYouTrackBoards = async () => {
-  const response = await fetch(`${API_BASE}/api/settings/youtrack/boards`, {
+  const response = await authFetch(`${API_BASE}/api/settings/youtrack/boards`, {
     headers: getAuthHeaders(),
   });
 
@@ -803,7 +894,7 @@ export const getFilterOptions = async (column = '') => {
     url += `?column=${encodeURIComponent(column)}`;
   }
   
-  const response = await fetch(url, {
+  const response = await authFetch(url, {
     headers: getAuthHeaders()
   });
   
@@ -817,7 +908,7 @@ export const getFilterOptions = async (column = '') => {
 
 // NEW: Get enhanced analysis with filters and sorting
 export const getEnhancedAnalysis = async (requestBody) => {
-  const response = await fetch(`${API_BASE}/analyze/enhanced`, {
+  const response = await authFetch(`${API_BASE}/analyze/enhanced`, {
     method: 'POST',
     headers: getAuthHeaders(),
     body: JSON.stringify(requestBody),
@@ -833,7 +924,7 @@ export const getEnhancedAnalysis = async (requestBody) => {
 
 // NEW: Get changed mappings
 export const getChangedMappings = async () => {
-  const response = await fetch(`${API_BASE}/changed-mappings`, {
+  const response = await authFetch(`${API_BASE}/changed-mappings`, {
     headers: getAuthHeaders()
   });
   
@@ -852,7 +943,7 @@ export const syncEnhancedTickets = async (column = '', body = {}) => {
     url += `?column=${encodeURIComponent(column)}`;
   }
   
-  const response = await fetch(url, {
+  const response = await authFetch(url, {
     method: 'POST',
     headers: getAuthHeaders(),
     body: JSON.stringify(body)
@@ -868,7 +959,7 @@ export const syncEnhancedTickets = async (column = '', body = {}) => {
 
 // NEW: Get detailed auto-sync status
 export const getAutoSyncDetailed = async () => {
-  const response = await fetch(`${API_BASE}/auto-sync/detailed`, {
+  const response = await authFetch(`${API_BASE}/auto-sync/detailed`, {
     headers: getAuthHeaders()
   });
 
@@ -886,7 +977,7 @@ export const getAutoSyncDetailed = async () => {
 
 // Get sync history (last 15 operations)
 export const getSyncHistory = async (limit = 15) => {
-  const response = await fetch(`${API_BASE}/api/sync/history?limit=${limit}`, {
+  const response = await authFetch(`${API_BASE}/api/sync/history?limit=${limit}`, {
     headers: getAuthHeaders()
   });
 
@@ -900,7 +991,7 @@ export const getSyncHistory = async (limit = 15) => {
 
 // Rollback a sync operation
 export const rollbackSync = async (operationId) => {
-  const response = await fetch(`${API_BASE}/api/sync/rollback/${operationId}`, {
+  const response = await authFetch(`${API_BASE}/api/sync/rollback/${operationId}`, {
     method: 'POST',
     headers: getAuthHeaders()
   });
@@ -916,7 +1007,7 @@ export const rollbackSync = async (operationId) => {
 
 // Get snapshot summary for an operation
 export const getSnapshotSummary = async (operationId) => {
-  const response = await fetch(`${API_BASE}/api/sync/snapshot/${operationId}`, {
+  const response = await authFetch(`${API_BASE}/api/sync/snapshot/${operationId}`, {
     headers: getAuthHeaders()
   });
 
@@ -930,7 +1021,7 @@ export const getSnapshotSummary = async (operationId) => {
 
 // Get operation audit logs
 export const getOperationAuditLogs = async (operationId) => {
-  const response = await fetch(`${API_BASE}/api/sync/operation/${operationId}/logs`, {
+  const response = await authFetch(`${API_BASE}/api/sync/operation/${operationId}/logs`, {
     headers: getAuthHeaders()
   });
 
@@ -953,7 +1044,7 @@ export const getAuditLogs = async (filters = {}) => {
   if (filters.endDate) params.append('end_date', filters.endDate);
   if (filters.limit) params.append('limit', filters.limit);
 
-  const response = await fetch(`${API_BASE}/api/audit/logs?${params.toString()}`, {
+  const response = await authFetch(`${API_BASE}/api/audit/logs?${params.toString()}`, {
     headers: getAuthHeaders()
   });
 
@@ -973,7 +1064,7 @@ export const exportAuditLogsCSV = async (filters = {}) => {
   if (filters.startDate) params.append('start_date', filters.startDate);
   if (filters.endDate) params.append('end_date', filters.endDate);
 
-  const response = await fetch(`${API_BASE}/api/audit/logs/export?${params.toString()}`, {
+  const response = await authFetch(`${API_BASE}/api/audit/logs/export?${params.toString()}`, {
     headers: getAuthHeaders()
   });
 
@@ -989,7 +1080,7 @@ export const exportAuditLogsCSV = async (filters = {}) => {
 
 // Get ticket history
 export const getTicketHistory = async (ticketId) => {
-  const response = await fetch(`${API_BASE}/api/audit/ticket/${ticketId}/history`, {
+  const response = await authFetch(`${API_BASE}/api/audit/ticket/${ticketId}/history`, {
     headers: getAuthHeaders()
   });
 
@@ -1007,7 +1098,7 @@ export const getTicketHistory = async (ticketId) => {
 
 // Get YouTrack users for creator dropdown
 export const getYouTrackUsers = async () => {
-  const response = await fetch(`${API_BASE}/reverse-sync/users`, {
+  const response = await authFetch(`${API_BASE}/reverse-sync/users`, {
     headers: getAuthHeaders()
   });
 
@@ -1022,7 +1113,7 @@ export const getYouTrackUsers = async () => {
 
 // Perform reverse analysis (YouTrack → Asana)
 export const reverseAnalyzeTickets = async (creatorFilter) => {
-  const response = await fetch(`${API_BASE}/reverse-sync/analyze`, {
+  const response = await authFetch(`${API_BASE}/reverse-sync/analyze`, {
     method: 'POST',
     headers: getAuthHeaders(),
     body: JSON.stringify({
@@ -1041,7 +1132,7 @@ export const reverseAnalyzeTickets = async (creatorFilter) => {
 
 // Create tickets from YouTrack to Asana
 export const reverseCreateTickets = async (selectedIssueIDs = []) => {
-  const response = await fetch(`${API_BASE}/reverse-sync/create`, {
+  const response = await authFetch(`${API_BASE}/reverse-sync/create`, {
     method: 'POST',
     headers: getAuthHeaders(),
     body: JSON.stringify({
@@ -1064,7 +1155,7 @@ export const reverseCreateTickets = async (selectedIssueIDs = []) => {
 
 // Get reverse ignored tickets status
 export const getReverseIgnoredStatus = async () => {
-  const response = await fetch(`${API_BASE}/reverse-sync/ignored/status`, {
+  const response = await authFetch(`${API_BASE}/reverse-sync/ignored/status`, {
     method: 'GET',
     headers: getAuthHeaders()
   });
@@ -1080,7 +1171,7 @@ export const getReverseIgnoredStatus = async () => {
 
 // Add or remove a reverse ignored ticket
 export const reverseIgnoreAction = async (ticketId, action, ignoreType) => {
-  const response = await fetch(`${API_BASE}/reverse-sync/ignored`, {
+  const response = await authFetch(`${API_BASE}/reverse-sync/ignored`, {
     method: 'POST',
     headers: getAuthHeaders(),
     body: JSON.stringify({
@@ -1101,7 +1192,7 @@ export const reverseIgnoreAction = async (ticketId, action, ignoreType) => {
 
 // Clear reverse ignored tickets
 export const clearReverseIgnored = async (ignoreType = '') => {
-  const response = await fetch(`${API_BASE}/reverse-sync/ignored/clear`, {
+  const response = await authFetch(`${API_BASE}/reverse-sync/ignored/clear`, {
     method: 'POST',
     headers: getAuthHeaders(),
     body: JSON.stringify({
@@ -1124,7 +1215,7 @@ export const clearReverseIgnored = async (ignoreType = '') => {
 
 // Get reverse auto-create status
 export const getReverseAutoCreateStatus = async () => {
-  const response = await fetch(`${API_BASE}/reverse-sync/auto-create/status`, {
+  const response = await authFetch(`${API_BASE}/reverse-sync/auto-create/status`, {
     method: 'GET',
     headers: getAuthHeaders()
   });
@@ -1140,7 +1231,7 @@ export const getReverseAutoCreateStatus = async () => {
 
 // Start reverse auto-create
 export const startReverseAutoCreate = async (intervalSeconds, selectedCreators) => {
-  const response = await fetch(`${API_BASE}/reverse-sync/auto-create/start`, {
+  const response = await authFetch(`${API_BASE}/reverse-sync/auto-create/start`, {
     method: 'POST',
     headers: getAuthHeaders(),
     body: JSON.stringify({
@@ -1160,7 +1251,7 @@ export const startReverseAutoCreate = async (intervalSeconds, selectedCreators)
 
 // Stop reverse auto-create
 export const stopReverseAutoCreate = async () => {
-  const response = await fetch(`${API_BASE}/reverse-sync/auto-create/stop`, {
+  const response = await authFetch(`${API_BASE}/reverse-sync/auto-create/stop`, {
     method: 'POST',
     headers: getAuthHeaders()
   });
@@ -1176,7 +1267,7 @@ export const stopReverseAutoCreate = async () => {
 
 // Update reverse auto-create settings
 export const updateReverseAutoCreateSettings = async (selectedCreators) => {
-  const response = await fetch(`${API_BASE}/reverse-sync/auto-create/settings`, {
+  const response = await authFetch(`${API_BASE}/reverse-sync/auto-create/settings`, {
     method: 'POST',
     headers: getAuthHeaders(),
     body: JSON.stringify({
@@ -1194,7 +1285,7 @@ export const updateReverseAutoCreateSettings = async (selectedCreators) => {
 };
 
 export const addToBoard = async (issueIds) => {
-  const response = await fetch(`${API_BASE}/add-to-board`, {
+  const response = await authFetch(`${API_BASE}/add-to-board`, {
     method: 'POST',
     headers: getAuthHeaders(),
     body: JSON.stringify({ issue_ids: issueIds }),
@@ -1208,7 +1299,7 @@ export const addToBoard = async (issueIds) => {
 
 // Sync priority mismatches: items = [{youtrack_issue_id, priority}]
 export const syncPriorities = async (items) => {
-  const response = await fetch(`${API_BASE}/sync-priorities`, {
+  const response = await authFetch(`${API_BASE}/sync-priorities`, {
     method: 'POST',
     headers: getAuthHeaders(),
     body: JSON.stringify({ items }),
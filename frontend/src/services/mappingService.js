@@ -3,6 +3,8 @@
 // ============================================
 // API service for ticket mapping endpoints
 
+import { authFetch, refreshSession } from './api';
+
 const API_BASE_URL = process.env.NODE_ENV === 'production'
   ? process.env.REACT_APP_API_URL || 'https://boardsyncv2.onrender.com'
   : 'http://localhost:8080';
@@ -35,8 +37,13 @@ const getAuthHeaders = () => {
  */
 const handleAuthError = (response) => {
   if (response.status === 401) {
-    localStorage.removeItem('auth_token');
-    window.location.href = '/';
+    // The access token may just have expired; only sign out if the session is gone too
+    refreshSession().then((refreshed) => {
+      if (!refreshed) {
+        localStorage.removeItem('auth_token');
+        window.location.href = '/';
+      }
+    });
   }
 };
 
@@ -49,7 +56,7 @@ const mappingService = {
    */
   createMapping: async (asanaUrl, youtrackUrl) => {
     try {
-      const response = await fetch(`${API_BASE_URL}/api/mappings`, {
+      const response = await authFetch(`${API_BASE_URL}/api/mappings`, {
         method: 'POST',
         headers: getAuthHeaders(),
         body: JSON.stringify({
@@ -81,7 +88,7 @@ const mappingService = {
    */
   getAllMappings: async () => {
     try {
-      const response = await fetch(`${API_BASE_URL}/api/mappings`, {
+      const response = await authFetch(`${API_BASE_URL}/api/mappings`, {
         method: 'GET',
         headers: getAuthHeaders()
       });
@@ -109,7 +116,7 @@ const mappingService = {
    */
   deleteMapping: async (id) => {
     try {
-      const response = await fetch(`${API_BASE_URL}/api/mappings/${id}`, {
+      const response = await authFetch(`${API_BASE_URL}/api/mappings/${id}`, {
         method: 'DELETE',
         headers: getAuthHeaders()
       });
@@ -137,7 +144,7 @@ const mappingService = {
    */
   findByAsanaId: async (taskId) => {
     try {
-      const response = await fetch(`${API_BASE_URL}/api/mappings/asana/${taskId}`, {
+      const response = await authFetch(`${API_BASE_URL}/api/mappings/asana/${taskId}`, {
         method: 'GET',
         headers: getAuthHeaders()
       });
@@ -165,7 +172,7 @@ const mappingService = {
    */
   findByYouTrackId: async (issueId) => {
     try {
-      const response = await fetch(`${API_BASE_URL}/api/mappings/youtrack/${issueId}`, {
+      const response = await authFetch(`${API_BASE_URL}/api/mappings/youtrack/${issueId}`, {
         method: 'GET',
         headers: getAuthHeaders()
       });